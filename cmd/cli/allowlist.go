@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// runUpdateAllowlist applies --allowlist-add and --allowlist-remove to the scan
+// allowlist and prints the result
+func (c *CLI) runUpdateAllowlist() error {
+	c.logOperation("Update Scan Allowlist")
+	fmt.Println("🛡️  Updating the scan allowlist...")
+
+	allowlist, err := augmentcleaner.UpdateScanAllowlist(context.Background(), c.progressOptions(),
+		splitExtensionIDs(c.config.TrustExtIDs), splitExtensionIDs(c.config.UntrustExtIDs))
+	if err != nil {
+		c.logOperationResult("Update Scan Allowlist", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Update Scan Allowlist", true, fmt.Sprintf("%d trusted extensions", len(allowlist)))
+	fmt.Printf("✅ Scans skip %d trusted extensions:\n", len(allowlist))
+	for _, id := range allowlist {
+		fmt.Printf("  %s\n", id)
+	}
+	return nil
+}
+
+// splitExtensionIDs splits a comma-separated list of extension IDs
+func splitExtensionIDs(list string) []string {
+	var ids []string
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
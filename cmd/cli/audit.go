@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// AuditKeyEnv is the environment variable holding the HMAC key for audit files
+const AuditKeyEnv = "AUGMENT_AUDIT_KEY"
+
+// auditKey reads the audit HMAC key from the environment
+func auditKey() ([]byte, error) {
+	key := os.Getenv(AuditKeyEnv)
+	if key == "" {
+		return nil, fmt.Errorf("audit key not set: export %s with the HMAC key", AuditKeyEnv)
+	}
+	return []byte(key), nil
+}
+
+// cleanerOptions builds library options from CLI flags, including the audit key when requested
+func (c *CLI) cleanerOptions() (augmentcleaner.Options, error) {
+	opts := c.progressOptions()
+	opts.IncludePlaintext = c.config.IncludePlain
+	opts.UpdateSyncMetadata = c.config.UpdateSync
+
+	if !c.config.WriteAudit {
+		return opts, nil
+	}
+
+	key, err := auditKey()
+	if err != nil {
+		return opts, err
+	}
+	opts.AuditKey = key
+
+	return opts, nil
+}
+
+// runVerifyAudit verifies the HMAC signature of a telemetry audit file
+func (c *CLI) runVerifyAudit() error {
+	c.logOperation("Verify Audit")
+	fmt.Println("🔏 Verifying telemetry audit file...")
+
+	key, err := auditKey()
+	if err != nil {
+		return err
+	}
+
+	result, err := cleaner.VerifyTelemetryAuditFile(c.config.AuditFile, key)
+	if err != nil {
+		c.logOperationResult("Verify Audit", false, err.Error())
+		return fmt.Errorf("audit verification failed: %w", err)
+	}
+
+	if !result.Valid {
+		c.logOperationResult("Verify Audit", false, "signature mismatch")
+		fmt.Println("\n❌ Audit file signature is invalid")
+		if err := c.printResultDetails(result); err != nil {
+			return err
+		}
+		return fmt.Errorf("audit file signature is invalid: %s", c.config.AuditFile)
+	}
+
+	c.logOperationResult("Verify Audit", true, "Signature valid")
+
+	return c.printResult("Audit Verification", result)
+}
+
+// printAuditVerifyResult prints the verified audit record
+func (c *CLI) printAuditVerifyResult(r *cleaner.AuditVerifyResult) {
+	c.printField("Audit File", r.AuditFilePath)
+	c.printField("Signature Valid", r.Valid)
+	c.printField("Timestamp", r.Record.Timestamp)
+	c.printField("Storage File", r.Record.StoragePath)
+	c.printFieldIf("Storage Backup", r.Record.StorageBackupPath)
+	c.printFieldIf("Machine ID Backup", r.Record.MachineIDBackupPath)
+	c.printField("Old Machine ID Hash", r.Record.OldMachineIDHash)
+	c.printField("New Machine ID Hash", r.Record.NewMachineIDHash)
+	c.printField("Old Device ID Hash", r.Record.OldDeviceIDHash)
+	c.printField("New Device ID Hash", r.Record.NewDeviceIDHash)
+	c.printFieldIf("Old Machine ID", r.Record.OldMachineID)
+	c.printFieldIf("New Machine ID", r.Record.NewMachineID)
+}
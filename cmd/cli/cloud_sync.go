@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// backupDirsForOperation returns the backup directories the operation writes to,
+// or none when it creates no backups
+func (c *CLI) backupDirsForOperation(opts augmentcleaner.Options) []string {
+	if !c.config.CreateBackups || c.config.DryRun {
+		return nil
+	}
+
+	backupDir := c.configManager.GetConfig().BackupDirectory
+	switch c.config.Operation {
+	case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpQuickClean:
+		return []string{backupDir}
+	case OpCleanBrowser:
+		return []string{opts.BrowserBackupDir}
+	case OpCleanExtensions, OpCleanAugmentExt:
+		return []string{augmentcleaner.ExtensionBackupDir()}
+	case OpRunAll:
+		return []string{backupDir, opts.BrowserBackupDir}
+	}
+	return nil
+}
+
+// checkCloudSync warns when the VS Code data an operation modifies is inside a
+// OneDrive, Dropbox, Google Drive or iCloud Drive folder, and refuses to write
+// backups into one unless --allow-cloud-backup is given
+func (c *CLI) checkCloudSync() error {
+	switch c.config.Operation {
+	case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpCleanExtensions, OpRunAll, OpQuickClean, OpCleanAugmentExt:
+	default:
+		return nil
+	}
+
+	opts := c.progressOptions()
+	var blocked []augmentcleaner.CloudSyncMatch
+	for _, match := range augmentcleaner.FindCloudSyncPaths(opts, c.backupDirsForOperation(opts)) {
+		if match.Backup {
+			blocked = append(blocked, match)
+			fmt.Printf("\n⚠️  WARNING: Backup directory %s is inside the %s folder %s\n", match.Path, match.Provider, match.Root)
+			fmt.Println("   Backups would be uploaded to cloud storage and can use a lot of space.")
+			c.log("WARN", "Backup directory %s is inside %s folder %s", match.Path, match.Provider, match.Root)
+			continue
+		}
+
+		fmt.Printf("\n⚠️  WARNING: VS Code data %s is inside the %s folder %s\n", match.Path, match.Provider, match.Root)
+		fmt.Printf("   Pause %s syncing while cleaning, or the sync client may restore or conflict with the cleaned files.\n", match.Provider)
+		c.log("WARN", "VS Code data %s is inside %s folder %s", match.Path, match.Provider, match.Root)
+	}
+
+	if len(blocked) > 0 && !c.config.CloudBackup {
+		return fmt.Errorf("backup directory %s is inside a %s folder; choose another backup directory, use --no-backup, or pass --allow-cloud-backup to back up there anyway",
+			blocked[0].Path, blocked[0].Provider)
+	}
+	return nil
+}
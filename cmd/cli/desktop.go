@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"augment-telemetry-cleaner/internal/desktop"
+)
+
+// guiBinaryName is the name build.sh and build.bat give the GUI executable
+const guiBinaryName = "augment-telemetry-cleaner"
+
+// guiExecutable returns the GUI executable in the directory of the running CLI
+func guiExecutable() (string, error) {
+	cliPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the CLI executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(cliPath); err == nil {
+		cliPath = resolved
+	}
+
+	name := guiBinaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	guiPath := filepath.Join(filepath.Dir(cliPath), name)
+	if info, err := os.Stat(guiPath); err != nil || info.IsDir() {
+		return "", fmt.Errorf("GUI executable %s not found; place it next to the CLI", guiPath)
+	}
+	return guiPath, nil
+}
+
+// runInstallDesktopEntry adds the GUI to the application menu with the icon that
+// suits the platform
+func (c *CLI) runInstallDesktopEntry() error {
+	c.logOperation("Install Desktop Entry")
+	fmt.Println("🖥️  Installing desktop entry...")
+
+	guiPath, err := guiExecutable()
+	if err != nil {
+		c.logOperationResult("Install Desktop Entry", false, err.Error())
+		return err
+	}
+
+	entryPath, err := desktop.InstallEntry(guiPath)
+	if err != nil {
+		c.logOperationResult("Install Desktop Entry", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Install Desktop Entry", true, entryPath)
+	fmt.Printf("✅ Installed %s for %s\n", entryPath, guiPath)
+	return nil
+}
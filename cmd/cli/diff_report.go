@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// ANSI color codes used for the diff report
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// runScan executes a read-only storage analysis
+func (c *CLI) runScan() error {
+	c.logOperation("Scan Storage")
+	fmt.Println("🔍 Scanning extension storage...")
+
+	result, err := augmentcleaner.Scan(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Scan Storage", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Scan Storage", true, fmt.Sprintf("Analyzed %d extensions", result.StorageStatistics.ExtensionCount))
+
+	if c.config.Anonymize {
+		homeDir, err := utils.GetHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		if result, err = scanner.AnonymizeScanResult(result, homeDir); err != nil {
+			return err
+		}
+		result.ToolVersion = version
+	}
+
+	if err := c.printResult("Storage Scan", result); err != nil {
+		return err
+	}
+	c.checkScanCoverage(result.StorageStatistics.Coverage)
+	return nil
+}
+
+// checkScanCoverage warns and sets the low coverage exit code when the scan
+// analyzed fewer files than --min-coverage or the configured minimum
+func (c *CLI) checkScanCoverage(coverage scanner.CoverageStats) {
+	minCoverage := c.configManager.GetConfig().MinScanCoverage
+	if c.config.MinCoverage >= 0 {
+		minCoverage = c.config.MinCoverage
+	}
+
+	if coverage.Percent() < minCoverage {
+		fmt.Fprintf(os.Stderr, "\n⚠️  Scan coverage %.1f%% is below the minimum of %.1f%% (%s); results may look cleaner than they are\n",
+			coverage.Percent(), minCoverage, coverage)
+		c.log("WARN", "Scan coverage %.1f%% below minimum %.1f%%: %s", coverage.Percent(), minCoverage, coverage)
+		c.lowCoverage = true
+	}
+}
+
+// runDiffReport compares two scan reports and prints what changed between them
+func (c *CLI) runDiffReport() error {
+	c.logOperation("Diff Report")
+	fmt.Println("🔎 Comparing scan reports...")
+
+	before, err := loadScanReport(c.config.BeforeReport)
+	if err != nil {
+		return fmt.Errorf("failed to load --before report: %w", err)
+	}
+
+	after, err := loadScanReport(c.config.AfterReport)
+	if err != nil {
+		return fmt.Errorf("failed to load --after report: %w", err)
+	}
+
+	diff := scanner.DiffScanResults(before, after)
+
+	c.logOperationResult("Diff Report", true, fmt.Sprintf("%d removed, %d added, %d changed",
+		len(diff.Removed), len(diff.Added), len(diff.Changed)))
+
+	return c.printResult("Diff Report", diff)
+}
+
+// loadScanReport reads a scan report written by --operation scan --output json.
+// Reports captured from stdout start with the CLI header, so everything before
+// the first top-level JSON object is skipped.
+func loadScanReport(path string) (*scanner.StorageAnalysisResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan report: %w", err)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		start := bytes.Index(data, []byte("\n{"))
+		if start < 0 {
+			return nil, fmt.Errorf("no JSON scan report found in %s", path)
+		}
+		data = data[start+1:]
+	}
+
+	var result scanner.StorageAnalysisResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse scan report: %w", err)
+	}
+
+	return &result, nil
+}
+
+// printScanDiff prints a colored, human-readable diff
+func (c *CLI) printScanDiff(diff *scanner.ScanDiff) {
+	c.printField("Items Before", diff.BeforeItemCount)
+	c.printField("Items After", diff.AfterItemCount)
+	c.printField("Telemetry Size Before", diff.TelemetrySizeBefore)
+	c.printField("Telemetry Size After", diff.TelemetrySizeAfter)
+
+	if !diff.HasChanges() {
+		fmt.Println("\n  No differences found between the two scans")
+		return
+	}
+
+	if len(diff.Removed) > 0 {
+		fmt.Printf("\n  Removed (cleaned): %d\n", len(diff.Removed))
+		for _, item := range diff.Removed {
+			fmt.Printf("%s  - [%s] %s: %s (%d bytes)%s\n",
+				colorRed, item.Risk.String(), item.Location, item.Key, item.OldSize, colorReset)
+		}
+	}
+
+	if len(diff.Added) > 0 {
+		fmt.Printf("\n  Added (re-created since the first scan): %d\n", len(diff.Added))
+		for _, item := range diff.Added {
+			fmt.Printf("%s  + [%s] %s: %s (%d bytes)%s\n",
+				colorGreen, item.Risk.String(), item.Location, item.Key, item.NewSize, colorReset)
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		fmt.Printf("\n  Changed: %d\n", len(diff.Changed))
+		for _, item := range diff.Changed {
+			fmt.Printf("%s  ~ [%s] %s: %s (%d -> %d bytes)%s\n",
+				colorYellow, item.Risk.String(), item.Location, item.Key, item.OldSize, item.NewSize, colorReset)
+			if c.config.Verbose {
+				fmt.Printf("%s      %v -> %v%s\n", colorYellow, item.OldValue, item.NewValue, colorReset)
+			}
+		}
+	}
+}
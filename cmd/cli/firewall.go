@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"augment-telemetry-cleaner/internal/firewall"
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+// blocksTelemetryNetwork reports whether --block-telemetry-network applies to an
+// operation: those cleaning VS Code data, which a running editor could otherwise
+// report on while it is cleaned
+func blocksTelemetryNetwork(operation string) bool {
+	switch operation {
+	case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanExtensions, OpCleanAugmentExt,
+		OpRunAll, OpQuickClean:
+		return true
+	}
+	return false
+}
+
+// printTelemetryNetworkDryRun reports in a dry run what --block-telemetry-network
+// would block
+func (c *CLI) printTelemetryNetworkDryRun() {
+	if !c.config.BlockNetwork || !c.config.DryRun {
+		return
+	}
+	domains := scanner.NewTelemetryEndpointChecker().Domains()
+	fmt.Printf("DRY RUN: Would block %d telemetry domains in the firewall while cleaning\n", len(domains))
+}
+
+// blockTelemetryNetwork blocks the telemetry domains of TelemetryEndpointChecker
+// in the system firewall for --block-telemetry-network. Cleaning operations call
+// it once the user has confirmed them, right before they change anything; it does
+// nothing in a dry run, without the flag or when the domains are already blocked.
+// The rules stay until unblockTelemetryNetwork runs at the end of the run, or
+// until the run is interrupted with SIGINT or SIGTERM. Failing to add the rules
+// fails the run, since the user asked not to clean without them.
+func (c *CLI) blockTelemetryNetwork() error {
+	if !c.config.BlockNetwork || c.config.DryRun || c.unblockNetwork != nil {
+		return nil
+	}
+
+	domains := scanner.NewTelemetryEndpointChecker().Domains()
+	fmt.Println("⚠️  WARNING: --block-telemetry-network changes the system firewall.")
+	fmt.Println("   It requires root or administrator rights, and until cleaning finishes")
+	fmt.Println("   no program on this machine can reach these domains:")
+	fmt.Printf("   %s\n", strings.Join(domains, ", "))
+	fmt.Printf("   If the run is killed, remove the rules named %q by hand.\n", firewall.RuleName)
+
+	removeFailed := false
+	firewall.SetLogger(logger.FuncLogger(func(level logger.LogLevel, message string) {
+		c.log(level.String(), "%s", message)
+		if level == logger.ERROR {
+			removeFailed = true
+			fmt.Printf("❌ %s\n", message)
+		}
+	}))
+	unblock, err := firewall.BlockTelemetryDomains(domains)
+	if err != nil {
+		return fmt.Errorf("failed to block telemetry network access (run as root or administrator): %w", err)
+	}
+	fmt.Println("🔒 Telemetry domains blocked for the duration of the run")
+	c.log("INFO", "Blocked %d telemetry domains in the firewall", len(domains))
+
+	var once sync.Once
+	remove := func() {
+		once.Do(func() {
+			unblock()
+			if removeFailed {
+				return
+			}
+			fmt.Println("🔓 Removed the temporary telemetry firewall rules")
+			c.log("INFO", "Removed the temporary telemetry firewall rules")
+		})
+	}
+
+	// Remove the rules when the run is interrupted, which skips deferred calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	finished := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		select {
+		case <-finished:
+			return
+		default:
+		}
+		fmt.Println("\nInterrupted; removing the telemetry firewall rules")
+		c.log("WARN", "Interrupted while the telemetry domains were blocked")
+		remove()
+		os.Exit(130)
+	}()
+
+	c.unblockNetwork = func() {
+		close(finished)
+		stop()
+		remove()
+	}
+	return nil
+}
+
+// unblockTelemetryNetwork removes the firewall rules added by blockTelemetryNetwork, if any
+func (c *CLI) unblockTelemetryNetwork() {
+	if c.unblockNetwork != nil {
+		c.unblockNetwork()
+		c.unblockNetwork = nil
+	}
+}
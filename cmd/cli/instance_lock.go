@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"augment-telemetry-cleaner/internal/instancelock"
+)
+
+// modifiesData reports whether an operation changes VS Code, browser or backup
+// data and must therefore hold the instance lock
+func modifiesData(operation string) bool {
+	switch operation {
+	case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpCleanExtensions,
+		OpRunAll, OpQuickClean, OpCleanSecrets, OpMigrateBackups, OpRestoreSettings, OpCleanSettings, OpCleanAugmentExt:
+		return true
+	}
+	return false
+}
+
+// acquireInstanceLock takes the instance lock for operations that modify data so
+// that the GUI or another CLI run cannot clean or back up the same files at the
+// same time. Without --wait a busy lock fails the run; with it the run blocks
+// until the other instance finishes. The returned function releases the lock.
+func (c *CLI) acquireInstanceLock() (func(), error) {
+	if !modifiesData(c.config.Operation) || c.config.DryRun {
+		return func() {}, nil
+	}
+
+	path, err := instancelock.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate instance lock: %w", err)
+	}
+
+	var lock *instancelock.Lock
+	if c.config.Wait {
+		lock, err = instancelock.Wait(context.Background(), path, c.config.Operation, instancelock.DefaultPollInterval,
+			func(busy *instancelock.BusyError) {
+				fmt.Printf("⏳ Waiting: %v\n", busy)
+				c.log("INFO", "Waiting for instance lock: %v", busy)
+			})
+	} else {
+		lock, err = instancelock.Acquire(path, c.config.Operation)
+	}
+	var busy *instancelock.BusyError
+	if errors.As(err, &busy) && !c.config.Wait {
+		return nil, fmt.Errorf("%w; use --wait to run once it finishes", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.log("DEBUG", "Acquired instance lock %s", lock.Path())
+	return lock.Release, nil
+}
@@ -1,19 +1,29 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
-	"augment-telemetry-cleaner/internal/browser"
 	"augment-telemetry-cleaner/internal/cleaner"
 	"augment-telemetry-cleaner/internal/config"
+	"augment-telemetry-cleaner/internal/jsonschema"
+	"augment-telemetry-cleaner/internal/likepattern"
 	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/retry"
+	"augment-telemetry-cleaner/internal/sanitize"
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
 )
 
 // CLI represents the command-line interface
@@ -22,6 +32,29 @@ type CLI struct {
 	fileLogger    *log.Logger
 	logLevel      int
 	config        *CLIConfig
+
+	// pathResolver derives every path the operations use for the editor chosen
+	// with --editor; nil uses the stable VS Code paths of the current user
+	pathResolver utils.PathResolver
+
+	// browserProfiles is the browser profile snapshot shared by every browser
+	// operation of this invocation; nil until first needed
+	browserProfiles []augmentcleaner.BrowserProfile
+
+	// permissionDenied collects the paths operations of this invocation could not
+	// clean for lack of access rights
+	permissionDenied []string
+
+	// unblockNetwork removes the firewall rules of --block-telemetry-network; nil
+	// until blockTelemetryNetwork adds them
+	unblockNetwork func()
+
+	// lowCoverage is set when a scan analyzed fewer files than the minimum coverage
+	lowCoverage bool
+
+	// resultCounts totals what the operations of this invocation found or removed,
+	// for the webhook summary
+	resultCounts map[string]int64
 }
 
 // CLIConfig holds CLI-specific configuration
@@ -31,9 +64,58 @@ type CLIConfig struct {
 	CreateBackups  bool
 	NoConfirm      bool
 	TargetBrowser  string
+	Editor         string
 	Operation      string
 	OutputFormat   string
 	LogLevel       string
+	BeforeReport   string
+	AfterReport    string
+	WriteAudit     bool
+	IncludePlain   bool
+	UpdateSync     bool
+	AuditFile      string
+	JSONPretty     bool
+	JSONCompact    bool
+	OrphansOnly    bool
+	TopN           int
+	CheckPatterns  bool
+	PatternURL     string
+	ScanTimeout    time.Duration
+	DeepScan       bool
+	ScanSecrets    bool
+	GuessWorkspace bool
+	ExtensionID    string
+	ExtensionIDs   string
+	MinCoverage    float64
+	MinItemSize    int64
+	Anonymize      bool
+	WebhookURL     string
+	DBPath         string
+	RebootDelete   bool
+	DefaultProfile bool
+	BrowserBackup  string
+	IncludeHistory bool
+	Aggressive     bool
+	PurgeSessions  bool
+	CustomPatterns stringList
+	AppendPatterns bool
+	Force          bool
+	CloudBackup    bool
+	HistoryLast    int
+	ValidateOnly   bool
+	InstallDesktop bool
+	PrintSchema    string
+	Wait           bool
+	BlockNetwork   bool
+	BackupID       string
+	SettingsKeys   string
+	ResetSettings  bool
+	PatternValue   string
+	PatternFile    string
+	PreserveKeys   string
+	BackupSort     string
+	TrustExtIDs    string
+	UntrustExtIDs  string
 }
 
 // Operation constants
@@ -43,6 +125,37 @@ const (
 	OpCleanWorkspace  = "clean-workspace"
 	OpCleanBrowser    = "clean-browser"
 	OpRunAll          = "run-all"
+	OpScan            = "scan"
+	OpDiffReport      = "diff-report"
+	OpMigrateBackups  = "migrate-backups"
+	OpBackupStats     = "backup-stats"
+	OpListBackups     = "list-backups"
+	OpVerifyAudit     = "verify-audit"
+	OpCleanSecrets    = "clean-secret-store"
+	OpCleanExtensions = "clean-extensions"
+	OpListProcesses   = "list-processes"
+	OpSuggestSettings = "suggest-settings"
+	OpHistory         = "history"
+	OpSelfTest        = "self-test"
+	OpQuickClean      = "quick-clean"
+	OpRestoreSettings = "restore-vscode-settings"
+	OpCleanSettings   = "clean-settings"
+	OpTestPattern     = "test-pattern"
+	OpCleanAugmentExt = "clean-augment-extension"
+)
+
+// version is the CLI version shown in the banner, usage and anonymized reports
+const version = "2.0.0"
+
+// Exit codes
+const (
+	exitFailure = 1
+	// exitPartialSuccess means the operations completed, but some files were skipped
+	// because of permission errors
+	exitPartialSuccess = 2
+	// exitLowCoverage means the scan completed, but too many files could not be
+	// analyzed for its results to be trusted
+	exitLowCoverage = 3
 )
 
 func main() {
@@ -52,33 +165,131 @@ func main() {
 
 	if err := cli.parseFlags(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
+	}
+
+	// The schema goes to stdout on its own, without the banner or a log file
+	if cli.config.PrintSchema != "" {
+		if err := cli.printSchema(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing schema: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		os.Exit(0)
 	}
 
-	if err := cli.initialize(); err != nil {
+	initialize := cli.initialize
+	if cli.config.ValidateOnly {
+		initialize = cli.initializeValidation
+	}
+	if err := initialize(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing CLI: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
+	}
+
+	startedAt := time.Now()
+	err := cli.run()
+	exitCode := cli.exitCode(err)
+	cli.sendWebhook(startedAt, exitCode, err)
+	os.Exit(exitCode)
+}
+
+// exitCode reports the outcome of run and returns the process exit code
+func (c *CLI) exitCode(runErr error) int {
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "Error running operation: %v\n", runErr)
+		if augmentcleaner.IsPermissionError(runErr) {
+			c.notePermissionDenied(augmentcleaner.PermissionDeniedPath(runErr))
+			c.printPermissionDenied()
+		}
+		return exitFailure
+	}
+
+	if len(c.permissionDenied) > 0 {
+		c.printPermissionDenied()
+		return exitPartialSuccess
 	}
 
-	if err := cli.run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running operation: %v\n", err)
-		os.Exit(1)
+	if c.lowCoverage {
+		return exitLowCoverage
 	}
+	return 0
+}
+
+// stringList is a flag.Value collecting every value of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 // parseFlags parses command-line flags
 func (c *CLI) parseFlags() error {
 	var noBackup bool
 
-	flag.StringVar(&c.config.Operation, "operation", "", "Operation to perform: modify-telemetry, clean-database, clean-workspace, clean-browser, run-all")
+	flag.StringVar(&c.config.Operation, "operation", "", "Operation to perform: modify-telemetry, clean-database, clean-workspace, clean-browser, run-all, scan, diff-report, migrate-backups, backup-stats, list-backups, verify-audit, clean-secret-store, clean-extensions, list-processes, suggest-settings, history, self-test, restore-vscode-settings, clean-settings, test-pattern, clean-augment-extension")
 	flag.BoolVar(&c.config.DryRun, "dry-run", false, "Preview operations without making changes")
 	flag.BoolVar(&c.config.Verbose, "verbose", false, "Enable verbose output")
 	flag.BoolVar(&c.config.CreateBackups, "backup", true, "Create backups before operations")
 	flag.BoolVar(&noBackup, "no-backup", false, "Disable backup creation")
 	flag.BoolVar(&c.config.NoConfirm, "no-confirm", false, "Skip confirmation prompts")
-	flag.StringVar(&c.config.TargetBrowser, "browser", "", "Target specific browser: chrome, firefox, edge, safari (for browser operations)")
-	flag.StringVar(&c.config.OutputFormat, "output", "text", "Output format: text, json")
+	flag.StringVar(&c.config.TargetBrowser, "browser", "", "Target specific browser: chrome, chrome-beta, chrome-dev, chrome-canary, edge, arc, webview2, firefox, safari (for browser operations)")
+	flag.StringVar(&c.config.Editor, "editor", "", "Target only this editor: vscode, vscode-insiders, vscodium, cursor (default: VS Code, with extension scans and cloud sync checks covering every editor)")
+	flag.StringVar(&c.config.OutputFormat, "output", "text", "Output format: text, json, yaml")
+	flag.BoolVar(&c.config.JSONPretty, "json-pretty", false, "Indent JSON output (default for --output json)")
+	flag.BoolVar(&c.config.JSONCompact, "json-compact", false, "Emit compact single-line JSON (with --output json)")
 	flag.StringVar(&c.config.LogLevel, "log-level", "INFO", "Log level: DEBUG, INFO, WARN, ERROR")
+	flag.StringVar(&c.config.BeforeReport, "before", "", "Scan report (JSON) taken before cleaning (for diff-report)")
+	flag.StringVar(&c.config.AfterReport, "after", "", "Scan report (JSON) taken after cleaning (for diff-report)")
+	flag.BoolVar(&c.config.WriteAudit, "audit", false, "Write a signed old/new ID audit file when modifying telemetry (key from "+AuditKeyEnv+")")
+	flag.BoolVar(&c.config.IncludePlain, "include-plaintext", false, "Include raw IDs in the audit file instead of hashes only")
+	flag.BoolVar(&c.config.UpdateSync, "update-sync", false, "Also write the new telemetry IDs to the VS Code Settings Sync metadata so a sync does not restore the old ones (for modify-telemetry, run-all and quick-clean)")
+	flag.BoolVar(&c.config.Force, "force", false, "Also clean storage of workspaces currently open in VS Code (for clean-workspace) and clean while several VS Code windows are open")
+	flag.BoolVar(&c.config.CloudBackup, "allow-cloud-backup", false, "Create backups even when the backup directory is inside a OneDrive, Dropbox, Google Drive or iCloud Drive folder (for cleaning operations)")
+	flag.BoolVar(&c.config.OrphansOnly, "orphans-only", false, "Only remove workspace storage of folders that no longer exist (for clean-workspace)")
+	flag.BoolVar(&c.config.RebootDelete, "schedule-delete-on-reboot", false, "Register browser files locked by other processes for deletion at the next reboot (Windows, requires administrator)")
+	flag.BoolVar(&c.config.DefaultProfile, "default-profile-only", false, "Only clean the default profile of each browser instead of all profiles (for clean-browser)")
+	flag.BoolVar(&c.config.IncludeHistory, "include-history", false, "Also remove visited Augment URLs from Chromium History and Top Sites and Firefox places.sqlite (for clean-browser, opt-in)")
+	flag.BoolVar(&c.config.IncludeHistory, "clean-history", false, "Same as --include-history")
+	flag.BoolVar(&c.config.PurgeSessions, "purge-all-session-storage", false, "Delete every Chromium Session Storage file instead of the keys of Augment origins, losing the session storage of every site (for clean-browser)")
+	flag.BoolVar(&c.config.Aggressive, "aggressive", false, "Also remove data of unknown browser extensions whose manifest references Augment domains (for clean-browser)")
+	flag.Var(&c.config.CustomPatterns, "augment-pattern", "SQL LIKE pattern of Augment data, e.g. %acme-ai%, replacing the config's custom_augment_patterns; repeat for several (for clean-database, clean-browser, run-all, quick-clean, scan and test-pattern)")
+	flag.BoolVar(&c.config.AppendPatterns, "append-patterns", false, "Add the --augment-pattern patterns to the config's custom_augment_patterns instead of replacing them")
+	flag.StringVar(&c.config.BrowserBackup, "browser-backup-dir", "", "Directory browser profile backups are stored in, e.g. on an external drive (for clean-browser, default from config)")
+	flag.IntVar(&c.config.HistoryLast, "last", 10, "Number of most recent operations or backups to show (for history and list-backups, 0 for all)")
+	flag.StringVar(&c.config.BackupSort, "sort", "", "Order of the listed backups: date (newest first, default) or size (largest first) (for list-backups)")
+	flag.IntVar(&c.config.TopN, "top", 0, "Number of largest telemetry items and extensions to list (for scan, default from config)")
+	flag.StringVar(&c.config.DBPath, "db-path", "", "VS Code state.vscdb database to clean instead of the auto-detected one, e.g. of a portable install (for clean-database and quick-clean)")
+	flag.BoolVar(&c.config.DeepScan, "deep-scan", false, "Also analyze extension JavaScript bundles for the telemetry endpoints they call (for scan, slower)")
+	flag.BoolVar(&c.config.ScanSecrets, "scan-secrets", false, "Also list Augment tokens in the OS secret store (for scan)")
+	flag.BoolVar(&c.config.GuessWorkspace, "guess-workspaces", false, "Search common project directories for workspace settings when VS Code lists no recently opened folders (for scan)")
+	flag.BoolVar(&c.config.ValidateOnly, "validate-only", false, "Check the config file and the paths the operation would use, then exit without reading or modifying data (operation optional)")
+	flag.BoolVar(&c.config.InstallDesktop, "install-desktop-entry", false, "Add the GUI next to this binary to the application menu (.desktop entry on Linux, Start Menu shortcut on Windows), then exit")
+	flag.StringVar(&c.config.TrustExtIDs, "allowlist-add", "", "Comma-separated extension IDs scans should trust and skip, e.g. ms-python.python, then exit")
+	flag.StringVar(&c.config.UntrustExtIDs, "allowlist-remove", "", "Comma-separated extension IDs scans should analyze again, then exit")
+	flag.StringVar(&c.config.PrintSchema, "print-schema", "", "Print the JSON Schema of an operation's --output json result (or validate-only), then exit")
+	flag.StringVar(&c.config.ExtensionID, "extension", "", "Only scan the global and workspace storage of this extension ID, e.g. ms-python.python (for scan), or the extension to clean instead of Augment (for clean-augment-extension)")
+	flag.StringVar(&c.config.ExtensionIDs, "extension-ids", "", "Comma-separated extension IDs to clean, e.g. ms-python.python,augment.vscode-augment (for clean-extensions, default: every extension at medium risk or above)")
+	flag.Float64Var(&c.config.MinCoverage, "min-coverage", -1, "Minimum percentage of storage files a scan must analyze before exiting with code 3 (for scan, default from config)")
+	flag.Int64Var(&c.config.MinItemSize, "min-item-size-bytes", 0, "Only report storage keys whose values are at least this many bytes, for a quick scan for large data blobs (for scan)")
+	flag.BoolVar(&c.config.Anonymize, "anonymize", false, "Hash paths, mask user names and emails, and drop stored values so the report can be shared (for scan)")
+	flag.StringVar(&c.config.WebhookURL, "webhook-url", "", "POST a JSON summary of the run to this URL, signed with AUGMENT_WEBHOOK_SECRET when set (default from config)")
+	flag.DurationVar(&c.config.ScanTimeout, "scan-timeout", 0, "Stop scanning after this long and report partial results, e.g. 2m (0 = no limit)")
+	flag.BoolVar(&c.config.CheckPatterns, "check-pattern-updates", false, "Download newer telemetry patterns before scanning")
+	flag.StringVar(&c.config.PatternURL, "pattern-update-url", scanner.DefaultPatternUpdateURL, "Telemetry pattern manifest URL (with --check-pattern-updates)")
+	flag.BoolVar(&c.config.Wait, "wait", false, "Wait for another instance that is modifying data to finish instead of failing (for operations that modify data)")
+	flag.BoolVar(&c.config.BlockNetwork, "block-telemetry-network", false, "Block the known telemetry domains in the system firewall while cleaning; requires root or administrator rights (for cleaning operations)")
+	flag.StringVar(&c.config.BackupID, "backup-id", "", "ID of the settings backup to restore instead of the most recent, e.g. backup-1700000000 (for restore-vscode-settings)")
+	flag.StringVar(&c.config.SettingsKeys, "settings-keys", "", "Comma-separated Augment settings to clean, as keys or patterns such as augment.chat.* (for clean-settings, default: every Augment setting)")
+	flag.BoolVar(&c.config.ResetSettings, "reset-settings", false, "Set the selected settings to the defaults of the installed Augment extension instead of removing them (for clean-settings)")
+	flag.StringVar(&c.config.AuditFile, "audit-file", "", "Audit file to check (for verify-audit)")
+	flag.StringVar(&c.config.PreserveKeys, "preserve-keys", "", "Comma-separated global storage keys to keep even when they look like telemetry (for clean-augment-extension, default: userPreferences,licenseKey)")
+	flag.StringVar(&c.config.PatternValue, "value", "", "Storage key, path or value to run through the telemetry patterns (for test-pattern)")
+	flag.StringVar(&c.config.PatternFile, "file", "", "File whose path and content are run through the telemetry patterns (for test-pattern)")
 
 	// Custom help
 	flag.Usage = c.printUsage
@@ -90,12 +301,45 @@ func (c *CLI) parseFlags() error {
 		c.config.CreateBackups = false
 	}
 
-	// Validate operation
+	// Every path the operations use is derived for the chosen editor
+	if c.config.Editor != "" {
+		editor, err := utils.ParseEditor(c.config.Editor)
+		if err != nil {
+			return fmt.Errorf("invalid --editor: %s. Valid editors: vscode, vscode-insiders, vscodium, cursor", c.config.Editor)
+		}
+		c.pathResolver = utils.NewEditorPathResolver(utils.DefaultPathResolver(), editor)
+	}
+
+	if c.config.PrintSchema != "" {
+		if c.config.Operation != "" || c.config.ValidateOnly || c.config.InstallDesktop {
+			return fmt.Errorf("--print-schema cannot be combined with --operation, --validate-only or --install-desktop-entry")
+		}
+		return nil
+	}
+
+	if c.config.InstallDesktop {
+		if c.config.Operation != "" || c.config.ValidateOnly {
+			return fmt.Errorf("--install-desktop-entry cannot be combined with --operation or --validate-only")
+		}
+		return nil
+	}
+
+	if c.config.TrustExtIDs != "" || c.config.UntrustExtIDs != "" {
+		if c.config.Operation != "" || c.config.ValidateOnly || c.config.InstallDesktop {
+			return fmt.Errorf("--allowlist-add and --allowlist-remove cannot be combined with --operation, --validate-only or --install-desktop-entry")
+		}
+		return nil
+	}
+
+	// Validate operation; --validate-only without one checks the paths of every operation
+	if c.config.Operation == "" && c.config.ValidateOnly {
+		return nil
+	}
 	if c.config.Operation == "" {
 		return fmt.Errorf("operation is required. Use --help for usage information")
 	}
 
-	validOps := []string{OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpRunAll}
+	validOps := []string{OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpRunAll, OpScan, OpDiffReport, OpMigrateBackups, OpBackupStats, OpListBackups, OpVerifyAudit, OpCleanSecrets, OpCleanExtensions, OpListProcesses, OpSuggestSettings, OpHistory, OpSelfTest, OpQuickClean, OpRestoreSettings, OpCleanSettings, OpTestPattern, OpCleanAugmentExt}
 	valid := false
 	for _, op := range validOps {
 		if c.config.Operation == op {
@@ -107,12 +351,199 @@ func (c *CLI) parseFlags() error {
 		return fmt.Errorf("invalid operation: %s. Valid operations: %s", c.config.Operation, strings.Join(validOps, ", "))
 	}
 
+	switch strings.ToUpper(c.config.LogLevel) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		return fmt.Errorf("invalid log level: %s. Valid levels: DEBUG, INFO, WARN, ERROR", c.config.LogLevel)
+	}
+
+	switch c.config.OutputFormat {
+	case "text", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid output format: %s. Valid formats: text, json, yaml", c.config.OutputFormat)
+	}
+
+	if c.config.JSONPretty && c.config.JSONCompact {
+		return fmt.Errorf("--json-pretty and --json-compact cannot be used together")
+	}
+
+	if c.config.OrphansOnly && c.config.Operation != OpCleanWorkspace {
+		return fmt.Errorf("--orphans-only can only be used with clean-workspace")
+	}
+
+	if c.config.Force {
+		switch c.config.Operation {
+		case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpCleanExtensions, OpRunAll, OpQuickClean, OpCleanAugmentExt:
+		default:
+			return fmt.Errorf("--force can only be used with cleaning operations")
+		}
+	}
+
+	if c.config.CloudBackup {
+		switch c.config.Operation {
+		case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpCleanBrowser, OpCleanExtensions, OpRunAll, OpQuickClean, OpCleanAugmentExt:
+		default:
+			return fmt.Errorf("--allow-cloud-backup can only be used with cleaning operations")
+		}
+	}
+
+	if c.config.RebootDelete && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--schedule-delete-on-reboot can only be used with clean-browser or run-all")
+	}
+
+	if c.config.DefaultProfile && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--default-profile-only can only be used with clean-browser or run-all")
+	}
+
+	if c.config.IncludeHistory && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--include-history/--clean-history can only be used with clean-browser or run-all")
+	}
+
+	if c.config.Aggressive && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--aggressive can only be used with clean-browser or run-all")
+	}
+
+	if c.config.PurgeSessions && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--purge-all-session-storage can only be used with clean-browser or run-all")
+	}
+
+	if len(c.config.CustomPatterns) > 0 {
+		switch c.config.Operation {
+		case OpCleanDatabase, OpCleanBrowser, OpRunAll, OpQuickClean, OpScan, OpTestPattern:
+		default:
+			return fmt.Errorf("--augment-pattern can only be used with clean-database, clean-browser, run-all, quick-clean, scan or test-pattern")
+		}
+		for _, pattern := range c.config.CustomPatterns {
+			if err := likepattern.Validate(pattern); err != nil {
+				return fmt.Errorf("invalid --augment-pattern: %w", err)
+			}
+		}
+	}
+
+	if c.config.AppendPatterns && len(c.config.CustomPatterns) == 0 {
+		return fmt.Errorf("--append-patterns can only be used with --augment-pattern")
+	}
+
+	if c.config.BrowserBackup != "" && c.config.Operation != OpCleanBrowser && c.config.Operation != OpRunAll {
+		return fmt.Errorf("--browser-backup-dir can only be used with clean-browser or run-all")
+	}
+
+	if c.config.UpdateSync && c.config.Operation != OpModifyTelemetry && c.config.Operation != OpRunAll && c.config.Operation != OpQuickClean {
+		return fmt.Errorf("--update-sync can only be used with modify-telemetry, run-all or quick-clean")
+	}
+
+	if c.config.DBPath != "" && c.config.Operation != OpCleanDatabase && c.config.Operation != OpRunAll && c.config.Operation != OpQuickClean {
+		return fmt.Errorf("--db-path can only be used with clean-database, run-all or quick-clean")
+	}
+
+	if !c.config.CreateBackups && c.config.Operation == OpQuickClean {
+		return fmt.Errorf("quick-clean always creates backups and cannot be used with --no-backup or --backup=false")
+	}
+
+	if c.config.DeepScan && c.config.Operation != OpScan {
+		return fmt.Errorf("--deep-scan can only be used with scan")
+	}
+
+	if c.config.ScanSecrets && c.config.Operation != OpScan {
+		return fmt.Errorf("--scan-secrets can only be used with scan")
+	}
+
+	if c.config.GuessWorkspace && c.config.Operation != OpScan {
+		return fmt.Errorf("--guess-workspaces can only be used with scan")
+	}
+
+	if c.config.ExtensionID != "" && c.config.Operation != OpScan && c.config.Operation != OpCleanAugmentExt {
+		return fmt.Errorf("--extension can only be used with scan or clean-augment-extension")
+	}
+
+	if c.config.PreserveKeys != "" && c.config.Operation != OpCleanAugmentExt {
+		return fmt.Errorf("--preserve-keys can only be used with clean-augment-extension")
+	}
+
+	if c.config.ExtensionIDs != "" && c.config.Operation != OpCleanExtensions {
+		return fmt.Errorf("--extension-ids can only be used with clean-extensions")
+	}
+
+	if c.config.MinCoverage >= 0 && c.config.Operation != OpScan {
+		return fmt.Errorf("--min-coverage can only be used with scan")
+	}
+
+	if c.config.MinCoverage > 100 {
+		return fmt.Errorf("--min-coverage must be a percentage between 0 and 100")
+	}
+
+	if c.config.MinItemSize != 0 && c.config.Operation != OpScan {
+		return fmt.Errorf("--min-item-size-bytes can only be used with scan")
+	}
+
+	if c.config.MinItemSize < 0 {
+		return fmt.Errorf("--min-item-size-bytes must not be negative")
+	}
+
+	if c.config.Anonymize && c.config.Operation != OpScan {
+		return fmt.Errorf("--anonymize can only be used with scan")
+	}
+
+	if c.config.BackupID != "" && c.config.Operation != OpRestoreSettings {
+		return fmt.Errorf("--backup-id can only be used with restore-vscode-settings")
+	}
+
+	if c.config.SettingsKeys != "" && c.config.Operation != OpCleanSettings {
+		return fmt.Errorf("--settings-keys can only be used with clean-settings")
+	}
+
+	if c.config.ResetSettings && c.config.Operation != OpCleanSettings {
+		return fmt.Errorf("--reset-settings can only be used with clean-settings")
+	}
+
+	if (c.config.PatternValue != "" || c.config.PatternFile != "") && c.config.Operation != OpTestPattern {
+		return fmt.Errorf("--value and --file can only be used with test-pattern")
+	}
+
+	if c.config.BackupSort != "" && c.config.Operation != OpListBackups {
+		return fmt.Errorf("--sort can only be used with list-backups")
+	}
+
+	if c.config.BackupSort != "" && c.config.BackupSort != "date" && c.config.BackupSort != "size" {
+		return fmt.Errorf("invalid --sort: %s. Valid orders: date, size", c.config.BackupSort)
+	}
+
+	if c.config.Wait && !modifiesData(c.config.Operation) {
+		return fmt.Errorf("--wait can only be used with operations that modify data")
+	}
+
+	if c.config.BlockNetwork && !blocksTelemetryNetwork(c.config.Operation) {
+		return fmt.Errorf("--block-telemetry-network can only be used with modify-telemetry, clean-database, clean-workspace, clean-extensions, clean-augment-extension, run-all or quick-clean")
+	}
+
+	if c.config.WebhookURL != "" {
+		if u, err := url.Parse(c.config.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--webhook-url must be an http or https URL")
+		}
+	}
+
+	if c.config.ValidateOnly {
+		return nil
+	}
+
+	if c.config.Operation == OpDiffReport && (c.config.BeforeReport == "" || c.config.AfterReport == "") {
+		return fmt.Errorf("diff-report requires both --before and --after scan reports")
+	}
+
+	if c.config.Operation == OpVerifyAudit && c.config.AuditFile == "" {
+		return fmt.Errorf("verify-audit requires --audit-file")
+	}
+
+	if c.config.Operation == OpTestPattern && c.config.PatternValue == "" && c.config.PatternFile == "" {
+		return fmt.Errorf("test-pattern requires --value or --file")
+	}
+
 	return nil
 }
 
 // printUsage prints usage information
 func (c *CLI) printUsage() {
-	fmt.Fprintf(os.Stderr, `Augment Telemetry Cleaner CLI v2.0.0
+	fmt.Fprintf(os.Stderr, `Augment Telemetry Cleaner CLI v%s
 
 USAGE:
     augment-telemetry-cleaner-cli --operation <operation> [options]
@@ -123,6 +554,26 @@ OPERATIONS:
     clean-workspace     Clean VS Code workspace storage
     clean-browser       Clean Augment data from browsers
     run-all            Run all cleaning operations
+    quick-clean        Only clean the database and rotate telemetry IDs, the fastest operations
+    scan               Analyze extension storage without modifying anything
+    diff-report        Compare two scan reports (requires --before and --after)
+    migrate-backups    Upgrade metadata of existing backups to the current format
+    backup-stats       Summarize the disk space used by extension backups
+    list-backups       List the most recent extension backups (see --last and --sort)
+    verify-audit       Verify the signature of a telemetry audit file (requires --audit-file)
+    clean-secret-store Remove Augment tokens from the OS secret store (libsecret/Keychain/Credential Manager)
+    clean-extensions   Clean the storage of several extensions at once (see --extension-ids)
+    list-processes     List running browser processes that clean-browser would close
+    suggest-settings   Score VS Code settings for privacy and print a recommended settings.json patch
+    history            Show the most recent cleaning operations (see --last)
+    self-test          Run every cleaner against a temporary sandbox and print PASS/FAIL per module
+    restore-vscode-settings
+                       Revert VS Code settings.json to its most recent settings backup (see --backup-id)
+    clean-settings     Remove Augment settings from VS Code settings.json (see --settings-keys)
+    clean-augment-extension
+                       Remove telemetry keys from the global storage of the Augment extension
+                       (see --preserve-keys)
+    test-pattern       Show the pattern rules that fire for a value or file (requires --value or --file)
 
 OPTIONS:
     --operation <op>        Operation to perform (required)
@@ -131,9 +582,107 @@ OPTIONS:
     --backup               Create backups before operations (default: true)
     --no-backup            Disable backup creation
     --no-confirm           Skip confirmation prompts
-    --browser <browser>    Target specific browser for browser operations
-    --output <format>      Output format: text, json (default: text)
+    --browser <browser>    Target specific browser for browser operations: chrome,
+                           chrome-beta, chrome-dev, chrome-canary, edge, arc,
+                           webview2 (Windows), firefox or safari
+    --editor <editor>      Target only this editor: vscode, vscode-insiders,
+                           vscodium or cursor (default: VS Code; extension
+                           scans and cloud sync checks cover every editor)
+    --output <format>      Output format: text, json, yaml (default: text)
+    --json-pretty          Indent JSON output (default with --output json)
+    --json-compact         Emit compact single-line JSON (with --output json)
     --log-level <level>    Log level: DEBUG, INFO, WARN, ERROR (default: INFO)
+    --before <file>        Scan report taken before cleaning (diff-report)
+    --after <file>         Scan report taken after cleaning (diff-report)
+    --audit                Write a signed audit file when modifying telemetry IDs
+                           (HMAC key read from AUGMENT_AUDIT_KEY)
+    --include-plaintext    Include raw IDs in the audit file (default: hashes only)
+    --update-sync          Also write the new IDs to the VS Code Settings Sync metadata
+                           (modify-telemetry, run-all, quick-clean)
+    --audit-file <file>    Audit file to verify (verify-audit)
+    --backup-id <id>       Settings backup to restore instead of the most recent
+                           (restore-vscode-settings)
+    --settings-keys <keys> Comma-separated Augment settings or patterns such as augment.chat.*
+                           to clean; without it every Augment setting (clean-settings)
+    --reset-settings       Reset the settings to the extension defaults instead of removing
+                           them (clean-settings)
+    --preserve-keys <keys> Comma-separated global storage keys to keep (clean-augment-extension,
+                           default: userPreferences,licenseKey)
+    --last <n>             Number of most recent operations or backups to show, 0 for all
+                           (history, list-backups, default: 10)
+    --sort <order>         List backups by date (newest first) or size (largest first)
+                           (list-backups, default: date)
+    --value <string>       Storage key, path or value to test against the patterns (test-pattern)
+    --file <path>          File whose path and content are tested against the patterns
+                           (test-pattern)
+    --top <n>              Number of largest telemetry items and extensions to list (scan)
+    --deep-scan            Analyze extension bundles for telemetry endpoints (scan)
+    --scan-secrets         List Augment tokens in the OS secret store (scan)
+    --extension <id>       Only scan the storage of one extension, e.g. ms-python.python (scan),
+                           or clean it instead of Augment (clean-augment-extension)
+    --extension-ids <ids>  Comma-separated extension IDs to clean; without it every extension
+                           at medium risk or above is cleaned (clean-extensions)
+    --min-coverage <pct>   Exit with code 3 when a scan analyzes fewer files (scan, default 90)
+    --min-item-size-bytes <bytes>
+                           Only report storage keys with values of at least this size (scan)
+    --anonymize            Hash paths and drop stored values so the report can be shared (scan)
+    --webhook-url <url>    POST a JSON summary of the run to this URL (signed with
+                           AUGMENT_WEBHOOK_SECRET when set; default from config)
+    --scan-timeout <dur>   Stop scanning after this long and show partial results (e.g. 2m)
+    --check-pattern-updates
+                           Download newer telemetry patterns before scanning
+    --pattern-update-url <url>
+                           Pattern manifest URL (default: project repository)
+    --db-path <path>       VS Code state.vscdb to clean instead of the auto-detected one
+                           (clean-database, quick-clean, also with --dry-run)
+    --orphans-only         Only prune workspace storage of deleted folders (clean-workspace)
+    --force                Also clean storage of workspaces open in VS Code (clean-workspace)
+                           and clean while several VS Code windows are open
+    --allow-cloud-backup   Create backups inside OneDrive/Dropbox/Google Drive/iCloud
+                           folders instead of refusing to
+    --schedule-delete-on-reboot
+                           Delete browser files locked by other processes at the next
+                           reboot (clean-browser, Windows only, requires administrator)
+    --default-profile-only Only clean the default profile of each browser (clean-browser)
+    --include-history      Also remove visited Augment URLs from Chromium History and
+                           Top Sites and Firefox places.sqlite (clean-browser, alias
+                           --clean-history)
+    --aggressive           Also remove data of unknown browser extensions whose manifest
+                           references Augment domains (clean-browser)
+    --purge-all-session-storage
+                           Delete every Chromium Session Storage file instead of only
+                           the keys of Augment origins; other sites lose their session
+                           storage too (clean-browser)
+    --browser-backup-dir <dir>
+                           Directory browser profile backups are stored in (clean-browser)
+    --augment-pattern <pattern>
+                           SQL LIKE pattern of Augment data besides the built-in ones,
+                           e.g. %%acme-ai%%; repeat for several. Replaces the config's
+                           custom_augment_patterns (clean-database, clean-browser,
+                           run-all, quick-clean, scan, test-pattern)
+    --append-patterns      Add the --augment-pattern patterns to the config's instead
+                           of replacing them
+    --wait                 Wait for another instance that is modifying data to finish
+                           instead of failing (cleaning operations, migrate-backups,
+                           restore-vscode-settings, clean-settings)
+    --block-telemetry-network
+                           Block the known telemetry domains in the system firewall
+                           while cleaning and remove the rules afterwards. Requires
+                           root or administrator rights and cuts every program off
+                           from those domains meanwhile (cleaning operations)
+    --validate-only        Check the config file and the paths the operation would use
+                           without reading or modifying data; exits 1 on any failure
+                           (the operation is optional and defaults to all paths)
+    --install-desktop-entry
+                           Add the GUI next to this binary to the application menu
+                           (Linux .desktop entry, Windows Start Menu shortcut)
+    --allowlist-add <ids>  Add comma-separated extension IDs to the scan allowlist and exit;
+                           scans report them as trusted without analyzing their storage
+    --allowlist-remove <ids>
+                           Remove extension IDs from the scan allowlist and exit
+    --print-schema <operation>
+                           Print the JSON Schema of the operation's --output json
+                           result (or validate-only) and exit
     --help                 Show this help message
 
 EXAMPLES:
@@ -146,9 +695,22 @@ EXAMPLES:
     # Clean Chrome browser data without confirmation
     augment-telemetry-cleaner-cli --operation clean-browser --browser chrome --no-confirm
 
+    # Check that everything run-all needs is accessible, without changing anything
+    augment-telemetry-cleaner-cli --operation run-all --validate-only
+
     # Modify telemetry IDs without creating backups
     augment-telemetry-cleaner-cli --operation modify-telemetry --no-backup
 
+    # Verify a cleaning run by comparing scans taken before and after
+    augment-telemetry-cleaner-cli --operation scan --output json > before.json
+    augment-telemetry-cleaner-cli --operation run-all --no-confirm
+    augment-telemetry-cleaner-cli --operation scan --output json > after.json
+    augment-telemetry-cleaner-cli --operation diff-report --before before.json --after after.json
+
+    # Rotate telemetry IDs with a signed audit trail, then verify it
+    AUGMENT_AUDIT_KEY=secret augment-telemetry-cleaner-cli --operation modify-telemetry --audit
+    AUGMENT_AUDIT_KEY=secret augment-telemetry-cleaner-cli --operation verify-audit --audit-file telemetry-audit.1700000000.json
+
 SAFETY FEATURES:
     - Dry-run mode for safe preview
     - Automatic backup creation (unless disabled)
@@ -159,7 +721,7 @@ WARNING:
     This application may log you out of other browser extensions and accounts,
     but Augment will continue to work properly even with a new email account
     after running this tool.
-`)
+`, version)
 }
 
 // initialize initializes the CLI components
@@ -180,6 +742,7 @@ func (c *CLI) initialize() error {
 	if err != nil {
 		return fmt.Errorf("failed to update configuration: %w", err)
 	}
+	retry.SetDefault(c.configManager.GetConfig().RetryPolicy())
 
 	// Initialize simple file logger
 	logDir := "logs"
@@ -195,6 +758,18 @@ func (c *CLI) initialize() error {
 	return nil
 }
 
+// initializeValidation loads the configuration for --validate-only without
+// saving it or creating the config and log directories
+func (c *CLI) initializeValidation() error {
+	configManager, err := config.OpenConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+	c.configManager = configManager
+	c.logLevel = c.parseLogLevel(c.config.LogLevel)
+	return nil
+}
+
 // Logging helper methods
 func (c *CLI) logOperation(operation string) {
 	c.log("INFO", "=== Starting operation: %s ===", operation)
@@ -245,6 +820,30 @@ func (c *CLI) log(level, format string, args ...interface{}) {
 func (c *CLI) run() error {
 	c.printHeader()
 
+	if c.config.ValidateOnly {
+		return c.runValidate()
+	}
+	if c.config.InstallDesktop {
+		return c.runInstallDesktopEntry()
+	}
+	if c.config.TrustExtIDs != "" || c.config.UntrustExtIDs != "" {
+		return c.runUpdateAllowlist()
+	}
+
+	if err := c.checkCloudSync(); err != nil {
+		return err
+	}
+
+	release, err := c.acquireInstanceLock()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// Cleaning operations block the telemetry network once confirmed
+	c.printTelemetryNetworkDryRun()
+	defer c.unblockTelemetryNetwork()
+
 	switch c.config.Operation {
 	case OpModifyTelemetry:
 		return c.runModifyTelemetry()
@@ -256,6 +855,40 @@ func (c *CLI) run() error {
 		return c.runCleanBrowser()
 	case OpRunAll:
 		return c.runAllOperations()
+	case OpQuickClean:
+		return c.runQuickClean()
+	case OpScan:
+		return c.runScan()
+	case OpDiffReport:
+		return c.runDiffReport()
+	case OpMigrateBackups:
+		return c.runMigrateBackups()
+	case OpBackupStats:
+		return c.runBackupStats()
+	case OpListBackups:
+		return c.runListBackups()
+	case OpVerifyAudit:
+		return c.runVerifyAudit()
+	case OpCleanSecrets:
+		return c.runCleanSecretStore()
+	case OpCleanExtensions:
+		return c.runCleanExtensions()
+	case OpListProcesses:
+		return c.runListProcesses()
+	case OpSuggestSettings:
+		return c.runSuggestSettings()
+	case OpHistory:
+		return c.runHistory()
+	case OpSelfTest:
+		return c.runSelfTest()
+	case OpRestoreSettings:
+		return c.runRestoreVSCodeSettings()
+	case OpCleanSettings:
+		return c.runCleanSettings()
+	case OpTestPattern:
+		return c.runTestPattern()
+	case OpCleanAugmentExt:
+		return c.runCleanAugmentExtension()
 	default:
 		return fmt.Errorf("unknown operation: %s", c.config.Operation)
 	}
@@ -263,14 +896,19 @@ func (c *CLI) run() error {
 
 // printHeader prints the application header
 func (c *CLI) printHeader() {
-	fmt.Println("=== Augment Telemetry Cleaner CLI v2.0.0 ===")
+	fmt.Printf("=== Augment Telemetry Cleaner CLI v%s ===\n", version)
 	fmt.Printf("Operation: %s\n", c.config.Operation)
-	if c.config.DryRun {
+	if c.config.ValidateOnly {
+		fmt.Println("Mode: VALIDATE ONLY (No data read or changed)")
+	} else if c.config.DryRun {
 		fmt.Println("Mode: DRY RUN (Preview only)")
 	} else {
 		fmt.Println("Mode: LIVE (Making actual changes)")
 	}
 	fmt.Printf("Backups: %t\n", c.config.CreateBackups)
+	if editor, ok := utils.ResolverEditor(c.pathResolver); ok {
+		fmt.Printf("Editor: %s\n", editor.Name)
+	}
 	fmt.Println("==========================================")
 	fmt.Println()
 }
@@ -279,6 +917,8 @@ func (c *CLI) printHeader() {
 func (c *CLI) runModifyTelemetry() error {
 	c.logOperation("Modify Telemetry IDs")
 	fmt.Println("🔧 Modifying VS Code telemetry IDs...")
+	c.printLastRotation()
+	c.warnSettingsSync()
 
 	if c.config.DryRun {
 		fmt.Println("DRY RUN: Would modify telemetry IDs in VS Code storage")
@@ -293,10 +933,19 @@ func (c *CLI) runModifyTelemetry() error {
 		}
 	}
 
-	result, err := cleaner.ModifyTelemetryIDs()
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	opts, err := c.cleanerOptions()
+	if err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.ModifyTelemetryIDs(context.Background(), opts)
 	if err != nil {
 		c.logOperationResult("Modify Telemetry IDs", false, err.Error())
-		return fmt.Errorf("telemetry modification failed: %w", err)
+		return err
 	}
 
 	c.logOperationResult("Modify Telemetry IDs", true, "Telemetry IDs modified successfully")
@@ -311,12 +960,12 @@ func (c *CLI) runCleanDatabase() error {
 	fmt.Println("🗃️ Cleaning VS Code database...")
 
 	if c.config.DryRun {
-		count, err := cleaner.GetAugmentDataCount()
+		estimate, err := augmentcleaner.EstimateDatabaseClean(context.Background(), c.progressOptions())
 		if err != nil {
-			return fmt.Errorf("failed to count database records: %w", err)
+			return err
 		}
-		fmt.Printf("DRY RUN: Would delete %d database records\n", count)
-		c.logInfo("DRY RUN MODE: Would delete %d database records", count)
+		fmt.Printf("DRY RUN: Would delete %d database records, freeing %s\n", estimate.Records, utils.FormatBytes(estimate.Bytes))
+		c.logInfo("DRY RUN MODE: Would delete %d database records (%d bytes)", estimate.Records, estimate.Bytes)
 		return nil
 	}
 
@@ -327,26 +976,43 @@ func (c *CLI) runCleanDatabase() error {
 		}
 	}
 
-	result, err := cleaner.CleanAugmentData()
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.CleanDatabase(context.Background(), c.progressOptions())
 	if err != nil {
 		c.logOperationResult("Clean Database", false, err.Error())
-		return fmt.Errorf("database cleaning failed: %w", err)
+		return err
 	}
 
 	c.logOperationResult("Clean Database", true, fmt.Sprintf("Deleted %d records", result.DeletedRows))
-	c.logBackupCreated("database", result.DBBackupPath)
+	for _, backupPath := range result.DBBackupPaths {
+		c.logBackupCreated("database", backupPath)
+	}
 
 	return c.printResult("Database Cleaning", result)
 }
 
 // runCleanWorkspace executes the workspace cleaning operation
 func (c *CLI) runCleanWorkspace() error {
+	if c.config.OrphansOnly {
+		return c.runPruneOrphanedWorkspaces()
+	}
+
 	c.logOperation("Clean Workspace")
 	fmt.Println("💾 Cleaning VS Code workspace storage...")
 
 	if c.config.DryRun {
-		fmt.Println("DRY RUN: Would clean VS Code workspace storage")
-		c.logInfo("DRY RUN MODE: Would clean workspace storage")
+		estimate, err := augmentcleaner.EstimateWorkspaceClean(context.Background(), c.progressOptions())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("DRY RUN: Would delete %d workspace storage files, freeing %s\n", estimate.Files, utils.FormatBytes(estimate.Bytes))
+		if len(estimate.SkippedOpenWorkspaces) > 0 {
+			fmt.Printf("DRY RUN: Would skip %d workspaces open in VS Code (use --force to include them)\n", len(estimate.SkippedOpenWorkspaces))
+		}
+		c.logInfo("DRY RUN MODE: Would delete %d workspace storage files (%d bytes)", estimate.Files, estimate.Bytes)
 		return nil
 	}
 
@@ -357,220 +1023,936 @@ func (c *CLI) runCleanWorkspace() error {
 		}
 	}
 
-	result, err := cleaner.CleanWorkspaceStorage()
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.CleanWorkspace(context.Background(), c.progressOptions())
 	if err != nil {
 		c.logOperationResult("Clean Workspace", false, err.Error())
-		return fmt.Errorf("workspace cleaning failed: %w", err)
+		return err
 	}
 
 	c.logOperationResult("Clean Workspace", true, fmt.Sprintf("Deleted %d files", result.DeletedFilesCount))
-	c.logBackupCreated("workspace", result.BackupPath)
+	for _, backupPath := range result.BackupPaths {
+		c.logBackupCreated("workspace", backupPath)
+	}
+	c.warnSkippedOpenWorkspaces(result)
 
 	return c.printResult("Workspace Cleaning", result)
 }
 
-// runCleanBrowser executes the browser cleaning operation
-func (c *CLI) runCleanBrowser() error {
-	c.logOperation("Clean Browser Data")
-	fmt.Println("🌐 Cleaning browser data...")
+// runPruneOrphanedWorkspaces removes workspace storage whose folders no longer exist
+func (c *CLI) runPruneOrphanedWorkspaces() error {
+	c.logOperation("Prune Orphaned Workspaces")
+	fmt.Println("💾 Pruning orphaned VS Code workspace storage...")
 
 	if c.config.DryRun {
-		browserCleaner, err := browser.NewBrowserCleaner()
+		orphans, err := augmentcleaner.FindOrphanedWorkspaces(context.Background(), c.progressOptions())
 		if err != nil {
-			return fmt.Errorf("failed to create browser cleaner: %w", err)
+			return err
 		}
 
-		counts, err := browserCleaner.GetBrowserDataCount()
-		if err != nil {
-			return fmt.Errorf("failed to count browser data: %w", err)
-		}
-
-		totalCount := int64(0)
-		for _, count := range counts {
-			totalCount += count
+		var totalSize int64
+		for _, orphan := range orphans {
+			fmt.Printf("DRY RUN: Would prune %s (%s, %s)\n", orphan.WorkspaceHash, orphan.WorkspacePath, utils.FormatBytes(orphan.SizeBytes))
+			totalSize += orphan.SizeBytes
 		}
-
-		fmt.Printf("DRY RUN: Would clean %d browser data items\n", totalCount)
-		c.logInfo("DRY RUN MODE: Would clean %d browser data items", totalCount)
+		fmt.Printf("DRY RUN: Would prune %d orphaned workspaces, freeing %s\n", len(orphans), utils.FormatBytes(totalSize))
+		c.logInfo("DRY RUN MODE: Would prune %d orphaned workspaces", len(orphans))
 		return nil
 	}
 
 	if !c.config.NoConfirm {
-		fmt.Println("⚠️  WARNING: Please close all browsers before proceeding.")
-		fmt.Println("This operation will clean:")
-		fmt.Println("  • Augment-related cookies and domains")
-		fmt.Println("  • Local storage data containing Augment patterns")
-		fmt.Println("  • Session storage with Augment identifiers")
-		fmt.Println("  • Cache files with Augment references")
-		fmt.Println()
-
-		if !c.confirmOperation("clean browser data") {
+		if !c.confirmOperation("remove workspace storage of deleted folders") {
 			fmt.Println("Operation cancelled by user")
 			return nil
 		}
 	}
 
-	browserCleaner, err := browser.NewBrowserCleaner()
-	if err != nil {
-		c.logOperationResult("Clean Browser Data", false, err.Error())
-		return fmt.Errorf("browser cleaner creation failed: %w", err)
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
 	}
 
-	results, err := browserCleaner.CleanBrowserData(c.config.CreateBackups)
+	result, err := augmentcleaner.PruneOrphanedWorkspaces(context.Background(), c.progressOptions())
 	if err != nil {
-		c.logOperationResult("Clean Browser Data", false, err.Error())
-		return fmt.Errorf("browser cleaning failed: %w", err)
+		c.logOperationResult("Prune Orphaned Workspaces", false, err.Error())
+		return err
 	}
 
-	// Process results
-	totalCookies := int64(0)
-	totalStorage := int64(0)
-	totalCache := int64(0)
-	var allErrors []string
-
-	for _, result := range results {
-		totalCookies += result.CookiesDeleted
-		totalStorage += result.StorageDeleted
-		totalCache += result.CacheDeleted
-
-		if result.BackupPath != "" {
-			c.logBackupCreated("browser-"+result.Profile.Name, result.BackupPath)
-		}
-
-		for _, err := range result.Errors {
-			allErrors = append(allErrors, fmt.Sprintf("%s: %s", result.Profile.Name, err))
+	c.logOperationResult("Prune Orphaned Workspaces", true,
+		fmt.Sprintf("Pruned %d workspaces, reclaimed %d bytes", len(result.PrunedWorkspaces), result.ReclaimedBytes))
+	for _, pruned := range result.PrunedWorkspaces {
+		if pruned.BackupPath != "" {
+			c.logBackupCreated(pruned.StoragePath, pruned.BackupPath)
 		}
 	}
 
-	// Log results
-	successMsg := fmt.Sprintf("Cleaned %d cookies, %d storage items, %d cache items", totalCookies, totalStorage, totalCache)
-	c.logOperationResult("Clean Browser Data", len(allErrors) == 0, successMsg)
-
-	// Log any errors
-	for _, err := range allErrors {
-		c.logError("Browser cleaning error: %s", err)
-	}
-
-	return c.printResult("Browser Cleaning", results)
+	return c.printResult("Orphaned Workspace Pruning", result)
 }
 
-// runAllOperations executes all cleaning operations in sequence
-func (c *CLI) runAllOperations() error {
-	c.logOperation("Run All Operations")
-	fmt.Println("🚀 Running all cleaning operations...")
+// runCleanSecretStore removes Augment tokens from the OS secret store
+func (c *CLI) runCleanSecretStore() error {
+	c.logOperation("Clean Secret Store")
+	fmt.Println("🔑 Cleaning Augment secrets from the OS secret store...")
 
 	if c.config.DryRun {
-		fmt.Println("DRY RUN: Would run all cleaning operations")
-		c.logInfo("DRY RUN MODE: Would run all operations")
+		entries, err := augmentcleaner.ScanSecretStore(context.Background(), c.progressOptions())
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			fmt.Printf("DRY RUN: Would remove %s/%s\n", entry.ServiceName, entry.AccountName)
+		}
+		fmt.Printf("DRY RUN: Would remove %d secrets\n", len(entries))
+		c.logInfo("DRY RUN MODE: Would remove %d secrets", len(entries))
 		return nil
 	}
 
 	if !c.config.NoConfirm {
-		fmt.Println("This will run all cleaning operations:")
-		fmt.Println("  1. Modify telemetry IDs")
-		fmt.Println("  2. Clean database")
-		fmt.Println("  3. Clean workspace")
-		fmt.Println("  4. Clean browser data")
-		fmt.Println()
-
-		if !c.confirmOperation("run all cleaning operations") {
+		if !c.confirmOperation("remove Augment secrets from the OS secret store") {
 			fmt.Println("Operation cancelled by user")
 			return nil
 		}
 	}
 
-	operations := []struct {
-		name string
-		fn   func() error
-	}{
-		{"Modify Telemetry IDs", c.runModifyTelemetryInternal},
-		{"Clean Database", c.runCleanDatabaseInternal},
-		{"Clean Workspace", c.runCleanWorkspaceInternal},
-		{"Clean Browser Data", c.runCleanBrowserInternal},
+	result, err := augmentcleaner.CleanSecretStore(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Clean Secret Store", false, err.Error())
+		return err
 	}
 
-	for i, op := range operations {
-		fmt.Printf("Step %d/4: %s...\n", i+1, op.name)
-		if err := op.fn(); err != nil {
-			c.logError("Operation failed: %s - %v", op.name, err)
-			fmt.Printf("❌ %s failed: %v\n", op.name, err)
-			continue
-		}
-		fmt.Printf("✅ %s completed\n", op.name)
-	}
+	c.logOperationResult("Clean Secret Store", true, fmt.Sprintf("Removed %d secrets", len(result.RemovedEntries)))
 
-	fmt.Println("\n🎉 All operations completed!")
-	c.logOperationResult("Run All Operations", true, "All operations completed")
-	return nil
+	return c.printResult("Secret Store Cleaning", result)
 }
 
-// Internal operation methods (without confirmation prompts)
-func (c *CLI) runModifyTelemetryInternal() error {
-	return c.executeOperation("Telemetry modification", func() (interface{}, error) {
-		result, err := cleaner.ModifyTelemetryIDs()
-		if err == nil && result != nil {
-			c.logBackupCreated("storage.json", result.StorageBackupPath)
+// runCleanExtensions cleans the storage of the --extension-ids extensions, or of
+// every extension at medium risk or above without the flag
+func (c *CLI) runCleanExtensions() error {
+	c.logOperation("Clean Extensions")
+	fmt.Println("🧩 Cleaning extension storage...")
+
+	var extensionIDs []string
+	for _, id := range strings.Split(c.config.ExtensionIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			extensionIDs = append(extensionIDs, id)
 		}
-		return result, err
-	})
+	}
+
+	policy := augmentcleaner.DefaultRemovalPolicy()
+	policy.CreateBackups = c.config.CreateBackups && !c.config.DryRun
+	policy.DryRun = c.config.DryRun
+
+	if !c.config.DryRun && !c.config.NoConfirm {
+		target := "every extension at medium risk or above"
+		if len(extensionIDs) > 0 {
+			target = strings.Join(extensionIDs, ", ")
+		}
+		if !c.confirmOperation("clean the storage of " + target) {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.CleanExtensions(context.Background(), c.progressOptions(), extensionIDs, policy)
+	if err != nil {
+		c.logOperationResult("Clean Extensions", false, err.Error())
+		return err
+	}
+
+	if c.config.DryRun {
+		for _, id := range sortedExtensionIDs(result) {
+			extension := result.PerExtension[id]
+			fmt.Printf("DRY RUN: Would remove %d items (%s) from %s\n", extension.ItemsRemoved, utils.FormatBytes(extension.TotalSizeRemoved), id)
+		}
+		fmt.Printf("DRY RUN: Would remove %d items from %d extensions, freeing %s\n",
+			result.TotalItemsRemoved, len(result.PerExtension), utils.FormatBytes(result.TotalSizeRemoved))
+		c.logInfo("DRY RUN MODE: Would remove %d items from %d extensions", result.TotalItemsRemoved, len(result.PerExtension))
+		return nil
+	}
+
+	c.logOperationResult("Clean Extensions", len(result.FailedExtensions) == 0,
+		fmt.Sprintf("Removed %d items from %d extensions", result.TotalItemsRemoved, len(result.PerExtension)))
+
+	return c.printResult("Extension Cleaning", result)
+}
+
+// runCleanAugmentExtension removes telemetry keys from the global storage of the
+// Augment extension, or of --extension
+func (c *CLI) runCleanAugmentExtension() error {
+	c.logOperation("Clean Augment Extension")
+	fmt.Println("🧩 Cleaning Augment extension storage...")
+
+	req := augmentcleaner.AugmentStorageCleanRequest{ExtensionID: c.config.ExtensionID}
+	if c.config.PreserveKeys != "" {
+		req.PreserveKeys = []string{}
+		for _, key := range strings.Split(c.config.PreserveKeys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				req.PreserveKeys = append(req.PreserveKeys, key)
+			}
+		}
+	}
+
+	if c.config.DryRun || !c.config.NoConfirm {
+		req.DryRun = true
+		preview, err := augmentcleaner.CleanAugmentExtension(context.Background(), c.progressOptions(), req)
+		if err != nil {
+			return err
+		}
+		for _, key := range preview.KeysRemoved {
+			fmt.Printf("  %s: %s (%s)\n", key.File, key.Key, key.Risk)
+		}
+
+		if c.config.DryRun {
+			fmt.Printf("DRY RUN: Would remove %d telemetry keys and delete %d files\n", len(preview.KeysRemoved), len(preview.FilesDeleted))
+			c.logInfo("DRY RUN MODE: Would remove %d telemetry keys from %s", len(preview.KeysRemoved), preview.StoragePath)
+			return nil
+		}
+		if len(preview.KeysRemoved) == 0 {
+			fmt.Println("No telemetry keys to remove")
+			return nil
+		}
+		if !c.confirmOperation("remove these keys from " + preview.StoragePath) {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+		req.DryRun = false
+	}
+
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.CleanAugmentExtension(context.Background(), c.progressOptions(), req)
+	if err != nil {
+		c.logOperationResult("Clean Augment Extension", false, err.Error())
+		return err
+	}
+
+	if result.BackupPath != "" {
+		c.logBackupCreated(result.StoragePath, result.BackupPath)
+	}
+	c.logOperationResult("Clean Augment Extension", len(result.Errors) == 0,
+		fmt.Sprintf("Removed %d telemetry keys and deleted %d files", len(result.KeysRemoved), len(result.FilesDeleted)))
+
+	return c.printResult("Augment Extension Cleaning", result)
+}
+
+// sortedExtensionIDs returns the extension IDs of a bulk clean result in order
+func sortedExtensionIDs(result *augmentcleaner.BulkCleanResult) []string {
+	ids := make([]string, 0, len(result.PerExtension))
+	for id := range result.PerExtension {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// runCleanBrowser executes the browser cleaning operation
+func (c *CLI) runCleanBrowser() error {
+	c.logOperation("Clean Browser Data")
+	fmt.Println("🌐 Cleaning browser data...")
+
+	if err := c.snapshotBrowserProfiles(); err != nil {
+		return err
+	}
+
+	if c.config.DryRun || !c.config.NoConfirm {
+		counts, err := augmentcleaner.CountBrowserData(context.Background(), c.progressOptions())
+		if err != nil {
+			return err
+		}
+		totalCount, totalBytes := c.printBrowserPreview(counts)
+
+		if c.config.DryRun {
+			fmt.Printf("DRY RUN: Would clean %d browser data items, freeing %s\n", totalCount, utils.FormatBytes(totalBytes))
+			c.logInfo("DRY RUN MODE: Would clean %d browser data items (%d bytes)", totalCount, totalBytes)
+			return nil
+		}
+	}
+
+	if !c.config.NoConfirm {
+		fmt.Println("⚠️  WARNING: Please close all browsers before proceeding.")
+		fmt.Println("This operation will clean:")
+		fmt.Println("  • Augment-related cookies and domains")
+		fmt.Println("  • Local storage data containing Augment patterns")
+		fmt.Println("  • Session storage with Augment identifiers")
+		fmt.Println("  • Cache files with Augment references")
+		fmt.Println()
+
+		if !c.confirmOperation("clean browser data") {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	results, err := augmentcleaner.CleanBrowsers(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Clean Browser Data", false, err.Error())
+		return err
+	}
+
+	// Process results
+	totalCookies := int64(0)
+	totalStorage := int64(0)
+	totalCache := int64(0)
+	var allErrors []string
+
+	for _, result := range results {
+		totalCookies += result.CookiesDeleted
+		totalStorage += result.StorageDeleted
+		totalCache += result.CacheDeleted
+
+		if result.BackupPath != "" {
+			c.logBackupCreated("browser-"+result.Profile.Name, result.BackupPath)
+		}
+
+		for _, err := range result.Errors {
+			allErrors = append(allErrors, fmt.Sprintf("%s: %s", result.Profile.Name, err))
+		}
+	}
+
+	// Log results
+	successMsg := fmt.Sprintf("Cleaned %d cookies, %d storage items, %d cache items", totalCookies, totalStorage, totalCache)
+	c.logOperationResult("Clean Browser Data", len(allErrors) == 0, successMsg)
+
+	// Log any errors
+	for _, err := range allErrors {
+		c.logError("Browser cleaning error: %s", err)
+	}
+
+	return c.printResult("Browser Cleaning", results)
+}
+
+// runListProcesses lists the running processes clean-browser would close
+func (c *CLI) runListProcesses() error {
+	c.logOperation("List Browser Processes")
+	fmt.Println("🔎 Listing browser processes...")
+
+	processes, err := augmentcleaner.ListBrowserProcesses(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("List Browser Processes", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("List Browser Processes", true, fmt.Sprintf("Matched %d processes", len(processes)))
+
+	return c.printResult("Browser Processes", processes)
+}
+
+// runSuggestSettings scores the VS Code settings for privacy and prints the recommended changes
+func (c *CLI) runSuggestSettings() error {
+	c.logOperation("Suggest Settings")
+	fmt.Println("🔒 Analyzing VS Code privacy settings...")
+
+	suggestion, err := augmentcleaner.SuggestSettings(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Suggest Settings", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Suggest Settings", true, fmt.Sprintf("Privacy score %d/%d", suggestion.CurrentScore, suggestion.PotentialScore))
+
+	return c.printResult("Privacy Optimization Opportunities", suggestion)
+}
+
+// runTestPattern prints the pattern rules that fire for --value or --file
+func (c *CLI) runTestPattern() error {
+	c.logOperation("Test Pattern")
+	fmt.Println("🧪 Testing telemetry patterns...")
+
+	req := augmentcleaner.PatternTestRequest{Value: c.config.PatternValue, File: c.config.PatternFile}
+	result, err := augmentcleaner.TestPattern(context.Background(), c.progressOptions(), req)
+	if err != nil {
+		c.logOperationResult("Test Pattern", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Test Pattern", true, fmt.Sprintf("%d pattern rules matched", len(result.Matches)))
+
+	return c.printResult("Pattern Test", result)
+}
+
+// runHistory prints the most recent operations from the history file
+func (c *CLI) runHistory() error {
+	c.logOperation("History")
+	fmt.Println("📜 Reading operation history...")
+
+	records, err := augmentcleaner.History(context.Background(), c.progressOptions(), c.config.HistoryLast)
+	if err != nil {
+		c.logOperationResult("History", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("History", true, fmt.Sprintf("Read %d records", len(records)))
+
+	return c.printResult("History", records)
+}
+
+// runValidate checks the config file and the paths the operation would use
+// without performing it. Any failure makes the CLI exit with exitFailure.
+func (c *CLI) runValidate() error {
+	fmt.Println("🔍 Validating configuration and paths...")
+	cfg := c.configManager.GetConfig()
+	opts := c.progressOptions()
+
+	req := augmentcleaner.ValidationRequest{Browser: c.config.TargetBrowser}
+	switch c.config.Operation {
+	case OpScan, OpSuggestSettings:
+		req.ReadPaths = true
+	case OpModifyTelemetry, OpCleanDatabase, OpCleanWorkspace, OpQuickClean:
+		req.WritePaths = true
+	case OpCleanBrowser, OpListProcesses:
+		req.Browsers = true
+	case OpRunAll, "":
+		req.ReadPaths = true
+		req.WritePaths = true
+		req.Browsers = true
+	}
+	if c.config.CreateBackups && (req.WritePaths || c.config.Operation == OpCleanBrowser) {
+		req.BackupDirs = append(req.BackupDirs, cfg.BackupDirectory, opts.BrowserBackupDir)
+	}
+
+	failures := c.configManager.Validate()
+	pathFailures, err := augmentcleaner.Validate(context.Background(), opts, req)
+	if err != nil {
+		return err
+	}
+	seen := make(map[augmentcleaner.ValidationFailure]bool)
+	for _, failure := range failures {
+		seen[failure] = true
+	}
+	for _, failure := range pathFailures {
+		// The backup directory is checked by both the config and the path validation
+		if !seen[failure] {
+			failures = append(failures, failure)
+		}
+	}
+
+	if err := c.printResult("Validation", failures); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("validation failed: %d checks failed", len(failures))
+	}
+	return nil
+}
+
+// runSelfTest validates every cleaner against a sandbox fixture without touching user data
+func (c *CLI) runSelfTest() error {
+	c.logOperation("Self-Test")
+	fmt.Println("🧪 Running cleaners against a sandbox...")
+
+	results, err := augmentcleaner.SelfTest(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Self-Test", false, err.Error())
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == augmentcleaner.SelfTestFail {
+			failed++
+		}
+	}
+
+	if err := c.printResult("Self-Test", results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		c.logOperationResult("Self-Test", false, fmt.Sprintf("%d modules failed", failed))
+		return fmt.Errorf("self-test failed: %d of %d modules failed", failed, len(results))
+	}
+
+	c.logOperationResult("Self-Test", true, fmt.Sprintf("%d modules checked", len(results)))
+	return nil
+}
+
+// printLastRotation tells the user how long ago telemetry IDs were last rotated
+func (c *CLI) printLastRotation() {
+	record, err := augmentcleaner.LastSuccessfulRun(context.Background(), c.progressOptions(), OpModifyTelemetry)
+	if err != nil {
+		c.log("WARN", "Failed to read operation history: %v", err)
+		return
+	}
+	if record == nil {
+		fmt.Println("ℹ️  No previous ID rotation recorded")
+		return
+	}
+
+	days := int(time.Since(record.Timestamp).Hours() / 24)
+	fmt.Printf("ℹ️  IDs last rotated %d days ago (%s)\n", days, record.Timestamp.Format("2006-01-02 15:04"))
+}
+
+// warnSettingsSync warns that VS Code Settings Sync may restore the rotated IDs,
+// unless --update-sync rotates them in the sync metadata too
+func (c *CLI) warnSettingsSync() {
+	state, err := augmentcleaner.DetectSettingsSync(context.Background(), c.progressOptions())
+	if err != nil || !state.Detected() {
+		return
+	}
+	if c.config.UpdateSync {
+		fmt.Println("ℹ️  Settings Sync detected: the new IDs will also be written to its metadata")
+		return
+	}
+	fmt.Println("⚠️  Settings Sync detected: the next sync may restore the old telemetry IDs from another device")
+	fmt.Println("   Turn it off in VS Code first, or use --update-sync to update its metadata too")
+	c.log("WARN", "Settings Sync detected; telemetry IDs may be reverted by the next sync")
 }
 
-func (c *CLI) runCleanDatabaseInternal() error {
-	return c.executeOperation("Database cleaning", func() (interface{}, error) {
-		result, err := cleaner.CleanAugmentData()
-		if err == nil && result != nil {
-			c.logInfo("Database cleaned successfully, deleted %d records", result.DeletedRows)
-			c.logBackupCreated("database", result.DBBackupPath)
+// runMigrateBackups upgrades legacy backup metadata to the current schema version
+func (c *CLI) runMigrateBackups() error {
+	c.logOperation("Migrate Backups")
+	fmt.Println("📦 Migrating backup metadata...")
+
+	backupManager := cleaner.NewBackupManager()
+
+	if c.config.DryRun {
+		count, err := backupManager.CountBackupsNeedingMigration()
+		if err != nil {
+			return fmt.Errorf("failed to inspect backups: %w", err)
+		}
+		fmt.Printf("DRY RUN: Would migrate %d backups to schema version %d\n", count, cleaner.CurrentBackupSchemaVersion)
+		c.logInfo("DRY RUN MODE: Would migrate %d backups", count)
+		return nil
+	}
+
+	report, err := backupManager.MigrateBackups()
+	if err != nil {
+		c.logOperationResult("Migrate Backups", false, err.Error())
+		return fmt.Errorf("backup migration failed: %w", err)
+	}
+
+	c.logOperationResult("Migrate Backups", report.Failed == 0,
+		fmt.Sprintf("Migrated %d, skipped %d, failed %d", report.Migrated, report.Skipped, report.Failed))
+
+	return c.printResult("Backup Migration", report)
+}
+
+// runRestoreVSCodeSettings reverts settings.json to a settings backup and prints
+// the settings that changed
+func (c *CLI) runRestoreVSCodeSettings() error {
+	c.logOperation("Restore VS Code Settings")
+	fmt.Println("⚙️  Restoring VS Code settings...")
+
+	if c.config.DryRun {
+		backup, err := augmentcleaner.FindSettingsBackup(context.Background(), c.progressOptions(), c.config.BackupID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("DRY RUN: Would restore %s from backup %s (created %s)\n",
+			backup.OriginalPath, backup.BackupID, backup.CreationTime.Format("2006-01-02 15:04:05"))
+		c.logInfo("DRY RUN MODE: Would restore settings from backup %s", backup.BackupID)
+		return nil
+	}
+
+	if !c.config.NoConfirm {
+		if !c.confirmOperation("restore VS Code settings.json from a backup") {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	result, err := augmentcleaner.RestoreVSCodeSettings(context.Background(), c.progressOptions(), c.config.BackupID)
+	if err != nil {
+		c.logOperationResult("Restore VS Code Settings", false, err.Error())
+		return err
+	}
+
+	if result.PreRestoreBackup != "" {
+		c.logBackupCreated(result.RestoredPath, result.PreRestoreBackup)
+	}
+	c.logOperationResult("Restore VS Code Settings", true,
+		fmt.Sprintf("Restored %d settings from backup %s", len(result.SettingsRestored), result.BackupUsed.BackupID))
+
+	return c.printResult("Restore VS Code Settings", result)
+}
+
+// runCleanSettings removes or resets Augment settings in settings.json. The
+// change is previewed as a unified diff before confirmation and in dry runs.
+func (c *CLI) runCleanSettings() error {
+	c.logOperation("Clean VS Code Settings")
+	fmt.Println("⚙️  Cleaning Augment settings...")
+
+	req := augmentcleaner.SettingsCleanRequest{ResetToDefaults: c.config.ResetSettings}
+	for _, key := range strings.Split(c.config.SettingsKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			req.Keys = append(req.Keys, key)
+		}
+	}
+
+	if c.config.DryRun || !c.config.NoConfirm {
+		req.DryRun = true
+		preview, err := augmentcleaner.CleanVSCodeSettings(context.Background(), c.progressOptions(), req)
+		if err != nil {
+			return err
+		}
+		c.printSettingsPreview(preview)
+
+		if c.config.DryRun {
+			fmt.Printf("DRY RUN: Would remove %d and reset %d Augment settings\n", len(preview.SettingsRemoved), len(preview.SettingsReset))
+			c.logInfo("DRY RUN MODE: Would remove %d and reset %d Augment settings", len(preview.SettingsRemoved), len(preview.SettingsReset))
+			return nil
+		}
+		if preview.Diff == "" {
+			fmt.Println("No Augment settings to clean")
+			return nil
+		}
+		if !c.confirmOperation("change the Augment settings in " + preview.SettingsPath) {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+		req.DryRun = false
+	}
+
+	result, err := augmentcleaner.CleanVSCodeSettings(context.Background(), c.progressOptions(), req)
+	if err != nil {
+		c.logOperationResult("Clean VS Code Settings", false, err.Error())
+		return err
+	}
+
+	if result.BackupPath != "" {
+		c.logBackupCreated(result.SettingsPath, result.BackupPath)
+	}
+	c.logOperationResult("Clean VS Code Settings", true,
+		fmt.Sprintf("Removed %d and reset %d Augment settings", len(result.SettingsRemoved), len(result.SettingsReset)))
+
+	return c.printResult("Clean VS Code Settings", result)
+}
+
+// printSettingsPreview prints the settings.json diff of a clean-settings dry run
+// and the selected settings it leaves alone
+func (c *CLI) printSettingsPreview(preview *augmentcleaner.CleanVSCodeSettingsResult) {
+	if preview.Diff != "" {
+		fmt.Printf("\n%s\n", preview.Diff)
+	}
+	for _, key := range preview.Protected {
+		fmt.Printf("  Keeping protected setting %s\n", key)
+	}
+	for _, key := range preview.NotFound {
+		fmt.Printf("  No setting matches %s\n", key)
+	}
+}
+
+// settingValue formats a settings.json value for the restore diff as JSON,
+// masking values of sensitive keys such as tokens
+func settingValue(key string, value interface{}) string {
+	data, err := json.Marshal(sanitize.KeyValue(key, value))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// runBackupStats summarizes the disk space and coverage of extension backups
+func (c *CLI) runBackupStats() error {
+	c.logOperation("Backup Stats")
+	fmt.Println("📦 Summarizing backups...")
+
+	stats, err := augmentcleaner.GetBackupStats(context.Background(), c.progressOptions())
+	if err != nil {
+		c.logOperationResult("Backup Stats", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Backup Stats", true, fmt.Sprintf("%d backups, %d bytes", stats.TotalBackups, stats.TotalSizeBytes))
+
+	return c.printResult("Backup Statistics", stats)
+}
+
+// runListBackups prints the most recent or largest extension backups
+func (c *CLI) runListBackups() error {
+	c.logOperation("List Backups")
+	fmt.Println("📦 Listing backups...")
+
+	page, err := augmentcleaner.ListBackupsPage(context.Background(), c.progressOptions(), 0, c.config.HistoryLast, c.config.BackupSort)
+	if err != nil {
+		c.logOperationResult("List Backups", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("List Backups", true, fmt.Sprintf("Listed %d of %d backups", len(page.Backups), page.Total))
+
+	return c.printResult("Backups", page)
+}
+
+// runAllOperations executes all cleaning operations in sequence
+func (c *CLI) runAllOperations() error {
+	c.logOperation("Run All Operations")
+	fmt.Println("🚀 Running all cleaning operations...")
+	c.warnSettingsSync()
+
+	if c.config.DryRun {
+		estimate, err := augmentcleaner.EstimateRunAll(context.Background(), c.progressOptions())
+		if err != nil {
+			return err
+		}
+		fmt.Println("DRY RUN: Would modify telemetry IDs")
+		if estimate.Database != nil {
+			fmt.Printf("DRY RUN: Would delete %d database records (%s)\n", estimate.Database.Records, utils.FormatBytes(estimate.Database.Bytes))
+		}
+		if estimate.Workspace != nil {
+			fmt.Printf("DRY RUN: Would delete %d workspace storage files (%s)\n", estimate.Workspace.Files, utils.FormatBytes(estimate.Workspace.Bytes))
+		}
+		if estimate.Browsers != nil {
+			totalCount, totalBytes := c.printBrowserPreview(estimate.Browsers)
+			fmt.Printf("DRY RUN: Would clean %d browser data items (%s)\n", totalCount, utils.FormatBytes(totalBytes))
+		}
+		for _, estimateErr := range estimate.Errors {
+			fmt.Printf("DRY RUN: Could not estimate %s\n", estimateErr)
+		}
+		fmt.Printf("DRY RUN: Would run all cleaning operations, freeing %s\n", utils.FormatBytes(estimate.TotalBytes))
+		c.logInfo("DRY RUN MODE: Would run all operations (%d bytes)", estimate.TotalBytes)
+		return nil
+	}
+
+	if !c.config.NoConfirm {
+		fmt.Println("This will run all cleaning operations:")
+		fmt.Println("  1. Modify telemetry IDs")
+		fmt.Println("  2. Clean database")
+		fmt.Println("  3. Clean workspace")
+		fmt.Println("  4. Clean browser data")
+		fmt.Println()
+
+		if !c.confirmOperation("run all cleaning operations") {
+			fmt.Println("Operation cancelled by user")
+			return nil
+		}
+	}
+
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	opts, err := c.cleanerOptions()
+	if err != nil {
+		return err
+	}
+	// Pin the browser profiles like clean-browser; if detection fails here, the
+	// browser step detects them itself and reports the failure
+	if err := c.snapshotBrowserProfiles(); err != nil {
+		c.logError("Browser profile detection failed: %v", err)
+	} else {
+		opts.BrowserProfiles = c.browserProfiles
+	}
+
+	result, err := augmentcleaner.RunAll(context.Background(), opts)
+	if result == nil {
+		c.logOperationResult("Run All Operations", false, err.Error())
+		return err
+	}
+
+	if result.Telemetry != nil {
+		c.logBackupCreated("storage.json", result.Telemetry.StorageBackupPath)
+	}
+	if result.Database != nil {
+		for _, backupPath := range result.Database.DBBackupPaths {
+			c.logBackupCreated("database", backupPath)
 		}
-		return result, err
-	})
-}
+	}
+	if result.Workspace != nil {
+		for _, backupPath := range result.Workspace.BackupPaths {
+			c.logBackupCreated("workspace", backupPath)
+		}
+		c.warnSkippedOpenWorkspaces(result.Workspace)
+	}
+	for _, profile := range result.Browsers {
+		if profile.BackupPath != "" {
+			c.logBackupCreated("browser-"+profile.Profile.Name, profile.BackupPath)
+		}
+	}
 
-func (c *CLI) runCleanWorkspaceInternal() error {
-	return c.executeOperation("Workspace cleaning", func() (interface{}, error) {
-		result, err := cleaner.CleanWorkspaceStorage()
-		if err == nil && result != nil {
-			c.logInfo("Workspace cleaned successfully, deleted %d files", result.DeletedFilesCount)
-			c.logBackupCreated("workspace", result.BackupPath)
+	failed := result.Failed()
+	for _, step := range failed {
+		c.logError("Operation failed: %s - %s", step.Step, step.Error)
+		if augmentcleaner.IsPermissionError(step.Err) {
+			c.notePermissionDenied(augmentcleaner.PermissionDeniedPath(step.Err))
 		}
-		return result, err
-	})
+	}
+
+	if len(failed) > 0 {
+		summary := fmt.Sprintf("%d of %d steps failed", len(failed), len(result.Steps))
+		c.logOperationResult("Run All Operations", false, summary)
+		fmt.Printf("\n⚠️  Run All Operations finished, %s\n", summary)
+		return c.printResultDetails(result)
+	}
+
+	c.logOperationResult("Run All Operations", true, fmt.Sprintf("All %d steps completed in %s",
+		len(result.Steps), result.Duration.Round(time.Millisecond)))
+	return c.printResult("Run All Operations", result)
 }
 
-func (c *CLI) runCleanBrowserInternal() error {
-	return c.executeOperation("Browser cleaning", func() (interface{}, error) {
-		browserCleaner, err := browser.NewBrowserCleaner()
+// runQuickClean cleans the database and rotates the telemetry IDs only, skipping
+// the slower workspace and browser cleaning
+func (c *CLI) runQuickClean() error {
+	c.logOperation("Quick Clean")
+	fmt.Println("⚡ Running quick clean (database and telemetry IDs)...")
+	c.warnSettingsSync()
+
+	if c.config.DryRun {
+		estimate, err := augmentcleaner.EstimateDatabaseClean(context.Background(), c.progressOptions())
 		if err != nil {
-			return nil, err
+			return err
+		}
+		fmt.Printf("DRY RUN: Would delete %d database records, freeing %s, and modify telemetry IDs\n",
+			estimate.Records, utils.FormatBytes(estimate.Bytes))
+		c.logInfo("DRY RUN MODE: Would delete %d database records (%d bytes) and modify telemetry IDs", estimate.Records, estimate.Bytes)
+		return nil
+	}
+
+	if !c.config.NoConfirm {
+		if !c.confirmOperation("clean the VS Code database and modify telemetry IDs") {
+			fmt.Println("Operation cancelled by user")
+			return nil
 		}
+	}
 
-		results, err := browserCleaner.CleanBrowserData(c.config.CreateBackups)
-		if err == nil && results != nil {
-			// Count total items cleaned and log backups
-			totalItems := int64(0)
-			for _, result := range results {
-				totalItems += result.CookiesDeleted + result.StorageDeleted + result.CacheDeleted
-				if result.BackupPath != "" {
-					c.logBackupCreated("browser-"+result.Profile.Name, result.BackupPath)
-				}
+	if err := c.blockTelemetryNetwork(); err != nil {
+		return err
+	}
+
+	opts, err := c.cleanerOptions()
+	if err != nil {
+		return err
+	}
+
+	result, err := augmentcleaner.QuickClean(context.Background(), opts)
+	if result != nil {
+		if result.Database != nil {
+			for _, backupPath := range result.Database.DBBackupPaths {
+				c.logBackupCreated("database", backupPath)
 			}
-			c.logInfo("Browser data cleaned successfully, processed %d items", totalItems)
 		}
-		return results, err
-	})
+		if result.Telemetry != nil {
+			c.logBackupCreated("storage.json", result.Telemetry.StorageBackupPath)
+		}
+	}
+	if err != nil {
+		c.logOperationResult("Quick Clean", false, err.Error())
+		return err
+	}
+
+	c.logOperationResult("Quick Clean", true, fmt.Sprintf("Deleted %d records and modified telemetry IDs in %s",
+		result.Database.DeletedRows, result.Duration.Round(time.Millisecond)))
+
+	return c.printResult("Quick Clean", result)
+}
+
+// warnSkippedOpenWorkspaces warns about workspaces kept because they are open in VS Code
+func (c *CLI) warnSkippedOpenWorkspaces(result *augmentcleaner.WorkspaceCleanResult) {
+	for _, hash := range result.SkippedOpenWorkspaces {
+		fmt.Printf("⚠️  Skipped workspace %s because it is open in VS Code (close it or use --force)\n", hash)
+		c.log("WARN", "Skipped open workspace: %s", hash)
+	}
 }
 
-// executeOperation is a helper method to reduce code duplication
-func (c *CLI) executeOperation(operationName string, operation func() (interface{}, error)) error {
-	_, err := operation()
+// snapshotBrowserProfiles detects browser profiles once per invocation, so the
+// preview counts and the clean that follows cover exactly the same profiles
+func (c *CLI) snapshotBrowserProfiles() error {
+	if c.browserProfiles != nil {
+		return nil
+	}
+
+	profiles, err := augmentcleaner.DetectBrowserProfiles(context.Background(), c.progressOptions())
 	if err != nil {
-		c.logError("%s failed: %v", operationName, err)
 		return err
 	}
-	c.logInfo("%s completed successfully", operationName)
+	if profiles == nil {
+		profiles = []augmentcleaner.BrowserProfile{}
+	}
+	c.browserProfiles = profiles
+	return nil
+}
+
+// printBrowserPreview prints the Augment data found per browser profile and returns
+// the total item count and size
+func (c *CLI) printBrowserPreview(counts []augmentcleaner.BrowserProfileCount) (int64, int64) {
+	var total, totalBytes int64
+	for _, count := range counts {
+		summary := fmt.Sprintf("%d cookies, %d storage items", count.Cookies, count.Storage)
+		if count.Extensions > 0 {
+			summary += fmt.Sprintf(", %d extension data items", count.Extensions)
+		}
+		if c.config.IncludeHistory {
+			summary += fmt.Sprintf(", %d history entries", count.History)
+		}
+		summary += fmt.Sprintf(" (%s)", utils.FormatBytes(count.Bytes))
+		fmt.Printf("  %s: %s\n", count.Profile.Name, summary)
+		c.logInfo("Preview %s (%s): %s", count.Profile.Name, count.Profile.ProfilePath, summary)
+		total += count.Total
+		totalBytes += count.Bytes
+	}
+	if len(counts) == 0 {
+		fmt.Println("  No browser profiles found")
+	}
+	return total, totalBytes
+}
+
+// allOperationsStepResult returns the result of one run-all step, or nil if the
+// step produced none
+func allOperationsStepResult(result *augmentcleaner.AllOperationsResult, step string) interface{} {
+	switch {
+	case step == augmentcleaner.StepModifyTelemetry && result.Telemetry != nil:
+		return result.Telemetry
+	case step == augmentcleaner.StepCleanDatabase && result.Database != nil:
+		return result.Database
+	case step == augmentcleaner.StepCleanWorkspace && result.Workspace != nil:
+		return result.Workspace
+	case step == augmentcleaner.StepCleanBrowser && result.Browsers != nil:
+		return result.Browsers
+	}
 	return nil
 }
 
+// collectPermissionDenied records the paths an operation result reports as
+// skipped for lack of access rights
+func (c *CLI) collectPermissionDenied(result interface{}) {
+	switch r := result.(type) {
+	case *augmentcleaner.AllOperationsResult:
+		for _, step := range r.Steps {
+			if stepResult := allOperationsStepResult(r, step.Step); stepResult != nil {
+				c.collectPermissionDenied(stepResult)
+			}
+		}
+	case *augmentcleaner.WorkspaceCleanResult:
+		c.notePermissionDenied(r.PermissionDenied...)
+	case *augmentcleaner.OrphanCleanResult:
+		c.notePermissionDenied(r.PermissionDenied...)
+	case []augmentcleaner.BrowserCleanResult:
+		for _, profile := range r {
+			c.notePermissionDenied(profile.PermissionDenied...)
+		}
+	}
+}
+
+// notePermissionDenied records paths that could not be cleaned for lack of access rights
+func (c *CLI) notePermissionDenied(paths ...string) {
+	for _, path := range paths {
+		c.log("WARN", "Permission denied: %s", path)
+	}
+	c.permissionDenied = append(c.permissionDenied, paths...)
+}
+
+// printPermissionDenied lists the paths skipped for lack of access rights with
+// what the user can do about it
+func (c *CLI) printPermissionDenied() {
+	fmt.Fprintf(os.Stderr, "\n⚠️  Permission denied for %d item(s):\n", len(c.permissionDenied))
+	for _, path := range c.permissionDenied {
+		fmt.Fprintf(os.Stderr, "    %s\n", path)
+	}
+	fmt.Fprintln(os.Stderr, "\n  To clean them:")
+	for _, hint := range augmentcleaner.PermissionRemediationHints() {
+		fmt.Fprintf(os.Stderr, "    - %s\n", hint)
+	}
+}
+
 // confirmOperation prompts the user for confirmation
 func (c *CLI) confirmOperation(operation string) bool {
 	fmt.Printf("Are you sure you want to %s? [y/N]: ", operation)
@@ -583,15 +1965,30 @@ func (c *CLI) confirmOperation(operation string) bool {
 // printResult prints the operation result
 func (c *CLI) printResult(operationName string, result interface{}) error {
 	fmt.Printf("\n✅ %s completed successfully!\n", operationName)
+	return c.printResultDetails(result)
+}
+
+// printResultDetails prints the result as text, JSON or YAML, without a status line
+func (c *CLI) printResultDetails(result interface{}) error {
+	c.collectPermissionDenied(result)
+	c.countResult(result)
 
-	if c.config.OutputFormat == "json" {
-		jsonData, err := json.MarshalIndent(result, "", "  ")
+	switch c.config.OutputFormat {
+	case "json":
+		jsonData, err := c.marshalJSON(result)
 		if err != nil {
-			return fmt.Errorf("failed to marshal result to JSON: %w", err)
+			return err
 		}
 		fmt.Println("\nResult Details (JSON):")
 		fmt.Println(string(jsonData))
-	} else {
+	case "yaml":
+		yamlData, err := jsonschema.MarshalYAMLDocuments(result, jsonSchemaVersion)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result to YAML: %w", err)
+		}
+		fmt.Println("\nResult Details (YAML):")
+		fmt.Print(string(yamlData))
+	default:
 		fmt.Println("\nResult Details:")
 		c.printTextResult(result)
 	}
@@ -602,46 +1999,166 @@ func (c *CLI) printResult(operationName string, result interface{}) error {
 // printTextResult prints the result in human-readable text format
 func (c *CLI) printTextResult(result interface{}) {
 	switch r := result.(type) {
-	case *cleaner.TelemetryModifyResult:
+	case *augmentcleaner.TelemetryModifyResult:
 		c.printField("Old Machine ID", r.OldMachineID)
 		c.printField("New Machine ID", r.NewMachineID)
 		c.printField("Old Device ID", r.OldDeviceID)
 		c.printField("New Device ID", r.NewDeviceID)
+		c.printField("Machine ID Format", r.IDFormat)
+		c.printFieldIf("VS Code Version", r.VSCodeVersion)
 		c.printFieldIf("Storage Backup", r.StorageBackupPath)
 		c.printFieldIf("Machine ID Backup", r.MachineIDBackupPath)
+		c.printFieldIf("Audit File", r.AuditFilePath)
+		c.printField("Settings Sync", r.SyncDetected)
+		if len(r.SyncFilesUpdated) > 0 {
+			c.printField("Sync Files Updated", len(r.SyncFilesUpdated))
+		}
+
+	case *augmentcleaner.QuickCleanResult:
+		if r.Database != nil {
+			c.printTextResult(r.Database)
+		}
+		if r.Telemetry != nil {
+			c.printTextResult(r.Telemetry)
+		}
+		c.printField("Duration", r.Duration.Round(time.Millisecond))
+
+	case *augmentcleaner.AllOperationsResult:
+		for _, step := range r.Steps {
+			switch {
+			case step.Skipped:
+				fmt.Printf("  ⏭️  %s skipped: %s\n", step.Step, step.Error)
+			case step.Error != "" && c.config.Verbose:
+				fmt.Printf("  ❌ %s failed after %s: %s\n", step.Step, step.Duration.Round(time.Millisecond), step.Error)
+			case step.Error != "":
+				fmt.Printf("  ❌ %s failed: %s\n", step.Step, step.Error)
+			default:
+				fmt.Printf("  ✅ %s (%s)\n", step.Step, step.Duration.Round(time.Millisecond))
+			}
+			if stepResult := allOperationsStepResult(r, step.Step); stepResult != nil {
+				c.printTextResult(stepResult)
+			}
+		}
+		c.printField("Total Freed", utils.FormatBytes(r.BytesFreed()))
+		c.printField("Duration", r.Duration.Round(time.Millisecond))
 
-	case *cleaner.DatabaseCleanResult:
+	case *augmentcleaner.DatabaseCleanResult:
 		c.printField("Records Deleted", r.DeletedRows)
-		c.printFieldIf("Database Backup", r.DBBackupPath)
+		c.printField("Space Freed", utils.FormatBytes(r.BytesFreed))
+		c.printField("Batches", r.BatchCount)
+		if r.LockRetries > 0 {
+			c.printField("Lock Retries", r.LockRetries)
+		}
+		for _, backupPath := range r.DBBackupPaths {
+			c.printField("Database Backup", backupPath)
+		}
 
-	case *cleaner.WorkspaceCleanResult:
+	case *augmentcleaner.WorkspaceCleanResult:
 		c.printField("Files Deleted", r.DeletedFilesCount)
-		c.printFieldIf("Workspace Backup", r.BackupPath)
+		c.printField("Space Freed", utils.FormatBytes(r.BytesFreed))
+		for _, backupPath := range r.BackupPaths {
+			c.printField("Workspace Backup", backupPath)
+		}
+		if len(r.SkippedOpenWorkspaces) > 0 {
+			c.printField("Open Workspaces Skipped", len(r.SkippedOpenWorkspaces))
+		}
+		if len(r.FailedOperations) > 0 {
+			c.printField("Failed Operations", len(r.FailedOperations))
+		}
+
+	case *augmentcleaner.OrphanCleanResult:
+		c.printField("Workspaces Pruned", len(r.PrunedWorkspaces))
+		c.printField("Space Freed", utils.FormatBytes(r.ReclaimedBytes))
+		for _, pruned := range r.PrunedWorkspaces {
+			fmt.Printf("    %s\n", pruned.WorkspacePath)
+		}
+		if len(r.FailedOperations) > 0 {
+			c.printField("Failed Operations", len(r.FailedOperations))
+		}
+
+	case *augmentcleaner.SecretStoreCleanResult:
+		c.printField("Secrets Removed", len(r.RemovedEntries))
+		for _, entry := range r.RemovedEntries {
+			fmt.Printf("    %s/%s\n", entry.ServiceName, entry.AccountName)
+		}
 		if len(r.FailedOperations) > 0 {
 			c.printField("Failed Operations", len(r.FailedOperations))
 		}
 
-	case []browser.BrowserCleanResult:
+	case *augmentcleaner.BulkCleanResult:
+		c.printField("Extensions Cleaned", len(r.PerExtension)-len(r.FailedExtensions))
+		c.printField("Items Removed", r.TotalItemsRemoved)
+		c.printField("Space Freed", utils.FormatBytes(r.TotalSizeRemoved))
+		for _, id := range sortedExtensionIDs(r) {
+			extension := r.PerExtension[id]
+			fmt.Printf("    %s: %d items, %s\n", id, extension.ItemsRemoved, utils.FormatBytes(extension.TotalSizeRemoved))
+			for _, message := range extension.Errors {
+				fmt.Printf("      ❌ %s\n", message)
+			}
+		}
+		if len(r.FailedExtensions) > 0 {
+			c.printField("Failed Extensions", strings.Join(r.FailedExtensions, ", "))
+		}
+
+	case []augmentcleaner.BrowserCleanResult:
 		totalCookies := int64(0)
 		totalStorage := int64(0)
 		totalCache := int64(0)
+		totalBytes := int64(0)
 		totalErrors := 0
 
 		for _, result := range r {
 			totalCookies += result.CookiesDeleted
 			totalStorage += result.StorageDeleted
 			totalCache += result.CacheDeleted
+			totalBytes += result.BytesFreed
 			totalErrors += len(result.Errors)
 
 			fmt.Printf("  Browser: %s (%s)\n", result.Profile.Name, result.Profile.Type.String())
 			fmt.Printf("    Cookies Deleted: %d\n", result.CookiesDeleted)
 			fmt.Printf("    Storage Items Deleted: %d\n", result.StorageDeleted)
 			fmt.Printf("    Cache Items Deleted: %d\n", result.CacheDeleted)
+			fmt.Printf("    Space Freed: %s\n", utils.FormatBytes(result.BytesFreed))
+			if result.IndexedDBDeleted > 0 {
+				fmt.Printf("    IndexedDB Databases Deleted: %d\n", result.IndexedDBDeleted)
+			}
+			if result.PermissionsDeleted > 0 {
+				fmt.Printf("    Site Permissions Removed: %d\n", result.PermissionsDeleted)
+			}
+			if result.HistoryDeleted > 0 {
+				fmt.Printf("    History Entries Deleted: %d\n", result.HistoryDeleted)
+			}
+			if result.PreferencesDeleted > 0 {
+				fmt.Printf("    Site Preferences Removed: %d\n", result.PreferencesDeleted)
+			}
+			if result.ExtensionDataDeleted > 0 {
+				fmt.Printf("    Extension Data Removed: %d\n", result.ExtensionDataDeleted)
+			}
+			if len(result.SuspiciousExtensions) > 0 {
+				fmt.Printf("    Suspicious Extensions (kept, use --aggressive): %s\n", strings.Join(result.SuspiciousExtensions, ", "))
+			}
 			if result.BackupPath != "" {
 				fmt.Printf("    Backup: %s\n", result.BackupPath)
 			}
+			if c.config.Verbose {
+				fmt.Printf("    Duration: %s\n", result.Duration.Round(time.Millisecond))
+			}
 			if len(result.Errors) > 0 {
 				fmt.Printf("    Errors: %d\n", len(result.Errors))
+				for _, err := range result.Errors {
+					fmt.Printf("      - %s\n", err)
+				}
+			}
+			for _, locked := range result.LockedFiles {
+				for _, process := range locked.LockedBy {
+					fmt.Printf("    %s held by %s\n", filepath.Base(locked.Path), process)
+				}
+			}
+			if len(result.PendingRebootDeletions) > 0 {
+				fmt.Printf("    Pending Deletion at Reboot: %d\n", len(result.PendingRebootDeletions))
+				for _, path := range result.PendingRebootDeletions {
+					fmt.Printf("      - %s\n", path)
+				}
 			}
 		}
 
@@ -649,16 +2166,442 @@ func (c *CLI) printTextResult(result interface{}) {
 		c.printField("    Total Cookies Deleted", totalCookies)
 		c.printField("    Total Storage Items Deleted", totalStorage)
 		c.printField("    Total Cache Items Deleted", totalCache)
+		c.printField("    Total Space Freed", utils.FormatBytes(totalBytes))
 		if totalErrors > 0 {
 			c.printField("    Total Errors", totalErrors)
 		}
 
+	case []augmentcleaner.BrowserProcess:
+		c.printField("Matched Processes", len(r))
+		for _, process := range r {
+			fmt.Printf("  %s: %s\n", process.Browser, process)
+		}
+
+	case *scanner.SettingsSuggestion:
+		c.printSettingsSuggestion(r)
+
+	case []augmentcleaner.HistoryRecord:
+		c.printField("Operations", len(r))
+		for _, record := range r {
+			status := "✅"
+			if !record.Success {
+				status = "❌"
+			}
+			fmt.Printf("\n  %s %s  %s\n", status, record.Timestamp.Format("2006-01-02 15:04:05"), record.Operation)
+			c.printFieldIf("  Summary", record.Summary)
+			for _, backup := range record.BackupIDs {
+				c.printField("  Backup", backup)
+			}
+			for _, msg := range record.Errors {
+				c.printField("  Error", msg)
+			}
+			if c.config.Verbose && len(record.Options) > 0 {
+				c.printField("  Options", record.Options)
+			}
+		}
+
+	case []augmentcleaner.ValidationFailure:
+		if len(r) == 0 {
+			fmt.Println("  ✅ Validation OK")
+			break
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "  CHECK\tPATH\tPROBLEM")
+		for _, failure := range r {
+			fmt.Fprintf(writer, "  %s\t%s\t%s\n", failure.Check, failure.Path, failure.Problem)
+		}
+		writer.Flush()
+
+	case []augmentcleaner.SelfTestResult:
+		for _, result := range r {
+			status := "✅"
+			switch result.Status {
+			case augmentcleaner.SelfTestFail:
+				status = "❌"
+			case augmentcleaner.SelfTestSkip:
+				status = "⏭️ "
+			}
+			fmt.Printf("  %s %s %s: %s\n", status, result.Status, result.Module, result.Detail)
+		}
+
+	case *augmentcleaner.BackupStats:
+		c.printField("Total Backups", r.TotalBackups)
+		if r.TotalBackups == 0 {
+			fmt.Println("  No backups found")
+			break
+		}
+		c.printField("Total Size", fmt.Sprintf("%d bytes", r.TotalSizeBytes))
+		c.printField("Average Size", fmt.Sprintf("%.2f MB", r.AverageBackupSizeMB))
+		c.printField("Oldest Backup", r.OldestBackup.Format("2006-01-02 15:04:05"))
+		c.printField("Newest Backup", r.NewestBackup.Format("2006-01-02 15:04:05"))
+		c.printField("Verified", r.VerifiedCount)
+		c.printField("Unverified", r.UnverifiedCount)
+		c.printField("Extensions Covered", strings.Join(r.ExtensionsCovered, ", "))
+		compressions := make([]string, 0, len(r.CompressionStats))
+		for compression := range r.CompressionStats {
+			compressions = append(compressions, compression)
+		}
+		sort.Strings(compressions)
+		for _, compression := range compressions {
+			c.printField("Compression "+compression, fmt.Sprintf("%d bytes", r.CompressionStats[compression]))
+		}
+
+	case *augmentcleaner.BackupPage:
+		if r.Total == 0 {
+			fmt.Println("  No backups found")
+			break
+		}
+		c.printField("Showing", fmt.Sprintf("%d of %d backups, by %s", len(r.Backups), r.Total, r.SortBy))
+		for _, backup := range r.Backups {
+			fmt.Printf("  %s  %s  %-16s %d bytes  %s\n", backup.CreationTime.Format("2006-01-02 15:04:05"),
+				backup.BackupID, backup.BackupType, backup.TotalSize, backup.BackupPath)
+			if c.config.Verbose && backup.ExtensionID != "" {
+				fmt.Printf("    Extension: %s, %d files, verified: %t\n", backup.ExtensionID, backup.FileCount, backup.Verified)
+			}
+		}
+
+	case *augmentcleaner.RestoreVSCodeSettingsResult:
+		c.printField("Restored Path", r.RestoredPath)
+		c.printField("Backup Used", fmt.Sprintf("%s (created %s)", r.BackupUsed.BackupID, r.BackupUsed.CreationTime.Format("2006-01-02 15:04:05")))
+		c.printFieldIf("Previous Settings Backup", r.PreRestoreBackup)
+		c.printField("Settings Changed", len(r.SettingsRestored))
+		for _, change := range r.Changes {
+			switch {
+			case change.Before == nil:
+				fmt.Printf("    + %s: %s\n", change.Key, settingValue(change.Key, change.After))
+			case change.After == nil:
+				fmt.Printf("    - %s: %s\n", change.Key, settingValue(change.Key, change.Before))
+			default:
+				fmt.Printf("    ~ %s: %s -> %s\n", change.Key, settingValue(change.Key, change.Before), settingValue(change.Key, change.After))
+			}
+		}
+		c.printField("Duration", r.Duration)
+
+	case *augmentcleaner.ExtensionStorageCleanResult:
+		c.printField("Extension", r.ExtensionID)
+		c.printField("Storage Path", r.StoragePath)
+		c.printFieldIf("Backup", r.BackupPath)
+		c.printField("Keys Removed", len(r.KeysRemoved))
+		for _, key := range r.KeysRemoved {
+			fmt.Printf("    - %s: %s (%s)\n", key.File, key.Key, key.Risk)
+		}
+		for _, key := range r.KeysPreserved {
+			fmt.Printf("    = %s (preserved)\n", key)
+		}
+		c.printField("Files Patched", len(r.FilesPatched))
+		c.printField("Files Deleted", len(r.FilesDeleted))
+		for _, file := range r.FilesDeleted {
+			fmt.Printf("    - %s\n", file)
+		}
+		for _, msg := range r.Errors {
+			c.printField("Error", msg)
+		}
+		c.printField("Duration", r.Duration)
+
+	case *augmentcleaner.PatternTestResult:
+		c.printFieldIf("Value", r.Value)
+		c.printFieldIf("File", r.File)
+		c.printFieldIf("Custom Patterns", r.PatternFile)
+		c.printField("Rules Matched", len(r.Matches))
+		if len(r.Matches) == 0 {
+			break
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "  RISK\tENGINE\tRULE\tSOURCE\tDETAIL")
+		for _, match := range r.Matches {
+			fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\t%s\n", match.Risk, match.Engine, match.Rule, match.Source, match.Detail)
+		}
+		writer.Flush()
+
+	case *augmentcleaner.CleanVSCodeSettingsResult:
+		c.printField("Settings Path", r.SettingsPath)
+		c.printFieldIf("Settings Backup", r.BackupPath)
+		c.printField("Settings Removed", len(r.SettingsRemoved))
+		c.printField("Settings Reset", len(r.SettingsReset))
+		for _, change := range r.Changes {
+			if change.After == nil {
+				fmt.Printf("    - %s: %s\n", change.Key, settingValue(change.Key, change.Before))
+			} else {
+				fmt.Printf("    ~ %s: %s -> %s\n", change.Key, settingValue(change.Key, change.Before), settingValue(change.Key, change.After))
+			}
+		}
+		if len(r.Protected) > 0 {
+			c.printField("Protected Settings Kept", strings.Join(r.Protected, ", "))
+		}
+		if len(r.NotFound) > 0 {
+			c.printField("Not Found", strings.Join(r.NotFound, ", "))
+		}
+		c.printField("Duration", r.Duration)
+
+	case *cleaner.MigrationReport:
+		c.printField("Backups Migrated", r.Migrated)
+		c.printField("Backups Skipped", r.Skipped)
+		c.printField("Backups Failed", r.Failed)
+		if c.config.Verbose {
+			for _, detail := range r.Details {
+				fmt.Printf("    %s\n", detail)
+			}
+		}
+
+	case *scanner.StorageAnalysisResult:
+		stats := r.StorageStatistics
+		if r.Anonymized {
+			fmt.Printf("  Anonymized report (Augment Telemetry Cleaner CLI v%s): paths are hashed and stored values removed\n", r.ToolVersion)
+		}
+		if r.AnalysisIncomplete {
+			fmt.Printf("  ⚠️  Scan incomplete - showing partial results (%s)\n", r.IncompleteReason)
+			c.printField("Extensions Not Scanned", len(r.SkippedExtensions))
+		}
+		c.printField("Extensions Analyzed", stats.ExtensionCount)
+		c.printField("Workspaces Analyzed", stats.WorkspaceCount)
+		trusted := trustedExtensionIDs(r)
+		if len(trusted) > 0 {
+			c.printField("Trusted Extensions", fmt.Sprintf("%s (on the scan allowlist, not analyzed)", strings.Join(trusted, ", ")))
+		}
+		c.printField("Total Storage Size", stats.TotalStorageSize)
+		c.printField("Telemetry Storage Size", stats.TelemetryStorageSize)
+		c.printField("Telemetry Percentage", fmt.Sprintf("%.1f%%", stats.TelemetryPercentage))
+		c.printField("Storage Items by Risk", scanner.FormatRiskDistribution(r.GlobalStorageAnalysis.RiskDistribution))
+		c.printField("Scan Duration", r.ScanDuration)
+		if c.config.Verbose {
+			phases := r.PhaseDurations
+			fmt.Printf("    Global Storage: %s, Workspace Storage: %s, Cache: %s, Temp Files: %s\n",
+				phases.GlobalStorage.Round(time.Millisecond), phases.WorkspaceStorage.Round(time.Millisecond),
+				phases.Cache.Round(time.Millisecond), phases.TempFiles.Round(time.Millisecond))
+		}
+		c.printField("Coverage", stats.Coverage)
+		if stats.SizeThreshold > 0 {
+			c.printField("Size Threshold", fmt.Sprintf("only storage keys of at least %d bytes were reported", stats.SizeThreshold))
+		}
+		if stats.SkippedFileCount > 0 {
+			c.printField("Files Skipped", stats.SkippedFileCount)
+		}
+		if len(stats.TopExtensions) > 0 {
+			fmt.Println("\n  Top Extensions by Telemetry Size:")
+			for i, ext := range stats.TopExtensions {
+				fmt.Printf("    %2d. %s: %d bytes (of %d bytes)\n", i+1, ext.ExtensionID, ext.TelemetrySize, ext.TotalSize)
+			}
+		}
+		if len(stats.TopTelemetryItems) > 0 {
+			fmt.Println("\n  Largest Telemetry Items:")
+			for i, item := range stats.TopTelemetryItems {
+				fmt.Printf("    %2d. %s %s: %d bytes\n", i+1, item.ExtensionID, item.Key, item.Size)
+				if c.config.Verbose {
+					for _, reason := range item.Reasons {
+						fmt.Printf("        %s\n", reason)
+					}
+				}
+			}
+		}
+		if r.NetworkAnalysis != nil {
+			c.printField("Telemetry Endpoints Found", r.NetworkAnalysis.EndpointsFound)
+			for _, finding := range r.NetworkAnalysis.Findings {
+				fmt.Printf("    [%s] %s (%s) at %s:%d\n", finding.Risk, finding.URL, finding.EndpointCategory, finding.File, finding.LineNumber)
+			}
+		}
+		if c.config.Verbose && len(r.WorkspaceStorageAnalysis.WorkspaceStorages) > 0 {
+			fmt.Println("\n  Workspaces:")
+			for _, ws := range r.WorkspaceStorageAnalysis.WorkspaceStorages {
+				status := ""
+				if ws.ExistsUnknown {
+					status = " (not accessible)"
+				} else if !ws.Exists {
+					status = " (missing)"
+				}
+				fmt.Printf("    %s  %s%s\n", ws.WorkspaceHash, ws.WorkspacePath, status)
+			}
+		}
+		if len(r.SecretStoreAnalysis) > 0 {
+			fmt.Printf("\n  Secret Store Entries: %d\n", len(r.SecretStoreAnalysis))
+			for _, entry := range r.SecretStoreAnalysis {
+				fmt.Printf("    %s/%s %s\n", entry.ServiceName, entry.AccountName, entry.TokenSnippet)
+			}
+		}
+		if len(r.SizeLimitViolations) > 0 {
+			fmt.Printf("\n  Oversized Extensions: %d\n", len(r.SizeLimitViolations))
+			for _, v := range r.SizeLimitViolations {
+				fmt.Printf("    [%s] %s: %d bytes (limit %d bytes)\n", v.Severity, v.ExtensionID, v.ActualSizeBytes, v.LimitBytes)
+			}
+		}
+		if len(r.RetentionViolations) > 0 {
+			fmt.Printf("\n  Retention Violations: %d\n", len(r.RetentionViolations))
+			for _, v := range r.RetentionViolations {
+				fmt.Printf("    [%s] %s/%s: %d days old (policy %d days, overdue by %d days)\n", v.Risk, v.ExtensionID, v.ItemKey,
+					int(v.ActualAge.Hours()/24), int(v.PolicyPeriod.Hours()/24), int(v.OverdueBy.Hours()/24))
+			}
+		}
+		if c.config.Verbose {
+			printedHeader := false
+			for _, storage := range r.GlobalStorageAnalysis.ExtensionStorages {
+				manifest := storage.ManifestInfo
+				if manifest == nil || len(manifest.DeclaredTelemetrySettings) == 0 {
+					continue
+				}
+				if !printedHeader {
+					fmt.Println("\n  Declared Telemetry Settings:")
+					printedHeader = true
+				}
+				optOut := "no opt-out"
+				if manifest.HasExplicitOptOut {
+					optOut = "can be turned off"
+				}
+				fmt.Printf("    %s: %s (%s)\n", storage.ExtensionID, strings.Join(manifest.DeclaredTelemetrySettings, ", "), optOut)
+				if manifest.PrivacyPolicyURL != "" {
+					fmt.Printf("        %s\n", manifest.PrivacyPolicyURL)
+				}
+			}
+		}
+		if len(r.PrivacyScores) > 0 {
+			fmt.Println("\n  Extension Privacy Scores:")
+			c.printPrivacyScores(r.PrivacyScores, trusted)
+		}
+		if r.PrivacyOptimization != nil {
+			fmt.Println("\n  Privacy Optimization Opportunities:")
+			c.printSettingsSuggestion(r.PrivacyOptimization)
+		}
+		if growth := r.GrowthAnalysis; growth != nil && len(growth.FastGrowingExtensions) > 0 {
+			fmt.Printf("\n  Fast-Growing Telemetry Storage (over %s/day since %s):\n",
+				utils.FormatBytes(growth.ThresholdBytesPerDay), growth.PreviousScanAt.Local().Format("2006-01-02 15:04"))
+			printGrowthChart(growth.FastGrowingExtensions)
+		}
+
+	case *cleaner.AuditVerifyResult:
+		c.printAuditVerifyResult(r)
+
+	case *scanner.ScanDiff:
+		c.printScanDiff(r)
+
 	default:
 		c.printField("Result", result)
 	}
 }
 
+// marshalJSON encodes a result as a JSON document stamped with jsonSchemaVersion,
+// indented or compact with --json-compact
+func (c *CLI) marshalJSON(result interface{}) ([]byte, error) {
+	jsonData, err := jsonschema.MarshalDocument(result, jsonSchemaVersion, !c.config.JSONCompact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result to JSON: %w", err)
+	}
+	return jsonData, nil
+}
+
 // Helper functions for printing formatted output
+// printPrivacyScores prints each extension's privacy score as a colored badge, worst first
+func (c *CLI) printPrivacyScores(scores map[string]scanner.PrivacyScore, trusted []string) {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]].Overall != scores[ids[j]].Overall {
+			return scores[ids[i]].Overall < scores[ids[j]].Overall
+		}
+		return ids[i] < ids[j]
+	})
+
+	for _, id := range ids {
+		score := scores[id]
+		badge := ""
+		if i := sort.SearchStrings(trusted, id); i < len(trusted) && trusted[i] == id {
+			badge = " [trusted]"
+		}
+		fmt.Printf("    %s %s%s\n", privacyBadge(score), id, badge)
+		fmt.Printf("      %s\n", score.RecommendationSummary)
+		if c.config.Verbose {
+			for _, reason := range score.PenaltyReasons {
+				fmt.Printf("      - %s\n", reason)
+			}
+		}
+	}
+}
+
+// growthChartEntries and growthChartWidth are the number of extensions and the
+// length of the longest bar of the growth chart
+const (
+	growthChartEntries = 10
+	growthChartWidth   = 30
+)
+
+// printGrowthChart prints a bar chart of the daily telemetry growth of the
+// fastest-growing extensions; entries are sorted fastest first
+func printGrowthChart(entries []scanner.GrowthEntry) {
+	if len(entries) > growthChartEntries {
+		entries = entries[:growthChartEntries]
+	}
+	for i, entry := range entries {
+		bar := int(entry.DailyGrowthRateBytes * growthChartWidth / entries[0].DailyGrowthRateBytes)
+		if bar < 1 {
+			bar = 1
+		}
+		// Padded by hand, as the bar characters are wider than one byte
+		chart := strings.Repeat("█", bar) + strings.Repeat(" ", growthChartWidth-bar)
+		fmt.Printf("    %2d. %-40s %s %s/day (%s -> %s)\n", i+1, entry.ExtensionID, chart,
+			utils.FormatBytes(entry.DailyGrowthRateBytes), utils.FormatBytes(entry.PreviousSizeBytes), utils.FormatBytes(entry.CurrentSizeBytes))
+	}
+}
+
+// trustedExtensionIDs returns the sorted IDs of the extensions a scan skipped
+// because they are on the scan allowlist
+func trustedExtensionIDs(result *scanner.StorageAnalysisResult) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(storages []scanner.ExtensionStorage) {
+		for _, storage := range storages {
+			if storage.Trusted && !seen[storage.ExtensionID] {
+				seen[storage.ExtensionID] = true
+				ids = append(ids, storage.ExtensionID)
+			}
+		}
+	}
+	add(result.GlobalStorageAnalysis.ExtensionStorages)
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		add(workspace.ExtensionStorages)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// privacyBadge renders a score red below 40, yellow up to 70 and green above
+func privacyBadge(score scanner.PrivacyScore) string {
+	color := colorGreen
+	switch score.Rating() {
+	case "poor":
+		color = colorRed
+	case "fair":
+		color = colorYellow
+	}
+	return fmt.Sprintf("%s[%3d/100]%s", color, score.Overall, colorReset)
+}
+
+// printSettingsSuggestion prints the privacy score, each recommended change and the settings.json patch
+func (c *CLI) printSettingsSuggestion(s *scanner.SettingsSuggestion) {
+	c.printField("Settings File", s.SettingsPath)
+	c.printField("Privacy Score", fmt.Sprintf("%d/100", s.CurrentScore))
+	c.printField("Potential Score", fmt.Sprintf("%d/100", s.PotentialScore))
+	if len(s.RecommendedChanges) == 0 {
+		fmt.Println("  All telemetry settings are already opted out")
+		return
+	}
+
+	fmt.Println("\n  Recommended Changes:")
+	for _, change := range s.RecommendedChanges {
+		current := "(not set)"
+		if change.CurrentValue != nil {
+			current = fmt.Sprintf("%v", change.CurrentValue)
+		}
+		fmt.Printf("    %s: %s -> %v\n", change.Key, current, change.RecommendedValue)
+		fmt.Printf("      %s\n", change.Reason)
+	}
+
+	patch, err := json.MarshalIndent(s.Patch(), "  ", "    ")
+	if err != nil {
+		return
+	}
+	fmt.Println("\n  settings.json patch:")
+	fmt.Printf("  %s\n", patch)
+}
+
 func (c *CLI) printField(label string, value interface{}) {
 	fmt.Printf("  %s: %v\n", label, value)
 }
@@ -708,4 +2651,62 @@ func (c *CLI) parseLogLevel(level string) int {
 	}
 }
 
-
+// progressOptions builds library options from CLI flags, routing progress to the log file
+func (c *CLI) progressOptions() augmentcleaner.Options {
+	cfg := c.configManager.GetConfig()
+	rateLimit := cfg.CleanRateLimit
+	opts := augmentcleaner.Options{
+		CreateBackups:              c.config.CreateBackups,
+		PathResolver:               c.pathResolver,
+		StorageLimits:              cfg.StorageLimits,
+		TopOffenders:               cfg.TopOffenderCount,
+		BrowserProcessNames:        cfg.BrowserProcessNames,
+		RebootDeleteLocked:         c.config.RebootDelete,
+		DefaultBrowserProfilesOnly: c.config.DefaultProfile,
+		IncludeBrowserHistory:      c.config.IncludeHistory,
+		BrowserExtensionIDs:        cfg.BrowserExtensionIDs,
+		CustomAugmentPatterns:      cfg.CustomAugmentPatterns,
+		AggressiveBrowserCleaning:  c.config.Aggressive,
+		PurgeAllSessionStorage:     c.config.PurgeSessions,
+		BrowserBackupDir:           cfg.BrowserBackupDir,
+		Browser:                    c.config.TargetBrowser,
+		BrowserProfiles:            c.browserProfiles,
+		Force:                      c.config.Force,
+		AllowMultipleIDEWindows:    c.config.Force,
+		CheckPatternUpdates:        c.config.CheckPatterns,
+		PatternUpdateURL:           c.config.PatternURL,
+		ScanTimeout:                c.config.ScanTimeout,
+		DatabaseBatchSize:          rateLimit.BatchSize,
+		DatabaseBatchDelay:         time.Duration(rateLimit.BatchDelayMs) * time.Millisecond,
+		DatabaseLockBackoff:        time.Duration(rateLimit.LockBackoffMs) * time.Millisecond,
+		Progress: func(p augmentcleaner.Progress) {
+			c.logInfo("[%s] %s", p.Operation, p.Message)
+		},
+		Logger: logger.FuncLogger(func(level logger.LogLevel, message string) {
+			c.log(level.String(), "%s", message)
+		}),
+	}
+	if historyPath, err := augmentcleaner.DefaultHistoryPath(); err == nil {
+		opts.HistoryPath = historyPath
+	}
+	if c.config.TopN > 0 {
+		opts.TopOffenders = c.config.TopN
+	}
+	if len(c.config.CustomPatterns) > 0 {
+		if c.config.AppendPatterns {
+			opts.CustomAugmentPatterns = append(append([]string(nil), cfg.CustomAugmentPatterns...), c.config.CustomPatterns...)
+		} else {
+			opts.CustomAugmentPatterns = c.config.CustomPatterns
+		}
+	}
+	if c.config.BrowserBackup != "" {
+		opts.BrowserBackupDir = c.config.BrowserBackup
+	}
+	opts.DeepScan = c.config.DeepScan
+	opts.ScanSecretStore = c.config.ScanSecrets
+	opts.GuessWorkspaceFolders = c.config.GuessWorkspace
+	opts.ExtensionID = c.config.ExtensionID
+	opts.MinItemSizeBytes = c.config.MinItemSize
+	opts.DatabasePath = c.config.DBPath
+	return opts
+}
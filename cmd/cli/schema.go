@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/jsonschema"
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// jsonSchemaVersion is the schema_version of every --output json document.
+// Bump it whenever a result struct changes the JSON it marshals to; the
+// fingerprint test in schema_test.go fails until you do.
+const jsonSchemaVersion = 21
+
+// schemaValidateOnly names the --validate-only document for --print-schema
+const schemaValidateOnly = "validate-only"
+
+// resultTypes maps each operation to the types of the results it prints with
+// --output json
+var resultTypes = map[string][]reflect.Type{
+	OpModifyTelemetry: {reflect.TypeOf(&augmentcleaner.TelemetryModifyResult{})},
+	OpCleanDatabase:   {reflect.TypeOf(&augmentcleaner.DatabaseCleanResult{})},
+	OpCleanWorkspace: {
+		reflect.TypeOf(&augmentcleaner.WorkspaceCleanResult{}),
+		reflect.TypeOf(&augmentcleaner.OrphanCleanResult{}), // --orphans-only
+	},
+	OpCleanBrowser:     {reflect.TypeOf([]augmentcleaner.BrowserCleanResult{})},
+	OpRunAll:           {reflect.TypeOf(&augmentcleaner.AllOperationsResult{})},
+	OpScan:             {reflect.TypeOf(&augmentcleaner.Report{})},
+	OpDiffReport:       {reflect.TypeOf(&scanner.ScanDiff{})},
+	OpMigrateBackups:   {reflect.TypeOf(&cleaner.MigrationReport{})},
+	OpBackupStats:      {reflect.TypeOf(&augmentcleaner.BackupStats{})},
+	OpListBackups:      {reflect.TypeOf(&augmentcleaner.BackupPage{})},
+	OpVerifyAudit:      {reflect.TypeOf(&cleaner.AuditVerifyResult{})},
+	OpCleanSecrets:     {reflect.TypeOf(&augmentcleaner.SecretStoreCleanResult{})},
+	OpCleanExtensions:  {reflect.TypeOf(&augmentcleaner.BulkCleanResult{})},
+	OpListProcesses:    {reflect.TypeOf([]augmentcleaner.BrowserProcess{})},
+	OpSuggestSettings:  {reflect.TypeOf(&augmentcleaner.SettingsSuggestion{})},
+	OpHistory:          {reflect.TypeOf([]augmentcleaner.HistoryRecord{})},
+	OpSelfTest:         {reflect.TypeOf([]augmentcleaner.SelfTestResult{})},
+	OpQuickClean:       {reflect.TypeOf(&augmentcleaner.QuickCleanResult{})},
+	OpRestoreSettings:  {reflect.TypeOf(&augmentcleaner.RestoreVSCodeSettingsResult{})},
+	OpCleanSettings:    {reflect.TypeOf(&augmentcleaner.CleanVSCodeSettingsResult{})},
+	OpTestPattern:      {reflect.TypeOf(&augmentcleaner.PatternTestResult{})},
+	OpCleanAugmentExt:  {reflect.TypeOf(&augmentcleaner.ExtensionStorageCleanResult{})},
+	schemaValidateOnly: {reflect.TypeOf([]augmentcleaner.ValidationFailure{})},
+}
+
+// resultSchema returns the JSON Schema of the documents operation prints with --output json
+func resultSchema(operation string) (*jsonschema.Schema, error) {
+	types, ok := resultTypes[operation]
+	if !ok {
+		return nil, fmt.Errorf("no JSON schema for %q. Valid names: %s", operation, strings.Join(schemaNames(), ", "))
+	}
+	return jsonschema.DocumentSchema(fmt.Sprintf("augment-telemetry-cleaner %s result", operation), jsonSchemaVersion, types...), nil
+}
+
+// schemaNames returns the names --print-schema accepts, sorted
+func schemaNames() []string {
+	names := make([]string, 0, len(resultTypes))
+	for name := range resultTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printSchema prints the JSON Schema of an operation's result documents
+func (c *CLI) printSchema() error {
+	schema, err := resultSchema(c.config.PrintSchema)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if c.config.JSONCompact {
+		data, err = json.Marshal(schema)
+	} else {
+		data, err = json.MarshalIndent(schema, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema to JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/jsonschema"
+)
+
+// schemaFingerprints records a hash of every result schema per schema version.
+// When TestSchemaVersionFingerprint fails, a result struct changed: bump
+// jsonSchemaVersion and add the new fingerprint for it.
+var schemaFingerprints = map[int]string{
+	1:  "fd09f2b1f20cac076ccfd9997a8fdaf4bf74ffcaf453acc81c63710b184420c2",
+	2:  "55582a53026d68a24951526e8af4778e5d81555c57a17602984f5f0a78532f52", // list-processes: exe
+	3:  "64d839ec4ce2909378f8d799f9db13b341542a3cfbc87da5d87691e04acc47e7", // scan: manifest_info
+	4:  "52908f25ae09ac8bf926631793b9790357461df7fbcbe44c0656d767d66f95d9", // clean-browser: extension_data_deleted, suspicious_extensions
+	5:  "4fb359ab830da72bd08b1f5aa39e7a1f4d63df12862f1fd4eef49ef45091421e", // quick-clean
+	6:  "95b2e7bf6ad17ad78614c37472d38fcbf8c26e6b271667e7627f4520a67795e2", // run-all
+	7:  "79161c7c89f351108e1e668ba7ddca30bf1f85859e93d195613f1b46dd0d7292", // restore-vscode-settings
+	8:  "fde75b42e1fbf38ab4c2cd52b5d481fe75a2b5862345dac32ef7bfab84075cee", // clean-settings
+	9:  "c89b9759d86c52459d1196a9efe9780c0667a87082c10c637104b1bf3b747ee9", // ide_processes in extension safety checks
+	10: "c9fccf3eb6df18eb03c9d8dc4d721cb0d69ad8d1ee499059133ded238d47d679", // test-pattern
+	11: "f9b52fd752c7cf4b5fde7dee463679eacaafc939f77fb575138aae1ba7d48eea", // clean-augment-extension
+	12: "bea766ebe363129096da546657b97c31585c79635cb3c860c7fff2248d57d6f7", // phase_durations, browser duration
+	13: "db4818034e77a2692e57d69f1ca19c8d1fa344a5722dd94bd4ce0a5ecb44cdcf", // list-backups
+	14: "e21dce8c4624bc832eda64096a4113dcbbe0d96963f3b62d09e8ba6d3d1a31c8", // trusted extensions
+	15: "ce842e0e359dd61af5e94dbb7efd808b3a3816a8f764e616dab6e3d9148defdd", // settings sync
+	16: "bdc32c0fa27cdb14fa19fe24381803857965b7fafc816af2df38f5f400c9768c", // bytes freed
+	17: "eb0637c77cc432e4967592a35db92f6575dda25a5e5ec715b0a6f1c8895ac083", // storage growth
+	18: "477b072fe5cf2251dd3550c2cbedb7d807280d831e130e25c517a894d1414dfb", // webview2 host app
+	19: "1d5228267cadfacb951f1b05c68dd68848c2442cb9d85b0d68f100a3bdf4198a", // secret entry target
+	20: "a413556c9fa234b626f18ebb3b99a1a8504925e66b1ebe3c3748250c8bbedd5b", // backups of every install
+	21: "f8644239fe4b14c719e4572f6f28d03624bbb3242947b991b27df6f167c6ac43", // workspaces whose folder could not be checked
+}
+
+func TestResultSchemasMatchOutput(t *testing.T) {
+	for _, name := range schemaNames() {
+		schema, err := resultSchema(name)
+		if err != nil {
+			t.Fatalf("resultSchema(%s) failed: %v", name, err)
+		}
+
+		for _, resultType := range resultTypes[name] {
+			result := filledValue(resultType, nil)
+			document, err := jsonschema.MarshalDocument(result.Interface(), jsonSchemaVersion, true)
+			if err != nil {
+				t.Fatalf("%s: MarshalDocument() failed: %v", name, err)
+			}
+
+			if err := schema.Validate(document); err != nil {
+				t.Errorf("%s: marshaled %s does not match its schema: %v", name, resultType, err)
+			}
+			// Every field is set, so every property of the schema has to show up
+			if missing := missingProperties(schema, schema, document); len(missing) > 0 {
+				t.Errorf("%s: schema properties missing from marshaled %s: %v", name, resultType, missing)
+			}
+		}
+	}
+}
+
+func TestSchemaVersionFingerprint(t *testing.T) {
+	hash := sha256.New()
+	for _, name := range schemaNames() {
+		schema, err := resultSchema(name)
+		if err != nil {
+			t.Fatalf("resultSchema(%s) failed: %v", name, err)
+		}
+		data, err := json.Marshal(schema)
+		if err != nil {
+			t.Fatalf("failed to marshal %s schema: %v", name, err)
+		}
+		hash.Write([]byte(name))
+		hash.Write(data)
+	}
+
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+	if want := schemaFingerprints[jsonSchemaVersion]; fingerprint != want {
+		t.Errorf("Result schemas changed without a schema version bump: bump jsonSchemaVersion and record fingerprint %s for it", fingerprint)
+	}
+}
+
+func TestResultSchemaUnknownOperation(t *testing.T) {
+	if _, err := resultSchema("no-such-operation"); err == nil {
+		t.Error("Expected no schema for an unknown operation")
+	}
+}
+
+// filledValue returns a value of type t with every field, element and pointer
+// set, so that it marshals to every property its schema allows. parents holds
+// the struct types being filled, where recursive types stop.
+func filledValue(t reflect.Type, parents []reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	if t == reflect.TypeOf(time.Time{}) {
+		v.Set(reflect.ValueOf(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Ptr:
+		v.Set(filledValue(t.Elem(), parents).Addr())
+	case reflect.Slice:
+		slice := reflect.MakeSlice(t, 1, 1)
+		slice.Index(0).Set(filledValue(t.Elem(), parents))
+		v.Set(slice)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(filledValue(t.Elem(), parents))
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(t)
+		m.SetMapIndex(filledValue(t.Key(), parents), filledValue(t.Elem(), parents))
+		v.Set(m)
+	case reflect.Interface:
+		if reflect.TypeOf("").AssignableTo(t) {
+			v.Set(reflect.ValueOf("value"))
+		}
+	case reflect.Struct:
+		for _, parent := range parents {
+			if parent == t {
+				return v
+			}
+		}
+		parents = append(parents[:len(parents):len(parents)], t)
+		for i := 0; i < t.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				v.Field(i).Set(filledValue(t.Field(i).Type, parents))
+			}
+		}
+	}
+	return v
+}
+
+// missingProperties returns the properties of object schemas in s that the
+// decoded document does not have
+func missingProperties(root, s *jsonschema.Schema, document []byte) []string {
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return []string{err.Error()}
+	}
+	var missing []string
+	collectMissing(root, s, value, "$", &missing)
+	return missing
+}
+
+// collectMissing walks value alongside s and records absent properties
+func collectMissing(root, s *jsonschema.Schema, value interface{}, location string, missing *[]string) {
+	if s.Ref != "" {
+		s = root.Defs[s.Ref[len("#/$defs/"):]]
+	}
+	if len(s.AnyOf) > 0 {
+		// Follow the option the value matches best
+		var best []string
+		found := false
+		for _, option := range s.AnyOf {
+			if option.Type == "null" && value != nil {
+				continue
+			}
+			var optionMissing []string
+			collectMissing(root, option, value, location, &optionMissing)
+			if !found || len(optionMissing) < len(best) {
+				best, found = optionMissing, true
+			}
+		}
+		*missing = append(*missing, best...)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, property := range s.Properties {
+			child, ok := v[name]
+			if !ok {
+				*missing = append(*missing, location+"."+name)
+				continue
+			}
+			collectMissing(root, property, child, location+"."+name, missing)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for _, item := range v {
+				collectMissing(root, s.Items, item, location+"[]", missing)
+			}
+		}
+	}
+}
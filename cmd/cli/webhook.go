@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/webhook"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// countResult adds the counts of an operation result to the webhook summary
+func (c *CLI) countResult(result interface{}) {
+	if c.resultCounts == nil {
+		c.resultCounts = make(map[string]int64)
+	}
+
+	switch r := result.(type) {
+	case *augmentcleaner.AllOperationsResult:
+		for _, step := range r.Steps {
+			if stepResult := allOperationsStepResult(r, step.Step); stepResult != nil {
+				c.countResult(stepResult)
+			}
+		}
+	case *augmentcleaner.QuickCleanResult:
+		if r.Database != nil {
+			c.countResult(r.Database)
+		}
+		if r.Telemetry != nil {
+			c.countResult(r.Telemetry)
+		}
+	case *augmentcleaner.TelemetryModifyResult:
+		c.resultCounts["telemetry_modifications"]++
+	case *augmentcleaner.DatabaseCleanResult:
+		c.resultCounts["database_records_deleted"] += r.DeletedRows
+	case *augmentcleaner.WorkspaceCleanResult:
+		c.resultCounts["workspace_files_deleted"] += int64(r.DeletedFilesCount)
+	case *augmentcleaner.OrphanCleanResult:
+		c.resultCounts["workspaces_pruned"] += int64(len(r.PrunedWorkspaces))
+	case *augmentcleaner.SecretStoreCleanResult:
+		c.resultCounts["secrets_removed"] += int64(len(r.RemovedEntries))
+	case *augmentcleaner.BulkCleanResult:
+		c.resultCounts["extension_items_removed"] += int64(r.TotalItemsRemoved)
+		c.resultCounts["extensions_failed"] += int64(len(r.FailedExtensions))
+	case []augmentcleaner.BrowserCleanResult:
+		for _, profile := range r {
+			c.resultCounts["browser_cookies_deleted"] += profile.CookiesDeleted
+			c.resultCounts["browser_storage_deleted"] += profile.StorageDeleted
+			c.resultCounts["browser_cache_deleted"] += profile.CacheDeleted
+		}
+	case *scanner.StorageAnalysisResult:
+		c.resultCounts["extensions_scanned"] += int64(r.StorageStatistics.ExtensionCount)
+		c.resultCounts["telemetry_bytes"] += r.StorageStatistics.TelemetryStorageSize
+	}
+}
+
+// webhookURL returns the --webhook-url flag, or the configured URL without it
+func (c *CLI) webhookURL() string {
+	if c.config.WebhookURL != "" {
+		return c.config.WebhookURL
+	}
+	if c.configManager == nil {
+		return ""
+	}
+	return c.configManager.GetConfig().WebhookURL
+}
+
+// sendWebhook posts a summary of the run to the webhook URL if one is set. A
+// failed delivery is logged and never changes the exit code.
+func (c *CLI) sendWebhook(startedAt time.Time, exitCode int, runErr error) {
+	endpoint := c.webhookURL()
+	if endpoint == "" {
+		return
+	}
+
+	summary := webhook.Summary{
+		Operation:  c.config.Operation,
+		HostID:     webhook.HostID(),
+		Success:    runErr == nil,
+		ExitCode:   exitCode,
+		DryRun:     c.config.DryRun,
+		Counts:     c.resultCounts,
+		StartedAt:  startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+	}
+	if c.config.ValidateOnly {
+		summary.Operation = "validate-only"
+	}
+	if c.config.InstallDesktop {
+		summary.Operation = "install-desktop-entry"
+	}
+	if runErr != nil {
+		summary.Errors = append(summary.Errors, runErr.Error())
+	}
+	if len(c.permissionDenied) > 0 {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("permission denied for %d item(s)", len(c.permissionDenied)))
+	}
+
+	if err := webhook.NewSender(endpoint, []byte(os.Getenv(webhook.SecretEnv))).Send(summary); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to send run summary to webhook: %v\n", err)
+		c.log("WARN", "Failed to send run summary to webhook: %v", err)
+		return
+	}
+	c.log("INFO", "Sent run summary to webhook")
+}
@@ -0,0 +1,42 @@
+package browser
+
+import "strings"
+
+// augmentDomains are the sites whose origins, cookies and permissions belong to
+// Augment; subdomains such as app.augmentcode.com are included
+var augmentDomains = []string{
+	"augmentcode.com",
+}
+
+// isAugmentHost reports whether host is one of the augmentDomains or a subdomain of one
+func isAugmentHost(host string) bool {
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(host), "."), ".")
+	for _, domain := range augmentDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// originHost returns the host of an origin or origin pattern such as
+// "https://app.augmentcode.com:443", "https://[*.]augmentcode.com" or
+// "*://*.augmentcode.com/*", without scheme, wildcard label, port or path
+func originHost(origin string) string {
+	host := strings.TrimSpace(origin)
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndexByte(host, '@'); i >= 0 {
+		host = host[i+1:]
+	}
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	host = strings.TrimPrefix(host, "[*.]")
+	host = strings.TrimPrefix(host, "*.")
+	return host
+}
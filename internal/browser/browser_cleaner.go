@@ -1,134 +1,231 @@
 package browser
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/utils"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // BrowserCleanResult contains the results of browser cleaning operation
 type BrowserCleanResult struct {
-	Profile         BrowserProfile `json:"profile"`
-	BackupPath      string         `json:"backup_path,omitempty"`
-	CookiesDeleted  int64          `json:"cookies_deleted"`
-	StorageDeleted  int64          `json:"storage_deleted"`
-	CacheDeleted    int64          `json:"cache_deleted"`
-	FilesDeleted    []string       `json:"files_deleted"`
-	Errors          []string       `json:"errors,omitempty"`
+	Profile          BrowserProfile `json:"profile"`
+	BackupPath       string         `json:"backup_path,omitempty"`
+	CookiesDeleted   int64          `json:"cookies_deleted"`
+	StorageDeleted   int64          `json:"storage_deleted"`
+	CacheDeleted     int64          `json:"cache_deleted"`
+	IndexedDBDeleted int64          `json:"indexeddb_deleted"`
+	// PreferencesDeleted counts Augment origins removed from Preferences and Secure Preferences
+	PreferencesDeleted int64        `json:"preferences_deleted"`
+	// PermissionsDeleted counts Firefox permissions and site-specific prefs of Augment origins removed
+	PermissionsDeleted int64        `json:"permissions_deleted"`
+	// HistoryDeleted counts history rows (Chromium History and Top Sites, Firefox
+	// places.sqlite) removed when history cleaning is enabled
+	HistoryDeleted   int64          `json:"history_deleted"`
+	// ExtensionDataDeleted counts LevelDB folders, Extension State keys and
+	// extensions.settings preferences of Augment browser extensions removed
+	ExtensionDataDeleted int64      `json:"extension_data_deleted"`
+	// SuspiciousExtensions lists unknown extensions whose manifest references Augment
+	// domains; their data is only removed in aggressive mode
+	SuspiciousExtensions []string   `json:"suspicious_extensions,omitempty"`
+	FilesDeleted     []string       `json:"files_deleted"`
+	Errors           []string       `json:"errors,omitempty"`
+	LockedFiles      []LockedFile   `json:"locked_files,omitempty"`
+	// PendingRebootDeletions lists locked files registered for deletion at the next reboot
+	PendingRebootDeletions []string `json:"pending_reboot_deletions,omitempty"`
+	// PermissionDenied lists the files and databases that could not be cleaned for lack
+	// of access rights, e.g. Safari data without Full Disk Access
+	PermissionDenied []string `json:"permission_denied,omitempty"`
+	// BytesFreed is the size of the files and directories deleted; rows deleted from
+	// cookie and history databases are not included
+	BytesFreed int64 `json:"bytes_freed"`
+	// Duration is the time spent closing the browser and cleaning the profile
+	Duration time.Duration `json:"duration"`
 }
 
 // BrowserCleaner handles cleaning of browser data
 type BrowserCleaner struct {
-	detector *BrowserDetector
+	detector       *BrowserDetector
+	processManager *ProcessManager
+
+	scheduleDeleteOnReboot bool
+	removal                *removalTracker // Removal failures of the profile being cleaned
+	log                    logger.Leveled
+	backupDir              string // Empty uses backups/browser-data in the working directory
+	defaultProfilesOnly    bool
+	browsers               []BrowserType // Empty works on every browser, see SetBrowsers
+	profileCache           profileCache
+	maxScanBytes           int64 // Bytes of each file searched for Augment data, see SetMaxScanBytes
+	levelDBScan            LevelDBScanConfig // Sampling of LevelDB files past maxScanBytes, see SetLevelDBScanConfig
+	includeHistory         bool
+	extensionIDs           []string // Augment browser extensions besides the known ones, see SetAugmentExtensionIDs
+	customPatterns         []customAugmentPattern // See SetCustomAugmentPatterns
+	aggressive             bool
+	purgeAllSessionStorage bool
 }
 
 // NewBrowserCleaner creates a new browser cleaner
 func NewBrowserCleaner() (*BrowserCleaner, error) {
-	detector, err := NewBrowserDetector()
+	return NewBrowserCleanerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewBrowserCleanerWithResolver creates a browser cleaner that detects profiles
+// under the home directory of resolver
+func NewBrowserCleanerWithResolver(resolver utils.PathResolver) (*BrowserCleaner, error) {
+	detector, err := NewBrowserDetectorWithResolver(resolver)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser detector: %w", err)
 	}
 	
 	return &BrowserCleaner{
-		detector: detector,
+		detector:       detector,
+		processManager: NewProcessManager(),
+		profileCache:   profileCache{ttl: DefaultProfileCacheTTL},
+		maxScanBytes:   DefaultMaxScanBytes,
+		levelDBScan:    DefaultLevelDBScanConfig,
 	}, nil
 }
 
+// SetExtraProcessNames configures additional process names per browser type that
+// must be closed before a profile is cleaned
+func (bc *BrowserCleaner) SetExtraProcessNames(names map[BrowserType][]string) {
+	bc.processManager.SetExtraProcessNames(names)
+}
+
+// SetLogger sets the logger receiving DEBUG traces of every file, row and skipped path
+func (bc *BrowserCleaner) SetLogger(l logger.Leveled) {
+	bc.log = l
+}
+
+// SetBackupDir sets the directory profile backups are created in
+func (bc *BrowserCleaner) SetBackupDir(dir string) {
+	bc.backupDir = dir
+}
+
+// SetDefaultProfilesOnly limits cleaning and counting to the default profile of
+// each browser instead of every detected profile
+func (bc *BrowserCleaner) SetDefaultProfilesOnly(defaultOnly bool) {
+	bc.defaultProfilesOnly = defaultOnly
+}
+
+// SetBrowsers limits cleaning, counting and process listing to the given browsers;
+// without any, every detected browser is cleaned
+func (bc *BrowserCleaner) SetBrowsers(browserTypes ...BrowserType) {
+	bc.browsers = browserTypes
+}
+
+// SetProcessLister replaces how running processes are listed before a profile is
+// cleaned, e.g. to clean a sandbox profile without closing the real browsers
+func (bc *BrowserCleaner) SetProcessLister(list func() ([]BrowserProcess, error)) {
+	bc.processManager.listProcesses = list
+}
+
+// logger returns the configured logger, discarding output if none is set
+func (bc *BrowserCleaner) logger() logger.Leveled {
+	return logger.OrDiscard(bc.log)
+}
+
+// ListBrowserProcesses returns every running process that would be closed before cleaning
+func (bc *BrowserCleaner) ListBrowserProcesses() ([]BrowserProcess, error) {
+	return bc.processManager.ListBrowserProcesses(bc.browsers...)
+}
+
 // CleanBrowserData cleans Augment-related data from all detected browsers
 func (bc *BrowserCleaner) CleanBrowserData(createBackup bool) ([]BrowserCleanResult, error) {
-	profiles, err := bc.detector.DetectBrowsers()
+	profiles, err := bc.detectProfiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect browsers: %w", err)
 	}
 	
+	// Browsers whose detection panicked are reported while the others are cleaned
 	var results []BrowserCleanResult
-	processManager := NewProcessManager()
+	for _, failure := range bc.detectionFailures() {
+		results = append(results, bc.detectionFailureResult(failure))
+	}
 	
 	for _, profile := range profiles {
-		// Check if browser is running
-		isRunning, err := bc.detector.IsProcessRunning(profile.Type)
-		if err != nil {
-			result := BrowserCleanResult{
-				Profile: profile,
-				Errors:  []string{fmt.Sprintf("Failed to check if browser is running: %v", err)},
-			}
-			results = append(results, result)
-			continue
-		}
-		
-		if isRunning {
-			// Try to force close the browser
-			if err := processManager.ForceCloseBrowser(profile.Type); err != nil {
-				result := BrowserCleanResult{
-					Profile: profile,
-					Errors:  []string{fmt.Sprintf("Failed to close %s processes: %v", profile.Type.String(), err)},
-				}
-				results = append(results, result)
-				continue
-			}
-			
-			// Wait for processes to close
-			if err := processManager.WaitForProcessesToClose(profile.Type, 10*time.Second); err != nil {
-				result := BrowserCleanResult{
-					Profile: profile,
-					Errors:  []string{fmt.Sprintf("%s processes did not close in time. Please close manually and try again.", profile.Type.String())},
-				}
-				results = append(results, result)
-				continue
-			}
-		}
-		
-		// Clean the profile
-		result := bc.cleanProfile(profile, createBackup)
-		results = append(results, result)
+		results = append(results, bc.closeAndCleanProfile(profile, createBackup))
 	}
 	
 	return results, nil
 }
 
-// GetBrowserDataCount returns the count of Augment-related data in browsers (for dry-run)
-func (bc *BrowserCleaner) GetBrowserDataCount() (map[string]int64, error) {
-	profiles, err := bc.detector.DetectBrowsers()
+// closeAndCleanProfile closes the browser of a profile and cleans it. A panic is
+// recorded as an error on the profile's result, so one malformed profile never
+// aborts the cleaning of the others.
+func (bc *BrowserCleaner) closeAndCleanProfile(profile BrowserProfile, createBackup bool) (result BrowserCleanResult) {
+	result.Profile = profile
+	elapsed := utils.StartTimer()
+	defer func() { result.Duration = elapsed() }()
+	defer bc.recoverProfilePanic(&result)
+	processManager := bc.processManager
+	
+	// Check if browser is running
+	running, err := processManager.FindProfileProcesses(profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to detect browsers: %w", err)
+		result.Errors = []string{fmt.Sprintf("Failed to check if browser is running: %v", err)}
+		return result
 	}
 	
-	counts := make(map[string]int64)
-	
-	for _, profile := range profiles {
-		count := bc.countAugmentData(profile)
-		if count > 0 {
-			counts[profile.Name] = count
+	if len(running) > 0 {
+		// Try to force close the processes using the profile
+		if err := processManager.CloseProcesses(running); err != nil {
+			result.Errors = processErrors(fmt.Sprintf("Failed to close %s processes: %v", profile.Type.String(), err), running)
+			return result
+		}
+		
+		// Wait for processes to close
+		if remaining, err := processManager.WaitForProfileProcessesToClose(profile, 10*time.Second); err != nil {
+			result.Errors = processErrors(fmt.Sprintf("%s processes did not close in time. Please close manually and try again.", profile.Type.String()), remaining)
+			return result
 		}
 	}
 	
-	return counts, nil
+	// Clean the profile
+	return bc.cleanProfile(profile, createBackup)
 }
 
-// cleanProfile cleans a specific browser profile
-func (bc *BrowserCleaner) cleanProfile(profile BrowserProfile, createBackup bool) BrowserCleanResult {
-	result := BrowserCleanResult{
-		Profile: profile,
+// processErrors returns message followed by one entry per process still holding the profile
+func processErrors(message string, processes []BrowserProcess) []string {
+	messages := []string{message}
+	for _, process := range processes {
+		messages = append(messages, fmt.Sprintf("Still running: %s", process))
 	}
+	return messages
+}
+
+// cleanProfile cleans a specific browser profile
+func (bc *BrowserCleaner) cleanProfile(profile BrowserProfile, createBackup bool) (result BrowserCleanResult) {
+	result.Profile = profile
+	defer bc.recoverProfilePanic(&result) // Keeps what was cleaned before the panic
 	
 	// Create backup if requested
 	if createBackup {
-		backupPath, err := bc.createProfileBackup(profile)
+		backup := bc.createProfileBackup
+		if profile.Type == Safari {
+			backup = bc.createSafariBackup
+		}
+		backupPath, err := backup(profile)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to create backup: %v", err))
+			result.addError("create backup", err)
 			return result
 		}
 		result.BackupPath = backupPath
 	}
 	
+	bc.removal = &removalTracker{}
+	defer func() { bc.removal = nil }()
+	
 	// Clean based on browser type
 	switch profile.Type {
-	case Chrome, Edge:
+	case Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2:
 		bc.cleanChromiumBrowser(profile, &result)
 	case Firefox:
 		bc.cleanFirefoxBrowser(profile, &result)
@@ -136,19 +233,21 @@ func (bc *BrowserCleaner) cleanProfile(profile BrowserProfile, createBackup bool
 		bc.cleanSafariBrowser(profile, &result)
 	}
 	
+	bc.removal.applyTo(&result)
+	
 	return result
 }
 
-// cleanChromiumBrowser cleans Chrome/Edge browsers (Chromium-based)
+// cleanChromiumBrowser cleans Chromium-based browsers: every Chrome channel, Edge,
+// Arc and WebView2 user data folders
 func (bc *BrowserCleaner) cleanChromiumBrowser(profile BrowserProfile, result *BrowserCleanResult) {
-	// Clean cookies database
-	cookiesDB := filepath.Join(profile.ProfilePath, "Cookies")
-	if _, err := os.Stat(cookiesDB); err == nil {
+	// Clean cookies databases
+	for _, cookiesDB := range chromiumCookieDBs(profile.ProfilePath) {
 		deleted, err := bc.cleanChromiumCookies(cookiesDB)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean cookies: %v", err))
+			result.addError("clean cookies", err)
 		} else {
-			result.CookiesDeleted = deleted
+			result.CookiesDeleted += deleted
 		}
 	}
 	
@@ -157,7 +256,7 @@ func (bc *BrowserCleaner) cleanChromiumBrowser(profile BrowserProfile, result *B
 	if _, err := os.Stat(localStorageDir); err == nil {
 		deleted, err := bc.cleanChromiumLocalStorage(localStorageDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean local storage: %v", err))
+			result.addError("clean local storage", err)
 		} else {
 			result.StorageDeleted = deleted
 		}
@@ -168,7 +267,7 @@ func (bc *BrowserCleaner) cleanChromiumBrowser(profile BrowserProfile, result *B
 	if _, err := os.Stat(sessionStorageDir); err == nil {
 		deleted, err := bc.cleanChromiumSessionStorage(sessionStorageDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean session storage: %v", err))
+			result.addError("clean session storage", err)
 		} else {
 			result.StorageDeleted += deleted
 		}
@@ -179,11 +278,35 @@ func (bc *BrowserCleaner) cleanChromiumBrowser(profile BrowserProfile, result *B
 	if _, err := os.Stat(cacheDir); err == nil {
 		deleted, err := bc.cleanChromiumCache(cacheDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean cache: %v", err))
+			result.addError("clean cache", err)
 		} else {
 			result.CacheDeleted = deleted
 		}
 	}
+	
+	// Clean site permissions and engagement of Augment origins
+	deleted, err := bc.cleanChromiumPreferences(profile.ProfilePath)
+	if err != nil {
+		result.addError("clean preferences", err)
+	}
+	result.PreferencesDeleted = deleted
+	
+	// Clean the storage and settings of Augment browser extensions
+	deleted, suspicious, err := bc.cleanChromiumExtensions(profile.ProfilePath)
+	if err != nil {
+		result.addError("clean extension data", err)
+	}
+	result.ExtensionDataDeleted = deleted
+	result.SuspiciousExtensions = suspicious
+	
+	// Clean visited URLs only when explicitly requested
+	if bc.includeHistory {
+		deleted, err := bc.cleanChromiumHistory(profile.ProfilePath)
+		if err != nil {
+			result.addError("clean history", err)
+		}
+		result.HistoryDeleted = deleted
+	}
 }
 
 // cleanChromiumCookies cleans Augment-related cookies from Chromium browsers
@@ -200,44 +323,12 @@ func (bc *BrowserCleaner) cleanChromiumCookies(cookiesDBPath string) (int64, err
 		os.Remove(shmFile)
 	}
 
-	// Open database with retry mechanism and timeout
-	connectionString := fmt.Sprintf("%s?_timeout=30000&_journal_mode=DELETE&_synchronous=NORMAL", cookiesDBPath)
-	db, err := sql.Open("sqlite3", connectionString)
+	db, err := openBrowserDatabase(cookiesDBPath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to open cookies database: %w", err)
 	}
 	defer db.Close()
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-
-	// Test connection with retry
-	var connectionErr error
-	for i := 0; i < 3; i++ {
-		if connectionErr = db.Ping(); connectionErr == nil {
-			break
-		}
-		time.Sleep(time.Duration(i+1) * time.Second)
-	}
-	if connectionErr != nil {
-		return 0, fmt.Errorf("failed to connect to database after retries: %w", connectionErr)
-	}
-
-	// Enhanced patterns for Augment-related domains and cookie names
-	augmentPatterns := []string{
-		"%augment%",
-		"%augmentcode%",
-		"%augment-code%",
-		"%vscode-augment%",
-		"%augment.code%",
-		"%augment_telemetry%",
-		"%augment_session%",
-		"%augment_user%",
-		"%augmentai%",
-		"%augment-ai%",
-	}
-
 	var totalDeleted int64
 
 	// Begin transaction for better performance and atomicity
@@ -248,7 +339,7 @@ func (bc *BrowserCleaner) cleanChromiumCookies(cookiesDBPath string) (int64, err
 	defer tx.Rollback()
 
 	// Delete cookies with Augment-related domains or names
-	for _, pattern := range augmentPatterns {
+	for _, pattern := range bc.sqlPatterns() {
 		query := `DELETE FROM cookies WHERE host_key LIKE ? OR name LIKE ? OR value LIKE ?`
 		result, err := tx.Exec(query, pattern, pattern, pattern)
 		if err != nil {
@@ -259,6 +350,7 @@ func (bc *BrowserCleaner) cleanChromiumCookies(cookiesDBPath string) (int64, err
 		if err != nil {
 			return totalDeleted, fmt.Errorf("failed to get affected rows for pattern %s: %w", pattern, err)
 		}
+		bc.logger().Debug("SQL: %s [%s] -> %d rows", query, pattern, deleted)
 
 		totalDeleted += deleted
 	}
@@ -271,139 +363,40 @@ func (bc *BrowserCleaner) cleanChromiumCookies(cookiesDBPath string) (int64, err
 	return totalDeleted, nil
 }
 
-// cleanChromiumLocalStorage cleans Augment-related local storage
+// cleanChromiumLocalStorage deletes the Local Storage keys of Augment origins from
+// the LevelDB database and returns the number of keys deleted. Only when the
+// database is damaged are the table and log files mentioning Augment deleted
+// instead; CURRENT, MANIFEST and the other structural files are never deleted,
+// as that would lose the local storage of every site. A database another process
+// has open is an error.
 func (bc *BrowserCleaner) cleanChromiumLocalStorage(storageDir string) (int64, error) {
-	var deleted int64
+	// A database still open in the browser is skipped
+	if err := cleaner.CheckLevelDBLock(storageDir); err != nil {
+		bc.logger().Warn("Skipping local storage %s: %v", storageDir, err)
+		return 0, nil
+	}
 
-	// Enhanced patterns for Augment-related storage files
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augment_telemetry",
-		"augment_session",
-		"augment_user",
-		"augmentai",
-		"augment-ai",
-	}
-
-	// First, try to remove any lock files that might prevent access
-	lockFiles := []string{
-		filepath.Join(storageDir, "LOCK"),
-		filepath.Join(storageDir, "LOG"),
-		filepath.Join(storageDir, "LOG.old"),
+	deleted, err := bc.cleanLevelDBKeys(storageDir, bc.isAugmentLocalStorageKey)
+	if err == nil {
+		return deleted, nil
 	}
-	
-	for _, lockFile := range lockFiles {
-		if _, err := os.Stat(lockFile); err == nil {
-			// Try to remove lock files, but don't fail if we can't
-			os.Remove(lockFile)
-		}
+	if !isDamagedLevelDB(err) {
+		return 0, fmt.Errorf("failed to clean local storage %s: %w", storageDir, err)
 	}
 
-	// LevelDB files containing Augment data
-	err := filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			// Skip files we can't access instead of failing
-			return nil
-		}
-
-		if !info.IsDir() {
-			fileName := strings.ToLower(info.Name())
-
-			// Check if file contains any Augment-related patterns
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					// Try multiple times to remove the file
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					break
-				}
-			}
-
-			// Also check for files that might contain Augment data in their content
-			// This is more thorough but slower
-			if bc.shouldCheckFileContent(fileName) {
-				if bc.fileContainsAugmentData(path) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-				}
-			}
-		}
-
-		return nil
-	})
-
-	return deleted, err
-}
-
-// cleanChromiumSessionStorage cleans Augment-related session storage
-func (bc *BrowserCleaner) cleanChromiumSessionStorage(storageDir string) (int64, error) {
-	var deleted int64
-	
-	// Remove lock files first
-	lockFiles := []string{
-		filepath.Join(storageDir, "LOCK"),
-		filepath.Join(storageDir, "LOG"),
-		filepath.Join(storageDir, "LOG.old"),
+	files := bc.augmentLevelDBDataFiles(storageDir)
+	if len(files) == 0 {
+		return 0, fmt.Errorf("failed to clean local storage %s: %w", storageDir, err)
 	}
-	
-	for _, lockFile := range lockFiles {
-		if _, err := os.Stat(lockFile); err == nil {
-			os.Remove(lockFile)
+	bc.logger().Warn("WARNING: local storage %s is a damaged LevelDB database (%v); deleting %d data files that mention Augment instead. "+
+		"Other sites' local storage in those files is lost as well.", storageDir, err, len(files))
+	var removed int64
+	for _, path := range files {
+		if bc.removeFile(path) {
+			removed++
 		}
 	}
-	
-	err := filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-		
-		if !info.IsDir() {
-			fileName := strings.ToLower(info.Name())
-			
-			// Check filename for Augment patterns
-			augmentPatterns := []string{
-				"augment",
-				"augmentcode",
-				"augment-code",
-				"vscode-augment",
-				"augment.code",
-				"augmentai",
-				"augment-ai",
-			}
-			
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					// Try multiple times to remove the file
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					break
-				}
-			}
-		}
-		
-		return nil
-	})
-	
-	return deleted, err
+	return removed, nil
 }
 
 // cleanChromiumCache cleans Augment-related cache files
@@ -431,6 +424,7 @@ func (bc *BrowserCleaner) cleanChromiumCache(cacheDir string) (int64, error) {
 	
 	err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			bc.skipPath(path, err)
 			return nil // Skip files we can't access
 		}
 		
@@ -438,39 +432,19 @@ func (bc *BrowserCleaner) cleanChromiumCache(cacheDir string) (int64, error) {
 			fileName := strings.ToLower(info.Name())
 			
 			// Check filename for Augment patterns first (faster)
-			augmentPatterns := []string{
-				"augment",
-				"augmentcode",
-				"augment-code",
-				"vscode-augment",
-				"augment.code",
-				"augmentai",
-				"augment-ai",
-			}
-			
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					// Try multiple times to remove the file
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					return nil
+			if bc.isAugmentName(fileName) {
+				// Try multiple times to remove the file
+				if bc.removeFile(path) {
+					deleted++
 				}
+				return nil
 			}
 			
 			// For cache files, also check content if it's a reasonable size
 			if info.Size() < 10*1024*1024 && bc.shouldCheckFileContent(fileName) { // Only check files < 10MB
 				if bc.fileContainsAugmentData(path) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
+					if bc.removeFile(path) {
+						deleted++
 					}
 				}
 			}
@@ -489,7 +463,7 @@ func (bc *BrowserCleaner) cleanFirefoxBrowser(profile BrowserProfile, result *Br
 	if _, err := os.Stat(cookiesDB); err == nil {
 		deleted, err := bc.cleanFirefoxCookies(cookiesDB)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean cookies: %v", err))
+			result.addError("clean cookies", err)
 		} else {
 			result.CookiesDeleted = deleted
 		}
@@ -500,10 +474,34 @@ func (bc *BrowserCleaner) cleanFirefoxBrowser(profile BrowserProfile, result *Br
 	if _, err := os.Stat(storageDir); err == nil {
 		deleted, err := bc.cleanFirefoxStorage(storageDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean storage: %v", err))
+			result.addError("clean storage", err)
 		} else {
 			result.StorageDeleted = deleted
 		}
+
+		// Clean IndexedDB and localStorage of Augment extensions (moz-extension+++<uuid>)
+		idbDeleted, lsDeleted, errs := bc.cleanFirefoxExtensionStorage(profile, storageDir)
+		for _, err := range errs {
+			result.addError("clean extension storage", err)
+		}
+		result.IndexedDBDeleted += idbDeleted
+		result.StorageDeleted += lsDeleted
+	}
+	
+	// Clean site permissions and site-specific prefs
+	deleted, err := bc.cleanFirefoxSiteData(profile.ProfilePath)
+	if err != nil {
+		result.addError("clean site permissions", err)
+	}
+	result.PermissionsDeleted = deleted
+	
+	// Clean visited URLs only when explicitly requested
+	if bc.includeHistory {
+		deleted, err := bc.cleanFirefoxHistory(profile.ProfilePath)
+		if err != nil {
+			result.addError("clean history", err)
+		}
+		result.HistoryDeleted = deleted
 	}
 	
 	// Clean cache
@@ -511,7 +509,7 @@ func (bc *BrowserCleaner) cleanFirefoxBrowser(profile BrowserProfile, result *Br
 	if _, err := os.Stat(cacheDir); err == nil {
 		deleted, err := bc.cleanFirefoxCache(cacheDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean cache: %v", err))
+			result.addError("clean cache", err)
 		} else {
 			result.CacheDeleted = deleted
 		}
@@ -531,44 +529,12 @@ func (bc *BrowserCleaner) cleanFirefoxCookies(cookiesDBPath string) (int64, erro
 		os.Remove(shmFile)
 	}
 
-	// Open database with retry mechanism and timeout
-	connectionString := fmt.Sprintf("%s?_timeout=30000&_journal_mode=DELETE&_synchronous=NORMAL", cookiesDBPath)
-	db, err := sql.Open("sqlite3", connectionString)
+	db, err := openBrowserDatabase(cookiesDBPath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to open cookies database: %w", err)
 	}
 	defer db.Close()
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-
-	// Test connection with retry
-	var connectionErr error
-	for i := 0; i < 3; i++ {
-		if connectionErr = db.Ping(); connectionErr == nil {
-			break
-		}
-		time.Sleep(time.Duration(i+1) * time.Second)
-	}
-	if connectionErr != nil {
-		return 0, fmt.Errorf("failed to connect to database after retries: %w", connectionErr)
-	}
-
-	// Enhanced patterns for Firefox
-	augmentPatterns := []string{
-		"%augment%",
-		"%augmentcode%",
-		"%augment-code%",
-		"%vscode-augment%",
-		"%augment.code%",
-		"%augment_telemetry%",
-		"%augment_session%",
-		"%augment_user%",
-		"%augmentai%",
-		"%augment-ai%",
-	}
-
 	var totalDeleted int64
 
 	// Begin transaction
@@ -578,10 +544,29 @@ func (bc *BrowserCleaner) cleanFirefoxCookies(cookiesDBPath string) (int64, erro
 	}
 	defer tx.Rollback()
 
+	// Delete cookies isolated under an Augment site
+	ids, err := firefoxIsolatedCookieIDs(tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) > 0 {
+		query := "DELETE FROM moz_cookies WHERE " + rowIDCondition(ids)
+		result, err := tx.Exec(query, ids...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete isolated cookies: %w", err)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows for isolated cookies: %w", err)
+		}
+		bc.logger().Debug("SQL: %s -> %d rows", query, deleted)
+		totalDeleted += deleted
+	}
+
 	// Delete cookies with Augment-related domains or names
-	for _, pattern := range augmentPatterns {
-		query := `DELETE FROM moz_cookies WHERE host LIKE ? OR name LIKE ? OR value LIKE ?`
-		result, err := tx.Exec(query, pattern, pattern, pattern)
+	query := "DELETE FROM moz_cookies WHERE " + firefoxCookieWhere
+	for _, pattern := range bc.sqlPatterns() {
+		result, err := tx.Exec(query, likeArgs(query, pattern)...)
 		if err != nil {
 			return totalDeleted, fmt.Errorf("failed to delete cookies with pattern %s: %w", pattern, err)
 		}
@@ -590,6 +575,7 @@ func (bc *BrowserCleaner) cleanFirefoxCookies(cookiesDBPath string) (int64, erro
 		if err != nil {
 			return totalDeleted, fmt.Errorf("failed to get affected rows for pattern %s: %w", pattern, err)
 		}
+		bc.logger().Debug("SQL: %s [%s] -> %d rows", query, pattern, deleted)
 
 		totalDeleted += deleted
 	}
@@ -606,51 +592,23 @@ func (bc *BrowserCleaner) cleanFirefoxCookies(cookiesDBPath string) (int64, erro
 func (bc *BrowserCleaner) cleanFirefoxStorage(storageDir string) (int64, error) {
 	var deleted int64
 
-	// Enhanced patterns for Firefox storage
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augmentai",
-		"augment-ai",
-	}
-
 	err := filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			bc.skipPath(path, err)
 			return nil // Skip files we can't access
 		}
 
 		if info.IsDir() {
-			dirName := strings.ToLower(info.Name())
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(dirName, pattern) {
-					// Try multiple times to remove the directory
-					for i := 0; i < 3; i++ {
-						if err := os.RemoveAll(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					return filepath.SkipDir
+			if bc.isAugmentName(info.Name()) {
+				if bc.removeTree(path) == nil {
+					deleted++
 				}
+				return filepath.SkipDir
 			}
-		} else {
+		} else if bc.isAugmentName(info.Name()) {
 			// Also check individual files
-			fileName := strings.ToLower(info.Name())
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					break
-				}
+			if bc.removeFile(path) {
+				deleted++
 			}
 		}
 
@@ -664,19 +622,9 @@ func (bc *BrowserCleaner) cleanFirefoxStorage(storageDir string) (int64, error)
 func (bc *BrowserCleaner) cleanFirefoxCache(cacheDir string) (int64, error) {
 	var deleted int64
 
-	// Enhanced patterns for Firefox cache
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augmentai",
-		"augment-ai",
-	}
-
 	err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			bc.skipPath(path, err)
 			return nil // Skip files we can't access
 		}
 
@@ -684,28 +632,18 @@ func (bc *BrowserCleaner) cleanFirefoxCache(cacheDir string) (int64, error) {
 			fileName := strings.ToLower(info.Name())
 			
 			// Check filename for Augment patterns first
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					return nil
+			if bc.isAugmentName(fileName) {
+				if bc.removeFile(path) {
+					deleted++
 				}
+				return nil
 			}
 			
 			// Also check content for smaller files
 			if info.Size() < 5*1024*1024 && bc.shouldCheckFileContent(fileName) { // Only check files < 5MB
 				if bc.fileContainsAugmentData(path) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
+					if bc.removeFile(path) {
+						deleted++
 					}
 				}
 			}
@@ -730,39 +668,3 @@ func (bc *BrowserCleaner) shouldCheckFileContent(fileName string) bool {
 	// Also check files without extensions (common in LevelDB)
 	return !strings.Contains(fileName, ".")
 }
-
-// fileContainsAugmentData checks if a file contains Augment-related data in its content
-func (bc *BrowserCleaner) fileContainsAugmentData(filePath string) bool {
-	// Read first 1KB of file to check for Augment patterns
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
-	
-	buffer := make([]byte, 1024)
-	n, err := file.Read(buffer)
-	if err != nil && err.Error() != "EOF" {
-		return false
-	}
-	
-	content := strings.ToLower(string(buffer[:n]))
-	
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augmentai",
-		"augment-ai",
-	}
-	
-	for _, pattern := range augmentPatterns {
-		if strings.Contains(content, pattern) {
-			return true
-		}
-	}
-	
-	return false
-}
\ No newline at end of file
@@ -0,0 +1,230 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"augment-telemetry-cleaner/internal/testfixtures"
+)
+
+// lockSQLite holds an exclusive lock on the SQLite database at path, as a running
+// browser does, until the returned function is called
+func lockSQLite(t *testing.T, path string) func() {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", path, err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		t.Fatalf("Failed to lock %s: %v", path, err)
+	}
+	return func() {
+		conn.ExecContext(ctx, "ROLLBACK")
+		conn.Close()
+		db.Close()
+	}
+}
+
+// shortBusyTimeout makes locked browser databases fail fast for the test
+func shortBusyTimeout(t *testing.T) {
+	saved := browserDBBusyTimeout
+	browserDBBusyTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { browserDBBusyTimeout = saved })
+}
+
+func TestCleanCookiesDatabases(t *testing.T) {
+	shortBusyTimeout(t)
+
+	tests := []struct {
+		name   string
+		create func(path string) error
+		table  string
+		clean  func(bc *BrowserCleaner, path string) (int64, error)
+		locked bool
+	}{
+		{"chromium", testfixtures.CreateChromiumCookiesDB, "cookies", (*BrowserCleaner).cleanChromiumCookies, false},
+		{"chromium locked", testfixtures.CreateChromiumCookiesDB, "cookies", (*BrowserCleaner).cleanChromiumCookies, true},
+		{"firefox", testfixtures.CreateFirefoxCookiesDB, "moz_cookies", (*BrowserCleaner).cleanFirefoxCookies, false},
+		{"firefox locked", testfixtures.CreateFirefoxCookiesDB, "moz_cookies", (*BrowserCleaner).cleanFirefoxCookies, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cookies.db")
+			if err := tt.create(path); err != nil {
+				t.Fatalf("Failed to create fixture: %v", err)
+			}
+
+			unlock := func() {}
+			if tt.locked {
+				unlock = lockSQLite(t, path)
+			}
+			deleted, err := tt.clean(&BrowserCleaner{}, path)
+			unlock()
+
+			total := testfixtures.AugmentCookies + testfixtures.OtherCookies
+			if tt.locked {
+				if err == nil {
+					t.Error("Expected an error for a locked database")
+				}
+				if got := countRows(t, path, tt.table); got != total {
+					t.Errorf("Expected all %d cookies to be kept, got %d", total, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Cleaning failed: %v", err)
+			}
+			if deleted != testfixtures.AugmentCookies {
+				t.Errorf("Expected %d cookies deleted, got %d", testfixtures.AugmentCookies, deleted)
+			}
+			if got := countRows(t, path, tt.table); got != testfixtures.OtherCookies {
+				t.Errorf("Expected %d other cookies to be kept, got %d", testfixtures.OtherCookies, got)
+			}
+		})
+	}
+}
+
+func TestCleanChromiumLocalStorageFixture(t *testing.T) {
+	tests := []struct {
+		name   string
+		locked bool
+	}{
+		{"closed", false},
+		{"open in another process", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageDir := filepath.Join(t.TempDir(), "leveldb")
+			if err := testfixtures.CreateChromiumLevelDB(storageDir); err != nil {
+				t.Fatalf("Failed to create fixture: %v", err)
+			}
+
+			bc := &BrowserCleaner{levelDBScan: DefaultLevelDBScanConfig}
+			var deleted int64
+			var err error
+			if tt.locked {
+				// goleveldb locks with flock, which CheckLevelDBLock does not see, so
+				// opening it again must fail rather than fall back to deleting files
+				db, openErr := leveldb.OpenFile(storageDir, nil)
+				if openErr != nil {
+					t.Fatalf("Failed to open fixture: %v", openErr)
+				}
+				deleted, err = bc.cleanChromiumLocalStorage(storageDir)
+				db.Close()
+			} else {
+				deleted, err = bc.cleanChromiumLocalStorage(storageDir)
+			}
+
+			keys, _, countErr := countLevelDBKeys(storageDir, func([]byte) bool { return true })
+			if countErr != nil {
+				t.Fatalf("Expected the database to open after cleaning: %v", countErr)
+			}
+			total := int64(testfixtures.AugmentLocalStorageKeys + testfixtures.OtherLocalStorageKeys)
+			if tt.locked {
+				if err == nil {
+					t.Error("Expected an error for a database open in another process")
+				}
+				if deleted != 0 || keys != total {
+					t.Errorf("Expected all %d keys to be kept, got %d (%d deleted)", total, keys, deleted)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("cleanChromiumLocalStorage failed: %v", err)
+			}
+			if deleted != testfixtures.AugmentLocalStorageKeys {
+				t.Errorf("Expected %d keys deleted, got %d", testfixtures.AugmentLocalStorageKeys, deleted)
+			}
+			if keys != testfixtures.OtherLocalStorageKeys {
+				t.Errorf("Expected %d other keys to be kept, got %d", testfixtures.OtherLocalStorageKeys, keys)
+			}
+		})
+	}
+}
+
+func TestCleanFirefoxStorageFixture(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), "storage", "default")
+	if err := testfixtures.CreateFirefoxStorage(storageDir); err != nil {
+		t.Fatalf("Failed to create fixture: %v", err)
+	}
+
+	bc := &BrowserCleaner{removal: &removalTracker{}}
+	deleted, err := bc.cleanFirefoxStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanFirefoxStorage failed: %v", err)
+	}
+	if deleted != testfixtures.AugmentStorageOrigins {
+		t.Errorf("Expected %d origins deleted, got %d", testfixtures.AugmentStorageOrigins, deleted)
+	}
+	entries, err := os.ReadDir(storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != testfixtures.OtherStorageOrigins || entries[0].Name() != "https+++github.com" {
+		t.Errorf("Expected only the other origin to be kept, got %v", entries)
+	}
+}
+
+func TestCleanProfileBackup(t *testing.T) {
+	tests := []struct {
+		name         string
+		browserType  BrowserType
+		cookiesFile  string
+		createCookie func(path string) error
+		table        string
+		backup       bool
+	}{
+		{"chromium with backup", Chrome, "Cookies", testfixtures.CreateChromiumCookiesDB, "cookies", true},
+		{"chromium without backup", Chrome, "Cookies", testfixtures.CreateChromiumCookiesDB, "cookies", false},
+		{"firefox with backup", Firefox, "cookies.sqlite", testfixtures.CreateFirefoxCookiesDB, "moz_cookies", true},
+		{"firefox without backup", Firefox, "cookies.sqlite", testfixtures.CreateFirefoxCookiesDB, "moz_cookies", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profileDir := t.TempDir()
+			if err := tt.createCookie(filepath.Join(profileDir, tt.cookiesFile)); err != nil {
+				t.Fatalf("Failed to create fixture: %v", err)
+			}
+
+			backupDir := t.TempDir()
+			bc := &BrowserCleaner{levelDBScan: DefaultLevelDBScanConfig}
+			bc.SetBackupDir(backupDir)
+			profile := BrowserProfile{Type: tt.browserType, Name: "Test Profile", ProfilePath: profileDir}
+			result := bc.cleanProfile(profile, tt.backup)
+
+			if len(result.Errors) != 0 {
+				t.Fatalf("Expected no errors, got %v", result.Errors)
+			}
+			if result.CookiesDeleted != testfixtures.AugmentCookies {
+				t.Errorf("Expected %d cookies deleted, got %d", testfixtures.AugmentCookies, result.CookiesDeleted)
+			}
+			if !tt.backup {
+				if result.BackupPath != "" {
+					t.Errorf("Expected no backup, got %s", result.BackupPath)
+				}
+				return
+			}
+
+			backupCookies := filepath.Join(result.BackupPath, tt.cookiesFile)
+			if got := countRows(t, backupCookies, tt.table); got != testfixtures.AugmentCookies+testfixtures.OtherCookies {
+				t.Errorf("Expected the backup to hold every cookie from before cleaning, got %d", got)
+			}
+		})
+	}
+}
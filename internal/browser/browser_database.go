@@ -0,0 +1,266 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/retry"
+)
+
+// augmentSQLPatterns are the LIKE patterns matching Augment domains, URLs and
+// cookie names in browser databases
+var augmentSQLPatterns = []string{
+	"%augment%",
+	"%augmentcode%",
+	"%augment-code%",
+	"%vscode-augment%",
+	"%augment.code%",
+	"%augment_telemetry%",
+	"%augment_session%",
+	"%augment_user%",
+	"%augmentai%",
+	"%augment-ai%",
+}
+
+// browserDBBusyTimeout is how long a statement waits for a browser to release
+// the lock on its database before failing
+var browserDBBusyTimeout = 30 * time.Second
+
+// openBrowserDatabase opens a browser SQLite database for writing with a busy
+// timeout, retrying the connection while the browser releases its locks
+func openBrowserDatabase(dbPath string) (*sql.DB, error) {
+	connectionString := fmt.Sprintf("%s?_timeout=%d&_journal_mode=DELETE&_synchronous=NORMAL", dbPath, browserDBBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite3", connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set connection pool settings
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	// Test connection with retry
+	if connectionErr := retry.Do(context.Background(), db.Ping); connectionErr != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database after retries: %w", connectionErr)
+	}
+
+	return db, nil
+}
+
+// vacuumBrowserDatabase rebuilds a browser database, so deleted rows do not
+// remain readable in its free pages
+func vacuumBrowserDatabase(dbPath string) error {
+	db, err := openBrowserDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// augmentRowDelete selects the Augment rows of a table in a browser database;
+// every ? in where is bound to the same LIKE pattern
+type augmentRowDelete struct {
+	table string
+	where string
+}
+
+// deleteAugmentRows runs the deletes for every Augment pattern in a single
+// transaction, skipping tables the database does not have, and returns the number
+// of rows deleted
+func (bc *BrowserCleaner) deleteAugmentRows(dbPath string, deletes []augmentRowDelete) (int64, error) {
+	db, err := openBrowserDatabase(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var totalDeleted int64
+	for _, del := range deletes {
+		exists, err := sqliteTableExists(tx, del.table)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			bc.logger().Debug("Skipping missing table %s in %s", del.table, dbPath)
+			continue
+		}
+
+		query := "DELETE FROM " + del.table + " WHERE " + del.where
+		for _, pattern := range bc.sqlPatterns() {
+			result, err := tx.Exec(query, likeArgs(query, pattern)...)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete from %s with pattern %s: %w", del.table, pattern, err)
+			}
+
+			deleted, err := result.RowsAffected()
+			if err != nil {
+				return 0, fmt.Errorf("failed to get affected rows for pattern %s: %w", pattern, err)
+			}
+			bc.logger().Debug("SQL: %s [%s] -> %d rows", query, pattern, deleted)
+
+			totalDeleted += deleted
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return totalDeleted, nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sqliteTableExists reports whether a SQLite database has the table
+func sqliteTableExists(db queryRower, table string) (bool, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// sqliteTableColumns returns the column names of a table in a SQLite database
+func sqliteTableColumns(db queryer, table string) (map[string]bool, error) {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid          int
+			name, ctype  string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	return columns, nil
+}
+
+// firefoxCookieWhere is the condition selecting Augment cookies in moz_cookies
+// by host, name and value; every ? is bound to the same LIKE pattern
+const firefoxCookieWhere = "host LIKE ? OR name LIKE ? OR value LIKE ?"
+
+// firefoxIsolatedCookieIDs returns the rowids of the cookies in moz_cookies that
+// are isolated under an Augment site. originAttributes holds the partitionKey of
+// Total Cookie Protection (Firefox 86+) and the firstPartyDomain of First-Party
+// Isolation, which some older Firefox versions kept in a column of its own. The
+// site must be one of the augmentDomains, so sites that merely contain "augment"
+// are left alone.
+func firefoxIsolatedCookieIDs(db queryer) ([]interface{}, error) {
+	columns, err := sqliteTableColumns(db, "moz_cookies")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []interface{}
+	seen := make(map[int64]bool)
+	for _, column := range []string{"originAttributes", "firstPartyDomain"} {
+		if !columns[column] {
+			continue
+		}
+
+		rows, err := db.Query("SELECT rowid, " + column + " FROM moz_cookies WHERE " + column + " != ''")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of cookies: %w", column, err)
+		}
+		for rows.Next() {
+			var (
+				id    int64
+				value string
+			)
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to read %s of cookies: %w", column, err)
+			}
+
+			sites := []string{value}
+			if column == "originAttributes" {
+				sites = originAttributeSites(value)
+			}
+			for _, site := range sites {
+				if isAugmentHost(site) && !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of cookies: %w", column, err)
+		}
+	}
+	return ids, nil
+}
+
+// originAttributeSites returns the sites a Firefox origin attributes suffix such as
+// "^userContextId=1&partitionKey=%28https%2Caugmentcode.com%29" isolates a cookie
+// under: the host of its partitionKey and its firstPartyDomain
+func originAttributeSites(attributes string) []string {
+	values, err := url.ParseQuery(strings.TrimPrefix(attributes, "^"))
+	if err != nil {
+		return nil
+	}
+
+	var sites []string
+	// A partitionKey is (scheme,host[,port]), followed by more fields for
+	// partitions with a foreign ancestor
+	if parts := strings.Split(strings.Trim(values.Get("partitionKey"), "()"), ","); len(parts) >= 2 {
+		sites = append(sites, parts[1])
+	}
+	if domain := values.Get("firstPartyDomain"); domain != "" {
+		sites = append(sites, domain)
+	}
+	return sites
+}
+
+// rowIDCondition returns a condition selecting the rows with the given rowids,
+// with one placeholder per id
+func rowIDCondition(ids []interface{}) string {
+	return "rowid IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+}
+
+// likeArgs binds pattern to every placeholder of query
+func likeArgs(query, pattern string) []interface{} {
+	args := make([]interface{}, strings.Count(query, "?"))
+	for i := range args {
+		args[i] = pattern
+	}
+	return args
+}
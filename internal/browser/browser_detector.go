@@ -3,10 +3,12 @@ package browser
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
 )
 
 // BrowserType represents different browser types
@@ -17,8 +19,23 @@ const (
 	Edge
 	Firefox
 	Safari
+	// Chrome channels and Arc come after Safari so the serialized type of existing
+	// profiles does not change
+	ChromeBeta
+	ChromeDev
+	ChromeCanary
+	Arc
+	// WebView2 is the Chromium engine Windows apps embed; each host app keeps its
+	// own user data folder
+	WebView2
 )
 
+// chromiumBrowserTypes are the Chromium-based browsers, whose profiles share one layout
+var chromiumBrowserTypes = []BrowserType{Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2}
+
+// allBrowserTypes are every supported browser
+var allBrowserTypes = []BrowserType{Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2, Firefox, Safari}
+
 // String returns the string representation of the browser type
 func (bt BrowserType) String() string {
 	switch bt {
@@ -30,29 +47,85 @@ func (bt BrowserType) String() string {
 		return "Mozilla Firefox"
 	case Safari:
 		return "Safari"
+	case ChromeBeta:
+		return "Google Chrome Beta"
+	case ChromeDev:
+		return "Google Chrome Dev"
+	case ChromeCanary:
+		return "Google Chrome Canary"
+	case Arc:
+		return "Arc"
+	case WebView2:
+		return "WebView2"
 	default:
 		return "Unknown"
 	}
 }
 
+// ShortName returns the short browser name accepted by ParseBrowserType
+func (bt BrowserType) ShortName() string {
+	switch bt {
+	case Chrome:
+		return "chrome"
+	case Edge:
+		return "edge"
+	case Firefox:
+		return "firefox"
+	case Safari:
+		return "safari"
+	case ChromeBeta:
+		return "chrome-beta"
+	case ChromeDev:
+		return "chrome-dev"
+	case ChromeCanary:
+		return "chrome-canary"
+	case Arc:
+		return "arc"
+	case WebView2:
+		return "webview2"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseBrowserType parses a short browser name: chrome, chrome-beta, chrome-dev,
+// chrome-canary, edge, arc, webview2, firefox or safari
+func ParseBrowserType(name string) (BrowserType, error) {
+	short := strings.ToLower(strings.TrimSpace(name))
+	for _, browserType := range allBrowserTypes {
+		if browserType.ShortName() == short {
+			return browserType, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown browser: %s", name)
+}
+
 // BrowserProfile represents a browser profile/installation
 type BrowserProfile struct {
 	Type        BrowserType `json:"type"`
 	Name        string      `json:"name"`
 	ProfilePath string      `json:"profile_path"`
 	DataPath    string      `json:"data_path"`
-	IsDefault   bool        `json:"is_default"`
+	IsDefault   bool        `json:"is_default"` // Profile the browser opens by default; for Firefox, the default of an installation
 	Version     string      `json:"version,omitempty"`
+	HostApp     string      `json:"host_app,omitempty"` // App embedding a WebView2 profile, e.g. Microsoft Teams
 }
 
 // BrowserDetector handles detection of installed browsers and their profiles
 type BrowserDetector struct {
 	homeDir string
+	detect  func(BrowserType) ([]BrowserProfile, error) // Nil uses detectBrowser
 }
 
 // NewBrowserDetector creates a new browser detector
 func NewBrowserDetector() (*BrowserDetector, error) {
-	homeDir, err := os.UserHomeDir()
+	return NewBrowserDetectorWithResolver(utils.DefaultPathResolver())
+}
+
+// NewBrowserDetectorWithResolver creates a browser detector that looks for
+// profiles under the home directory of resolver
+func NewBrowserDetectorWithResolver(resolver utils.PathResolver) (*BrowserDetector, error) {
+	homeDir, err := resolver.HomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user home directory: %w", err)
 	}
@@ -64,171 +137,184 @@ func NewBrowserDetector() (*BrowserDetector, error) {
 
 // DetectBrowsers detects all installed browsers and their profiles
 func (bd *BrowserDetector) DetectBrowsers() ([]BrowserProfile, error) {
+	profiles, _ := bd.detectBrowsers()
+	return profiles, nil
+}
+
+// detectBrowsers detects the profiles of every browser supported on this OS. A
+// browser whose detection panics, e.g. on a malformed profiles.ini, is returned
+// as a failure while the other browsers are still detected.
+func (bd *BrowserDetector) detectBrowsers() ([]BrowserProfile, []detectionFailure) {
 	var profiles []BrowserProfile
+	var failures []detectionFailure
 	
-	// Detect Chrome profiles
-	chromeProfiles, err := bd.detectChromeProfiles()
-	if err == nil {
-		profiles = append(profiles, chromeProfiles...)
-	}
-	
-	// Detect Edge profiles
-	edgeProfiles, err := bd.detectEdgeProfiles()
-	if err == nil {
-		profiles = append(profiles, edgeProfiles...)
+	// Each Chrome channel is a browser of its own; Safari exists on macOS only
+	browserTypes := append(append([]BrowserType{}, chromiumBrowserTypes...), Firefox)
+	if runtime.GOOS == "darwin" {
+		browserTypes = append(browserTypes, Safari)
 	}
 	
-	// Detect Firefox profiles
-	firefoxProfiles, err := bd.detectFirefoxProfiles()
-	if err == nil {
-		profiles = append(profiles, firefoxProfiles...)
+	detect := bd.detect
+	if detect == nil {
+		detect = bd.detectBrowser
 	}
 	
-	// Detect Safari profiles (macOS only)
-	if runtime.GOOS == "darwin" {
-		safariProfiles, err := bd.detectSafariProfiles()
+	for _, browserType := range browserTypes {
+		browserProfiles, err := detectRecovering(browserType, detect)
+		if panicErr, ok := err.(*panicError); ok {
+			failures = append(failures, detectionFailure{browserType: browserType, err: panicErr})
+			continue
+		}
 		if err == nil {
-			profiles = append(profiles, safariProfiles...)
+			profiles = append(profiles, browserProfiles...)
 		}
 	}
 	
+	// Sort by browser type, then profile name, so results are stable between runs
+	sort.SliceStable(profiles, func(i, j int) bool {
+		if profiles[i].Type != profiles[j].Type {
+			return profiles[i].Type < profiles[j].Type
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+	
+	return profiles, failures
+}
+
+// detectBrowser detects the profiles of a single browser
+func (bd *BrowserDetector) detectBrowser(browserType BrowserType) ([]BrowserProfile, error) {
+	switch browserType {
+	case Firefox:
+		return bd.detectFirefoxProfiles()
+	case Safari:
+		return bd.detectSafariProfiles()
+	case WebView2:
+		return bd.detectWebView2Profiles()
+	default:
+		return bd.detectChromiumProfiles(browserType)
+	}
+}
+
+// detectChromiumProfiles detects the profiles of a Chromium-based browser: the
+// Default profile and every "Profile N" directory of its user data directories
+func (bd *BrowserDetector) detectChromiumProfiles(browserType BrowserType) ([]BrowserProfile, error) {
+	var profiles []BrowserProfile
+	namePrefix := chromiumProfilePrefix(browserType)
+
+	for _, basePath := range chromiumDataDirs(browserType, bd.homeDir, runtime.GOOS) {
+		profiles = append(profiles, chromiumUserDataProfiles(browserType, basePath, namePrefix)...)
+	}
+
 	return profiles, nil
 }
 
-// detectChromeProfiles detects Google Chrome profiles
-func (bd *BrowserDetector) detectChromeProfiles() ([]BrowserProfile, error) {
+// chromiumUserDataProfiles returns the Default profile and every "Profile N"
+// directory of a Chromium user data directory, named "<namePrefix> - <profile>"
+func chromiumUserDataProfiles(browserType BrowserType, basePath, namePrefix string) []BrowserProfile {
 	var profiles []BrowserProfile
-	var chromePaths []string
-	
-	switch runtime.GOOS {
-	case "windows":
-		chromePaths = []string{
-			filepath.Join(bd.homeDir, "AppData", "Local", "Google", "Chrome", "User Data"),
-		}
-	case "darwin":
-		chromePaths = []string{
-			filepath.Join(bd.homeDir, "Library", "Application Support", "Google", "Chrome"),
-		}
-	case "linux":
-		chromePaths = []string{
-			filepath.Join(bd.homeDir, ".config", "google-chrome"),
-			filepath.Join(bd.homeDir, ".config", "chromium"),
-		}
+	if _, err := os.Stat(basePath); os.IsNotExist(err) {
+		return profiles
 	}
-	
-	for _, basePath := range chromePaths {
-		if _, err := os.Stat(basePath); os.IsNotExist(err) {
-			continue
-		}
-		
-		// Default profile
-		defaultProfile := filepath.Join(basePath, "Default")
-		if _, err := os.Stat(defaultProfile); err == nil {
+
+	// Default profile
+	defaultProfile := filepath.Join(basePath, "Default")
+	if _, err := os.Stat(defaultProfile); err == nil {
+		profiles = append(profiles, BrowserProfile{
+			Type:        browserType,
+			Name:        namePrefix + " - Default",
+			ProfilePath: defaultProfile,
+			DataPath:    basePath,
+			IsDefault:   true,
+		})
+	}
+
+	// Additional profiles
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return profiles
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "Profile ") {
+			profilePath := filepath.Join(basePath, entry.Name())
 			profiles = append(profiles, BrowserProfile{
-				Type:        Chrome,
-				Name:        "Chrome - Default",
-				ProfilePath: defaultProfile,
+				Type:        browserType,
+				Name:        fmt.Sprintf("%s - %s", namePrefix, entry.Name()),
+				ProfilePath: profilePath,
 				DataPath:    basePath,
-				IsDefault:   true,
+				IsDefault:   false,
 			})
 		}
-		
-		// Additional profiles
-		entries, err := os.ReadDir(basePath)
-		if err != nil {
-			continue
-		}
-		
-		for _, entry := range entries {
-			if entry.IsDir() && strings.HasPrefix(entry.Name(), "Profile ") {
-				profilePath := filepath.Join(basePath, entry.Name())
-				profiles = append(profiles, BrowserProfile{
-					Type:        Chrome,
-					Name:        fmt.Sprintf("Chrome - %s", entry.Name()),
-					ProfilePath: profilePath,
-					DataPath:    basePath,
-					IsDefault:   false,
-				})
-			}
-		}
 	}
-	
-	return profiles, nil
+
+	return profiles
 }
 
-// detectEdgeProfiles detects Microsoft Edge profiles
-func (bd *BrowserDetector) detectEdgeProfiles() ([]BrowserProfile, error) {
-	var profiles []BrowserProfile
-	var edgePaths []string
-	
-	switch runtime.GOOS {
+// chromiumProfilePrefix returns the browser part of the names of a Chromium-based
+// browser's profiles, e.g. "Chrome Canary" in "Chrome Canary - Default"
+func chromiumProfilePrefix(browserType BrowserType) string {
+	switch browserType {
+	case Chrome:
+		return "Chrome"
+	case ChromeBeta:
+		return "Chrome Beta"
+	case ChromeDev:
+		return "Chrome Dev"
+	case ChromeCanary:
+		return "Chrome Canary"
+	case Edge:
+		return "Edge"
+	default:
+		return browserType.String()
+	}
+}
+
+// chromiumDataDirs returns the user data directories of a Chromium-based browser
+// under home on goos. Every Chrome channel keeps its own directory.
+func chromiumDataDirs(browserType BrowserType, homeDir, goos string) []string {
+	var dirs map[BrowserType][]string
+
+	switch goos {
 	case "windows":
-		edgePaths = []string{
-			filepath.Join(bd.homeDir, "AppData", "Local", "Microsoft", "Edge", "User Data"),
+		local := filepath.Join(homeDir, "AppData", "Local")
+		dirs = map[BrowserType][]string{
+			Chrome:       {filepath.Join(local, "Google", "Chrome", "User Data")},
+			ChromeBeta:   {filepath.Join(local, "Google", "Chrome Beta", "User Data")},
+			ChromeDev:    {filepath.Join(local, "Google", "Chrome Dev", "User Data")},
+			ChromeCanary: {filepath.Join(local, "Google", "Chrome SxS", "User Data")},
+			Edge:         {filepath.Join(local, "Microsoft", "Edge", "User Data")},
 		}
 	case "darwin":
-		edgePaths = []string{
-			filepath.Join(bd.homeDir, "Library", "Application Support", "Microsoft Edge"),
+		support := filepath.Join(homeDir, "Library", "Application Support")
+		dirs = map[BrowserType][]string{
+			Chrome:       {filepath.Join(support, "Google", "Chrome")},
+			ChromeBeta:   {filepath.Join(support, "Google", "Chrome Beta")},
+			ChromeDev:    {filepath.Join(support, "Google", "Chrome Dev")},
+			ChromeCanary: {filepath.Join(support, "Google", "Chrome Canary")},
+			Edge:         {filepath.Join(support, "Microsoft Edge")},
+			Arc:          {filepath.Join(support, "Arc", "User Data")},
 		}
 	case "linux":
-		edgePaths = []string{
-			filepath.Join(bd.homeDir, ".config", "microsoft-edge"),
+		config := filepath.Join(homeDir, ".config")
+		dirs = map[BrowserType][]string{
+			Chrome: {
+				filepath.Join(config, "google-chrome"),
+				filepath.Join(config, "chromium"),
+			},
+			ChromeBeta:   {filepath.Join(config, "google-chrome-beta")},
+			ChromeDev:    {filepath.Join(config, "google-chrome-unstable")},
+			ChromeCanary: {filepath.Join(config, "google-chrome-canary")},
+			Edge:         {filepath.Join(config, "microsoft-edge")},
 		}
 	}
-	
-	for _, basePath := range edgePaths {
-		if _, err := os.Stat(basePath); os.IsNotExist(err) {
-			continue
-		}
-		
-		// Default profile
-		defaultProfile := filepath.Join(basePath, "Default")
-		if _, err := os.Stat(defaultProfile); err == nil {
-			profiles = append(profiles, BrowserProfile{
-				Type:        Edge,
-				Name:        "Edge - Default",
-				ProfilePath: defaultProfile,
-				DataPath:    basePath,
-				IsDefault:   true,
-			})
-		}
-		
-		// Additional profiles
-		entries, err := os.ReadDir(basePath)
-		if err != nil {
-			continue
-		}
-		
-		for _, entry := range entries {
-			if entry.IsDir() && strings.HasPrefix(entry.Name(), "Profile ") {
-				profilePath := filepath.Join(basePath, entry.Name())
-				profiles = append(profiles, BrowserProfile{
-					Type:        Edge,
-					Name:        fmt.Sprintf("Edge - %s", entry.Name()),
-					ProfilePath: profilePath,
-					DataPath:    basePath,
-					IsDefault:   false,
-				})
-			}
-		}
-	}
-	
-	return profiles, nil
+
+	return dirs[browserType]
 }
 
 // detectFirefoxProfiles detects Mozilla Firefox profiles
 func (bd *BrowserDetector) detectFirefoxProfiles() ([]BrowserProfile, error) {
 	var profiles []BrowserProfile
-	var firefoxPath string
-	
-	switch runtime.GOOS {
-	case "windows":
-		firefoxPath = filepath.Join(bd.homeDir, "AppData", "Roaming", "Mozilla", "Firefox")
-	case "darwin":
-		firefoxPath = filepath.Join(bd.homeDir, "Library", "Application Support", "Firefox")
-	case "linux":
-		firefoxPath = filepath.Join(bd.homeDir, ".mozilla", "firefox")
-	}
+	firefoxPath := firefoxDataDir(bd.homeDir, runtime.GOOS)
 	
 	if _, err := os.Stat(firefoxPath); os.IsNotExist(err) {
 		return profiles, nil
@@ -246,68 +332,41 @@ func (bd *BrowserDetector) detectFirefoxProfiles() ([]BrowserProfile, error) {
 		return profiles, nil
 	}
 	
-	lines := strings.Split(string(content), "\n")
-	var currentProfile map[string]string
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		
-		if strings.HasPrefix(line, "[Profile") {
-			if currentProfile != nil {
-				// Process previous profile
-				if path, ok := currentProfile["Path"]; ok {
-					isDefault := currentProfile["Default"] == "1"
-					name := currentProfile["Name"]
-					if name == "" {
-						name = "Firefox Profile"
-					}
-					
-					profilePath := filepath.Join(firefoxPath, path)
-					if _, err := os.Stat(profilePath); err == nil {
-						profiles = append(profiles, BrowserProfile{
-							Type:        Firefox,
-							Name:        fmt.Sprintf("Firefox - %s", name),
-							ProfilePath: profilePath,
-							DataPath:    firefoxPath,
-							IsDefault:   isDefault,
-						})
-					}
-				}
-			}
-			currentProfile = make(map[string]string)
-		} else if strings.Contains(line, "=") && currentProfile != nil {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				currentProfile[parts[0]] = parts[1]
-			}
+	for _, entry := range parseFirefoxProfilesINI(string(content), firefoxPath) {
+		// profiles.ini keeps entries of profiles deleted by hand
+		if _, err := os.Stat(entry.Dir); err != nil {
+			continue
 		}
-	}
-	
-	// Process last profile
-	if currentProfile != nil {
-		if path, ok := currentProfile["Path"]; ok {
-			isDefault := currentProfile["Default"] == "1"
-			name := currentProfile["Name"]
-			if name == "" {
-				name = "Firefox Profile"
-			}
-			
-			profilePath := filepath.Join(firefoxPath, path)
-			if _, err := os.Stat(profilePath); err == nil {
-				profiles = append(profiles, BrowserProfile{
-					Type:        Firefox,
-					Name:        fmt.Sprintf("Firefox - %s", name),
-					ProfilePath: profilePath,
-					DataPath:    firefoxPath,
-					IsDefault:   isDefault,
-				})
-			}
+
+		name := entry.Name
+		if name == "" {
+			name = "Firefox Profile"
 		}
+
+		profiles = append(profiles, BrowserProfile{
+			Type:        Firefox,
+			Name:        fmt.Sprintf("Firefox - %s", name),
+			ProfilePath: entry.Dir,
+			DataPath:    firefoxPath,
+			IsDefault:   entry.IsDefault,
+		})
 	}
 	
 	return profiles, nil
 }
 
+// firefoxDataDir returns the directory holding profiles.ini under home on goos
+func firefoxDataDir(homeDir, goos string) string {
+	switch goos {
+	case "windows":
+		return filepath.Join(homeDir, "AppData", "Roaming", "Mozilla", "Firefox")
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "Firefox")
+	default:
+		return filepath.Join(homeDir, ".mozilla", "firefox")
+	}
+}
+
 // detectSafariProfiles detects Safari profiles (macOS only)
 func (bd *BrowserDetector) detectSafariProfiles() ([]BrowserProfile, error) {
 	var profiles []BrowserProfile
@@ -330,113 +389,5 @@ func (bd *BrowserDetector) detectSafariProfiles() ([]BrowserProfile, error) {
 
 // IsProcessRunning checks if a browser process is currently running
 func (bd *BrowserDetector) IsProcessRunning(browserType BrowserType) (bool, error) {
-	var processNames []string
-
-	switch browserType {
-	case Chrome:
-		switch runtime.GOOS {
-		case "windows":
-			processNames = []string{"chrome.exe", "chrome_proxy.exe", "chrome_crashpad_handler.exe"}
-		case "darwin":
-			processNames = []string{"Google Chrome", "Google Chrome Helper", "chrome"}
-		case "linux":
-			processNames = []string{"chrome", "chromium", "google-chrome", "chrome-sandbox"}
-		}
-	case Edge:
-		switch runtime.GOOS {
-		case "windows":
-			processNames = []string{"msedge.exe", "msedge_proxy.exe", "msedgewebview2.exe"}
-		case "darwin":
-			processNames = []string{"Microsoft Edge", "Microsoft Edge Helper"}
-		case "linux":
-			processNames = []string{"microsoft-edge", "msedge"}
-		}
-	case Firefox:
-		switch runtime.GOOS {
-		case "windows":
-			processNames = []string{"firefox.exe", "plugin-container.exe", "crashreporter.exe"}
-		case "darwin":
-			processNames = []string{"Firefox", "firefox", "plugin-container"}
-		case "linux":
-			processNames = []string{"firefox", "firefox-bin", "plugin-container"}
-		}
-	case Safari:
-		if runtime.GOOS == "darwin" {
-			processNames = []string{"Safari", "com.apple.WebKit.WebContent", "SafariForWebKitDevelopment"}
-		}
-	}
-
-	return bd.checkProcesses(processNames)
-}
-
-// checkProcesses checks if any of the given process names are running
-func (bd *BrowserDetector) checkProcesses(processNames []string) (bool, error) {
-	if len(processNames) == 0 {
-		return false, nil
-	}
-
-	switch runtime.GOOS {
-	case "windows":
-		return bd.checkWindowsProcesses(processNames)
-	case "darwin":
-		return bd.checkMacProcesses(processNames)
-	case "linux":
-		return bd.checkLinuxProcesses(processNames)
-	default:
-		return false, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
-	}
-}
-
-// checkWindowsProcesses checks if processes are running on Windows
-func (bd *BrowserDetector) checkWindowsProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("tasklist", "/fo", "csv", "/nh")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to execute tasklist: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
-// checkMacProcesses checks if processes are running on macOS
-func (bd *BrowserDetector) checkMacProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("ps", "-A")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to execute ps: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
-// checkLinuxProcesses checks if processes are running on Linux
-func (bd *BrowserDetector) checkLinuxProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("ps", "-A")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to execute ps: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return IsBrowserRunning(browserType)
 }
@@ -0,0 +1,311 @@
+package browser
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// historyDelete removes the rows of a history table that belong to the Augment
+// URLs; the %s in where is replaced by one placeholder per URL id
+type historyDelete struct {
+	table string
+	where string
+}
+
+// historyDatabase is a history database file: the table listing its visited URLs
+// by rowid and url, and the deletes run once the Augment URLs are known
+type historyDatabase struct {
+	urlTable string
+	deletes  []historyDelete
+}
+
+// historyQueryer is implemented by *sql.DB and *sql.Tx
+type historyQueryer interface {
+	queryer
+	queryRower
+}
+
+// historyIDBatch is the number of URL ids bound to one statement, well below
+// the SQLite limit on host parameters
+const historyIDBatch = 500
+
+// chromiumHistoryDeletes lists the deletes run per database file. Segments and
+// visits are deleted before the urls rows they reference.
+var chromiumHistoryDeletes = map[string]historyDatabase{
+	"History": {"urls", []historyDelete{
+		{"segment_usage", `segment_id IN (SELECT id FROM segments WHERE url_id IN (%s))`},
+		{"segments", `url_id IN (%s)`},
+		{"visits", `url IN (%s)`},
+		{"urls", `rowid IN (%s)`},
+	}},
+	"Top Sites": {"top_sites", []historyDelete{
+		{"top_sites", `rowid IN (%s)`},
+	}},
+}
+
+// chromiumHistoryFiles are the database files of chromiumHistoryDeletes in cleaning order
+var chromiumHistoryFiles = []string{"History", "Top Sites"}
+
+// firefoxHistoryDeletes lists the deletes run on places.sqlite. Visits of
+// bookmarked places are removed, but the places stay so the bookmarks keep working.
+var firefoxHistoryDeletes = map[string]historyDatabase{
+	"places.sqlite": {"moz_places", []historyDelete{
+		{"moz_historyvisits", `place_id IN (%s)`},
+		{"moz_places", `rowid IN (%s) AND id NOT IN (SELECT fk FROM moz_bookmarks WHERE fk IS NOT NULL)`},
+	}},
+}
+
+// firefoxHistoryFiles are the database files of firefoxHistoryDeletes
+var firefoxHistoryFiles = []string{"places.sqlite"}
+
+// SetIncludeHistory enables removing visited Augment URLs from the History and Top
+// Sites databases of Chromium browsers and places.sqlite of Firefox. It is off by
+// default because history is more invasive to modify.
+func (bc *BrowserCleaner) SetIncludeHistory(include bool) {
+	bc.includeHistory = include
+}
+
+// CleanBrowserHistory removes visited Augment URLs, those whose host is one of
+// the augmentDomains or that match a custom pattern, from the history of a Chromium or Firefox profile and returns the
+// number of history rows removed. The databases are vacuumed afterwards so the
+// rows do not linger in free pages. With createBackup they are copied to the
+// profile backup directory first. It works whether or not SetIncludeHistory is set.
+func (bc *BrowserCleaner) CleanBrowserHistory(profile BrowserProfile, createBackup bool) (int64, error) {
+	var files []string
+	switch profile.Type {
+	case Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2:
+		files = chromiumHistoryFiles
+	case Firefox:
+		files = firefoxHistoryFiles
+	default:
+		return 0, fmt.Errorf("history cleaning is not supported for %s", profile.Type.String())
+	}
+
+	if createBackup {
+		backupPath := bc.profileBackupPath(profile)
+		for _, name := range files {
+			dbPath := filepath.Join(profile.ProfilePath, name)
+			if _, err := os.Stat(dbPath); err != nil {
+				continue
+			}
+			if err := os.MkdirAll(backupPath, 0755); err != nil {
+				return 0, fmt.Errorf("failed to create history backup directory: %w", err)
+			}
+			if err := utils.CopyFile(dbPath, filepath.Join(backupPath, name)); err != nil {
+				return 0, fmt.Errorf("failed to back up %s: %w", name, err)
+			}
+		}
+	}
+
+	if profile.Type == Firefox {
+		return bc.cleanFirefoxHistory(profile.ProfilePath)
+	}
+	return bc.cleanChromiumHistory(profile.ProfilePath)
+}
+
+// cleanChromiumHistory removes Augment URLs, their segments, visits and top sites
+// entries and returns the number of rows deleted
+func (bc *BrowserCleaner) cleanChromiumHistory(profilePath string) (int64, error) {
+	return bc.cleanHistory(profilePath, chromiumHistoryFiles, chromiumHistoryDeletes)
+}
+
+// cleanFirefoxHistory removes Augment places and their visits from places.sqlite
+// and returns the number of rows deleted
+func (bc *BrowserCleaner) cleanFirefoxHistory(profilePath string) (int64, error) {
+	return bc.cleanHistory(profilePath, firefoxHistoryFiles, firefoxHistoryDeletes)
+}
+
+// cleanHistory runs the deletes of each history database file in the profile and
+// vacuums the files rows were deleted from
+func (bc *BrowserCleaner) cleanHistory(profilePath string, files []string, databases map[string]historyDatabase) (int64, error) {
+	var total int64
+	for _, name := range files {
+		dbPath := filepath.Join(profilePath, name)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+
+		deleted, err := bc.deleteHistoryRows(dbPath, databases[name])
+		total += deleted
+		if err != nil {
+			return total, fmt.Errorf("failed to clean %s: %w", name, err)
+		}
+		if deleted > 0 {
+			if err := vacuumBrowserDatabase(dbPath); err != nil {
+				return total, fmt.Errorf("failed to compact %s: %w", name, err)
+			}
+		}
+	}
+	return total, nil
+}
+
+// countChromiumHistory counts the rows cleanChromiumHistory would delete
+func (bc *BrowserCleaner) countChromiumHistory(profilePath string) int64 {
+	return bc.countHistory(profilePath, chromiumHistoryFiles, chromiumHistoryDeletes)
+}
+
+// countFirefoxHistory counts the rows cleanFirefoxHistory would delete
+func (bc *BrowserCleaner) countFirefoxHistory(profilePath string) int64 {
+	return bc.countHistory(profilePath, firefoxHistoryFiles, firefoxHistoryDeletes)
+}
+
+// countHistory counts the rows the deletes would remove from the history database
+// files, opening the databases read-only
+func (bc *BrowserCleaner) countHistory(profilePath string, files []string, databases map[string]historyDatabase) int64 {
+	var count int64
+	for _, name := range files {
+		dbPath := filepath.Join(profilePath, name)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+
+		db, err := sql.Open("sqlite3", "file:"+filepath.ToSlash(dbPath)+"?mode=ro")
+		if err != nil {
+			continue
+		}
+		database := databases[name]
+		ids, err := bc.augmentHistoryURLIDs(db, database.urlTable)
+		if err != nil || len(ids) == 0 {
+			db.Close()
+			continue
+		}
+		for _, del := range database.deletes {
+			if exists, err := sqliteTableExists(db, del.table); err != nil || !exists {
+				continue
+			}
+
+			for _, batch := range historyIDBatches(ids) {
+				query := "SELECT COUNT(*) FROM " + del.table + " WHERE " + historyWhere(del.where, batch)
+				var rows int64
+				if err := db.QueryRow(query, batch...).Scan(&rows); err == nil {
+					count += rows
+				}
+			}
+		}
+		db.Close()
+	}
+	return count
+}
+
+// deleteHistoryRows runs the deletes of a history database for its Augment URLs
+// in a single transaction, skipping tables the database does not have, and
+// returns the number of rows deleted
+func (bc *BrowserCleaner) deleteHistoryRows(dbPath string, database historyDatabase) (int64, error) {
+	db, err := openBrowserDatabase(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := bc.augmentHistoryURLIDs(tx, database.urlTable)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var totalDeleted int64
+	for _, del := range database.deletes {
+		exists, err := sqliteTableExists(tx, del.table)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			bc.logger().Debug("Skipping missing table %s in %s", del.table, dbPath)
+			continue
+		}
+
+		for _, batch := range historyIDBatches(ids) {
+			query := "DELETE FROM " + del.table + " WHERE " + historyWhere(del.where, batch)
+			result, err := tx.Exec(query, batch...)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete from %s: %w", del.table, err)
+			}
+
+			deleted, err := result.RowsAffected()
+			if err != nil {
+				return 0, fmt.Errorf("failed to get affected rows of %s: %w", del.table, err)
+			}
+			bc.logger().Debug("SQL: %s -> %d rows", query, deleted)
+
+			totalDeleted += deleted
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return totalDeleted, nil
+}
+
+// augmentHistoryURLIDs returns the rowids of the Augment URLs in a history table,
+// or none if the database does not have the table. The URL host must be one of
+// the augmentDomains, so pages that merely mention Augment in their title or
+// query are left alone.
+func (bc *BrowserCleaner) augmentHistoryURLIDs(db historyQueryer, table string) ([]interface{}, error) {
+	if exists, err := sqliteTableExists(db, table); err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT rowid, url FROM " + table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read urls of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var ids []interface{}
+	for rows.Next() {
+		var (
+			id     int64
+			rawURL sql.NullString
+		)
+		if err := rows.Scan(&id, &rawURL); err != nil {
+			return nil, fmt.Errorf("failed to read urls of %s: %w", table, err)
+		}
+		if bc.isAugmentURL(rawURL.String) {
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read urls of %s: %w", table, err)
+	}
+	return ids, nil
+}
+
+// isAugmentURL reports whether a visited URL is on one of the augmentDomains or
+// matches a custom pattern
+func (bc *BrowserCleaner) isAugmentURL(rawURL string) bool {
+	if parsed, err := url.Parse(rawURL); err == nil && isAugmentHost(parsed.Hostname()) {
+		return true
+	}
+	return bc.matchesCustomOrigin(rawURL)
+}
+
+// historyIDBatches splits URL ids into batches of at most historyIDBatch
+func historyIDBatches(ids []interface{}) [][]interface{} {
+	var batches [][]interface{}
+	for len(ids) > historyIDBatch {
+		batches = append(batches, ids[:historyIDBatch])
+		ids = ids[historyIDBatch:]
+	}
+	return append(batches, ids)
+}
+
+// historyWhere returns the condition of a historyDelete for a batch of URL ids
+func historyWhere(where string, batch []interface{}) string {
+	return fmt.Sprintf(where, strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", "))
+}
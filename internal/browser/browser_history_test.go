@@ -0,0 +1,197 @@
+package browser
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// createTestDB creates a SQLite database and runs the statements
+func createTestDB(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to run %q: %v", statement, err)
+		}
+	}
+}
+
+// countRows returns the number of rows in a table
+func countRows(t *testing.T, path, table string) int {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return count
+}
+
+func TestCleanChromiumHistory(t *testing.T) {
+	profileDir := t.TempDir()
+	historyDB := filepath.Join(profileDir, "History")
+	topSitesDB := filepath.Join(profileDir, "Top Sites")
+
+	createTestDB(t, historyDB,
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER)`,
+		`CREATE TABLE segments (id INTEGER PRIMARY KEY, name TEXT, url_id INTEGER)`,
+		`CREATE TABLE segment_usage (id INTEGER PRIMARY KEY, segment_id INTEGER, visit_count INTEGER)`,
+		`INSERT INTO urls VALUES (1, 'https://app.augmentcode.com/account', 'Account'), (2, 'https://example.com', 'Augment docs'), (3, 'https://github.com', 'GitHub')`,
+		`INSERT INTO visits (url) VALUES (1), (1), (2), (3)`,
+		`INSERT INTO segments VALUES (1, 'http://app.augmentcode.com/', 1), (2, 'http://github.com/', 3)`,
+		`INSERT INTO segment_usage (segment_id, visit_count) VALUES (1, 2), (2, 1)`,
+	)
+	createTestDB(t, topSitesDB,
+		`CREATE TABLE top_sites (url TEXT, url_rank INTEGER, title TEXT)`,
+		`INSERT INTO top_sites VALUES ('https://www.augmentcode.com/', 0, 'Augment'), ('https://github.com/', 1, 'GitHub')`,
+	)
+
+	bc := &BrowserCleaner{}
+	bc.SetIncludeHistory(true)
+
+	// 1 url + 2 visits + 1 segment + 1 segment usage + 1 top site; the page
+	// titled "Augment docs" is not on an Augment domain and stays
+	if got := bc.countChromiumHistory(profileDir); got != 6 {
+		t.Errorf("Expected a preview of 6 rows, got %d", got)
+	}
+
+	deleted, err := bc.cleanChromiumHistory(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumHistory failed: %v", err)
+	}
+	if deleted != 6 {
+		t.Errorf("Expected 6 rows deleted, got %d", deleted)
+	}
+
+	if got := countRows(t, historyDB, "urls"); got != 2 {
+		t.Errorf("Expected 2 remaining urls, got %d", got)
+	}
+	if got := countRows(t, historyDB, "visits"); got != 2 {
+		t.Errorf("Expected 2 remaining visits, got %d", got)
+	}
+	if got := countRows(t, historyDB, "segments"); got != 1 {
+		t.Errorf("Expected 1 remaining segment, got %d", got)
+	}
+	if got := countRows(t, historyDB, "segment_usage"); got != 1 {
+		t.Errorf("Expected 1 remaining segment usage, got %d", got)
+	}
+	if got := countRows(t, topSitesDB, "top_sites"); got != 1 {
+		t.Errorf("Expected 1 remaining top site, got %d", got)
+	}
+}
+
+func TestCleanBrowserHistoryFirefox(t *testing.T) {
+	profileDir := t.TempDir()
+	placesDB := filepath.Join(profileDir, "places.sqlite")
+	createTestDB(t, placesDB,
+		`CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`CREATE TABLE moz_historyvisits (id INTEGER PRIMARY KEY, place_id INTEGER)`,
+		`CREATE TABLE moz_bookmarks (id INTEGER PRIMARY KEY, fk INTEGER)`,
+		`INSERT INTO moz_places VALUES (1, 'https://app.augmentcode.com/', 'Augment'), (2, 'https://docs.augmentcode.com/', 'Docs'), (3, 'https://github.com/', 'GitHub'), (4, 'https://augmentedreality.example/?q=augmentcode.com', 'Augmented reality')`,
+		`INSERT INTO moz_historyvisits (place_id) VALUES (1), (1), (2), (3), (4)`,
+		`INSERT INTO moz_bookmarks (fk) VALUES (2), (NULL)`,
+	)
+
+	bc := &BrowserCleaner{backupDir: filepath.Join(t.TempDir(), "backups")}
+	profile := BrowserProfile{Type: Firefox, Name: "default", ProfilePath: profileDir}
+
+	// 3 visits + 1 place; the bookmarked place stays
+	if got := bc.countFirefoxHistory(profileDir); got != 4 {
+		t.Errorf("Expected a preview of 4 rows, got %d", got)
+	}
+
+	deleted, err := bc.CleanBrowserHistory(profile, true)
+	if err != nil {
+		t.Fatalf("CleanBrowserHistory failed: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("Expected 4 rows deleted, got %d", deleted)
+	}
+	if got := countRows(t, placesDB, "moz_places"); got != 3 {
+		t.Errorf("Expected the bookmarked and the unrelated places to remain, got %d places", got)
+	}
+	if got := countRows(t, placesDB, "moz_historyvisits"); got != 2 {
+		t.Errorf("Expected 2 remaining visits, got %d", got)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(bc.backupDir, "*", "*", "default", "places.sqlite"))
+	if len(backups) != 1 {
+		t.Fatalf("Expected places.sqlite to be backed up, found %v", backups)
+	}
+	if got := countRows(t, backups[0], "moz_places"); got != 4 {
+		t.Errorf("Expected the backup to hold the original 4 places, got %d", got)
+	}
+
+	if _, err := bc.CleanBrowserHistory(BrowserProfile{Type: Safari, ProfilePath: profileDir}, false); err == nil {
+		t.Error("Expected an error for Safari history")
+	}
+}
+
+func TestCleanChromiumHistoryMissingTables(t *testing.T) {
+	profileDir := t.TempDir()
+	createTestDB(t, filepath.Join(profileDir, "History"),
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`INSERT INTO urls VALUES (1, 'https://augmentcode.com', '')`,
+	)
+
+	bc := &BrowserCleaner{}
+	deleted, err := bc.cleanChromiumHistory(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumHistory failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 row deleted, got %d", deleted)
+	}
+}
+
+func TestChromiumHistoryIsOptIn(t *testing.T) {
+	profileDir := t.TempDir()
+	createTestDB(t, filepath.Join(profileDir, "History"),
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`INSERT INTO urls VALUES (1, 'https://augmentcode.com', '')`,
+	)
+
+	bc := &BrowserCleaner{}
+	profile := BrowserProfile{Type: Chrome, ProfilePath: profileDir}
+	if count := bc.countAugmentData(profile); count.History != 0 {
+		t.Errorf("Expected history not to be counted by default, got %d", count.History)
+	}
+
+	var result BrowserCleanResult
+	bc.cleanChromiumBrowser(profile, &result)
+	if result.HistoryDeleted != 0 {
+		t.Errorf("Expected history not to be cleaned by default, got %d", result.HistoryDeleted)
+	}
+	if got := countRows(t, filepath.Join(profileDir, "History"), "urls"); got != 1 {
+		t.Errorf("Expected history to be untouched, got %d urls", got)
+	}
+}
+
+func TestCleanChromiumHistoryCustomPattern(t *testing.T) {
+	profileDir := t.TempDir()
+	historyDB := filepath.Join(profileDir, "History")
+	createTestDB(t, historyDB,
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`INSERT INTO urls VALUES (1, 'https://acme-ai.example/login', 'Login'), (2, 'https://example.com', 'acme-ai docs')`,
+	)
+
+	bc := &BrowserCleaner{}
+	bc.SetCustomAugmentPatterns("%acme-ai%")
+	deleted, err := bc.cleanChromiumHistory(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumHistory failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected only the URL matching the custom pattern to be deleted, got %d rows", deleted)
+	}
+}
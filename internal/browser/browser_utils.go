@@ -4,9 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"time"
 
@@ -20,7 +18,7 @@ func (bc *BrowserCleaner) cleanSafariBrowser(profile BrowserProfile, result *Bro
 	if _, err := os.Stat(localStorageDir); err == nil {
 		deleted, err := bc.cleanSafariStorage(localStorageDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean storage: %v", err))
+			result.addError("clean storage", err)
 		} else {
 			result.StorageDeleted = deleted
 		}
@@ -31,7 +29,7 @@ func (bc *BrowserCleaner) cleanSafariBrowser(profile BrowserProfile, result *Bro
 	if _, err := os.Stat(webkitStorageDir); err == nil {
 		deleted, err := bc.cleanSafariStorage(webkitStorageDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean WebKit storage: %v", err))
+			result.addError("clean WebKit storage", err)
 		} else {
 			result.StorageDeleted += deleted
 		}
@@ -42,7 +40,7 @@ func (bc *BrowserCleaner) cleanSafariBrowser(profile BrowserProfile, result *Bro
 	if _, err := os.Stat(databasesDir); err == nil {
 		deleted, err := bc.cleanSafariDatabases(databasesDir)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to clean databases: %v", err))
+			result.addError("clean databases", err)
 		} else {
 			result.StorageDeleted += deleted
 		}
@@ -56,36 +54,16 @@ func (bc *BrowserCleaner) cleanSafariBrowser(profile BrowserProfile, result *Bro
 func (bc *BrowserCleaner) cleanSafariStorage(storageDir string) (int64, error) {
 	var deleted int64
 	
-	// Enhanced patterns for Safari storage
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augmentai",
-		"augment-ai",
-	}
-	
 	err := filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			bc.skipPath(path, err)
 			return nil // Skip files we can't access
 		}
 		
-		if !info.IsDir() {
-			fileName := strings.ToLower(info.Name())
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					// Try multiple times to remove the file
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					break
-				}
+		if !info.IsDir() && bc.isAugmentName(info.Name()) {
+			// Try multiple times to remove the file
+			if bc.removeFile(path) {
+				deleted++
 			}
 		}
 		
@@ -104,65 +82,104 @@ func (bc *BrowserCleaner) containsAugmentData(filePath string) bool {
 }
 
 // countAugmentData counts Augment-related data in a browser profile
-func (bc *BrowserCleaner) countAugmentData(profile BrowserProfile) int64 {
-	var count int64
+func (bc *BrowserCleaner) countAugmentData(profile BrowserProfile) ProfileDataCount {
+	count := ProfileDataCount{Profile: profile}
 	
 	switch profile.Type {
-	case Chrome, Edge:
-		count += bc.countChromiumData(profile)
+	case Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2:
+		var storageBytes, extensionBytes int64
+		count.Cookies, count.Storage, storageBytes = bc.countChromiumData(profile)
+		count.Extensions, extensionBytes = bc.countChromiumExtensionData(profile.ProfilePath)
+		count.Bytes = storageBytes + extensionBytes
+		if bc.includeHistory {
+			count.History = bc.countChromiumHistory(profile.ProfilePath)
+		}
 	case Firefox:
-		count += bc.countFirefoxData(profile)
+		count.Cookies, count.Storage, count.Bytes = bc.countFirefoxData(profile)
+		if bc.includeHistory {
+			count.History = bc.countFirefoxHistory(profile.ProfilePath)
+		}
 	case Safari:
-		count += bc.countSafariData(profile)
+		count.Storage, count.Bytes = bc.countSafariData(profile)
 	}
 	
+	count.Total = count.Cookies + count.Storage + count.Extensions + count.History
 	return count
 }
 
-// countChromiumData counts Augment data in Chromium browsers
-func (bc *BrowserCleaner) countChromiumData(profile BrowserProfile) int64 {
-	var count int64
-	
+// countChromiumData counts Augment cookies and local and session storage keys in
+// Chromium browsers, and the size of the storage
+func (bc *BrowserCleaner) countChromiumData(profile BrowserProfile) (cookies, storage, bytes int64) {
 	// Count cookies
-	cookiesDB := filepath.Join(profile.ProfilePath, "Cookies")
-	if _, err := os.Stat(cookiesDB); err == nil {
+	for _, cookiesDB := range chromiumCookieDBs(profile.ProfilePath) {
 		if db, err := sql.Open("sqlite3", cookiesDB); err == nil {
-			defer db.Close()
 			var cookieCount int64
-			query := `SELECT COUNT(*) FROM cookies WHERE host_key LIKE '%augment%' OR name LIKE '%augment%'`
-			if err := db.QueryRow(query).Scan(&cookieCount); err == nil {
-				count += cookieCount
+			where, args := bc.countPatternsWhere("host_key LIKE ? OR name LIKE ?")
+			query := "SELECT COUNT(*) FROM cookies WHERE " + where
+			if err := db.QueryRow(query, args...).Scan(&cookieCount); err == nil {
+				cookies += cookieCount
 			}
+			db.Close()
 		}
 	}
 	
-	// Count storage files
+	// Count the Augment keys in local storage, or the data files deleted when its
+	// database is damaged
 	storageDir := filepath.Join(profile.ProfilePath, "Local Storage", "leveldb")
 	if _, err := os.Stat(storageDir); err == nil {
-		filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
-			if err == nil && !info.IsDir() && strings.Contains(strings.ToLower(info.Name()), "augment") {
-				count++
+		if keys, size, err := countLevelDBKeys(storageDir, bc.isAugmentLocalStorageKey); err == nil {
+			storage, bytes = keys, size
+		} else if isDamagedLevelDB(err) {
+			for _, path := range bc.augmentLevelDBDataFiles(storageDir) {
+				if info, err := os.Stat(path); err == nil {
+					storage++
+					bytes += info.Size()
+				}
 			}
-			return nil
-		})
+		}
 	}
 	
-	return count
+	// Count the Session Storage keys of Augment origins
+	sessionStorageDir := filepath.Join(profile.ProfilePath, "Session Storage")
+	if _, err := os.Stat(sessionStorageDir); err == nil {
+		keys, size := bc.countChromiumSessionStorage(sessionStorageDir)
+		storage += keys
+		bytes += size
+	}
+	
+	return cookies, storage, bytes
 }
 
-// countFirefoxData counts Augment data in Firefox
-func (bc *BrowserCleaner) countFirefoxData(profile BrowserProfile) int64 {
-	var count int64
-	
+// chromiumCookieDBs returns the cookie databases of a Chromium profile. Chromium
+// 96+ and WebView2 keep cookies in Network/Cookies, older versions in Cookies.
+func chromiumCookieDBs(profilePath string) []string {
+	var dbs []string
+	for _, name := range []string{"Cookies", filepath.Join("Network", "Cookies")} {
+		cookiesDB := filepath.Join(profilePath, name)
+		if info, err := os.Stat(cookiesDB); err == nil && !info.IsDir() {
+			dbs = append(dbs, cookiesDB)
+		}
+	}
+	return dbs
+}
+
+// countFirefoxData counts Augment cookies and storage directories in Firefox, and
+// the size of the storage directories
+func (bc *BrowserCleaner) countFirefoxData(profile BrowserProfile) (cookies, storage, bytes int64) {
 	// Count cookies
 	cookiesDB := filepath.Join(profile.ProfilePath, "cookies.sqlite")
 	if _, err := os.Stat(cookiesDB); err == nil {
 		if db, err := sql.Open("sqlite3", cookiesDB); err == nil {
 			defer db.Close()
 			var cookieCount int64
-			query := `SELECT COUNT(*) FROM moz_cookies WHERE host LIKE '%augment%' OR name LIKE '%augment%'`
-			if err := db.QueryRow(query).Scan(&cookieCount); err == nil {
-				count += cookieCount
+			where, args := bc.countPatternsWhere(firefoxCookieWhere)
+			if ids, err := firefoxIsolatedCookieIDs(db); err == nil && len(ids) > 0 {
+				where += " OR " + rowIDCondition(ids)
+				args = append(args, ids...)
+			}
+			query := "SELECT COUNT(*) FROM moz_cookies WHERE " + where
+			if err := db.QueryRow(query, args...).Scan(&cookieCount); err == nil {
+				cookies = cookieCount
 			}
 		}
 	}
@@ -172,18 +189,19 @@ func (bc *BrowserCleaner) countFirefoxData(profile BrowserProfile) int64 {
 	if _, err := os.Stat(storageDir); err == nil {
 		filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
 			if err == nil && info.IsDir() && strings.Contains(strings.ToLower(info.Name()), "augment") {
-				count++
+				storage++
+				bytes += treeSize(path)
+				return filepath.SkipDir
 			}
 			return nil
 		})
 	}
 	
-	return count
+	return cookies, storage, bytes
 }
 
-// countSafariData counts Augment data in Safari
-func (bc *BrowserCleaner) countSafariData(profile BrowserProfile) int64 {
-	var count int64
+// countSafariData counts Augment data in Safari and its size
+func (bc *BrowserCleaner) countSafariData(profile BrowserProfile) (count, bytes int64) {
 	
 	// Count storage files
 	storageDir := filepath.Join(profile.ProfilePath, "LocalStorage")
@@ -191,65 +209,133 @@ func (bc *BrowserCleaner) countSafariData(profile BrowserProfile) int64 {
 		filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
 			if err == nil && !info.IsDir() && strings.Contains(strings.ToLower(info.Name()), "augment") {
 				count++
+				bytes += info.Size()
 			}
 			return nil
 		})
 	}
 	
-	return count
+	return count, bytes
 }
 
-// createProfileBackup creates a backup of the browser profile
+// createProfileBackup backs up the critical files and storage directories of a
+// browser profile to <backupDir>/<browser>/<timestamp>/<profile>
 func (bc *BrowserCleaner) createProfileBackup(profile BrowserProfile) (string, error) {
-	timestamp := time.Now().Unix()
-	backupName := fmt.Sprintf("%s-backup-%d", 
-		strings.ReplaceAll(strings.ToLower(profile.Name), " ", "-"), 
-		timestamp)
-	
-	// Use the same backup directory as other components
-	backupDir := filepath.Join("backups", "browser-data")
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	backupPath := bc.profileBackupPath(profile)
+	if err := os.MkdirAll(backupPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile backup directory: %w", err)
 	}
 	
-	backupPath := filepath.Join(backupDir, backupName)
-	
 	// Create a simple backup by copying critical files
 	criticalFiles := bc.getCriticalFiles(profile)
 	
-	if err := os.MkdirAll(backupPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create profile backup directory: %w", err)
-	}
-	
 	for _, file := range criticalFiles {
 		if _, err := os.Stat(file); err == nil {
 			destFile := filepath.Join(backupPath, filepath.Base(file))
 			if err := utils.CopyFile(file, destFile); err != nil {
 				// Log error but continue with other files
+				bc.logger().Warn("Failed to back up %s: %v", file, err)
 				continue
 			}
 		}
 	}
 	
+	// Storage directories keep their layout relative to the profile
+	for _, dir := range bc.getCriticalDirs(profile) {
+		if err := copyDirectory(filepath.Join(profile.ProfilePath, dir), filepath.Join(backupPath, dir)); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", dir, err)
+		}
+	}
+	
 	return backupPath, nil
 }
 
+// profileBackupPath returns the timestamped <backupDir>/<browser>/<timestamp>/<profile>
+// directory a profile is backed up to
+func (bc *BrowserCleaner) profileBackupPath(profile BrowserProfile) string {
+	// Use the same backup directory as other components unless one is configured
+	backupDir := filepath.Join("backups", "browser-data")
+	if bc.backupDir != "" {
+		backupDir = bc.backupDir
+	}
+
+	return filepath.Join(backupDir,
+		profile.Type.ShortName(),
+		fmt.Sprintf("%d", time.Now().Unix()),
+		strings.ReplaceAll(strings.ToLower(profile.Name), " ", "-"))
+}
+
+// getCriticalDirs returns the storage directories, relative to the profile, that are
+// backed up before cleaning
+func (bc *BrowserCleaner) getCriticalDirs(profile BrowserProfile) []string {
+	switch profile.Type {
+	case Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2:
+		dirs := []string{
+			filepath.Join("Local Storage", "leveldb"),
+			"Session Storage",
+			"Network",
+		}
+		targets, _ := bc.augmentExtensionIDs(profile.ProfilePath)
+		for _, dir := range chromiumExtensionDataDirs(profile.ProfilePath, targets) {
+			rel, err := filepath.Rel(profile.ProfilePath, dir)
+			if err == nil {
+				dirs = append(dirs, rel)
+			}
+		}
+		return dirs
+	}
+	return nil
+}
+
+// copyDirectory copies the files below src to dst; a missing src is not an error
+func copyDirectory(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		// LevelDB's LOCK file is held open by a running browser and holds no data
+		if info.Name() == "LOCK" {
+			return nil
+		}
+		return utils.CopyFile(path, filepath.Join(dst, rel))
+	})
+}
+
 // getCriticalFiles returns a list of critical files to backup for a browser profile
 func (bc *BrowserCleaner) getCriticalFiles(profile BrowserProfile) []string {
 	var files []string
 	
 	switch profile.Type {
-	case Chrome, Edge:
+	case Chrome, ChromeBeta, ChromeDev, ChromeCanary, Edge, Arc, WebView2:
 		files = []string{
 			filepath.Join(profile.ProfilePath, "Cookies"),
 			filepath.Join(profile.ProfilePath, "Preferences"),
+			filepath.Join(profile.ProfilePath, "Secure Preferences"),
 			filepath.Join(profile.ProfilePath, "Local State"),
 		}
+		if bc.includeHistory {
+			files = append(files,
+				filepath.Join(profile.ProfilePath, "History"),
+				filepath.Join(profile.ProfilePath, "Top Sites"))
+		}
 	case Firefox:
 		files = []string{
 			filepath.Join(profile.ProfilePath, "cookies.sqlite"),
 			filepath.Join(profile.ProfilePath, "prefs.js"),
 			filepath.Join(profile.ProfilePath, "places.sqlite"),
+			filepath.Join(profile.ProfilePath, "permissions.sqlite"),
+			filepath.Join(profile.ProfilePath, "content-prefs.sqlite"),
 		}
 	case Safari:
 		files = []string{
@@ -263,132 +349,34 @@ func (bc *BrowserCleaner) getCriticalFiles(profile BrowserProfile) []string {
 
 // IsBrowserRunning checks if a browser process is currently running
 func IsBrowserRunning(browserType BrowserType) (bool, error) {
-	var processNames []string
-
-	switch browserType {
-	case Chrome:
-		processNames = []string{"chrome", "google chrome", "googlechrome"}
-	case Edge:
-		processNames = []string{"msedge", "microsoft edge"}
-	case Firefox:
-		processNames = []string{"firefox", "mozilla firefox"}
-	case Safari:
-		processNames = []string{"safari"}
-	}
-
-	switch runtime.GOOS {
-	case "windows":
-		return checkWindowsProcesses(processNames)
-	case "darwin":
-		return checkMacProcesses(processNames)
-	case "linux":
-		return checkLinuxProcesses(processNames)
-	default:
-		return false, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
-	}
-}
-
-// checkWindowsProcesses checks if processes are running on Windows
-func checkWindowsProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("tasklist", "/fo", "csv", "/nh")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to execute tasklist: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
-// checkMacProcesses checks if processes are running on macOS
-func checkMacProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("ps", "-A")
-	output, err := cmd.Output()
+	processes, err := NewProcessManager().FindProcesses(browserType)
 	if err != nil {
-		return false, fmt.Errorf("failed to execute ps: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
+		return false, err
 	}
 
-	return false, nil
+	return len(processes) > 0, nil
 }
 
-// checkLinuxProcesses checks if processes are running on Linux
-func checkLinuxProcesses(processNames []string) (bool, error) {
-	cmd := exec.Command("ps", "-A")
-	output, err := cmd.Output()
-	if err != nil {
-		return false, fmt.Errorf("failed to execute ps: %w", err)
-	}
-
-	outputStr := strings.ToLower(string(output))
-	for _, name := range processNames {
-		if strings.Contains(outputStr, strings.ToLower(name)) {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
 // cleanSafariDatabases cleans Augment-related databases from Safari
 func (bc *BrowserCleaner) cleanSafariDatabases(databasesDir string) (int64, error) {
 	var deleted int64
 	
-	// Enhanced patterns for Safari databases
-	augmentPatterns := []string{
-		"augment",
-		"augmentcode",
-		"augment-code",
-		"vscode-augment",
-		"augment.code",
-		"augmentai",
-		"augment-ai",
-	}
-	
 	err := filepath.Walk(databasesDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			bc.skipPath(path, err)
 			return nil // Skip files we can't access
 		}
 		
 		if info.IsDir() {
-			dirName := strings.ToLower(info.Name())
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(dirName, pattern) {
-					// Try multiple times to remove the directory
-					for i := 0; i < 3; i++ {
-						if err := os.RemoveAll(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					return filepath.SkipDir
+			if bc.isAugmentName(info.Name()) {
+				if bc.removeTree(path) == nil {
+					deleted++
 				}
+				return filepath.SkipDir
 			}
-		} else {
-			fileName := strings.ToLower(info.Name())
-			for _, pattern := range augmentPatterns {
-				if strings.Contains(fileName, pattern) {
-					for i := 0; i < 3; i++ {
-						if err := os.Remove(path); err == nil {
-							deleted++
-							break
-						}
-						time.Sleep(100 * time.Millisecond)
-					}
-					break
-				}
+		} else if bc.isAugmentName(info.Name()) {
+			if bc.removeFile(path) {
+				deleted++
 			}
 		}
 		
@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateProfileBackupLayout(t *testing.T) {
+	profileDir := t.TempDir()
+	files := map[string]string{
+		"Cookies": "cookies",
+		filepath.Join("Local Storage", "leveldb", "000003.log"): "augment",
+		filepath.Join("Local Storage", "leveldb", "LOCK"):       "",
+		filepath.Join("Session Storage", "CURRENT"):             "MANIFEST-000001",
+	}
+	for name, content := range files {
+		path := filepath.Join(profileDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	backupDir := t.TempDir()
+	bc := &BrowserCleaner{}
+	bc.SetBackupDir(backupDir)
+
+	profile := BrowserProfile{Type: Chrome, Name: "Chrome Default", ProfilePath: profileDir}
+	backupPath, err := bc.createProfileBackup(profile)
+	if err != nil {
+		t.Fatalf("createProfileBackup failed: %v", err)
+	}
+
+	rel, err := filepath.Rel(backupDir, backupPath)
+	if err != nil {
+		t.Fatalf("Backup path %s is outside %s", backupPath, backupDir)
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 3 || parts[0] != "chrome" || parts[2] != "chrome-default" {
+		t.Errorf("Expected <browser>/<timestamp>/<profile> layout, got %s", rel)
+	}
+
+	for _, name := range []string{"Cookies", filepath.Join("Local Storage", "leveldb", "000003.log"), filepath.Join("Session Storage", "CURRENT")} {
+		if _, err := os.Stat(filepath.Join(backupPath, name)); err != nil {
+			t.Errorf("Expected %s in backup: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(backupPath, "Local Storage", "leveldb", "LOCK")); err == nil {
+		t.Error("Expected LevelDB LOCK file to be skipped")
+	}
+}
@@ -0,0 +1,321 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+)
+
+// knownAugmentExtensionIDs are the Chrome Web Store IDs of Augment's own browser
+// extensions, whose data is always removed. Add the ID of every extension Augment
+// publishes here, lower case; IDs of other builds, e.g. unpacked or enterprise
+// installs, are added with SetAugmentExtensionIDs.
+var knownAugmentExtensionIDs = []string{}
+
+// chromiumExtensionSettingsDirs hold one LevelDB folder per extension, named by
+// its ID
+var chromiumExtensionSettingsDirs = []string{"Local Extension Settings", "Sync Extension Settings"}
+
+// chromiumExtensionStateDirs are LevelDB databases shared by every extension, with
+// keys of the form "<extension id>.<key>": the state of extension APIs such as
+// alarms, declarative rules and registered user scripts
+var chromiumExtensionStateDirs = []string{"Extension State", "Extension Rules", "Extension Scripts"}
+
+// chromiumExtensionManifest mirrors the manifest.json fields naming the sites an
+// extension accesses
+type chromiumExtensionManifest struct {
+	HomepageURL             string        `json:"homepage_url"`
+	Permissions             []interface{} `json:"permissions"` // Manifest V2 mixes hosts into these
+	OptionalPermissions     []interface{} `json:"optional_permissions"`
+	HostPermissions         []string      `json:"host_permissions"`
+	OptionalHostPermissions []string      `json:"optional_host_permissions"`
+	ContentScripts          []struct {
+		Matches []string `json:"matches"`
+	} `json:"content_scripts"`
+	ExternallyConnectable struct {
+		Matches []string `json:"matches"`
+	} `json:"externally_connectable"`
+}
+
+// SetAugmentExtensionIDs adds browser extension IDs whose data is removed from
+// Chromium profiles, e.g. from the browser_extension_ids config setting
+func (bc *BrowserCleaner) SetAugmentExtensionIDs(ids ...string) {
+	for _, id := range ids {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			bc.extensionIDs = append(bc.extensionIDs, id)
+		}
+	}
+}
+
+// SetAggressive also removes the data of unknown extensions whose manifest
+// references Augment domains. Without it they are only reported.
+func (bc *BrowserCleaner) SetAggressive(aggressive bool) {
+	bc.aggressive = aggressive
+}
+
+// augmentExtensionIDs returns the extensions whose data is removed from a Chromium
+// profile, and the installed unknown extensions whose manifest references Augment
+// domains. Suspicious extensions are only cleaned with SetAggressive.
+func (bc *BrowserCleaner) augmentExtensionIDs(profilePath string) (targets, suspicious []string) {
+	known := make(map[string]bool)
+	for _, ids := range [][]string{knownAugmentExtensionIDs, bc.extensionIDs} {
+		for _, id := range ids {
+			if !known[id] {
+				known[id] = true
+				targets = append(targets, id)
+			}
+		}
+	}
+
+	suspicious = findSuspiciousExtensions(profilePath, known)
+	if bc.aggressive {
+		targets = append(targets, suspicious...)
+	}
+	return targets, suspicious
+}
+
+// findSuspiciousExtensions returns the IDs of extensions installed in a Chromium
+// profile that are not known but whose manifest references Augment domains, sorted
+func findSuspiciousExtensions(profilePath string, known map[string]bool) []string {
+	entries, err := os.ReadDir(filepath.Join(profilePath, "Extensions"))
+	if err != nil {
+		return nil
+	}
+
+	var suspicious []string
+	for _, entry := range entries {
+		id := strings.ToLower(entry.Name())
+		if !entry.IsDir() || known[id] {
+			continue
+		}
+
+		// Every installed version has its own <version>/manifest.json
+		manifests, _ := filepath.Glob(filepath.Join(profilePath, "Extensions", entry.Name(), "*", "manifest.json"))
+		for _, manifest := range manifests {
+			data, err := os.ReadFile(manifest)
+			if err == nil && manifestReferencesAugment(data) {
+				suspicious = append(suspicious, id)
+				break
+			}
+		}
+	}
+
+	sort.Strings(suspicious)
+	return suspicious
+}
+
+// manifestReferencesAugment reports whether an extension manifest requests access
+// to, or links to, an Augment domain
+func manifestReferencesAugment(data []byte) bool {
+	var manifest chromiumExtensionManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+
+	patterns := []string{manifest.HomepageURL}
+	patterns = append(patterns, manifest.HostPermissions...)
+	patterns = append(patterns, manifest.OptionalHostPermissions...)
+	patterns = append(patterns, manifest.ExternallyConnectable.Matches...)
+	for _, script := range manifest.ContentScripts {
+		patterns = append(patterns, script.Matches...)
+	}
+	for _, permission := range append(manifest.Permissions, manifest.OptionalPermissions...) {
+		if pattern, ok := permission.(string); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern != "" && isAugmentOrigin(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanChromiumExtensions removes the LevelDB folders, the keys in the shared
+// Extension State databases and the extensions.settings preferences of Augment
+// browser extensions. It returns the number of folders, keys and preference
+// entries removed, and the suspicious extensions that were found.
+func (bc *BrowserCleaner) cleanChromiumExtensions(profilePath string) (int64, []string, error) {
+	targets, suspicious := bc.augmentExtensionIDs(profilePath)
+	if !bc.aggressive {
+		for _, id := range suspicious {
+			bc.logger().Warn("Extension %s references Augment domains; its data is only removed in aggressive mode", id)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, suspicious, nil
+	}
+
+	var deleted int64
+	for _, dir := range chromiumExtensionDataDirs(profilePath, targets) {
+		// Release the LevelDB lock first; a database still open elsewhere is skipped
+		if err := cleaner.ReleaseLevelDBLock(dir); err != nil {
+			bc.logger().Warn("Skipping extension settings %s: %v", dir, err)
+			continue
+		}
+		if err := bc.removeTree(dir); err != nil {
+			return deleted, suspicious, fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		bc.logger().Debug("Deleted %s", dir)
+		deleted++
+	}
+
+	match := extensionStateKeyMatcher(targets)
+	for _, dir := range chromiumExtensionStateDBs(profilePath) {
+		if err := cleaner.ReleaseLevelDBLock(dir); err != nil {
+			bc.logger().Warn("Skipping extension state %s: %v", dir, err)
+			continue
+		}
+		keys, err := bc.cleanLevelDBKeys(dir, match)
+		deleted += keys
+		if err != nil {
+			return deleted, suspicious, fmt.Errorf("failed to clean %s: %w", dir, err)
+		}
+	}
+
+	removeSettings := func(data []byte) ([]byte, []string, error) {
+		return removeExtensionSettings(data, targets)
+	}
+	for _, name := range chromiumPreferenceFiles {
+		path := filepath.Join(profilePath, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		removed, err := bc.cleanChromiumPreferencesFile(path, removeSettings)
+		if err != nil {
+			return deleted, suspicious, fmt.Errorf("failed to clean %s: %w", name, err)
+		}
+		deleted += removed
+	}
+
+	return deleted, suspicious, nil
+}
+
+// countChromiumExtensionData counts the LevelDB folders, Extension State keys and
+// preference entries cleanChromiumExtensions would remove, and the size of the
+// folders and keys
+func (bc *BrowserCleaner) countChromiumExtensionData(profilePath string) (int64, int64) {
+	targets, _ := bc.augmentExtensionIDs(profilePath)
+	if len(targets) == 0 {
+		return 0, 0
+	}
+
+	var count, size int64
+	for _, dir := range chromiumExtensionDataDirs(profilePath, targets) {
+		count++
+		size += treeSize(dir)
+	}
+	match := extensionStateKeyMatcher(targets)
+	for _, dir := range chromiumExtensionStateDBs(profilePath) {
+		if keys, keySize, err := countLevelDBKeys(dir, match); err == nil {
+			count += keys
+			size += keySize
+		}
+	}
+	for _, name := range chromiumPreferenceFiles {
+		data, err := os.ReadFile(filepath.Join(profilePath, name))
+		if err != nil {
+			continue
+		}
+		if _, removed, err := removeExtensionSettings(data, targets); err == nil {
+			count += int64(len(removed))
+		}
+	}
+	return count, size
+}
+
+// chromiumExtensionDataDirs returns the existing LevelDB folders of the extensions
+func chromiumExtensionDataDirs(profilePath string, ids []string) []string {
+	var dirs []string
+	for _, id := range ids {
+		for _, name := range chromiumExtensionSettingsDirs {
+			dir := filepath.Join(profilePath, name, id)
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// chromiumExtensionStateDBs returns the existing shared extension LevelDB
+// databases of a profile
+func chromiumExtensionStateDBs(profilePath string) []string {
+	var dirs []string
+	for _, name := range chromiumExtensionStateDirs {
+		dir := filepath.Join(profilePath, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// extensionStateKeyMatcher returns a matcher of the keys of the extensions in a
+// shared extension LevelDB database
+func extensionStateKeyMatcher(ids []string) func(key []byte) bool {
+	prefixes := make([][]byte, len(ids))
+	for i, id := range ids {
+		prefixes[i] = []byte(id + ".")
+	}
+	return func(key []byte) bool {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// removeExtensionSettings removes the extensions.settings.<id> entries of the given
+// extensions, which hold their install state, granted permissions and options.
+// Values it does not modify are written back byte for byte. It returns the new
+// content and the dotted keys that were removed.
+func removeExtensionSettings(data []byte, ids []string) ([]byte, []string, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse preferences: %w", err)
+	}
+
+	extensions, err := decodeJSONObject(root["extensions"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse extension preferences: %w", err)
+	}
+	settings, err := decodeJSONObject(extensions["settings"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse extension settings: %w", err)
+	}
+
+	var removed []string
+	for _, id := range ids {
+		if _, ok := settings[id]; ok {
+			delete(settings, id)
+			removed = append(removed, "extensions.settings."+id)
+		}
+	}
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+
+	if extensions["settings"], err = encodeJSON(settings); err != nil {
+		return nil, nil, err
+	}
+	if root["extensions"], err = encodeJSON(extensions); err != nil {
+		return nil, nil, err
+	}
+	cleaned, err := encodeJSON(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cleaned, removed, nil
+}
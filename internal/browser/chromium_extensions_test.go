@@ -0,0 +1,198 @@
+package browser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	testAugmentExtensionID    = "aaaabbbbccccddddeeeeffffgggghhhh"
+	testSuspiciousExtensionID = "iiiijjjjkkkkllllmmmmnnnnoooopppp"
+	testOtherExtensionID      = "ppppoooonnnnmmmmllllkkkkjjjjiiii"
+)
+
+// createExtensionProfile creates a Chromium profile with the data of a configured
+// Augment extension, an unknown extension referencing Augment domains and an
+// unrelated extension
+func createExtensionProfile(t *testing.T) string {
+	t.Helper()
+	profileDir := t.TempDir()
+
+	for _, id := range []string{testAugmentExtensionID, testSuspiciousExtensionID, testOtherExtensionID} {
+		writeTestFile(t, filepath.Join(profileDir, "Local Extension Settings", id, "000003.log"), "state")
+	}
+	writeTestFile(t, filepath.Join(profileDir, "Sync Extension Settings", testAugmentExtensionID, "000003.log"), "state")
+	writeTestFile(t, filepath.Join(profileDir, "Extensions", testSuspiciousExtensionID, "1.2.0_0", "manifest.json"),
+		`{"name": "Helper", "host_permissions": ["https://*.augmentcode.com/*"]}`)
+	writeTestFile(t, filepath.Join(profileDir, "Extensions", testOtherExtensionID, "3.0_0", "manifest.json"),
+		`{"name": "Other", "host_permissions": ["https://example.com/*"]}`)
+	writeTestFile(t, filepath.Join(profileDir, "Preferences"), `{"extensions":{"settings":{`+
+		`"`+testAugmentExtensionID+`":{"state":1},`+
+		`"`+testSuspiciousExtensionID+`":{"state":1},`+
+		`"`+testOtherExtensionID+`":{"state":1}}},"homepage":"https://example.com/"}`)
+
+	return profileDir
+}
+
+// extensionSettingsIDs returns the extension IDs left in extensions.settings of Preferences
+func extensionSettingsIDs(t *testing.T, profileDir string) map[string]bool {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(profileDir, "Preferences"))
+	if err != nil {
+		t.Fatalf("Failed to read Preferences: %v", err)
+	}
+	var prefs struct {
+		Extensions struct {
+			Settings map[string]json.RawMessage `json:"settings"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		t.Fatalf("Failed to parse Preferences: %v", err)
+	}
+	ids := make(map[string]bool)
+	for id := range prefs.Extensions.Settings {
+		ids[id] = true
+	}
+	return ids
+}
+
+func TestCleanChromiumExtensions(t *testing.T) {
+	profileDir := createExtensionProfile(t)
+	profile := BrowserProfile{Type: Chrome, ProfilePath: profileDir}
+
+	bc := &BrowserCleaner{}
+	bc.SetAugmentExtensionIDs(" AAAABBBBCCCCDDDDEEEEFFFFGGGGHHHH ")
+
+	if count := bc.countAugmentData(profile); count.Extensions != 3 {
+		t.Errorf("Expected 2 folders and 1 preference entry counted, got %d", count.Extensions)
+	}
+
+	var result BrowserCleanResult
+	bc.cleanChromiumBrowser(profile, &result)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if result.ExtensionDataDeleted != 3 {
+		t.Errorf("Expected 2 folders and 1 preference entry removed, got %d", result.ExtensionDataDeleted)
+	}
+	if want := []string{testSuspiciousExtensionID}; !reflect.DeepEqual(result.SuspiciousExtensions, want) {
+		t.Errorf("Expected suspicious extensions %v, got %v", want, result.SuspiciousExtensions)
+	}
+
+	for dir, wantExists := range map[string]bool{
+		filepath.Join("Local Extension Settings", testAugmentExtensionID):    false,
+		filepath.Join("Sync Extension Settings", testAugmentExtensionID):     false,
+		filepath.Join("Local Extension Settings", testSuspiciousExtensionID): true,
+		filepath.Join("Local Extension Settings", testOtherExtensionID):      true,
+	} {
+		if _, err := os.Stat(filepath.Join(profileDir, dir)); (err == nil) != wantExists {
+			t.Errorf("Expected %s to exist: %v, got error %v", dir, wantExists, err)
+		}
+	}
+
+	ids := extensionSettingsIDs(t, profileDir)
+	if ids[testAugmentExtensionID] || !ids[testSuspiciousExtensionID] || !ids[testOtherExtensionID] {
+		t.Errorf("Expected only the Augment extension's settings to be removed, got %v", ids)
+	}
+}
+
+func TestCleanChromiumExtensionsAggressive(t *testing.T) {
+	profileDir := createExtensionProfile(t)
+
+	bc := &BrowserCleaner{}
+	bc.SetAggressive(true)
+
+	deleted, suspicious, err := bc.cleanChromiumExtensions(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumExtensions failed: %v", err)
+	}
+	if deleted != 2 || len(suspicious) != 1 {
+		t.Errorf("Expected the suspicious extension's folder and settings removed, got %d removed, suspicious %v", deleted, suspicious)
+	}
+	if _, err := os.Stat(filepath.Join(profileDir, "Local Extension Settings", testSuspiciousExtensionID)); !os.IsNotExist(err) {
+		t.Errorf("Expected the suspicious extension's folder to be removed, got %v", err)
+	}
+	if ids := extensionSettingsIDs(t, profileDir); !ids[testOtherExtensionID] || len(ids) != 2 {
+		t.Errorf("Expected the other extensions' settings to survive, got %v", ids)
+	}
+}
+
+func TestManifestReferencesAugment(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     bool
+	}{
+		{"host permission", `{"host_permissions": ["https://app.augmentcode.com/*"]}`, true},
+		{"content script", `{"content_scripts": [{"matches": ["https://example.com/*", "*://*.augmentcode.com/*"]}]}`, true},
+		{"manifest v2 permission", `{"permissions": ["storage", {"fileSystem": ["write"]}, "https://augmentcode.com/"]}`, true},
+		{"homepage", `{"homepage_url": "https://www.augmentcode.com"}`, true},
+		{"unrelated", `{"name": "Other", "permissions": ["storage"], "host_permissions": ["<all_urls>"]}`, false},
+		{"malformed", `{"host_permissions": "https://augmentcode.com/*"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manifestReferencesAugment([]byte(tt.manifest)); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBrowserDataCountBytesMatchBytesFreed(t *testing.T) {
+	profileDir := createExtensionProfile(t)
+	writeTestFile(t, filepath.Join(profileDir, "Local Storage", "leveldb", "augment-code.log"), string(make([]byte, 2048)))
+	writeTestFile(t, filepath.Join(profileDir, "Local Storage", "leveldb", "CURRENT"), "MANIFEST-000001")
+	profile := BrowserProfile{Type: Chrome, ProfilePath: profileDir}
+
+	bc := &BrowserCleaner{levelDBScan: DefaultLevelDBScanConfig}
+	bc.SetAugmentExtensionIDs(testAugmentExtensionID)
+
+	// The storage file and both LevelDB folders of the Augment extension
+	count := bc.countAugmentData(profile)
+	if want := int64(2048 + 2*len("state")); count.Bytes != want {
+		t.Errorf("Expected an estimate of %d bytes, got %d", want, count.Bytes)
+	}
+
+	result := bc.cleanProfile(profile, false)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if result.BytesFreed != count.Bytes {
+		t.Errorf("Expected the estimated %d bytes to be freed, got %d", count.Bytes, result.BytesFreed)
+	}
+}
+
+func TestCleanChromiumExtensionsRemovesExtensionStateKeys(t *testing.T) {
+	profileDir := createExtensionProfile(t)
+	stateDir := filepath.Join(profileDir, "Extension State")
+	createLocalStorage(t, stateDir, map[string]string{
+		testAugmentExtensionID + ".alarms":       "[]",
+		testAugmentExtensionID + ".storage":      "token",
+		testOtherExtensionID + ".alarms":         "[]",
+		testAugmentExtensionID[:16] + ".unknown": "kept", // Not the extension's full ID
+	})
+
+	bc := &BrowserCleaner{}
+	bc.SetAugmentExtensionIDs(testAugmentExtensionID)
+
+	// 2 folders, 2 Extension State keys and 1 preference entry
+	if count, _ := bc.countChromiumExtensionData(profileDir); count != 5 {
+		t.Errorf("Expected 5 items counted, got %d", count)
+	}
+
+	deleted, _, err := bc.cleanChromiumExtensions(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumExtensions failed: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("Expected 5 items removed, got %d", deleted)
+	}
+	if keys, _, err := countLevelDBKeys(stateDir, func([]byte) bool { return true }); err != nil || keys != 2 {
+		t.Errorf("Expected the 2 keys of other extensions to remain, got %d (%v)", keys, err)
+	}
+}
@@ -0,0 +1,193 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// chromiumPreferenceFiles are the JSON preference files of a Chromium profile.
+// Secure Preferences holds the same content settings plus protection.macs, the
+// HMACs Chromium uses to detect tampering, which are never modified.
+var chromiumPreferenceFiles = []string{"Preferences", "Secure Preferences"}
+
+// cleanChromiumPreferences removes the per-origin permissions, notification grants
+// and engagement scores of Augment origins from Preferences and Secure Preferences.
+// These survive cookie cleaning and re-identify the user. It returns the number of
+// entries removed.
+func (bc *BrowserCleaner) cleanChromiumPreferences(profilePath string) (int64, error) {
+	var total int64
+	for _, name := range chromiumPreferenceFiles {
+		path := filepath.Join(profilePath, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		removed, err := bc.cleanChromiumPreferencesFile(path, removeAugmentPreferences)
+		if err != nil {
+			return total, fmt.Errorf("failed to clean %s: %w", name, err)
+		}
+		total += removed
+	}
+	return total, nil
+}
+
+// cleanChromiumPreferencesFile removes entries from one preferences file with clean,
+// which returns the new content and the removed keys, and rewrites it atomically.
+// The file is left untouched when nothing matches.
+func (bc *BrowserCleaner) cleanChromiumPreferencesFile(path string, clean func([]byte) ([]byte, []string, error)) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read preferences: %w", err)
+	}
+
+	cleaned, removed, err := clean(data)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range removed {
+		bc.logger().Debug("Removed preference %s from %s", key, path)
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	if err := utils.WriteFileAtomic(path, cleaned, 0644); err != nil {
+		return 0, err
+	}
+	return int64(len(removed)), nil
+}
+
+// removeAugmentPreferences removes Augment origins from the content setting
+// exceptions (profile.content_settings.exceptions.<setting>.<origin pattern>) and
+// from the top-level site_engagement dictionary of older versions. Values it does
+// not modify are written back byte for byte. It returns the new content and the
+// dotted keys that were removed.
+func removeAugmentPreferences(data []byte) ([]byte, []string, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse preferences: %w", err)
+	}
+
+	var removed []string
+
+	profile, err := decodeJSONObject(root["profile"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse profile preferences: %w", err)
+	}
+	contentSettings, err := decodeJSONObject(profile["content_settings"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse content settings: %w", err)
+	}
+	exceptions, err := decodeJSONObject(contentSettings["exceptions"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse content setting exceptions: %w", err)
+	}
+
+	exceptionsChanged := false
+	for setting, raw := range exceptions {
+		origins, err := decodeJSONObject(raw)
+		if err != nil {
+			continue // Not a dictionary of origin patterns
+		}
+		keys := removeAugmentOrigins(origins)
+		if len(keys) == 0 {
+			continue
+		}
+		if exceptions[setting], err = encodeJSON(origins); err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			removed = append(removed, "profile.content_settings.exceptions."+setting+"."+key)
+		}
+		exceptionsChanged = true
+	}
+
+	if exceptionsChanged {
+		if contentSettings["exceptions"], err = encodeJSON(exceptions); err != nil {
+			return nil, nil, err
+		}
+		if profile["content_settings"], err = encodeJSON(contentSettings); err != nil {
+			return nil, nil, err
+		}
+		if root["profile"], err = encodeJSON(profile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if engagement, err := decodeJSONObject(root["site_engagement"]); err == nil {
+		keys := removeAugmentOrigins(engagement)
+		if len(keys) > 0 {
+			if root["site_engagement"], err = encodeJSON(engagement); err != nil {
+				return nil, nil, err
+			}
+			for _, key := range keys {
+				removed = append(removed, "site_engagement."+key)
+			}
+		}
+	}
+
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+
+	cleaned, err := encodeJSON(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cleaned, removed, nil
+}
+
+// removeAugmentOrigins deletes the keys of origins that belong to Augment, such as
+// "https://app.augmentcode.com:443,*", and returns them
+func removeAugmentOrigins(origins map[string]json.RawMessage) []string {
+	var removed []string
+	for origin := range origins {
+		if isAugmentOrigin(origin) {
+			delete(origins, origin)
+			removed = append(removed, origin)
+		}
+	}
+	return removed
+}
+
+// isAugmentOrigin reports whether a content settings origin pattern refers to one
+// of the augmentDomains. Patterns may pair a primary and a secondary origin with a
+// comma.
+func isAugmentOrigin(pattern string) bool {
+	for _, origin := range strings.Split(pattern, ",") {
+		if isAugmentHost(originHost(origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSONObject decodes a JSON object, returning an empty map for missing values
+func decodeJSONObject(raw json.RawMessage) (map[string]json.RawMessage, error) {
+	object := make(map[string]json.RawMessage)
+	if len(raw) == 0 {
+		return object, nil
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// encodeJSON encodes a value compactly like Chromium does, without escaping HTML
+// characters in the values that are copied through
+func encodeJSON(value interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
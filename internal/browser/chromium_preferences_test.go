@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSecurePreferences = `{"extensions":{"settings":{}},` +
+	`"profile":{"content_settings":{"exceptions":{` +
+	`"notifications":{"https://app.augmentcode.com:443,*":{"setting":1},"https://example.com:443,*":{"setting":2}},` +
+	`"site_engagement":{"https://augmentcode.com:443,*":{"setting":{"rawScore":4.5}}},` +
+	`"cookies":{}}},"name":"Person 1"},` +
+	`"protection":{"macs":{"profile":{"content_settings":"ABCDEF0123&<>"}}},` +
+	`"site_engagement":{"https://www.augmentcode.com":{"score":2},"https://github.com":{"score":9}}}`
+
+func TestRemoveAugmentPreferences(t *testing.T) {
+	cleaned, removed, err := removeAugmentPreferences([]byte(testSecurePreferences))
+	if err != nil {
+		t.Fatalf("removeAugmentPreferences failed: %v", err)
+	}
+	if len(removed) != 3 {
+		t.Fatalf("Expected 3 removed entries, got %v", removed)
+	}
+
+	content := string(cleaned)
+	if strings.Contains(content, "augment") {
+		t.Errorf("Augment origins were not removed: %s", content)
+	}
+	for _, kept := range []string{"https://example.com:443,*", "https://github.com", `"name":"Person 1"`} {
+		if !strings.Contains(content, kept) {
+			t.Errorf("Expected %s to be kept: %s", kept, content)
+		}
+	}
+	// The HMACs are copied through byte for byte, including HTML characters
+	if !strings.Contains(content, `"protection":{"macs":{"profile":{"content_settings":"ABCDEF0123&<>"}}}`) {
+		t.Errorf("Expected protection.macs to be untouched: %s", content)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(cleaned, &parsed); err != nil {
+		t.Fatalf("Cleaned preferences are not valid JSON: %v", err)
+	}
+}
+
+func TestRemoveAugmentPreferencesNoMatch(t *testing.T) {
+	data := []byte(`{"profile":{"content_settings":{"exceptions":{"cookies":{"https://example.com:443,*":{}}}}}}`)
+	cleaned, removed, err := removeAugmentPreferences(data)
+	if err != nil {
+		t.Fatalf("removeAugmentPreferences failed: %v", err)
+	}
+	if len(removed) != 0 || string(cleaned) != string(data) {
+		t.Errorf("Expected preferences without Augment origins to be unchanged, got %s", cleaned)
+	}
+
+	if _, _, err := removeAugmentPreferences([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid preferences")
+	}
+}
+
+func TestCleanChromiumPreferences(t *testing.T) {
+	profileDir := t.TempDir()
+	securePath := filepath.Join(profileDir, "Secure Preferences")
+	if err := os.WriteFile(securePath, []byte(testSecurePreferences), 0600); err != nil {
+		t.Fatalf("Failed to write Secure Preferences: %v", err)
+	}
+	untouched := `{"browser":{"window_placement":{}}}`
+	prefsPath := filepath.Join(profileDir, "Preferences")
+	if err := os.WriteFile(prefsPath, []byte(untouched), 0600); err != nil {
+		t.Fatalf("Failed to write Preferences: %v", err)
+	}
+
+	bc := &BrowserCleaner{}
+	removed, err := bc.cleanChromiumPreferences(profileDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumPreferences failed: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("Expected 3 entries removed, got %d", removed)
+	}
+
+	data, _ := os.ReadFile(prefsPath)
+	if string(data) != untouched {
+		t.Errorf("Expected Preferences to be unchanged, got %s", data)
+	}
+	data, _ = os.ReadFile(securePath)
+	if strings.Contains(string(data), "augment") {
+		t.Errorf("Expected Augment origins to be removed from Secure Preferences: %s", data)
+	}
+
+	entries, _ := os.ReadDir(profileDir)
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files to remain, got %d entries", len(entries))
+	}
+}
+
+func TestIsAugmentOrigin(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"https://app.augmentcode.com:443,*", true},
+		{"https://[*.]augmentcode.com,*", true},
+		{"[*.]augmentcode.com", true},
+		{"*://*.augmentcode.com/*", true},
+		{"https://augmentcode.com", true},
+		{"*,https://www.augmentcode.com:443", true},
+		{"https://augmentedreality.com:443,*", false},
+		{"https://augmentcode.com.example.net", false},
+		{"https://github.com/augmentcode", false},
+		{"https://notaugmentcode.com", false},
+		{"<all_urls>", false},
+	}
+	for _, tt := range tests {
+		if got := isAugmentOrigin(tt.pattern); got != tt.want {
+			t.Errorf("isAugmentOrigin(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
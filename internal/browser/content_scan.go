@@ -0,0 +1,211 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/likepattern"
+)
+
+const (
+	// DefaultMaxScanBytes is how much of each storage file is searched for Augment data
+	DefaultMaxScanBytes int64 = 64 * 1024
+
+	// contentScanChunkSize is the number of new bytes searched per window
+	contentScanChunkSize = 4 * 1024
+
+	// binarySniffSize is the prefix inspected to tell binary from text content
+	binarySniffSize = 512
+)
+
+// augmentContentPatterns are the lowercase byte sequences that mark Augment data
+// inside LevelDB, cache and storage files
+var augmentContentPatterns = [][]byte{
+	[]byte("augment"),
+	[]byte("augmentcode"),
+	[]byte("augment-code"),
+	[]byte("vscode-augment"),
+	[]byte("augment.code"),
+	[]byte("augmentai"),
+	[]byte("augment-ai"),
+}
+
+// LevelDBScanConfig controls how LevelDB files larger than the scanned prefix are
+// sampled: ChunkSize bytes are read at Positions evenly spaced offsets (0%, 10%,
+// ... 90% of the file size for ten positions). Values of zero or less use
+// DefaultLevelDBScanConfig.
+type LevelDBScanConfig struct {
+	Positions int
+	ChunkSize int
+}
+
+// DefaultLevelDBScanConfig samples ten 512 byte chunks, about 5KB per file
+var DefaultLevelDBScanConfig = LevelDBScanConfig{Positions: 10, ChunkSize: 512}
+
+// SetLevelDBScanConfig configures the sampling of large LevelDB files
+func (bc *BrowserCleaner) SetLevelDBScanConfig(config LevelDBScanConfig) {
+	bc.levelDBScan = config
+}
+
+// SetMaxScanBytes limits how many bytes of each file are searched for Augment data.
+// Values of zero or less restore DefaultMaxScanBytes.
+func (bc *BrowserCleaner) SetMaxScanBytes(limit int64) {
+	bc.maxScanBytes = limit
+}
+
+// fileContainsAugmentData checks if the first maxScanBytes of a file contain
+// Augment-related data. The file is searched in overlapping windows, so patterns
+// spanning two chunks are still found. Matching ignores case; in mostly-NUL
+// (binary) files such as LevelDB SST tables only ASCII letters are folded.
+func (bc *BrowserCleaner) fileContainsAugmentData(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	limit := bc.maxScanBytes
+	if limit <= 0 {
+		limit = DefaultMaxScanBytes
+	}
+	reader := bufio.NewReaderSize(io.LimitReader(file, limit), contentScanChunkSize)
+
+	sniff, _ := reader.Peek(binarySniffSize)
+	binary := isBinaryContent(sniff)
+
+	overlap := max(longestAugmentPattern(), bc.longestCustomPattern()) - 1
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, contentScanChunkSize+overlap), contentScanChunkSize+overlap)
+	scanner.Split(overlappingWindows(contentScanChunkSize, overlap))
+
+	for scanner.Scan() {
+		if bc.containsAugmentPattern(scanner.Bytes(), binary) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// levelDBFileContainsAugmentData checks a LevelDB file for Augment data. SST
+// tables can be hundreds of megabytes with identifiers anywhere in them, so past
+// the prefix fileContainsAugmentData searches, chunks at the sample positions of
+// the LevelDB scan config are searched as well.
+func (bc *BrowserCleaner) levelDBFileContainsAugmentData(filePath string) bool {
+	if bc.fileContainsAugmentData(filePath) {
+		return true
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	limit := bc.maxScanBytes
+	if limit <= 0 {
+		limit = DefaultMaxScanBytes
+	}
+	size := info.Size()
+	if size <= limit {
+		return false // The prefix was the whole file
+	}
+
+	positions, chunkSize := bc.levelDBScan.Positions, bc.levelDBScan.ChunkSize
+	if positions <= 0 {
+		positions = DefaultLevelDBScanConfig.Positions
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultLevelDBScanConfig.ChunkSize
+	}
+
+	sniff := make([]byte, binarySniffSize)
+	n, _ := file.ReadAt(sniff, 0)
+	binary := isBinaryContent(sniff[:n])
+
+	chunk := make([]byte, chunkSize)
+	for i := 0; i < positions; i++ {
+		offset := size * int64(i) / int64(positions)
+		if offset+int64(chunkSize) <= limit {
+			continue // Already searched as part of the prefix
+		}
+		n, err := file.ReadAt(chunk, offset)
+		if n == 0 && err != nil {
+			continue
+		}
+		if bc.containsAugmentPattern(chunk[:n], binary) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlappingWindows returns a bufio.SplitFunc yielding windows of up to
+// size+overlap bytes that advance by size, so every sequence of at most
+// overlap+1 bytes lies entirely within one window
+func overlappingWindows(size, overlap int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) == 0 {
+			return 0, nil, nil
+		}
+		if len(data) >= size+overlap {
+			return size, data[:size+overlap], nil
+		}
+		if !atEOF {
+			return 0, nil, nil // Request more data
+		}
+		// The rest fits into one window; consuming it all ends the scan
+		return len(data), data, nil
+	}
+}
+
+// isBinaryContent reports whether more than 30% of data are NUL bytes
+func isBinaryContent(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	return bytes.Count(data, []byte{0})*10 > len(data)*3
+}
+
+// longestAugmentPattern returns the length of the longest augmentContentPatterns entry
+func longestAugmentPattern() int {
+	longest := 0
+	for _, pattern := range augmentContentPatterns {
+		longest = max(longest, len(pattern))
+	}
+	return longest
+}
+
+// MatchAugmentPatterns returns the Augment patterns of browser cleaning that value
+// contains, case-insensitively: the content patterns storage and cache files are
+// searched for, the LIKE patterns of database rows such as cookies, and the
+// custom LIKE patterns given
+func MatchAugmentPatterns(value string, custom ...string) []string {
+	lowerValue := strings.ToLower(value)
+
+	var rules []string
+	for _, pattern := range augmentContentPatterns {
+		if strings.Contains(lowerValue, string(pattern)) {
+			rules = append(rules, "file content: "+string(pattern))
+		}
+	}
+	for _, pattern := range augmentSQLPatterns {
+		if strings.Contains(lowerValue, strings.Trim(pattern, "%")) {
+			rules = append(rules, "database: "+pattern)
+		}
+	}
+	for _, pattern := range custom {
+		if likepattern.Validate(pattern) == nil && likepattern.CompileSearch(pattern).MatchString(value) {
+			rules = append(rules, "custom: "+pattern)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeScanFile writes content to a file in a temporary directory
+func writeScanFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "000005")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestFileContainsAugmentDataBeyondFirstKilobyte(t *testing.T) {
+	content := append(bytes.Repeat([]byte("x"), 20*1024), []byte("https://app.AugmentCode.com")...)
+	bc := &BrowserCleaner{}
+	if !bc.fileContainsAugmentData(writeScanFile(t, content)) {
+		t.Error("Expected pattern at 20KB to be found")
+	}
+}
+
+func TestFileContainsAugmentDataAcrossChunkBoundary(t *testing.T) {
+	// "augment" starts 3 bytes before the end of the first 4KB chunk
+	content := append(bytes.Repeat([]byte("x"), contentScanChunkSize-3), []byte("augment")...)
+	content = append(content, bytes.Repeat([]byte("y"), 2*contentScanChunkSize)...)
+	bc := &BrowserCleaner{}
+	if !bc.fileContainsAugmentData(writeScanFile(t, content)) {
+		t.Error("Expected pattern spanning two chunks to be found")
+	}
+}
+
+func TestFileContainsAugmentDataRespectsLimit(t *testing.T) {
+	content := append(bytes.Repeat([]byte("x"), 100*1024), []byte("augment")...)
+	path := writeScanFile(t, content)
+
+	bc := &BrowserCleaner{}
+	if bc.fileContainsAugmentData(path) {
+		t.Error("Expected pattern past DefaultMaxScanBytes to be ignored")
+	}
+
+	bc.SetMaxScanBytes(200 * 1024)
+	if !bc.fileContainsAugmentData(path) {
+		t.Error("Expected pattern within a raised limit to be found")
+	}
+}
+
+func TestLevelDBFileContainsAugmentDataSamplesLargeFiles(t *testing.T) {
+	const size = 1024 * 1024
+	content := make([]byte, size)
+	copy(content[size/2+100:], "vscode-augment")
+	path := writeScanFile(t, content)
+
+	bc := &BrowserCleaner{}
+	if bc.fileContainsAugmentData(path) {
+		t.Fatal("Expected pattern in the middle of the file to be past the scanned prefix")
+	}
+	if !bc.levelDBFileContainsAugmentData(path) {
+		t.Error("Expected pattern at the 50% sample position to be found")
+	}
+
+	// Two positions sample 0% and 50%, and 64 bytes stop short of the pattern
+	bc.SetLevelDBScanConfig(LevelDBScanConfig{Positions: 2, ChunkSize: 64})
+	if bc.levelDBFileContainsAugmentData(path) {
+		t.Error("Expected pattern outside the sampled chunks to be missed")
+	}
+
+	bc.SetLevelDBScanConfig(LevelDBScanConfig{Positions: 2, ChunkSize: 256})
+	if !bc.levelDBFileContainsAugmentData(path) {
+		t.Error("Expected pattern within a larger chunk to be found")
+	}
+}
+
+func TestFileContainsAugmentDataBinary(t *testing.T) {
+	content := make([]byte, 8*1024)
+	copy(content[6000:], "vscode-augment")
+	bc := &BrowserCleaner{}
+	if !bc.fileContainsAugmentData(writeScanFile(t, content)) {
+		t.Error("Expected pattern in binary content to be found")
+	}
+
+	mixedCase := make([]byte, 8*1024)
+	copy(mixedCase[6000:], "Augment.vscode-Augment\xff")
+	if !bc.fileContainsAugmentData(writeScanFile(t, mixedCase)) {
+		t.Error("Expected pattern in binary content to be found regardless of case")
+	}
+
+	clean := make([]byte, 8*1024)
+	if bc.fileContainsAugmentData(writeScanFile(t, clean)) {
+		t.Error("Expected binary content without patterns not to match")
+	}
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	if isBinaryContent([]byte("plain text")) {
+		t.Error("Expected text not to be binary")
+	}
+	if !isBinaryContent([]byte{0, 0, 0, 'a', 'b', 0, 0}) {
+		t.Error("Expected mostly-NUL data to be binary")
+	}
+	if isBinaryContent(nil) {
+		t.Error("Expected empty data not to be binary")
+	}
+}
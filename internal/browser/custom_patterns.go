@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/likepattern"
+)
+
+// customAugmentPattern is a user-defined SQL LIKE pattern of Augment data with
+// the regular expressions matching it outside SQL
+type customAugmentPattern struct {
+	like   string
+	name   *regexp.Regexp // Matches whole file names and storage origins
+	search *regexp.Regexp // Finds the pattern inside file content
+}
+
+// SetCustomAugmentPatterns adds SQL LIKE patterns, e.g. from the
+// custom_augment_patterns config setting, to the built-in ones. They select
+// cookies and other database rows like the built-in patterns, and file names,
+// storage origins and file content that match them are cleaned as well.
+// Patterns made only of wildcards are skipped, as they would match everything.
+func (bc *BrowserCleaner) SetCustomAugmentPatterns(patterns ...string) {
+	for _, pattern := range patterns {
+		if err := likepattern.Validate(pattern); err != nil {
+			bc.logger().Warn("Skipping custom Augment pattern: %v", err)
+			continue
+		}
+		bc.customPatterns = append(bc.customPatterns, customAugmentPattern{
+			like:   pattern,
+			name:   likepattern.Compile(pattern),
+			search: likepattern.CompileSearch(pattern),
+		})
+	}
+}
+
+// sqlPatterns returns the LIKE patterns of Augment rows: augmentSQLPatterns
+// followed by the custom patterns
+func (bc *BrowserCleaner) sqlPatterns() []string {
+	patterns := append([]string(nil), augmentSQLPatterns...)
+	for _, custom := range bc.customPatterns {
+		patterns = append(patterns, custom.like)
+	}
+	return patterns
+}
+
+// countPatternsWhere returns a condition selecting the rows where matches any
+// Augment pattern, with its arguments, so each row is counted once. Every
+// built-in pattern contains "augment", so it stands for all of them.
+func (bc *BrowserCleaner) countPatternsWhere(where string) (string, []interface{}) {
+	patterns := []string{augmentSQLPatterns[0]}
+	for _, custom := range bc.customPatterns {
+		patterns = append(patterns, custom.like)
+	}
+
+	conditions := make([]string, len(patterns))
+	var args []interface{}
+	for i, pattern := range patterns {
+		conditions[i] = "(" + where + ")"
+		args = append(args, likeArgs(where, pattern)...)
+	}
+	return strings.Join(conditions, " OR "), args
+}
+
+// isAugmentName reports whether a file or directory name contains one of the
+// augmentContentPatterns or matches a custom pattern
+func (bc *BrowserCleaner) isAugmentName(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, pattern := range augmentContentPatterns {
+		if strings.Contains(lowerName, string(pattern)) {
+			return true
+		}
+	}
+	for _, custom := range bc.customPatterns {
+		if custom.name.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCustomOrigin reports whether a storage origin matches a custom pattern
+func (bc *BrowserCleaner) matchesCustomOrigin(origin string) bool {
+	for _, custom := range bc.customPatterns {
+		if custom.name.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCustomPattern reports whether data contains a value matching a custom
+// pattern
+func (bc *BrowserCleaner) containsCustomPattern(data []byte) bool {
+	for _, custom := range bc.customPatterns {
+		if custom.search.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// longestCustomPattern returns the length of the longest custom pattern without
+// its wildcards at either end. A % inside a pattern can span any distance, so
+// such matches are only found within one window.
+func (bc *BrowserCleaner) longestCustomPattern() int {
+	longest := 0
+	for _, custom := range bc.customPatterns {
+		longest = max(longest, len(strings.Trim(custom.like, "%")))
+	}
+	return longest
+}
+
+// containsAugmentPattern reports whether data contains one of the
+// augmentContentPatterns or a value matching a custom pattern, ignoring case.
+// Text is lowercased as UTF-8; binary data only has its ASCII letters folded, so
+// invalid UTF-8 is left as is.
+func (bc *BrowserCleaner) containsAugmentPattern(data []byte, binary bool) bool {
+	if binary {
+		data = foldASCII(data)
+	} else {
+		data = bytes.ToLower(data)
+	}
+	for _, pattern := range augmentContentPatterns {
+		if bytes.Contains(data, pattern) {
+			return true
+		}
+	}
+	return bc.containsCustomPattern(data)
+}
+
+// foldASCII returns a copy of data with the ASCII letters A-Z lowercased and
+// every other byte unchanged
+func foldASCII(data []byte) []byte {
+	folded := make([]byte, len(data))
+	for i, b := range data {
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		folded[i] = b
+	}
+	return folded
+}
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestCustomAugmentPatternsCookies(t *testing.T) {
+	cookiesDB := filepath.Join(t.TempDir(), "Cookies")
+	createTestDB(t, cookiesDB,
+		`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT)`,
+		`INSERT INTO cookies VALUES ('.augmentcode.com', 'session', 'a'), ('.acme-ai.dev', 'session', 'b'),
+			('.example.com', 'acme_token', 'c'), ('.example.com', 'NID', 'd')`,
+	)
+
+	bc := &BrowserCleaner{}
+	bc.SetCustomAugmentPatterns("%acme%", "%", "_%")
+	if len(bc.customPatterns) != 1 {
+		t.Fatalf("Expected the wildcard-only patterns to be skipped, got %d patterns", len(bc.customPatterns))
+	}
+
+	cookies, _, _ := bc.countChromiumData(BrowserProfile{ProfilePath: filepath.Dir(cookiesDB)})
+	if cookies != 3 {
+		t.Errorf("Expected 3 cookies counted, got %d", cookies)
+	}
+	deleted, err := bc.cleanChromiumCookies(cookiesDB)
+	if err != nil {
+		t.Fatalf("cleanChromiumCookies failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected the Augment cookie and the 2 matching the custom pattern to be deleted, got %d", deleted)
+	}
+	if got := countRows(t, cookiesDB, "cookies"); got != 1 {
+		t.Errorf("Expected the unrelated cookie to survive, got %d", got)
+	}
+}
+
+func TestCustomAugmentPatternsStorage(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), "leveldb")
+	createLocalStorage(t, storageDir, map[string]string{
+		"META:https://app.acme.dev":          "meta",
+		"_https://app.acme.dev\x00\x01token": "secret",
+		"META:https://github.com":            "meta",
+	})
+
+	bc := &BrowserCleaner{}
+	bc.SetCustomAugmentPatterns("https://%.acme.dev")
+	deleted, err := bc.cleanChromiumLocalStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumLocalStorage failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected the 2 keys of the custom origin deleted, got %d", deleted)
+	}
+
+	db, err := leveldb.OpenFile(storageDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to reopen leveldb: %v", err)
+	}
+	defer db.Close()
+	if ok, _ := db.Has([]byte("META:https://github.com"), nil); !ok {
+		t.Error("Expected the other origin to be kept")
+	}
+}
+
+func TestCustomAugmentPatternsFiles(t *testing.T) {
+	cacheDir := t.TempDir()
+	files := map[string]string{
+		"acme-cache.bin": "x",
+		"f_000001":       `{"url":"https://ACME.dev/api"}`,
+		"f_000002":       "unrelated",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(cacheDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bc := &BrowserCleaner{}
+	bc.SetCustomAugmentPatterns("%acme%")
+	deleted, err := bc.cleanChromiumCache(cacheDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumCache failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected the file named and the file containing the custom pattern deleted, got %d", deleted)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "f_000002")); err != nil {
+		t.Errorf("Expected the unrelated file to be kept: %v", err)
+	}
+}
+
+func TestMatchAugmentPatternsCustom(t *testing.T) {
+	rules := MatchAugmentPatterns("https://app.acme.dev", "%acme%", "%other%")
+	if len(rules) != 1 || rules[0] != "custom: %acme%" {
+		t.Errorf("Expected only the custom rule to match, got %v", rules)
+	}
+	if rules := MatchAugmentPatterns("https://app.acme.dev"); len(rules) != 0 {
+		t.Errorf("Expected no built-in rule to match, got %v", rules)
+	}
+	if rules := MatchAugmentPatterns("augment", "%"); strings.Contains(strings.Join(rules, ","), "custom") {
+		t.Errorf("Expected a wildcard-only pattern to be ignored, got %v", rules)
+	}
+}
@@ -0,0 +1,113 @@
+package browser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanFirefoxCookiesIsolated(t *testing.T) {
+	cookiesDB := filepath.Join(t.TempDir(), "cookies.sqlite")
+	createTestDB(t, cookiesDB,
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '',
+			name TEXT, value TEXT, host TEXT, path TEXT)`,
+		`INSERT INTO moz_cookies (originAttributes, name, value, host, path) VALUES
+			('', 'session', 'abc', '.augmentcode.com', '/'),
+			('^partitionKey=%28https%2Cexample.com%29', 'session', 'abc', '.augmentcode.com', '/'),
+			('^partitionKey=%28https%2Caugmentcode.com%29', '_ga', 'GA1.2.3', '.google-analytics.com', '/'),
+			('^firstPartyDomain=augmentcode.com', 'NID', 'xyz', '.google.com', '/'),
+			('^userContextId=1&partitionKey=%28https%2Capp.augmentcode.com%2C8443%29', 'sid', 'x', '.example.com', '/'),
+			('', 'NID', 'xyz', '.google.com', '/'),
+			('^partitionKey=%28https%2Cexample.com%29', '_ga', 'GA1.2.3', '.google-analytics.com', '/'),
+			('^partitionKey=%28https%2Caugmentedreality.com%29', '_ga', 'GA1.2.3', '.google-analytics.com', '/'),
+			('^firstPartyDomain=augmentedreality.com', 'NID', 'xyz', '.google.com', '/')`,
+	)
+
+	bc := &BrowserCleaner{}
+	deleted, err := bc.cleanFirefoxCookies(cookiesDB)
+	if err != nil {
+		t.Fatalf("cleanFirefoxCookies failed: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("Expected the Augment cookie in both partitions and the 3 cookies isolated under augmentcode.com to be deleted, got %d", deleted)
+	}
+	if got := countRows(t, cookiesDB, "moz_cookies"); got != 4 {
+		t.Errorf("Expected the 4 unrelated cookies, including those isolated under augmentedreality.com, to survive, got %d", got)
+	}
+}
+
+func TestCleanFirefoxCookiesLegacySchemas(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		insert  string
+		deleted int64
+	}{
+		{
+			name:    "firstPartyDomain column",
+			schema:  `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, firstPartyDomain TEXT, name TEXT, value TEXT, host TEXT)`,
+			insert:  `INSERT INTO moz_cookies (firstPartyDomain, name, value, host) VALUES ('', 'session', 'abc', '.augmentcode.com'), ('augmentcode.com', '_ga', 'x', '.google-analytics.com'), ('augmentedreality.com', '_ga', 'x', '.google-analytics.com'), ('', 'NID', 'y', '.google.com')`,
+			deleted: 2,
+		},
+		{
+			name:    "no isolation columns",
+			schema:  `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT)`,
+			insert:  `INSERT INTO moz_cookies (name, value, host) VALUES ('session', 'abc', '.augmentcode.com'), ('NID', 'y', '.google.com')`,
+			deleted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookiesDB := filepath.Join(t.TempDir(), "cookies.sqlite")
+			createTestDB(t, cookiesDB, tt.schema, tt.insert)
+
+			bc := &BrowserCleaner{}
+			deleted, err := bc.cleanFirefoxCookies(cookiesDB)
+			if err != nil {
+				t.Fatalf("cleanFirefoxCookies failed: %v", err)
+			}
+			if deleted != tt.deleted {
+				t.Errorf("Expected %d cookies deleted, got %d", tt.deleted, deleted)
+			}
+		})
+	}
+}
+
+func TestCountFirefoxDataIncludesIsolatedCookies(t *testing.T) {
+	profileDir := t.TempDir()
+	createTestDB(t, filepath.Join(profileDir, "cookies.sqlite"),
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, value TEXT, host TEXT)`,
+		`INSERT INTO moz_cookies (originAttributes, name, value, host) VALUES
+			('', 'session', 'abc', '.augmentcode.com'),
+			('^partitionKey=%28https%2Caugmentcode.com%29', '_ga', 'x', '.google-analytics.com'),
+			('^partitionKey=%28https%2Caugmentedreality.com%29', '_ga', 'x', '.google-analytics.com'),
+			('', 'NID', 'y', '.google.com')`,
+	)
+
+	bc := &BrowserCleaner{}
+	cookies, _, _ := bc.countFirefoxData(BrowserProfile{ProfilePath: profileDir})
+	if cookies != 2 {
+		t.Errorf("Expected 2 Augment cookies counted, got %d", cookies)
+	}
+}
+
+func TestOriginAttributeSites(t *testing.T) {
+	tests := []struct {
+		attributes string
+		want       []string
+	}{
+		{"", nil},
+		{"^userContextId=1", nil},
+		{"^partitionKey=%28https%2Caugmentcode.com%29", []string{"augmentcode.com"}},
+		{"^partitionKey=%28https%2Caugmentcode.com%2C8443%29", []string{"augmentcode.com"}},
+		{"^firstPartyDomain=augmentcode.com&userContextId=2", []string{"augmentcode.com"}},
+	}
+
+	for _, tt := range tests {
+		got := originAttributeSites(tt.attributes)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("originAttributeSites(%q) = %v, expected %v", tt.attributes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/retry"
+)
+
+// firefoxUUIDsPref is the prefs.js preference mapping add-on IDs to internal UUIDs
+const firefoxUUIDsPref = "extensions.webextensions.uuids"
+
+// firefoxExtensionsFile mirrors the parts of <profile>/extensions.json we need
+type firefoxExtensionsFile struct {
+	Addons []firefoxAddon `json:"addons"`
+}
+
+// firefoxAddon is a single add-on entry in extensions.json
+type firefoxAddon struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	DefaultLocale struct {
+		Name string `json:"name"`
+	} `json:"defaultLocale"`
+}
+
+// FirefoxExtensionUUIDMapper maps Firefox add-on IDs to the internal UUIDs used
+// for moz-extension:// origins and storage directory names
+type FirefoxExtensionUUIDMapper struct{}
+
+// NewFirefoxExtensionUUIDMapper creates a new Firefox extension UUID mapper
+func NewFirefoxExtensionUUIDMapper() *FirefoxExtensionUUIDMapper {
+	return &FirefoxExtensionUUIDMapper{}
+}
+
+// GetUUID returns the internal UUID Firefox uses for the given extension in a profile.
+// The add-on must be listed in <profile>/extensions.json; the UUID itself is resolved
+// from the extensions.webextensions.uuids preference in <profile>/prefs.js.
+func (m *FirefoxExtensionUUIDMapper) GetUUID(extensionID string, profile BrowserProfile) (string, error) {
+	addons, err := m.readAddons(profile)
+	if err != nil {
+		return "", err
+	}
+
+	found := false
+	for _, addon := range addons {
+		if addon.ID == extensionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("extension %s not found in %s profile", extensionID, profile.Name)
+	}
+
+	uuids, err := m.readUUIDMap(profile)
+	if err != nil {
+		return "", err
+	}
+
+	uuid, ok := uuids[extensionID]
+	if !ok || uuid == "" {
+		return "", fmt.Errorf("no storage UUID recorded for extension %s", extensionID)
+	}
+
+	return uuid, nil
+}
+
+// FindAugmentExtensionIDs returns the IDs of installed add-ons that look Augment-related
+func (m *FirefoxExtensionUUIDMapper) FindAugmentExtensionIDs(profile BrowserProfile) ([]string, error) {
+	addons, err := m.readAddons(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, addon := range addons {
+		id := strings.ToLower(addon.ID)
+		name := strings.ToLower(addon.DefaultLocale.Name)
+		if strings.Contains(id, "augment") || strings.Contains(name, "augment") {
+			ids = append(ids, addon.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// readAddons parses the add-on list from extensions.json
+func (m *FirefoxExtensionUUIDMapper) readAddons(profile BrowserProfile) ([]firefoxAddon, error) {
+	data, err := os.ReadFile(filepath.Join(profile.ProfilePath, "extensions.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extensions.json: %w", err)
+	}
+
+	var extensions firefoxExtensionsFile
+	if err := json.Unmarshal(data, &extensions); err != nil {
+		return nil, fmt.Errorf("failed to parse extensions.json: %w", err)
+	}
+
+	return extensions.Addons, nil
+}
+
+// readUUIDMap parses the add-on ID to UUID mapping from prefs.js
+func (m *FirefoxExtensionUUIDMapper) readUUIDMap(profile BrowserProfile) (map[string]string, error) {
+	file, err := os.Open(filepath.Join(profile.ProfilePath, "prefs.js"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prefs.js: %w", err)
+	}
+	defer file.Close()
+
+	prefix := fmt.Sprintf("user_pref(%q,", firefoxUUIDsPref)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		value := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		value = strings.TrimSuffix(value, ";")
+		value = strings.TrimSpace(strings.TrimSuffix(value, ")"))
+
+		raw, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s preference: %w", firefoxUUIDsPref, err)
+		}
+
+		uuids := make(map[string]string)
+		if err := json.Unmarshal([]byte(raw), &uuids); err != nil {
+			return nil, fmt.Errorf("failed to parse %s preference: %w", firefoxUUIDsPref, err)
+		}
+		return uuids, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prefs.js: %w", err)
+	}
+
+	return nil, fmt.Errorf("%s preference not found in prefs.js", firefoxUUIDsPref)
+}
+
+// cleanFirefoxExtensionStorage removes IndexedDB and localStorage data of Augment
+// extensions from their moz-extension+++<uuid> storage directories. A failure
+// for one extension is recorded and the remaining extensions are still cleaned.
+func (bc *BrowserCleaner) cleanFirefoxExtensionStorage(profile BrowserProfile, storageDir string) (indexedDBDeleted, storageDeleted int64, errs []error) {
+	mapper := NewFirefoxExtensionUUIDMapper()
+
+	extensionIDs, err := mapper.FindAugmentExtensionIDs(profile)
+	if err != nil {
+		// No extensions.json means no installed extensions to clean
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, []error{err}
+	}
+
+	for _, extensionID := range extensionIDs {
+		uuid, err := mapper.GetUUID(extensionID, profile)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Directory names may carry origin attribute suffixes (e.g. ^userContextId=1)
+		originDirs, _ := filepath.Glob(filepath.Join(storageDir, "moz-extension+++"+uuid+"*"))
+		for _, originDir := range originDirs {
+			idbDir := filepath.Join(originDir, "idb")
+			if count := countFilesWithExtension(idbDir, ".sqlite"); count > 0 {
+				if err := bc.removeTree(idbDir); err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove %s: %w", idbDir, err))
+				} else {
+					bc.logger().Debug("Deleted %s (%d databases)", idbDir, count)
+					indexedDBDeleted += count
+				}
+			}
+
+			lsDir := filepath.Join(originDir, "ls")
+			if _, err := os.Stat(lsDir); err == nil {
+				if err := bc.removeTree(lsDir); err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove %s: %w", lsDir, err))
+				} else {
+					bc.logger().Debug("Deleted %s", lsDir)
+					storageDeleted++
+				}
+			}
+		}
+	}
+
+	return indexedDBDeleted, storageDeleted, errs
+}
+
+// countFilesWithExtension counts files with the given extension below dir
+func countFilesWithExtension(dir, ext string) int64 {
+	var count int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ext) {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// removeAllWithRetry removes a directory tree, retrying with the default retry
+// policy if files are still locked
+func removeAllWithRetry(path string) error {
+	return retry.Do(context.Background(), func() error {
+		return os.RemoveAll(path)
+	})
+}
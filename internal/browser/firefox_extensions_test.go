@@ -0,0 +1,109 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	augmentAddonUUID = "0b5c8a6e-3a43-4f7e-9d2b-1c5e7f1a2b3c"
+	otherAddonUUID   = "7d1e2f3a-4b5c-4d6e-8f90-a1b2c3d4e5f6"
+)
+
+// createFirefoxExtensionProfile creates a profile with an Augment add-on, an
+// Augment add-on without a recorded UUID and an unrelated add-on, and returns
+// the profile and its storage/default directory
+func createFirefoxExtensionProfile(t *testing.T) (BrowserProfile, string) {
+	t.Helper()
+	profileDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(profileDir, "extensions.json"), `{"addons": [
+		{"id": "broken@augmentcode.com", "type": "extension", "defaultLocale": {"name": "Augment Beta"}},
+		{"id": "augment@augmentcode.com", "type": "extension", "defaultLocale": {"name": "Augment"}},
+		{"id": "other@example.com", "type": "extension", "defaultLocale": {"name": "Other"}}
+	]}`)
+	writeTestFile(t, filepath.Join(profileDir, "prefs.js"),
+		`user_pref("browser.startup.page", 3);`+"\n"+
+			`user_pref("extensions.webextensions.uuids", "{\"augment@augmentcode.com\":\"`+augmentAddonUUID+
+			`\",\"other@example.com\":\"`+otherAddonUUID+`\"}");`+"\n")
+
+	storageDir := filepath.Join(profileDir, "storage", "default")
+	for _, origin := range []string{
+		"moz-extension+++" + augmentAddonUUID,
+		"moz-extension+++" + augmentAddonUUID + "^userContextId=1",
+		"moz-extension+++" + otherAddonUUID,
+	} {
+		writeTestFile(t, filepath.Join(storageDir, origin, "idb", "3647222921wleabcEoxlt-eengsairo.sqlite"), "idb")
+		writeTestFile(t, filepath.Join(storageDir, origin, "ls", "data.sqlite"), "ls")
+	}
+
+	return BrowserProfile{Name: "default", Type: Firefox, ProfilePath: profileDir}, storageDir
+}
+
+func TestFirefoxExtensionUUIDMapper(t *testing.T) {
+	profile, _ := createFirefoxExtensionProfile(t)
+	mapper := NewFirefoxExtensionUUIDMapper()
+
+	ids, err := mapper.FindAugmentExtensionIDs(profile)
+	if err != nil {
+		t.Fatalf("FindAugmentExtensionIDs failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "broken@augmentcode.com" || ids[1] != "augment@augmentcode.com" {
+		t.Errorf("Expected both Augment add-ons, got %v", ids)
+	}
+
+	uuid, err := mapper.GetUUID("augment@augmentcode.com", profile)
+	if err != nil || uuid != augmentAddonUUID {
+		t.Errorf("Expected UUID %s, got %q (%v)", augmentAddonUUID, uuid, err)
+	}
+
+	if _, err := mapper.GetUUID("broken@augmentcode.com", profile); err == nil {
+		t.Error("Expected an error for an add-on without a recorded UUID")
+	}
+	if _, err := mapper.GetUUID("missing@augmentcode.com", profile); err == nil {
+		t.Error("Expected an error for an add-on that is not installed")
+	}
+}
+
+func TestCleanFirefoxExtensionStorage(t *testing.T) {
+	profile, storageDir := createFirefoxExtensionProfile(t)
+
+	bc := &BrowserCleaner{}
+	idbDeleted, lsDeleted, errs := bc.cleanFirefoxExtensionStorage(profile, storageDir)
+
+	// The add-on without a UUID is reported but does not stop the others
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken@augmentcode.com") {
+		t.Errorf("Expected one error for the add-on without a UUID, got %v", errs)
+	}
+	if idbDeleted != 2 || lsDeleted != 2 {
+		t.Errorf("Expected 2 IndexedDB databases and 2 localStorage directories deleted, got %d and %d", idbDeleted, lsDeleted)
+	}
+
+	for _, origin := range []string{
+		"moz-extension+++" + augmentAddonUUID,
+		"moz-extension+++" + augmentAddonUUID + "^userContextId=1",
+	} {
+		for _, dir := range []string{"idb", "ls"} {
+			if _, err := os.Stat(filepath.Join(storageDir, origin, dir)); !os.IsNotExist(err) {
+				t.Errorf("Expected %s/%s to be removed", origin, dir)
+			}
+		}
+	}
+
+	for _, dir := range []string{"idb", "ls"} {
+		if _, err := os.Stat(filepath.Join(storageDir, "moz-extension+++"+otherAddonUUID, dir)); err != nil {
+			t.Errorf("Expected the unrelated add-on's %s to survive: %v", dir, err)
+		}
+	}
+}
+
+func TestCleanFirefoxExtensionStorageWithoutExtensions(t *testing.T) {
+	bc := &BrowserCleaner{}
+	profile := BrowserProfile{Name: "default", Type: Firefox, ProfilePath: t.TempDir()}
+	idbDeleted, lsDeleted, errs := bc.cleanFirefoxExtensionStorage(profile, filepath.Join(profile.ProfilePath, "storage", "default"))
+	if idbDeleted != 0 || lsDeleted != 0 || len(errs) != 0 {
+		t.Errorf("Expected nothing to clean, got %d, %d (%v)", idbDeleted, lsDeleted, errs)
+	}
+}
@@ -0,0 +1,43 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// firefoxSiteDataDeletes lists the deletes run per Firefox database file.
+// permissions.sqlite keys permissions by origin (https://app.augmentcode.com);
+// content-prefs.sqlite stores site-specific prefs such as the zoom level in prefs,
+// referencing the site in groups, so prefs are deleted before their groups.
+var firefoxSiteDataDeletes = map[string][]augmentRowDelete{
+	"permissions.sqlite": {
+		{"moz_perms", `origin LIKE ?`},
+	},
+	"content-prefs.sqlite": {
+		{"prefs", `groupID IN (SELECT id FROM groups WHERE name LIKE ?)`},
+		{"groups", `name LIKE ?`},
+	},
+}
+
+// firefoxSiteDataFiles are the database files of firefoxSiteDataDeletes in cleaning order
+var firefoxSiteDataFiles = []string{"permissions.sqlite", "content-prefs.sqlite"}
+
+// cleanFirefoxSiteData removes the permissions and site-specific prefs of Augment
+// origins, which survive cookie and storage cleaning, and returns the rows deleted
+func (bc *BrowserCleaner) cleanFirefoxSiteData(profilePath string) (int64, error) {
+	var total int64
+	for _, name := range firefoxSiteDataFiles {
+		dbPath := filepath.Join(profilePath, name)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+
+		deleted, err := bc.deleteAugmentRows(dbPath, firefoxSiteDataDeletes[name])
+		total += deleted
+		if err != nil {
+			return total, fmt.Errorf("failed to clean %s: %w", name, err)
+		}
+	}
+	return total, nil
+}
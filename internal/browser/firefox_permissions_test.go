@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanFirefoxSiteData(t *testing.T) {
+	profileDir := t.TempDir()
+	permissionsDB := filepath.Join(profileDir, "permissions.sqlite")
+	contentPrefsDB := filepath.Join(profileDir, "content-prefs.sqlite")
+
+	createTestDB(t, permissionsDB,
+		`CREATE TABLE moz_perms (id INTEGER PRIMARY KEY, origin TEXT, type TEXT, permission INTEGER)`,
+		`INSERT INTO moz_perms (origin, type, permission) VALUES
+			('https://app.augmentcode.com', 'desktop-notification', 1),
+			('https://augmentcode.com', 'cookie', 1),
+			('https://github.com', 'desktop-notification', 1),
+			('https://example.com', 'camera', 2)`,
+	)
+	createTestDB(t, contentPrefsDB,
+		`CREATE TABLE groups (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`,
+		`CREATE TABLE settings (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`,
+		`CREATE TABLE prefs (id INTEGER PRIMARY KEY, groupID INTEGER, settingID INTEGER, value BLOB, timestamp INTEGER)`,
+		`INSERT INTO groups VALUES (1, 'augmentcode.com'), (2, 'github.com')`,
+		`INSERT INTO settings VALUES (1, 'browser.content.full-zoom')`,
+		`INSERT INTO prefs (groupID, settingID, value) VALUES (1, 1, 1.2), (2, 1, 0.9)`,
+	)
+
+	bc := &BrowserCleaner{}
+	deleted, err := bc.cleanFirefoxSiteData(profileDir)
+	if err != nil {
+		t.Fatalf("cleanFirefoxSiteData failed: %v", err)
+	}
+	// 2 permissions + 1 pref + 1 group
+	if deleted != 4 {
+		t.Errorf("Expected 4 rows deleted, got %d", deleted)
+	}
+
+	if got := countRows(t, permissionsDB, "moz_perms"); got != 2 {
+		t.Errorf("Expected the 2 non-Augment permissions to survive, got %d", got)
+	}
+	if got := countRows(t, contentPrefsDB, "groups"); got != 1 {
+		t.Errorf("Expected 1 remaining group, got %d", got)
+	}
+	if got := countRows(t, contentPrefsDB, "prefs"); got != 1 {
+		t.Errorf("Expected 1 remaining pref, got %d", got)
+	}
+	if got := countRows(t, contentPrefsDB, "settings"); got != 1 {
+		t.Errorf("Expected settings to be untouched, got %d", got)
+	}
+}
+
+func TestCleanFirefoxSiteDataWithoutDatabases(t *testing.T) {
+	bc := &BrowserCleaner{}
+	deleted, err := bc.cleanFirefoxSiteData(t.TempDir())
+	if err != nil || deleted != 0 {
+		t.Errorf("Expected nothing to clean, got %d (%v)", deleted, err)
+	}
+}
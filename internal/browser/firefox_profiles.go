@@ -0,0 +1,91 @@
+package browser
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+)
+
+// firefoxProfileEntry is a [ProfileN] section of profiles.ini
+type firefoxProfileEntry struct {
+	Name      string
+	Path      string // As written in profiles.ini, used as the key of [Install] defaults
+	Dir       string // Resolved profile directory
+	IsDefault bool
+}
+
+// parseFirefoxProfilesINI parses the profiles.ini of the Firefox data directory
+// firefoxPath. Since Firefox 67 each installation has its own default profile,
+// recorded as Default= in an [Install<hash>] section; the legacy Default=1 of a
+// [Profile] section is only honored when there are no install sections, because
+// it keeps pointing at the profile of whichever install wrote it last.
+func parseFirefoxProfilesINI(content, firefoxPath string) []firefoxProfileEntry {
+	var profiles []map[string]string
+	installDefaults := make(map[string]bool)
+
+	var section map[string]string
+	var sectionName string
+	flush := func() {
+		switch {
+		case section == nil:
+		case strings.HasPrefix(sectionName, "Profile"):
+			profiles = append(profiles, section)
+		case strings.HasPrefix(sectionName, "Install"):
+			if path := section["Default"]; path != "" {
+				installDefaults[path] = true
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			sectionName = line[1 : len(line)-1]
+			section = make(map[string]string)
+			continue
+		}
+
+		if key, value, found := strings.Cut(line, "="); found && section != nil {
+			section[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	flush()
+
+	var entries []firefoxProfileEntry
+	for _, profile := range profiles {
+		path := profile["Path"]
+		if path == "" {
+			continue
+		}
+
+		isDefault := profile["Default"] == "1"
+		if len(installDefaults) > 0 {
+			isDefault = installDefaults[path]
+		}
+
+		entries = append(entries, firefoxProfileEntry{
+			Name:      profile["Name"],
+			Path:      path,
+			Dir:       resolveFirefoxProfileDir(firefoxPath, path, profile["IsRelative"]),
+			IsDefault: isDefault,
+		})
+	}
+
+	return entries
+}
+
+// resolveFirefoxProfileDir returns the directory of a profile path. Relative paths
+// (IsRelative=1, the default) use forward slashes on every OS and are relative to
+// the Firefox data directory; IsRelative=0 paths are absolute.
+func resolveFirefoxProfileDir(firefoxPath, path, isRelative string) string {
+	if isRelative == "0" || filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(firefoxPath, filepath.FromSlash(path))
+}
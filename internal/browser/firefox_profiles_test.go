@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseFirefoxProfilesINIUsesInstallDefaults(t *testing.T) {
+	firefoxPath := filepath.Join("home", ".mozilla", "firefox")
+	absolute := filepath.Join(string(filepath.Separator), "data", "work-profile")
+	content := `[Install4F96D1932A9F858E]
+Default=Profiles/abcd.default-release
+Locked=1
+
+[Profile1]
+Name=default
+IsRelative=1
+Path=Profiles/stale.default
+Default=1
+
+[Profile0]
+Name=default-release
+IsRelative=1
+Path=Profiles/abcd.default-release
+
+[Profile2]
+Name=work
+IsRelative=0
+Path=` + absolute + `
+
+[General]
+StartWithLastProfile=1
+Version=2
+`
+
+	entries := parseFirefoxProfilesINI(content, firefoxPath)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 profiles, got %+v", entries)
+	}
+
+	byName := make(map[string]firefoxProfileEntry)
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+
+	if byName["default"].IsDefault {
+		t.Error("Expected the legacy Default=1 profile to be ignored when install sections exist")
+	}
+	release := byName["default-release"]
+	if !release.IsDefault {
+		t.Error("Expected the install default profile to be the default")
+	}
+	if want := filepath.Join(firefoxPath, "Profiles", "abcd.default-release"); release.Dir != want {
+		t.Errorf("Expected relative profile in %s, got %s", want, release.Dir)
+	}
+	if byName["work"].Dir != absolute {
+		t.Errorf("Expected absolute profile path %s, got %s", absolute, byName["work"].Dir)
+	}
+}
+
+func TestParseFirefoxProfilesINILegacyDefault(t *testing.T) {
+	content := "[Profile0]\nName=old\nPath=old.default\n\n[Profile1]\nName=main\nPath=main.default\nDefault=1\n"
+
+	entries := parseFirefoxProfilesINI(content, "firefox")
+	if len(entries) != 2 || entries[0].IsDefault || !entries[1].IsDefault {
+		t.Errorf("Expected Default=1 to mark the default without install sections, got %+v", entries)
+	}
+}
+
+func TestDetectFirefoxProfilesSkipsMissingDirectories(t *testing.T) {
+	home := t.TempDir()
+	detector := &BrowserDetector{homeDir: home}
+
+	firefoxPath := firefoxDataDir(home, runtime.GOOS)
+
+	if err := os.MkdirAll(filepath.Join(firefoxPath, "Profiles", "live.default-release"), 0755); err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	ini := "[Install1]\nDefault=Profiles/live.default-release\n\n" +
+		"[Profile0]\nName=live\nPath=Profiles/live.default-release\n\n" +
+		"[Profile1]\nName=deleted\nPath=Profiles/gone.default\nDefault=1\n"
+	if err := os.WriteFile(filepath.Join(firefoxPath, "profiles.ini"), []byte(ini), 0644); err != nil {
+		t.Fatalf("Failed to write profiles.ini: %v", err)
+	}
+
+	profiles, err := detector.detectFirefoxProfiles()
+	if err != nil {
+		t.Fatalf("detectFirefoxProfiles() failed: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "Firefox - live" || !profiles[0].IsDefault {
+		t.Errorf("Expected only the live default profile, got %+v", profiles)
+	}
+}
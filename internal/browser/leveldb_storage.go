@@ -0,0 +1,168 @@
+package browser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldberrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Prefixes of the keys Chromium keeps in the Local Storage LevelDB: data keys are
+// "_<origin>\x00<key>", metadata keys "META:<origin>" and "METAACCESS:<origin>"
+var (
+	localStorageDataPrefix       = []byte("_")
+	localStorageMetaPrefix       = []byte("META:")
+	localStorageMetaAccessPrefix = []byte("METAACCESS:")
+)
+
+// localStorageOrigin returns the origin a Chromium Local Storage key belongs to;
+// keys of no origin, such as VERSION, return false
+func localStorageOrigin(key []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(key, localStorageDataPrefix):
+		end := bytes.IndexByte(key, 0)
+		if end < 0 {
+			return "", false
+		}
+		return string(key[len(localStorageDataPrefix):end]), true
+	case bytes.HasPrefix(key, localStorageMetaAccessPrefix):
+		return string(key[len(localStorageMetaAccessPrefix):]), true
+	case bytes.HasPrefix(key, localStorageMetaPrefix):
+		return string(key[len(localStorageMetaPrefix):]), true
+	}
+	return "", false
+}
+
+// isAugmentLocalStorageKey reports whether a Local Storage key belongs to an
+// Augment origin
+func (bc *BrowserCleaner) isAugmentLocalStorageKey(key []byte) bool {
+	origin, ok := localStorageOrigin(key)
+	return ok && bc.isAugmentStorageOrigin(origin)
+}
+
+// isAugmentStorageOrigin reports whether the origin of a Local or Session Storage
+// key is an Augment origin or matches a custom pattern. Partitioned storage keys
+// append "^<n><top-level site>" to the origin, which is ignored.
+func (bc *BrowserCleaner) isAugmentStorageOrigin(origin string) bool {
+	if i := strings.IndexByte(origin, '^'); i >= 0 {
+		origin = origin[:i]
+	}
+	return isAugmentOrigin(origin) || bc.matchesCustomOrigin(origin)
+}
+
+// cleanLevelDBKeys deletes the keys of the LevelDB database in dir that match and
+// compacts it, so the deleted values do not linger in its table and log files. The
+// database's own files are left to LevelDB. It returns the number of keys deleted;
+// an error means the database could not be opened or written.
+func (bc *BrowserCleaner) cleanLevelDBKeys(dir string, match func(key []byte) bool) (int64, error) {
+	sizeBefore := treeSize(dir)
+
+	db, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to open leveldb: %w", err)
+	}
+
+	batch := new(leveldb.Batch)
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		if match(iter.Key()) {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		db.Close()
+		return 0, fmt.Errorf("failed to read leveldb: %w", err)
+	}
+	if batch.Len() == 0 {
+		return 0, db.Close()
+	}
+
+	if err := db.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		db.Close()
+		return 0, fmt.Errorf("failed to delete keys: %w", err)
+	}
+	if err := db.CompactRange(util.Range{}); err != nil {
+		db.Close()
+		return int64(batch.Len()), fmt.Errorf("failed to compact leveldb: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return int64(batch.Len()), fmt.Errorf("failed to close leveldb: %w", err)
+	}
+	bc.logger().Debug("Deleted %d keys from %s", batch.Len(), dir)
+
+	if freed := sizeBefore - treeSize(dir); freed > 0 && bc.removal != nil {
+		bc.removal.bytesFreed += freed
+	}
+	return int64(batch.Len()), nil
+}
+
+// countLevelDBKeys counts the keys of the LevelDB database in dir that match and
+// the size of their keys and values, opening the database read-only
+func countLevelDBKeys(dir string, match func(key []byte) bool) (keys, size int64, err error) {
+	db, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfMissing: true, ReadOnly: true})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open leveldb: %w", err)
+	}
+	defer db.Close()
+
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if match(iter.Key()) {
+			keys++
+			size += int64(len(iter.Key()) + len(iter.Value()))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read leveldb: %w", err)
+	}
+	return keys, size, nil
+}
+
+// isDamagedLevelDB reports whether opening a LevelDB database failed because it
+// is corrupted or files are missing, rather than because it is locked or cannot
+// be read
+func isDamagedLevelDB(err error) bool {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if leveldberrors.IsCorrupted(e) {
+			return true
+		}
+	}
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// isLevelDBDataFile reports whether a file in a LevelDB directory is a table or
+// log file. CURRENT, MANIFEST-*, LOCK and LOG describe the whole database, so
+// deleting them corrupts the storage of every site.
+func isLevelDBDataFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".ldb" || ext == ".log"
+}
+
+// augmentLevelDBDataFiles returns the table and log files in dir whose name or
+// content mentions Augment, for when the database cannot be opened
+func (bc *BrowserCleaner) augmentLevelDBDataFiles(dir string) []string {
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			bc.skipPath(path, err)
+			return nil // Skip files we can't access
+		}
+		if info.IsDir() || !isLevelDBDataFile(info.Name()) {
+			return nil
+		}
+		if strings.Contains(strings.ToLower(info.Name()), "augment") || bc.levelDBFileContainsAugmentData(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
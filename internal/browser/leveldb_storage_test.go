@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// createLocalStorage creates a Local Storage LevelDB with the keys and values
+func createLocalStorage(t *testing.T, dir string, entries map[string]string) {
+	t.Helper()
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create leveldb: %v", err)
+	}
+	defer db.Close()
+	for key, value := range entries {
+		if err := db.Put([]byte(key), []byte(value), nil); err != nil {
+			t.Fatalf("Failed to put %q: %v", key, err)
+		}
+	}
+}
+
+func TestCleanChromiumLocalStorageDeletesAugmentOrigins(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), "leveldb")
+	createLocalStorage(t, storageDir, map[string]string{
+		"VERSION":                                   "1",
+		"META:https://app.augmentcode.com":          "meta",
+		"METAACCESS:https://app.augmentcode.com":    "access",
+		"_https://app.augmentcode.com\x00\x01token": "secret",
+		"_https://github.com\x00\x01augment_theme":  "dark", // Mentions Augment but belongs to another site
+		"META:https://github.com":                   "meta",
+	})
+
+	bc := &BrowserCleaner{}
+	if keys, _, err := countLevelDBKeys(storageDir, bc.isAugmentLocalStorageKey); err != nil || keys != 3 {
+		t.Errorf("Expected 3 Augment keys counted, got %d (%v)", keys, err)
+	}
+
+	deleted, err := bc.cleanChromiumLocalStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumLocalStorage failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 keys deleted, got %d", deleted)
+	}
+
+	db, err := leveldb.OpenFile(storageDir, nil)
+	if err != nil {
+		t.Fatalf("Expected the cleaned database to open: %v", err)
+	}
+	defer db.Close()
+	for _, key := range []string{"VERSION", "_https://github.com\x00\x01augment_theme", "META:https://github.com"} {
+		if ok, _ := db.Has([]byte(key), nil); !ok {
+			t.Errorf("Expected %q to be kept", key)
+		}
+	}
+	if ok, _ := db.Has([]byte("_https://app.augmentcode.com\x00\x01token"), nil); ok {
+		t.Error("Expected the Augment key to be deleted")
+	}
+}
+
+func TestCleanChromiumLocalStorageFallbackKeepsStructuralFiles(t *testing.T) {
+	storageDir := t.TempDir()
+	// CURRENT names a manifest that does not exist, so the database cannot be opened
+	files := map[string]string{
+		"CURRENT":         "MANIFEST-000009\n",
+		"MANIFEST-000001": "augmentcode.com",
+		"LOG":             "augmentcode.com",
+		"000005.ldb":      "_https://app.augmentcode.com",
+		"000006.log":      "_https://github.com",
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(storageDir, name), content)
+	}
+
+	bc := &BrowserCleaner{levelDBScan: DefaultLevelDBScanConfig}
+	deleted, err := bc.cleanChromiumLocalStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumLocalStorage failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected only the Augment table file to be deleted, got %d", deleted)
+	}
+	if _, err := os.Stat(filepath.Join(storageDir, "000005.ldb")); err == nil {
+		t.Error("Expected 000005.ldb to be deleted")
+	}
+	for _, name := range []string{"CURRENT", "MANIFEST-000001", "LOG", "000006.log"} {
+		if _, err := os.Stat(filepath.Join(storageDir, name)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/retry"
+)
+
+// LockedFile is a file that could not be deleted because another process holds it open
+type LockedFile struct {
+	Path     string           `json:"path"`
+	LockedBy []BrowserProcess `json:"locked_by,omitempty"`
+	Error    string           `json:"error"`
+}
+
+// removalTracker collects files a profile cleaning run could not delete
+type removalTracker struct {
+	lockedFiles   []LockedFile
+	pendingReboot []string
+	failed        []string
+	denied        []string
+	bytesFreed    int64 // Size of the files and directories removed
+}
+
+// SetScheduleDeleteOnReboot makes files locked by other processes be registered for
+// deletion at the next reboot (Windows only) instead of being left in place
+func (bc *BrowserCleaner) SetScheduleDeleteOnReboot(enabled bool) {
+	bc.scheduleDeleteOnReboot = enabled
+}
+
+// removeFile deletes a file, retrying briefly while it is locked. Files that stay
+// locked or fail to delete are recorded so they are reported instead of silently skipped.
+func (bc *BrowserCleaner) removeFile(path string) bool {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	err := removeWithRetry(path)
+	if err == nil {
+		bc.logger().Debug("Deleted %s", path)
+		if bc.removal != nil {
+			bc.removal.bytesFreed += size
+		}
+		return true
+	}
+	bc.logger().Debug("Skipped %s: %v", path, err)
+
+	tracker := bc.removal
+	if tracker == nil || os.IsNotExist(err) {
+		return false
+	}
+
+	if !isSharingViolation(err) {
+		tracker.failed = append(tracker.failed, fmt.Sprintf("Failed to delete %s: %v", path, err))
+		if cleaner.IsPermissionError(err) {
+			tracker.denied = append(tracker.denied, path)
+		}
+		return false
+	}
+
+	if bc.scheduleDeleteOnReboot {
+		scheduleErr := scheduleDeleteOnReboot(path)
+		if scheduleErr == nil {
+			tracker.pendingReboot = append(tracker.pendingReboot, path)
+			return false
+		}
+		err = fmt.Errorf("%v (scheduling deletion at reboot failed: %v)", err, scheduleErr)
+	}
+
+	lockedBy, _ := findLockingProcesses(path) // Best effort; the file is reported either way
+	tracker.lockedFiles = append(tracker.lockedFiles, LockedFile{
+		Path:     path,
+		LockedBy: lockedBy,
+		Error:    err.Error(),
+	})
+	return false
+}
+
+// removeTree deletes a directory tree like removeAllWithRetry, recording its size
+// as freed once it is gone
+func (bc *BrowserCleaner) removeTree(path string) error {
+	size := treeSize(path)
+	if err := removeAllWithRetry(path); err != nil {
+		return err
+	}
+	if bc.removal != nil {
+		bc.removal.bytesFreed += size
+	}
+	return nil
+}
+
+// treeSize returns the total size of the files below path
+func treeSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// skipPath logs a path a cleaning walk could not access. Paths denied for lack of
+// permission are recorded so they are reported instead of silently skipped.
+func (bc *BrowserCleaner) skipPath(path string, err error) {
+	bc.logger().Debug("Skipped %s: %v", path, err)
+	if bc.removal != nil && cleaner.IsPermissionError(err) {
+		bc.removal.denied = append(bc.removal.denied, path)
+	}
+}
+
+// applyTo copies the recorded removal failures into a clean result
+func (rt *removalTracker) applyTo(result *BrowserCleanResult) {
+	result.LockedFiles = append(result.LockedFiles, rt.lockedFiles...)
+	result.PendingRebootDeletions = append(result.PendingRebootDeletions, rt.pendingReboot...)
+	result.Errors = append(result.Errors, rt.failed...)
+	result.PermissionDenied = append(result.PermissionDenied, rt.denied...)
+	result.BytesFreed += rt.bytesFreed
+
+	for _, locked := range rt.lockedFiles {
+		result.Errors = append(result.Errors, fmt.Sprintf("File locked by another process: %s", locked.Path))
+	}
+}
+
+// addError records a failed cleaning step. Permission errors are also listed in
+// PermissionDenied, as they need the user to act before a retry can succeed.
+func (r *BrowserCleanResult) addError(step string, err error) {
+	r.Errors = append(r.Errors, fmt.Sprintf("Failed to %s: %v", step, err))
+	if cleaner.IsPermissionError(err) {
+		r.PermissionDenied = append(r.PermissionDenied, cleaner.PermissionDeniedPath(err))
+	}
+}
+
+// removeWithRetry removes a file, retrying with the default retry policy while it is locked
+func removeWithRetry(path string) error {
+	return retry.Do(context.Background(), func() error {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
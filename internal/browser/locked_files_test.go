@@ -0,0 +1,113 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/logger"
+)
+
+func TestRemoveFileReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	bc := &BrowserCleaner{removal: &removalTracker{}}
+
+	file := filepath.Join(dir, "augment.ldb")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if !bc.removeFile(file) {
+		t.Error("Expected removable file to be deleted")
+	}
+
+	// A non-empty directory cannot be removed with os.Remove
+	stubborn := filepath.Join(dir, "augment-dir")
+	if err := os.MkdirAll(filepath.Join(stubborn, "child"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if bc.removeFile(stubborn) {
+		t.Error("Expected removal of non-empty directory to fail")
+	}
+
+	var result BrowserCleanResult
+	bc.removal.applyTo(&result)
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], stubborn) {
+		t.Errorf("Expected one failure naming %s, got %v", stubborn, result.Errors)
+	}
+	if len(result.LockedFiles) != 0 || len(result.PendingRebootDeletions) != 0 {
+		t.Errorf("Expected no locked files, got %+v", result)
+	}
+}
+
+func TestRemoveFileMissing(t *testing.T) {
+	bc := &BrowserCleaner{}
+	if bc.removeFile(filepath.Join(t.TempDir(), "missing.ldb")) {
+		t.Error("Expected missing file not to count as removed")
+	}
+
+	bc.removal = &removalTracker{}
+	bc.removeFile(filepath.Join(t.TempDir(), "missing.ldb"))
+	if len(bc.removal.failed) != 0 {
+		t.Errorf("Expected missing file not to be reported as a failure, got %v", bc.removal.failed)
+	}
+}
+
+func TestScheduleDeleteOnRebootUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("deletion at reboot is supported on Windows")
+	}
+	if err := scheduleDeleteOnReboot(filepath.Join(t.TempDir(), "file")); err == nil {
+		t.Error("Expected scheduling deletion at reboot to fail outside Windows")
+	}
+}
+
+func TestRemoveFileLogsAtDebug(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "augment.ldb")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var lines []string
+	bc := &BrowserCleaner{removal: &removalTracker{}}
+	bc.SetLogger(logger.FuncLogger(func(level logger.LogLevel, message string) {
+		if level == logger.DEBUG {
+			lines = append(lines, message)
+		}
+	}))
+
+	bc.removeFile(file)
+	bc.removeFile(filepath.Join(dir, "missing.ldb"))
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected two DEBUG lines, got %v", lines)
+	}
+	if lines[0] != "Deleted "+file {
+		t.Errorf("Unexpected deletion trace: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "Skipped "+filepath.Join(dir, "missing.ldb")+": ") {
+		t.Errorf("Unexpected skip trace: %s", lines[1])
+	}
+}
+
+func TestAddErrorListsPermissionDenied(t *testing.T) {
+	var result BrowserCleanResult
+	denied := &fs.PathError{Op: "open", Path: "/Safari/LocalStorage", Err: syscall.EPERM}
+
+	result.addError("clean storage", fmt.Errorf("walk failed: %w", denied))
+	result.addError("clean cookies", errors.New("database is locked"))
+
+	if len(result.Errors) != 2 || result.Errors[0] != "Failed to clean storage: walk failed: open /Safari/LocalStorage: operation not permitted" {
+		t.Errorf("Unexpected errors: %v", result.Errors)
+	}
+	if len(result.PermissionDenied) != 1 || result.PermissionDenied[0] != "/Safari/LocalStorage" {
+		t.Errorf("Expected only the permission error in PermissionDenied, got %v", result.PermissionDenied)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// errRebootDeleteUnsupported is returned when deletion at reboot is requested outside Windows
+var errRebootDeleteUnsupported = errors.New("scheduling deletion at reboot is only supported on Windows")
+
+// isSharingViolation reports whether err means another process holds the file open.
+// POSIX systems allow deleting open files, so this never happens here.
+func isSharingViolation(err error) bool {
+	return false
+}
+
+// findLockingProcesses is only implemented on Windows (Restart Manager)
+func findLockingProcesses(path string) ([]BrowserProcess, error) {
+	return nil, fmt.Errorf("finding locking processes is not supported on %s", runtime.GOOS)
+}
+
+// scheduleDeleteOnReboot is only implemented on Windows (MoveFileEx)
+func scheduleDeleteOnReboot(path string) error {
+	return errRebootDeleteUnsupported
+}
@@ -0,0 +1,108 @@
+//go:build windows
+
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Restart Manager (rstrtmgr.dll) reports which processes hold a file open
+var (
+	modrstrtmgr             = windows.NewLazySystemDLL("rstrtmgr.dll")
+	procRmStartSession      = modrstrtmgr.NewProc("RmStartSession")
+	procRmRegisterResources = modrstrtmgr.NewProc("RmRegisterResources")
+	procRmGetList           = modrstrtmgr.NewProc("RmGetList")
+	procRmEndSession        = modrstrtmgr.NewProc("RmEndSession")
+)
+
+// Restart Manager buffer sizes (CCH_RM_SESSION_KEY, CCH_RM_MAX_APP_NAME, CCH_RM_MAX_SVC_NAME)
+const (
+	rmSessionKeyLen = 32
+	rmMaxAppName    = 255
+	rmMaxSvcName    = 63
+)
+
+// rmUniqueProcess mirrors RM_UNIQUE_PROCESS
+type rmUniqueProcess struct {
+	ProcessID        uint32
+	ProcessStartTime windows.Filetime
+}
+
+// rmProcessInfo mirrors RM_PROCESS_INFO
+type rmProcessInfo struct {
+	Process          rmUniqueProcess
+	AppName          [rmMaxAppName + 1]uint16
+	ServiceShortName [rmMaxSvcName + 1]uint16
+	ApplicationType  uint32
+	AppStatus        uint32
+	TSSessionID      uint32
+	Restartable      int32
+}
+
+// isSharingViolation reports whether err means another process holds the file open
+func isSharingViolation(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
+
+// findLockingProcesses asks the Restart Manager which processes hold path open
+func findLockingProcesses(path string) ([]BrowserProcess, error) {
+	var session uint32
+	var sessionKey [rmSessionKeyLen + 1]uint16
+	if ret, _, _ := procRmStartSession.Call(uintptr(unsafe.Pointer(&session)), 0, uintptr(unsafe.Pointer(&sessionKey[0]))); ret != 0 {
+		return nil, fmt.Errorf("failed to start restart manager session: %w", windows.Errno(ret))
+	}
+	defer procRmEndSession.Call(uintptr(session))
+
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if ret, _, _ := procRmRegisterResources.Call(uintptr(session), 1, uintptr(unsafe.Pointer(&pathPtr)), 0, 0, 0, 0); ret != 0 {
+		return nil, fmt.Errorf("failed to register file with restart manager: %w", windows.Errno(ret))
+	}
+
+	infos := make([]rmProcessInfo, 8)
+	var needed, count, rebootReasons uint32
+	for {
+		count = uint32(len(infos))
+		ret, _, _ := procRmGetList.Call(uintptr(session),
+			uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&count)),
+			uintptr(unsafe.Pointer(&infos[0])), uintptr(unsafe.Pointer(&rebootReasons)))
+		if windows.Errno(ret) == windows.ERROR_MORE_DATA && needed > uint32(len(infos)) {
+			infos = make([]rmProcessInfo, needed)
+			continue
+		}
+		if ret != 0 {
+			return nil, fmt.Errorf("failed to list locking processes: %w", windows.Errno(ret))
+		}
+		break
+	}
+
+	processes := make([]BrowserProcess, 0, count)
+	for _, info := range infos[:count] {
+		processes = append(processes, BrowserProcess{
+			PID:       int(info.Process.ProcessID),
+			Name:      windows.UTF16ToString(info.AppName[:]),
+			StartTime: time.Unix(0, info.Process.ProcessStartTime.Nanoseconds()),
+		})
+	}
+	return processes, nil
+}
+
+// scheduleDeleteOnReboot registers path for deletion at the next reboot.
+// This requires administrator rights.
+func scheduleDeleteOnReboot(path string) error {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if err := windows.MoveFileEx(pathPtr, nil, windows.MOVEFILE_DELAY_UNTIL_REBOOT); err != nil {
+		return fmt.Errorf("failed to schedule deletion at reboot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package browser
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// panicError is a recovered panic, e.g. of a parser fed a malformed profile file
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+// Error describes the panic value; the stack is only logged at DEBUG level
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// detectionFailure is a browser whose profile detection panicked
+type detectionFailure struct {
+	browserType BrowserType
+	err         *panicError
+}
+
+// detectRecovering runs the profile detection of one browser, returning a panic
+// as a *panicError instead of letting it abort the detection of the other browsers
+func detectRecovering(browserType BrowserType, detect func(BrowserType) ([]BrowserProfile, error)) (profiles []BrowserProfile, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			profiles, err = nil, &panicError{value: r, stack: debug.Stack()}
+		}
+	}()
+	return detect(browserType)
+}
+
+// recoverProfilePanic turns a panic while a profile is closed or cleaned into an
+// error on its result, with the stack logged at DEBUG level, so the remaining
+// profiles are still cleaned. It must be deferred directly.
+func (bc *BrowserCleaner) recoverProfilePanic(result *BrowserCleanResult) {
+	if r := recover(); r != nil {
+		err := &panicError{value: r, stack: debug.Stack()}
+		bc.logger().Debug("Recovered from a panic while cleaning %s: %v\n%s", result.Profile.Name, r, err.stack)
+		result.addError("clean profile", err)
+	}
+}
+
+// detectionFailureResult returns the result reporting a browser whose detection
+// panicked, logging the stack at DEBUG level
+func (bc *BrowserCleaner) detectionFailureResult(failure detectionFailure) BrowserCleanResult {
+	bc.logger().Debug("Recovered from a panic while detecting %s profiles: %v\n%s",
+		failure.browserType.String(), failure.err.value, failure.err.stack)
+
+	result := BrowserCleanResult{
+		Profile: BrowserProfile{Type: failure.browserType, Name: failure.browserType.String()},
+	}
+	result.addError(fmt.Sprintf("detect %s profiles", failure.browserType.String()), failure.err)
+	return result
+}
@@ -0,0 +1,137 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/logger"
+)
+
+// writeAugmentStorage creates a Chromium profile with one Augment local storage file
+func writeAugmentStorage(t *testing.T, profilePath string) string {
+	t.Helper()
+	storageDir := filepath.Join(profilePath, "Local Storage", "leveldb")
+	if err := os.MkdirAll(storageDir, 0755); err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	file := filepath.Join(storageDir, "augment_session.log")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write storage file: %v", err)
+	}
+	return file
+}
+
+func TestCleanBrowserDataSurvivesDetectionPanic(t *testing.T) {
+	home := t.TempDir()
+	chromeDirs := chromiumDataDirs(Chrome, home, runtime.GOOS)
+	if len(chromeDirs) == 0 {
+		t.Skipf("No Chrome data directory on %s", runtime.GOOS)
+	}
+	storageFile := writeAugmentStorage(t, filepath.Join(chromeDirs[0], "Default"))
+
+	// A truncated profiles.ini whose section header is never closed
+	firefoxPath := firefoxDataDir(home, runtime.GOOS)
+	if err := os.MkdirAll(firefoxPath, 0755); err != nil {
+		t.Fatalf("Failed to create Firefox directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(firefoxPath, "profiles.ini"), []byte("[Profile0\nName=default\nPa"), 0644); err != nil {
+		t.Fatalf("Failed to write profiles.ini: %v", err)
+	}
+
+	detector := &BrowserDetector{homeDir: home}
+	detector.detect = func(browserType BrowserType) ([]BrowserProfile, error) {
+		if browserType != Firefox {
+			return detector.detectBrowser(browserType)
+		}
+		// A parser that trusts every section header to be closed
+		content, err := os.ReadFile(filepath.Join(firefoxPath, "profiles.ini"))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "[") {
+				_ = line[1:strings.Index(line, "]")]
+			}
+		}
+		return nil, nil
+	}
+
+	var debugLog strings.Builder
+	bc := &BrowserCleaner{detector: detector, processManager: NewProcessManager()}
+	bc.SetProcessLister(func() ([]BrowserProcess, error) { return nil, nil })
+	bc.SetLogger(logger.FuncLogger(func(level logger.LogLevel, message string) {
+		if level == logger.DEBUG {
+			debugLog.WriteString(message)
+		}
+	}))
+
+	results, err := bc.CleanBrowserData(false)
+	if err != nil {
+		t.Fatalf("CleanBrowserData() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected a Firefox failure and the Chrome profile, got %+v", results)
+	}
+
+	failure := results[0]
+	if failure.Profile.Type != Firefox || len(failure.Errors) != 1 || !strings.HasPrefix(failure.Errors[0], "Failed to detect Mozilla Firefox profiles: panic: ") {
+		t.Errorf("Expected the Firefox detection panic as an error, got %+v", failure)
+	}
+	if !strings.Contains(debugLog.String(), "goroutine") {
+		t.Errorf("Expected the panic stack in the debug log, got %q", debugLog.String())
+	}
+
+	chrome := results[1]
+	if chrome.Profile.Name != "Chrome - Default" || chrome.StorageDeleted != 1 || len(chrome.Errors) != 0 {
+		t.Errorf("Expected the Chrome profile to be cleaned, got %+v", chrome)
+	}
+	if _, err := os.Stat(storageFile); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", storageFile, err)
+	}
+}
+
+func TestCleanBrowserDataSurvivesProfilePanic(t *testing.T) {
+	root := t.TempDir()
+	first := filepath.Join(root, "Default")
+	second := filepath.Join(root, "Profile 1")
+	firstFile := writeAugmentStorage(t, first)
+	secondFile := writeAugmentStorage(t, second)
+
+	bc := &BrowserCleaner{processManager: NewProcessManager()}
+	bc.SetProfiles([]BrowserProfile{
+		{Type: Chrome, Name: "Chrome - Default", ProfilePath: first},
+		{Type: Chrome, Name: "Chrome - Profile 1", ProfilePath: second},
+	})
+	calls := 0
+	bc.SetProcessLister(func() ([]BrowserProcess, error) {
+		calls++
+		if calls == 1 {
+			var processes []BrowserProcess
+			return processes[:1], nil
+		}
+		return nil, nil
+	})
+
+	results, err := bc.CleanBrowserData(false)
+	if err != nil {
+		t.Fatalf("CleanBrowserData() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected both profiles in the results, got %+v", results)
+	}
+	if errs := results[0].Errors; len(errs) != 1 || !strings.HasPrefix(errs[0], "Failed to clean profile: panic: ") {
+		t.Errorf("Expected the panic as an error of the first profile, got %v", errs)
+	}
+	if _, err := os.Stat(firstFile); err != nil {
+		t.Errorf("Expected the first profile to be left alone, got %v", err)
+	}
+	if results[1].StorageDeleted != 1 || len(results[1].Errors) != 0 {
+		t.Errorf("Expected the second profile to be cleaned, got %+v", results[1])
+	}
+	if _, err := os.Stat(secondFile); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", secondFile, err)
+	}
+}
@@ -3,25 +3,172 @@ package browser
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
 )
 
+// psCommTruncation is the length Linux truncates process names to in /proc and ps output
+const psCommTruncation = 15
+
+// BrowserProcess describes a running process matched to a browser
+type BrowserProcess struct {
+	Browser   string    `json:"browser"`
+	PID       int       `json:"pid"`
+	Name      string    `json:"name"`
+	Exe       string    `json:"exe,omitempty"` // Empty when the executable path cannot be read
+	User      string    `json:"user,omitempty"`
+	StartTime time.Time `json:"start_time,omitempty"` // Zero when the process cannot be opened
+	CmdLine   []string  `json:"-"`                    // Empty when the command line cannot be read
+}
+
+// String returns a one-line description of the process for error messages
+func (p BrowserProcess) String() string {
+	details := []string{fmt.Sprintf("pid %d", p.PID)}
+	if p.User != "" {
+		details = append(details, "user "+p.User)
+	}
+	if !p.StartTime.IsZero() {
+		details = append(details, "started "+p.StartTime.Format("2006-01-02 15:04:05"))
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, strings.Join(details, ", "))
+}
+
 // ProcessManager handles browser process management
-type ProcessManager struct{}
+type ProcessManager struct {
+	goos              string
+	extraProcessNames map[BrowserType][]string
+	listProcesses     func() ([]BrowserProcess, error)
+}
 
 // NewProcessManager creates a new process manager
 func NewProcessManager() *ProcessManager {
-	return &ProcessManager{}
+	pm := &ProcessManager{goos: runtime.GOOS}
+	pm.listProcesses = pm.listSystemProcesses
+	return pm
+}
+
+// SetExtraProcessNames adds process names matched in addition to the built-in
+// names for each browser type (e.g. chrome-beta or renamed corporate builds)
+func (pm *ProcessManager) SetExtraProcessNames(names map[BrowserType][]string) {
+	pm.extraProcessNames = names
+}
+
+// ProcessNames returns the process names matched for a browser type
+func (pm *ProcessManager) ProcessNames(browserType BrowserType) []string {
+	names := defaultProcessNames(browserType, pm.goos)
+	return append(names, pm.extraProcessNames[browserType]...)
+}
+
+// FindProcesses returns the running processes matching a browser type
+func (pm *ProcessManager) FindProcesses(browserType BrowserType) ([]BrowserProcess, error) {
+	processes, err := pm.listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	return matchProcesses(processes, browserType, pm.ProcessNames(browserType), pm.goos), nil
+}
+
+// FindProfileProcesses returns the running processes using a profile. WebView2
+// runtime processes are shared by every host app, so only those started with
+// the profile's user data folder as --user-data-dir are returned; when the
+// command line of one cannot be read, its host app cannot be told and an error
+// asking to close the host app is returned instead.
+func (pm *ProcessManager) FindProfileProcesses(profile BrowserProfile) ([]BrowserProcess, error) {
+	processes, err := pm.FindProcesses(profile.Type)
+	if err != nil || profile.Type != WebView2 {
+		return processes, err
+	}
+
+	var matched []BrowserProcess
+	for _, process := range processes {
+		if len(process.CmdLine) == 0 {
+			return nil, fmt.Errorf("cannot read the command line of %s to tell which app it belongs to; close %s and try again", process, profile.HostApp)
+		}
+		if sameUserDataDir(userDataDirFlag(process.CmdLine), profile.DataPath) {
+			matched = append(matched, process)
+		}
+	}
+	return matched, nil
 }
 
-// ForceCloseBrowser attempts to forcefully close all browser processes
+// userDataDirFlag returns the value of the --user-data-dir argument of a command line
+func userDataDirFlag(args []string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--user-data-dir="); ok {
+			return strings.Trim(value, `"`)
+		}
+		if arg == "--user-data-dir" && i+1 < len(args) {
+			return strings.Trim(args[i+1], `"`)
+		}
+	}
+	return ""
+}
+
+// sameUserDataDir reports whether two user data folders are the same, ignoring
+// case, separators and trailing separators as Windows does
+func sameUserDataDir(a, b string) bool {
+	normalize := func(path string) string {
+		return strings.TrimRight(strings.ToLower(strings.ReplaceAll(path, `\`, "/")), "/")
+	}
+	return a != "" && normalize(a) == normalize(b)
+}
+
+// ListBrowserProcesses returns every running process the manager would match for
+// browserTypes, or for any browser when none are given
+func (pm *ProcessManager) ListBrowserProcesses(browserTypes ...BrowserType) ([]BrowserProcess, error) {
+	processes, err := pm.listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(browserTypes) == 0 {
+		browserTypes = allBrowserTypes
+	}
+
+	var matched []BrowserProcess
+	for _, browserType := range browserTypes {
+		matched = append(matched, matchProcesses(processes, browserType, pm.ProcessNames(browserType), pm.goos)...)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Browser != matched[j].Browser {
+			return matched[i].Browser < matched[j].Browser
+		}
+		return matched[i].PID < matched[j].PID
+	})
+
+	return matched, nil
+}
+
+// ForceCloseBrowser attempts to forcefully close all browser processes. Processes
+// are closed by PID, so closing one Chrome channel leaves the others running.
 func (pm *ProcessManager) ForceCloseBrowser(browserType BrowserType) error {
+	processes, err := pm.FindProcesses(browserType)
+	if err != nil {
+		return err
+	}
+	return pm.terminateProcesses(processes)
+}
+
+// CloseProcesses forcefully closes the given processes by PID
+func (pm *ProcessManager) CloseProcesses(processes []BrowserProcess) error {
+	return pm.terminateProcesses(processes)
+}
+
+// defaultProcessNames returns the built-in process names of a browser on the given OS
+func defaultProcessNames(browserType BrowserType, goos string) []string {
 	var processNames []string
 
 	switch browserType {
 	case Chrome:
-		switch runtime.GOOS {
+		switch goos {
 		case "windows":
 			processNames = []string{"chrome.exe", "chrome_proxy.exe", "chrome_crashpad_handler.exe"}
 		case "darwin":
@@ -29,8 +176,18 @@ func (pm *ProcessManager) ForceCloseBrowser(browserType BrowserType) error {
 		case "linux":
 			processNames = []string{"chrome", "chromium", "google-chrome", "chrome-sandbox"}
 		}
+	case ChromeBeta, ChromeDev, ChromeCanary:
+		// Only macOS names the channels apart; see chromeChannelInstallDirs
+		switch goos {
+		case "windows":
+			processNames = []string{"chrome.exe", "chrome_proxy.exe", "chrome_crashpad_handler.exe"}
+		case "darwin":
+			processNames = []string{browserType.String(), browserType.String() + " Helper"}
+		case "linux":
+			processNames = []string{"chrome", "chrome-sandbox"}
+		}
 	case Edge:
-		switch runtime.GOOS {
+		switch goos {
 		case "windows":
 			processNames = []string{"msedge.exe", "msedge_proxy.exe", "msedgewebview2.exe"}
 		case "darwin":
@@ -38,8 +195,18 @@ func (pm *ProcessManager) ForceCloseBrowser(browserType BrowserType) error {
 		case "linux":
 			processNames = []string{"microsoft-edge", "msedge"}
 		}
+	case Arc:
+		if goos == "darwin" {
+			processNames = []string{"Arc", "Arc Helper"}
+		}
+	case WebView2:
+		// The host apps run their own executables; only the WebView2 runtime
+		// processes hold the user data folder open
+		if goos == "windows" {
+			processNames = []string{"msedgewebview2.exe"}
+		}
 	case Firefox:
-		switch runtime.GOOS {
+		switch goos {
 		case "windows":
 			processNames = []string{"firefox.exe", "plugin-container.exe", "crashreporter.exe"}
 		case "darwin":
@@ -48,37 +215,121 @@ func (pm *ProcessManager) ForceCloseBrowser(browserType BrowserType) error {
 			processNames = []string{"firefox", "firefox-bin", "plugin-container"}
 		}
 	case Safari:
-		if runtime.GOOS == "darwin" {
+		if goos == "darwin" {
 			processNames = []string{"Safari", "com.apple.WebKit.WebContent", "SafariForWebKitDevelopment"}
 		}
 	}
 
-	return pm.terminateProcesses(processNames)
+	return processNames
+}
+
+// chromeChannelInstallDirs holds, per OS, a fragment of the lowercase, slash-separated
+// executable path of each Chrome channel that runs under stable Chrome's process names
+var chromeChannelInstallDirs = map[string]map[BrowserType]string{
+	"windows": {
+		ChromeBeta:   "/google/chrome beta/application/",
+		ChromeDev:    "/google/chrome dev/application/",
+		ChromeCanary: "/google/chrome sxs/application/",
+	},
+	"linux": {
+		ChromeBeta:   "/opt/google/chrome-beta/",
+		ChromeDev:    "/opt/google/chrome-unstable/",
+		ChromeCanary: "/opt/google/chrome-canary/",
+	},
+}
+
+// matchProcesses returns the processes whose name matches one of processNames
+// and that belong to browserType rather than to another Chrome channel
+func matchProcesses(processes []BrowserProcess, browserType BrowserType, processNames []string, goos string) []BrowserProcess {
+	var matched []BrowserProcess
+	for _, process := range processes {
+		if matchesProcessName(process.Name, processNames) && belongsToChannel(process.Exe, browserType, goos) {
+			process.Browser = browserType.String()
+			matched = append(matched, process)
+		}
+	}
+	return matched
+}
+
+// belongsToChannel reports whether a process with executable path exe belongs to
+// browserType where Chrome channels share process names. A channel's processes are
+// recognized by install directory; any other process, including one whose path
+// cannot be read, belongs to stable Chrome.
+func belongsToChannel(exe string, browserType BrowserType, goos string) bool {
+	installDirs := chromeChannelInstallDirs[goos]
+	exe = strings.ToLower(strings.ReplaceAll(exe, `\`, "/"))
+
+	if dir, ok := installDirs[browserType]; ok {
+		return strings.Contains(exe, dir)
+	}
+	if browserType == Chrome {
+		for _, dir := range installDirs {
+			if strings.Contains(exe, dir) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesProcessName reports whether a process name equals one of names, ignoring
+// case. Names truncated by the Linux kernel match any name they are a prefix of.
+func matchesProcessName(processName string, names []string) bool {
+	base := strings.ToLower(filepath.Base(processName))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if base == name {
+			return true
+		}
+		if len(base) == psCommTruncation && strings.HasPrefix(name, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// listSystemProcesses lists all running processes with their owner and start time
+func (pm *ProcessManager) listSystemProcesses() ([]BrowserProcess, error) {
+	procs, err := utils.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]BrowserProcess, 0, len(procs))
+	for _, proc := range procs {
+		processes = append(processes, BrowserProcess{
+			PID:       proc.PID,
+			Name:      proc.Name,
+			Exe:       proc.Exe,
+			User:      proc.User,
+			StartTime: proc.StartTime,
+			CmdLine:   proc.CmdLine,
+		})
+	}
+	return processes, nil
 }
 
 // terminateProcesses terminates the specified processes
-func (pm *ProcessManager) terminateProcesses(processNames []string) error {
-	if len(processNames) == 0 {
+func (pm *ProcessManager) terminateProcesses(processes []BrowserProcess) error {
+	if len(processes) == 0 {
 		return nil
 	}
 
 	switch runtime.GOOS {
 	case "windows":
-		return pm.terminateWindowsProcesses(processNames)
-	case "darwin":
-		return pm.terminateMacProcesses(processNames)
-	case "linux":
-		return pm.terminateLinuxProcesses(processNames)
+		return pm.terminateWindowsProcesses(processes)
+	case "darwin", "linux":
+		return pm.terminateUnixProcesses(processes)
 	default:
 		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
 // terminateWindowsProcesses terminates processes on Windows
-func (pm *ProcessManager) terminateWindowsProcesses(processNames []string) error {
-	for _, name := range processNames {
-		cmd := exec.Command("taskkill", "/F", "/IM", name)
-		cmd.Run() // Ignore errors as process might not be running
+func (pm *ProcessManager) terminateWindowsProcesses(processes []BrowserProcess) error {
+	for _, process := range processes {
+		cmd := exec.Command("taskkill", "/F", "/PID", strconv.Itoa(process.PID))
+		cmd.Run() // Ignore errors as process might have exited
 	}
 	
 	// Wait a moment for processes to terminate
@@ -87,41 +338,23 @@ func (pm *ProcessManager) terminateWindowsProcesses(processNames []string) error
 	return nil
 }
 
-// terminateMacProcesses terminates processes on macOS
-func (pm *ProcessManager) terminateMacProcesses(processNames []string) error {
-	for _, name := range processNames {
-		// Try graceful termination first
-		cmd := exec.Command("pkill", "-f", name)
-		cmd.Run()
-		
-		// Wait a moment
-		time.Sleep(1 * time.Second)
-		
-		// Force kill if still running
-		cmd = exec.Command("pkill", "-9", "-f", name)
-		cmd.Run()
+// terminateUnixProcesses terminates processes on macOS and Linux
+func (pm *ProcessManager) terminateUnixProcesses(processes []BrowserProcess) error {
+	pids := make([]string, 0, len(processes))
+	for _, process := range processes {
+		pids = append(pids, strconv.Itoa(process.PID))
 	}
+
+	// Try graceful termination first
+	cmd := exec.Command("kill", pids...)
+	cmd.Run() // Ignore errors as processes might have exited
 	
-	// Wait for processes to terminate
-	time.Sleep(2 * time.Second)
+	// Wait a moment
+	time.Sleep(1 * time.Second)
 	
-	return nil
-}
-
-// terminateLinuxProcesses terminates processes on Linux
-func (pm *ProcessManager) terminateLinuxProcesses(processNames []string) error {
-	for _, name := range processNames {
-		// Try graceful termination first
-		cmd := exec.Command("pkill", "-f", name)
-		cmd.Run()
-		
-		// Wait a moment
-		time.Sleep(1 * time.Second)
-		
-		// Force kill if still running
-		cmd = exec.Command("pkill", "-9", "-f", name)
-		cmd.Run()
-	}
+	// Force kill if still running
+	cmd = exec.Command("kill", append([]string{"-9"}, pids...)...)
+	cmd.Run()
 	
 	// Wait for processes to terminate
 	time.Sleep(2 * time.Second)
@@ -129,26 +362,35 @@ func (pm *ProcessManager) terminateLinuxProcesses(processNames []string) error {
 	return nil
 }
 
-// WaitForProcessesToClose waits for browser processes to close with timeout
-func (pm *ProcessManager) WaitForProcessesToClose(browserType BrowserType, timeout time.Duration) error {
-	detector, err := NewBrowserDetector()
-	if err != nil {
-		return fmt.Errorf("failed to create browser detector: %w", err)
-	}
+// WaitForProcessesToClose waits for browser processes to close with timeout.
+// On timeout the processes still running are returned along with the error.
+func (pm *ProcessManager) WaitForProcessesToClose(browserType BrowserType, timeout time.Duration) ([]BrowserProcess, error) {
+	return waitForProcessesToClose(func() ([]BrowserProcess, error) { return pm.FindProcesses(browserType) }, browserType, timeout)
+}
+
+// WaitForProfileProcessesToClose waits for the processes using a profile to
+// close with timeout, returning the processes still running on timeout
+func (pm *ProcessManager) WaitForProfileProcessesToClose(profile BrowserProfile, timeout time.Duration) ([]BrowserProcess, error) {
+	return waitForProcessesToClose(func() ([]BrowserProcess, error) { return pm.FindProfileProcesses(profile) }, profile.Type, timeout)
+}
 
+// waitForProcessesToClose polls find until it returns no processes or timeout passes
+func waitForProcessesToClose(find func() ([]BrowserProcess, error), browserType BrowserType, timeout time.Duration) ([]BrowserProcess, error) {
 	start := time.Now()
-	for time.Since(start) < timeout {
-		isRunning, err := detector.IsProcessRunning(browserType)
+	for {
+		processes, err := find()
 		if err != nil {
-			return fmt.Errorf("failed to check if browser is running: %w", err)
+			return nil, fmt.Errorf("failed to check if browser is running: %w", err)
 		}
-		
-		if !isRunning {
-			return nil // Processes have closed
+
+		if len(processes) == 0 {
+			return nil, nil // Processes have closed
+		}
+
+		if time.Since(start) >= timeout {
+			return processes, fmt.Errorf("timeout waiting for %s processes to close", browserType.String())
 		}
-		
+
 		time.Sleep(500 * time.Millisecond)
 	}
-	
-	return fmt.Errorf("timeout waiting for %s processes to close", browserType.String())
-}
\ No newline at end of file
+}
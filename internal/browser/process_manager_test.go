@@ -0,0 +1,186 @@
+package browser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindProcessesWithExtraNames(t *testing.T) {
+	pm := &ProcessManager{goos: "linux"}
+	pm.listProcesses = func() ([]BrowserProcess, error) {
+		return []BrowserProcess{
+			{PID: 10, Name: "chrome"},
+			{PID: 11, Name: "chrome-beta"},
+			{PID: 12, Name: "chrome_crashpad"}, // truncated by the kernel
+			{PID: 13, Name: "firefox"},
+			{PID: 14, Name: "corp-browser"},
+		}, nil
+	}
+
+	processes, err := pm.FindProcesses(Chrome)
+	if err != nil {
+		t.Fatalf("FindProcesses() failed: %v", err)
+	}
+	if len(processes) != 1 || processes[0].PID != 10 {
+		t.Errorf("Expected only the built-in chrome process, got %+v", processes)
+	}
+
+	pm.SetExtraProcessNames(map[BrowserType][]string{
+		Chrome: {"chrome-beta", "chrome_crashpad_handler", "Corp-Browser"},
+	})
+
+	processes, err = pm.FindProcesses(Chrome)
+	if err != nil {
+		t.Fatalf("FindProcesses() failed: %v", err)
+	}
+	var pids []int
+	for _, process := range processes {
+		pids = append(pids, process.PID)
+		if process.Browser != Chrome.String() {
+			t.Errorf("Expected browser %q, got %q", Chrome.String(), process.Browser)
+		}
+	}
+	if len(pids) != 4 || pids[0] != 10 || pids[1] != 11 || pids[2] != 12 || pids[3] != 14 {
+		t.Errorf("Expected PIDs [10 11 12 14], got %v", pids)
+	}
+
+	all, err := pm.ListBrowserProcesses()
+	if err != nil {
+		t.Fatalf("ListBrowserProcesses() failed: %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("Expected 5 matched processes across browsers, got %+v", all)
+	}
+}
+
+func TestFindProcessesTellsChromeChannelsApart(t *testing.T) {
+	tests := []struct {
+		goos      string
+		processes []BrowserProcess
+		want      map[BrowserType][]int
+	}{
+		{
+			goos: "windows",
+			processes: []BrowserProcess{
+				{PID: 10, Name: "chrome.exe", Exe: `C:\Program Files\Google\Chrome\Application\chrome.exe`},
+				{PID: 11, Name: "chrome.exe", Exe: `C:\Users\alice\AppData\Local\Google\Chrome SxS\Application\chrome.exe`},
+				{PID: 12, Name: "chrome.exe", Exe: `C:\Program Files\Google\Chrome Beta\Application\chrome.exe`},
+				{PID: 13, Name: "chrome.exe"}, // Another user's process
+			},
+			want: map[BrowserType][]int{Chrome: {10, 13}, ChromeBeta: {12}, ChromeDev: nil, ChromeCanary: {11}},
+		},
+		{
+			goos: "linux",
+			processes: []BrowserProcess{
+				{PID: 20, Name: "chrome", Exe: "/opt/google/chrome/chrome"},
+				{PID: 21, Name: "chrome", Exe: "/opt/google/chrome-unstable/chrome"},
+				{PID: 22, Name: "chromium", Exe: "/usr/lib/chromium/chromium"},
+			},
+			want: map[BrowserType][]int{Chrome: {20, 22}, ChromeBeta: nil, ChromeDev: {21}, ChromeCanary: nil},
+		},
+		{
+			goos: "darwin",
+			processes: []BrowserProcess{
+				{PID: 30, Name: "Google Chrome"},
+				{PID: 31, Name: "Google Chrome Canary"},
+				{PID: 32, Name: "Google Chrome Canary Helper"},
+				{PID: 33, Name: "Arc Helper"},
+			},
+			want: map[BrowserType][]int{Chrome: {30}, ChromeCanary: {31, 32}, Arc: {33}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			pm := &ProcessManager{goos: tt.goos}
+			pm.listProcesses = func() ([]BrowserProcess, error) { return tt.processes, nil }
+
+			for browserType, want := range tt.want {
+				processes, err := pm.FindProcesses(browserType)
+				if err != nil {
+					t.Fatalf("FindProcesses(%s) failed: %v", browserType, err)
+				}
+				var pids []int
+				for _, process := range processes {
+					pids = append(pids, process.PID)
+				}
+				if !reflect.DeepEqual(pids, want) {
+					t.Errorf("FindProcesses(%s) = PIDs %v, want %v", browserType, pids, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindProfileProcessesMatchesWebView2UserDataDir(t *testing.T) {
+	teams := BrowserProfile{Type: WebView2, HostApp: "Microsoft Teams", DataPath: `C:\Users\dev\AppData\Local\Microsoft\Teams\EBWebView`}
+	pm := &ProcessManager{goos: "windows"}
+	pm.listProcesses = func() ([]BrowserProcess, error) {
+		return []BrowserProcess{
+			{PID: 40, Name: "msedgewebview2.exe", CmdLine: []string{`C:\WebView2\msedgewebview2.exe`, `--user-data-dir=C:\Users\dev\AppData\Local\Microsoft\Teams\EBWebView`}},
+			{PID: 41, Name: "msedgewebview2.exe", CmdLine: []string{`C:\WebView2\msedgewebview2.exe`, "--type=renderer", "--user-data-dir", `c:\users\dev\appdata\local\microsoft\teams\ebwebview\`}},
+			{PID: 42, Name: "msedgewebview2.exe", CmdLine: []string{`C:\WebView2\msedgewebview2.exe`, `--user-data-dir=C:\Users\dev\AppData\Local\Microsoft\Olk\EBWebView`}},
+			{PID: 43, Name: "ms-teams.exe"},
+		}, nil
+	}
+
+	processes, err := pm.FindProfileProcesses(teams)
+	if err != nil {
+		t.Fatalf("FindProfileProcesses() failed: %v", err)
+	}
+	var pids []int
+	for _, process := range processes {
+		pids = append(pids, process.PID)
+	}
+	if !reflect.DeepEqual(pids, []int{40, 41}) {
+		t.Errorf("Expected only the Teams WebView2 processes [40 41], got %v", pids)
+	}
+
+	// A process whose command line cannot be read may belong to any host app
+	pm.listProcesses = func() ([]BrowserProcess, error) {
+		return []BrowserProcess{{PID: 44, Name: "msedgewebview2.exe"}}, nil
+	}
+	if _, err := pm.FindProfileProcesses(teams); err == nil || !strings.Contains(err.Error(), "close Microsoft Teams") {
+		t.Errorf("Expected an error asking to close the host app, got %v", err)
+	}
+
+	// Other browsers keep matching by name alone
+	pm.listProcesses = func() ([]BrowserProcess, error) {
+		return []BrowserProcess{{PID: 45, Name: "chrome.exe"}}, nil
+	}
+	if processes, err := pm.FindProfileProcesses(BrowserProfile{Type: Chrome}); err != nil || len(processes) != 1 {
+		t.Errorf("Expected the Chrome process, got %+v (%v)", processes, err)
+	}
+}
+
+func TestProcessErrorsListProcesses(t *testing.T) {
+	started := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.Local)
+	errs := processErrors("Chrome processes did not close in time.", []BrowserProcess{
+		{PID: 4242, Name: "chrome", User: "alice", StartTime: started},
+	})
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected message plus one process entry, got %v", errs)
+	}
+	for _, want := range []string{"chrome", "pid 4242", "user alice", "started 2026-10-15 09:00:00"} {
+		if !strings.Contains(errs[1], want) {
+			t.Errorf("Expected %q in %q", want, errs[1])
+		}
+	}
+}
+
+func TestParseBrowserType(t *testing.T) {
+	if browserType, err := ParseBrowserType(" Chrome "); err != nil || browserType != Chrome {
+		t.Errorf("ParseBrowserType(Chrome) = %v, %v", browserType, err)
+	}
+	for _, browserType := range allBrowserTypes {
+		if parsed, err := ParseBrowserType(browserType.ShortName()); err != nil || parsed != browserType {
+			t.Errorf("ParseBrowserType(%s) = %v, %v", browserType.ShortName(), parsed, err)
+		}
+	}
+	if _, err := ParseBrowserType("netscape"); err == nil {
+		t.Error("Expected error for unknown browser")
+	}
+}
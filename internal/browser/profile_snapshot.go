@@ -0,0 +1,160 @@
+package browser
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultProfileCacheTTL is how long a BrowserCleaner reuses detected profiles
+const DefaultProfileCacheTTL = 30 * time.Second
+
+// ProfileDataCount is the Augment data found in a single browser profile
+type ProfileDataCount struct {
+	Profile BrowserProfile `json:"profile"`
+	Cookies int64          `json:"cookies"`
+	Storage int64          `json:"storage"` // Local storage files, or storage directories for Firefox
+	// Extensions counts LevelDB folders and preferences of Augment browser extensions
+	Extensions int64 `json:"extensions"`
+	History    int64 `json:"history"` // History rows, only counted with SetIncludeHistory
+	Total      int64 `json:"total"`
+	// Bytes is the size of the counted storage files and extension folders, the
+	// estimate of BrowserCleanResult.BytesFreed
+	Bytes int64 `json:"bytes"`
+}
+
+// profileCache is the last browser detection result of a BrowserCleaner
+type profileCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	profiles   []BrowserProfile
+	failures   []detectionFailure // Browsers whose detection panicked
+	detectedAt time.Time
+	pinned     bool // Set with SetProfiles; never expires
+}
+
+// SetProfileCacheTTL sets how long detected profiles are reused before browsers are
+// detected again; 0 detects on every call
+func (bc *BrowserCleaner) SetProfileCacheTTL(ttl time.Duration) {
+	bc.profileCache.mu.Lock()
+	defer bc.profileCache.mu.Unlock()
+	bc.profileCache.ttl = ttl
+}
+
+// SetProfiles pins the profiles counted and cleaned, e.g. a snapshot taken by
+// Profiles on another cleaner, so a preview and the following clean cover exactly
+// the same profiles
+func (bc *BrowserCleaner) SetProfiles(profiles []BrowserProfile) {
+	bc.profileCache.mu.Lock()
+	defer bc.profileCache.mu.Unlock()
+	bc.profileCache.profiles = profiles
+	bc.profileCache.failures = nil
+	bc.profileCache.pinned = true
+}
+
+// Profiles returns every detected browser profile, reusing the previous detection
+// while it is younger than the cache TTL
+func (bc *BrowserCleaner) Profiles() ([]BrowserProfile, error) {
+	cache := &bc.profileCache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.pinned || (cache.profiles != nil && time.Since(cache.detectedAt) < cache.ttl) {
+		return cache.profiles, nil
+	}
+
+	profiles, failures := bc.detector.detectBrowsers()
+	if profiles == nil {
+		profiles = []BrowserProfile{} // Cache "no browsers" as well
+	}
+
+	cache.profiles = profiles
+	cache.failures = failures
+	cache.detectedAt = time.Now()
+	return profiles, nil
+}
+
+// detectProfiles returns the detected profiles that should be cleaned
+func (bc *BrowserCleaner) detectProfiles() ([]BrowserProfile, error) {
+	profiles, err := bc.Profiles()
+	if err != nil {
+		return nil, err
+	}
+	if !bc.defaultProfilesOnly && len(bc.browsers) == 0 {
+		return profiles, nil
+	}
+
+	var selected []BrowserProfile
+	for _, profile := range profiles {
+		if bc.defaultProfilesOnly && !profile.IsDefault {
+			continue
+		}
+		if bc.selectsBrowser(profile.Type) {
+			selected = append(selected, profile)
+		}
+	}
+	return selected, nil
+}
+
+// detectionFailures returns the selected browsers whose last detection panicked
+func (bc *BrowserCleaner) detectionFailures() []detectionFailure {
+	bc.profileCache.mu.Lock()
+	defer bc.profileCache.mu.Unlock()
+
+	var failures []detectionFailure
+	for _, failure := range bc.profileCache.failures {
+		if bc.selectsBrowser(failure.browserType) {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// selectsBrowser reports whether profiles of browserType are cleaned, see SetBrowsers
+func (bc *BrowserCleaner) selectsBrowser(browserType BrowserType) bool {
+	if len(bc.browsers) == 0 {
+		return true
+	}
+	for _, selected := range bc.browsers {
+		if selected == browserType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBrowserDataCount returns the Augment data of every profile that would be cleaned
+// (for dry-run), in detection order. Profiles are counted concurrently.
+func (bc *BrowserCleaner) GetBrowserDataCount() ([]ProfileDataCount, error) {
+	profiles, err := bc.detectProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect browsers: %w", err)
+	}
+
+	counts := make([]ProfileDataCount, len(profiles))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(profiles) {
+		workers = len(profiles)
+	}
+
+	jobs := make(chan int, len(profiles))
+	for i := range profiles {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				counts[index] = bc.countAugmentData(profiles[index]) // Each worker writes distinct indexes
+			}
+		}()
+	}
+	wg.Wait()
+
+	return counts, nil
+}
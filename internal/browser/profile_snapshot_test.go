@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeFirefoxProfiles creates profile directories and a profiles.ini listing them
+func writeFirefoxProfiles(t *testing.T, firefoxPath string, names ...string) {
+	t.Helper()
+
+	ini := ""
+	for i, name := range names {
+		if err := os.MkdirAll(filepath.Join(firefoxPath, name), 0755); err != nil {
+			t.Fatalf("Failed to create profile: %v", err)
+		}
+		ini += fmt.Sprintf("[Profile%d]\nName=%s\nPath=%s\n\n", i, name, name)
+	}
+	if err := os.WriteFile(filepath.Join(firefoxPath, "profiles.ini"), []byte(ini), 0644); err != nil {
+		t.Fatalf("Failed to write profiles.ini: %v", err)
+	}
+}
+
+func TestProfilesAreCachedForTTL(t *testing.T) {
+	home := t.TempDir()
+	firefoxPath := firefoxDataDir(home, runtime.GOOS)
+	bc := &BrowserCleaner{
+		detector:       &BrowserDetector{homeDir: home},
+		processManager: NewProcessManager(),
+		profileCache:   profileCache{ttl: time.Hour},
+	}
+
+	writeFirefoxProfiles(t, firefoxPath, "first")
+	profiles, err := bc.Profiles()
+	if err != nil || len(profiles) != 1 {
+		t.Fatalf("Expected 1 profile, got %d (%v)", len(profiles), err)
+	}
+
+	writeFirefoxProfiles(t, firefoxPath, "first", "second")
+	if profiles, _ := bc.Profiles(); len(profiles) != 1 {
+		t.Errorf("Expected the cached detection within the TTL, got %d profiles", len(profiles))
+	}
+
+	bc.SetProfileCacheTTL(0)
+	if profiles, _ := bc.Profiles(); len(profiles) != 2 {
+		t.Errorf("Expected a fresh detection without a TTL, got %d profiles", len(profiles))
+	}
+
+	pinned := []BrowserProfile{{Type: Firefox, Name: "Firefox - pinned", ProfilePath: filepath.Join(firefoxPath, "first")}}
+	bc.SetProfiles(pinned)
+	if profiles, _ := bc.Profiles(); len(profiles) != 1 || profiles[0].Name != "Firefox - pinned" {
+		t.Errorf("Expected the pinned snapshot, got %+v", profiles)
+	}
+}
+
+func TestGetBrowserDataCountKeepsProfileOrder(t *testing.T) {
+	root := t.TempDir()
+	var profiles []BrowserProfile
+	for i := 0; i < 8; i++ {
+		profilePath := filepath.Join(root, fmt.Sprintf("Profile %d", i))
+		storageDir := filepath.Join(profilePath, "Local Storage", "leveldb")
+		if err := os.MkdirAll(storageDir, 0755); err != nil {
+			t.Fatalf("Failed to create profile: %v", err)
+		}
+		for j := 0; j < i; j++ {
+			file := filepath.Join(storageDir, fmt.Sprintf("augment_%d.log", j))
+			if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+				t.Fatalf("Failed to write storage file: %v", err)
+			}
+		}
+		profiles = append(profiles, BrowserProfile{Type: Chrome, Name: fmt.Sprintf("Chrome - Profile %d", i), ProfilePath: profilePath})
+	}
+
+	bc := &BrowserCleaner{processManager: NewProcessManager()}
+	bc.SetProfiles(profiles)
+
+	counts, err := bc.GetBrowserDataCount()
+	if err != nil {
+		t.Fatalf("GetBrowserDataCount() failed: %v", err)
+	}
+	if len(counts) != len(profiles) {
+		t.Fatalf("Expected %d counts, got %d", len(profiles), len(counts))
+	}
+	for i, count := range counts {
+		if count.Profile.Name != profiles[i].Name {
+			t.Errorf("Count %d is for %s, expected %s", i, count.Profile.Name, profiles[i].Name)
+		}
+		if count.Storage != int64(i) || count.Total != int64(i) {
+			t.Errorf("%s: expected %d storage items, got %+v", count.Profile.Name, i, count)
+		}
+	}
+}
+
+func TestChromeChannelsAreSeparateBrowsers(t *testing.T) {
+	home := t.TempDir()
+	for _, browserType := range []BrowserType{Chrome, ChromeCanary} {
+		dirs := chromiumDataDirs(browserType, home, runtime.GOOS)
+		if len(dirs) == 0 {
+			t.Skipf("No %s data directory on %s", browserType, runtime.GOOS)
+		}
+		for _, profile := range []string{"Default", "Profile 1"} {
+			if err := os.MkdirAll(filepath.Join(dirs[0], profile), 0755); err != nil {
+				t.Fatalf("Failed to create profile: %v", err)
+			}
+		}
+	}
+
+	bc := &BrowserCleaner{
+		detector:       &BrowserDetector{homeDir: home},
+		processManager: NewProcessManager(),
+	}
+	profiles, err := bc.Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() failed: %v", err)
+	}
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	want := []string{"Chrome - Default", "Chrome - Profile 1", "Chrome Canary - Default", "Chrome Canary - Profile 1"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected profiles %v, got %v", want, names)
+	}
+
+	bc.SetBrowsers(ChromeCanary)
+	bc.SetDefaultProfilesOnly(true)
+	selected, err := bc.detectProfiles()
+	if err != nil {
+		t.Fatalf("detectProfiles() failed: %v", err)
+	}
+	if len(selected) != 1 || selected[0].Type != ChromeCanary || !selected[0].IsDefault {
+		t.Errorf("Expected only the default Canary profile, got %+v", selected)
+	}
+}
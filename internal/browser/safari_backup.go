@@ -0,0 +1,153 @@
+package browser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// ErrSIPProtected is returned when macOS denies access to Safari data, which
+// happens unless the terminal or app has been granted Full Disk Access
+var ErrSIPProtected = errors.New("Safari data is protected by macOS System Integrity Protection; grant Full Disk Access and try again")
+
+// safariBackupManifestName is the manifest written to every Safari backup
+const safariBackupManifestName = "backup.json"
+
+// safariBackupSources are the Safari files and directories backed up before
+// cleaning, relative to ~/Library
+var safariBackupSources = []string{
+	filepath.Join("Cookies", "Cookies.binarycookies"),
+	filepath.Join("Safari", "LocalStorage"),
+	filepath.Join("Caches", "com.apple.Safari", "fsCachedData"),
+}
+
+// SafariBackupManifest lists the contents of a Safari backup
+type SafariBackupManifest struct {
+	CreatedAt time.Time           `json:"created_at"`
+	Profile   string              `json:"profile"`
+	Files     []SafariBackupEntry `json:"files"`
+	TotalSize int64               `json:"total_size"`
+}
+
+// SafariBackupEntry is a file in a Safari backup, relative to the backup directory
+type SafariBackupEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// createSafariBackup backs up the Safari cookies, local storage and cache to a
+// timestamped directory with a backup.json manifest. It returns ErrSIPProtected
+// before copying anything if macOS denies reading any of them.
+func (bc *BrowserCleaner) createSafariBackup(profile BrowserProfile) (string, error) {
+	// The profile is ~/Library/Safari; cookies and cache live elsewhere in ~/Library
+	libraryDir := filepath.Dir(profile.ProfilePath)
+
+	for _, source := range safariBackupSources {
+		if err := checkReadable(filepath.Join(libraryDir, source)); err != nil {
+			if cleaner.IsPermissionError(err) {
+				return "", fmt.Errorf("%w: %w", ErrSIPProtected, err)
+			}
+			return "", fmt.Errorf("failed to read %s: %w", source, err)
+		}
+	}
+
+	backupPath := bc.profileBackupPath(profile)
+	if err := os.MkdirAll(backupPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile backup directory: %w", err)
+	}
+
+	manifest := SafariBackupManifest{
+		CreatedAt: time.Now(),
+		Profile:   profile.Name,
+		Files:     []SafariBackupEntry{},
+	}
+	for _, source := range safariBackupSources {
+		entries, err := copySafariSource(libraryDir, backupPath, source)
+		if err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", source, err)
+		}
+		for _, entry := range entries {
+			manifest.Files = append(manifest.Files, entry)
+			manifest.TotalSize += entry.Size
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(backupPath, safariBackupManifestName), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	return backupPath, nil
+}
+
+// checkReadable reads the first byte of a file or entry of a directory, as macOS
+// only denies Safari data when it is opened or listed; a missing path is not an error
+func checkReadable(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		_, err = f.Readdirnames(1)
+	} else {
+		_, err = f.Read(make([]byte, 1))
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// copySafariSource copies source, a file or directory relative to libraryDir, to
+// the same relative path below backupPath and returns the files copied
+func copySafariSource(libraryDir, backupPath, source string) ([]SafariBackupEntry, error) {
+	src := filepath.Join(libraryDir, source)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var entries []SafariBackupEntry
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// LevelDB's LOCK file is held open by a running browser and holds no data
+		if info.Name() == "LOCK" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(libraryDir, path)
+		if err != nil {
+			return err
+		}
+		if err := utils.CopyFile(path, filepath.Join(backupPath, rel)); err != nil {
+			return err
+		}
+		entries = append(entries, SafariBackupEntry{Path: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	return entries, err
+}
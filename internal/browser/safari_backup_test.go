@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateSafariBackup(t *testing.T) {
+	libraryDir := t.TempDir()
+	writeTestFile(t, filepath.Join(libraryDir, "Cookies", "Cookies.binarycookies"), "cook")
+	writeTestFile(t, filepath.Join(libraryDir, "Safari", "LocalStorage", "https_augmentcode.com_0.localstorage"), "storage")
+	writeTestFile(t, filepath.Join(libraryDir, "Safari", "LocalStorage", "leveldb", "LOCK"), "")
+	writeTestFile(t, filepath.Join(libraryDir, "Caches", "com.apple.Safari", "fsCachedData", "ABC123"), "cached")
+
+	backupDir := t.TempDir()
+	bc := &BrowserCleaner{}
+	bc.SetBackupDir(backupDir)
+
+	profile := BrowserProfile{Type: Safari, Name: "Safari - Default", ProfilePath: filepath.Join(libraryDir, "Safari")}
+	backupPath, err := bc.createSafariBackup(profile)
+	if err != nil {
+		t.Fatalf("createSafariBackup failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(backupPath, safariBackupManifestName))
+	if err != nil {
+		t.Fatalf("Expected a backup manifest: %v", err)
+	}
+	var manifest SafariBackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Invalid backup manifest: %v", err)
+	}
+
+	want := map[string]int64{
+		"Cookies/Cookies.binarycookies":                            4,
+		"Safari/LocalStorage/https_augmentcode.com_0.localstorage": 7,
+		"Caches/com.apple.Safari/fsCachedData/ABC123":              6,
+	}
+	if len(manifest.Files) != len(want) {
+		t.Fatalf("Expected %d files in manifest, got %+v", len(want), manifest.Files)
+	}
+	for _, entry := range manifest.Files {
+		if size, ok := want[entry.Path]; !ok || size != entry.Size {
+			t.Errorf("Unexpected manifest entry %+v", entry)
+		}
+		if _, err := os.Stat(filepath.Join(backupPath, filepath.FromSlash(entry.Path))); err != nil {
+			t.Errorf("Expected %s in backup: %v", entry.Path, err)
+		}
+	}
+	if manifest.TotalSize != 17 {
+		t.Errorf("Expected total size 17, got %d", manifest.TotalSize)
+	}
+}
+
+func TestCreateSafariBackupSIPProtected(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("requires file permissions that deny the current user")
+	}
+
+	libraryDir := t.TempDir()
+	cookies := filepath.Join(libraryDir, "Cookies", "Cookies.binarycookies")
+	writeTestFile(t, cookies, "cook")
+	if err := os.Chmod(cookies, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(cookies, 0644)
+
+	backupDir := t.TempDir()
+	bc := &BrowserCleaner{}
+	bc.SetBackupDir(backupDir)
+
+	profile := BrowserProfile{Type: Safari, Name: "Safari - Default", ProfilePath: filepath.Join(libraryDir, "Safari")}
+	if _, err := bc.createSafariBackup(profile); !errors.Is(err, ErrSIPProtected) {
+		t.Fatalf("Expected ErrSIPProtected, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(backupDir)
+	if len(entries) != 0 {
+		t.Error("Expected no backup to be created when access is denied")
+	}
+}
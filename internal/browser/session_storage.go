@@ -0,0 +1,166 @@
+package browser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Prefixes of the keys Chromium keeps in the Session Storage LevelDB:
+// "namespace-<namespace GUID>-<origin>" maps the session storage of an origin in
+// one tab to a map ID, whose values are stored as "map-<map ID>-<key>"
+var (
+	sessionStorageNamespacePrefix = []byte("namespace-")
+	sessionStorageMapPrefix       = []byte("map-")
+)
+
+// sessionStorageNamespaceIDLen is the length of the GUID in namespace keys
+const sessionStorageNamespaceIDLen = 36
+
+// SetPurgeAllSessionStorage makes cleaning delete every Session Storage file of
+// Chromium profiles instead of the keys of Augment origins, losing the session
+// storage of every site. It is meant for databases key deletion cannot handle.
+func (bc *BrowserCleaner) SetPurgeAllSessionStorage(purge bool) {
+	bc.purgeAllSessionStorage = purge
+}
+
+// sessionStorageNamespaceOrigin returns the origin of a Session Storage namespace
+// key; other keys return false
+func sessionStorageNamespaceOrigin(key []byte) (string, bool) {
+	if !bytes.HasPrefix(key, sessionStorageNamespacePrefix) {
+		return "", false
+	}
+	rest := key[len(sessionStorageNamespacePrefix):]
+	if len(rest) <= sessionStorageNamespaceIDLen || rest[sessionStorageNamespaceIDLen] != '-' {
+		return "", false
+	}
+	return string(rest[sessionStorageNamespaceIDLen+1:]), true
+}
+
+// augmentSessionStorageMatcher reads the namespaces of the Session Storage
+// database in dir and returns a matcher of the namespace keys of Augment origins
+// and the keys of their maps. A map also used by another origin's namespace is
+// kept.
+func (bc *BrowserCleaner) augmentSessionStorageMatcher(dir string) (func(key []byte) bool, error) {
+	db, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfMissing: true, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leveldb: %w", err)
+	}
+	defer db.Close()
+
+	augmentMaps := make(map[string]bool)
+	sharedMaps := make(map[string]bool)
+	iter := db.NewIterator(util.BytesPrefix(sessionStorageNamespacePrefix), nil)
+	for iter.Next() {
+		origin, ok := sessionStorageNamespaceOrigin(iter.Key())
+		if !ok {
+			continue
+		}
+		mapPrefix := string(sessionStorageMapPrefix) + string(iter.Value()) + "-"
+		if bc.isAugmentStorageOrigin(origin) {
+			augmentMaps[mapPrefix] = true
+		} else {
+			sharedMaps[mapPrefix] = true
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read leveldb: %w", err)
+	}
+
+	var mapPrefixes [][]byte
+	for prefix := range augmentMaps {
+		if !sharedMaps[prefix] {
+			mapPrefixes = append(mapPrefixes, []byte(prefix))
+		}
+	}
+	return func(key []byte) bool {
+		if origin, ok := sessionStorageNamespaceOrigin(key); ok {
+			return bc.isAugmentStorageOrigin(origin)
+		}
+		for _, prefix := range mapPrefixes {
+			if bytes.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// cleanChromiumSessionStorage deletes the Session Storage keys of Augment origins,
+// their namespace entries and the values of their maps, and returns the number of
+// keys deleted. With SetPurgeAllSessionStorage every file is deleted instead.
+func (bc *BrowserCleaner) cleanChromiumSessionStorage(storageDir string) (int64, error) {
+	if bc.purgeAllSessionStorage {
+		return bc.purgeChromiumSessionStorage(storageDir)
+	}
+
+	// A database still open in the browser is skipped
+	if err := cleaner.CheckLevelDBLock(storageDir); err != nil {
+		bc.logger().Warn("Skipping session storage %s: %v", storageDir, err)
+		return 0, nil
+	}
+
+	match, err := bc.augmentSessionStorageMatcher(storageDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean session storage %s: %w", storageDir, err)
+	}
+	deleted, err := bc.cleanLevelDBKeys(storageDir, match)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to clean session storage %s: %w", storageDir, err)
+	}
+	return deleted, nil
+}
+
+// purgeChromiumSessionStorage deletes every file of the Session Storage database
+// and returns the number of files deleted
+func (bc *BrowserCleaner) purgeChromiumSessionStorage(storageDir string) (int64, error) {
+	// Release the LevelDB lock first; a database still open elsewhere is skipped
+	if err := cleaner.ReleaseLevelDBLock(storageDir); err != nil {
+		bc.logger().Warn("Skipping session storage %s: %v", storageDir, err)
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(storageDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session storage %s: %w", storageDir, err)
+	}
+	bc.logger().Warn("Purging all session storage in %s, including that of other sites", storageDir)
+	var deleted int64
+	for _, entry := range entries {
+		if !entry.IsDir() && bc.removeFile(filepath.Join(storageDir, entry.Name())) {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// countChromiumSessionStorage counts the Session Storage keys cleaning would
+// delete and the size of their keys and values, or the files with
+// SetPurgeAllSessionStorage
+func (bc *BrowserCleaner) countChromiumSessionStorage(storageDir string) (keys, size int64) {
+	if bc.purgeAllSessionStorage {
+		entries, _ := os.ReadDir(storageDir)
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && !entry.IsDir() {
+				keys++
+				size += info.Size()
+			}
+		}
+		return keys, size
+	}
+
+	match, err := bc.augmentSessionStorageMatcher(storageDir)
+	if err != nil {
+		return 0, 0
+	}
+	keys, size, _ = countLevelDBKeys(storageDir, match)
+	return keys, size
+}
@@ -0,0 +1,105 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	testNamespaceA = "0a1b2c3d-0000-4000-8000-000000000001"
+	testNamespaceB = "0a1b2c3d-0000-4000-8000-000000000002"
+)
+
+// sessionStorageEntries returns a Session Storage database with two tabs: both
+// have app.augmentcode.com (map 1 in the first tab, its clone map 3 in the
+// second) and the first also github.com (map 2)
+func sessionStorageEntries() map[string]string {
+	return map[string]string{
+		"version":     "1",
+		"next-map-id": "4",
+		"namespace-" + testNamespaceA + "-https://app.augmentcode.com/": "1",
+		"namespace-" + testNamespaceA + "-https://github.com/":          "2",
+		"namespace-" + testNamespaceB + "-https://app.augmentcode.com/": "3",
+		"map-1-authState":       "secret",
+		"map-1-sessionId":       "abc",
+		"map-2-augment_sidebar": "open", // Mentions Augment but belongs to another site
+		"map-3-authState":       "secret",
+		"map-10-draft":          "kept", // Map 10 is not map 1
+	}
+}
+
+func TestCleanChromiumSessionStorageDeletesAugmentOrigins(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), "Session Storage")
+	createLocalStorage(t, storageDir, sessionStorageEntries())
+
+	bc := &BrowserCleaner{}
+	if keys, _ := bc.countChromiumSessionStorage(storageDir); keys != 5 {
+		t.Errorf("Expected 5 Augment keys counted, got %d", keys)
+	}
+
+	deleted, err := bc.cleanChromiumSessionStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumSessionStorage failed: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("Expected 5 keys deleted, got %d", deleted)
+	}
+
+	db, err := leveldb.OpenFile(storageDir, nil)
+	if err != nil {
+		t.Fatalf("Expected the cleaned database to open: %v", err)
+	}
+	defer db.Close()
+	kept := []string{"version", "next-map-id", "namespace-" + testNamespaceA + "-https://github.com/", "map-2-augment_sidebar", "map-10-draft"}
+	for _, key := range kept {
+		if ok, _ := db.Has([]byte(key), nil); !ok {
+			t.Errorf("Expected %q to be kept", key)
+		}
+	}
+	for _, key := range []string{"map-1-authState", "map-3-authState", "namespace-" + testNamespaceB + "-https://app.augmentcode.com/"} {
+		if ok, _ := db.Has([]byte(key), nil); ok {
+			t.Errorf("Expected %q to be deleted", key)
+		}
+	}
+}
+
+func TestAugmentSessionStorageMatcherKeepsSharedMaps(t *testing.T) {
+	storageDir := t.TempDir()
+	createLocalStorage(t, storageDir, map[string]string{
+		"namespace-" + testNamespaceA + "-https://app.augmentcode.com/": "1",
+		"namespace-" + testNamespaceB + "-https://github.com/":          "1",
+		"map-1-value": "shared",
+	})
+
+	match, err := (&BrowserCleaner{}).augmentSessionStorageMatcher(storageDir)
+	if err != nil {
+		t.Fatalf("augmentSessionStorageMatcher failed: %v", err)
+	}
+	if match([]byte("map-1-value")) {
+		t.Error("Expected a map used by another origin to be kept")
+	}
+	if !match([]byte("namespace-" + testNamespaceA + "-https://app.augmentcode.com/")) {
+		t.Error("Expected the Augment namespace key to match")
+	}
+}
+
+func TestCleanChromiumSessionStoragePurgeAll(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), "Session Storage")
+	createLocalStorage(t, storageDir, sessionStorageEntries())
+
+	bc := &BrowserCleaner{removal: &removalTracker{}}
+	bc.SetPurgeAllSessionStorage(true)
+	deleted, err := bc.cleanChromiumSessionStorage(storageDir)
+	if err != nil {
+		t.Fatalf("cleanChromiumSessionStorage failed: %v", err)
+	}
+	if deleted == 0 {
+		t.Error("Expected the session storage files to be deleted")
+	}
+	if entries, _ := os.ReadDir(storageDir); len(entries) != 0 {
+		t.Errorf("Expected no files left, got %d", len(entries))
+	}
+}
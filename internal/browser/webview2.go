@@ -0,0 +1,146 @@
+package browser
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// webView2ScanDepth limits how deep below AppData\Local the scan for EBWebView
+// folders descends
+const webView2ScanDepth = 6
+
+// webView2KnownDataDirs maps the user data folders, relative to AppData\Local, of
+// well-known WebView2 host apps to the app's name
+var webView2KnownDataDirs = map[string]string{
+	filepath.Join("Microsoft", "Edge WebView2"):                                                           "Edge WebView2",
+	filepath.Join("Packages", "MSTeams_8wekyb3d8bbwe", "LocalCache", "Microsoft", "MSTeams", "EBWebView"): "Microsoft Teams",
+	filepath.Join("Microsoft", "Teams", "EBWebView"):                                                      "Microsoft Teams (classic)",
+	filepath.Join("Microsoft", "Olk", "EBWebView"):                                                        "Outlook",
+	filepath.Join("Microsoft", "OneDrive", "EBWebView"):                                                   "OneDrive",
+}
+
+// webView2GenericDirs are path components that do not name the host app of an
+// EBWebView folder
+var webView2GenericDirs = map[string]bool{
+	"ebwebview":  true,
+	"localcache": true,
+	"localstate": true,
+	"roaming":    true,
+	"local":      true,
+	"packages":   true,
+	"user data":  true,
+	"microsoft":  true,
+}
+
+// detectWebView2Profiles detects the user data folders of WebView2 host apps.
+// WebView2 only exists on Windows.
+func (bd *BrowserDetector) detectWebView2Profiles() ([]BrowserProfile, error) {
+	if runtime.GOOS != "windows" {
+		return nil, nil
+	}
+	return webView2Profiles(filepath.Join(bd.homeDir, "AppData", "Local")), nil
+}
+
+// webView2Profiles returns the profiles of every WebView2 user data folder below
+// localAppData, named "WebView2 (<host app>) - <profile>"
+func webView2Profiles(localAppData string) []BrowserProfile {
+	var profiles []BrowserProfile
+	for _, dir := range webView2DataDirs(localAppData) {
+		hostApp := webView2HostApp(localAppData, dir)
+		for _, profile := range chromiumUserDataProfiles(WebView2, dir, "WebView2 ("+hostApp+")") {
+			profile.HostApp = hostApp
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// webView2DataDirs returns the well-known WebView2 user data folders below
+// localAppData and every EBWebView folder with a Default profile found by
+// scanning it, sorted and without duplicates
+func webView2DataDirs(localAppData string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		key := strings.ToLower(filepath.Clean(dir))
+		if !seen[key] {
+			seen[key] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for rel := range webView2KnownDataDirs {
+		dir := filepath.Join(localAppData, rel)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			add(dir)
+		}
+	}
+
+	baseDepth := strings.Count(filepath.Clean(localAppData), string(filepath.Separator))
+	filepath.WalkDir(localAppData, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != localAppData {
+				return filepath.SkipDir
+			}
+			return nil // Skip what cannot be read
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != localAppData && strings.EqualFold(d.Name(), "Temp") {
+			return filepath.SkipDir
+		}
+		if strings.EqualFold(d.Name(), "EBWebView") {
+			if _, err := os.Stat(filepath.Join(path, "Default")); err == nil {
+				add(path)
+			}
+			return filepath.SkipDir
+		}
+		if strings.Count(path, string(filepath.Separator))-baseDepth >= webView2ScanDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	sort.Strings(dirs)
+	return dirs
+}
+
+// webView2HostApp names the app owning a WebView2 user data folder: the known
+// app name, or else the nearest directory above it that is not a generic one,
+// without the .exe.WebView2 suffix or the publisher ID of Store package names
+func webView2HostApp(localAppData, dataDir string) string {
+	rel, err := filepath.Rel(localAppData, dataDir)
+	if err != nil {
+		return "Unknown"
+	}
+	for known, name := range webView2KnownDataDirs {
+		if strings.EqualFold(rel, known) {
+			return name
+		}
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i := len(parts) - 1; i >= 0; i-- {
+		name := parts[i]
+		if name == "" || name == "." || webView2GenericDirs[strings.ToLower(name)] {
+			continue
+		}
+		// Apps without a configured folder get <exe>.exe.WebView2 next to the executable
+		if strings.HasSuffix(strings.ToLower(name), ".exe.webview2") {
+			name = name[:len(name)-len(".exe.WebView2")]
+		}
+		// Store packages are named <name>_<13 character publisher ID>
+		if i > 0 && strings.EqualFold(parts[i-1], "Packages") {
+			if sep := strings.LastIndex(name, "_"); sep > 0 && len(name)-sep-1 == 13 {
+				name = name[:sep]
+			}
+		}
+		return name
+	}
+	return "Unknown"
+}
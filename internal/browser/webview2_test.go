@@ -0,0 +1,84 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebView2Profiles(t *testing.T) {
+	localAppData := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join("Packages", "MSTeams_8wekyb3d8bbwe", "LocalCache", "Microsoft", "MSTeams", "EBWebView", "Default"),
+		filepath.Join("Packages", "Contoso.Notes_1a2b3c4d5e6f7", "LocalState", "EBWebView", "Default"),
+		filepath.Join("Programs", "Widget", "Widget.exe.WebView2", "EBWebView", "Default"),
+		filepath.Join("Programs", "Widget", "Widget.exe.WebView2", "EBWebView", "Profile 1"),
+		filepath.Join("Temp", "Installer", "EBWebView", "Default"),
+		filepath.Join("Empty", "EBWebView"),
+	} {
+		if err := os.MkdirAll(filepath.Join(localAppData, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	names := make(map[string]BrowserProfile)
+	for _, profile := range webView2Profiles(localAppData) {
+		if profile.Type != WebView2 {
+			t.Errorf("Expected WebView2 profile, got %s for %s", profile.Type.String(), profile.Name)
+		}
+		names[profile.Name] = profile
+	}
+
+	expected := map[string]string{
+		"WebView2 (Microsoft Teams) - Default": "Microsoft Teams",
+		"WebView2 (Contoso.Notes) - Default":   "Contoso.Notes",
+		"WebView2 (Widget) - Default":          "Widget",
+		"WebView2 (Widget) - Profile 1":        "Widget",
+	}
+	if len(names) != len(expected) {
+		t.Errorf("Expected %d profiles, got %v", len(expected), names)
+	}
+	for name, hostApp := range expected {
+		profile, found := names[name]
+		if !found {
+			t.Errorf("Expected profile %q", name)
+			continue
+		}
+		if profile.HostApp != hostApp {
+			t.Errorf("Expected host app %q for %q, got %q", hostApp, name, profile.HostApp)
+		}
+	}
+	if !names["WebView2 (Widget) - Default"].IsDefault || names["WebView2 (Widget) - Profile 1"].IsDefault {
+		t.Error("Expected only the Default profile to be the default")
+	}
+}
+
+func TestCleanChromiumBrowserNetworkCookies(t *testing.T) {
+	profileDir := t.TempDir()
+	cookiesDB := filepath.Join(profileDir, "Network", "Cookies")
+	if err := os.MkdirAll(filepath.Dir(cookiesDB), 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestDB(t, cookiesDB,
+		`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT)`,
+		`INSERT INTO cookies VALUES ('.augmentcode.com', 'session', 'x'), ('login.microsoftonline.com', 'augment_session', 'y'), ('github.com', 'user_session', 'z')`,
+	)
+
+	bc := &BrowserCleaner{}
+	profile := BrowserProfile{Type: WebView2, Name: "WebView2 (Widget) - Default", ProfilePath: profileDir}
+	if count := bc.countAugmentData(profile); count.Cookies != 2 {
+		t.Errorf("Expected 2 Augment cookies counted, got %d", count.Cookies)
+	}
+
+	var result BrowserCleanResult
+	bc.cleanChromiumBrowser(profile, &result)
+	if len(result.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", result.Errors)
+	}
+	if result.CookiesDeleted != 2 {
+		t.Errorf("Expected 2 cookies deleted, got %d", result.CookiesDeleted)
+	}
+	if remaining := countRows(t, cookiesDB, "cookies"); remaining != 1 {
+		t.Errorf("Expected 1 cookie left, got %d", remaining)
+	}
+}
@@ -0,0 +1,234 @@
+package cleaner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// AugmentExtensionID is the VS Code extension ID of Augment
+const AugmentExtensionID = "augment.vscode-augment"
+
+// DefaultPreservedStorageKeys are the global storage keys CleanExtensionGlobalStorage
+// keeps even when they look like telemetry, so cleaning does not sign the user out
+// or reset their preferences
+var DefaultPreservedStorageKeys = []string{"userPreferences", "licenseKey"}
+
+// ExtensionStorageCleanOptions controls CleanExtensionGlobalStorage
+type ExtensionStorageCleanOptions struct {
+	// ExtensionID is the extension whose global storage is cleaned; empty uses AugmentExtensionID
+	ExtensionID string
+	// PreserveKeys are top-level JSON keys that are never removed, compared
+	// case-insensitively; nil uses DefaultPreservedStorageKeys
+	PreserveKeys []string
+	// Backup backs up the extension's global storage before it is changed
+	Backup bool
+	// DryRun lists the keys and files that would be removed without changing them
+	DryRun bool
+	// Resolver locates the global storage; nil uses the current user's
+	Resolver utils.PathResolver
+}
+
+// RemovedStorageKey is a top-level key removed from a JSON file in extension storage
+type RemovedStorageKey struct {
+	File string                `json:"file"` // Relative to the extension's storage directory
+	Key  string                `json:"key"`
+	Risk scanner.TelemetryRisk `json:"risk"`
+}
+
+// ExtensionStorageCleanResult is the result of cleaning one extension's global storage
+type ExtensionStorageCleanResult struct {
+	ExtensionID   string              `json:"extension_id"`
+	StoragePath   string              `json:"storage_path"`
+	BackupPath    string              `json:"backup_path,omitempty"`
+	KeysRemoved   []RemovedStorageKey `json:"keys_removed"`
+	KeysPreserved []string            `json:"keys_preserved,omitempty"` // Telemetry keys kept by PreserveKeys, as file: key
+	FilesPatched  []string            `json:"files_patched"`
+	FilesDeleted  []string            `json:"files_deleted"` // JSON files that held nothing but telemetry
+	Errors        []string            `json:"errors,omitempty"`
+	DryRun        bool                `json:"dry_run"`
+	Duration      time.Duration       `json:"duration"`
+}
+
+// storageFilePlan is what CleanExtensionGlobalStorage does to one JSON file
+type storageFilePlan struct {
+	path     string
+	relative string
+	keys     []RemovedStorageKey
+	remove   bool // Every key is telemetry, so the whole file goes
+}
+
+// CleanAugmentExtensionGlobalStorage removes telemetry from the global storage of
+// extensionID, the Augment extension when empty, keeping DefaultPreservedStorageKeys
+func CleanAugmentExtensionGlobalStorage(extensionID string, createBackup bool) (*ExtensionStorageCleanResult, error) {
+	return NewBackupManager().CleanExtensionGlobalStorage(ExtensionStorageCleanOptions{
+		ExtensionID: extensionID,
+		Backup:      createBackup,
+	})
+}
+
+// CleanExtensionGlobalStorage removes telemetry from the JSON files in
+// <globalStorage>/<extensionID>/ without the scanner pipeline. Top-level keys a
+// storage scan rates at medium risk or above are removed with PatchJSONFile,
+// unless opts.PreserveKeys lists them; files whose keys are all removed are
+// deleted. Other files are left alone. With opts.Backup the directory is backed
+// up as an extension backup first.
+func (bm *BackupManager) CleanExtensionGlobalStorage(opts ExtensionStorageCleanOptions) (*ExtensionStorageCleanResult, error) {
+	startTime := time.Now()
+
+	extensionID := opts.ExtensionID
+	if extensionID == "" {
+		extensionID = AugmentExtensionID
+	}
+	preserveKeys := opts.PreserveKeys
+	if preserveKeys == nil {
+		preserveKeys = DefaultPreservedStorageKeys
+	}
+
+	storagePath, err := utils.NewVSCodePaths(opts.Resolver).ExtensionGlobalStoragePath(extensionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global storage path: %w", err)
+	}
+	if info, err := bm.fs.Stat(storagePath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("no global storage found for extension %s at %s", extensionID, storagePath)
+	}
+
+	result := &ExtensionStorageCleanResult{
+		ExtensionID:  extensionID,
+		StoragePath:  storagePath,
+		KeysRemoved:  make([]RemovedStorageKey, 0),
+		FilesPatched: make([]string, 0),
+		FilesDeleted: make([]string, 0),
+		DryRun:       opts.DryRun,
+	}
+
+	plans, err := bm.planStorageCleaning(storagePath, preserveKeys, result)
+	if err != nil {
+		return nil, err
+	}
+	for _, plan := range plans {
+		result.KeysRemoved = append(result.KeysRemoved, plan.keys...)
+	}
+
+	if opts.DryRun || len(plans) == 0 {
+		for _, plan := range plans {
+			if plan.remove {
+				result.FilesDeleted = append(result.FilesDeleted, plan.relative)
+			} else {
+				result.FilesPatched = append(result.FilesPatched, plan.relative)
+			}
+		}
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if opts.Backup {
+		storage := scanner.ExtensionStorage{ExtensionID: extensionID, StoragePath: storagePath}
+		backupName := fmt.Sprintf("%s-global-storage-%d", strings.ReplaceAll(extensionID, ".", "-"), bm.now().Unix())
+		if result.BackupPath, err = bm.CreateExtensionBackup(storage, backupName); err != nil {
+			return nil, fmt.Errorf("failed to back up global storage: %w", err)
+		}
+	}
+
+	for _, plan := range plans {
+		if plan.remove {
+			if err := bm.fs.Remove(plan.path); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("Failed to delete %s: %v", plan.relative, err))
+				continue
+			}
+			result.FilesDeleted = append(result.FilesDeleted, plan.relative)
+			continue
+		}
+
+		keys := make([]string, len(plan.keys))
+		for i, key := range plan.keys {
+			keys[i] = key.Key
+		}
+		if _, err := PatchJSONFile(bm.fs, plan.path, keys); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to patch %s: %v", plan.relative, err))
+			continue
+		}
+		result.FilesPatched = append(result.FilesPatched, plan.relative)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// planStorageCleaning rates the top-level keys of every JSON file below
+// storagePath and returns the files to patch or delete, in path order. Preserved
+// telemetry keys and unreadable files are recorded in result.
+func (bm *BackupManager) planStorageCleaning(storagePath string, preserveKeys []string, result *ExtensionStorageCleanResult) ([]storageFilePlan, error) {
+	analyzer := scanner.NewStorageAnalyzer()
+
+	var plans []storageFilePlan
+	err := bm.fs.Walk(storagePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+		relative, err := filepath.Rel(storagePath, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		data, err := bm.fs.ReadFile(path)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to read %s: %v", relative, err))
+			return nil
+		}
+		var document map[string]interface{}
+		if err := parseJSONC(data, &document); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Skipped %s: not a JSON object", relative))
+			return nil
+		}
+
+		plan := storageFilePlan{path: path, relative: relative}
+		keys := make([]string, 0, len(document))
+		for key := range document {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			risk := analyzer.AssessKeyRisk(key, document[key])
+			if risk < scanner.TelemetryRiskMedium {
+				continue
+			}
+			if containsFold(preserveKeys, key) {
+				result.KeysPreserved = append(result.KeysPreserved, relative+": "+key)
+				continue
+			}
+			plan.keys = append(plan.keys, RemovedStorageKey{File: relative, Key: key, Risk: risk})
+		}
+		if len(plan.keys) == 0 {
+			return nil
+		}
+		plan.remove = len(plan.keys) == len(keys)
+		plans = append(plans, plan)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global storage: %w", err)
+	}
+	return plans, nil
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+	"augment-telemetry-cleaner/internal/vfs"
+)
+
+const augmentGlobalState = `{
+    "machineId": "3f2b9c1e-8d4a-4b7e-9a61-2c5d8e7f1a90",
+    "licenseKey": "4e1c2a7b-9f3d-4c8e-a5b6-7d8e9f0a1b2c",
+    "theme": "dark",
+    "sessionId": "c0ffee00-1234-4abc-8def-567890abcdef"
+}`
+
+func TestCleanExtensionGlobalStorage(t *testing.T) {
+	resolver := utils.FakePathResolver{Home: filepath.FromSlash("/home/user"), OS: "linux"}
+	storagePath, err := utils.NewVSCodePaths(resolver).ExtensionGlobalStoragePath(AugmentExtensionID)
+	if err != nil {
+		t.Fatalf("ExtensionGlobalStoragePath() failed: %v", err)
+	}
+
+	memFS := vfs.NewMemFS()
+	if err := memFS.MkdirAll(filepath.Join(storagePath, "cache"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	files := map[string]string{
+		"state.json":             augmentGlobalState,
+		"cache/telemetry.json":   `{"telemetryData": {"events": 12}, "deviceId": "device"}`,
+		"cache/completions.json": `{"recent": ["fmt.Println"]}`,
+		"notes.txt":              "machineId=device",
+	}
+	for name, content := range files {
+		if err := memFS.WriteFile(filepath.Join(storagePath, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	manager := NewBackupManager()
+	manager.SetFileSystem(memFS)
+	manager.backupDirectory = filepath.FromSlash("/backups/extensions")
+	opts := ExtensionStorageCleanOptions{Backup: true, DryRun: true, Resolver: resolver}
+
+	dryRun, err := manager.CleanExtensionGlobalStorage(opts)
+	if err != nil {
+		t.Fatalf("CleanExtensionGlobalStorage() dry run failed: %v", err)
+	}
+	if data, _ := memFS.ReadFile(filepath.Join(storagePath, "state.json")); string(data) != augmentGlobalState {
+		t.Errorf("Expected a dry run to leave state.json unchanged, got %s", data)
+	}
+
+	opts.DryRun = false
+	result, err := manager.CleanExtensionGlobalStorage(opts)
+	if err != nil {
+		t.Fatalf("CleanExtensionGlobalStorage() failed: %v", err)
+	}
+	if !reflect.DeepEqual(dryRun.KeysRemoved, result.KeysRemoved) || !reflect.DeepEqual(dryRun.FilesDeleted, result.FilesDeleted) {
+		t.Errorf("Expected the dry run to match the clean, got %+v and %+v", dryRun, result)
+	}
+
+	var removed []string
+	for _, key := range result.KeysRemoved {
+		removed = append(removed, key.File+": "+key.Key)
+	}
+	wantRemoved := []string{"cache/telemetry.json: deviceId", "cache/telemetry.json: telemetryData", "state.json: machineId", "state.json: sessionId"}
+	if !reflect.DeepEqual(removed, wantRemoved) {
+		t.Errorf("Expected removed keys %v, got %v", wantRemoved, removed)
+	}
+	if want := []string{"state.json: licenseKey"}; !reflect.DeepEqual(result.KeysPreserved, want) {
+		t.Errorf("Expected preserved keys %v, got %v", want, result.KeysPreserved)
+	}
+	if !reflect.DeepEqual(result.FilesDeleted, []string{"cache/telemetry.json"}) || !reflect.DeepEqual(result.FilesPatched, []string{"state.json"}) {
+		t.Errorf("Expected telemetry.json deleted and state.json patched, got %+v", result)
+	}
+	if result.BackupPath == "" {
+		t.Error("Expected a backup of the global storage")
+	}
+
+	wantState := `{
+    "licenseKey": "4e1c2a7b-9f3d-4c8e-a5b6-7d8e9f0a1b2c",
+    "theme": "dark"
+}`
+	if data, _ := memFS.ReadFile(filepath.Join(storagePath, "state.json")); string(data) != wantState {
+		t.Errorf("Expected state.json to be\n%s\ngot\n%s", wantState, data)
+	}
+	if _, err := memFS.Stat(filepath.Join(storagePath, "cache", "telemetry.json")); err == nil {
+		t.Error("Expected the telemetry-only file to be deleted")
+	}
+	for _, name := range []string{"cache/completions.json", "notes.txt"} {
+		if data, _ := memFS.ReadFile(filepath.Join(storagePath, filepath.FromSlash(name))); string(data) != files[name] {
+			t.Errorf("Expected %s to be left alone, got %s", name, data)
+		}
+	}
+
+	opts.ExtensionID = "missing.extension"
+	if _, err := manager.CleanExtensionGlobalStorage(opts); err == nil {
+		t.Error("Expected an error for an extension without global storage")
+	}
+}
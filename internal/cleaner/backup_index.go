@@ -0,0 +1,271 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupIndexFile is the index of the backup directory, kept next to the backups
+const backupIndexFile = "index.json"
+
+// backupIndexVersion is bumped when backupIndexEntry changes, so older indexes
+// are rebuilt instead of read
+const backupIndexVersion = 1
+
+// BackupSort is the order ListBackupsPage returns backups in
+type BackupSort string
+
+const (
+	// BackupSortDate lists the newest backups first
+	BackupSortDate BackupSort = "date"
+	// BackupSortSize lists the largest backups first
+	BackupSortSize BackupSort = "size"
+)
+
+// backupIndexEntry is what the backup index records of one backup: enough to
+// sort and page backups without loading their metadata
+type backupIndexEntry struct {
+	BackupID     string    `json:"backup_id"`
+	ExtensionID  string    `json:"extension_id"`
+	BackupType   string    `json:"backup_type"`
+	CreationTime time.Time `json:"creation_time"`
+	TotalSize    int64     `json:"total_size"`
+	MetadataPath string    `json:"metadata_path"`
+}
+
+// backupIndex is the content of backupIndexFile
+type backupIndex struct {
+	Version int                `json:"version"`
+	Entries []backupIndexEntry `json:"entries"`
+}
+
+// BackupPage is one page of backups returned by ListBackupsPage
+type BackupPage struct {
+	Backups []BackupMetadata `json:"backups"`
+	Total   int              `json:"total"` // Backups on all pages
+	Offset  int              `json:"offset"`
+	SortBy  BackupSort       `json:"sort_by"`
+}
+
+// ListBackupsPage returns up to limit backups in sortBy order, skipping the first
+// offset; a limit of zero or less returns all of them. Unlike ListBackups it only
+// loads the metadata of the backups on the page: the order comes from the backup
+// index, which is rebuilt when it is missing, unreadable, out of date with the
+// metadata files on disk or refers to a backup that no longer exists.
+func (bm *BackupManager) ListBackupsPage(offset, limit int, sortBy BackupSort) (*BackupPage, error) {
+	if sortBy == "" {
+		sortBy = BackupSortDate
+	}
+	if sortBy != BackupSortDate && sortBy != BackupSortSize {
+		return nil, fmt.Errorf("unknown backup sort order %q (use date or size)", sortBy)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	page := &BackupPage{Backups: make([]BackupMetadata, 0), Offset: offset, SortBy: sortBy}
+	if _, err := bm.fs.Stat(bm.backupDirectory); os.IsNotExist(err) {
+		return page, nil // No backups directory
+	}
+
+	index, err := bm.loadBackupIndex()
+	if err != nil || bm.backupIndexStale(index) {
+		if index, err = bm.rebuildBackupIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	backups, ok := bm.loadBackupPage(index, offset, limit, sortBy)
+	if !ok {
+		// A backup was removed without updating the index
+		if index, err = bm.rebuildBackupIndex(); err != nil {
+			return nil, err
+		}
+		backups, _ = bm.loadBackupPage(index, offset, limit, sortBy)
+	}
+
+	page.Backups = append(page.Backups, backups...)
+	page.Total = len(index.Entries)
+	return page, nil
+}
+
+// loadBackupPage sorts the index entries and loads the metadata of the requested
+// page. It reports false when a metadata file of the page cannot be loaded.
+func (bm *BackupManager) loadBackupPage(index *backupIndex, offset, limit int, sortBy BackupSort) ([]BackupMetadata, bool) {
+	entries := append([]backupIndexEntry(nil), index.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if sortBy == BackupSortSize && entries[i].TotalSize != entries[j].TotalSize {
+			return entries[i].TotalSize > entries[j].TotalSize
+		}
+		return entries[i].CreationTime.After(entries[j].CreationTime)
+	})
+
+	if offset >= len(entries) {
+		return nil, true
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	backups := make([]BackupMetadata, 0, len(entries))
+	for _, entry := range entries {
+		metadata, err := bm.loadBackupMetadata(entry.MetadataPath)
+		if err != nil {
+			return nil, false
+		}
+		backups = append(backups, *metadata)
+	}
+	return backups, true
+}
+
+// backupIndexStale reports whether backups were added, removed or rewritten
+// behind the index's back: the backup directory holds a different number of
+// metadata files than the index has entries, or one of them is newer than the
+// index. Only the files are stat'ed; their metadata is not loaded.
+func (bm *BackupManager) backupIndexStale(index *backupIndex) bool {
+	indexInfo, err := bm.fs.Stat(filepath.Join(bm.backupDirectory, backupIndexFile))
+	if err != nil {
+		return true
+	}
+
+	count := 0
+	stale := false
+	err = bm.fs.Walk(bm.backupDirectory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Continue despite errors, as rebuildBackupIndex does
+		}
+		if !strings.HasSuffix(info.Name(), ".metadata.json") {
+			return nil
+		}
+		count++
+		if info.ModTime().After(indexInfo.ModTime()) {
+			stale = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return err != nil || stale || count != len(index.Entries)
+}
+
+// rebuildBackupIndex walks the backup directory and rewrites the backup index
+// from the metadata files found
+func (bm *BackupManager) rebuildBackupIndex() (*backupIndex, error) {
+	unlock, err := bm.fs.Lock(bm.backupDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock backup directory: %w", err)
+	}
+	defer unlock()
+
+	index := &backupIndex{Version: backupIndexVersion, Entries: make([]backupIndexEntry, 0)}
+	err = bm.fs.Walk(bm.backupDirectory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Continue despite errors
+		}
+		if !strings.HasSuffix(info.Name(), ".metadata.json") {
+			return nil
+		}
+		metadata, err := bm.loadBackupMetadata(path)
+		if err != nil {
+			return nil // Skip invalid metadata files
+		}
+		index.Entries = append(index.Entries, newBackupIndexEntry(*metadata, path))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	if err := bm.saveBackupIndex(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// addToBackupIndex records a new backup in the backup index. The caller holds
+// the backup directory lock. Without a readable index nothing is written; the
+// next ListBackupsPage rebuilds it.
+func (bm *BackupManager) addToBackupIndex(metadata BackupMetadata, metadataPath string) error {
+	index, err := bm.loadBackupIndex()
+	if err != nil {
+		return nil
+	}
+
+	entries := index.Entries[:0]
+	for _, entry := range index.Entries {
+		if entry.MetadataPath != metadataPath {
+			entries = append(entries, entry) // A backup with the same name is replaced
+		}
+	}
+	index.Entries = append(entries, newBackupIndexEntry(metadata, metadataPath))
+	return bm.saveBackupIndex(index)
+}
+
+// removeFromBackupIndex drops a removed backup from the backup index
+func (bm *BackupManager) removeFromBackupIndex(metadataPath string) error {
+	unlock, err := bm.fs.Lock(bm.backupDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to lock backup directory: %w", err)
+	}
+	defer unlock()
+
+	index, err := bm.loadBackupIndex()
+	if err != nil {
+		return nil
+	}
+
+	entries := index.Entries[:0]
+	for _, entry := range index.Entries {
+		if entry.MetadataPath != metadataPath {
+			entries = append(entries, entry)
+		}
+	}
+	index.Entries = entries
+	return bm.saveBackupIndex(index)
+}
+
+// loadBackupIndex reads the backup index; an index of another version is an error
+func (bm *BackupManager) loadBackupIndex() (*backupIndex, error) {
+	data, err := bm.fs.ReadFile(filepath.Join(bm.backupDirectory, backupIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup index: %w", err)
+	}
+
+	var index backupIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backup index: %w", err)
+	}
+	if index.Version != backupIndexVersion {
+		return nil, fmt.Errorf("backup index version %d is not %d", index.Version, backupIndexVersion)
+	}
+	return &index, nil
+}
+
+// saveBackupIndex writes the backup index
+func (bm *BackupManager) saveBackupIndex(index *backupIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup index: %w", err)
+	}
+	if err := bm.fs.WriteFile(filepath.Join(bm.backupDirectory, backupIndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup index: %w", err)
+	}
+	return nil
+}
+
+// newBackupIndexEntry returns the index entry of a backup
+func newBackupIndexEntry(metadata BackupMetadata, metadataPath string) backupIndexEntry {
+	return backupIndexEntry{
+		BackupID:     metadata.BackupID,
+		ExtensionID:  metadata.ExtensionID,
+		BackupType:   metadata.BackupType,
+		CreationTime: metadata.CreationTime,
+		TotalSize:    metadata.TotalSize,
+		MetadataPath: metadataPath,
+	}
+}
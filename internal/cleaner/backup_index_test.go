@@ -0,0 +1,141 @@
+package cleaner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListBackupsPage(t *testing.T) {
+	manager, memFS, storage, now := newMemBackupManager(t)
+
+	// Each backup is a minute newer and 100 bytes larger than the previous one,
+	// except the last, which is the smallest
+	padding := filepath.Join(storage.StoragePath, "padding.txt")
+	create := func(name string, paddingSize int) {
+		t.Helper()
+		if err := memFS.WriteFile(padding, []byte(strings.Repeat("x", paddingSize)), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if _, err := manager.CreateExtensionBackup(storage, name); err != nil {
+			t.Fatalf("CreateExtensionBackup(%s) failed: %v", name, err)
+		}
+		*now = now.Add(time.Minute)
+	}
+	create("first", 100)
+	create("second", 200)
+	create("third", 300)
+
+	ids := func(page *BackupPage) string {
+		var names []string
+		for _, backup := range page.Backups {
+			names = append(names, strings.TrimSuffix(filepath.Base(backup.BackupPath), ".zip"))
+		}
+		return strings.Join(names, ",")
+	}
+	list := func(offset, limit int, sortBy BackupSort) *BackupPage {
+		t.Helper()
+		page, err := manager.ListBackupsPage(offset, limit, sortBy)
+		if err != nil {
+			t.Fatalf("ListBackupsPage(%d, %d, %s) failed: %v", offset, limit, sortBy, err)
+		}
+		return page
+	}
+
+	// The first listing builds the missing index
+	if page := list(0, 2, BackupSortDate); ids(page) != "third,second" || page.Total != 3 {
+		t.Errorf("Expected the two newest of 3 backups, got %s of %d", ids(page), page.Total)
+	}
+	if _, err := memFS.Stat(filepath.Join(manager.backupDirectory, backupIndexFile)); err != nil {
+		t.Fatalf("Expected the backup index to be written: %v", err)
+	}
+
+	// Later backups are added to the index as they are created
+	create("fourth", 0)
+	index, err := manager.loadBackupIndex()
+	if err != nil || len(index.Entries) != 4 {
+		t.Fatalf("Expected 4 indexed backups, got %v (%v)", index, err)
+	}
+	if page := list(1, 2, BackupSortDate); ids(page) != "third,second" || page.Total != 4 {
+		t.Errorf("Expected the second page of 4 backups, got %s of %d", ids(page), page.Total)
+	}
+	if page := list(0, 0, BackupSortSize); ids(page) != "third,second,first,fourth" {
+		t.Errorf("Expected backups largest first, got %s", ids(page))
+	}
+	if page := list(10, 2, BackupSortDate); len(page.Backups) != 0 || page.Total != 4 {
+		t.Errorf("Expected an empty page past the last backup, got %s of %d", ids(page), page.Total)
+	}
+
+	// Removed backups leave the index
+	if err := manager.removeBackup(list(0, 1, BackupSortSize).Backups[0]); err != nil {
+		t.Fatalf("removeBackup() failed: %v", err)
+	}
+	if page := list(0, 0, BackupSortDate); ids(page) != "fourth,second,first" {
+		t.Errorf("Expected the removed backup to be gone, got %s", ids(page))
+	}
+
+	// A backup deleted behind the index's back makes it rebuild
+	if err := memFS.Remove(filepath.Join(manager.backupDirectory, "fourth.metadata.json")); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if page := list(0, 1, BackupSortDate); ids(page) != "second" || page.Total != 2 {
+		t.Errorf("Expected the stale index to be rebuilt, got %s of %d", ids(page), page.Total)
+	}
+
+	if _, err := manager.ListBackupsPage(0, 1, "name"); err == nil {
+		t.Error("Expected an error for an unknown sort order")
+	}
+}
+
+func TestListBackupsPageRebuildsCorruptIndex(t *testing.T) {
+	manager, memFS, storage, _ := newMemBackupManager(t)
+	if _, err := manager.CreateExtensionBackup(storage, "only"); err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+
+	for _, content := range []string{"not json", fmt.Sprintf(`{"version": %d, "entries": []}`, backupIndexVersion+1)} {
+		if err := memFS.WriteFile(filepath.Join(manager.backupDirectory, backupIndexFile), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		page, err := manager.ListBackupsPage(0, 0, BackupSortDate)
+		if err != nil {
+			t.Fatalf("ListBackupsPage() failed: %v", err)
+		}
+		if page.Total != 1 || len(page.Backups) != 1 {
+			t.Errorf("Expected the index %q to be rebuilt with 1 backup, got %+v", content, page)
+		}
+	}
+}
+
+func TestListBackupsPageRebuildsStaleIndex(t *testing.T) {
+	manager, memFS, storage, _ := newMemBackupManager(t)
+	if _, err := manager.CreateExtensionBackup(storage, "only"); err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	if page, err := manager.ListBackupsPage(0, 0, BackupSortDate); err != nil || page.Total != 1 {
+		t.Fatalf("Expected 1 backup, got %+v (%v)", page, err)
+	}
+
+	// A backup copied into the directory is not in the index, but every backup
+	// the index refers to still loads
+	data, err := memFS.ReadFile(filepath.Join(manager.backupDirectory, "only.metadata.json"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if err := memFS.WriteFile(filepath.Join(manager.backupDirectory, "copied.metadata.json"), data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	page, err := manager.ListBackupsPage(0, 0, BackupSortDate)
+	if err != nil {
+		t.Fatalf("ListBackupsPage() failed: %v", err)
+	}
+	if page.Total != 2 || len(page.Backups) != 2 {
+		t.Errorf("Expected the stale index to be rebuilt with 2 backups, got %+v", page)
+	}
+	if index, err := manager.loadBackupIndex(); err != nil || len(index.Entries) != 2 {
+		t.Errorf("Expected the rebuilt index to be saved with 2 entries, got %v (%v)", index, err)
+	}
+}
@@ -0,0 +1,26 @@
+package cleaner
+
+import (
+	"errors"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/filelock"
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+func TestCreateExtensionBackupLockedDirectory(t *testing.T) {
+	manager := NewBackupManager()
+	manager.backupDirectory = t.TempDir()
+
+	unlock, err := filelock.Lock(manager.backupDirectory)
+	if err != nil {
+		t.Fatalf("Lock() failed: %v", err)
+	}
+	defer unlock()
+
+	storage := scanner.ExtensionStorage{ExtensionID: "augment.vscode-augment", StoragePath: t.TempDir()}
+	_, err = manager.CreateExtensionBackup(storage, "locked")
+	if !errors.Is(err, filelock.ErrLockBusy) {
+		t.Errorf("Expected ErrLockBusy while the backup directory is locked, got %v", err)
+	}
+}
@@ -3,15 +3,19 @@ package cleaner
 import (
 	"archive/zip"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/vfs"
 )
 
 // BackupManager handles creation, verification, and restoration of backups
@@ -19,23 +23,28 @@ type BackupManager struct {
 	backupDirectory string
 	maxBackupAge    time.Duration
 	maxBackupSize   int64
+	fs              vfs.FileSystem   // File operations, OsFS unless replaced with SetFileSystem
+	now             func() time.Time // Clock for backup timestamps and age checks
 }
 
 // BackupMetadata represents metadata about a backup
 type BackupMetadata struct {
-	BackupID        string                    `json:"backup_id"`
-	ExtensionID     string                    `json:"extension_id"`
-	CreationTime    time.Time                 `json:"creation_time"`
-	BackupType      string                    `json:"backup_type"`
-	OriginalPath    string                    `json:"original_path"`
-	BackupPath      string                    `json:"backup_path"`
-	TotalSize       int64                     `json:"total_size"`
-	FileCount       int                       `json:"file_count"`
-	Checksum        string                    `json:"checksum"`
-	BackupItems     []BackupItem              `json:"backup_items"`
-	CompressionType string                    `json:"compression_type"`
-	Verified        bool                      `json:"verified"`
-	RestorationInfo *RestorationInfo          `json:"restoration_info,omitempty"`
+	BackupID         string            `json:"backup_id"`
+	ExtensionID      string            `json:"extension_id"`
+	CreationTime     time.Time         `json:"creation_time"`
+	BackupType       string            `json:"backup_type"`
+	OriginalPath     string            `json:"original_path"`
+	BackupPath       string            `json:"backup_path"`
+	TotalSize        int64             `json:"total_size"`
+	FileCount        int               `json:"file_count"`
+	Checksum         string            `json:"checksum"`
+	BackupItems      []BackupItem      `json:"backup_items"`
+	CompressionType  string            `json:"compression_type"`
+	Verified         bool              `json:"verified"`
+	RestorationInfo  *RestorationInfo  `json:"restoration_info,omitempty"`
+	SchemaVersion    int               `json:"schema_version"`
+	SHA256Checksum   string            `json:"sha256_checksum,omitempty"`
+	PerFileChecksums map[string]string `json:"per_file_checksums,omitempty"`
 }
 
 // BackupItem represents an individual item in a backup
@@ -86,59 +95,87 @@ func NewBackupManager() *BackupManager {
 		backupDirectory: backupDir,
 		maxBackupAge:    90 * 24 * time.Hour, // 90 days
 		maxBackupSize:   1024 * 1024 * 1024,  // 1GB
+		fs:              vfs.OsFS{},
+		now:             time.Now,
 	}
 }
 
+// SetFileSystem replaces the file system backups are read from and written to,
+// e.g. with a vfs.MemFS in tests
+func (bm *BackupManager) SetFileSystem(fs vfs.FileSystem) {
+	bm.fs = fs
+}
+
 // CreateExtensionBackup creates a comprehensive backup of extension data
 func (bm *BackupManager) CreateExtensionBackup(extensionStorage scanner.ExtensionStorage, backupName string) (string, error) {
+	metadata := BackupMetadata{
+		ExtensionID:  extensionStorage.ExtensionID,
+		BackupType:   "extension_full",
+		OriginalPath: extensionStorage.StoragePath,
+	}
+	return bm.createBackup(backupName, extensionStorage.StoragePath, nil, metadata, extensionStorage.StorageItems)
+}
+
+// createBackup archives the files below root as backupName, optionally only
+// those include accepts, and saves metadata next to the archive. Directories below
+// root that include rejects are not walked. metadata describes the source; the
+// remaining fields are filled in here.
+func (bm *BackupManager) createBackup(backupName, root string, include func(path string) bool, metadata BackupMetadata, storageItems []scanner.StorageDataItem) (string, error) {
 	// Ensure backup directory exists
-	if err := os.MkdirAll(bm.backupDirectory, 0755); err != nil {
+	if err := bm.fs.MkdirAll(bm.backupDirectory, 0755); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	// Create backup path
-	backupPath := filepath.Join(bm.backupDirectory, backupName+".zip")
-	
-	// Create backup metadata
-	metadata := BackupMetadata{
-		BackupID:        bm.generateBackupID(),
-		ExtensionID:     extensionStorage.ExtensionID,
-		CreationTime:    time.Now(),
-		BackupType:      "extension_full",
-		OriginalPath:    extensionStorage.StoragePath,
-		BackupPath:      backupPath,
-		CompressionType: "zip",
-		BackupItems:     make([]BackupItem, 0),
-	}
-
-	// Create zip file
-	zipFile, err := os.Create(backupPath)
+	// Lock the backup directory so concurrent runs cannot write the same backup
+	unlock, err := bm.fs.Lock(bm.backupDirectory)
+	if err != nil {
+		return "", fmt.Errorf("failed to lock backup directory: %w", err)
+	}
+	defer unlock()
+
+	// Create zip file, never replacing an earlier backup of the same name
+	zipFile, backupPath, err := bm.createUniqueBackupFile(backupName)
 	if err != nil {
 		return "", fmt.Errorf("failed to create backup file: %w", err)
 	}
 	defer zipFile.Close()
 
+	// Complete backup metadata
+	metadata.BackupID = bm.generateBackupID()
+	metadata.CreationTime = bm.now()
+	metadata.BackupPath = backupPath
+	metadata.CompressionType = "zip"
+	metadata.BackupItems = make([]BackupItem, 0)
+	metadata.SchemaVersion = CurrentBackupSchemaVersion
+	metadata.PerFileChecksums = make(map[string]string)
+
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
 	// Backup storage directory
-	err = filepath.Walk(extensionStorage.StoragePath, func(path string, info os.FileInfo, err error) error {
+	err = bm.fs.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Continue despite errors
 		}
 
+		if include != nil && !include(path) {
+			if info.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if info.IsDir() {
 			return nil
 		}
 
 		// Calculate relative path
-		relPath, err := filepath.Rel(extensionStorage.StoragePath, path)
+		relPath, err := filepath.Rel(root, path)
 		if err != nil {
 			return nil // Skip files we can't process
 		}
 
 		// Create backup item
-		backupItem, err := bm.createBackupItem(path, relPath, info, extensionStorage.StorageItems)
+		backupItem, err := bm.createBackupItem(path, relPath, info, storageItems)
 		if err != nil {
 			return nil // Skip files we can't backup
 		}
@@ -149,6 +186,9 @@ func (bm *BackupManager) CreateExtensionBackup(extensionStorage scanner.Extensio
 		}
 
 		metadata.BackupItems = append(metadata.BackupItems, *backupItem)
+		if fileChecksum, err := bm.calculateFileSHA256(path); err == nil {
+			metadata.PerFileChecksums[filepath.ToSlash(relPath)] = fileChecksum
+		}
 		metadata.TotalSize += info.Size()
 		metadata.FileCount++
 
@@ -156,7 +196,7 @@ func (bm *BackupManager) CreateExtensionBackup(extensionStorage scanner.Extensio
 	})
 
 	if err != nil {
-		os.Remove(backupPath)
+		bm.fs.Remove(backupPath)
 		return "", fmt.Errorf("failed to create backup: %w", err)
 	}
 
@@ -166,24 +206,59 @@ func (bm *BackupManager) CreateExtensionBackup(extensionStorage scanner.Extensio
 
 	checksum, err := bm.calculateFileChecksum(backupPath)
 	if err != nil {
-		os.Remove(backupPath)
+		bm.fs.Remove(backupPath)
 		return "", fmt.Errorf("failed to calculate backup checksum: %w", err)
 	}
 	metadata.Checksum = checksum
 
+	sha256Checksum, err := bm.calculateFileSHA256(backupPath)
+	if err != nil {
+		bm.fs.Remove(backupPath)
+		return "", fmt.Errorf("failed to calculate backup SHA-256 checksum: %w", err)
+	}
+	metadata.SHA256Checksum = sha256Checksum
+
 	// Save metadata
 	metadataPath := strings.TrimSuffix(backupPath, ".zip") + ".metadata.json"
 	if err := bm.saveBackupMetadata(metadata, metadataPath); err != nil {
-		os.Remove(backupPath)
+		bm.fs.Remove(backupPath)
 		return "", fmt.Errorf("failed to save backup metadata: %w", err)
 	}
 
+	// The backup is complete; a stale index is rebuilt when backups are listed
+	bm.addToBackupIndex(metadata, metadataPath)
+
 	return backupPath, nil
 }
 
+// maxBackupNameAttempts bounds the numbered suffixes createUniqueBackupFile tries
+const maxBackupNameAttempts = 100
+
+// createUniqueBackupFile creates <backupName>.zip in the backup directory, or
+// <backupName>-2.zip and so on when a backup of that name already exists, and
+// returns the file with its path
+func (bm *BackupManager) createUniqueBackupFile(backupName string) (vfs.File, string, error) {
+	for attempt := 1; attempt <= maxBackupNameAttempts; attempt++ {
+		name := backupName
+		if attempt > 1 {
+			name = fmt.Sprintf("%s-%d", backupName, attempt)
+		}
+		backupPath := filepath.Join(bm.backupDirectory, name+".zip")
+		file, err := bm.fs.OpenFile(backupPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return file, backupPath, nil
+	}
+	return nil, "", fmt.Errorf("%d backups named %s already exist", maxBackupNameAttempts, backupName)
+}
+
 // BackupStorageItem creates a backup of a single storage item
 func (bm *BackupManager) BackupStorageItem(item scanner.StorageDataItem) (string, error) {
-	timestamp := time.Now().Unix()
+	timestamp := bm.now().Unix()
 	backupName := fmt.Sprintf("storage-item-%s-%d", 
 		strings.ReplaceAll(item.Key, "/", "-"), 
 		timestamp)
@@ -191,7 +266,7 @@ func (bm *BackupManager) BackupStorageItem(item scanner.StorageDataItem) (string
 	backupPath := filepath.Join(bm.backupDirectory, "items", backupName+".json")
 	
 	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+	if err := bm.fs.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
@@ -205,7 +280,7 @@ func (bm *BackupManager) BackupStorageItem(item scanner.StorageDataItem) (string
 		"category":      item.Category,
 		"description":   item.Description,
 		"last_modified": item.LastModified,
-		"backup_time":   time.Now(),
+		"backup_time":   bm.now(),
 	}
 
 	// Save backup
@@ -214,7 +289,7 @@ func (bm *BackupManager) BackupStorageItem(item scanner.StorageDataItem) (string
 		return "", fmt.Errorf("failed to marshal backup data: %w", err)
 	}
 
-	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+	if err := bm.fs.WriteFile(backupPath, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to write backup file: %w", err)
 	}
 
@@ -231,7 +306,7 @@ func (bm *BackupManager) VerifyBackup(backupPath string) error {
 	}
 
 	// Verify backup file exists
-	if _, err := os.Stat(backupPath); err != nil {
+	if _, err := bm.fs.Stat(backupPath); err != nil {
 		return fmt.Errorf("backup file not found: %w", err)
 	}
 
@@ -246,6 +321,19 @@ func (bm *BackupManager) VerifyBackup(backupPath string) error {
 			metadata.Checksum, currentChecksum)
 	}
 
+	// Verify SHA-256 checksum (present from schema version 2)
+	if metadata.SHA256Checksum != "" {
+		currentSHA256, err := bm.calculateFileSHA256(backupPath)
+		if err != nil {
+			return fmt.Errorf("failed to calculate current SHA-256 checksum: %w", err)
+		}
+
+		if currentSHA256 != metadata.SHA256Checksum {
+			return fmt.Errorf("backup SHA-256 checksum mismatch: expected %s, got %s",
+				metadata.SHA256Checksum, currentSHA256)
+		}
+	}
+
 	// Verify zip file integrity
 	if err := bm.verifyZipIntegrity(backupPath); err != nil {
 		return fmt.Errorf("zip file integrity check failed: %w", err)
@@ -286,36 +374,24 @@ func (bm *BackupManager) RestoreBackup(backupPath, restorePath string) (*Restore
 	}
 
 	// Create restore directory
-	if err := os.MkdirAll(restorePath, 0755); err != nil {
+	if err := bm.fs.MkdirAll(restorePath, 0755); err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to create restore directory: %v", err))
 		return result, fmt.Errorf("failed to create restore directory: %w", err)
 	}
 
-	// Extract zip file
-	if err := bm.extractZipFile(backupPath, restorePath); err != nil {
+	// Extract zip file, counting the restored files rather than walking restorePath,
+	// which can hold unrelated files such as the rest of the VS Code User directory
+	fileCount, restoredSize, err := bm.extractZipFile(backupPath, restorePath)
+	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("Failed to extract backup: %v", err))
 		return result, fmt.Errorf("failed to extract backup: %w", err)
 	}
-
-	// Calculate restored size and file count
-	err = filepath.Walk(restorePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() {
-			result.RestoredSize += info.Size()
-			result.FileCount++
-		}
-		return nil
-	})
-
-	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to calculate restore statistics: %v", err))
-	}
+	result.FileCount = fileCount
+	result.RestoredSize = restoredSize
 
 	// Update metadata with restoration info
 	metadata.RestorationInfo = &RestorationInfo{
-		RestoredTime:    time.Now(),
+		RestoredTime:    bm.now(),
 		RestoredBy:      "extension_cleaner",
 		RestorationPath: restorePath,
 		Success:         len(result.Errors) == 0,
@@ -340,11 +416,11 @@ func (bm *BackupManager) RestoreBackup(backupPath, restorePath string) (*Restore
 func (bm *BackupManager) ListBackups() ([]BackupMetadata, error) {
 	var backups []BackupMetadata
 
-	if _, err := os.Stat(bm.backupDirectory); os.IsNotExist(err) {
+	if _, err := bm.fs.Stat(bm.backupDirectory); os.IsNotExist(err) {
 		return backups, nil // No backups directory
 	}
 
-	err := filepath.Walk(bm.backupDirectory, func(path string, info os.FileInfo, err error) error {
+	err := bm.fs.Walk(bm.backupDirectory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Continue despite errors
 		}
@@ -374,7 +450,7 @@ func (bm *BackupManager) CleanupOldBackups() error {
 		return fmt.Errorf("failed to list backups: %w", err)
 	}
 
-	now := time.Now()
+	now := bm.now()
 	var totalSize int64
 
 	// Calculate total backup size
@@ -414,7 +490,7 @@ func (bm *BackupManager) CleanupOldBackups() error {
 
 // generateBackupID generates a unique backup ID
 func (bm *BackupManager) generateBackupID() string {
-	timestamp := time.Now().Unix()
+	timestamp := bm.now().Unix()
 	return fmt.Sprintf("backup-%d", timestamp)
 }
 
@@ -450,7 +526,7 @@ func (bm *BackupManager) createBackupItem(filePath, relativePath string, info os
 
 // addFileToZip adds a file to a zip archive
 func (bm *BackupManager) addFileToZip(zipWriter *zip.Writer, filePath, relativePath string) error {
-	file, err := os.Open(filePath)
+	file, err := bm.fs.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
@@ -488,7 +564,7 @@ func (bm *BackupManager) addFileToZip(zipWriter *zip.Writer, filePath, relativeP
 
 // calculateFileChecksum calculates MD5 checksum of a file
 func (bm *BackupManager) calculateFileChecksum(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+	file, err := bm.fs.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
 	}
@@ -502,6 +578,22 @@ func (bm *BackupManager) calculateFileChecksum(filePath string) (string, error)
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
+// calculateFileSHA256 calculates SHA-256 checksum of a file
+func (bm *BackupManager) calculateFileSHA256(filePath string) (string, error) {
+	file, err := bm.fs.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to calculate hash: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
 // saveBackupMetadata saves backup metadata to a JSON file
 func (bm *BackupManager) saveBackupMetadata(metadata BackupMetadata, metadataPath string) error {
 	data, err := json.MarshalIndent(metadata, "", "  ")
@@ -509,7 +601,7 @@ func (bm *BackupManager) saveBackupMetadata(metadata BackupMetadata, metadataPat
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+	if err := bm.fs.WriteFile(metadataPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
@@ -518,7 +610,7 @@ func (bm *BackupManager) saveBackupMetadata(metadata BackupMetadata, metadataPat
 
 // loadBackupMetadata loads backup metadata from a JSON file
 func (bm *BackupManager) loadBackupMetadata(metadataPath string) (*BackupMetadata, error) {
-	data, err := os.ReadFile(metadataPath)
+	data, err := bm.fs.ReadFile(metadataPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata file: %w", err)
 	}
@@ -533,11 +625,11 @@ func (bm *BackupManager) loadBackupMetadata(metadataPath string) (*BackupMetadat
 
 // verifyZipIntegrity verifies that a zip file can be opened and read
 func (bm *BackupManager) verifyZipIntegrity(zipPath string) error {
-	reader, err := zip.OpenReader(zipPath)
+	reader, closer, err := bm.openZip(zipPath)
 	if err != nil {
 		return fmt.Errorf("failed to open zip file: %w", err)
 	}
-	defer reader.Close()
+	defer closer.Close()
 
 	// Try to read each file in the zip
 	for _, file := range reader.File {
@@ -559,13 +651,39 @@ func (bm *BackupManager) verifyZipIntegrity(zipPath string) error {
 	return nil
 }
 
-// extractZipFile extracts a zip file to the specified directory
-func (bm *BackupManager) extractZipFile(zipPath, destPath string) error {
-	reader, err := zip.OpenReader(zipPath)
+// openZip opens a zip archive on the backup file system; close the returned
+// closer when done with the reader
+func (bm *BackupManager) openZip(zipPath string) (*zip.Reader, io.Closer, error) {
+	file, err := bm.fs.Open(zipPath)
 	if err != nil {
-		return fmt.Errorf("failed to open zip file: %w", err)
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	reader, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return reader, file, nil
+}
+
+// extractZipFile extracts a zip file to the specified directory and returns the
+// number and total size of the extracted files
+func (bm *BackupManager) extractZipFile(zipPath, destPath string) (int, int64, error) {
+	reader, closer, err := bm.openZip(zipPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open zip file: %w", err)
 	}
-	defer reader.Close()
+	defer closer.Close()
+
+	var fileCount int
+	var totalSize int64
 
 	// Extract files
 	for _, file := range reader.File {
@@ -573,26 +691,28 @@ func (bm *BackupManager) extractZipFile(zipPath, destPath string) error {
 		
 		// Ensure the file path is within the destination directory
 		if !strings.HasPrefix(path, filepath.Clean(destPath)+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid file path: %s", file.Name)
+			return fileCount, totalSize, fmt.Errorf("invalid file path: %s", file.Name)
 		}
 
 		if file.FileInfo().IsDir() {
-			os.MkdirAll(path, file.FileInfo().Mode())
+			bm.fs.MkdirAll(path, file.FileInfo().Mode())
 			continue
 		}
 
 		// Create directory for file
-		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
+		if err := bm.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fileCount, totalSize, fmt.Errorf("failed to create directory: %w", err)
 		}
 
 		// Extract file
 		if err := bm.extractFile(file, path); err != nil {
-			return fmt.Errorf("failed to extract file %s: %w", file.Name, err)
+			return fileCount, totalSize, fmt.Errorf("failed to extract file %s: %w", file.Name, err)
 		}
+		fileCount++
+		totalSize += int64(file.UncompressedSize64)
 	}
 
-	return nil
+	return fileCount, totalSize, nil
 }
 
 // extractFile extracts a single file from a zip archive
@@ -603,7 +723,7 @@ func (bm *BackupManager) extractFile(file *zip.File, destPath string) error {
 	}
 	defer rc.Close()
 
-	outFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.FileInfo().Mode())
+	outFile, err := bm.fs.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.FileInfo().Mode())
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
@@ -620,16 +740,19 @@ func (bm *BackupManager) extractFile(file *zip.File, destPath string) error {
 // removeBackup removes a backup and its metadata
 func (bm *BackupManager) removeBackup(backup BackupMetadata) error {
 	// Remove backup file
-	if err := os.Remove(backup.BackupPath); err != nil && !os.IsNotExist(err) {
+	if err := bm.fs.Remove(backup.BackupPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove backup file: %w", err)
 	}
 
 	// Remove metadata file
 	metadataPath := strings.TrimSuffix(backup.BackupPath, ".zip") + ".metadata.json"
-	if err := os.Remove(metadataPath); err != nil && !os.IsNotExist(err) {
+	if err := bm.fs.Remove(metadataPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove metadata file: %w", err)
 	}
 
+	// The backup is gone; a stale index is rebuilt when backups are listed
+	bm.removeFromBackupIndex(metadataPath)
+
 	return nil
 }
 
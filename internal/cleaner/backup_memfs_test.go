@@ -0,0 +1,209 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/vfs"
+)
+
+// memBackupFiles is the extension storage created by newMemBackupManager,
+// keyed by slash-separated path relative to the storage directory
+var memBackupFiles = map[string]string{
+	"state.json":           `{"machineId":"abc","sessionId":"def"}`,
+	"cache/telemetry.json": `{"events":[1,2,3]}`,
+	"cache/empty.log":      "",
+}
+
+// newMemBackupManager returns a backup manager on a MemFS holding a populated
+// extension storage directory, and a clock that tests can move forward
+func newMemBackupManager(t *testing.T) (*BackupManager, *vfs.MemFS, scanner.ExtensionStorage, *time.Time) {
+	t.Helper()
+	memFS := vfs.NewMemFS()
+	storagePath := filepath.FromSlash("/vscode/globalStorage/augment.vscode-augment")
+
+	for name, content := range memBackupFiles {
+		path := filepath.Join(storagePath, filepath.FromSlash(name))
+		if err := memFS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := memFS.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewBackupManager()
+	manager.SetFileSystem(memFS)
+	manager.backupDirectory = filepath.FromSlash("/backups/extensions")
+	manager.now = func() time.Time { return now }
+
+	storage := scanner.ExtensionStorage{ExtensionID: "augment.vscode-augment", StoragePath: storagePath}
+	return manager, memFS, storage, &now
+}
+
+func TestBackupManagerMemFSBackupCycle(t *testing.T) {
+	manager, memFS, storage, now := newMemBackupManager(t)
+
+	backupPath, err := manager.CreateExtensionBackup(storage, "augment-full")
+	if err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	if want := filepath.Join(manager.backupDirectory, "augment-full.zip"); backupPath != want {
+		t.Errorf("Expected backup path %s, got %s", want, backupPath)
+	}
+
+	// The archive holds every file of the storage directory
+	reader, closer, err := manager.openZip(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to open backup archive: %v", err)
+	}
+	archived := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s in archive: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s in archive: %v", file.Name, err)
+		}
+		archived[filepath.ToSlash(file.Name)] = string(data)
+	}
+	closer.Close()
+	if len(archived) != len(memBackupFiles) {
+		t.Errorf("Expected %d archived files, got %v", len(memBackupFiles), archived)
+	}
+	for name, content := range memBackupFiles {
+		if archived[name] != content {
+			t.Errorf("Expected %s archived with %q, got %q", name, content, archived[name])
+		}
+	}
+
+	// The metadata JSON describes the archive
+	data, err := memFS.ReadFile(filepath.Join(manager.backupDirectory, "augment-full.metadata.json"))
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	var metadata BackupMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	var totalSize int64
+	var checksummed []string
+	for name, content := range memBackupFiles {
+		totalSize += int64(len(content))
+		checksummed = append(checksummed, name)
+	}
+	if metadata.ExtensionID != storage.ExtensionID || metadata.OriginalPath != storage.StoragePath || metadata.BackupPath != backupPath {
+		t.Errorf("Unexpected metadata identity: %+v", metadata)
+	}
+	if !metadata.CreationTime.Equal(*now) || metadata.BackupID != "backup-1709294400" {
+		t.Errorf("Expected the backup to be stamped with the fake clock, got %s and %s", metadata.CreationTime, metadata.BackupID)
+	}
+	if metadata.FileCount != len(memBackupFiles) || metadata.TotalSize != totalSize || len(metadata.BackupItems) != len(memBackupFiles) {
+		t.Errorf("Expected %d files of %d bytes, got %d files (%d items) of %d bytes",
+			len(memBackupFiles), totalSize, metadata.FileCount, len(metadata.BackupItems), metadata.TotalSize)
+	}
+	if metadata.SchemaVersion != CurrentBackupSchemaVersion || metadata.Checksum == "" || metadata.SHA256Checksum == "" {
+		t.Errorf("Expected a current schema version and archive checksums, got %+v", metadata)
+	}
+	var perFile []string
+	for name := range metadata.PerFileChecksums {
+		perFile = append(perFile, name)
+	}
+	sort.Strings(perFile)
+	sort.Strings(checksummed)
+	if !reflect.DeepEqual(perFile, checksummed) {
+		t.Errorf("Expected per-file checksums for %v, got %v", checksummed, perFile)
+	}
+
+	if err := manager.VerifyBackup(backupPath); err != nil {
+		t.Fatalf("VerifyBackup() failed: %v", err)
+	}
+
+	// Restoring recreates the storage directory elsewhere
+	*now = now.Add(time.Hour)
+	restorePath := filepath.FromSlash("/restore/augment.vscode-augment")
+	result, err := manager.RestoreBackup(backupPath, restorePath)
+	if err != nil {
+		t.Fatalf("RestoreBackup() failed: %v", err)
+	}
+	if !result.Success || result.FileCount != len(memBackupFiles) || result.RestoredSize != totalSize {
+		t.Errorf("Expected %d files of %d bytes restored, got %+v", len(memBackupFiles), totalSize, result)
+	}
+	for name, content := range memBackupFiles {
+		data, err := memFS.ReadFile(filepath.Join(restorePath, filepath.FromSlash(name)))
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %s restored with %q, got %q (%v)", name, content, data, err)
+		}
+	}
+
+	restored, err := manager.loadBackupMetadata(filepath.Join(manager.backupDirectory, "augment-full.metadata.json"))
+	if err != nil {
+		t.Fatalf("Failed to reload metadata: %v", err)
+	}
+	if !restored.Verified || restored.RestorationInfo == nil || !restored.RestorationInfo.RestoredTime.Equal(*now) {
+		t.Errorf("Expected verified metadata with restoration info at %s, got %+v", *now, restored)
+	}
+}
+
+func TestBackupManagerMemFSVerifyDetectsCorruption(t *testing.T) {
+	manager, memFS, storage, _ := newMemBackupManager(t)
+
+	backupPath, err := manager.CreateExtensionBackup(storage, "augment-full")
+	if err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	if err := memFS.WriteFile(backupPath, []byte("not a zip archive"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt backup: %v", err)
+	}
+
+	if err := manager.VerifyBackup(backupPath); err == nil {
+		t.Error("Expected VerifyBackup() to fail for a corrupted archive")
+	}
+	if _, err := manager.RestoreBackup(backupPath, filepath.FromSlash("/restore")); err == nil {
+		t.Error("Expected RestoreBackup() to refuse a corrupted archive")
+	}
+}
+
+func TestBackupManagerCleanupOldBackupsFakeClock(t *testing.T) {
+	manager, memFS, storage, now := newMemBackupManager(t)
+
+	if _, err := manager.CreateExtensionBackup(storage, "old"); err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	*now = now.Add(60 * 24 * time.Hour)
+	if _, err := manager.CreateExtensionBackup(storage, "recent"); err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+
+	// 95 days after the first backup only that one is past the 90 day limit
+	*now = now.Add(35 * 24 * time.Hour)
+	if err := manager.CleanupOldBackups(); err != nil {
+		t.Fatalf("CleanupOldBackups() failed: %v", err)
+	}
+
+	for name, wantExists := range map[string]bool{
+		"old.zip":              false,
+		"old.metadata.json":    false,
+		"recent.zip":           true,
+		"recent.metadata.json": true,
+	} {
+		if _, err := memFS.Stat(filepath.Join(manager.backupDirectory, name)); (err == nil) != wantExists {
+			t.Errorf("Expected %s to exist: %v, got error %v", name, wantExists, err)
+		}
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil || len(backups) != 1 || backups[0].BackupPath != filepath.Join(manager.backupDirectory, "recent.zip") {
+		t.Errorf("Expected only the recent backup to be listed, got %v (%v)", backups, err)
+	}
+}
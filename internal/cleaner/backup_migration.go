@@ -0,0 +1,165 @@
+package cleaner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// CurrentBackupSchemaVersion is the metadata schema version written by new backups.
+//
+//	v1: original format (MD5 checksum only)
+//	v2: adds SHA256Checksum of the backup archive
+//	v3: adds PerFileChecksums (SHA-256 per archived file)
+const CurrentBackupSchemaVersion = 3
+
+// MigrationReport represents the result of migrating backup metadata
+type MigrationReport struct {
+	Migrated int      `json:"migrated"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Details  []string `json:"details"`
+}
+
+// backupMigration upgrades metadata from one schema version to the next
+type backupMigration struct {
+	fromVersion int
+	apply       func(bm *BackupManager, metadata *BackupMetadata) error
+}
+
+// backupMigrations lists all migrations in version order
+var backupMigrations = []backupMigration{
+	{fromVersion: 1, apply: (*BackupManager).migrateAddSHA256Checksum},
+	{fromVersion: 2, apply: (*BackupManager).migrateAddPerFileChecksums},
+}
+
+// MigrateBackups upgrades the metadata of all existing backups to the current schema version.
+// Backups already at the current version are skipped, so running it repeatedly is safe.
+func (bm *BackupManager) MigrateBackups() (*MigrationReport, error) {
+	report := &MigrationReport{
+		Details: make([]string, 0),
+	}
+
+	backups, err := bm.ListBackups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	for _, backup := range backups {
+		metadata := backup
+		fromVersion := backupSchemaVersion(metadata)
+
+		if fromVersion >= CurrentBackupSchemaVersion {
+			report.Skipped++
+			continue
+		}
+
+		if err := bm.migrateBackupMetadata(&metadata); err != nil {
+			report.Failed++
+			report.Details = append(report.Details, fmt.Sprintf("%s: migration failed: %v", metadata.BackupID, err))
+			continue
+		}
+
+		metadataPath := strings.TrimSuffix(metadata.BackupPath, ".zip") + ".metadata.json"
+		if err := bm.saveBackupMetadata(metadata, metadataPath); err != nil {
+			report.Failed++
+			report.Details = append(report.Details, fmt.Sprintf("%s: %v", metadata.BackupID, err))
+			continue
+		}
+
+		report.Migrated++
+		report.Details = append(report.Details, fmt.Sprintf("%s: migrated from v%d to v%d",
+			metadata.BackupID, fromVersion, metadata.SchemaVersion))
+	}
+
+	return report, nil
+}
+
+// CountBackupsNeedingMigration returns how many backups are below the current schema version
+func (bm *BackupManager) CountBackupsNeedingMigration() (int, error) {
+	backups, err := bm.ListBackups()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	count := 0
+	for _, backup := range backups {
+		if backupSchemaVersion(backup) < CurrentBackupSchemaVersion {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// migrateBackupMetadata applies all pending migrations in version order
+func (bm *BackupManager) migrateBackupMetadata(metadata *BackupMetadata) error {
+	metadata.SchemaVersion = backupSchemaVersion(*metadata)
+
+	for _, migration := range backupMigrations {
+		if metadata.SchemaVersion != migration.fromVersion {
+			continue
+		}
+
+		if err := migration.apply(bm, metadata); err != nil {
+			return fmt.Errorf("v%d to v%d: %w", migration.fromVersion, migration.fromVersion+1, err)
+		}
+		metadata.SchemaVersion = migration.fromVersion + 1
+	}
+
+	return nil
+}
+
+// migrateAddSHA256Checksum adds the SHA-256 checksum of the backup archive (v1 -> v2)
+func (bm *BackupManager) migrateAddSHA256Checksum(metadata *BackupMetadata) error {
+	checksum, err := bm.calculateFileSHA256(metadata.BackupPath)
+	if err != nil {
+		return fmt.Errorf("failed to calculate SHA-256 checksum: %w", err)
+	}
+
+	metadata.SHA256Checksum = checksum
+	return nil
+}
+
+// migrateAddPerFileChecksums adds SHA-256 checksums for each archived file (v2 -> v3)
+func (bm *BackupManager) migrateAddPerFileChecksums(metadata *BackupMetadata) error {
+	reader, closer, err := bm.openZip(metadata.BackupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer closer.Close()
+
+	checksums := make(map[string]string)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open file %s in zip: %w", file.Name, err)
+		}
+
+		hash := sha256.New()
+		_, err = io.Copy(hash, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to hash file %s in zip: %w", file.Name, err)
+		}
+
+		checksums[filepath.ToSlash(file.Name)] = fmt.Sprintf("%x", hash.Sum(nil))
+	}
+
+	metadata.PerFileChecksums = checksums
+	return nil
+}
+
+// backupSchemaVersion returns the schema version of metadata, treating a missing version as v1
+func backupSchemaVersion(metadata BackupMetadata) int {
+	if metadata.SchemaVersion <= 0 {
+		return 1
+	}
+	return metadata.SchemaVersion
+}
@@ -0,0 +1,86 @@
+package cleaner
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupManagerMigrateBackups(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewBackupManager()
+	manager.backupDirectory = tempDir
+
+	// Create a legacy (v1) backup: zip archive plus metadata without a schema version
+	backupPath := filepath.Join(tempDir, "legacy.zip")
+	zipFile, err := os.Create(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipWriter := zip.NewWriter(zipFile)
+	writer, err := zipWriter.Create("state.json")
+	if err != nil {
+		t.Fatalf("Failed to add zip entry: %v", err)
+	}
+	writer.Write([]byte(`{"machineId":"abc"}`))
+	zipWriter.Close()
+	zipFile.Close()
+
+	checksum, err := manager.calculateFileChecksum(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to calculate checksum: %v", err)
+	}
+
+	legacy := BackupMetadata{
+		BackupID:     "backup-legacy",
+		CreationTime: time.Now(),
+		BackupPath:   backupPath,
+		Checksum:     checksum,
+	}
+	metadataPath := filepath.Join(tempDir, "legacy.metadata.json")
+	if err := manager.saveBackupMetadata(legacy, metadataPath); err != nil {
+		t.Fatalf("Failed to save metadata: %v", err)
+	}
+
+	report, err := manager.MigrateBackups()
+	if err != nil {
+		t.Fatalf("MigrateBackups() failed: %v", err)
+	}
+
+	if report.Migrated != 1 || report.Failed != 0 {
+		t.Errorf("Expected 1 migrated and 0 failed, got %+v", report)
+	}
+
+	migrated, err := manager.loadBackupMetadata(metadataPath)
+	if err != nil {
+		t.Fatalf("Failed to load migrated metadata: %v", err)
+	}
+
+	if migrated.SchemaVersion != CurrentBackupSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", CurrentBackupSchemaVersion, migrated.SchemaVersion)
+	}
+
+	if migrated.SHA256Checksum == "" {
+		t.Error("Expected SHA-256 checksum to be added")
+	}
+
+	if _, ok := migrated.PerFileChecksums["state.json"]; !ok {
+		t.Error("Expected per-file checksum for state.json")
+	}
+
+	if err := manager.VerifyBackup(backupPath); err != nil {
+		t.Errorf("Expected migrated backup to verify, got: %v", err)
+	}
+
+	// Running the migration again must be a no-op
+	report, err = manager.MigrateBackups()
+	if err != nil {
+		t.Fatalf("Second MigrateBackups() failed: %v", err)
+	}
+
+	if report.Migrated != 0 || report.Skipped != 1 {
+		t.Errorf("Expected second run to skip the backup, got %+v", report)
+	}
+}
@@ -0,0 +1,85 @@
+package cleaner
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BackupStats summarizes the disk space and coverage of all extension backups
+type BackupStats struct {
+	TotalBackups        int              `json:"total_backups"`
+	TotalSizeBytes      int64            `json:"total_size_bytes"` // Size of the backup archives on disk
+	OldestBackup        time.Time        `json:"oldest_backup"`
+	NewestBackup        time.Time        `json:"newest_backup"`
+	VerifiedCount       int              `json:"verified_count"`
+	UnverifiedCount     int              `json:"unverified_count"`
+	ExtensionsCovered   []string         `json:"extensions_covered"`
+	AverageBackupSizeMB float64          `json:"average_backup_size_mb"`
+	CompressionStats    map[string]int64 `json:"compression_stats"` // Bytes on disk per compression type
+}
+
+// GetBackupStats summarizes the backups returned by ListBackups
+func (bm *BackupManager) GetBackupStats() (*BackupStats, error) {
+	backups, err := bm.ListBackups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	return bm.summarizeBackups(backups), nil
+}
+
+// summarizeBackups aggregates backup metadata into BackupStats
+func (bm *BackupManager) summarizeBackups(backups []BackupMetadata) *BackupStats {
+	stats := &BackupStats{
+		TotalBackups:      len(backups),
+		ExtensionsCovered: make([]string, 0),
+		CompressionStats:  make(map[string]int64),
+	}
+
+	extensions := make(map[string]bool)
+	for _, backup := range backups {
+		size := bm.backupSizeOnDisk(backup)
+		stats.TotalSizeBytes += size
+
+		compression := backup.CompressionType
+		if compression == "" {
+			compression = "none"
+		}
+		stats.CompressionStats[compression] += size
+
+		if backup.Verified {
+			stats.VerifiedCount++
+		} else {
+			stats.UnverifiedCount++
+		}
+
+		if stats.OldestBackup.IsZero() || backup.CreationTime.Before(stats.OldestBackup) {
+			stats.OldestBackup = backup.CreationTime
+		}
+		if backup.CreationTime.After(stats.NewestBackup) {
+			stats.NewestBackup = backup.CreationTime
+		}
+
+		if backup.ExtensionID != "" && !extensions[backup.ExtensionID] {
+			extensions[backup.ExtensionID] = true
+			stats.ExtensionsCovered = append(stats.ExtensionsCovered, backup.ExtensionID)
+		}
+	}
+	sort.Strings(stats.ExtensionsCovered)
+
+	if stats.TotalBackups > 0 {
+		stats.AverageBackupSizeMB = float64(stats.TotalSizeBytes) / float64(stats.TotalBackups) / (1024 * 1024)
+	}
+
+	return stats
+}
+
+// backupSizeOnDisk returns the size of the backup archive, falling back to the
+// uncompressed size recorded in the metadata when the archive cannot be read
+func (bm *BackupManager) backupSizeOnDisk(backup BackupMetadata) int64 {
+	if info, err := bm.fs.Stat(backup.BackupPath); err == nil {
+		return info.Size()
+	}
+	return backup.TotalSize
+}
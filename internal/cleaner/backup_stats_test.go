@@ -0,0 +1,80 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackupManagerGetBackupStats(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewBackupManager()
+	manager.backupDirectory = tempDir
+
+	oldest := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	newest := oldest.Add(48 * time.Hour)
+
+	backups := []struct {
+		name     string
+		metadata BackupMetadata
+		archive  int // Bytes written to the archive, 0 for a missing archive
+	}{
+		{"a", BackupMetadata{ExtensionID: "augment.vscode-augment", CreationTime: newest, CompressionType: "zip", Verified: true, TotalSize: 9999}, 1024},
+		{"b", BackupMetadata{ExtensionID: "augment.vscode-augment", CreationTime: oldest, CompressionType: "zip", TotalSize: 9999}, 2048},
+		{"c", BackupMetadata{ExtensionID: "ms-python.python", CreationTime: oldest.Add(time.Hour), TotalSize: 512}, 0},
+	}
+	for _, b := range backups {
+		b.metadata.BackupPath = filepath.Join(tempDir, b.name+".zip")
+		if b.archive > 0 {
+			if err := os.WriteFile(b.metadata.BackupPath, make([]byte, b.archive), 0644); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+		}
+		if err := manager.saveBackupMetadata(b.metadata, filepath.Join(tempDir, b.name+".metadata.json")); err != nil {
+			t.Fatalf("Failed to save metadata: %v", err)
+		}
+	}
+
+	stats, err := manager.GetBackupStats()
+	if err != nil {
+		t.Fatalf("GetBackupStats() failed: %v", err)
+	}
+
+	if stats.TotalBackups != 3 {
+		t.Errorf("Expected 3 backups, got %d", stats.TotalBackups)
+	}
+	// Archives count with their size on disk; the missing one with its recorded size
+	if stats.TotalSizeBytes != 1024+2048+512 {
+		t.Errorf("Expected %d bytes, got %d", 1024+2048+512, stats.TotalSizeBytes)
+	}
+	if !stats.OldestBackup.Equal(oldest) || !stats.NewestBackup.Equal(newest) {
+		t.Errorf("Expected backups from %v to %v, got %v to %v", oldest, newest, stats.OldestBackup, stats.NewestBackup)
+	}
+	if stats.VerifiedCount != 1 || stats.UnverifiedCount != 2 {
+		t.Errorf("Expected 1 verified and 2 unverified, got %d and %d", stats.VerifiedCount, stats.UnverifiedCount)
+	}
+	if want := []string{"augment.vscode-augment", "ms-python.python"}; !reflect.DeepEqual(stats.ExtensionsCovered, want) {
+		t.Errorf("Expected extensions %v, got %v", want, stats.ExtensionsCovered)
+	}
+	if want := map[string]int64{"zip": 3072, "none": 512}; !reflect.DeepEqual(stats.CompressionStats, want) {
+		t.Errorf("Expected compression stats %v, got %v", want, stats.CompressionStats)
+	}
+	if want := float64(3584) / 3 / (1024 * 1024); stats.AverageBackupSizeMB != want {
+		t.Errorf("Expected average %f MB, got %f", want, stats.AverageBackupSizeMB)
+	}
+}
+
+func TestBackupManagerGetBackupStatsWithoutBackups(t *testing.T) {
+	manager := NewBackupManager()
+	manager.backupDirectory = filepath.Join(t.TempDir(), "missing")
+
+	stats, err := manager.GetBackupStats()
+	if err != nil {
+		t.Fatalf("GetBackupStats() failed: %v", err)
+	}
+	if stats.TotalBackups != 0 || stats.AverageBackupSizeMB != 0 || !stats.OldestBackup.IsZero() {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+}
@@ -1,10 +1,7 @@
 package cleaner
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"augment-telemetry-cleaner/internal/utils"
@@ -14,6 +11,7 @@ import (
 type DependencyChecker struct {
 	extensionRegistry map[string]*ExtensionInfo
 	dependencyGraph   map[string][]string
+	resolver          utils.PathResolver
 }
 
 // ExtensionInfo represents information about an installed extension
@@ -40,9 +38,16 @@ type DependencyInfo struct {
 
 // NewDependencyChecker creates a new dependency checker
 func NewDependencyChecker() *DependencyChecker {
+	return NewDependencyCheckerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewDependencyCheckerWithResolver creates a dependency checker that reads the
+// extensions installed in the environment described by resolver
+func NewDependencyCheckerWithResolver(resolver utils.PathResolver) *DependencyChecker {
 	return &DependencyChecker{
 		extensionRegistry: make(map[string]*ExtensionInfo),
 		dependencyGraph:   make(map[string][]string),
+		resolver:          resolver,
 	}
 }
 
@@ -93,39 +98,13 @@ func (dc *DependencyChecker) CheckDependencies(extensionID string) ([]Dependency
 
 // loadExtensionRegistry loads information about all installed extensions
 func (dc *DependencyChecker) loadExtensionRegistry() error {
-	extensionsPath, err := utils.GetExtensionsPath()
-	if err != nil {
-		return fmt.Errorf("failed to get extensions path: %w", err)
-	}
-
-	if _, err := os.Stat(extensionsPath); os.IsNotExist(err) {
-		return nil // No extensions directory
-	}
-
-	entries, err := os.ReadDir(extensionsPath)
+	extensions, err := utils.ListInstalledExtensionsWithResolver(dc.resolver)
 	if err != nil {
-		return fmt.Errorf("failed to read extensions directory: %w", err)
+		return fmt.Errorf("failed to list installed extensions: %w", err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		extensionPath := filepath.Join(extensionsPath, entry.Name())
-		manifestPath := filepath.Join(extensionPath, "package.json")
-
-		// Check if package.json exists
-		if _, err := os.Stat(manifestPath); err != nil {
-			continue
-		}
-
-		// Load extension info
-		extInfo, err := dc.loadExtensionInfo(extensionPath, manifestPath)
-		if err != nil {
-			continue // Skip extensions we can't load
-		}
-
+	for _, extension := range extensions {
+		extInfo := dc.newExtensionInfo(extension)
 		dc.extensionRegistry[extInfo.ID] = extInfo
 		
 		// Build dependency graph
@@ -137,59 +116,29 @@ func (dc *DependencyChecker) loadExtensionRegistry() error {
 	return nil
 }
 
-// loadExtensionInfo loads information about a specific extension
-func (dc *DependencyChecker) loadExtensionInfo(extensionPath, manifestPath string) (*ExtensionInfo, error) {
-	// Read package.json
-	data, err := os.ReadFile(manifestPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read manifest: %w", err)
-	}
-
-	var manifest map[string]interface{}
-	if err := json.Unmarshal(data, &manifest); err != nil {
-		return nil, fmt.Errorf("failed to parse manifest: %w", err)
-	}
-
-	// Extract basic information
+// newExtensionInfo converts an installed extension to the registry entry
+func (dc *DependencyChecker) newExtensionInfo(extension utils.InstalledExtension) *ExtensionInfo {
 	extInfo := &ExtensionInfo{
-		InstallPath: extensionPath,
-		Manifest:    manifest,
-	}
-
-	// Extract ID
-	if name, ok := manifest["name"].(string); ok {
-		if publisher, ok := manifest["publisher"].(string); ok {
-			extInfo.ID = fmt.Sprintf("%s.%s", publisher, name)
-			extInfo.Name = name
-			extInfo.Publisher = publisher
-		}
-	}
-
-	// Extract version
-	if version, ok := manifest["version"].(string); ok {
-		extInfo.Version = version
+		ID:                    extension.ID,
+		Name:                  strings.TrimPrefix(extension.ID, extension.Publisher+"."),
+		Version:               extension.Version,
+		Publisher:             extension.Publisher,
+		ExtensionDependencies: extension.ExtensionDependencies,
+		InstallPath:           extension.Path,
+		Manifest:              extension.Manifest,
 	}
 
 	// Extract dependencies
-	if deps, ok := manifest["dependencies"].(map[string]interface{}); ok {
+	if deps, ok := extension.Manifest["dependencies"].(map[string]interface{}); ok {
 		for dep := range deps {
 			extInfo.Dependencies = append(extInfo.Dependencies, dep)
 		}
 	}
 
-	// Extract extension dependencies
-	if extDeps, ok := manifest["extensionDependencies"].([]interface{}); ok {
-		for _, dep := range extDeps {
-			if depStr, ok := dep.(string); ok {
-				extInfo.ExtensionDependencies = append(extInfo.ExtensionDependencies, depStr)
-			}
-		}
-	}
-
 	// Check if extension is active (simplified check)
 	extInfo.IsActive = dc.isExtensionActive(extInfo.ID)
 
-	return extInfo, nil
+	return extInfo
 }
 
 // hasConfigurationDependency checks if an extension has configuration dependencies
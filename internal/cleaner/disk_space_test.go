@@ -0,0 +1,23 @@
+package cleaner
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDiskSpace(t *testing.T) {
+	validator := NewSafetyValidator()
+	missing := filepath.Join(t.TempDir(), "backups", "not-created-yet")
+
+	// Paths that do not exist yet are checked on their closest existing parent
+	if err := validator.CheckDiskSpace(missing, 1); err != nil {
+		t.Errorf("Expected 1 byte to be available, got %v", err)
+	}
+
+	err := validator.CheckDiskSpace(missing, math.MaxUint64)
+	if err == nil || !strings.Contains(err.Error(), "insufficient disk space") {
+		t.Errorf("Expected insufficient disk space, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package cleaner
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// freeDiskSpace returns the bytes available to the current user, including disk
+// quotas, on the volume holding path
+func freeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("invalid path %s: %w", path, err)
+	}
+
+	var freeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, nil, nil); err != nil {
+		return 0, fmt.Errorf("failed to get free space of %s: %w", path, err)
+	}
+	return freeBytes, nil
+}
@@ -0,0 +1,154 @@
+package cleaner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// BulkCleanResult aggregates the cleaning of several extensions
+type BulkCleanResult struct {
+	PerExtension      map[string]*ExtensionCleanResult `json:"per_extension"` // Keyed by extension ID
+	TotalItemsRemoved int                              `json:"total_items_removed"`
+	TotalSizeRemoved  int64                            `json:"total_size_removed"`
+	FailedExtensions  []string                         `json:"failed_extensions,omitempty"`
+	Duration          time.Duration                    `json:"duration"`
+}
+
+// extensionCleanJob is the storage of one extension, global and per workspace
+type extensionCleanJob struct {
+	extensionID string
+	storages    []scanner.ExtensionStorage
+	checks      []SafetyCheckResult // Pre-flight result of each storage
+}
+
+// CleanExtensionList cleans the storages of several extensions with concurrency
+// workers. Safety checks of every storage run before any cleaning begins; an
+// extension whose checks or cleaning fail is listed in FailedExtensions without
+// stopping the others. Storages of the same extension, e.g. its global storage
+// and workspace storages, are cleaned together and reported as one result.
+func CleanExtensionList(storages []scanner.ExtensionStorage, policy RemovalPolicy, concurrency int) (*BulkCleanResult, error) {
+	return CleanExtensionListWithResolver(storages, policy, concurrency, utils.DefaultPathResolver())
+}
+
+// CleanExtensionListWithResolver is CleanExtensionList with the dependencies of
+// the extensions checked in the environment described by resolver
+func CleanExtensionListWithResolver(storages []scanner.ExtensionStorage, policy RemovalPolicy, concurrency int, resolver utils.PathResolver) (*BulkCleanResult, error) {
+	startTime := time.Now()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ec := NewExtensionCleanerWithResolver(policy, resolver)
+	result := &BulkCleanResult{PerExtension: make(map[string]*ExtensionCleanResult)}
+
+	// Pre-flight: check every storage before anything is modified
+	var jobs []*extensionCleanJob
+	byID := make(map[string]*extensionCleanJob)
+	for _, storage := range storages {
+		job, ok := byID[storage.ExtensionID]
+		if !ok {
+			job = &extensionCleanJob{extensionID: storage.ExtensionID}
+			byID[storage.ExtensionID] = job
+			jobs = append(jobs, job)
+		}
+
+		checks, err := ec.performSafetyChecks(storage)
+		if err != nil {
+			checks = &SafetyCheckResult{BlockingIssues: []string{fmt.Sprintf("Safety checks failed: %v", err)}}
+		}
+		job.storages = append(job.storages, storage)
+		job.checks = append(job.checks, *checks)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan *extensionCleanJob)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				jobResult, failed := ec.cleanExtensionJob(job)
+
+				mu.Lock()
+				result.PerExtension[job.extensionID] = jobResult
+				result.TotalItemsRemoved += jobResult.ItemsRemoved
+				result.TotalSizeRemoved += jobResult.TotalSizeRemoved
+				if failed {
+					result.FailedExtensions = append(result.FailedExtensions, job.extensionID)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	sort.Strings(result.FailedExtensions)
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// cleanExtensionJob cleans every storage of an extension and merges the results.
+// Storages failing their safety checks are skipped; failed reports whether any
+// storage could not be cleaned.
+func (ec *ExtensionCleaner) cleanExtensionJob(job *extensionCleanJob) (*ExtensionCleanResult, bool) {
+	startTime := time.Now()
+	merged := &ExtensionCleanResult{
+		ExtensionID:         job.extensionID,
+		CleanedStorageItems: make([]CleanedStorageItem, 0),
+		CleanedCacheFiles:   make([]CleanedCacheFile, 0),
+		CleanedTempFiles:    make([]CleanedTempFile, 0),
+		BackupPaths:         make([]string, 0),
+		Errors:              make([]string, 0),
+		SafetyChecks: SafetyCheckResult{
+			Passed:          true,
+			Warnings:        make([]string, 0),
+			BlockingIssues:  make([]string, 0),
+			BackupVerified:  true,
+			RollbackCapable: true,
+		},
+	}
+
+	failed := false
+	for i, storage := range job.storages {
+		storageResult, err := ec.cleanCheckedExtension(storage, job.checks[i], time.Now())
+		mergeExtensionCleanResult(merged, storageResult)
+		if err != nil {
+			merged.Errors = append(merged.Errors, fmt.Sprintf("%s: %v", storage.StoragePath, err))
+			failed = true
+		}
+	}
+
+	merged.CleanupDuration = time.Since(startTime)
+	return merged, failed
+}
+
+// mergeExtensionCleanResult adds the cleaned items, totals, errors and safety
+// check outcome of src to dst
+func mergeExtensionCleanResult(dst, src *ExtensionCleanResult) {
+	dst.CleanedStorageItems = append(dst.CleanedStorageItems, src.CleanedStorageItems...)
+	dst.CleanedCacheFiles = append(dst.CleanedCacheFiles, src.CleanedCacheFiles...)
+	dst.CleanedTempFiles = append(dst.CleanedTempFiles, src.CleanedTempFiles...)
+	dst.BackupPaths = append(dst.BackupPaths, src.BackupPaths...)
+	dst.TotalSizeRemoved += src.TotalSizeRemoved
+	dst.TelemetrySizeRemoved += src.TelemetrySizeRemoved
+	dst.ItemsRemoved += src.ItemsRemoved
+	dst.Errors = append(dst.Errors, src.Errors...)
+
+	checks := &dst.SafetyChecks
+	checks.Passed = checks.Passed && src.SafetyChecks.Passed
+	checks.Warnings = append(checks.Warnings, src.SafetyChecks.Warnings...)
+	checks.BlockingIssues = append(checks.BlockingIssues, src.SafetyChecks.BlockingIssues...)
+	checks.BackupVerified = checks.BackupVerified && src.SafetyChecks.BackupVerified
+	checks.DependencyCheck = checks.DependencyCheck || src.SafetyChecks.DependencyCheck
+	checks.RollbackCapable = checks.RollbackCapable && src.SafetyChecks.RollbackCapable
+}
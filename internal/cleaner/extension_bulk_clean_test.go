@@ -0,0 +1,121 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+func TestCleanExtensionList(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	items := func(keys ...string) []scanner.StorageDataItem {
+		var result []scanner.StorageDataItem
+		for _, key := range keys {
+			result = append(result, scanner.StorageDataItem{Key: key, Size: 10, Risk: scanner.TelemetryRiskHigh, LastModified: old})
+		}
+		return result
+	}
+
+	storages := []scanner.ExtensionStorage{
+		{ExtensionID: "augment.vscode-augment", StoragePath: t.TempDir(), StorageItems: items("sessionId", "deviceId")},
+		{ExtensionID: "ms-python.python", StoragePath: t.TempDir(), StorageItems: items("telemetry.machineId")},
+		{ExtensionID: "augment.vscode-augment", StoragePath: t.TempDir(), StorageItems: items("workspaceId")},
+		{ExtensionID: "missing.extension", StoragePath: filepath.Join(t.TempDir(), "missing"), StorageItems: items("userId")},
+	}
+
+	policy := GetDefaultRemovalPolicy()
+	policy.CreateBackups = false
+	policy.DryRun = true
+
+	result, err := CleanExtensionList(storages, policy, 2)
+	if err != nil {
+		t.Fatalf("CleanExtensionList() failed: %v", err)
+	}
+
+	if len(result.PerExtension) != 3 {
+		t.Fatalf("Expected 3 extensions, got %d", len(result.PerExtension))
+	}
+	augment := result.PerExtension["augment.vscode-augment"]
+	if augment.ItemsRemoved != 3 || augment.TotalSizeRemoved != 30 {
+		t.Errorf("Expected the global and workspace storage of augment.vscode-augment merged to 3 items and 30 bytes, got %d items and %d bytes",
+			augment.ItemsRemoved, augment.TotalSizeRemoved)
+	}
+	if !augment.SafetyChecks.Passed {
+		t.Errorf("Expected augment.vscode-augment safety checks to pass, got %v", augment.SafetyChecks.BlockingIssues)
+	}
+
+	if len(result.FailedExtensions) != 1 || result.FailedExtensions[0] != "missing.extension" {
+		t.Errorf("Expected only missing.extension to fail, got %v", result.FailedExtensions)
+	}
+	if missing := result.PerExtension["missing.extension"]; missing.SafetyChecks.Passed || missing.ItemsRemoved != 0 {
+		t.Errorf("Expected missing.extension to be blocked by its safety checks, got %+v", missing.SafetyChecks)
+	}
+
+	if result.TotalItemsRemoved != 4 || result.TotalSizeRemoved != 40 {
+		t.Errorf("Expected totals of 4 items and 40 bytes, got %d items and %d bytes", result.TotalItemsRemoved, result.TotalSizeRemoved)
+	}
+}
+
+func TestCleanExtensionListBacksUpEachStorage(t *testing.T) {
+	storages := make([]scanner.ExtensionStorage, 2)
+	for i := range storages {
+		storagePath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(storagePath, "state.json"), []byte(`{"sessionId": "abc"}`), 0644); err != nil {
+			t.Fatalf("Failed to write storage file: %v", err)
+		}
+		storages[i] = scanner.ExtensionStorage{
+			ExtensionID:  "augment.vscode-augment",
+			StoragePath:  storagePath,
+			StorageItems: []scanner.StorageDataItem{{Key: "sessionId", Size: 10, Risk: scanner.TelemetryRiskHigh}},
+		}
+	}
+
+	policy := GetDefaultRemovalPolicy()
+	policy.CreateBackups = true
+	policy.DryRun = true
+	ec := NewExtensionCleaner(policy)
+	ec.backupManager.backupDirectory = t.TempDir()
+	ec.safetyValidator = newProcessValidator()
+
+	job := &extensionCleanJob{extensionID: "augment.vscode-augment"}
+	for _, storage := range storages {
+		checks, err := ec.performSafetyChecks(storage)
+		if err != nil {
+			t.Fatalf("performSafetyChecks() failed: %v", err)
+		}
+		job.storages = append(job.storages, storage)
+		job.checks = append(job.checks, *checks)
+	}
+
+	result, failed := ec.cleanExtensionJob(job)
+	if failed {
+		t.Fatalf("Expected the job to succeed, got %v", result.Errors)
+	}
+	if len(result.BackupPaths) != 2 || result.BackupPaths[0] == result.BackupPaths[1] {
+		t.Fatalf("Expected a separate backup of each storage, got %v", result.BackupPaths)
+	}
+	for _, backupPath := range result.BackupPaths {
+		if _, err := os.Stat(backupPath); err != nil {
+			t.Errorf("Expected backup %s to exist: %v", backupPath, err)
+		}
+	}
+}
+
+func TestCreateBackupKeepsBackupOfSameName(t *testing.T) {
+	manager, _, storage, _ := newMemBackupManager(t)
+
+	first, err := manager.CreateExtensionBackup(storage, "augment-full")
+	if err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	second, err := manager.CreateExtensionBackup(storage, "augment-full")
+	if err != nil {
+		t.Fatalf("CreateExtensionBackup() failed: %v", err)
+	}
+	if want := filepath.Join(manager.backupDirectory, "augment-full-2.zip"); second != want || first == second {
+		t.Errorf("Expected the second backup at %s next to %s, got %s", want, first, second)
+	}
+}
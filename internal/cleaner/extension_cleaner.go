@@ -1,13 +1,18 @@
 package cleaner
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
 )
 
 // ExtensionCleanResult represents the result of extension data cleaning
@@ -61,6 +66,7 @@ type SafetyCheckResult struct {
 	BackupVerified   bool     `json:"backup_verified"`
 	DependencyCheck  bool     `json:"dependency_check"`
 	RollbackCapable  bool     `json:"rollback_capable"`
+	IDEProcesses     []IDEProcess `json:"ide_processes,omitempty"` // Running VS Code main processes and extension hosts
 }
 
 // RemovalPolicy represents policies for data removal
@@ -84,14 +90,21 @@ type ExtensionCleaner struct {
 	backupManager   *BackupManager
 	dependencyChecker *DependencyChecker
 	safetyValidator *SafetyValidator
+	backupMu        sync.Mutex // Serializes backups, which lock the backup directory
 }
 
 // NewExtensionCleaner creates a new extension cleaner
 func NewExtensionCleaner(policy RemovalPolicy) *ExtensionCleaner {
+	return NewExtensionCleanerWithResolver(policy, utils.DefaultPathResolver())
+}
+
+// NewExtensionCleanerWithResolver creates an extension cleaner that checks the
+// dependencies of the extensions installed in the environment described by resolver
+func NewExtensionCleanerWithResolver(policy RemovalPolicy, resolver utils.PathResolver) *ExtensionCleaner {
 	return &ExtensionCleaner{
 		policy:            policy,
 		backupManager:     NewBackupManager(),
-		dependencyChecker: NewDependencyChecker(),
+		dependencyChecker: NewDependencyCheckerWithResolver(resolver),
 		safetyValidator:   NewSafetyValidator(),
 	}
 }
@@ -99,7 +112,18 @@ func NewExtensionCleaner(policy RemovalPolicy) *ExtensionCleaner {
 // CleanExtensionData performs intelligent cleaning of extension data
 func (ec *ExtensionCleaner) CleanExtensionData(extensionStorage scanner.ExtensionStorage) (*ExtensionCleanResult, error) {
 	startTime := time.Now()
-	
+
+	// Perform safety checks
+	safetyResult, err := ec.performSafetyChecks(extensionStorage)
+	if err != nil {
+		return nil, fmt.Errorf("safety checks failed: %w", err)
+	}
+
+	return ec.cleanCheckedExtension(extensionStorage, *safetyResult, startTime)
+}
+
+// cleanCheckedExtension cleans extension data whose safety checks have already run
+func (ec *ExtensionCleaner) cleanCheckedExtension(extensionStorage scanner.ExtensionStorage, safetyResult SafetyCheckResult, startTime time.Time) (*ExtensionCleanResult, error) {
 	result := &ExtensionCleanResult{
 		ExtensionID:         extensionStorage.ExtensionID,
 		CleanedStorageItems: make([]CleanedStorageItem, 0),
@@ -107,15 +131,9 @@ func (ec *ExtensionCleaner) CleanExtensionData(extensionStorage scanner.Extensio
 		CleanedTempFiles:    make([]CleanedTempFile, 0),
 		BackupPaths:         make([]string, 0),
 		Errors:              make([]string, 0),
+		SafetyChecks:        safetyResult,
 	}
 
-	// Perform safety checks
-	safetyResult, err := ec.performSafetyChecks(extensionStorage)
-	if err != nil {
-		return nil, fmt.Errorf("safety checks failed: %w", err)
-	}
-	result.SafetyChecks = *safetyResult
-
 	if !safetyResult.Passed {
 		return result, fmt.Errorf("safety checks failed, aborting cleanup")
 	}
@@ -166,6 +184,17 @@ func (ec *ExtensionCleaner) performSafetyChecks(extensionStorage scanner.Extensi
 		result.Passed = false
 	}
 
+	// Check that only one VS Code window can write to the storage
+	processes, err := ec.safetyValidator.CheckForRunningIDEProcesses()
+	result.IDEProcesses = processes
+	var multiple *MultipleIDEWindowsError
+	if errors.As(err, &multiple) {
+		result.BlockingIssues = append(result.BlockingIssues, multiple.Error())
+		result.Passed = false
+	} else if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("VS Code process check failed: %v", err))
+	}
+
 	// Check for critical dependencies
 	dependencies, err := ec.dependencyChecker.CheckDependencies(extensionStorage.ExtensionID)
 	if err != nil {
@@ -204,10 +233,15 @@ func (ec *ExtensionCleaner) performSafetyChecks(extensionStorage scanner.Extensi
 
 // createExtensionBackup creates a comprehensive backup of extension data
 func (ec *ExtensionCleaner) createExtensionBackup(extensionStorage scanner.ExtensionStorage) (string, error) {
-	timestamp := time.Now().Unix()
-	backupName := fmt.Sprintf("%s-backup-%d", 
-		strings.ReplaceAll(extensionStorage.ExtensionID, ".", "-"), 
-		timestamp)
+	// The global and workspace storages of one extension are backed up in the
+	// same run, so the name also carries a hash of the storage path
+	pathHash := sha256.Sum256([]byte(extensionStorage.StoragePath))
+	backupName := fmt.Sprintf("%s-backup-%d-%s",
+		strings.ReplaceAll(extensionStorage.ExtensionID, ".", "-"),
+		time.Now().UnixNano(), hex.EncodeToString(pathHash[:4]))
+
+	ec.backupMu.Lock()
+	defer ec.backupMu.Unlock()
 
 	backupPath, err := ec.backupManager.CreateExtensionBackup(extensionStorage, backupName)
 	if err != nil {
@@ -332,7 +366,9 @@ func (ec *ExtensionCleaner) removeStorageItem(item scanner.StorageDataItem, resu
 
 	// Create individual backup if needed
 	if ec.policy.CreateBackups {
+		ec.backupMu.Lock()
 		backupPath, err := ec.backupManager.BackupStorageItem(item)
+		ec.backupMu.Unlock()
 		if err != nil {
 			return fmt.Errorf("failed to backup item: %w", err)
 		}
@@ -439,6 +475,24 @@ func GetAggressiveRemovalPolicy() RemovalPolicy {
 	}
 }
 
+// GetQuickRemovalPolicy returns the policy of a quick clean: only critical-risk
+// data, regardless of age, always backed up first
+func GetQuickRemovalPolicy() RemovalPolicy {
+	return RemovalPolicy{
+		MinRiskLevel:        scanner.TelemetryRiskCritical,
+		MaxFileAge:          0,
+		MaxFileSize:         0,
+		PreserveRecent:      false,
+		RecentThreshold:     0,
+		CreateBackups:       true,
+		VerifyBackups:       true,
+		DryRun:              false,
+		RequireConfirmation: true,
+		ExcludePatterns:     []string{},
+		IncludePatterns:     []string{},
+	}
+}
+
 // GetConservativeRemovalPolicy returns a conservative removal policy
 func GetConservativeRemovalPolicy() RemovalPolicy {
 	return RemovalPolicy{
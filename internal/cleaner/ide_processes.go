@@ -0,0 +1,204 @@
+package cleaner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// vscodeExecutableNames are the executable names of VS Code builds, without .exe
+var vscodeExecutableNames = []string{"code", "code-insiders", "code - insiders", "codium", "vscodium", "code-oss", "cursor"}
+
+// vscodeAppBundles are the macOS app bundles of VS Code builds, whose processes
+// are named Electron and Code Helper
+var vscodeAppBundles = []string{"/visual studio code.app/", "/visual studio code - insiders.app/", "/vscodium.app/", "/cursor.app/"}
+
+// vscodeValueFlags are VS Code flags whose value is passed as the next argument
+var vscodeValueFlags = map[string]bool{
+	"--user-data-dir":  true,
+	"--extensions-dir": true,
+	"--profile":        true,
+	"--locale":         true,
+	"--log":            true,
+	"--sync":           true,
+}
+
+// IDEProcess is a running VS Code main process or extension host
+type IDEProcess struct {
+	PID            int    `json:"pid"`
+	ExecutablePath string `json:"executable_path"`
+	// OpenWorkspace is the folder or workspace file from the command line; empty
+	// when the process was started without one, as extension hosts are
+	OpenWorkspace   string `json:"open_workspace,omitempty"`
+	IsExtensionHost bool   `json:"is_extension_host"`
+}
+
+// MultipleIDEWindowsError is returned by CheckForRunningIDEProcesses when more than
+// one VS Code window or instance is running
+type MultipleIDEWindowsError struct {
+	Processes []IDEProcess
+}
+
+// Error lists each process with its open workspace
+func (e *MultipleIDEWindowsError) Error() string {
+	lines := make([]string, 0, len(e.Processes))
+	for _, process := range e.Processes {
+		kind := "VS Code"
+		if process.IsExtensionHost {
+			kind = "extension host"
+		}
+		workspace := process.OpenWorkspace
+		if workspace == "" {
+			workspace = "workspace unknown"
+		}
+		lines = append(lines, fmt.Sprintf("  %s pid %d (%s): %s", kind, process.PID, process.ExecutablePath, workspace))
+	}
+	return fmt.Sprintf("multiple VS Code windows are open and may write to the same database; close all but one (or all) of them:\n%s",
+		strings.Join(lines, "\n"))
+}
+
+// CheckForRunningIDEProcesses returns the running VS Code main processes and
+// extension hosts. Every VS Code window has its own extension host, so more than
+// one extension host, or more than one main process (separate instances), means
+// several windows may write to the same files while they are cleaned; the
+// processes are then also returned in a *MultipleIDEWindowsError. When the command
+// line of a process cannot be read, e.g. of other users' processes, it only counts
+// as a main process if its parent is not a VS Code process; its extension hosts
+// cannot be told apart from other helpers then.
+func (sv *SafetyValidator) CheckForRunningIDEProcesses() ([]IDEProcess, error) {
+	procs, err := sv.listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	vscodePIDs := make(map[int]bool)
+	for _, proc := range procs {
+		if isVSCodeProcess(proc) {
+			vscodePIDs[proc.PID] = true
+		}
+	}
+
+	var processes []IDEProcess
+	mainCount, hostCount := 0, 0
+	for _, proc := range procs {
+		if !vscodePIDs[proc.PID] {
+			continue
+		}
+
+		if len(proc.CmdLine) == 0 {
+			if proc.PPID == 0 || vscodePIDs[proc.PPID] {
+				continue // A helper or extension host, or a process whose parent is unknown
+			}
+			exe := proc.Exe
+			if exe == "" {
+				exe = proc.Name
+			}
+			processes = append(processes, IDEProcess{PID: proc.PID, ExecutablePath: exe})
+			mainCount++
+			continue
+		}
+
+		processType := commandLineFlag(proc.CmdLine, "--type")
+		isHost := processType == "extensionHost" || commandLineFlag(proc.CmdLine, "--vscode-utility-kind") == "extensionHost"
+		if processType != "" && !isHost {
+			continue // Renderer, GPU and other helper processes
+		}
+		if !isHost && hasArgument(proc.CmdLine, "--ms-enable-electron-run-as-node") {
+			continue // The code command line interface and other Node.js helpers
+		}
+
+		exe := proc.Exe
+		if exe == "" {
+			exe = proc.CmdLine[0]
+		}
+		processes = append(processes, IDEProcess{
+			PID:             proc.PID,
+			ExecutablePath:  exe,
+			OpenWorkspace:   commandLineWorkspace(proc.CmdLine),
+			IsExtensionHost: isHost,
+		})
+		if isHost {
+			hostCount++
+		} else {
+			mainCount++
+		}
+	}
+
+	if mainCount > 1 || hostCount > 1 {
+		return processes, &MultipleIDEWindowsError{Processes: processes}
+	}
+	return processes, nil
+}
+
+// isVSCodeProcess reports whether a process runs a VS Code executable
+func isVSCodeProcess(proc utils.ProcessInfo) bool {
+	exe := strings.ToLower(strings.ReplaceAll(proc.Exe, `\`, "/"))
+	for _, bundle := range vscodeAppBundles {
+		if strings.Contains(exe, bundle) {
+			return true
+		}
+	}
+
+	name := proc.Name
+	if exe != "" {
+		name = exe[strings.LastIndex(exe, "/")+1:]
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	for _, executable := range vscodeExecutableNames {
+		if name == executable {
+			return true
+		}
+	}
+	return false
+}
+
+// commandLineFlag returns the value of a --flag=value or --flag value argument
+func commandLineFlag(args []string, flag string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, flag+"="); ok {
+			return value
+		}
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// hasArgument reports whether args contain arg
+func hasArgument(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// commandLineWorkspace returns the folder or workspace a VS Code process was
+// opened with: a --folder-uri or --file-uri argument, else the last argument
+// that is not a flag
+func commandLineWorkspace(args []string) string {
+	for _, flag := range []string{"--folder-uri", "--file-uri"} {
+		if uri := commandLineFlag(args, flag); uri != "" {
+			if parsed, err := url.Parse(uri); err == nil && parsed.Scheme == "file" {
+				return parsed.Path
+			}
+			return uri
+		}
+	}
+
+	for i := len(args) - 1; i > 0; i-- {
+		arg := args[i]
+		if arg == "" || strings.HasPrefix(arg, "-") || strings.HasSuffix(arg, ".js") {
+			continue
+		}
+		if vscodeValueFlags[args[i-1]] {
+			continue
+		}
+		return arg
+	}
+	return ""
+}
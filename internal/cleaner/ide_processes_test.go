@@ -0,0 +1,114 @@
+package cleaner
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// newProcessValidator returns a safety validator that sees procs as the running processes
+func newProcessValidator(procs ...utils.ProcessInfo) *SafetyValidator {
+	validator := NewSafetyValidator()
+	validator.listProcesses = func() ([]utils.ProcessInfo, error) { return procs, nil }
+	return validator
+}
+
+func TestCheckForRunningIDEProcesses(t *testing.T) {
+	main := utils.ProcessInfo{PID: 100, Name: "code", Exe: "/usr/share/code/code",
+		CmdLine: []string{"/usr/share/code/code", "--user-data-dir", "/tmp/profile", "/home/dev/project", ""}}
+	renderer := utils.ProcessInfo{PID: 101, Name: "code", Exe: "/usr/share/code/code",
+		CmdLine: []string{"/usr/share/code/code", "--type=renderer"}}
+	host := utils.ProcessInfo{PID: 102, Name: "code", Exe: "/usr/share/code/code",
+		CmdLine: []string{"/usr/share/code/code", "--ms-enable-electron-run-as-node", "bootstrap-fork.js", "--type=extensionHost"}}
+	cli := utils.ProcessInfo{PID: 103, Name: "code", Exe: "/usr/share/code/code",
+		CmdLine: []string{"/usr/share/code/code", "/usr/share/code/resources/app/out/cli.js", "--ms-enable-electron-run-as-node", "."}}
+	unreadable := utils.ProcessInfo{PID: 104, Name: "Code.exe", Exe: `C:\Program Files\Microsoft VS Code\Code.exe`}
+	editor := utils.ProcessInfo{PID: 105, Name: "vim", Exe: "/usr/bin/vim", CmdLine: []string{"vim", "code"}}
+
+	processes, err := newProcessValidator(main, renderer, host, cli, unreadable, editor).CheckForRunningIDEProcesses()
+	if err != nil {
+		t.Fatalf("Expected a single window to pass, got %v", err)
+	}
+	want := []IDEProcess{
+		{PID: 100, ExecutablePath: "/usr/share/code/code", OpenWorkspace: "/home/dev/project"},
+		{PID: 102, ExecutablePath: "/usr/share/code/code", IsExtensionHost: true},
+	}
+	if !reflect.DeepEqual(processes, want) {
+		t.Errorf("Expected processes %+v, got %+v", want, processes)
+	}
+}
+
+func TestCheckForRunningIDEProcessesMultipleWindows(t *testing.T) {
+	insiders := utils.ProcessInfo{PID: 200, Exe: "/Applications/Visual Studio Code - Insiders.app/Contents/MacOS/Electron",
+		CmdLine: []string{"/Applications/Visual Studio Code - Insiders.app/Contents/MacOS/Electron", "--folder-uri=file:///Users/dev/api%20server"}}
+	stable := utils.ProcessInfo{PID: 300, Name: "code", Exe: "/usr/share/code/code",
+		CmdLine: []string{"/usr/share/code/code", "--new-window", "/home/dev/web"}}
+
+	processes, err := newProcessValidator(insiders, stable).CheckForRunningIDEProcesses()
+	var multiple *MultipleIDEWindowsError
+	if !errors.As(err, &multiple) {
+		t.Fatalf("Expected a MultipleIDEWindowsError, got %v", err)
+	}
+	if len(processes) != 2 || !reflect.DeepEqual(multiple.Processes, processes) {
+		t.Errorf("Expected both processes in the result and error, got %+v and %+v", processes, multiple.Processes)
+	}
+	for _, want := range []string{"pid 200", "/Users/dev/api server", "pid 300", "/home/dev/web"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %q", want, err.Error())
+		}
+	}
+
+	hosts := []utils.ProcessInfo{
+		{PID: 1, Name: "code", CmdLine: []string{"code", "--type=extensionHost"}},
+		{PID: 2, Name: "code", CmdLine: []string{"code", "--type=extensionHost"}},
+	}
+	if _, err := newProcessValidator(hosts...).CheckForRunningIDEProcesses(); !errors.As(err, &multiple) {
+		t.Errorf("Expected two extension hosts to be reported as two windows, got %v", err)
+	}
+}
+
+func TestCheckForRunningIDEProcessesWithoutCommandLines(t *testing.T) {
+	const exe = `C:\Program Files\Microsoft VS Code\Code.exe`
+	explorer := utils.ProcessInfo{PID: 10, Name: "explorer.exe"}
+	main := utils.ProcessInfo{PID: 100, PPID: 10, Name: "Code.exe", Exe: exe}
+	renderer := utils.ProcessInfo{PID: 101, PPID: 100, Name: "Code.exe", Exe: exe}
+	host := utils.ProcessInfo{PID: 102, PPID: 100, Name: "Code.exe", Exe: exe}
+
+	processes, err := newProcessValidator(explorer, main, renderer, host).CheckForRunningIDEProcesses()
+	if err != nil {
+		t.Fatalf("Expected a single instance to pass, got %v", err)
+	}
+	if want := []IDEProcess{{PID: 100, ExecutablePath: exe}}; !reflect.DeepEqual(processes, want) {
+		t.Errorf("Expected only the main process %+v, got %+v", want, processes)
+	}
+
+	// A second instance started from Explorer is another main process
+	second := utils.ProcessInfo{PID: 200, PPID: 10, Name: "Code.exe", Exe: exe}
+	var multiple *MultipleIDEWindowsError
+	if _, err := newProcessValidator(explorer, main, renderer, host, second).CheckForRunningIDEProcesses(); !errors.As(err, &multiple) {
+		t.Errorf("Expected two main processes to be reported as two windows, got %v", err)
+	}
+}
+
+func TestPerformSafetyChecksBlocksMultipleIDEWindows(t *testing.T) {
+	ec := NewExtensionCleaner(GetDefaultRemovalPolicy())
+	ec.safetyValidator = newProcessValidator(
+		utils.ProcessInfo{PID: 1, Name: "code", CmdLine: []string{"code", "/work/a"}},
+		utils.ProcessInfo{PID: 2, Name: "codium", CmdLine: []string{"codium", "/work/b"}},
+	)
+
+	result, err := ec.performSafetyChecks(scanner.ExtensionStorage{ExtensionID: "acme.ext", StoragePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("performSafetyChecks() failed: %v", err)
+	}
+	if result.Passed || len(result.IDEProcesses) != 2 {
+		t.Errorf("Expected the check to fail with both processes, got %+v", result)
+	}
+	if len(result.BlockingIssues) == 0 || !strings.Contains(result.BlockingIssues[0], "/work/b") {
+		t.Errorf("Expected a blocking issue listing the windows, got %v", result.BlockingIssues)
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 
+	"augment-telemetry-cleaner/internal/filelock"
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/sanitize"
 	"augment-telemetry-cleaner/internal/utils"
+	"augment-telemetry-cleaner/internal/vfs"
 )
 
 // TelemetryModifyResult contains the results of telemetry ID modification
@@ -16,6 +21,29 @@ type TelemetryModifyResult struct {
 	NewDeviceID          string `json:"new_device_id"`
 	StorageBackupPath    string `json:"storage_backup_path"`
 	MachineIDBackupPath  string `json:"machine_id_backup_path,omitempty"`
+	AuditFilePath        string `json:"audit_file_path,omitempty"`
+	IDFormat             string `json:"id_format"`
+	VSCodeVersion        string `json:"vscode_version,omitempty"`
+	// SyncDetected is set when VS Code Settings Sync is on or has synced this installation
+	SyncDetected         bool `json:"sync_detected"`
+	// SyncFilesUpdated lists the Settings Sync metadata files the new IDs were written to
+	SyncFilesUpdated     []string `json:"sync_files_updated,omitempty"`
+	SyncBackupPaths      []string `json:"sync_backup_paths,omitempty"`
+}
+
+// TelemetryModifyOptions controls optional behaviour of telemetry ID modification
+type TelemetryModifyOptions struct {
+	// AuditKey enables writing a signed old->new ID mapping file when non-empty
+	AuditKey []byte
+	// IncludePlaintext records raw IDs in the audit file instead of hashes only
+	IncludePlaintext bool
+	// Logger receives DEBUG traces of every file written; IDs are sanitized. May be nil
+	Logger logger.Leveled
+	// Resolver locates storage.json and the machine ID file; nil uses the current user's
+	Resolver utils.PathResolver
+	// UpdateSyncFiles also replaces the old IDs in the Settings Sync metadata under
+	// User/sync, so the next sync uploads the new IDs instead of restoring the old ones
+	UpdateSyncFiles bool
 }
 
 // ModifyTelemetryIDs modifies the telemetry IDs in the VS Code storage.json file and machine ID file
@@ -24,17 +52,27 @@ type TelemetryModifyResult struct {
 // This function:
 // 1. Creates backups of the storage.json and machine ID files
 // 2. Reads the storage.json file
-// 3. Generates new machine and device IDs
+// 3. Generates new machine and device IDs, keeping the machine ID format of the installed VS Code
 // 4. Updates the telemetry.machineId and telemetry.devDeviceId values in storage.json
 // 5. Updates the machine ID file with the new machine ID
 // 6. Saves the modified files
 func ModifyTelemetryIDs() (*TelemetryModifyResult, error) {
-	storagePath, err := utils.GetStoragePath()
+	return ModifyTelemetryIDsWithOptions(TelemetryModifyOptions{})
+}
+
+// ModifyTelemetryIDsWithOptions modifies the telemetry IDs like ModifyTelemetryIDs and,
+// when an audit key is provided, writes an HMAC-signed audit file next to the backups
+func ModifyTelemetryIDsWithOptions(opts TelemetryModifyOptions) (*TelemetryModifyResult, error) {
+	log := logger.OrDiscard(opts.Logger)
+
+	paths := utils.NewVSCodePaths(opts.Resolver)
+
+	storagePath, err := paths.StoragePath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get storage path: %w", err)
 	}
 
-	machineIDPath, err := utils.GetMachineIDPath()
+	machineIDPath, err := paths.MachineIDPath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get machine ID path: %w", err)
 	}
@@ -44,6 +82,14 @@ func ModifyTelemetryIDs() (*TelemetryModifyResult, error) {
 		return nil, fmt.Errorf("storage file not found at: %s", storagePath)
 	}
 
+	// Lock storage.json so concurrent runs cannot interleave backup and rewrite. The
+	// lock is taken on a sidecar file, as the atomic write replaces storage.json.
+	unlock, err := filelock.LockSidecar(storagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock storage file: %w", err)
+	}
+	defer unlock()
+
 	// Create backup of storage.json
 	storageBackupPath, err := utils.CreateBackup(storagePath)
 	if err != nil {
@@ -74,8 +120,11 @@ func ModifyTelemetryIDs() (*TelemetryModifyResult, error) {
 	oldMachineID, _ := jsonData["telemetry.machineId"].(string)
 	oldDeviceID, _ := jsonData["telemetry.devDeviceId"].(string)
 
-	// Generate new IDs
-	newMachineID, err := utils.GenerateMachineID()
+	// Generate new IDs in the format VS Code expects for this installation
+	vscodeVersion, _ := utils.DetectVSCodeVersion() // Unknown version falls back to the current ID's format or hex
+	idFormat := selectMachineIDFormat(oldMachineID, vscodeVersion)
+
+	newMachineID, err := generateMachineID(idFormat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate machine ID: %w", err)
 	}
@@ -92,21 +141,102 @@ func ModifyTelemetryIDs() (*TelemetryModifyResult, error) {
 		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	if err := os.WriteFile(storagePath, modifiedData, 0644); err != nil {
+	if err := utils.WriteFileAtomic(storagePath, modifiedData, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write storage file: %w", err)
 	}
+	log.Debug("Wrote %s: telemetry.machineId %s -> %s", storagePath, sanitize.String(oldMachineID), sanitize.String(newMachineID))
+	log.Debug("Wrote %s: telemetry.devDeviceId %s -> %s", storagePath, sanitize.String(oldDeviceID), sanitize.String(newDeviceID))
 
 	// Write the new device ID to the machine ID file
-	if err := os.WriteFile(machineIDPath, []byte(newDeviceID), 0644); err != nil {
+	if err := utils.WriteFileAtomic(machineIDPath, []byte(newDeviceID), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write machine ID file: %w", err)
 	}
+	log.Debug("Wrote %s: %s", machineIDPath, sanitize.String(newDeviceID))
 
-	return &TelemetryModifyResult{
+	result := &TelemetryModifyResult{
 		OldMachineID:        oldMachineID,
 		NewMachineID:        newMachineID,
 		OldDeviceID:         oldDeviceID,
 		NewDeviceID:         newDeviceID,
 		StorageBackupPath:   storageBackupPath,
 		MachineIDBackupPath: machineIDBackupPath,
-	}, nil
+		IDFormat:            idFormat,
+		VSCodeVersion:       vscodeVersion,
+	}
+
+	// Write the signed audit mapping if requested
+	if len(opts.AuditKey) > 0 {
+		record := newTelemetryAuditRecord(result, storagePath, machineIDPath, opts.IncludePlaintext)
+		auditPath, err := writeTelemetryAuditFile(record, opts.AuditKey)
+		if err != nil {
+			return result, fmt.Errorf("telemetry IDs modified but failed to write audit file: %w", err)
+		}
+		result.AuditFilePath = auditPath
+	}
+
+	// Settings Sync can restore the old IDs unless its metadata is updated too
+	syncState := DetectSettingsSync(opts.Resolver)
+	result.SyncDetected = syncState.Detected()
+	if opts.UpdateSyncFiles && syncState.SyncDir != "" {
+		updated, backups, err := rotateSyncMetadataIDs(syncState.SyncDir, map[string]string{
+			oldMachineID: newMachineID,
+			oldDeviceID:  newDeviceID,
+		})
+		result.SyncFilesUpdated = updated
+		result.SyncBackupPaths = backups
+		if err != nil {
+			return result, fmt.Errorf("telemetry IDs modified but failed to update settings sync files: %w", err)
+		}
+		for _, path := range updated {
+			log.Debug("Wrote %s: replaced telemetry IDs", path)
+		}
+	}
+
+	return result, nil
+}
+
+// PatchJSONFile removes the top-level keys in removeKeys from the JSON (or JSONC)
+// file at path and returns the keys it removed. The file is edited as text, so
+// the remaining keys keep their formatting; it is not written when no key is found.
+func PatchJSONFile(fsys vfs.FileSystem, path string, removeKeys []string) ([]string, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+	original, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+	members, err := parseJSONCMembers(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON file: %w", err)
+	}
+
+	remove := make(map[string]bool, len(removeKeys))
+	for _, key := range removeKeys {
+		remove[key] = true
+	}
+
+	// Edit from the end so that the offsets of earlier members stay valid
+	var removed []string
+	edited := original
+	for i := len(members) - 1; i >= 0; i-- {
+		if remove[members[i].key] {
+			edited = removeJSONCMember(edited, members, i)
+			removed = appendUnique(removed, members[i].key)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(removed)
+
+	var check map[string]interface{}
+	if err := parseJSONC(edited, &check); err != nil {
+		return nil, fmt.Errorf("patched JSON file is not valid JSON: %w", err)
+	}
+	if err := fsys.WriteFile(path, edited, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write JSON file: %w", err)
+	}
+	return removed, nil
 }
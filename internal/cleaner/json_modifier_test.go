@@ -0,0 +1,156 @@
+package cleaner
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestModifyTelemetryIDsWithResolver(t *testing.T) {
+	tests := []struct {
+		os          string
+		storagePath []string
+		machineID   []string
+	}{
+		{"windows", []string{"AppData", "Roaming", "Code", "User", "globalStorage", "storage.json"}, []string{"AppData", "Roaming", "Code", "User", "machineid"}},
+		{"darwin", []string{"Library", "Application Support", "Code", "User", "globalStorage", "storage.json"}, []string{"Library", "Application Support", "Code", "machineid"}},
+		{"linux", []string{".config", "Code", "User", "globalStorage", "storage.json"}, []string{".config", "Code", "User", "machineid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.os, func(t *testing.T) {
+			home := t.TempDir()
+			storagePath := filepath.Join(append([]string{home}, tt.storagePath...)...)
+			machineIDPath := filepath.Join(append([]string{home}, tt.machineID...)...)
+
+			if err := os.MkdirAll(filepath.Dir(storagePath), 0755); err != nil {
+				t.Fatalf("Failed to create storage directory: %v", err)
+			}
+			if err := os.WriteFile(storagePath, []byte(`{"telemetry.machineId": "old", "telemetry.devDeviceId": "old-device"}`), 0644); err != nil {
+				t.Fatalf("Failed to write storage.json: %v", err)
+			}
+
+			result, err := ModifyTelemetryIDsWithOptions(TelemetryModifyOptions{
+				Resolver: utils.FakePathResolver{Home: home, OS: tt.os},
+			})
+			if err != nil {
+				t.Fatalf("ModifyTelemetryIDsWithOptions failed: %v", err)
+			}
+
+			data, err := os.ReadFile(storagePath)
+			if err != nil {
+				t.Fatalf("Failed to read storage.json: %v", err)
+			}
+			var storage map[string]interface{}
+			if err := json.Unmarshal(data, &storage); err != nil {
+				t.Fatalf("Failed to parse storage.json: %v", err)
+			}
+			if storage["telemetry.machineId"] != result.NewMachineID || result.NewMachineID == "old" {
+				t.Errorf("Expected machine ID %s in storage.json, got %v", result.NewMachineID, storage["telemetry.machineId"])
+			}
+
+			machineID, err := os.ReadFile(machineIDPath)
+			if err != nil || string(machineID) != result.NewDeviceID {
+				t.Errorf("Expected device ID %s in %s, got %q (%v)", result.NewDeviceID, machineIDPath, machineID, err)
+			}
+		})
+	}
+}
+
+func TestModifyTelemetryIDsUpdatesSettingsSync(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	userDir := filepath.Join(home, ".config", "Code", "User")
+	storagePath := filepath.Join(userDir, "globalStorage", "storage.json")
+	syncPath := filepath.Join(userDir, "sync", "globalState", "lastSyncglobalState.json")
+	for _, dir := range []string{filepath.Dir(storagePath), filepath.Dir(syncPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	const oldMachineID, oldDeviceID = "0123456789abcdef", "11111111-2222-4333-8444-555555555555"
+	writeStorage := func() {
+		t.Helper()
+		content := `{"telemetry.machineId": "` + oldMachineID + `", "telemetry.devDeviceId": "` + oldDeviceID + `"}`
+		if err := os.WriteFile(storagePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write storage.json: %v", err)
+		}
+	}
+	writeStorage()
+	syncContent := `{"storage": {"telemetry.machineId": "` + oldMachineID + `", "telemetry.devDeviceId": "` + oldDeviceID + `"}}`
+	if err := os.WriteFile(syncPath, []byte(syncContent), 0644); err != nil {
+		t.Fatalf("Failed to write sync metadata: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(syncPath), "unrelated.json"), []byte(`{"theme": "dark"}`), 0644); err != nil {
+		t.Fatalf("Failed to write sync metadata: %v", err)
+	}
+
+	// Without UpdateSyncFiles sync is only reported
+	result, err := ModifyTelemetryIDsWithOptions(TelemetryModifyOptions{Resolver: resolver})
+	if err != nil {
+		t.Fatalf("ModifyTelemetryIDsWithOptions failed: %v", err)
+	}
+	if !result.SyncDetected || len(result.SyncFilesUpdated) != 0 {
+		t.Errorf("Expected sync to be detected without updating files, got %+v", result)
+	}
+	if data, _ := os.ReadFile(syncPath); string(data) != syncContent {
+		t.Errorf("Expected sync metadata to be left alone, got %s", data)
+	}
+
+	writeStorage()
+	result, err = ModifyTelemetryIDsWithOptions(TelemetryModifyOptions{Resolver: resolver, UpdateSyncFiles: true})
+	if err != nil {
+		t.Fatalf("ModifyTelemetryIDsWithOptions failed: %v", err)
+	}
+	if len(result.SyncFilesUpdated) != 1 || result.SyncFilesUpdated[0] != syncPath || len(result.SyncBackupPaths) != 1 {
+		t.Fatalf("Expected only %s to be updated, got %+v", syncPath, result)
+	}
+	data, err := os.ReadFile(syncPath)
+	if err != nil {
+		t.Fatalf("Failed to read sync metadata: %v", err)
+	}
+	if strings.Contains(string(data), oldMachineID) || strings.Contains(string(data), oldDeviceID) ||
+		!strings.Contains(string(data), result.NewMachineID) || !strings.Contains(string(data), result.NewDeviceID) {
+		t.Errorf("Expected the new IDs in sync metadata, got %s", data)
+	}
+	if backup, err := os.ReadFile(result.SyncBackupPaths[0]); err != nil || string(backup) != syncContent {
+		t.Errorf("Expected a backup of the original sync metadata, got %q (%v)", backup, err)
+	}
+}
+
+func TestDetectSettingsSyncFromStateDatabase(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	if DetectSettingsSync(resolver).Detected() {
+		t.Error("Expected no settings sync without a state database or sync directory")
+	}
+
+	dbPath := filepath.Join(home, ".config", "Code", "User", "globalStorage", "state.vscdb")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	for _, value := range []string{"false", "true"} {
+		if _, err := db.Exec("INSERT INTO ItemTable VALUES (?, ?)", settingsSyncEnableKey, value); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+		state := DetectSettingsSync(resolver)
+		if state.Enabled != (value == "true") || state.SyncDir != "" {
+			t.Errorf("Expected sync.enable=%s to be detected, got %+v", value, state)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package cleaner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// VS Code settings files are JSONC: JSON with // and /* */ comments and trailing
+// commas. The functions below edit them as text so that everything not being
+// changed, comments and formatting included, is kept byte for byte.
+
+// jsoncMember is a member of the top-level object of a JSONC document
+type jsoncMember struct {
+	key        string
+	start      int // Offset of the key's opening quote
+	valueStart int // Offset of the value's first byte
+	end        int // Offset just past the value
+}
+
+// parseJSONC parses a JSONC document into v
+func parseJSONC(data []byte, v interface{}) error {
+	return json.Unmarshal(stripJSONC(data), v)
+}
+
+// stripJSONC returns data with comments blanked out and trailing commas removed,
+// leaving plain JSON at the same offsets
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	for i := 0; i < len(data); {
+		switch {
+		case data[i] == '"':
+			i = scanJSONCString(data, i)
+		case isJSONCComment(data, i):
+			end := skipJSONCComment(data, i)
+			for j := i; j < end; j++ {
+				if out[j] != '\n' && out[j] != '\r' {
+					out[j] = ' '
+				}
+			}
+			i = end
+		case data[i] == ',':
+			if next := skipJSONCSpace(data, i+1); next < len(data) && (data[next] == '}' || data[next] == ']') {
+				out[i] = ' '
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+// parseJSONCMembers returns the members of the top-level object of data in order.
+// A key set more than once is returned once per occurrence.
+func parseJSONCMembers(data []byte) ([]jsoncMember, error) {
+	pos := skipJSONCSpace(data, 0)
+	if pos >= len(data) || data[pos] != '{' {
+		return nil, fmt.Errorf("settings are not a JSON object")
+	}
+	pos++
+
+	var members []jsoncMember
+	for {
+		pos = skipJSONCSpace(data, pos)
+		if pos >= len(data) {
+			return nil, fmt.Errorf("unterminated settings object")
+		}
+		if data[pos] == '}' {
+			if rest := skipJSONCSpace(data, pos+1); rest < len(data) {
+				return nil, fmt.Errorf("unexpected content after settings object at offset %d", rest)
+			}
+			return members, nil
+		}
+		if data[pos] != '"' {
+			return nil, fmt.Errorf("expected a setting key at offset %d", pos)
+		}
+
+		member := jsoncMember{start: pos}
+		keyEnd := scanJSONCString(data, pos)
+		if err := json.Unmarshal(data[pos:keyEnd], &member.key); err != nil {
+			return nil, fmt.Errorf("invalid setting key at offset %d: %w", pos, err)
+		}
+
+		pos = skipJSONCSpace(data, keyEnd)
+		if pos >= len(data) || data[pos] != ':' {
+			return nil, fmt.Errorf("expected ':' after setting %q", member.key)
+		}
+		member.valueStart = skipJSONCSpace(data, pos+1)
+		member.end = scanJSONCValue(data, member.valueStart)
+		if member.end == member.valueStart {
+			return nil, fmt.Errorf("missing value for setting %q", member.key)
+		}
+		members = append(members, member)
+
+		pos = skipJSONCSpace(data, member.end)
+		if pos < len(data) && data[pos] == ',' {
+			pos++
+		} else if pos < len(data) && data[pos] != '}' {
+			return nil, fmt.Errorf("expected ',' or '}' after setting %q", member.key)
+		}
+	}
+}
+
+// removeJSONCMember removes members[i] from data together with its comma, a
+// comment following it on the same line and, when it had a line of its own,
+// that line
+func removeJSONCMember(data []byte, members []jsoncMember, i int) []byte {
+	member := members[i]
+	start, end := member.start, member.end
+
+	// The last member without a trailing comma takes the previous member's comma
+	prevComma := -1
+	if comma := skipJSONCSpace(data, end); comma < len(data) && data[comma] == ',' {
+		end = comma + 1
+	} else if i > 0 {
+		prevComma = skipJSONCSpace(data, members[i-1].end)
+	}
+
+	lineStart := start
+	for lineStart > 0 && (data[lineStart-1] == ' ' || data[lineStart-1] == '\t') {
+		lineStart--
+	}
+	ownLine := lineStart == 0 || data[lineStart-1] == '\n'
+
+	rest := end
+	for rest < len(data) && (data[rest] == ' ' || data[rest] == '\t') {
+		rest++
+	}
+	if rest+1 < len(data) && data[rest] == '/' && data[rest+1] == '/' {
+		for rest < len(data) && data[rest] != '\n' && data[rest] != '\r' {
+			rest++
+		}
+	}
+	atLineEnd := rest == len(data) || data[rest] == '\n' || data[rest] == '\r'
+
+	switch {
+	case ownLine && atLineEnd:
+		start = lineStart
+		end = rest
+		if end < len(data) && data[end] == '\r' {
+			end++
+		}
+		if end < len(data) && data[end] == '\n' {
+			end++
+		}
+	case prevComma >= 0:
+		start = lineStart // Drop the space before a last member sharing its line
+		end = rest
+	default:
+		end = rest // Drop the space after a member's comma
+	}
+
+	edited := make([]byte, 0, len(data)-(end-start))
+	if prevComma >= 0 {
+		edited = append(edited, data[:prevComma]...)
+		edited = append(edited, data[prevComma+1:start]...)
+	} else {
+		edited = append(edited, data[:start]...)
+	}
+	return append(edited, data[end:]...)
+}
+
+// replaceJSONCValue replaces the value of member in data with value
+func replaceJSONCValue(data []byte, member jsoncMember, value interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value of %s: %w", member.key, err)
+	}
+
+	edited := make([]byte, 0, len(data)+len(encoded))
+	edited = append(edited, data[:member.valueStart]...)
+	edited = append(edited, encoded...)
+	return append(edited, data[member.end:]...), nil
+}
+
+// scanJSONCString returns the offset just past the string starting at data[pos]
+func scanJSONCString(data []byte, pos int) int {
+	for i := pos + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// scanJSONCValue returns the offset just past the value starting at data[pos]
+func scanJSONCValue(data []byte, pos int) int {
+	if pos >= len(data) {
+		return pos
+	}
+
+	switch data[pos] {
+	case '"':
+		return scanJSONCString(data, pos)
+	case '{', '[':
+		depth := 0
+		for i := pos; i < len(data); {
+			switch {
+			case data[i] == '"':
+				i = scanJSONCString(data, i)
+				continue
+			case isJSONCComment(data, i):
+				i = skipJSONCComment(data, i)
+				continue
+			case data[i] == '{' || data[i] == '[':
+				depth++
+			case data[i] == '}' || data[i] == ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return len(data)
+	}
+
+	// A number, true, false or null
+	i := pos
+	for i < len(data) && !bytes.ContainsRune([]byte(",}] \t\r\n/"), rune(data[i])) {
+		i++
+	}
+	return i
+}
+
+// skipJSONCSpace returns the offset of the first byte at or after pos that is
+// neither whitespace nor part of a comment
+func skipJSONCSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch {
+		case data[pos] == ' ' || data[pos] == '\t' || data[pos] == '\r' || data[pos] == '\n':
+			pos++
+		case isJSONCComment(data, pos):
+			pos = skipJSONCComment(data, pos)
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// isJSONCComment reports whether a comment starts at data[pos]
+func isJSONCComment(data []byte, pos int) bool {
+	return data[pos] == '/' && pos+1 < len(data) && (data[pos+1] == '/' || data[pos+1] == '*')
+}
+
+// skipJSONCComment returns the offset just past the comment starting at data[pos].
+// A line comment ends before its newline.
+func skipJSONCComment(data []byte, pos int) int {
+	if data[pos+1] == '/' {
+		for pos < len(data) && data[pos] != '\n' {
+			pos++
+		}
+		return pos
+	}
+
+	if end := bytes.Index(data[pos+2:], []byte("*/")); end >= 0 {
+		return pos + 2 + end + 2
+	}
+	return len(data)
+}
@@ -0,0 +1,144 @@
+package cleaner
+
+import (
+	"strings"
+	"testing"
+)
+
+// removeJSONCKey removes every occurrence of key from a JSONC document
+func removeJSONCKey(t *testing.T, data, key string) string {
+	t.Helper()
+	for {
+		members, err := parseJSONCMembers([]byte(data))
+		if err != nil {
+			t.Fatalf("parseJSONCMembers(%q) failed: %v", data, err)
+		}
+		found := -1
+		for i, member := range members {
+			if member.key == key {
+				found = i
+			}
+		}
+		if found < 0 {
+			return data
+		}
+		data = string(removeJSONCMember([]byte(data), members, found))
+	}
+}
+
+func TestRemoveJSONCMember(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{
+			name:  "middle member keeps surrounding comments",
+			input: "{\n  // Editor\n  \"editor.fontSize\": 14,\n  \"augment.x\": true, // noisy\n  /* kept */\n  \"files.eol\": \"\\n\"\n}\n",
+			want:  "{\n  // Editor\n  \"editor.fontSize\": 14,\n  /* kept */\n  \"files.eol\": \"\\n\"\n}\n",
+		},
+		{
+			name:  "last member takes the previous comma",
+			input: "{\n  \"editor.fontSize\": 14, // size\n  \"augment.x\": {\"a\": [1, 2]}\n}",
+			want:  "{\n  \"editor.fontSize\": 14 // size\n}",
+		},
+		{
+			name:  "trailing comma",
+			input: "{\n\t\"editor.fontSize\": 14,\n\t\"augment.x\": \"a,b}\",\n}",
+			want:  "{\n\t\"editor.fontSize\": 14,\n}",
+		},
+		{
+			name:  "only member",
+			input: "{\r\n  \"augment.x\": null\r\n}\r\n",
+			want:  "{\r\n}\r\n",
+		},
+		{
+			name:  "single line",
+			input: `{"augment.x": 1, "editor.fontSize": 14, "augment.y": false}`,
+			want:  `{"editor.fontSize": 14}`,
+		},
+		{
+			name:  "duplicate key",
+			input: "{\n  \"augment.x\": 1,\n  \"a\": 2,\n  \"augment.x\": 3\n}",
+			want:  "{\n  \"a\": 2\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := removeJSONCKey(t, removeJSONCKey(t, tt.input, "augment.x"), "augment.y")
+			if got != tt.want {
+				t.Errorf("Expected\n%q\ngot\n%q", tt.want, got)
+			}
+			var settings map[string]interface{}
+			if err := parseJSONC([]byte(got), &settings); err != nil {
+				t.Errorf("Edited settings do not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseJSONC(t *testing.T) {
+	input := `// User settings
+{
+	"url": "http://example.com/*not a comment*/", /* block
+	comment */ "list": [1, 2,],
+	"nested": {"a": "b",},
+}`
+	var settings map[string]interface{}
+	if err := parseJSONC([]byte(input), &settings); err != nil {
+		t.Fatalf("parseJSONC() failed: %v", err)
+	}
+	if settings["url"] != "http://example.com/*not a comment*/" || len(settings["list"].([]interface{})) != 2 {
+		t.Errorf("Unexpected settings %v", settings)
+	}
+
+	members, err := parseJSONCMembers([]byte(input))
+	if err != nil {
+		t.Fatalf("parseJSONCMembers() failed: %v", err)
+	}
+	if len(members) != 3 || members[1].key != "list" || input[members[1].valueStart:members[1].end] != "[1, 2,]" {
+		t.Errorf("Unexpected members %+v", members)
+	}
+
+	for _, invalid := range []string{`[]`, `{"a": 1`, `{"a" 1}`, `{"a": 1} x`, `{"a": }`} {
+		if _, err := parseJSONCMembers([]byte(invalid)); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3,\n  \"d\": 4,\n  \"e\": 5,\n  \"f\": 6,\n  \"g\": 7,\n  \"h\": 8,\n  \"i\": 9,\n  \"j\": 10\n}"
+	after := strings.Replace(strings.Replace(before, "  \"b\": 2,\n", "", 1), "\"j\": 10", "\"j\": 11", 1)
+
+	want := `--- a/settings.json
++++ b/settings.json
+@@ -1,6 +1,5 @@
+ {
+   "a": 1,
+-  "b": 2,
+   "c": 3,
+   "d": 4,
+   "e": 5,
+@@ -8,5 +7,5 @@
+   "g": 7,
+   "h": 8,
+   "i": 9,
+-  "j": 10
++  "j": 11
+ }
+\ No newline at end of file
+`
+	if got := unifiedDiff("a/settings.json", "b/settings.json", []byte(before), []byte(after)); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+	merged := strings.Replace(after, "\"i\": 9", "\"i\": 0", 1)
+	if got := unifiedDiff("a", "b", []byte(before), []byte(merged)); !strings.Contains(got, "@@ -1,12 +1,11 @@\n") || strings.Count(got, "@@") != 2 {
+		t.Errorf("Expected changes six lines apart to share a hunk, got\n%s", got)
+	}
+	if got := unifiedDiff("a", "b", []byte(before), []byte(before)); got != "" {
+		t.Errorf("Expected no diff for equal files, got %q", got)
+	}
+	if got := unifiedDiff("a", "b", nil, []byte("{}\n")); got != "--- a\n+++ b\n@@ -0,0 +1 @@\n+{}\n" {
+		t.Errorf("Unexpected diff of a new file %q", got)
+	}
+}
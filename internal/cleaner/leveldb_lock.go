@@ -0,0 +1,52 @@
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLevelDBInUse is returned by ReleaseLevelDBLock when another process has the
+// LevelDB database open
+var ErrLevelDBInUse = errors.New("leveldb database is open in another process")
+
+// levelDBLogFiles are the append-only info logs LevelDB writes next to its data.
+// They hold no data and are safe to delete once the database is closed.
+var levelDBLogFiles = []string{"LOG", "LOG.old"}
+
+// ReleaseLevelDBLock removes the LOCK, LOG and LOG.old files of the LevelDB
+// database in dirPath so its files can be cleaned. The LOCK file is only removed
+// after taking its lock proves no other process (a browser or VS Code) has the
+// database open; otherwise ErrLevelDBInUse is returned and nothing is removed.
+func ReleaseLevelDBLock(dirPath string) error {
+	if err := CheckLevelDBLock(dirPath); err != nil {
+		return err
+	}
+	lockPath := filepath.Join(dirPath, "LOCK")
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", lockPath, err)
+	}
+
+	for _, name := range levelDBLogFiles {
+		path := filepath.Join(dirPath, name)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// CheckLevelDBLock returns ErrLevelDBInUse when another process holds the lock on
+// the LOCK file of the LevelDB database in dirPath. Unlike ReleaseLevelDBLock it
+// leaves every file in place; a database without a LOCK file is not in use.
+func CheckLevelDBLock(dirPath string) error {
+	lockPath := filepath.Join(dirPath, "LOCK")
+	if _, err := os.Stat(lockPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s: %w", lockPath, err)
+	}
+	return tryLockLevelDB(lockPath)
+}
@@ -0,0 +1,45 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReleaseLevelDBLock(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"LOCK", "LOG", "LOG.old", "CURRENT", "000003.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := ReleaseLevelDBLock(dir); err != nil {
+		t.Fatalf("ReleaseLevelDBLock failed: %v", err)
+	}
+
+	for _, name := range []string{"LOCK", "LOG", "LOG.old"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+	for _, name := range []string{"CURRENT", "000003.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		}
+	}
+}
+
+func TestReleaseLevelDBLockWithoutLockFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "LOG"), []byte("info"), 0644); err != nil {
+		t.Fatalf("Failed to write LOG: %v", err)
+	}
+
+	if err := ReleaseLevelDBLock(dir); err != nil {
+		t.Fatalf("ReleaseLevelDBLock failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "LOG")); !os.IsNotExist(err) {
+		t.Error("Expected LOG to be removed")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// tryLockLevelDB takes and releases the lock LevelDB holds on its LOCK file.
+// LevelDB and Chromium lock it with fcntl(F_SETLK), which flock does not see on
+// Linux, so the same kind of record lock is used here.
+func tryLockLevelDB(lockPath string) error {
+	file, err := os.OpenFile(lockPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", lockPath, err)
+	}
+	defer file.Close()
+
+	lock := syscall.Flock_t{Type: syscall.F_WRLCK, Whence: 0, Start: 0, Len: 0}
+	if err := syscall.FcntlFlock(file.Fd(), syscall.F_SETLK, &lock); err != nil {
+		if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EACCES) {
+			return fmt.Errorf("%s: %w", lockPath, ErrLevelDBInUse)
+		}
+		return fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+
+	lock.Type = syscall.F_UNLCK
+	syscall.FcntlFlock(file.Fd(), syscall.F_SETLK, &lock)
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// levelDBLockHelperEnv makes the test binary hold the LOCK file named by it,
+// standing in for a browser with the database open
+const levelDBLockHelperEnv = "AUGMENT_TEST_LEVELDB_LOCK"
+
+func TestLevelDBLockHelper(t *testing.T) {
+	lockPath := os.Getenv(levelDBLockHelperEnv)
+	if lockPath == "" {
+		t.Skip("helper process only")
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("Failed to open LOCK: %v", err)
+	}
+	lock := syscall.Flock_t{Type: syscall.F_WRLCK}
+	if err := syscall.FcntlFlock(file.Fd(), syscall.F_SETLK, &lock); err != nil {
+		t.Fatalf("Failed to lock LOCK: %v", err)
+	}
+
+	fmt.Println("locked")
+	bufio.NewReader(os.Stdin).ReadString('\n') // Hold the lock until stdin closes
+}
+
+func TestReleaseLevelDBLockHeldByOtherProcess(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, "LOCK")
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to write LOCK: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "LOG"), []byte("info"), 0644); err != nil {
+		t.Fatalf("Failed to write LOG: %v", err)
+	}
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestLevelDBLockHelper$")
+	helper.Env = append(os.Environ(), levelDBLockHelperEnv+"="+lockPath)
+	stdin, err := helper.StdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdin pipe: %v", err)
+	}
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdout pipe: %v", err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatalf("Failed to start helper: %v", err)
+	}
+	defer helper.Wait()
+	defer stdin.Close()
+
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("Helper did not take the lock: %q %v", line, err)
+	}
+
+	err = ReleaseLevelDBLock(dir)
+	if !errors.Is(err, ErrLevelDBInUse) {
+		t.Fatalf("Expected ErrLevelDBInUse, got %v", err)
+	}
+	for _, name := range []string{"LOCK", "LOG"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be kept while the database is open: %v", name, err)
+		}
+	}
+}
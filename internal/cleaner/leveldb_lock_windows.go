@@ -0,0 +1,38 @@
+//go:build windows
+
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// tryLockLevelDB takes and releases the lock LevelDB holds on its LOCK file.
+// Chromium locks the whole file with LockFileEx, so the same range is requested.
+func tryLockLevelDB(lockPath string) error {
+	file, err := os.OpenFile(lockPath, os.O_RDWR, 0)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_SHARING_VIOLATION) {
+			return fmt.Errorf("%s: %w", lockPath, ErrLevelDBInUse)
+		}
+		return fmt.Errorf("failed to open %s: %w", lockPath, err)
+	}
+	defer file.Close()
+
+	handle := windows.Handle(file.Fd())
+	ol := &windows.Overlapped{}
+	err = windows.LockFileEx(handle, windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0, windows.INFINITE, windows.INFINITE, ol)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) || errors.Is(err, windows.ERROR_IO_PENDING) {
+			return fmt.Errorf("%s: %w", lockPath, ErrLevelDBInUse)
+		}
+		return fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+
+	windows.UnlockFileEx(handle, 0, windows.INFINITE, windows.INFINITE, ol)
+	return nil
+}
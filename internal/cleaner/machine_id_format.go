@@ -0,0 +1,90 @@
+package cleaner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// Machine ID formats written to telemetry.machineId
+const (
+	// IDFormatHex is the SHA-256 hex digest VS Code derives from the MAC address
+	IDFormatHex = "hex"
+	// IDFormatUUID is the UUID v4 VS Code falls back to when no MAC address is
+	// available, and which pre-1.0 releases always used
+	IDFormatUUID = "uuid"
+)
+
+var (
+	machineIDUUIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	machineIDHexPattern  = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// ValidateMachineIDFormat checks that id is either a lowercase UUID v4 or a
+// 64-character lowercase hex string
+func ValidateMachineIDFormat(id string) error {
+	switch machineIDFormat(id) {
+	case IDFormatUUID, IDFormatHex:
+		return nil
+	}
+
+	if strings.Contains(id, "-") {
+		return fmt.Errorf("invalid machine ID format: %q is not a lowercase UUID v4", id)
+	}
+	return fmt.Errorf("invalid machine ID format: %q is not a 64-character hex string", id)
+}
+
+// machineIDFormat returns the format of id, or an empty string if it matches none
+func machineIDFormat(id string) string {
+	switch {
+	case machineIDUUIDPattern.MatchString(id):
+		return IDFormatUUID
+	case machineIDHexPattern.MatchString(id):
+		return IDFormatHex
+	default:
+		return ""
+	}
+}
+
+// selectMachineIDFormat picks the format for a new machine ID. A valid current ID
+// keeps its format, since VS Code itself chose it for this machine; otherwise the
+// installed VS Code version decides, defaulting to hex when it is unknown.
+func selectMachineIDFormat(currentID, vscodeVersion string) string {
+	if format := machineIDFormat(currentID); format != "" {
+		return format
+	}
+	return machineIDFormatForVersion(vscodeVersion)
+}
+
+// machineIDFormatForVersion returns the machine ID format used by a VS Code version
+func machineIDFormatForVersion(version string) string {
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(version, "v"), ".", 2)[0])
+	if err == nil && major < 1 {
+		return IDFormatUUID
+	}
+	return IDFormatHex
+}
+
+// generateMachineID generates a new machine ID in the given format
+func generateMachineID(format string) (string, error) {
+	var id string
+	switch format {
+	case IDFormatUUID:
+		id = utils.GenerateDeviceID()
+	case IDFormatHex:
+		var err error
+		if id, err = utils.GenerateMachineID(); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown machine ID format: %s", format)
+	}
+
+	if err := ValidateMachineIDFormat(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
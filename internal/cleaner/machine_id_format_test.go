@@ -0,0 +1,73 @@
+package cleaner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMachineIDFormat(t *testing.T) {
+	valid := []string{
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"3f2504e0-4f89-41d3-9a0c-0305e82c3301",
+	}
+	for _, id := range valid {
+		if err := ValidateMachineIDFormat(id); err != nil {
+			t.Errorf("ValidateMachineIDFormat(%q) unexpected error: %v", id, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"": "hex",
+		"0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF": "hex",
+		"0123456789abcdef":                     "hex",
+		"3f2504e0-4f89-11d3-9a0c-0305e82c3301": "UUID", // version 1
+		"3f2504e0-4f89-41d3-ca0c-0305e82c3301": "UUID", // wrong variant
+	}
+	for id, want := range invalid {
+		err := ValidateMachineIDFormat(id)
+		if err == nil {
+			t.Errorf("ValidateMachineIDFormat(%q) expected error", id)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateMachineIDFormat(%q) error %q should mention %s", id, err, want)
+		}
+	}
+}
+
+func TestSelectMachineIDFormat(t *testing.T) {
+	tests := []struct {
+		currentID string
+		version   string
+		want      string
+	}{
+		{"3f2504e0-4f89-41d3-9a0c-0305e82c3301", "1.95.0", IDFormatUUID},
+		{strings.Repeat("ab", 32), "0.10.11", IDFormatHex},
+		{"", "1.95.0", IDFormatHex},
+		{"", "0.10.11", IDFormatUUID},
+		{"", "", IDFormatHex},
+		{"not-an-id", "garbage", IDFormatHex},
+	}
+
+	for _, tt := range tests {
+		if got := selectMachineIDFormat(tt.currentID, tt.version); got != tt.want {
+			t.Errorf("selectMachineIDFormat(%q, %q) = %s, want %s", tt.currentID, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateMachineIDFormats(t *testing.T) {
+	for _, format := range []string{IDFormatHex, IDFormatUUID} {
+		id, err := generateMachineID(format)
+		if err != nil {
+			t.Fatalf("generateMachineID(%s) failed: %v", format, err)
+		}
+		if got := machineIDFormat(id); got != format {
+			t.Errorf("generateMachineID(%s) produced %q in format %q", format, id, got)
+		}
+	}
+
+	if _, err := generateMachineID("base64"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
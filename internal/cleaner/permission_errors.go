@@ -0,0 +1,56 @@
+package cleaner
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// IsPermissionError reports whether err was caused by missing access rights, a
+// read-only file system or a read-only database rather than a transient failure.
+// Such errors do not go away on retry and need the user to fix the permissions.
+func IsPermissionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return true
+	}
+	return isSQLiteReadOnly(err)
+}
+
+// PermissionDeniedPath returns the path a permission error occurred on, or the
+// error message when err carries no path
+func PermissionDeniedPath(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Path
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Old
+	}
+	return err.Error()
+}
+
+// newFailedOperation records a failed operation on path, flagging permission errors
+func newFailedOperation(opType, path string, err error) FailedOperation {
+	return FailedOperation{
+		Type:             opType,
+		Path:             path,
+		Error:            err.Error(),
+		PermissionDenied: IsPermissionError(err),
+	}
+}
+
+// permissionDeniedPaths returns the paths of the operations that failed for lack of permission
+func permissionDeniedPaths(failed []FailedOperation) []string {
+	var paths []string
+	for _, op := range failed {
+		if op.PermissionDenied {
+			paths = append(paths, op.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,96 @@
+package cleaner
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func TestIsPermissionError(t *testing.T) {
+	pathErr := &fs.PathError{Op: "unlinkat", Path: "/profile/Cookies", Err: syscall.EACCES}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"access denied", pathErr, true},
+		{"wrapped", fmt.Errorf("failed to clean cookies: %w", pathErr), true},
+		{"operation not permitted", &fs.PathError{Op: "open", Path: "/Safari", Err: syscall.EPERM}, true},
+		{"read-only file system", &fs.PathError{Op: "open", Path: "/ro", Err: syscall.EROFS}, true},
+		{"not found", &fs.PathError{Op: "open", Path: "/missing", Err: fs.ErrNotExist}, false},
+		{"other error", errors.New("database is locked"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermissionError(tt.err); got != tt.want {
+				t.Errorf("IsPermissionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if path := PermissionDeniedPath(fmt.Errorf("wrapped: %w", pathErr)); path != "/profile/Cookies" {
+		t.Errorf("Expected the path of the error, got %q", path)
+	}
+	if path := PermissionDeniedPath(errors.New("no path")); path != "no path" {
+		t.Errorf("Expected the error message, got %q", path)
+	}
+}
+
+func TestIsPermissionErrorReadOnlyDatabase(t *testing.T) {
+	dbPath := createItemTableDB(t, 1, 0)
+
+	db, err := sql.Open("sqlite3", "file:"+filepath.ToSlash(dbPath)+"?mode=ro")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM ItemTable")
+	if err == nil {
+		t.Fatal("Expected deleting from a read-only database to fail")
+	}
+	if !IsPermissionError(fmt.Errorf("failed to delete: %w", err)) {
+		t.Errorf("Expected a read-only database error to be a permission error: %v", err)
+	}
+}
+
+func TestPruneOrphanedWorkspacesReportsPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced")
+	}
+
+	storagePath := t.TempDir()
+	orphanDir := filepath.Join(storagePath, "abc123")
+	if err := os.MkdirAll(orphanDir, 0755); err != nil {
+		t.Fatalf("Failed to create workspace storage: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(orphanDir, "workspace.json"), []byte(`{"folder": "file:///does/not/exist"}`), 0644); err != nil {
+		t.Fatalf("Failed to write workspace.json: %v", err)
+	}
+
+	// Entries of a read-only directory cannot be deleted
+	if err := os.Chmod(orphanDir, 0555); err != nil {
+		t.Fatalf("Failed to make directory read-only: %v", err)
+	}
+	defer os.Chmod(orphanDir, 0755)
+
+	result, err := pruneOrphanedWorkspaces(storagePath, false)
+	if err != nil {
+		t.Fatalf("pruneOrphanedWorkspaces failed: %v", err)
+	}
+	if len(result.FailedOperations) != 1 || !result.FailedOperations[0].PermissionDenied {
+		t.Fatalf("Expected one failure flagged as permission denied, got %+v", result.FailedOperations)
+	}
+	if len(result.PermissionDenied) != 1 || result.PermissionDenied[0] != orphanDir {
+		t.Errorf("Expected %s in PermissionDenied, got %v", orphanDir, result.PermissionDenied)
+	}
+}
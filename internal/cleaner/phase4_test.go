@@ -85,6 +85,22 @@ func TestGetConservativeRemovalPolicy(t *testing.T) {
 	}
 }
 
+func TestGetQuickRemovalPolicy(t *testing.T) {
+	policy := GetQuickRemovalPolicy()
+	
+	if policy.MinRiskLevel != scanner.TelemetryRiskCritical {
+		t.Errorf("Expected MinRiskLevel to be Critical, got %v", policy.MinRiskLevel)
+	}
+	
+	if policy.PreserveRecent {
+		t.Error("Expected PreserveRecent to be false for quick policy")
+	}
+	
+	if !policy.CreateBackups {
+		t.Error("Expected quick policy to always create backups")
+	}
+}
+
 func TestExtensionCleanerShouldCleanItem(t *testing.T) {
 	policy := GetDefaultRemovalPolicy()
 	cleaner := NewExtensionCleaner(policy)
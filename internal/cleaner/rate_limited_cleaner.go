@@ -0,0 +1,187 @@
+package cleaner
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/retry"
+)
+
+// Default rate limiting settings for database cleaning
+const (
+	DefaultCleanBatchSize   = 100
+	DefaultCleanBatchDelay  = 10 * time.Millisecond
+	DefaultLockBackoff      = 250 * time.Millisecond
+	DefaultMaxLockRetries   = 20
+	augmentKeyFilterPattern = "%augment%"
+	// augmentRowBytesExpr is the size of a row as reported in BytesFreed: its key and value bytes
+	augmentRowBytesExpr = "LENGTH(CAST(key AS BLOB)) + COALESCE(LENGTH(CAST(value AS BLOB)), 0)"
+)
+
+// RateLimitedCleaner deletes Augment rows from the VS Code database in small batches,
+// each in its own short transaction, so VS Code is never locked out for long
+type RateLimitedCleaner struct {
+	BatchSize      int
+	BatchDelay     time.Duration // 0 deletes the batches without pausing
+	LockBackoff    time.Duration
+	MaxLockRetries int
+	Logger         logger.Leveled // Receives DEBUG traces of every statement; may be nil
+	// ExtraKeyPatterns are SQL LIKE patterns of keys deleted as well as those
+	// containing 'augment', e.g. of a deployment branded differently
+	ExtraKeyPatterns []string
+
+	clock retry.Clock // Waits between batches and lock retries; nil uses the real clock
+}
+
+// NewRateLimitedCleaner creates a rate limited cleaner with default settings
+func NewRateLimitedCleaner() *RateLimitedCleaner {
+	return &RateLimitedCleaner{
+		BatchSize:      DefaultCleanBatchSize,
+		BatchDelay:     DefaultCleanBatchDelay,
+		LockBackoff:    DefaultLockBackoff,
+		MaxLockRetries: DefaultMaxLockRetries,
+	}
+}
+
+// DeleteAugmentRows deletes all ItemTable rows whose key contains 'augment' or
+// matches one of ExtraKeyPatterns.
+// The result's DBBackupPath is left empty; backups are the caller's responsibility.
+func (rc *RateLimitedCleaner) DeleteAugmentRows(db *sql.DB) (*DatabaseCleanResult, error) {
+	rc.applyDefaults()
+	result := &DatabaseCleanResult{}
+
+	for {
+		deleted, size, err := rc.deleteBatchWithRetry(db, result)
+		if err != nil {
+			return result, err
+		}
+
+		result.BatchCount++
+		result.DeletedRows += deleted
+		result.BytesFreed += size
+
+		// A short batch means there is nothing left to delete
+		if deleted < int64(rc.BatchSize) {
+			return result, nil
+		}
+
+		if rc.BatchDelay > 0 {
+			<-rc.getClock().After(rc.BatchDelay)
+		}
+	}
+}
+
+// deleteBatchWithRetry deletes a single batch, backing off while the database is
+// locked. MaxLockRetries bounds the retries of the whole run, not of each batch.
+func (rc *RateLimitedCleaner) deleteBatchWithRetry(db *sql.DB, result *DatabaseCleanResult) (int64, int64, error) {
+	policy := retry.Policy{
+		Attempts:  rc.MaxLockRetries + 1,
+		BaseDelay: rc.LockBackoff,
+		MaxDelay:  rc.LockBackoff, // A fixed pause rather than exponential backoff
+	}.WithClock(rc.getClock())
+
+	var deleted, size int64
+	err := policy.Do(context.Background(), func() error {
+		var err error
+		deleted, size, err = rc.deleteBatch(db)
+		if err == nil {
+			return nil
+		}
+		if !isDatabaseLocked(err) || result.LockRetries >= rc.MaxLockRetries {
+			return retry.Permanent(err)
+		}
+
+		result.LockRetries++
+		rc.logger().Debug("Database locked, retrying in %v (attempt %d/%d)", rc.LockBackoff, result.LockRetries, rc.MaxLockRetries)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return deleted, size, nil
+}
+
+// deleteBatch deletes up to BatchSize matching rows in one transaction and returns
+// the number of rows deleted and their size
+func (rc *RateLimitedCleaner) deleteBatch(db *sql.DB) (int64, int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+
+	where, args := augmentKeyFilter(rc.ExtraKeyPatterns)
+	batchKeysQuery := "SELECT key FROM ItemTable WHERE " + where + " LIMIT ?"
+	sizeQuery := "SELECT COALESCE(SUM(" + augmentRowBytesExpr + "), 0) FROM ItemTable WHERE key IN (" + batchKeysQuery + ")"
+	deleteQuery := "DELETE FROM ItemTable WHERE key IN (" + batchKeysQuery + ")"
+	args = append(args, rc.BatchSize)
+
+	// Measured in the same transaction, so the size is of exactly the rows deleted
+	var size int64
+	if err := tx.QueryRow(sizeQuery, args...).Scan(&size); err != nil {
+		return 0, 0, fmt.Errorf("failed to measure batch size: %w", err)
+	}
+
+	res, err := tx.Exec(deleteQuery, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get affected rows count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	rc.logger().Debug("SQL: %s %v -> %d rows", deleteQuery, args, deleted)
+	return deleted, size, nil
+}
+
+// augmentKeyFilter returns the WHERE condition matching the keys of Augment rows,
+// those containing 'augment' or matching one of extraPatterns, and its arguments
+func augmentKeyFilter(extraPatterns []string) (string, []interface{}) {
+	conditions := []string{"key LIKE ?"}
+	args := []interface{}{augmentKeyFilterPattern}
+	for _, pattern := range extraPatterns {
+		conditions = append(conditions, "key LIKE ?")
+		args = append(args, pattern)
+	}
+	return strings.Join(conditions, " OR "), args
+}
+
+// logger returns the configured logger, discarding output if none is set
+func (rc *RateLimitedCleaner) logger() logger.Leveled {
+	return logger.OrDiscard(rc.Logger)
+}
+
+// getClock returns the clock waited on between batches and lock retries
+func (rc *RateLimitedCleaner) getClock() retry.Clock {
+	if rc.clock == nil {
+		return retry.RealClock
+	}
+	return rc.clock
+}
+
+// applyDefaults replaces unset or invalid settings with defaults. A BatchDelay
+// of 0 is kept, as it asks for no pause between batches.
+func (rc *RateLimitedCleaner) applyDefaults() {
+	if rc.BatchSize <= 0 {
+		rc.BatchSize = DefaultCleanBatchSize
+	}
+	if rc.BatchDelay < 0 {
+		rc.BatchDelay = DefaultCleanBatchDelay
+	}
+	if rc.LockBackoff <= 0 {
+		rc.LockBackoff = DefaultLockBackoff
+	}
+	if rc.MaxLockRetries <= 0 {
+		rc.MaxLockRetries = DefaultMaxLockRetries
+	}
+}
@@ -0,0 +1,347 @@
+package cleaner
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/logger"
+)
+
+// createItemTableDB creates a VS Code style state database with augment and other rows
+func createItemTableDB(t *testing.T, augmentRows, otherRows int) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "state.vscdb")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	for i := 0; i < augmentRows; i++ {
+		if _, err := db.Exec("INSERT INTO ItemTable VALUES (?, ?)", fmt.Sprintf("augment.vscode-augment.key%d", i), "v"); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+	}
+	for i := 0; i < otherRows; i++ {
+		if _, err := db.Exec("INSERT INTO ItemTable VALUES (?, ?)", fmt.Sprintf("workbench.key%d", i), "v"); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+	}
+
+	return dbPath
+}
+
+func TestRateLimitedCleanerDeletesInBatches(t *testing.T) {
+	dbPath := createItemTableDB(t, 250, 10)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	limiter := NewRateLimitedCleaner()
+	limiter.BatchDelay = time.Millisecond
+
+	result, err := limiter.DeleteAugmentRows(db)
+	if err != nil {
+		t.Fatalf("DeleteAugmentRows() failed: %v", err)
+	}
+
+	if result.DeletedRows != 250 {
+		t.Errorf("Expected 250 deleted rows, got %d", result.DeletedRows)
+	}
+	if result.BatchCount != 3 {
+		t.Errorf("Expected 3 batches, got %d", result.BatchCount)
+	}
+
+	var remaining int
+	if err := db.QueryRow("SELECT COUNT(*) FROM ItemTable").Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	if remaining != 10 {
+		t.Errorf("Expected 10 unrelated rows to remain, got %d", remaining)
+	}
+}
+
+func TestRateLimitedCleanerExtraKeyPatterns(t *testing.T) {
+	dbPath := createItemTableDB(t, 3, 2)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, key := range []string{"acme.session", "ACME.token", "my.acme"} {
+		if _, err := db.Exec("INSERT INTO ItemTable VALUES (?, ?)", key, "v"); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+	}
+
+	extra := []string{"acme.%"}
+	estimate, err := EstimateAugmentDataFromPath(dbPath, extra...)
+	if err != nil {
+		t.Fatalf("EstimateAugmentDataFromPath failed: %v", err)
+	}
+	if estimate.Records != 5 {
+		t.Errorf("Expected 5 records to estimate, got %d", estimate.Records)
+	}
+
+	limiter := NewRateLimitedCleaner()
+	limiter.ExtraKeyPatterns = extra
+	result, err := limiter.DeleteAugmentRows(db)
+	if err != nil {
+		t.Fatalf("DeleteAugmentRows() failed: %v", err)
+	}
+	if result.DeletedRows != 5 {
+		t.Errorf("Expected 5 deleted rows, got %d", result.DeletedRows)
+	}
+
+	var remaining int
+	if err := db.QueryRow("SELECT COUNT(*) FROM ItemTable WHERE key = 'my.acme'").Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	if remaining != 1 {
+		t.Error("Expected the key not matching the pattern to remain")
+	}
+}
+
+func TestRateLimitedCleanerRetriesWhenLocked(t *testing.T) {
+	dbPath := createItemTableDB(t, 5, 0)
+
+	// Hold a write lock from another connection for a short time
+	locker, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open locking connection: %v", err)
+	}
+	defer locker.Close()
+
+	lockTx, err := locker.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin locking transaction: %v", err)
+	}
+	if _, err := lockTx.Exec("INSERT INTO ItemTable VALUES ('lock', 'v')"); err != nil {
+		t.Fatalf("Failed to take write lock: %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lockTx.Rollback()
+	}()
+
+	// Disable SQLite's own busy waiting so the lock surfaces as an error
+	db, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=0")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	limiter := NewRateLimitedCleaner()
+	limiter.LockBackoff = 20 * time.Millisecond
+
+	result, err := limiter.DeleteAugmentRows(db)
+	if err != nil {
+		t.Fatalf("DeleteAugmentRows() failed: %v", err)
+	}
+
+	if result.LockRetries == 0 {
+		t.Error("Expected at least one lock retry")
+	}
+	if result.DeletedRows != 5 {
+		t.Errorf("Expected 5 deleted rows, got %d", result.DeletedRows)
+	}
+}
+
+// fakeClock records the waits of a RateLimitedCleaner and returns at once,
+// calling onWait first if it is set
+type fakeClock struct {
+	waits  []time.Duration
+	onWait func(wait int)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	if c.onWait != nil {
+		c.onWait(len(c.waits))
+	}
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// lockItemTable holds a write lock on the database at dbPath from another
+// connection until the returned release function is called
+func lockItemTable(t *testing.T, dbPath string) (release func()) {
+	t.Helper()
+
+	locker, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open locking connection: %v", err)
+	}
+	t.Cleanup(func() { locker.Close() })
+
+	lockTx, err := locker.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin locking transaction: %v", err)
+	}
+	if _, err := lockTx.Exec("INSERT INTO ItemTable VALUES ('lock', 'v')"); err != nil {
+		t.Fatalf("Failed to take write lock: %v", err)
+	}
+	t.Cleanup(func() { lockTx.Rollback() })
+	return func() { lockTx.Rollback() }
+}
+
+// openWithoutBusyTimeout opens the database at dbPath with SQLite's own busy
+// waiting disabled, so locks surface as errors
+func openWithoutBusyTimeout(t *testing.T, dbPath string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=0")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRateLimitedCleanerWaitsOnClock(t *testing.T) {
+	dbPath := createItemTableDB(t, 5, 0)
+	release := lockItemTable(t, dbPath)
+	db := openWithoutBusyTimeout(t, dbPath)
+
+	// The lock is released during the second lock backoff
+	clock := &fakeClock{onWait: func(wait int) {
+		if wait == 2 {
+			release()
+		}
+	}}
+	limiter := NewRateLimitedCleaner()
+	limiter.BatchSize = 2
+	limiter.clock = clock
+
+	result, err := limiter.DeleteAugmentRows(db)
+	if err != nil {
+		t.Fatalf("DeleteAugmentRows() failed: %v", err)
+	}
+	if result.LockRetries != 2 || result.DeletedRows != 5 || result.BatchCount != 3 {
+		t.Errorf("Expected 5 rows in 3 batches after 2 lock retries, got %+v", result)
+	}
+
+	want := []time.Duration{DefaultLockBackoff, DefaultLockBackoff, DefaultCleanBatchDelay, DefaultCleanBatchDelay}
+	if !reflect.DeepEqual(clock.waits, want) {
+		t.Errorf("Expected waits %v, got %v", want, clock.waits)
+	}
+}
+
+func TestRateLimitedCleanerGivesUpAfterMaxLockRetries(t *testing.T) {
+	dbPath := createItemTableDB(t, 5, 0)
+	lockItemTable(t, dbPath)
+	db := openWithoutBusyTimeout(t, dbPath)
+
+	clock := &fakeClock{}
+	limiter := NewRateLimitedCleaner()
+	limiter.MaxLockRetries = 3
+	limiter.clock = clock
+
+	result, err := limiter.DeleteAugmentRows(db)
+	if err == nil || !isDatabaseLocked(err) {
+		t.Fatalf("Expected a database locked error, got %v", err)
+	}
+	if result.LockRetries != 3 || len(clock.waits) != 3 {
+		t.Errorf("Expected 3 lock retries and 3 waits, got %d and %v", result.LockRetries, clock.waits)
+	}
+}
+
+func TestRateLimitedCleanerDoesNotRetryOtherErrors(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "empty.vscdb"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	clock := &fakeClock{}
+	limiter := NewRateLimitedCleaner()
+	limiter.clock = clock
+
+	result, err := limiter.DeleteAugmentRows(db)
+	if err == nil {
+		t.Fatal("Expected an error for a database without ItemTable")
+	}
+	if result.LockRetries != 0 || len(clock.waits) != 0 {
+		t.Errorf("Expected no retries or waits, got %d and %v", result.LockRetries, clock.waits)
+	}
+}
+
+func TestRateLimitedCleanerLogsStatementsAtDebug(t *testing.T) {
+	dbPath := createItemTableDB(t, 150, 0)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var debugLines []string
+	limiter := NewRateLimitedCleaner()
+	limiter.BatchDelay = time.Millisecond
+	limiter.Logger = logger.FuncLogger(func(level logger.LogLevel, message string) {
+		if level == logger.DEBUG {
+			debugLines = append(debugLines, message)
+		}
+	})
+
+	if _, err := limiter.DeleteAugmentRows(db); err != nil {
+		t.Fatalf("DeleteAugmentRows() failed: %v", err)
+	}
+
+	if len(debugLines) != 2 {
+		t.Fatalf("Expected one DEBUG line per batch, got %v", debugLines)
+	}
+	if !strings.HasPrefix(debugLines[0], "SQL: DELETE FROM ItemTable") || !strings.HasSuffix(debugLines[0], "-> 100 rows") {
+		t.Errorf("Unexpected first batch trace: %s", debugLines[0])
+	}
+	if !strings.HasSuffix(debugLines[1], "-> 50 rows") {
+		t.Errorf("Unexpected second batch trace: %s", debugLines[1])
+	}
+}
+
+func TestCleanAugmentDataKeepsCommittedBatchesOnError(t *testing.T) {
+	dbPath := createItemTableDB(t, 5, 0)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	// The last row cannot be deleted, so the third batch fails
+	_, err = db.Exec(`CREATE TRIGGER keep_last BEFORE DELETE ON ItemTable WHEN old.key = 'augment.vscode-augment.key4'
+		BEGIN SELECT RAISE(ABORT, 'row is protected'); END`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	limiter := &RateLimitedCleaner{BatchSize: 2, BatchDelay: 0}
+	result, err := CleanAugmentDataFromPathWithLimiter(dbPath, true, limiter)
+	if err == nil {
+		t.Fatal("Expected the protected row to fail the clean")
+	}
+	if limiter.BatchDelay != 0 {
+		t.Errorf("Expected a BatchDelay of 0 to be kept, got %v", limiter.BatchDelay)
+	}
+	if result == nil || result.DeletedRows != 4 || result.BatchCount != 2 {
+		t.Fatalf("Expected the 4 rows of the 2 committed batches with the error, got %+v", result)
+	}
+	if result.DBBackupPath == "" || len(result.DBBackupPaths) != 1 {
+		t.Errorf("Expected the backup path with the error, got %q and %v", result.DBBackupPath, result.DBBackupPaths)
+	}
+}
@@ -3,17 +3,21 @@ package cleaner
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
 )
 
 // SafetyValidator handles validation of removal operations for safety
 type SafetyValidator struct {
 	criticalPaths    []string
 	protectedPatterns []string
+	protectedSettingKeys []string
 	safetyRules      []SafetyRule
+	listProcesses    func() ([]utils.ProcessInfo, error) // Running processes for CheckForRunningIDEProcesses
 }
 
 // SafetyRule represents a safety rule for data removal
@@ -49,9 +53,10 @@ type SafetyIssue struct {
 
 // NewSafetyValidator creates a new safety validator
 func NewSafetyValidator() *SafetyValidator {
-	validator := &SafetyValidator{}
+	validator := &SafetyValidator{listProcesses: utils.ListProcesses}
 	validator.initializeCriticalPaths()
 	validator.initializeProtectedPatterns()
+	validator.initializeProtectedSettingKeys()
 	validator.initializeSafetyRules()
 	return validator
 }
@@ -111,6 +116,17 @@ func (sv *SafetyValidator) initializeProtectedPatterns() {
 	}
 }
 
+// initializeProtectedSettingKeys sets up settings.json keys that are never removed
+func (sv *SafetyValidator) initializeProtectedSettingKeys() {
+	sv.protectedSettingKeys = []string{
+		// Sign-in and server configuration of self-hosted setups
+		"augment.advanced",
+
+		// User-written content that cannot be recreated
+		"augment.chat.userGuidelines",
+	}
+}
+
 // initializeSafetyRules sets up safety rules for validation
 func (sv *SafetyValidator) initializeSafetyRules() {
 	sv.safetyRules = []SafetyRule{
@@ -567,6 +583,56 @@ func (sv *SafetyValidator) validateZipBackup(zipPath string) error {
 	return nil
 }
 
+// CheckDiskSpace verifies that the file system holding path has at least
+// requiredBytes free. A path that does not exist yet is checked on its closest
+// existing parent, so backup directories can be checked before they are created.
+func (sv *SafetyValidator) CheckDiskSpace(path string, requiredBytes uint64) error {
+	existing := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("no existing parent directory of %s", path)
+		}
+		existing = parent
+	}
+
+	freeBytes, err := freeDiskSpace(existing)
+	if err != nil {
+		return err
+	}
+	if freeBytes < requiredBytes {
+		return fmt.Errorf("insufficient disk space at %s: %d bytes free, %d required", existing, freeBytes, requiredBytes)
+	}
+	return nil
+}
+
+// IsProtectedSettingKey reports whether a settings.json key must be kept when
+// cleaning settings: it is in the protected settings list or matches an enabled
+// content protection rule that blocks removal, such as protect_authentication
+func (sv *SafetyValidator) IsProtectedSettingKey(key string) bool {
+	for _, protected := range sv.protectedSettingKeys {
+		if strings.EqualFold(key, protected) {
+			return true
+		}
+	}
+
+	for _, rule := range sv.safetyRules {
+		if rule.Enabled && rule.RuleType == "content_protection" && rule.Action == "block" &&
+			sv.matchesPathPattern(key, rule.Pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedSettingKeys returns the settings.json keys that are always protected
+func (sv *SafetyValidator) ProtectedSettingKeys() []string {
+	return append([]string(nil), sv.protectedSettingKeys...)
+}
+
 // GetSafetyRules returns the current safety rules
 func (sv *SafetyValidator) GetSafetyRules() []SafetyRule {
 	return sv.safetyRules
@@ -0,0 +1,35 @@
+package cleaner
+
+import (
+	"fmt"
+
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+// SecretStoreCleanResult contains the results of removing Augment secrets from the OS secret store
+type SecretStoreCleanResult struct {
+	RemovedEntries   []scanner.SecretEntry `json:"removed_entries"`
+	FailedOperations []FailedOperation     `json:"failed_operations,omitempty"`
+}
+
+// CleanAugmentSecrets removes VS Code secret store entries that belong to Augment.
+// Secret values are never logged or returned; entries only carry a short snippet.
+func CleanAugmentSecrets() (*SecretStoreCleanResult, error) {
+	secretScanner := scanner.NewSecretStoreScanner()
+
+	entries, err := secretScanner.ScanForAugmentSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan secret store: %w", err)
+	}
+
+	result := &SecretStoreCleanResult{RemovedEntries: make([]scanner.SecretEntry, 0, len(entries))}
+	for _, entry := range entries {
+		if err := secretScanner.DeleteSecret(entry); err != nil {
+			result.FailedOperations = append(result.FailedOperations, newFailedOperation("secret", entry.ServiceName+"/"+entry.AccountName, err))
+			continue
+		}
+		result.RemovedEntries = append(result.RemovedEntries, entry)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package cleaner
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// BackupTypeVSCodeSettings is the backup type of VS Code settings.json backups
+const BackupTypeVSCodeSettings = "vscode_settings"
+
+// SettingChange is a top-level settings.json key whose value a restore changed.
+// Before is nil for keys the restore added and After is nil for keys it removed.
+type SettingChange struct {
+	Key    string      `json:"key"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// RestoreVSCodeSettingsResult is the result of restoring settings.json from a backup
+type RestoreVSCodeSettingsResult struct {
+	RestoredPath     string          `json:"restored_path"`
+	BackupUsed       BackupMetadata  `json:"backup_used"`
+	SettingsRestored []string        `json:"settings_restored"` // Keys whose value changed, sorted
+	Changes          []SettingChange `json:"changes,omitempty"`
+	PreRestoreBackup string          `json:"pre_restore_backup,omitempty"` // Backup of the settings replaced by the restore
+	Duration         time.Duration   `json:"duration"`
+}
+
+// CreateSettingsBackup backs up a VS Code settings.json so that
+// RestoreVSCodeSettings can revert later changes to it
+func (bm *BackupManager) CreateSettingsBackup(settingsPath string) (string, error) {
+	if _, err := bm.fs.Stat(settingsPath); err != nil {
+		return "", fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	metadata := BackupMetadata{
+		BackupType:   BackupTypeVSCodeSettings,
+		OriginalPath: settingsPath,
+	}
+	backupName := fmt.Sprintf("vscode-settings-%d", bm.now().UnixNano())
+	include := func(path string) bool { return path == filepath.Clean(settingsPath) }
+	return bm.createBackup(backupName, filepath.Dir(settingsPath), include, metadata, nil)
+}
+
+// FindSettingsBackup returns the settings backup with the given backup ID, or the
+// most recent one when backupID is empty
+func (bm *BackupManager) FindSettingsBackup(backupID string) (*BackupMetadata, error) {
+	backups, err := bm.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *BackupMetadata
+	for i := range backups {
+		backup := &backups[i]
+		if backup.BackupType != BackupTypeVSCodeSettings {
+			continue
+		}
+		if backupID != "" {
+			if backup.BackupID == backupID {
+				return backup, nil
+			}
+			continue
+		}
+		if found == nil || backup.CreationTime.After(found.CreationTime) {
+			found = backup
+		}
+	}
+
+	if backupID != "" {
+		return nil, fmt.Errorf("no VS Code settings backup with ID %s in %s", backupID, bm.backupDirectory)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no VS Code settings backup found in %s", bm.backupDirectory)
+	}
+	return found, nil
+}
+
+// RestoreVSCodeSettings restores settings.json from a settings backup, the most
+// recent one when backupID is empty, to the path it was backed up from. With
+// backupCurrent the settings being replaced are backed up first. The restored
+// file must be valid JSONC; the result lists the keys that changed.
+func (bm *BackupManager) RestoreVSCodeSettings(backupID string, backupCurrent bool) (*RestoreVSCodeSettingsResult, error) {
+	startTime := time.Now()
+
+	backup, err := bm.FindSettingsBackup(backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreVSCodeSettingsResult{
+		RestoredPath:     backup.OriginalPath,
+		BackupUsed:       *backup,
+		SettingsRestored: make([]string, 0),
+	}
+
+	if _, err := bm.fs.Stat(backup.OriginalPath); err == nil && backupCurrent {
+		if result.PreRestoreBackup, err = bm.CreateSettingsBackup(backup.OriginalPath); err != nil {
+			return nil, fmt.Errorf("failed to back up current settings: %w", err)
+		}
+	}
+
+	// The current settings may be missing or invalid; every restored key then counts as added
+	before, _ := bm.readSettings(backup.OriginalPath)
+
+	restoreResult, err := bm.RestoreBackup(backup.BackupPath, filepath.Dir(backup.OriginalPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore settings backup %s: %w", backup.BackupID, err)
+	}
+	if restoreResult.FileCount == 0 {
+		return nil, fmt.Errorf("settings backup %s contains no files", backup.BackupID)
+	}
+
+	after, err := bm.readSettings(backup.OriginalPath)
+	if err != nil {
+		return nil, fmt.Errorf("restored settings are not valid JSON: %w", err)
+	}
+
+	for _, change := range diffSettings(before, after) {
+		result.SettingsRestored = append(result.SettingsRestored, change.Key)
+		result.Changes = append(result.Changes, change)
+	}
+	result.Duration = time.Since(startTime)
+
+	return result, nil
+}
+
+// readSettings parses a settings.json file, which may contain comments
+func (bm *BackupManager) readSettings(path string) (map[string]interface{}, error) {
+	data, err := bm.fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]interface{}
+	if err := parseJSONC(data, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// diffSettings returns the top-level keys whose values differ, sorted by key
+func diffSettings(before, after map[string]interface{}) []SettingChange {
+	var changes []SettingChange
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes = append(changes, SettingChange{Key: key, Before: old, After: value})
+		}
+	}
+	for key, old := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, SettingChange{Key: key, Before: old})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
@@ -0,0 +1,149 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/vfs"
+)
+
+// newMemSettingsManager returns a backup manager on a MemFS holding a VS Code
+// User directory with settings.json and unrelated storage
+func newMemSettingsManager(t *testing.T, settings string) (*BackupManager, *vfs.MemFS, string, *time.Time) {
+	t.Helper()
+	memFS := vfs.NewMemFS()
+	userDir := filepath.FromSlash("/vscode/User")
+	settingsPath := filepath.Join(userDir, "settings.json")
+
+	if err := memFS.MkdirAll(filepath.Join(userDir, "globalStorage"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	for path, content := range map[string]string{
+		settingsPath: settings,
+		filepath.Join(userDir, "globalStorage", "state.vscdb"): "not part of the settings backup",
+	} {
+		if err := memFS.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewBackupManager()
+	manager.SetFileSystem(memFS)
+	manager.backupDirectory = filepath.FromSlash("/backups/extensions")
+	manager.now = func() time.Time { return now }
+	return manager, memFS, settingsPath, &now
+}
+
+func TestRestoreVSCodeSettings(t *testing.T) {
+	original := `{"telemetry.telemetryLevel":"all","editor.fontSize":14,"augment.enabled":true}`
+	manager, memFS, settingsPath, now := newMemSettingsManager(t, original)
+
+	backupPath, err := manager.CreateSettingsBackup(settingsPath)
+	if err != nil {
+		t.Fatalf("CreateSettingsBackup() failed: %v", err)
+	}
+	metadata, err := manager.loadBackupMetadata(strings.TrimSuffix(backupPath, ".zip") + ".metadata.json")
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if metadata.BackupType != BackupTypeVSCodeSettings || metadata.OriginalPath != settingsPath || metadata.FileCount != 1 {
+		t.Errorf("Expected a single-file settings backup of %s, got %+v", settingsPath, metadata)
+	}
+
+	*now = now.Add(time.Hour)
+	patched := `{"telemetry.telemetryLevel":"off","editor.fontSize":14,"workbench.enableExperiments":false}`
+	if err := memFS.WriteFile(settingsPath, []byte(patched), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	result, err := manager.RestoreVSCodeSettings("", true)
+	if err != nil {
+		t.Fatalf("RestoreVSCodeSettings() failed: %v", err)
+	}
+	if data, _ := memFS.ReadFile(settingsPath); string(data) != original {
+		t.Errorf("Expected the original settings restored, got %s", data)
+	}
+	if result.RestoredPath != settingsPath || result.BackupUsed.BackupPath != backupPath {
+		t.Errorf("Expected %s restored from %s, got %s from %s", settingsPath, backupPath, result.RestoredPath, result.BackupUsed.BackupPath)
+	}
+	want := []string{"augment.enabled", "telemetry.telemetryLevel", "workbench.enableExperiments"}
+	if !reflect.DeepEqual(result.SettingsRestored, want) {
+		t.Errorf("Expected changed settings %v, got %v", want, result.SettingsRestored)
+	}
+	if change := result.Changes[1]; change.Before != "off" || change.After != "all" {
+		t.Errorf("Expected telemetry.telemetryLevel to change from off to all, got %+v", change)
+	}
+	if result.PreRestoreBackup == "" {
+		t.Fatal("Expected the replaced settings to be backed up")
+	}
+
+	// The pre-restore backup is now the most recent and undoes the restore
+	*now = now.Add(time.Hour)
+	undo, err := manager.RestoreVSCodeSettings("", false)
+	if err != nil {
+		t.Fatalf("RestoreVSCodeSettings() of the pre-restore backup failed: %v", err)
+	}
+	if undo.BackupUsed.BackupPath != result.PreRestoreBackup || undo.PreRestoreBackup != "" {
+		t.Errorf("Expected the pre-restore backup to be used without a new backup, got %+v", undo)
+	}
+	if data, _ := memFS.ReadFile(settingsPath); string(data) != patched {
+		t.Errorf("Expected the patched settings restored, got %s", data)
+	}
+
+	// A specific backup is selected by ID
+	byID, err := manager.RestoreVSCodeSettings(metadata.BackupID, false)
+	if err != nil || byID.BackupUsed.BackupPath != backupPath {
+		t.Errorf("Expected backup %s to be restored by ID, got %+v (%v)", metadata.BackupID, byID, err)
+	}
+	if _, err := manager.RestoreVSCodeSettings("backup-1", false); err == nil {
+		t.Error("Expected an unknown backup ID to fail")
+	}
+}
+
+func TestRestoreVSCodeSettingsRejectsInvalidJSON(t *testing.T) {
+	manager, _, settingsPath, _ := newMemSettingsManager(t, `{"editor.fontSize": 14,`)
+
+	if _, err := manager.RestoreVSCodeSettings("", false); err == nil {
+		t.Error("Expected an error without any settings backup")
+	}
+
+	if _, err := manager.CreateSettingsBackup(settingsPath); err != nil {
+		t.Fatalf("CreateSettingsBackup() failed: %v", err)
+	}
+	if _, err := manager.RestoreVSCodeSettings("", false); err == nil {
+		t.Error("Expected restoring invalid JSON to fail")
+	}
+}
+
+// walkRecordingFS records the paths its Walk visits
+type walkRecordingFS struct {
+	*vfs.MemFS
+	visited []string
+}
+
+func (fs *walkRecordingFS) Walk(root string, fn filepath.WalkFunc) error {
+	return fs.MemFS.Walk(root, func(path string, info os.FileInfo, err error) error {
+		fs.visited = append(fs.visited, path)
+		return fn(path, info, err)
+	})
+}
+
+func TestCreateSettingsBackupSkipsOtherDirectories(t *testing.T) {
+	manager, memFS, settingsPath, _ := newMemSettingsManager(t, `{"editor.fontSize":14}`)
+	recording := &walkRecordingFS{MemFS: memFS}
+	manager.SetFileSystem(recording)
+
+	if _, err := manager.CreateSettingsBackup(settingsPath); err != nil {
+		t.Fatalf("CreateSettingsBackup() failed: %v", err)
+	}
+	for _, path := range recording.visited {
+		if strings.Contains(path, "globalStorage"+string(filepath.Separator)) {
+			t.Errorf("Expected globalStorage not to be walked, visited %s", path)
+		}
+	}
+}
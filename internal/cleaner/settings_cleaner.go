@@ -0,0 +1,211 @@
+package cleaner
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AugmentSettingPrefix prefixes the settings.json keys of the Augment extension
+const AugmentSettingPrefix = "augment."
+
+// CleanVSCodeSettingsOptions selects the settings CleanVSCodeSettings changes
+type CleanVSCodeSettingsOptions struct {
+	// Keys are the Augment settings to clean, as exact keys or path.Match patterns
+	// such as augment.chat.*; empty selects every Augment setting
+	Keys []string
+	// Defaults are values selected keys are reset to instead of being removed
+	Defaults map[string]interface{}
+	// Backup backs up settings.json before it is changed
+	Backup bool
+	// DryRun computes the change and its diff without writing settings.json
+	DryRun bool
+	// Validator decides which keys are protected; nil uses NewSafetyValidator()
+	Validator *SafetyValidator
+}
+
+// CleanVSCodeSettingsResult is the result of cleaning Augment settings from settings.json
+type CleanVSCodeSettingsResult struct {
+	SettingsPath    string          `json:"settings_path"`
+	SettingsRemoved []string        `json:"settings_removed"`
+	SettingsReset   []string        `json:"settings_reset"`
+	Changes         []SettingChange `json:"changes,omitempty"`
+	Protected       []string        `json:"protected,omitempty"` // Selected keys kept because the safety validator protects them
+	NotFound        []string        `json:"not_found,omitempty"` // Requested keys or patterns that matched no setting
+	Diff            string          `json:"diff,omitempty"`      // Unified diff of settings.json
+	BackupPath      string          `json:"backup_path,omitempty"`
+	DryRun          bool            `json:"dry_run"`
+	Duration        time.Duration   `json:"duration"`
+}
+
+// IsAugmentSetting reports whether a settings.json key belongs to the Augment extension
+func IsAugmentSetting(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), AugmentSettingPrefix)
+}
+
+// ManifestSettingDefaults returns the default values of the settings an extension
+// manifest contributes. contributes.configuration may be one object or a list.
+func ManifestSettingDefaults(manifest map[string]interface{}) map[string]interface{} {
+	defaults := make(map[string]interface{})
+	contributes, _ := manifest["contributes"].(map[string]interface{})
+
+	var configurations []interface{}
+	switch configuration := contributes["configuration"].(type) {
+	case map[string]interface{}:
+		configurations = []interface{}{configuration}
+	case []interface{}:
+		configurations = configuration
+	}
+
+	for _, configuration := range configurations {
+		section, _ := configuration.(map[string]interface{})
+		properties, _ := section["properties"].(map[string]interface{})
+		for key, property := range properties {
+			schema, _ := property.(map[string]interface{})
+			if value, ok := schema["default"]; ok {
+				defaults[key] = value
+			}
+		}
+	}
+	return defaults
+}
+
+// CleanVSCodeSettings removes the selected Augment settings from settings.json, or
+// resets those with a value in opts.Defaults. The file is edited as text, so its
+// comments and the formatting of other settings are kept. Keys protected by the
+// safety validator are never changed. The backup it creates is a settings backup
+// that RestoreVSCodeSettings can restore.
+func (bm *BackupManager) CleanVSCodeSettings(settingsPath string, opts CleanVSCodeSettingsOptions) (*CleanVSCodeSettingsResult, error) {
+	startTime := time.Now()
+
+	for _, key := range opts.Keys {
+		if !IsAugmentSetting(key) {
+			return nil, fmt.Errorf("%s is not an Augment setting", key)
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("invalid setting pattern %s: %w", key, err)
+		}
+	}
+	validator := opts.Validator
+	if validator == nil {
+		validator = NewSafetyValidator()
+	}
+
+	info, err := bm.fs.Stat(settingsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	original, err := bm.fs.ReadFile(settingsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	var before map[string]interface{}
+	if err := parseJSONC(original, &before); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	members, err := parseJSONCMembers(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+
+	result := &CleanVSCodeSettingsResult{
+		SettingsPath:    settingsPath,
+		SettingsRemoved: make([]string, 0),
+		SettingsReset:   make([]string, 0),
+		DryRun:          opts.DryRun,
+	}
+
+	selected := make(map[string]bool)
+	matched := make(map[string]bool)
+	for _, member := range members {
+		if !IsAugmentSetting(member.key) || selected[member.key] {
+			continue
+		}
+		if patterns, ok := matchSettingKey(member.key, opts.Keys); ok {
+			for _, pattern := range patterns {
+				matched[pattern] = true
+			}
+			if validator.IsProtectedSettingKey(member.key) {
+				result.Protected = appendUnique(result.Protected, member.key)
+				continue
+			}
+			selected[member.key] = true
+		}
+	}
+	for _, key := range opts.Keys {
+		if !matched[key] {
+			result.NotFound = append(result.NotFound, key)
+		}
+	}
+
+	// Edit from the end so that the offsets of earlier members stay valid
+	edited := original
+	for i := len(members) - 1; i >= 0; i-- {
+		member := members[i]
+		if !selected[member.key] {
+			continue
+		}
+		if value, ok := opts.Defaults[member.key]; ok {
+			if edited, err = replaceJSONCValue(edited, member, value); err != nil {
+				return nil, err
+			}
+			result.SettingsReset = appendUnique(result.SettingsReset, member.key)
+		} else {
+			edited = removeJSONCMember(edited, members, i)
+			result.SettingsRemoved = appendUnique(result.SettingsRemoved, member.key)
+		}
+	}
+	sort.Strings(result.SettingsRemoved)
+	sort.Strings(result.SettingsReset)
+
+	var after map[string]interface{}
+	if err := parseJSONC(edited, &after); err != nil {
+		return nil, fmt.Errorf("edited settings are not valid JSON: %w", err)
+	}
+	result.Changes = diffSettings(before, after)
+	result.Diff = unifiedDiff("a/settings.json", "b/settings.json", original, edited)
+
+	if opts.DryRun || result.Diff == "" {
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if opts.Backup {
+		if result.BackupPath, err = bm.CreateSettingsBackup(settingsPath); err != nil {
+			return nil, fmt.Errorf("failed to back up settings: %w", err)
+		}
+	}
+	if err := bm.fs.WriteFile(settingsPath, edited, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write settings file: %w", err)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// matchSettingKey reports whether key is selected by patterns, returning the
+// patterns it matches; no patterns select every key
+func matchSettingKey(key string, patterns []string) ([]string, bool) {
+	if len(patterns) == 0 {
+		return nil, true
+	}
+	var matches []string
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			matches = append(matches, pattern)
+		}
+	}
+	return matches, len(matches) > 0
+}
+
+// appendUnique appends s to list unless it is already present
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
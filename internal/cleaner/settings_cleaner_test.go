@@ -0,0 +1,131 @@
+package cleaner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const augmentSettings = `{
+	// Editor
+	"editor.fontSize": 14,
+	"augment.chat.userGuidelines": "Prefer table-driven tests",
+	"augment.completions.enableAutomaticCompletions": false, // too noisy
+	"augment.enableEmptyFileHint": false,
+	"augment.authToken": "secret",
+	"augment.nextEdit.enabled": true
+}
+`
+
+func TestCleanVSCodeSettings(t *testing.T) {
+	manager, memFS, settingsPath, _ := newMemSettingsManager(t, augmentSettings)
+
+	dryRun, err := manager.CleanVSCodeSettings(settingsPath, CleanVSCodeSettingsOptions{Backup: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("CleanVSCodeSettings() dry run failed: %v", err)
+	}
+	if data, _ := memFS.ReadFile(settingsPath); string(data) != augmentSettings {
+		t.Errorf("Expected a dry run to leave settings.json unchanged, got %s", data)
+	}
+	if dryRun.BackupPath != "" {
+		t.Errorf("Expected no backup in a dry run, got %s", dryRun.BackupPath)
+	}
+	if !strings.Contains(dryRun.Diff, "-\t\"augment.enableEmptyFileHint\": false,\n") {
+		t.Errorf("Expected the diff to remove augment.enableEmptyFileHint, got\n%s", dryRun.Diff)
+	}
+
+	result, err := manager.CleanVSCodeSettings(settingsPath, CleanVSCodeSettingsOptions{
+		Defaults: map[string]interface{}{"augment.nextEdit.enabled": false},
+		Backup:   true,
+	})
+	if err != nil {
+		t.Fatalf("CleanVSCodeSettings() failed: %v", err)
+	}
+
+	want := `{
+	// Editor
+	"editor.fontSize": 14,
+	"augment.chat.userGuidelines": "Prefer table-driven tests",
+	"augment.authToken": "secret",
+	"augment.nextEdit.enabled": false
+}
+`
+	if data, _ := memFS.ReadFile(settingsPath); string(data) != want {
+		t.Errorf("Expected settings\n%s\ngot\n%s", want, data)
+	}
+	if removed := []string{"augment.completions.enableAutomaticCompletions", "augment.enableEmptyFileHint"}; !reflect.DeepEqual(result.SettingsRemoved, removed) {
+		t.Errorf("Expected removed settings %v, got %v", removed, result.SettingsRemoved)
+	}
+	if !reflect.DeepEqual(result.SettingsReset, []string{"augment.nextEdit.enabled"}) {
+		t.Errorf("Expected augment.nextEdit.enabled to be reset, got %v", result.SettingsReset)
+	}
+	if protected := []string{"augment.chat.userGuidelines", "augment.authToken"}; !reflect.DeepEqual(result.Protected, protected) {
+		t.Errorf("Expected protected settings %v, got %v", protected, result.Protected)
+	}
+	if len(result.Changes) != 3 || result.Diff == "" {
+		t.Errorf("Expected three changed settings and a diff, got %+v", result)
+	}
+
+	// The backup restores the settings as they were before cleaning
+	restored, err := manager.RestoreVSCodeSettings("", false)
+	if err != nil || restored.BackupUsed.BackupPath != result.BackupPath {
+		t.Fatalf("Expected the cleaning backup to be restored, got %+v (%v)", restored, err)
+	}
+	if data, _ := memFS.ReadFile(settingsPath); string(data) != augmentSettings {
+		t.Errorf("Expected the original settings restored, got %s", data)
+	}
+}
+
+func TestCleanVSCodeSettingsSelectsKeys(t *testing.T) {
+	manager, memFS, settingsPath, _ := newMemSettingsManager(t, augmentSettings)
+
+	validator := NewSafetyValidator()
+	validator.DisableSafetyRule("protect_authentication")
+	result, err := manager.CleanVSCodeSettings(settingsPath, CleanVSCodeSettingsOptions{
+		Keys:      []string{"augment.authToken", "augment.completions.*", "augment.missing"},
+		Validator: validator,
+	})
+	if err != nil {
+		t.Fatalf("CleanVSCodeSettings() failed: %v", err)
+	}
+	if removed := []string{"augment.authToken", "augment.completions.enableAutomaticCompletions"}; !reflect.DeepEqual(result.SettingsRemoved, removed) {
+		t.Errorf("Expected removed settings %v, got %v", removed, result.SettingsRemoved)
+	}
+	if !reflect.DeepEqual(result.NotFound, []string{"augment.missing"}) {
+		t.Errorf("Expected augment.missing not to be found, got %v", result.NotFound)
+	}
+	if result.BackupPath != "" {
+		t.Errorf("Expected no backup without Backup, got %s", result.BackupPath)
+	}
+	if data, _ := memFS.ReadFile(settingsPath); strings.Contains(string(data), "authToken") || !strings.Contains(string(data), "augment.enableEmptyFileHint") {
+		t.Errorf("Expected only the selected settings removed, got %s", data)
+	}
+
+	if _, err := manager.CleanVSCodeSettings(settingsPath, CleanVSCodeSettingsOptions{Keys: []string{"editor.fontSize"}}); err == nil {
+		t.Error("Expected a non-Augment setting to be rejected")
+	}
+}
+
+func TestManifestSettingDefaults(t *testing.T) {
+	manifest := map[string]interface{}{
+		"contributes": map[string]interface{}{
+			"configuration": []interface{}{
+				map[string]interface{}{"properties": map[string]interface{}{
+					"augment.enableEmptyFileHint": map[string]interface{}{"type": "boolean", "default": true},
+					"augment.chat.userGuidelines": map[string]interface{}{"type": "string"},
+				}},
+				map[string]interface{}{"properties": map[string]interface{}{
+					"augment.nextEdit.enabled": map[string]interface{}{"default": false},
+				}},
+			},
+		},
+	}
+
+	want := map[string]interface{}{"augment.enableEmptyFileHint": true, "augment.nextEdit.enabled": false}
+	if got := ManifestSettingDefaults(manifest); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected defaults %v, got %v", want, got)
+	}
+	if got := ManifestSettingDefaults(map[string]interface{}{}); len(got) != 0 {
+		t.Errorf("Expected no defaults without contributes, got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package cleaner
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// settingsSyncEnableKey is the state.vscdb key VS Code sets to true while
+// Settings Sync is turned on
+const settingsSyncEnableKey = "sync.enable"
+
+// minSyncReplaceIDLength keeps IDs too short to be unique from being replaced
+// in sync metadata
+const minSyncReplaceIDLength = 8
+
+// SettingsSyncState is what DetectSettingsSync found out about VS Code Settings Sync
+type SettingsSyncState struct {
+	// Enabled is set when state.vscdb has Settings Sync turned on
+	Enabled bool
+	// SyncDir is the User/sync directory Settings Sync keeps its metadata in,
+	// empty when it does not exist
+	SyncDir string
+}
+
+// Detected reports whether Settings Sync is on or has synced this installation
+func (s SettingsSyncState) Detected() bool {
+	return s.Enabled || s.SyncDir != ""
+}
+
+// DetectSettingsSync checks whether VS Code Settings Sync is turned on in the
+// state database and whether the User/sync directory exists. Settings Sync
+// uploads storage.json changes and can bring rotated telemetry IDs back from
+// another device. A database that cannot be read counts as sync turned off.
+func DetectSettingsSync(resolver utils.PathResolver) SettingsSyncState {
+	paths := utils.NewVSCodePaths(resolver)
+
+	var state SettingsSyncState
+	if userDir, err := paths.UserDir(); err == nil {
+		syncDir := filepath.Join(userDir, "sync")
+		if info, err := os.Stat(syncDir); err == nil && info.IsDir() {
+			state.SyncDir = syncDir
+		}
+	}
+	if dbPath, err := paths.DBPath(); err == nil {
+		state.Enabled, _ = settingsSyncEnabled(dbPath)
+	}
+	return state
+}
+
+// settingsSyncEnabled reads the Settings Sync switch from state.vscdb
+func settingsSyncEnabled(dbPath string) (bool, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return false, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return false, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var value string
+	err = db.QueryRow("SELECT value FROM ItemTable WHERE key = ?", settingsSyncEnableKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", settingsSyncEnableKey, err)
+	}
+	return strings.TrimSpace(value) == "true", nil
+}
+
+// rotateSyncMetadataIDs replaces the old IDs with the new ones in every file
+// below syncDir, so the next sync uploads the new IDs instead of restoring the
+// old ones. Each changed file is backed up first. It returns the files changed
+// and their backups, in path order.
+func rotateSyncMetadataIDs(syncDir string, replacements map[string]string) ([]string, []string, error) {
+	var oldIDs []string
+	for oldID, newID := range replacements {
+		if len(oldID) >= minSyncReplaceIDLength && oldID != newID {
+			oldIDs = append(oldIDs, oldID)
+		}
+	}
+	if len(oldIDs) == 0 {
+		return nil, nil, nil
+	}
+	sort.Strings(oldIDs)
+
+	var files []string
+	err := filepath.Walk(syncDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip what cannot be read
+		}
+		if info.Mode().IsRegular() && !strings.Contains(info.Name(), ".bak.") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read sync directory: %w", err)
+	}
+
+	var updated, backups []string
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return updated, backups, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		content := string(data)
+		for _, oldID := range oldIDs {
+			content = strings.ReplaceAll(content, oldID, replacements[oldID])
+		}
+		if content == string(data) {
+			continue
+		}
+
+		backupPath, err := utils.CreateBackup(path)
+		if err != nil {
+			return updated, backups, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		backups = append(backups, backupPath)
+		if err := utils.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			return updated, backups, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		updated = append(updated, path)
+	}
+	return updated, backups, nil
+}
@@ -0,0 +1,29 @@
+//go:build cgo
+
+package cleaner
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isSQLiteReadOnly reports whether err is SQLite refusing to write a read-only
+// database (SQLITE_READONLY) or lacking access rights (SQLITE_PERM)
+func isSQLiteReadOnly(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrReadonly || sqliteErr.Code == sqlite3.ErrPerm
+	}
+	return false
+}
+
+// isDatabaseLocked reports whether err is SQLite's "database is locked" (SQLITE_BUSY)
+// or "database table is locked" (SQLITE_LOCKED)
+func isDatabaseLocked(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
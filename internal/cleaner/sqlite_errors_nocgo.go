@@ -0,0 +1,22 @@
+//go:build !cgo
+
+package cleaner
+
+import "strings"
+
+// isSQLiteReadOnly reports whether err is SQLite refusing to write a read-only
+// database or lacking access rights. Without cgo the sqlite3 error type is not
+// available, so the messages of SQLITE_READONLY and SQLITE_PERM are matched.
+func isSQLiteReadOnly(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "attempt to write a readonly database") ||
+		strings.Contains(message, "access permission denied")
+}
+
+// isDatabaseLocked reports whether err is SQLite's "database is locked" or
+// "database table is locked", matched by message like isSQLiteReadOnly
+func isDatabaseLocked(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "database is locked") ||
+		strings.Contains(message, "database table is locked")
+}
@@ -0,0 +1,19 @@
+//go:build !cgo
+
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsSQLiteReadOnlyByMessage(t *testing.T) {
+	readOnly := fmt.Errorf("failed to delete: %w", errors.New("attempt to write a readonly database"))
+	if !IsPermissionError(readOnly) {
+		t.Errorf("Expected %v to be a permission error", readOnly)
+	}
+	if IsPermissionError(errors.New("database is locked")) {
+		t.Error("Expected a locked database not to be a permission error")
+	}
+}
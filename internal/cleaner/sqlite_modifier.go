@@ -1,10 +1,13 @@
 package cleaner
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
+	"io"
 	"os"
 
+	"augment-telemetry-cleaner/internal/filelock"
 	"augment-telemetry-cleaner/internal/utils"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -12,7 +15,18 @@ import (
 // DatabaseCleanResult contains the results of database cleaning operation
 type DatabaseCleanResult struct {
 	DBBackupPath string `json:"db_backup_path"`
-	DeletedRows  int64  `json:"deleted_rows"`
+	// DBBackupPaths are the backups of every cleaned database, starting with DBBackupPath
+	DBBackupPaths []string `json:"db_backup_paths,omitempty"`
+	DeletedRows   int64    `json:"deleted_rows"`
+	BytesFreed    int64    `json:"bytes_freed"` // Key and value bytes of the deleted rows
+	BatchCount    int      `json:"batch_count"`
+	LockRetries   int      `json:"lock_retries"`
+}
+
+// DatabaseCleanEstimate is what cleaning the database would delete (for dry-run)
+type DatabaseCleanEstimate struct {
+	Records int64 `json:"records"`
+	Bytes   int64 `json:"bytes"` // Key and value bytes of the records, as in DatabaseCleanResult.BytesFreed
 }
 
 // CleanAugmentData cleans augment-related data from the SQLite database
@@ -22,27 +36,96 @@ type DatabaseCleanResult struct {
 // 1. Gets the SQLite database path
 // 2. Creates a backup of the database file
 // 3. Opens the database connection
-// 4. Deletes records where key contains 'augment'
+// 4. Deletes records where key contains 'augment' in rate limited batches
 func CleanAugmentData() (*DatabaseCleanResult, error) {
-	dbPath, err := utils.GetDBPath()
+	return CleanAugmentDataWithLimiter(NewRateLimitedCleaner())
+}
+
+// CleanAugmentDataWithLimiter cleans augment-related data like CleanAugmentData,
+// using the given rate limiter to batch the deletes
+func CleanAugmentDataWithLimiter(limiter *RateLimitedCleaner) (*DatabaseCleanResult, error) {
+	dbPaths, err := utils.NewVSCodePaths(nil).DBPaths()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database path: %w", err)
 	}
 
-	// Check if database file exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("database file not found at: %s", dbPath)
+	return CleanAugmentDataFromPathsWithLimiter(dbPaths, true, limiter)
+}
+
+// CleanAugmentDataFromPathsWithLimiter cleans every database in dbPaths, e.g. of
+// the regular install and of the Snap package, like CleanAugmentDataFromPathWithLimiter
+// and adds up the results. When a database fails, the results so far, including
+// those of the databases already cleaned, are returned with the error.
+func CleanAugmentDataFromPathsWithLimiter(dbPaths []string, createBackup bool, limiter *RateLimitedCleaner) (*DatabaseCleanResult, error) {
+	total := &DatabaseCleanResult{}
+	for _, dbPath := range existingDatabases(dbPaths) {
+		result, err := CleanAugmentDataFromPathWithLimiter(dbPath, createBackup, limiter)
+		if result != nil {
+			if total.DBBackupPath == "" {
+				total.DBBackupPath = result.DBBackupPath
+			}
+			total.DBBackupPaths = append(total.DBBackupPaths, result.DBBackupPaths...)
+			total.DeletedRows += result.DeletedRows
+			total.BytesFreed += result.BytesFreed
+			total.BatchCount += result.BatchCount
+			total.LockRetries += result.LockRetries
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// existingDatabases returns the databases in dbPaths that exist. When none does,
+// the first one is returned so that cleaning it reports why it is missing.
+func existingDatabases(dbPaths []string) []string {
+	var existing []string
+	for _, dbPath := range dbPaths {
+		if _, err := os.Stat(dbPath); err == nil {
+			existing = append(existing, dbPath)
+		}
 	}
+	if len(existing) == 0 && len(dbPaths) > 0 {
+		return dbPaths[:1]
+	}
+	return existing
+}
 
-	// Create backup before modification
-	dbBackupPath, err := utils.CreateBackup(dbPath)
+// CleanAugmentDataFromPath cleans augment-related data from the database at dbPath
+// instead of the auto-detected one, e.g. of a portable VS Code or another variant
+func CleanAugmentDataFromPath(dbPath string, createBackup bool) (*DatabaseCleanResult, error) {
+	return CleanAugmentDataFromPathWithLimiter(dbPath, createBackup, NewRateLimitedCleaner())
+}
+
+// CleanAugmentDataFromPathWithLimiter cleans the database at dbPath like
+// CleanAugmentDataFromPath, using the given rate limiter to batch the deletes
+func CleanAugmentDataFromPathWithLimiter(dbPath string, createBackup bool, limiter *RateLimitedCleaner) (*DatabaseCleanResult, error) {
+	if err := ValidateDatabasePath(dbPath); err != nil {
+		return nil, err
+	}
+
+	// Lock the database file so concurrent runs cannot clean it at the same time
+	unlock, err := filelock.Lock(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create database backup: %w", err)
+		return nil, fmt.Errorf("failed to lock database file: %w", err)
 	}
+	defer unlock()
+	limiter.logger().Debug("Locked database %s", dbPath)
+
+	// Create backup before modification
+	var dbBackupPath string
+	if createBackup {
+		dbBackupPath, err = utils.CreateBackup(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create database backup: %w", err)
+		}
+		limiter.logger().Debug("Backed up %s to %s", dbPath, dbBackupPath)
 
-	// Verify backup was created successfully
-	if err := utils.VerifyBackup(dbBackupPath); err != nil {
-		return nil, fmt.Errorf("backup verification failed: %w", err)
+		// Verify backup was created successfully
+		if err := utils.VerifyBackup(dbBackupPath); err != nil {
+			return nil, fmt.Errorf("backup verification failed: %w", err)
+		}
 	}
 
 	// Connect to the database
@@ -57,67 +140,120 @@ func CleanAugmentData() (*DatabaseCleanResult, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Begin transaction for safety
-	tx, err := db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	// Delete in batches, each in its own transaction. The batches committed
+	// before a failure stay deleted, so they are reported with the error.
+	result, err := limiter.DeleteAugmentRows(db)
+	result.DBBackupPath = dbBackupPath
+	if dbBackupPath != "" {
+		result.DBBackupPaths = []string{dbBackupPath}
 	}
-	defer tx.Rollback() // Will be ignored if tx.Commit() succeeds
+	return result, err
+}
 
-	// Execute the delete query
-	result, err := tx.Exec("DELETE FROM ItemTable WHERE key LIKE '%augment%'")
+// GetAugmentDataCount returns the count of records containing 'augment' in their keys
+// This can be used for dry-run mode to show what would be deleted
+func GetAugmentDataCount() (int64, error) {
+	estimate, err := EstimateAugmentData()
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute delete query: %w", err)
+		return 0, err
 	}
+	return estimate.Records, nil
+}
 
-	// Get the number of affected rows
-	deletedRows, err := result.RowsAffected()
+// GetAugmentDataCountFromPath returns the number of records CleanAugmentDataFromPath
+// would delete from the database at dbPath
+func GetAugmentDataCountFromPath(dbPath string) (int64, error) {
+	estimate, err := EstimateAugmentDataFromPath(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get affected rows count: %w", err)
+		return 0, err
 	}
+	return estimate.Records, nil
+}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// EstimateAugmentData returns the records CleanAugmentData would delete and their
+// size, with the keys matching extraPatterns counted as in
+// RateLimitedCleaner.ExtraKeyPatterns
+func EstimateAugmentData(extraPatterns ...string) (*DatabaseCleanEstimate, error) {
+	dbPaths, err := utils.NewVSCodePaths(nil).DBPaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database path: %w", err)
 	}
 
-	return &DatabaseCleanResult{
-		DBBackupPath: dbBackupPath,
-		DeletedRows:  deletedRows,
-	}, nil
+	return EstimateAugmentDataFromPaths(dbPaths, extraPatterns...)
 }
 
-// GetAugmentDataCount returns the count of records containing 'augment' in their keys
-// This can be used for dry-run mode to show what would be deleted
-func GetAugmentDataCount() (int64, error) {
-	dbPath, err := utils.GetDBPath()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get database path: %w", err)
+// EstimateAugmentDataFromPaths adds up EstimateAugmentDataFromPath for every
+// database in dbPaths, as cleaned by CleanAugmentDataFromPathsWithLimiter
+func EstimateAugmentDataFromPaths(dbPaths []string, extraPatterns ...string) (*DatabaseCleanEstimate, error) {
+	total := &DatabaseCleanEstimate{}
+	for _, dbPath := range existingDatabases(dbPaths) {
+		estimate, err := EstimateAugmentDataFromPath(dbPath, extraPatterns...)
+		if err != nil {
+			return nil, err
+		}
+		total.Records += estimate.Records
+		total.Bytes += estimate.Bytes
 	}
+	return total, nil
+}
 
-	// Check if database file exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return 0, fmt.Errorf("database file not found at: %s", dbPath)
+// EstimateAugmentDataFromPath returns the records CleanAugmentDataFromPath would
+// delete from the database at dbPath and their size, with the keys matching
+// extraPatterns counted as in RateLimitedCleaner.ExtraKeyPatterns
+func EstimateAugmentDataFromPath(dbPath string, extraPatterns ...string) (*DatabaseCleanEstimate, error) {
+	if err := ValidateDatabasePath(dbPath); err != nil {
+		return nil, err
 	}
 
 	// Connect to the database
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
-		return 0, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Count records that would be deleted
-	var count int64
-	err = db.QueryRow("SELECT COUNT(*) FROM ItemTable WHERE key LIKE '%augment%'").Scan(&count)
+	var estimate DatabaseCleanEstimate
+	where, args := augmentKeyFilter(extraPatterns)
+	err = db.QueryRow("SELECT COUNT(*), COALESCE(SUM("+augmentRowBytesExpr+"), 0) FROM ItemTable WHERE "+where,
+		args...).Scan(&estimate.Records, &estimate.Bytes)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count records: %w", err)
+		return nil, fmt.Errorf("failed to count records: %w", err)
 	}
 
-	return count, nil
+	return &estimate, nil
+}
+
+// sqliteMagic is the start of the "SQLite format 3" header of every database file
+var sqliteMagic = []byte("SQLite")
+
+// ValidateDatabasePath checks that dbPath is an existing, readable SQLite database
+func ValidateDatabasePath(dbPath string) error {
+	info, err := os.Stat(dbPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("database file not found at: %s", dbPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to access database file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("database path is a directory: %s", dbPath)
+	}
+
+	file, err := os.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("database file is not readable: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, len(sqliteMagic))
+	if _, err := io.ReadFull(file, header); err != nil || !bytes.Equal(header, sqliteMagic) {
+		return fmt.Errorf("not a SQLite database: %s", dbPath)
+	}
+	return nil
 }
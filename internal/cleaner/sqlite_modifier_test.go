@@ -0,0 +1,173 @@
+package cleaner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanAugmentDataFromPath(t *testing.T) {
+	dbPath := createItemTableDB(t, 5, 3)
+
+	count, err := GetAugmentDataCountFromPath(dbPath)
+	if err != nil {
+		t.Fatalf("GetAugmentDataCountFromPath failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 records to preview, got %d", count)
+	}
+
+	result, err := CleanAugmentDataFromPath(dbPath, true)
+	if err != nil {
+		t.Fatalf("CleanAugmentDataFromPath failed: %v", err)
+	}
+	if result.DeletedRows != 5 {
+		t.Errorf("Expected 5 deleted rows, got %d", result.DeletedRows)
+	}
+	if result.DBBackupPath == "" {
+		t.Error("Expected a database backup")
+	} else if err := ValidateDatabasePath(result.DBBackupPath); err != nil {
+		t.Errorf("Expected the backup to be a database: %v", err)
+	}
+
+	if count, err := GetAugmentDataCountFromPath(dbPath); err != nil || count != 0 {
+		t.Errorf("Expected no records left, got %d (%v)", count, err)
+	}
+}
+
+func TestEstimateAugmentDataMatchesBytesFreed(t *testing.T) {
+	dbPath := createItemTableDB(t, 250, 10)
+
+	// Every Augment row is its key plus the one-byte value "v"
+	var want int64
+	for i := 0; i < 250; i++ {
+		want += int64(len(fmt.Sprintf("augment.vscode-augment.key%d", i)) + 1)
+	}
+
+	estimate, err := EstimateAugmentDataFromPath(dbPath)
+	if err != nil {
+		t.Fatalf("EstimateAugmentDataFromPath failed: %v", err)
+	}
+	if estimate.Records != 250 || estimate.Bytes != want {
+		t.Errorf("Expected 250 records of %d bytes, got %+v", want, estimate)
+	}
+
+	// Several batches are deleted, and each is measured
+	result, err := CleanAugmentDataFromPath(dbPath, false)
+	if err != nil {
+		t.Fatalf("CleanAugmentDataFromPath failed: %v", err)
+	}
+	if result.BatchCount < 2 || result.BytesFreed != estimate.Bytes {
+		t.Errorf("Expected %d bytes freed over several batches, got %d in %d batches", estimate.Bytes, result.BytesFreed, result.BatchCount)
+	}
+}
+
+func TestCleanAugmentDataFromPaths(t *testing.T) {
+	regular := createItemTableDB(t, 3, 1)
+	snap := createItemTableDB(t, 2, 1)
+	missing := filepath.Join(t.TempDir(), "state.vscdb")
+	dbPaths := []string{missing, regular, snap}
+
+	estimate, err := EstimateAugmentDataFromPaths(dbPaths)
+	if err != nil {
+		t.Fatalf("EstimateAugmentDataFromPaths failed: %v", err)
+	}
+	if estimate.Records != 5 {
+		t.Errorf("Expected 5 records in both databases, got %d", estimate.Records)
+	}
+
+	// The missing database is skipped since the others exist
+	result, err := CleanAugmentDataFromPathsWithLimiter(dbPaths, true, NewRateLimitedCleaner())
+	if err != nil {
+		t.Fatalf("CleanAugmentDataFromPathsWithLimiter failed: %v", err)
+	}
+	if result.DeletedRows != 5 {
+		t.Errorf("Expected 5 deleted rows, got %d", result.DeletedRows)
+	}
+	if len(result.DBBackupPaths) != 2 || result.DBBackupPath != result.DBBackupPaths[0] {
+		t.Errorf("Expected a backup of each database, got %q and %v", result.DBBackupPath, result.DBBackupPaths)
+	}
+
+	// With no database at all, the first one is reported
+	if _, err := CleanAugmentDataFromPathsWithLimiter([]string{missing}, true, NewRateLimitedCleaner()); err == nil {
+		t.Error("Expected an error when no database exists")
+	}
+}
+
+func TestCleanAugmentDataFromPathsKeepsResultsOnError(t *testing.T) {
+	regular := createItemTableDB(t, 3, 1)
+	broken := filepath.Join(t.TempDir(), "state.vscdb")
+	if err := os.WriteFile(broken, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", broken, err)
+	}
+
+	// The first database is cleaned before the second one fails
+	result, err := CleanAugmentDataFromPathsWithLimiter([]string{regular, broken}, true, NewRateLimitedCleaner())
+	if err == nil {
+		t.Fatal("Expected an error for the broken database")
+	}
+	if result == nil || result.DeletedRows != 3 || len(result.DBBackupPaths) != 1 {
+		t.Errorf("Expected the 3 rows and backup of the first database with the error, got %+v", result)
+	}
+}
+
+func TestCleanAugmentDataFromPathWithoutBackup(t *testing.T) {
+	dbPath := createItemTableDB(t, 2, 0)
+
+	result, err := CleanAugmentDataFromPath(dbPath, false)
+	if err != nil {
+		t.Fatalf("CleanAugmentDataFromPath failed: %v", err)
+	}
+	if result.DBBackupPath != "" {
+		t.Errorf("Expected no backup, got %s", result.DBBackupPath)
+	}
+	if backups, _ := filepath.Glob(dbPath + ".bak.*"); len(backups) > 0 {
+		t.Errorf("Expected no backup files, found %v", backups)
+	}
+}
+
+func TestValidateDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "state.vscdb")
+	if err := os.WriteFile(textPath, []byte(`{"not": "sqlite"}`), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	shortPath := filepath.Join(dir, "short.vscdb")
+	if err := os.WriteFile(shortPath, []byte("SQL"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"database", createItemTableDB(t, 0, 0), ""},
+		{"missing file", filepath.Join(dir, "missing.vscdb"), "not found"},
+		{"directory", dir, "is a directory"},
+		{"not sqlite", textPath, "not a SQLite database"},
+		{"truncated header", shortPath, "not a SQLite database"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDatabasePath(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected valid database, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Nothing is deleted from a file that is not a database
+	if _, err := CleanAugmentDataFromPath(textPath, false); err == nil {
+		t.Error("Expected CleanAugmentDataFromPath to reject a non-database file")
+	}
+}
@@ -0,0 +1,156 @@
+package cleaner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// AuditSignatureAlgorithm is the signature algorithm used for telemetry audit files
+const AuditSignatureAlgorithm = "HMAC-SHA256"
+
+// TelemetryAuditRecord describes a single telemetry ID rotation for audit purposes.
+// IDs are recorded as SHA-256 hashes; plaintext IDs are only included when requested.
+type TelemetryAuditRecord struct {
+	Timestamp           time.Time `json:"timestamp"`
+	StoragePath         string    `json:"storage_path"`
+	MachineIDPath       string    `json:"machine_id_path"`
+	StorageBackupPath   string    `json:"storage_backup_path"`
+	MachineIDBackupPath string    `json:"machine_id_backup_path,omitempty"`
+	OldMachineIDHash    string    `json:"old_machine_id_hash"`
+	NewMachineIDHash    string    `json:"new_machine_id_hash"`
+	OldDeviceIDHash     string    `json:"old_device_id_hash"`
+	NewDeviceIDHash     string    `json:"new_device_id_hash"`
+	OldMachineID        string    `json:"old_machine_id,omitempty"`
+	NewMachineID        string    `json:"new_machine_id,omitempty"`
+	OldDeviceID         string    `json:"old_device_id,omitempty"`
+	NewDeviceID         string    `json:"new_device_id,omitempty"`
+}
+
+// TelemetryAuditFile is the signed audit document written to disk as a single
+// line. Record holds the TelemetryAuditRecord exactly as it was signed, so the
+// signature covers the bytes in the file rather than a re-encoding of them.
+type TelemetryAuditFile struct {
+	Record    json.RawMessage `json:"record"`
+	Algorithm string          `json:"algorithm"`
+	Signature string          `json:"signature"`
+}
+
+// AuditVerifyResult contains the result of verifying an audit file
+type AuditVerifyResult struct {
+	AuditFilePath string               `json:"audit_file_path"`
+	Valid         bool                 `json:"valid"`
+	Record        TelemetryAuditRecord `json:"record"`
+}
+
+// newTelemetryAuditRecord builds an audit record from a modification result
+func newTelemetryAuditRecord(result *TelemetryModifyResult, storagePath, machineIDPath string, includePlaintext bool) TelemetryAuditRecord {
+	record := TelemetryAuditRecord{
+		Timestamp:           time.Now().UTC(),
+		StoragePath:         storagePath,
+		MachineIDPath:       machineIDPath,
+		StorageBackupPath:   result.StorageBackupPath,
+		MachineIDBackupPath: result.MachineIDBackupPath,
+		OldMachineIDHash:    hashAuditID(result.OldMachineID),
+		NewMachineIDHash:    hashAuditID(result.NewMachineID),
+		OldDeviceIDHash:     hashAuditID(result.OldDeviceID),
+		NewDeviceIDHash:     hashAuditID(result.NewDeviceID),
+	}
+
+	if includePlaintext {
+		record.OldMachineID = result.OldMachineID
+		record.NewMachineID = result.NewMachineID
+		record.OldDeviceID = result.OldDeviceID
+		record.NewDeviceID = result.NewDeviceID
+	}
+
+	return record
+}
+
+// writeTelemetryAuditFile signs the record and writes it next to the storage backups
+func writeTelemetryAuditFile(record TelemetryAuditRecord, key []byte) (string, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	auditFile := TelemetryAuditFile{
+		Record:    payload,
+		Algorithm: AuditSignatureAlgorithm,
+		Signature: signAuditPayload(payload, key),
+	}
+
+	// Not indented: MarshalIndent would re-indent the signed record
+	data, err := json.Marshal(auditFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit file: %w", err)
+	}
+	data = append(data, '\n')
+
+	auditPath := filepath.Join(filepath.Dir(record.StoragePath),
+		fmt.Sprintf("telemetry-audit.%d.json", record.Timestamp.Unix()))
+	if err := utils.WriteFileAtomic(auditPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write audit file: %w", err)
+	}
+
+	return auditPath, nil
+}
+
+// VerifyTelemetryAuditFile checks the HMAC signature of an audit file with the
+// given key. The signature is checked against the record's bytes as they appear
+// in the file, so any change to them, including fields the record does not
+// know, invalidates it.
+func VerifyTelemetryAuditFile(auditPath string, key []byte) (*AuditVerifyResult, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("audit key is required")
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit file: %w", err)
+	}
+
+	var auditFile TelemetryAuditFile
+	if err := json.Unmarshal(data, &auditFile); err != nil {
+		return nil, fmt.Errorf("failed to parse audit file: %w", err)
+	}
+
+	if auditFile.Algorithm != AuditSignatureAlgorithm {
+		return nil, fmt.Errorf("unsupported audit signature algorithm: %s", auditFile.Algorithm)
+	}
+
+	var record TelemetryAuditRecord
+	if err := json.Unmarshal(auditFile.Record, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse audit record: %w", err)
+	}
+
+	expected := signAuditPayload(auditFile.Record, key)
+	return &AuditVerifyResult{
+		AuditFilePath: auditPath,
+		Valid:         hmac.Equal([]byte(expected), []byte(auditFile.Signature)),
+		Record:        record,
+	}, nil
+}
+
+// signAuditPayload computes the hex-encoded HMAC-SHA256 of a serialized record
+func signAuditPayload(payload []byte, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hashAuditID returns the SHA-256 hash of an ID, or an empty string for an empty ID
+func hashAuditID(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTelemetryAuditFileSignAndVerify(t *testing.T) {
+	tempDir := t.TempDir()
+	key := []byte("audit-secret")
+
+	result := &TelemetryModifyResult{
+		OldMachineID:      "old-machine",
+		NewMachineID:      "new-machine",
+		OldDeviceID:       "old-device",
+		NewDeviceID:       "new-device",
+		StorageBackupPath: filepath.Join(tempDir, "storage.json.bak.1"),
+	}
+	storagePath := filepath.Join(tempDir, "storage.json")
+
+	record := newTelemetryAuditRecord(result, storagePath, filepath.Join(tempDir, "machineid"), false)
+	auditPath, err := writeTelemetryAuditFile(record, key)
+	if err != nil {
+		t.Fatalf("writeTelemetryAuditFile() failed: %v", err)
+	}
+
+	if filepath.Dir(auditPath) != tempDir {
+		t.Errorf("Expected audit file next to storage file, got %s", auditPath)
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("Failed to read audit file: %v", err)
+	}
+	if strings.Contains(string(data), "old-machine") {
+		t.Error("Expected plaintext IDs to be omitted by default")
+	}
+	if strings.Count(string(data), "\n") != 1 || !strings.HasSuffix(string(data), "\n") {
+		t.Errorf("Expected the audit file to be a single line, got %q", data)
+	}
+
+	verified, err := VerifyTelemetryAuditFile(auditPath, key)
+	if err != nil {
+		t.Fatalf("VerifyTelemetryAuditFile() failed: %v", err)
+	}
+	if !verified.Valid {
+		t.Error("Expected signature to be valid")
+	}
+	if verified.Record.OldMachineIDHash != hashAuditID("old-machine") {
+		t.Errorf("Unexpected old machine ID hash: %s", verified.Record.OldMachineIDHash)
+	}
+
+	// A wrong key must not verify
+	verified, err = VerifyTelemetryAuditFile(auditPath, []byte("wrong-key"))
+	if err != nil {
+		t.Fatalf("VerifyTelemetryAuditFile() with wrong key failed: %v", err)
+	}
+	if verified.Valid {
+		t.Error("Expected signature to be invalid with the wrong key")
+	}
+
+	// Any change to the signed bytes must not verify, including fields the
+	// record does not know and a repeated key, whose last value wins when parsed
+	forged := hashAuditID("forged")
+	tamperings := map[string]string{
+		"changed hash":  strings.Replace(string(data), hashAuditID("new-machine"), forged, 1),
+		"unknown field": strings.Replace(string(data), `"record":{`, `"record":{"note":"forged",`, 1),
+		"repeated key":  strings.Replace(string(data), `"new_device_id_hash"`, `"new_machine_id_hash":"`+forged+`","new_device_id_hash"`, 1),
+	}
+	for name, tampered := range tamperings {
+		if tampered == string(data) {
+			t.Fatalf("%s: tampering did not change the audit file", name)
+		}
+		if err := os.WriteFile(auditPath, []byte(tampered), 0600); err != nil {
+			t.Fatalf("Failed to write tampered audit file: %v", err)
+		}
+
+		verified, err = VerifyTelemetryAuditFile(auditPath, key)
+		if err != nil {
+			t.Fatalf("%s: VerifyTelemetryAuditFile() on tampered file failed: %v", name, err)
+		}
+		if verified.Valid {
+			t.Errorf("%s: expected tampered audit file to fail verification", name)
+		}
+	}
+}
+
+func TestTelemetryAuditRecordIncludePlaintext(t *testing.T) {
+	result := &TelemetryModifyResult{OldMachineID: "old-machine", NewMachineID: "new-machine"}
+
+	record := newTelemetryAuditRecord(result, "storage.json", "machineid", true)
+	if record.OldMachineID != "old-machine" || record.NewMachineID != "new-machine" {
+		t.Errorf("Expected plaintext IDs to be included, got %+v", record)
+	}
+}
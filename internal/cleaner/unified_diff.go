@@ -0,0 +1,133 @@
+package cleaner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// diffLine is a line of an edit script: ' ' kept, '-' removed or '+' added.
+// before and after are the 0-based line numbers the line is at or would be
+// inserted at in each file.
+type diffLine struct {
+	op            byte
+	text          string
+	before, after int
+}
+
+// unifiedDiff returns the changes from before to after in unified diff format,
+// labelled with fromFile and toFile, or "" when they are equal
+func unifiedDiff(fromFile, toFile string, before, after []byte) string {
+	if string(before) == string(after) {
+		return ""
+	}
+
+	script := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromFile, toFile)
+	for i := 0; i < len(script); {
+		if script[i].op == ' ' {
+			i++
+			continue
+		}
+
+		// Changes separated by at most twice the context share a hunk
+		last := i
+		for j := i + 1; j < len(script) && j-last-1 <= 2*diffContextLines; j++ {
+			if script[j].op != ' ' {
+				last = j
+			}
+		}
+		start := max(i-diffContextLines, 0)
+		stop := min(last+diffContextLines+1, len(script))
+		writeDiffHunk(&b, script[start:stop])
+		i = stop
+	}
+	return b.String()
+}
+
+// writeDiffHunk writes a hunk header and lines
+func writeDiffHunk(b *strings.Builder, hunk []diffLine) {
+	beforeCount, afterCount := 0, 0
+	for _, line := range hunk {
+		if line.op != '+' {
+			beforeCount++
+		}
+		if line.op != '-' {
+			afterCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(hunk[0].before, beforeCount), hunkRange(hunk[0].after, afterCount))
+	for _, line := range hunk {
+		b.WriteByte(line.op)
+		b.WriteString(line.text)
+		if !strings.HasSuffix(line.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the 1-based start and line count of a hunk; an empty range
+// starts at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines returns an edit script turning a into b that keeps their longest
+// common subsequence of lines
+func diffLines(a, b []string) []diffLine {
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	script := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			script = append(script, diffLine{op: ' ', text: a[i], before: i, after: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			script = append(script, diffLine{op: '-', text: a[i], before: i, after: j})
+			i++
+		default:
+			script = append(script, diffLine{op: '+', text: b[j], before: i, after: j})
+			j++
+		}
+	}
+	return script
+}
+
+// splitLines splits s into lines that keep their newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -10,15 +10,41 @@ import (
 	"strings"
 	"time"
 
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/scanner"
 	"augment-telemetry-cleaner/internal/utils"
 )
 
 // WorkspaceCleanResult contains the results of workspace cleaning operation
 type WorkspaceCleanResult struct {
 	BackupPath           string                    `json:"backup_path"`
+	// BackupPaths are the backups of every cleaned workspace storage directory, starting with BackupPath
+	BackupPaths          []string                  `json:"backup_paths,omitempty"`
 	DeletedFilesCount    int                       `json:"deleted_files_count"`
 	FailedOperations     []FailedOperation         `json:"failed_operations,omitempty"`
 	FailedCompressions   []FailedCompression       `json:"failed_compressions,omitempty"`
+	SkippedOpenWorkspaces []string                 `json:"skipped_open_workspaces,omitempty"`
+	// PermissionDenied lists the paths of FailedOperations that failed for lack of access rights
+	PermissionDenied     []string                  `json:"permission_denied,omitempty"`
+	// BytesFreed is the size of the deleted files
+	BytesFreed           int64                     `json:"bytes_freed"`
+}
+
+// WorkspaceCleanEstimate is what cleaning workspace storage would delete (for dry-run)
+type WorkspaceCleanEstimate struct {
+	Files                 int      `json:"files"`
+	Bytes                 int64    `json:"bytes"`
+	SkippedOpenWorkspaces []string `json:"skipped_open_workspaces,omitempty"`
+}
+
+// WorkspaceCleanOptions controls optional behaviour of workspace storage cleaning
+type WorkspaceCleanOptions struct {
+	// Force also deletes storage of workspaces that are currently open in VS Code
+	Force bool
+	// Logger receives DEBUG traces of every file deleted or skipped; may be nil
+	Logger logger.Leveled
+	// Resolver locates the workspace storage directory; nil uses the current user's
+	Resolver utils.PathResolver
 }
 
 // FailedOperation represents a failed file/directory operation
@@ -26,6 +52,8 @@ type FailedOperation struct {
 	Type  string `json:"type"`  // "file" or "directory"
 	Path  string `json:"path"`
 	Error string `json:"error"`
+	// PermissionDenied is set when the operation failed for lack of access rights
+	PermissionDenied bool `json:"permission_denied,omitempty"`
 }
 
 // FailedCompression represents a failed compression operation
@@ -39,16 +67,50 @@ type FailedCompression struct {
 // This function:
 // 1. Gets the workspace storage path
 // 2. Creates a zip backup of all files in the directory
-// 3. Deletes all files in the directory
+// 3. Deletes all files in the directory, except those of workspaces open in VS Code
 func CleanWorkspaceStorage() (*WorkspaceCleanResult, error) {
-	workspacePath, err := utils.GetWorkspaceStoragePath()
+	return CleanWorkspaceStorageWithOptions(WorkspaceCleanOptions{})
+}
+
+// CleanWorkspaceStorageWithOptions cleans workspace storage like CleanWorkspaceStorage.
+// Unless opts.Force is set, storage of workspaces open in VS Code is kept and
+// reported in SkippedOpenWorkspaces, since deleting it loses their extension state.
+// When a storage directory fails, the results of the directories already cleaned
+// are returned with the error.
+func CleanWorkspaceStorageWithOptions(opts WorkspaceCleanOptions) (*WorkspaceCleanResult, error) {
+	log := logger.OrDiscard(opts.Logger)
+
+	targets, err := workspaceCleanTargets(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
+		return nil, err
 	}
 
-	// Check if workspace directory exists
-	if _, err := os.Stat(workspacePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("workspace storage directory not found at: %s", workspacePath)
+	total := &WorkspaceCleanResult{}
+	for _, target := range targets {
+		result, err := cleanWorkspaceStorageDir(log, target)
+		if err != nil {
+			return total, err
+		}
+
+		if total.BackupPath == "" {
+			total.BackupPath = result.BackupPath
+		}
+		total.BackupPaths = append(total.BackupPaths, result.BackupPath)
+		total.DeletedFilesCount += result.DeletedFilesCount
+		total.FailedOperations = append(total.FailedOperations, result.FailedOperations...)
+		total.FailedCompressions = append(total.FailedCompressions, result.FailedCompressions...)
+		total.SkippedOpenWorkspaces = append(total.SkippedOpenWorkspaces, result.SkippedOpenWorkspaces...)
+		total.PermissionDenied = append(total.PermissionDenied, result.PermissionDenied...)
+		total.BytesFreed += result.BytesFreed
+	}
+	return total, nil
+}
+
+// cleanWorkspaceStorageDir backs up and cleans a single workspace storage directory
+func cleanWorkspaceStorageDir(log logger.Leveled, target workspaceCleanTarget) (*WorkspaceCleanResult, error) {
+	workspacePath, openWorkspaces := target.path, target.openWorkspaces
+	for _, hash := range openWorkspaces {
+		log.Debug("Skipped %s: workspace is open in VS Code", filepath.Join(workspacePath, hash))
 	}
 
 	// Create backup filename with timestamp
@@ -61,26 +123,130 @@ func CleanWorkspaceStorage() (*WorkspaceCleanResult, error) {
 		return nil, fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Count files before deletion
-	totalFiles, err := countFiles(workspacePath)
+	// List files before deletion, leaving out open workspaces
+	files, sizes, err := listFiles(workspacePath, openWorkspaces)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count files: %w", err)
 	}
 
-	// Delete all files in the directory
-	failedOperations, err := deleteWorkspaceContents(workspacePath)
+	// Delete all files in the directory, keeping open workspaces
+	var failedOperations []FailedOperation
+	if len(openWorkspaces) == 0 {
+		failedOperations, err = deleteWorkspaceContents(workspacePath)
+	} else {
+		failedOperations, err = deleteWorkspaceEntriesExcept(workspacePath, openWorkspaces)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete workspace contents: %w", err)
 	}
+	logDeletedFiles(log, files, failedOperations)
 
 	return &WorkspaceCleanResult{
-		BackupPath:         backupPath,
-		DeletedFilesCount:  totalFiles,
-		FailedOperations:   failedOperations,
-		FailedCompressions: failedCompressions,
+		BackupPath:            backupPath,
+		DeletedFilesCount:     len(files),
+		FailedOperations:      failedOperations,
+		FailedCompressions:    failedCompressions,
+		SkippedOpenWorkspaces: openWorkspaces,
+		PermissionDenied:      permissionDeniedPaths(failedOperations),
+		BytesFreed:            deletedBytes(files, sizes, failedOperations),
 	}, nil
 }
 
+// EstimateWorkspaceStorage returns the files CleanWorkspaceStorageWithOptions would
+// delete with the same options and their size, without modifying anything
+func EstimateWorkspaceStorage(opts WorkspaceCleanOptions) (*WorkspaceCleanEstimate, error) {
+	targets, err := workspaceCleanTargets(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &WorkspaceCleanEstimate{}
+	for _, target := range targets {
+		files, sizes, err := listFiles(target.path, target.openWorkspaces)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count files: %w", err)
+		}
+
+		estimate.Files += len(files)
+		estimate.Bytes += deletedBytes(files, sizes, nil)
+		estimate.SkippedOpenWorkspaces = append(estimate.SkippedOpenWorkspaces, target.openWorkspaces...)
+	}
+	return estimate, nil
+}
+
+// workspaceCleanTarget is a workspace storage directory and, unless
+// WorkspaceCleanOptions.Force is set, the hashes of its workspaces open in VS Code
+type workspaceCleanTarget struct {
+	path           string
+	openWorkspaces []string
+}
+
+// workspaceCleanTargets returns the workspace storage directories of the regular
+// install and of the Snap and Flatpak packages that exist. It fails when none does.
+func workspaceCleanTargets(opts WorkspaceCleanOptions) ([]workspaceCleanTarget, error) {
+	workspacePaths, err := utils.NewVSCodePaths(opts.Resolver).WorkspaceStoragePaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
+	}
+
+	var targets []workspaceCleanTarget
+	for _, workspacePath := range workspacePaths {
+		// Check if workspace directory exists
+		if _, err := os.Stat(workspacePath); os.IsNotExist(err) {
+			continue
+		}
+
+		target := workspaceCleanTarget{path: workspacePath}
+		if !opts.Force {
+			target.openWorkspaces, err = scanner.GetOpenWorkspacesIn(workspacePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to detect open workspaces: %w", err)
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("workspace storage directory not found at: %s", workspacePaths[0])
+	}
+	return targets, nil
+}
+
+// deleteWorkspaceEntriesExcept deletes every entry of the workspace storage directory
+// except the given workspace hash directories
+func deleteWorkspaceEntriesExcept(workspacePath string, keep []string) ([]FailedOperation, error) {
+	kept := make(map[string]bool, len(keep))
+	for _, hash := range keep {
+		kept[hash] = true
+	}
+
+	entries, err := os.ReadDir(workspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace storage: %w", err)
+	}
+
+	var failedOperations []FailedOperation
+	for _, entry := range entries {
+		if kept[entry.Name()] {
+			continue
+		}
+
+		path := filepath.Join(workspacePath, entry.Name())
+		if entry.IsDir() {
+			if err := os.RemoveAll(path); err != nil {
+				failedOperations = append(failedOperations, newFailedOperation("directory", path, err))
+			}
+			continue
+		}
+
+		if err := deleteFile(path); err != nil {
+			failedOperations = append(failedOperations, newFailedOperation("file", path, err))
+		}
+	}
+
+	return failedOperations, nil
+}
+
 // createZipBackup creates a zip backup of the workspace directory
 func createZipBackup(workspacePath, backupPath string) ([]FailedCompression, error) {
 	var failedCompressions []FailedCompression
@@ -173,19 +339,65 @@ func addFileToZip(zipWriter *zip.Writer, filePath, relPath string) error {
 	return nil
 }
 
-// countFiles counts the total number of files in the directory
-func countFiles(dirPath string) (int, error) {
-	count := 0
+// listFiles lists all files in the directory and their sizes, skipping the given
+// top-level subdirectories
+func listFiles(dirPath string, skipDirs []string) ([]string, map[string]int64, error) {
+	skip := make(map[string]bool, len(skipDirs))
+	for _, name := range skipDirs {
+		skip[filepath.Join(dirPath, name)] = true
+	}
+
+	var files []string
+	sizes := make(map[string]int64)
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Continue counting despite errors
 		}
-		if !info.IsDir() {
-			count++
+		if info.IsDir() {
+			if skip[path] {
+				return filepath.SkipDir
+			}
+			return nil
 		}
+		files = append(files, path)
+		sizes[path] = info.Size()
 		return nil
 	})
-	return count, err
+	return files, sizes, err
+}
+
+// deletedBytes sums the sizes of the listed files that were not part of a failed
+// operation, matching the files logDeletedFiles reports as deleted
+func deletedBytes(files []string, sizes map[string]int64, failedOperations []FailedOperation) int64 {
+	failed := make(map[string]bool, len(failedOperations))
+	for _, op := range failedOperations {
+		failed[op.Path] = true
+	}
+
+	var total int64
+	for _, file := range files {
+		if failed[file] || failed[filepath.Dir(file)] {
+			continue
+		}
+		total += sizes[file]
+	}
+	return total
+}
+
+// logDeletedFiles traces every listed file that was not part of a failed operation
+func logDeletedFiles(log logger.Leveled, files []string, failedOperations []FailedOperation) {
+	failed := make(map[string]bool, len(failedOperations))
+	for _, op := range failedOperations {
+		failed[op.Path] = true
+		log.Debug("Skipped %s: %s", op.Path, op.Error)
+	}
+
+	for _, file := range files {
+		if failed[file] || failed[filepath.Dir(file)] {
+			continue
+		}
+		log.Debug("Deleted %s", file)
+	}
 }
 
 // deleteWorkspaceContents deletes all contents of the workspace directory
@@ -202,11 +414,7 @@ func deleteWorkspaceContents(workspacePath string) ([]FailedOperation, error) {
 	// If bulk removal failed, try file-by-file approach
 	err = filepath.Walk(workspacePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			failedOperations = append(failedOperations, FailedOperation{
-				Type:  "unknown",
-				Path:  path,
-				Error: err.Error(),
-			})
+			failedOperations = append(failedOperations, newFailedOperation("unknown", path, err))
 			return nil // Continue walking
 		}
 
@@ -223,11 +431,7 @@ func deleteWorkspaceContents(workspacePath string) ([]FailedOperation, error) {
 		// Delete file
 		err = deleteFile(path)
 		if err != nil {
-			failedOperations = append(failedOperations, FailedOperation{
-				Type:  "file",
-				Path:  path,
-				Error: err.Error(),
-			})
+			failedOperations = append(failedOperations, newFailedOperation("file", path, err))
 		}
 
 		return nil
@@ -250,11 +454,7 @@ func deleteWorkspaceContents(workspacePath string) ([]FailedOperation, error) {
 	for i := len(directories) - 1; i >= 0; i-- {
 		err := os.Remove(directories[i])
 		if err != nil {
-			failedOperations = append(failedOperations, FailedOperation{
-				Type:  "directory",
-				Path:  directories[i],
-				Error: err.Error(),
-			})
+			failedOperations = append(failedOperations, newFailedOperation("directory", directories[i], err))
 		}
 	}
 
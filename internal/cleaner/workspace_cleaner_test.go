@@ -0,0 +1,185 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestDeleteWorkspaceEntriesExcept(t *testing.T) {
+	workspacePath := t.TempDir()
+	for _, hash := range []string{"open", "closed1", "closed2"} {
+		dir := filepath.Join(workspacePath, hash)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create workspace dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "state.vscdb"), []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	failed, err := deleteWorkspaceEntriesExcept(workspacePath, []string{"open"})
+	if err != nil {
+		t.Fatalf("deleteWorkspaceEntriesExcept() failed: %v", err)
+	}
+	if len(failed) != 0 {
+		t.Errorf("Unexpected failed operations: %+v", failed)
+	}
+
+	entries, err := os.ReadDir(workspacePath)
+	if err != nil {
+		t.Fatalf("Failed to read workspace storage: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "open" {
+		t.Errorf("Expected only the open workspace to remain, got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(workspacePath, "open", "state.vscdb")); err != nil {
+		t.Errorf("Expected open workspace contents to be kept: %v", err)
+	}
+}
+
+func TestListFilesSkipsKeptWorkspaces(t *testing.T) {
+	workspacePath := t.TempDir()
+	for _, hash := range []string{"open", "closed"} {
+		dir := filepath.Join(workspacePath, hash)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create workspace dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "state.vscdb"), []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	files, _, err := listFiles(workspacePath, []string{"open"})
+	if err != nil {
+		t.Fatalf("listFiles() failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(workspacePath, "closed", "state.vscdb") {
+		t.Errorf("Expected only the closed workspace file, got %v", files)
+	}
+}
+
+func TestLogDeletedFilesOmitsFailedPaths(t *testing.T) {
+	files := []string{
+		filepath.Join("ws", "a", "state.vscdb"),
+		filepath.Join("ws", "b", "state.vscdb"),
+	}
+	failed := []FailedOperation{{Path: filepath.Join("ws", "b"), Error: "permission denied"}}
+
+	var lines []string
+	logDeletedFiles(logger.FuncLogger(func(level logger.LogLevel, message string) {
+		lines = append(lines, message)
+	}), files, failed)
+
+	expected := []string{
+		"Skipped " + filepath.Join("ws", "b") + ": permission denied",
+		"Deleted " + files[0],
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestEstimateWorkspaceStorageMatchesBytesFreed(t *testing.T) {
+	home := t.TempDir()
+	opts := WorkspaceCleanOptions{Force: true, Resolver: utils.FakePathResolver{Home: home, OS: "linux"}}
+	workspacePath := filepath.Join(home, ".config", "Code", "User", "workspaceStorage")
+	for name, size := range map[string]int{
+		filepath.Join("a", "state.vscdb"):          1000,
+		filepath.Join("a", "augment", "index.bin"): 2500,
+		filepath.Join("b", "state.vscdb"):          300,
+	} {
+		path := filepath.Join(workspacePath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create workspace dir: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	estimate, err := EstimateWorkspaceStorage(opts)
+	if err != nil {
+		t.Fatalf("EstimateWorkspaceStorage() failed: %v", err)
+	}
+	if estimate.Files != 3 || estimate.Bytes != 3800 {
+		t.Errorf("Expected 3 files of 3800 bytes, got %+v", estimate)
+	}
+
+	result, err := CleanWorkspaceStorageWithOptions(opts)
+	if err != nil {
+		t.Fatalf("CleanWorkspaceStorageWithOptions() failed: %v", err)
+	}
+	if result.DeletedFilesCount != estimate.Files || result.BytesFreed != estimate.Bytes {
+		t.Errorf("Expected the estimated %d files and %d bytes to be freed, got %d files and %d bytes",
+			estimate.Files, estimate.Bytes, result.DeletedFilesCount, result.BytesFreed)
+	}
+}
+
+func TestCleanWorkspaceStorageCleansPackagedInstalls(t *testing.T) {
+	for name, packagedDir := range map[string]string{
+		"snap":    filepath.Join("snap", "code", "current", ".config", "Code"),
+		"flatpak": filepath.Join(".var", "app", "com.visualstudio.code", "config", "Code"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			opts := WorkspaceCleanOptions{Force: true, Resolver: utils.FakePathResolver{Home: home, OS: "linux"}}
+			workspacePaths := []string{
+				filepath.Join(home, ".config", "Code", "User", "workspaceStorage"),
+				filepath.Join(home, packagedDir, "User", "workspaceStorage"),
+			}
+			for _, workspacePath := range workspacePaths {
+				path := filepath.Join(workspacePath, "a", "state.vscdb")
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create workspace dir: %v", err)
+				}
+				if err := os.WriteFile(path, make([]byte, 100), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			estimate, err := EstimateWorkspaceStorage(opts)
+			if err != nil {
+				t.Fatalf("EstimateWorkspaceStorage() failed: %v", err)
+			}
+			if estimate.Files != 2 || estimate.Bytes != 200 {
+				t.Errorf("Expected 2 files of 200 bytes, got %+v", estimate)
+			}
+
+			result, err := CleanWorkspaceStorageWithOptions(opts)
+			if err != nil {
+				t.Fatalf("CleanWorkspaceStorageWithOptions() failed: %v", err)
+			}
+			if result.DeletedFilesCount != 2 || len(result.BackupPaths) != 2 {
+				t.Errorf("Expected both installs to be backed up and cleaned, got %+v", result)
+			}
+			for _, workspacePath := range workspacePaths {
+				if entries, err := os.ReadDir(workspacePath); err != nil || len(entries) != 0 {
+					t.Errorf("Expected %s to be emptied, got %v (%v)", workspacePath, entries, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletedBytesOmitsFailedPaths(t *testing.T) {
+	files := []string{
+		filepath.Join("ws", "a", "state.vscdb"),
+		filepath.Join("ws", "b", "state.vscdb"),
+		filepath.Join("ws", "c", "state.vscdb"),
+	}
+	sizes := map[string]int64{files[0]: 10, files[1]: 20, files[2]: 40}
+	failed := []FailedOperation{{Path: filepath.Join("ws", "b")}, {Path: files[2]}}
+
+	if got := deletedBytes(files, sizes, failed); got != 10 {
+		t.Errorf("Expected only the 10 bytes of the deleted file, got %d", got)
+	}
+}
@@ -0,0 +1,145 @@
+package cleaner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// OrphanedWorkspace is a workspace storage directory whose folder no longer exists
+type OrphanedWorkspace struct {
+	WorkspaceHash string `json:"workspace_hash"`
+	WorkspacePath string `json:"workspace_path"`
+	StoragePath   string `json:"storage_path"`
+	SizeBytes     int64  `json:"size_bytes"`
+	BackupPath    string `json:"backup_path,omitempty"`
+}
+
+// OrphanCleanResult contains the results of pruning orphaned workspace storage
+type OrphanCleanResult struct {
+	PrunedWorkspaces   []OrphanedWorkspace `json:"pruned_workspaces"`
+	ReclaimedBytes     int64               `json:"reclaimed_bytes"`
+	FailedOperations   []FailedOperation   `json:"failed_operations,omitempty"`
+	FailedCompressions []FailedCompression `json:"failed_compressions,omitempty"`
+	// PermissionDenied lists the paths of FailedOperations that failed for lack of access rights
+	PermissionDenied []string `json:"permission_denied,omitempty"`
+}
+
+// FindOrphanedWorkspaces lists workspace storage directories whose local folder no
+// longer exists. Directories without a resolvable workspace.json, remote
+// workspaces and folders that could not be checked, e.g. on an unmounted drive or
+// below an unreadable directory, are never considered orphaned.
+func FindOrphanedWorkspaces(workspaceStoragePath string) ([]OrphanedWorkspace, error) {
+	entries, err := os.ReadDir(workspaceStoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace storage directory: %w", err)
+	}
+
+	var orphans []OrphanedWorkspace
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		storagePath := filepath.Join(workspaceStoragePath, entry.Name())
+		location, err := scanner.ResolveWorkspaceLocation(storagePath)
+		if err != nil || location.Remote || location.Exists || location.Unknown {
+			continue
+		}
+
+		orphans = append(orphans, OrphanedWorkspace{
+			WorkspaceHash: entry.Name(),
+			WorkspacePath: location.Path,
+			StoragePath:   storagePath,
+			SizeBytes:     directorySize(storagePath),
+		})
+	}
+
+	return orphans, nil
+}
+
+// PruneOrphanedWorkspaceStorage removes only the orphaned workspace storage
+// directories, optionally zipping each one next to the workspace storage directory first
+func PruneOrphanedWorkspaceStorage(createBackups bool) (*OrphanCleanResult, error) {
+	return PruneOrphanedWorkspaceStorageWithResolver(utils.DefaultPathResolver(), createBackups)
+}
+
+// PruneOrphanedWorkspaceStorageWithResolver prunes the orphaned workspace storage
+// of the environment described by resolver. When a storage directory fails, the
+// workspaces already pruned are returned with the error.
+func PruneOrphanedWorkspaceStorageWithResolver(resolver utils.PathResolver, createBackups bool) (*OrphanCleanResult, error) {
+	workspaceStoragePaths, err := utils.NewVSCodePaths(resolver).WorkspaceStoragePaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
+	}
+
+	total := &OrphanCleanResult{PrunedWorkspaces: make([]OrphanedWorkspace, 0)}
+	for _, workspaceStoragePath := range workspaceStoragePaths {
+		result, err := pruneOrphanedWorkspaces(workspaceStoragePath, createBackups)
+		if err != nil {
+			return total, err
+		}
+
+		total.PrunedWorkspaces = append(total.PrunedWorkspaces, result.PrunedWorkspaces...)
+		total.ReclaimedBytes += result.ReclaimedBytes
+		total.FailedOperations = append(total.FailedOperations, result.FailedOperations...)
+		total.FailedCompressions = append(total.FailedCompressions, result.FailedCompressions...)
+		total.PermissionDenied = append(total.PermissionDenied, result.PermissionDenied...)
+	}
+	return total, nil
+}
+
+// pruneOrphanedWorkspaces removes the orphaned directories below workspaceStoragePath
+func pruneOrphanedWorkspaces(workspaceStoragePath string, createBackups bool) (*OrphanCleanResult, error) {
+	orphans, err := FindOrphanedWorkspaces(workspaceStoragePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OrphanCleanResult{PrunedWorkspaces: make([]OrphanedWorkspace, 0, len(orphans))}
+	timestamp := time.Now().Unix()
+
+	for _, orphan := range orphans {
+		if createBackups {
+			backupPath := fmt.Sprintf("%s_orphan_%s_backup_%d.zip", workspaceStoragePath, orphan.WorkspaceHash, timestamp)
+			failed, err := createZipBackup(orphan.StoragePath, backupPath)
+			result.FailedCompressions = append(result.FailedCompressions, failed...)
+			if err != nil {
+				// Never delete a directory we could not back up
+				result.FailedOperations = append(result.FailedOperations, newFailedOperation("directory", orphan.StoragePath, fmt.Errorf("backup failed: %w", err)))
+				continue
+			}
+			orphan.BackupPath = backupPath
+		}
+
+		if err := os.RemoveAll(orphan.StoragePath); err != nil {
+			result.FailedOperations = append(result.FailedOperations, newFailedOperation("directory", orphan.StoragePath, err))
+			continue
+		}
+
+		result.PrunedWorkspaces = append(result.PrunedWorkspaces, orphan)
+		result.ReclaimedBytes += orphan.SizeBytes
+	}
+	result.PermissionDenied = permissionDeniedPaths(result.FailedOperations)
+
+	return result, nil
+}
+
+// directorySize returns the total size of all files below dirPath
+func directorySize(dirPath string) int64 {
+	var size int64
+	filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Continue despite errors
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
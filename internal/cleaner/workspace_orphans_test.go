@@ -0,0 +1,105 @@
+package cleaner
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeWorkspaceFixture(t *testing.T, storageDir, hash, folder string) string {
+	t.Helper()
+
+	hashDir := filepath.Join(storageDir, hash)
+	if err := os.MkdirAll(filepath.Join(hashDir, "augment.vscode-augment"), 0755); err != nil {
+		t.Fatalf("Failed to create workspace fixture: %v", err)
+	}
+
+	folderURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(folder)}).String()
+	if err := os.WriteFile(filepath.Join(hashDir, "workspace.json"), []byte(`{"folder": "`+folderURI+`"}`), 0644); err != nil {
+		t.Fatalf("Failed to write workspace.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hashDir, "state.vscdb"), make([]byte, 1024), 0644); err != nil {
+		t.Fatalf("Failed to write state.vscdb: %v", err)
+	}
+
+	return hashDir
+}
+
+func TestPruneOrphanedWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	storageDir := filepath.Join(root, "workspaceStorage")
+	liveProject := filepath.Join(root, "live")
+	if err := os.MkdirAll(liveProject, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	liveDir := writeWorkspaceFixture(t, storageDir, "livehash", liveProject)
+	orphanDir := writeWorkspaceFixture(t, storageDir, "orphanhash", filepath.Join(root, "deleted"))
+
+	// A directory without workspace.json must be left alone
+	unknownDir := filepath.Join(storageDir, "unknownhash")
+	if err := os.MkdirAll(unknownDir, 0755); err != nil {
+		t.Fatalf("Failed to create unknown dir: %v", err)
+	}
+
+	orphans, err := FindOrphanedWorkspaces(storageDir)
+	if err != nil {
+		t.Fatalf("FindOrphanedWorkspaces() failed: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].WorkspaceHash != "orphanhash" {
+		t.Fatalf("Expected only orphanhash to be orphaned, got %+v", orphans)
+	}
+
+	result, err := pruneOrphanedWorkspaces(storageDir, true)
+	if err != nil {
+		t.Fatalf("pruneOrphanedWorkspaces() failed: %v", err)
+	}
+
+	if len(result.PrunedWorkspaces) != 1 {
+		t.Fatalf("Expected 1 pruned workspace, got %d", len(result.PrunedWorkspaces))
+	}
+	if result.ReclaimedBytes < 1024 {
+		t.Errorf("Expected at least 1024 reclaimed bytes, got %d", result.ReclaimedBytes)
+	}
+	if _, err := os.Stat(result.PrunedWorkspaces[0].BackupPath); err != nil {
+		t.Errorf("Expected backup zip to exist: %v", err)
+	}
+
+	if _, err := os.Stat(orphanDir); !os.IsNotExist(err) {
+		t.Error("Expected orphaned workspace storage to be removed")
+	}
+	for _, dir := range []string{liveDir, unknownDir} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("Expected %s to be left untouched: %v", dir, err)
+		}
+	}
+}
+
+func TestFindOrphanedWorkspacesSkipsUnreadableFolders(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("Directory permissions are not enforced on Windows or for root")
+	}
+
+	root := t.TempDir()
+	storageDir := filepath.Join(root, "workspaceStorage")
+	parent := filepath.Join(root, "locked")
+	if err := os.MkdirAll(filepath.Join(parent, "project"), 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	writeWorkspaceFixture(t, storageDir, "lockedhash", filepath.Join(parent, "project"))
+
+	if err := os.Chmod(parent, 0); err != nil {
+		t.Fatalf("Failed to lock parent dir: %v", err)
+	}
+	defer os.Chmod(parent, 0755)
+
+	orphans, err := FindOrphanedWorkspaces(storageDir)
+	if err != nil {
+		t.Fatalf("FindOrphanedWorkspaces() failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected a folder that cannot be checked not to be orphaned, got %+v", orphans)
+	}
+}
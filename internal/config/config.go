@@ -2,13 +2,21 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/retry"
+	"augment-telemetry-cleaner/internal/utils"
 )
 
 // Config represents the application configuration
 type Config struct {
+	// JSON Schema of the file for editors, e.g. a path to docs/config-schema.json
+	Schema              string `json:"$schema,omitempty"`
+	
 	// General settings
 	DryRunMode          bool   `json:"dry_run_mode"`
 	CreateBackups       bool   `json:"create_backups"`
@@ -23,6 +31,7 @@ type Config struct {
 	// Backup settings
 	BackupDirectory        string `json:"backup_directory"`
 	MaxBackupAge           int    `json:"max_backup_age_days"`
+	BrowserBackupDir       string `json:"browser_backup_dir,omitempty"` // Empty uses backups/browser-data
 	
 	// Safety settings
 	RequireConfirmation    bool   `json:"require_confirmation"`
@@ -31,6 +40,38 @@ type Config struct {
 	// Advanced settings
 	DatabaseTimeout        int    `json:"database_timeout_seconds"`
 	FileOperationRetries   int    `json:"file_operation_retries"`
+	RetryBaseDelayMs       int    `json:"retry_base_delay_ms"` // Wait before the first retry, doubled on each further one
+	RetryMaxDelayMs        int    `json:"retry_max_delay_ms"`  // Upper bound of a single wait
+	CleanRateLimit         RateLimitConfig `json:"clean_rate_limit"`
+	
+	// Storage size limits in MB per extension ID ("default" applies to unknown extensions)
+	StorageLimits          map[string]int64 `json:"storage_limits,omitempty"`
+	
+	// Number of largest telemetry items and extensions listed in scan statistics
+	TopOffenderCount       int    `json:"top_offender_count"`
+	
+	// Percentage of storage files a scan must analyze; below it the CLI exits with a warning code
+	MinScanCoverage        float64 `json:"min_scan_coverage"`
+	
+	// Extra process names closed before browser cleaning, keyed by chrome, edge, firefox or safari
+	BrowserProcessNames    map[string][]string `json:"browser_process_names,omitempty"`
+	
+	// Chromium extension IDs of Augment browser extensions whose data is removed
+	BrowserExtensionIDs    []string `json:"browser_extension_ids,omitempty"`
+	
+	// SQL LIKE patterns of Augment data added to the built-in ones, e.g. "%acme-ai%"
+	// for a deployment branded differently
+	CustomAugmentPatterns  []string `json:"custom_augment_patterns,omitempty"`
+	
+	// URL the CLI posts a JSON summary of each run to; empty disables the webhook
+	WebhookURL             string `json:"webhook_url,omitempty"`
+}
+
+// RateLimitConfig controls how database cleaning is batched
+type RateLimitConfig struct {
+	BatchSize     int `json:"batch_size"`      // Rows deleted per transaction
+	BatchDelayMs  int `json:"batch_delay_ms"`  // Pause between batches
+	LockBackoffMs int `json:"lock_backoff_ms"` // Pause after "database is locked" before retrying
 }
 
 // DefaultConfig returns a configuration with default values
@@ -45,7 +86,33 @@ func DefaultConfig() *Config {
 		ShowPreviewBeforeRun:   true,
 		DatabaseTimeout:        30,
 		FileOperationRetries:   3,
+		RetryBaseDelayMs:       100,
+		RetryMaxDelayMs:        2000,
+		TopOffenderCount:       10,
+		MinScanCoverage:        90,
+		CleanRateLimit: RateLimitConfig{
+			BatchSize:     100,
+			BatchDelayMs:  10,
+			LockBackoffMs: 250,
+		},
+	}
+}
+
+// RetryPolicy returns the retry policy for locked files and busy databases built
+// from file_operation_retries, retry_base_delay_ms and retry_max_delay_ms. Values
+// that are not positive keep the built-in defaults.
+func (c *Config) RetryPolicy() retry.Policy {
+	policy := retry.DefaultPolicy()
+	if c.FileOperationRetries > 0 {
+		policy.Attempts = c.FileOperationRetries
+	}
+	if c.RetryBaseDelayMs > 0 {
+		policy.BaseDelay = time.Duration(c.RetryBaseDelayMs) * time.Millisecond
 	}
+	if c.RetryMaxDelayMs > 0 {
+		policy.MaxDelay = time.Duration(c.RetryMaxDelayMs) * time.Millisecond
+	}
+	return policy
 }
 
 // ConfigManager manages application configuration
@@ -54,29 +121,83 @@ type ConfigManager struct {
 	config     *Config
 }
 
-// NewConfigManager creates a new configuration manager
-func NewConfigManager() (*ConfigManager, error) {
+// AppConfigDir returns the application config directory, creating it if necessary
+func AppConfigDir() (string, error) {
+	appConfigDir, err := AppConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+	
+	// Create application config directory
+	if err := os.MkdirAll(appConfigDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return appConfigDir, nil
+}
+
+// AppConfigDirPath returns the application config directory without creating it
+func AppConfigDirPath() (string, error) {
 	// Get user's config directory
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		// Fallback to home directory
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get user directories: %w", err)
+			return "", fmt.Errorf("failed to get user directories: %w", err)
 		}
 		configDir = filepath.Join(homeDir, ".config")
 	}
 	
-	// Create application config directory
-	appConfigDir := filepath.Join(configDir, "augment-telemetry-cleaner")
-	if err := os.MkdirAll(appConfigDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	return filepath.Join(configDir, "augment-telemetry-cleaner"), nil
+}
+
+// NewConfigManager creates a new configuration manager
+func NewConfigManager() (*ConfigManager, error) {
+	appConfigDir, err := AppConfigDir()
+	if err != nil {
+		return nil, err
 	}
 	
-	configPath := filepath.Join(appConfigDir, "config.json")
+	cm := newConfigManager(appConfigDir)
 	
+	// Load existing config if it exists
+	if err := cm.Load(); err != nil {
+		// A config file that does not match the schema is reported instead of
+		// replaced, so a mistyped value never costs the user their settings
+		var schemaErr *SchemaError
+		if errors.As(err, &schemaErr) {
+			return nil, err
+		}
+		
+		// If loading fails, save the default config
+		if saveErr := cm.Save(); saveErr != nil {
+			return nil, fmt.Errorf("failed to save default config: %w", saveErr)
+		}
+	}
+	
+	return cm, nil
+}
+
+// OpenConfigManager loads the configuration like NewConfigManager but never
+// creates the config directory or writes the config file. A config file that
+// cannot be read leaves the defaults in place; Validate reports why.
+func OpenConfigManager() (*ConfigManager, error) {
+	appConfigDir, err := AppConfigDirPath()
+	if err != nil {
+		return nil, err
+	}
+	
+	cm := newConfigManager(appConfigDir)
+	_ = cm.Load()
+	return cm, nil
+}
+
+// newConfigManager creates a configuration manager with default values for the
+// config file in appConfigDir
+func newConfigManager(appConfigDir string) *ConfigManager {
 	cm := &ConfigManager{
-		configPath: configPath,
+		configPath: filepath.Join(appConfigDir, "config.json"),
 		config:     DefaultConfig(),
 	}
 	
@@ -91,18 +212,12 @@ func NewConfigManager() (*ConfigManager, error) {
 		}
 	}
 	
-	// Load existing config if it exists
-	if err := cm.Load(); err != nil {
-		// If loading fails, save the default config
-		if saveErr := cm.Save(); saveErr != nil {
-			return nil, fmt.Errorf("failed to save default config: %w", saveErr)
-		}
-	}
-	
-	return cm, nil
+	return cm
 }
 
-// Load loads the configuration from file
+// Load loads the configuration from file. A file that does not match the config
+// file schema returns a *SchemaError listing every invalid value and leaves the
+// configuration unchanged.
 func (cm *ConfigManager) Load() error {
 	data, err := os.ReadFile(cm.configPath)
 	if err != nil {
@@ -113,6 +228,14 @@ func (cm *ConfigManager) Load() error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 	
+	validationErrs, err := validateDocument(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(validationErrs) > 0 {
+		return &SchemaError{Path: cm.configPath, Errors: validationErrs}
+	}
+	
 	if err := json.Unmarshal(data, cm.config); err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
@@ -127,7 +250,7 @@ func (cm *ConfigManager) Save() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 	
-	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
+	if err := utils.WriteFileAtomic(cm.configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	
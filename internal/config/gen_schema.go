@@ -0,0 +1,24 @@
+//go:build ignore
+
+// gen_schema writes the config file schema to config-schema.json, embedded by
+// the config package, and to docs/config-schema.json. Run it with go generate.
+package main
+
+import (
+	"log"
+	"os"
+
+	"augment-telemetry-cleaner/internal/config"
+)
+
+func main() {
+	data, err := config.MarshalSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, path := range []string{"config-schema.json", "../../docs/config-schema.json"} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package config
+
+//go:generate go run gen_schema.go
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/jsonschema"
+)
+
+// SchemaDraft is the JSON Schema dialect of the config file schema, the newest
+// one VS Code validates settings files with
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// configSchemaJSON is the schema GenerateSchema returns, committed as
+// config-schema.json and docs/config-schema.json by go generate
+//
+//go:embed config-schema.json
+var configSchemaJSON []byte
+
+// fieldDoc describes a config file property for the schema
+type fieldDoc struct {
+	description string
+	path        bool // A file or directory path
+	enum        []interface{}
+	minimum     *float64
+	maximum     *float64
+}
+
+// bound returns a pointer to v, for fieldDoc minimums and maximums
+func bound(v float64) *float64 {
+	return &v
+}
+
+// fieldDocs describes every config file property, keyed by its location, e.g.
+// clean_rate_limit.batch_size. TestConfigSchemaIsUpToDate fails for fields
+// missing here.
+var fieldDocs = map[string]fieldDoc{
+	"$schema":                          {description: "JSON Schema of this file, for validation and completion in editors"},
+	"dry_run_mode":                     {description: "Preview what operations would change without modifying any file"},
+	"create_backups":                   {description: "Back up files and databases before they are modified"},
+	"log_level":                        {description: "Least severe level written to the log", enum: []interface{}{"DEBUG", "INFO", "WARN", "ERROR"}},
+	"custom_storage_path":              {description: "VS Code storage.json to use instead of the detected one", path: true},
+	"custom_db_path":                   {description: "VS Code state.vscdb to use instead of the detected one", path: true},
+	"custom_workspace_path":            {description: "VS Code workspaceStorage directory to use instead of the detected one", path: true},
+	"custom_machine_id_path":           {description: "VS Code machineid file to use instead of the detected one", path: true},
+	"backup_directory":                 {description: "Directory backups are created in", path: true},
+	"max_backup_age_days":              {description: "Days backups are kept before they may be cleaned up", minimum: bound(0)},
+	"browser_backup_dir":               {description: "Directory browser profile backups are created in; empty uses backups/browser-data", path: true},
+	"require_confirmation":             {description: "Ask before modifying data"},
+	"show_preview_before_run":          {description: "Show what an operation will change before running it"},
+	"database_timeout_seconds":         {description: "Seconds to wait for a locked database", minimum: bound(0)},
+	"file_operation_retries":           {description: "Attempts for locked files and busy databases; 0 uses the built-in default", minimum: bound(0)},
+	"retry_base_delay_ms":              {description: "Milliseconds to wait before the first retry, doubled on each further one; 0 uses the built-in default", minimum: bound(0)},
+	"retry_max_delay_ms":               {description: "Upper bound in milliseconds of a single retry wait; 0 uses the built-in default", minimum: bound(0)},
+	"clean_rate_limit":                 {description: "How database cleaning is batched"},
+	"clean_rate_limit.batch_size":      {description: "Rows deleted per transaction; 0 uses the built-in default", minimum: bound(0)},
+	"clean_rate_limit.batch_delay_ms":  {description: "Milliseconds to pause between batches", minimum: bound(0)},
+	"clean_rate_limit.lock_backoff_ms": {description: "Milliseconds to pause after \"database is locked\" before retrying", minimum: bound(0)},
+	"storage_limits":                   {description: "Storage size limits in MB per extension ID; \"default\" applies to unknown extensions", minimum: bound(0)},
+	"top_offender_count":               {description: "Number of largest telemetry items and extensions listed in scan statistics", minimum: bound(0)},
+	"min_scan_coverage":                {description: "Percentage of storage files a scan must analyze; below it the CLI exits with a warning code", minimum: bound(0), maximum: bound(100)},
+	"browser_process_names":            {description: "Extra process names closed before browser cleaning, keyed by browser such as chrome, edge, firefox or safari"},
+	"browser_extension_ids":            {description: "Chromium extension IDs of Augment browser extensions whose data is removed"},
+	"custom_augment_patterns":          {description: "SQL LIKE patterns of Augment data added to the built-in ones, e.g. %acme-ai% for a differently branded deployment; patterns of nothing but % and _ are rejected"},
+	"webhook_url":                      {description: "URL the CLI posts a JSON summary of each run to; empty disables the webhook"},
+}
+
+// ValidationError is a config value that does not match the config file schema
+type ValidationError struct {
+	Field   string      `json:"field"` // Location of the value, e.g. clean_rate_limit.batch_size
+	Message string      `json:"message"`
+	Value   interface{} `json:"value"`
+}
+
+// Error returns the field and what is wrong with it
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// SchemaError is returned by Load for a config file that is valid JSON but does
+// not match the config file schema
+type SchemaError struct {
+	Path   string
+	Errors []ValidationError
+}
+
+// Error lists every invalid value of the config file
+func (e *SchemaError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, validationErr := range e.Errors {
+		lines[i] = "  " + validationErr.Error()
+	}
+	return fmt.Sprintf("invalid config file %s:\n%s", e.Path, strings.Join(lines, "\n"))
+}
+
+// GenerateSchema returns the JSON Schema of the config file, built from the json
+// tags of Config, the values of DefaultConfig and fieldDocs
+func GenerateSchema() (*jsonschema.Schema, error) {
+	defaults, err := toJSONValue(DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := objectSchema(reflect.TypeOf(Config{}), "", defaults.(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = SchemaDraft
+	schema.Title = "augment-telemetry-cleaner configuration"
+	schema.Description = "Configuration file of Augment Telemetry Cleaner (config.json in its config directory)"
+	return schema, nil
+}
+
+// MarshalSchema returns the config file schema as the indented JSON committed to
+// config-schema.json
+func MarshalSchema() ([]byte, error) {
+	schema, err := GenerateSchema()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// objectSchema returns the schema of struct type t at location prefix, with the
+// default of each property taken from defaults
+func objectSchema(t reflect.Type, prefix string, defaults map[string]interface{}) (*jsonschema.Schema, error) {
+	object := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonschema.Schema),
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		location := prefix + name
+		doc, ok := fieldDocs[location]
+		if !ok {
+			return nil, fmt.Errorf("no description of config field %s", location)
+		}
+
+		var property *jsonschema.Schema
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			nested, _ := defaults[name].(map[string]interface{})
+			var err error
+			if property, err = objectSchema(field.Type, location+".", nested); err != nil {
+				return nil, err
+			}
+		case reflect.Map:
+			values := valueSchema(field.Type.Elem(), doc)
+			property = &jsonschema.Schema{Type: "object", AdditionalProperties: values}
+		case reflect.Slice:
+			property = &jsonschema.Schema{Type: "array", Items: valueSchema(field.Type.Elem(), fieldDoc{})}
+		default:
+			property = valueSchema(field.Type, doc)
+		}
+
+		property.Description = doc.description
+		if value, ok := defaults[name]; ok && value != "" && field.Type.Kind() != reflect.Struct {
+			property.Default = value
+		}
+		object.Properties[name] = property
+	}
+
+	return object, nil
+}
+
+// valueSchema returns the schema of a scalar or string slice value of type t
+func valueSchema(t reflect.Type, doc fieldDoc) *jsonschema.Schema {
+	schema := &jsonschema.Schema{Enum: doc.enum, Minimum: doc.minimum, Maximum: doc.maximum}
+	switch t.Kind() {
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice:
+		schema.Type = "array"
+		schema.Items = valueSchema(t.Elem(), fieldDoc{})
+	default:
+		schema.Type = "string"
+	}
+	return schema
+}
+
+// toJSONValue returns v as encoding/json decodes its JSON encoding
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// configSchema returns the embedded config file schema
+func configSchema() *jsonschema.Schema {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(configSchemaJSON, &schema); err != nil {
+		panic(fmt.Sprintf("embedded config schema is invalid: %v", err))
+	}
+	return &schema
+}
+
+// ValidateAgainstSchema checks cfg against the config file schema and returns
+// every value that does not match it, e.g. a log level that does not exist or a
+// negative retry count; an empty result means cfg is valid
+func (cm *ConfigManager) ValidateAgainstSchema(cfg *Config) []ValidationError {
+	value, err := toJSONValue(cfg)
+	if err != nil {
+		return []ValidationError{{Message: fmt.Sprintf("failed to encode config: %v", err)}}
+	}
+	return validateValue(configSchema(), value, "")
+}
+
+// validateDocument checks a config file against the config file schema. The
+// error is set when data is not JSON.
+func validateDocument(data []byte) ([]ValidationError, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return validateValue(configSchema(), value, ""), nil
+}
+
+// validateValue checks value at location field against schema, collecting every
+// mismatch rather than stopping at the first
+func validateValue(schema *jsonschema.Schema, value interface{}, field string) []ValidationError {
+	invalid := func(format string, args ...interface{}) []ValidationError {
+		return []ValidationError{{Field: field, Message: fmt.Sprintf(format, args...), Value: value}}
+	}
+
+	if schemaType, ok := schema.Type.(string); ok && !matchesSchemaType(schemaType, value) {
+		return invalid("must be %s, got %s", describeType(schemaType, field), jsonTypeName(value))
+	}
+
+	if len(schema.Enum) > 0 {
+		allowed := false
+		for _, option := range schema.Enum {
+			allowed = allowed || option == value
+		}
+		if !allowed {
+			options := make([]string, len(schema.Enum))
+			for i, option := range schema.Enum {
+				options[i] = fmt.Sprint(option)
+			}
+			return invalid("must be one of %s, got %q", strings.Join(options, ", "), fmt.Sprint(value))
+		}
+	}
+
+	var errs []ValidationError
+	switch v := value.(type) {
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			return invalid("must be at least %v, got %v", *schema.Minimum, v)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			return invalid("must be at most %v, got %v", *schema.Maximum, v)
+		}
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			location := name
+			if field != "" {
+				location = field + "." + name
+			}
+			property, ok := schema.Properties[name]
+			if !ok {
+				switch additional := schema.AdditionalProperties.(type) {
+				case map[string]interface{}:
+					property = schemaFromValue(additional)
+				case *jsonschema.Schema:
+					property = additional
+				default:
+					errs = append(errs, ValidationError{Field: location, Message: "unknown setting; check its spelling", Value: v[name]})
+					continue
+				}
+			}
+			errs = append(errs, validateValue(property, v[name], location)...)
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				errs = append(errs, validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+// schemaFromValue converts an additionalProperties schema that encoding/json
+// decoded into a map back into a *jsonschema.Schema
+func schemaFromValue(value map[string]interface{}) *jsonschema.Schema {
+	data, _ := json.Marshal(value)
+	var schema jsonschema.Schema
+	_ = json.Unmarshal(data, &schema)
+	return &schema
+}
+
+// matchesSchemaType reports whether value has the JSON type schemaType
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	actual := jsonTypeName(value)
+	return actual == schemaType || (schemaType == "number" && actual == "integer")
+}
+
+// describeType returns how messages name the values of schemaType at field
+func describeType(schemaType, field string) string {
+	switch schemaType {
+	case "boolean":
+		return "true or false"
+	case "integer":
+		return "an integer"
+	case "number":
+		return "a number"
+	case "string":
+		if fieldDocs[field].path {
+			return "a string path"
+		}
+		return "a string"
+	case "array":
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// jsonTypeName returns the JSON Schema type of a value decoded by encoding/json
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
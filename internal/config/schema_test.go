@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchemaIsUpToDate(t *testing.T) {
+	generated, err := MarshalSchema()
+	if err != nil {
+		t.Fatalf("MarshalSchema() failed: %v", err)
+	}
+	docs, err := os.ReadFile(filepath.Join("..", "..", "docs", "config-schema.json"))
+	if err != nil {
+		t.Fatalf("Failed to read docs/config-schema.json: %v", err)
+	}
+	if !bytes.Equal(generated, configSchemaJSON) || !bytes.Equal(generated, docs) {
+		t.Error("Config schema files are out of date: run go generate ./internal/config")
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	cm := newConfigManager(t.TempDir())
+	if errs := cm.ValidateAgainstSchema(cm.GetConfig()); len(errs) != 0 {
+		t.Errorf("Expected the defaults to be valid, got %+v", errs)
+	}
+
+	cfg := DefaultConfig()
+	cfg.LogLevel = "verbose"
+	cfg.FileOperationRetries = -1
+	cfg.MinScanCoverage = 150
+	cfg.StorageLimits = map[string]int64{"default": -5}
+
+	want := []ValidationError{
+		{Field: "file_operation_retries", Message: "must be at least 0, got -1", Value: float64(-1)},
+		{Field: "log_level", Message: `must be one of DEBUG, INFO, WARN, ERROR, got "verbose"`, Value: "verbose"},
+		{Field: "min_scan_coverage", Message: "must be at most 100, got 150", Value: float64(150)},
+		{Field: "storage_limits.default", Message: "must be at least 0, got -5", Value: float64(-5)},
+	}
+	if errs := cm.ValidateAgainstSchema(cfg); !reflect.DeepEqual(errs, want) {
+		t.Errorf("Expected errors %+v, got %+v", want, errs)
+	}
+}
+
+func TestLoadRejectsSchemaViolations(t *testing.T) {
+	dir := t.TempDir()
+	cm := newConfigManager(dir)
+	cm.config.BackupDirectory = filepath.Join(dir, "backups")
+	invalid := `{
+		"$schema": "./config-schema.json",
+		"backup_directory": 5,
+		"log_level": "DEBUG",
+		"clean_rate_limit": {"batch_size": "100"},
+		"browser_extension_ids": ["abc", 7],
+		"dry_run": false
+	}`
+	if err := os.WriteFile(cm.configPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	err := cm.Load()
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected a *SchemaError, got %v", err)
+	}
+	for _, message := range []string{
+		"backup_directory: must be a string path, got integer",
+		"browser_extension_ids[1]: must be a string, got integer",
+		"clean_rate_limit.batch_size: must be an integer, got string",
+		"dry_run: unknown setting",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected the error to contain %q, got %q", message, err.Error())
+		}
+	}
+	if len(schemaErr.Errors) != 4 {
+		t.Errorf("Expected 4 errors, got %+v", schemaErr.Errors)
+	}
+	if cm.GetConfig().LogLevel != "INFO" {
+		t.Error("Expected an invalid config file to leave the configuration unchanged")
+	}
+	if failures := cm.Validate(); len(failures) != 4 || failures[0].Check != "config file" {
+		t.Errorf("Expected Validate to report the 4 schema errors, got %+v", failures)
+	}
+
+	// $schema is kept when the configuration is saved again
+	valid := `{"$schema": "./config-schema.json", "log_level": "DEBUG"}`
+	if err := os.WriteFile(cm.configPath, []byte(valid), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(cm.configPath); !strings.Contains(string(data), `"$schema": "./config-schema.json"`) {
+		t.Errorf("Expected $schema to be saved, got %s", data)
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"augment-telemetry-cleaner/internal/likepattern"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// ValidationFailure is a check that failed while validating the configuration
+// or the paths an operation would use
+type ValidationFailure struct {
+	Check   string `json:"check"`
+	Path    string `json:"path,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// Validate checks that the config file can be parsed and matches the config file
+// schema, and that every configured path is usable: custom VS Code paths must
+// exist and be readable and writable, and backup directories must be writable or
+// creatable. Nothing is created or written; an empty result means the
+// configuration is valid.
+func (cm *ConfigManager) Validate() []ValidationFailure {
+	var failures []ValidationFailure
+
+	if data, err := os.ReadFile(cm.configPath); err != nil {
+		if !os.IsNotExist(err) {
+			failures = append(failures, ValidationFailure{"config file", cm.configPath, err.Error()})
+		}
+	} else if validationErrs, err := validateDocument(data); err != nil {
+		failures = append(failures, ValidationFailure{"config file", cm.configPath, fmt.Sprintf("failed to parse config file: %v", err)})
+	} else {
+		for _, validationErr := range validationErrs {
+			failures = append(failures, ValidationFailure{"config file", cm.configPath, validationErr.Error()})
+		}
+	}
+
+	customPaths := []struct {
+		check string
+		path  string
+	}{
+		{"custom storage path", cm.config.CustomStoragePath},
+		{"custom database path", cm.config.CustomDBPath},
+		{"custom workspace path", cm.config.CustomWorkspacePath},
+		{"custom machine ID path", cm.config.CustomMachineIDPath},
+	}
+	for _, custom := range customPaths {
+		if custom.path == "" {
+			continue
+		}
+		if err := utils.CheckAccess(custom.path, true); err != nil {
+			failures = append(failures, ValidationFailure{custom.check, custom.path, err.Error()})
+		}
+	}
+
+	if cm.config.BackupDirectory == "" {
+		failures = append(failures, ValidationFailure{"backup directory", "", "no backup directory configured"})
+	} else if err := utils.CheckWritableDir(cm.config.BackupDirectory); err != nil {
+		failures = append(failures, ValidationFailure{"backup directory", cm.config.BackupDirectory, err.Error()})
+	}
+	if cm.config.BrowserBackupDir != "" {
+		if err := utils.CheckWritableDir(cm.config.BrowserBackupDir); err != nil {
+			failures = append(failures, ValidationFailure{"browser backup directory", cm.config.BrowserBackupDir, err.Error()})
+		}
+	}
+
+	for _, pattern := range cm.config.CustomAugmentPatterns {
+		if err := likepattern.Validate(pattern); err != nil {
+			failures = append(failures, ValidationFailure{"custom Augment pattern", "", err.Error()})
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	cm := newConfigManager(dir)
+	cm.config.BackupDirectory = filepath.Join(dir, "backups")
+
+	if failures := cm.Validate(); len(failures) != 0 {
+		t.Errorf("Expected defaults without a config file to be valid, got %+v", failures)
+	}
+
+	if err := os.WriteFile(cm.configPath, []byte(`{"log_level": "INFO",`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	cm.config.CustomDBPath = filepath.Join(dir, "missing.vscdb")
+	cm.config.BrowserBackupDir = cm.configPath // A file, not a directory
+
+	failures := cm.Validate()
+	checks := make(map[string]bool)
+	for _, failure := range failures {
+		checks[failure.Check] = true
+	}
+	for _, check := range []string{"config file", "custom database path", "browser backup directory"} {
+		if !checks[check] {
+			t.Errorf("Expected a %s failure, got %+v", check, failures)
+		}
+	}
+	if len(failures) != 3 {
+		t.Errorf("Expected 3 failures, got %+v", failures)
+	}
+
+	if _, err := os.Stat(cm.config.BackupDirectory); !os.IsNotExist(err) {
+		t.Error("Expected Validate not to create the backup directory")
+	}
+}
@@ -0,0 +1,77 @@
+// Package desktop registers the GUI with the desktop environment: a .desktop
+// entry on Linux and a Start Menu shortcut on Windows.
+package desktop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+	"strings"
+)
+
+const (
+	// AppID is the application ID of the GUI, as in FyneApp.toml
+	AppID = "com.vinaykoirala.augmenttelemetrycleaner"
+	// AppName is the name shown in application menus
+	AppName = "Augment Telemetry Cleaner"
+	// appComment is the menu tooltip
+	appComment = "Clean Augment telemetry data from VS Code"
+)
+
+// InstallEntry registers the GUI executable exePath with the desktop environment
+// and returns the path of the entry it created
+func InstallEntry(exePath string) (string, error) {
+	return installEntry(exePath)
+}
+
+// desktopEntry returns a freedesktop.org .desktop file launching exePath with the
+// icon at iconPath
+func desktopEntry(exePath, iconPath string) string {
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Type=Application\n")
+	b.WriteString("Name=" + AppName + "\n")
+	b.WriteString("GenericName=Telemetry Cleaner\n")
+	b.WriteString("Comment=" + appComment + "\n")
+	b.WriteString("Exec=" + quoteExec(exePath) + "\n")
+	b.WriteString("Icon=" + iconPath + "\n")
+	b.WriteString("Terminal=false\n")
+	b.WriteString("Categories=Utility;System;\n")
+	b.WriteString("Keywords=telemetry;privacy;vscode;augment;cleaner;\n")
+	return b.String()
+}
+
+// quoteExec quotes a path for the Exec key of a .desktop file, escaping the
+// characters the specification reserves inside quotes and field codes
+func quoteExec(path string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	quoted := `"` + replacer.Replace(path) + `"`
+	// The value is also a string, so each backslash is escaped once more
+	quoted = strings.ReplaceAll(quoted, `\`, `\\`)
+	return strings.ReplaceAll(quoted, "%", "%%")
+}
+
+// pngToICO wraps a PNG of at most 256x256 pixels in an ICO file, which Windows
+// shortcuts need for their icon
+func pngToICO(data []byte) ([]byte, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode icon: %w", err)
+	}
+	if config.Width > 256 || config.Height > 256 {
+		return nil, fmt.Errorf("icon is %dx%d, ICO entries are at most 256x256", config.Width, config.Height)
+	}
+
+	var ico bytes.Buffer
+	// ICONDIR: reserved, type 1 (icon), one image
+	binary.Write(&ico, binary.LittleEndian, [3]uint16{0, 1, 1})
+	// ICONDIRENTRY: a size of 0 means 256
+	ico.Write([]byte{byte(config.Width), byte(config.Height), 0, 0})
+	binary.Write(&ico, binary.LittleEndian, struct {
+		Planes, BitCount uint16
+		Size, Offset     uint32
+	}{1, 32, uint32(len(data)), 6 + 16})
+	ico.Write(data)
+	return ico.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/resources"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// installEntry writes the scalable icon to the hicolor theme and a .desktop file
+// to the applications directory of $XDG_DATA_HOME (~/.local/share)
+func installEntry(exePath string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := utils.GetHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+
+	iconPath := filepath.Join(dataHome, "icons", "hicolor", "scalable", "apps", AppID+".svg")
+	entryPath := filepath.Join(dataHome, "applications", AppID+".desktop")
+	for _, dir := range []string{filepath.Dir(iconPath), filepath.Dir(entryPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	if err := utils.WriteFileAtomic(iconPath, resources.ResourceIconSvg.StaticContent, 0644); err != nil {
+		return "", fmt.Errorf("failed to write icon: %w", err)
+	}
+	if err := utils.WriteFileAtomic(entryPath, []byte(desktopEntry(exePath, iconPath)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write desktop entry: %w", err)
+	}
+	return entryPath, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package desktop
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// installEntry is unsupported; macOS registers applications from their .app bundle
+func installEntry(exePath string) (string, error) {
+	return "", fmt.Errorf("desktop entries are not supported on %s", runtime.GOOS)
+}
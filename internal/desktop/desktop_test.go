@@ -0,0 +1,66 @@
+package desktop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/resources"
+)
+
+func TestDesktopEntry(t *testing.T) {
+	entry := desktopEntry("/opt/augment cleaner/augment-telemetry-cleaner", "/icons/"+AppID+".svg")
+
+	for _, line := range []string{
+		"[Desktop Entry]",
+		"Type=Application",
+		"Name=" + AppName,
+		`Exec="/opt/augment cleaner/augment-telemetry-cleaner"`,
+		"Icon=/icons/" + AppID + ".svg",
+		"Terminal=false",
+	} {
+		if !strings.Contains(entry, line+"\n") {
+			t.Errorf("Expected line %q in desktop entry:\n%s", line, entry)
+		}
+	}
+}
+
+func TestQuoteExec(t *testing.T) {
+	tests := map[string]string{
+		"/usr/bin/cleaner":     `"/usr/bin/cleaner"`,
+		`/home/a"b/cleaner`:    `"/home/a\\"b/cleaner"`,
+		`/home/$USER/cleaner`:  `"/home/\\$USER/cleaner"`,
+		`/home/back\slash`:     `"/home/back\\\\slash"`,
+		"/home/100%/cleaner":   `"/home/100%%/cleaner"`,
+		"/home/`tick`/cleaner": "\"/home/\\\\`tick\\\\`/cleaner\"",
+	}
+	for path, want := range tests {
+		if got := quoteExec(path); got != want {
+			t.Errorf("quoteExec(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestPNGToICO(t *testing.T) {
+	data := resources.ResourceIconDarkPng.StaticContent
+	ico, err := pngToICO(data)
+	if err != nil {
+		t.Fatalf("pngToICO() failed: %v", err)
+	}
+
+	var header [3]uint16
+	if err := binary.Read(bytes.NewReader(ico), binary.LittleEndian, &header); err != nil || header != [3]uint16{0, 1, 1} {
+		t.Errorf("Unexpected ICO header %v (%v)", header, err)
+	}
+	if ico[6] != 0 || ico[7] != 0 {
+		t.Errorf("Expected a 256x256 entry written as 0x0, got %dx%d", ico[6], ico[7])
+	}
+	if !bytes.Equal(ico[22:], data) {
+		t.Error("Expected the PNG to follow the directory entry unchanged")
+	}
+
+	if _, err := pngToICO(resources.ResourceIconPng.StaticContent); err == nil {
+		t.Error("Expected an error for an icon larger than 256x256")
+	}
+}
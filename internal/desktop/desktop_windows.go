@@ -0,0 +1,63 @@
+//go:build windows
+
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/resources"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// shortcutScript creates the shortcut described by the environment variables
+// installEntry sets, which avoids quoting paths inside the script
+const shortcutScript = `$s = (New-Object -ComObject WScript.Shell).CreateShortcut($env:ATC_SHORTCUT)
+$s.TargetPath = $env:ATC_TARGET
+$s.WorkingDirectory = $env:ATC_WORKDIR
+$s.IconLocation = $env:ATC_ICON
+$s.Description = $env:ATC_DESCRIPTION
+$s.Save()`
+
+// installEntry writes the framed icon, which stays visible on dark taskbars, to
+// %LOCALAPPDATA% and creates a Start Menu shortcut to exePath with PowerShell
+func installEntry(exePath string) (string, error) {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	appData := os.Getenv("APPDATA")
+	if localAppData == "" || appData == "" {
+		homeDir, err := utils.GetHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		localAppData = filepath.Join(homeDir, "AppData", "Local")
+		appData = filepath.Join(homeDir, "AppData", "Roaming")
+	}
+
+	ico, err := pngToICO(resources.ResourceIconDarkPng.StaticContent)
+	if err != nil {
+		return "", err
+	}
+	iconPath := filepath.Join(localAppData, AppName, "icon.ico")
+	if err := os.MkdirAll(filepath.Dir(iconPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(iconPath), err)
+	}
+	if err := utils.WriteFileAtomic(iconPath, ico, 0644); err != nil {
+		return "", fmt.Errorf("failed to write icon: %w", err)
+	}
+
+	shortcutPath := filepath.Join(appData, "Microsoft", "Windows", "Start Menu", "Programs", AppName+".lnk")
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", shortcutScript)
+	cmd.Env = append(os.Environ(),
+		"ATC_SHORTCUT="+shortcutPath,
+		"ATC_TARGET="+exePath,
+		"ATC_WORKDIR="+filepath.Dir(exePath),
+		"ATC_ICON="+iconPath,
+		"ATC_DESCRIPTION="+appComment,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to create Start Menu shortcut: %w: %s", err, output)
+	}
+	return shortcutPath, nil
+}
@@ -0,0 +1,52 @@
+// Package filelock provides advisory, non-blocking exclusive locks on files and
+// directories so that concurrent instances of the tool do not modify the same
+// backup or storage file at the same time.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLockBusy is returned when the path is already locked by another process or goroutine
+var ErrLockBusy = errors.New("file is locked by another operation")
+
+// Lock takes an exclusive lock on path without blocking. The returned unlock
+// function releases the lock; it should be deferred by the caller. Locks are
+// also released by the operating system when the process exits.
+func Lock(path string) (unlock func(), err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat lock target: %w", err)
+	}
+
+	file, err := lockFile(path, info.IsDir())
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// SidecarSuffix is appended to a file's path to name the lock file of LockSidecar
+const SidecarSuffix = ".lock"
+
+// LockSidecar takes an exclusive lock on "<path>.lock", creating it if needed,
+// instead of on path itself. Use it for files that are replaced by renaming a new
+// file over them, e.g. with utils.WriteFileAtomic: a lock on the replaced file is
+// lost with it, and on Windows the open lock handle makes the rename fail. The
+// lock file is left in place, since removing it could race with another locker.
+func LockSidecar(path string) (unlock func(), err error) {
+	lockPath := path + SidecarSuffix
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	file.Close()
+
+	return Lock(lockPath)
+}
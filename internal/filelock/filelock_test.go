@@ -0,0 +1,129 @@
+package filelock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockBusyFromSecondGoroutine(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path func(t *testing.T) string
+	}{
+		{"file", func(t *testing.T) string {
+			path := filepath.Join(t.TempDir(), "storage.json")
+			if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			return path
+		}},
+		{"directory", func(t *testing.T) string { return t.TempDir() }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := tc.path(t)
+
+			unlock, err := Lock(path)
+			if err != nil {
+				t.Fatalf("Lock() failed: %v", err)
+			}
+
+			errs := make(chan error)
+			go func() {
+				secondUnlock, err := Lock(path)
+				if err == nil {
+					secondUnlock()
+				}
+				errs <- err
+			}()
+
+			if err := <-errs; !errors.Is(err, ErrLockBusy) {
+				t.Fatalf("Expected ErrLockBusy while locked, got %v", err)
+			}
+
+			unlock()
+
+			go func() {
+				secondUnlock, err := Lock(path)
+				if err == nil {
+					secondUnlock()
+				}
+				errs <- err
+			}()
+
+			if err := <-errs; err != nil {
+				t.Errorf("Expected lock to succeed after unlock, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLockMissingPath(t *testing.T) {
+	if _, err := Lock(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error locking a missing path")
+	}
+}
+
+func TestLockFileStaysWritable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Lock() failed: %v", err)
+	}
+	defer unlock()
+
+	if err := os.WriteFile(path, []byte(`{"a":1}`), 0644); err != nil {
+		t.Errorf("Expected locked file to remain writable by the holder: %v", err)
+	}
+}
+
+func TestLockSidecarSurvivesReplace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "storage.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	unlock, err := LockSidecar(path)
+	if err != nil {
+		t.Fatalf("LockSidecar() failed: %v", err)
+	}
+
+	// Replace the file the way an atomic write does
+	tempPath := filepath.Join(dir, "storage.json.tmp")
+	if err := os.WriteFile(tempPath, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		t.Fatalf("Expected the locked file to be replaceable: %v", err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		secondUnlock, err := LockSidecar(path)
+		if err == nil {
+			secondUnlock()
+		}
+		errs <- err
+	}()
+	if err := <-errs; !errors.Is(err, ErrLockBusy) {
+		t.Fatalf("Expected ErrLockBusy after the file was replaced, got %v", err)
+	}
+
+	unlock()
+
+	secondUnlock, err := LockSidecar(path)
+	if err != nil {
+		t.Fatalf("Expected lock to succeed after unlock, got %v", err)
+	}
+	secondUnlock()
+
+	if _, err := os.Stat(path + SidecarSuffix); err != nil {
+		t.Errorf("Expected the lock file next to the locked file: %v", err)
+	}
+}
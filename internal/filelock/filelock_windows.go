@@ -0,0 +1,63 @@
+//go:build windows
+
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// dirLockFileName is the file locked on behalf of a directory, since Windows
+// cannot lock a directory handle
+const dirLockFileName = ".augment-cleaner.lock"
+
+// Windows byte-range locks are mandatory, so a single byte far beyond any real
+// file content is locked. This keeps the file itself readable and writable by
+// SQLite and by the tool while still excluding other lock holders.
+const (
+	lockOffsetLow  = 0xFFFFFFFE
+	lockOffsetHigh = 0x7FFFFFFF
+)
+
+// lockFile opens path (or a lock file inside it for directories) and takes an
+// exclusive byte-range lock with LockFileEx
+func lockFile(path string, isDir bool) (*os.File, error) {
+	target := path
+	flags := os.O_RDWR
+	if isDir {
+		target = filepath.Join(path, dirLockFileName)
+		flags |= os.O_CREATE
+	}
+
+	file, err := os.OpenFile(target, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock target: %w", err)
+	}
+
+	ol := lockOverlapped()
+	err = windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) || errors.Is(err, windows.ERROR_IO_PENDING) {
+			return nil, fmt.Errorf("%s: %w", path, ErrLockBusy)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// unlockFile releases the byte-range lock held on file
+func unlockFile(file *os.File) {
+	windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, lockOverlapped())
+}
+
+// lockOverlapped returns the OVERLAPPED structure selecting the locked byte
+func lockOverlapped() *windows.Overlapped {
+	return &windows.Overlapped{Offset: lockOffsetLow, OffsetHigh: lockOffsetHigh}
+}
@@ -0,0 +1,232 @@
+// Package firewall temporarily blocks outgoing connections to telemetry services
+// with the firewall of the operating system: iptables on Linux, a pf anchor on
+// macOS and Windows Defender Firewall through netsh. Changing firewall rules
+// requires root or administrator rights.
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"augment-telemetry-cleaner/internal/logger"
+)
+
+const (
+	// RuleName names the iptables comments and the netsh rule this package adds, so
+	// leftover rules can be found and removed by hand
+	RuleName = "augment-telemetry-cleaner"
+	// pfAnchor is the pf anchor the macOS rules are loaded into. The default
+	// pf.conf evaluates every anchor below com.apple.
+	pfAnchor = "com.apple/" + RuleName
+)
+
+// command is a program run to change the firewall
+type command struct {
+	name  string
+	args  []string
+	stdin string
+}
+
+// String returns the command line, for logs and errors
+func (c command) String() string {
+	return strings.Join(append([]string{c.name}, c.args...), " ")
+}
+
+// rule is a firewall change: add applies it and remove, given the output of add,
+// returns the command undoing it, or false when there is nothing to undo
+type rule struct {
+	add    command
+	remove func(addOutput string) (command, bool)
+}
+
+// removeWith returns a remove function that always runs cmd
+func removeWith(cmd command) func(string) (command, bool) {
+	return func(string) (command, bool) { return cmd, true }
+}
+
+var (
+	// lookupIP resolves the addresses of a domain; tests replace it
+	lookupIP = net.LookupIP
+	// runCommand runs a firewall command and returns its combined output; tests
+	// replace it
+	runCommand = func(cmd command) (string, error) {
+		c := exec.Command(cmd.name, cmd.args...)
+		if cmd.stdin != "" {
+			c.Stdin = strings.NewReader(cmd.stdin)
+		}
+		output, err := c.CombinedOutput()
+		return string(output), err
+	}
+
+	logMu sync.Mutex
+	log   = logger.Discard
+)
+
+// SetLogger sets the logger firewall changes and failures to undo them are
+// reported to; nil discards them
+func SetLogger(l logger.Leveled) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	log = logger.OrDiscard(l)
+}
+
+// currentLogger returns the logger set with SetLogger
+func currentLogger() logger.Leveled {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return log
+}
+
+// BlockTelemetryDomains resolves domains and blocks outgoing connections to their
+// addresses until unblock is called. Domains that do not resolve are skipped. On
+// error no rule is left in place; unblock logs the rules it fails to remove
+// instead of returning an error, since it usually runs deferred.
+func BlockTelemetryDomains(domains []string) (unblock func(), err error) {
+	return blockOn(runtime.GOOS, domains)
+}
+
+// blockOn blocks domains with the firewall commands of goos
+func blockOn(goos string, domains []string) (func(), error) {
+	addrs := resolveDomains(domains)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("none of the %d telemetry domains resolved", len(domains))
+	}
+
+	var rules []rule
+	switch goos {
+	case "linux":
+		rules = iptablesRules(addrs)
+	case "darwin":
+		rules = pfRules(addrs)
+	case "windows":
+		rules = netshRules(addrs)
+	default:
+		return nil, fmt.Errorf("blocking network access is not supported on %s", goos)
+	}
+	return applyRules(rules)
+}
+
+// resolveDomains returns the addresses of domains, sorted and without duplicates
+func resolveDomains(domains []string) []net.IP {
+	seen := make(map[string]bool)
+	var addrs []net.IP
+	for _, domain := range domains {
+		ips, err := lookupIP(strings.TrimPrefix(domain, "."))
+		if err != nil {
+			currentLogger().Debug("Skipping %s, it did not resolve: %v", domain, err)
+			continue
+		}
+		for _, ip := range ips {
+			if !seen[ip.String()] {
+				seen[ip.String()] = true
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].String() < addrs[j].String() })
+	return addrs
+}
+
+// applyRules adds rules in order. When one fails, those already added are removed
+// again. The returned function removes them in reverse order.
+func applyRules(rules []rule) (func(), error) {
+	var undo []command
+	removeAll := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if output, err := runCommand(undo[i]); err != nil {
+				currentLogger().Error("Failed to remove firewall rule, run %q by hand: %v: %s", undo[i].String(), err, strings.TrimSpace(output))
+			}
+		}
+		undo = nil
+	}
+
+	for _, r := range rules {
+		output, err := runCommand(r.add)
+		if err != nil {
+			removeAll()
+			return nil, fmt.Errorf("failed to run %s: %w: %s", r.add.name, err, strings.TrimSpace(output))
+		}
+		currentLogger().Debug("Added firewall rule: %s", r.add.String())
+		if cmd, ok := r.remove(output); ok {
+			undo = append(undo, cmd)
+		}
+	}
+
+	var once sync.Once
+	return func() { once.Do(removeAll) }, nil
+}
+
+// iptablesRules drops outgoing packets to each address with iptables, or
+// ip6tables for IPv6 addresses
+func iptablesRules(addrs []net.IP) []rule {
+	rules := make([]rule, 0, len(addrs))
+	for _, ip := range addrs {
+		name := "iptables"
+		if ip.To4() == nil {
+			name = "ip6tables"
+		}
+		spec := []string{"OUTPUT", "-d", ip.String(), "-m", "comment", "--comment", RuleName, "-j", "DROP"}
+		rules = append(rules, rule{
+			add:    command{name: name, args: append([]string{"-A"}, spec...)},
+			remove: removeWith(command{name: name, args: append([]string{"-D"}, spec...)}),
+		})
+	}
+	return rules
+}
+
+// pfTokenPattern finds the reference token "pfctl -E" prints
+var pfTokenPattern = regexp.MustCompile(`Token\s*:\s*(\d+)`)
+
+// pfRules loads a block rule for the addresses into the pf anchor and enables pf.
+// pfctl -E counts a reference rather than enabling pf for good, so releasing its
+// token leaves pf as it was.
+func pfRules(addrs []net.IP) []rule {
+	hosts := make([]string, len(addrs))
+	for i, ip := range addrs {
+		hosts[i] = ip.String()
+	}
+	return []rule{
+		{
+			add: command{
+				name:  "pfctl",
+				args:  []string{"-a", pfAnchor, "-f", "-"},
+				stdin: fmt.Sprintf("block drop out quick to { %s }\n", strings.Join(hosts, ", ")),
+			},
+			remove: removeWith(command{name: "pfctl", args: []string{"-a", pfAnchor, "-F", "rules"}}),
+		},
+		{
+			add: command{name: "pfctl", args: []string{"-E"}},
+			remove: func(output string) (command, bool) {
+				match := pfTokenPattern.FindStringSubmatch(output)
+				if match == nil {
+					return command{}, false
+				}
+				return command{name: "pfctl", args: []string{"-X", match[1]}}, true
+			},
+		},
+	}
+}
+
+// netshRules adds one outbound Windows Defender Firewall rule blocking the
+// addresses
+func netshRules(addrs []net.IP) []rule {
+	hosts := make([]string, len(addrs))
+	for i, ip := range addrs {
+		hosts[i] = ip.String()
+	}
+	return []rule{{
+		add: command{name: "netsh", args: []string{
+			"advfirewall", "firewall", "add", "rule", "name=" + RuleName,
+			"dir=out", "action=block", "remoteip=" + strings.Join(hosts, ","),
+		}},
+		remove: removeWith(command{name: "netsh", args: []string{
+			"advfirewall", "firewall", "delete", "rule", "name=" + RuleName, "dir=out",
+		}}),
+	}}
+}
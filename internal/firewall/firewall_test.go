@@ -0,0 +1,134 @@
+package firewall
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// fakeFirewall replaces name resolution and command execution, recording the
+// commands run. Commands whose line is in fail return an error; outputs maps
+// command lines to their output.
+func fakeFirewall(t *testing.T, addrs map[string][]string, fail map[string]bool, outputs map[string]string) *[]string {
+	t.Helper()
+	origLookup, origRun := lookupIP, runCommand
+	t.Cleanup(func() { lookupIP, runCommand = origLookup, origRun })
+
+	lookupIP = func(host string) ([]net.IP, error) {
+		found, ok := addrs[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		var ips []net.IP
+		for _, addr := range found {
+			ips = append(ips, net.ParseIP(addr))
+		}
+		return ips, nil
+	}
+
+	var ran []string
+	runCommand = func(cmd command) (string, error) {
+		line := cmd.String()
+		ran = append(ran, line)
+		if fail[line] {
+			return "permission denied", errors.New("exit status 1")
+		}
+		return outputs[line], nil
+	}
+	return &ran
+}
+
+func TestBlockLinux(t *testing.T) {
+	ran := fakeFirewall(t, map[string][]string{
+		"sentry.io":      {"10.0.0.2", "2001:db8::1"},
+		"api.segment.io": {"10.0.0.1", "10.0.0.2"},
+	}, nil, nil)
+
+	unblock, err := blockOn("linux", []string{"sentry.io", "api.segment.io", "missing.example"})
+	if err != nil {
+		t.Fatalf("blockOn failed: %v", err)
+	}
+	unblock()
+	unblock() // A second call must not remove the rules twice
+
+	expected := []string{
+		"iptables -A OUTPUT -d 10.0.0.1 -m comment --comment augment-telemetry-cleaner -j DROP",
+		"iptables -A OUTPUT -d 10.0.0.2 -m comment --comment augment-telemetry-cleaner -j DROP",
+		"ip6tables -A OUTPUT -d 2001:db8::1 -m comment --comment augment-telemetry-cleaner -j DROP",
+		"ip6tables -D OUTPUT -d 2001:db8::1 -m comment --comment augment-telemetry-cleaner -j DROP",
+		"iptables -D OUTPUT -d 10.0.0.2 -m comment --comment augment-telemetry-cleaner -j DROP",
+		"iptables -D OUTPUT -d 10.0.0.1 -m comment --comment augment-telemetry-cleaner -j DROP",
+	}
+	if !reflect.DeepEqual(*ran, expected) {
+		t.Errorf("Expected commands %q, got %q", expected, *ran)
+	}
+}
+
+func TestBlockRemovesAddedRulesOnFailure(t *testing.T) {
+	ran := fakeFirewall(t, map[string][]string{"sentry.io": {"10.0.0.1", "10.0.0.2"}},
+		map[string]bool{"iptables -A OUTPUT -d 10.0.0.2 -m comment --comment augment-telemetry-cleaner -j DROP": true}, nil)
+
+	if _, err := blockOn("linux", []string{"sentry.io"}); err == nil {
+		t.Fatal("Expected an error when a rule cannot be added")
+	}
+	last := (*ran)[len(*ran)-1]
+	if last != "iptables -D OUTPUT -d 10.0.0.1 -m comment --comment augment-telemetry-cleaner -j DROP" {
+		t.Errorf("Expected the first rule to be removed again, got %q", *ran)
+	}
+}
+
+func TestBlockDarwinReleasesPfToken(t *testing.T) {
+	ran := fakeFirewall(t, map[string][]string{"sentry.io": {"10.0.0.1", "2001:db8::1"}}, nil,
+		map[string]string{"pfctl -E": "pf enabled\nToken : 1234567890\n"})
+
+	unblock, err := blockOn("darwin", []string{".sentry.io"})
+	if err != nil {
+		t.Fatalf("blockOn failed: %v", err)
+	}
+	unblock()
+
+	expected := []string{
+		"pfctl -a com.apple/augment-telemetry-cleaner -f -",
+		"pfctl -E",
+		"pfctl -X 1234567890",
+		"pfctl -a com.apple/augment-telemetry-cleaner -F rules",
+	}
+	if !reflect.DeepEqual(*ran, expected) {
+		t.Errorf("Expected commands %q, got %q", expected, *ran)
+	}
+	if rules := pfRules([]net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")}); rules[0].add.stdin != "block drop out quick to { 10.0.0.1, 2001:db8::1 }\n" {
+		t.Errorf("Unexpected pf rules %q", rules[0].add.stdin)
+	}
+}
+
+func TestBlockWindows(t *testing.T) {
+	ran := fakeFirewall(t, map[string][]string{"sentry.io": {"10.0.0.1"}, "api.segment.io": {"10.0.0.2"}}, nil, nil)
+
+	unblock, err := blockOn("windows", []string{"sentry.io", "api.segment.io"})
+	if err != nil {
+		t.Fatalf("blockOn failed: %v", err)
+	}
+	unblock()
+
+	expected := []string{
+		"netsh advfirewall firewall add rule name=augment-telemetry-cleaner dir=out action=block remoteip=10.0.0.1,10.0.0.2",
+		"netsh advfirewall firewall delete rule name=augment-telemetry-cleaner dir=out",
+	}
+	if !reflect.DeepEqual(*ran, expected) {
+		t.Errorf("Expected commands %q, got %q", expected, *ran)
+	}
+}
+
+func TestBlockFailsWithoutRules(t *testing.T) {
+	ran := fakeFirewall(t, map[string][]string{"sentry.io": {"10.0.0.1"}}, nil, nil)
+	if _, err := blockOn("linux", []string{"missing.example"}); err == nil {
+		t.Error("Expected an error when no domain resolves")
+	}
+	if _, err := blockOn("plan9", []string{"sentry.io"}); err == nil {
+		t.Error("Expected an error on an unsupported platform")
+	}
+	if len(*ran) != 0 {
+		t.Errorf("Expected no commands, got %q", *ran)
+	}
+}
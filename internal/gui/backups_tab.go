@@ -0,0 +1,120 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// backupColumns are the column headers of the Backups tab table
+var backupColumns = []string{"Created", "Extension", "Type", "Files", "Compression", "Verified", "Path"}
+
+// buildBackupsTab builds the Backups tab: a summary row above a table of all backups
+func (g *MainGUI) buildBackupsTab() fyne.CanvasObject {
+	g.backupsSummary = widget.NewLabel("")
+	g.backupsSummary.Wrapping = fyne.TextWrapWord
+
+	g.backupsTable = widget.NewTable(
+		func() (int, int) {
+			return len(g.backups) + 1, len(backupColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cell.(*widget.Label).SetText(g.backupCell(id.Row, id.Col))
+		},
+	)
+	for col, width := range []float32{150, 200, 110, 60, 100, 70, 300} {
+		g.backupsTable.SetColumnWidth(col, width)
+	}
+	g.refreshBackups()
+
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(
+				widget.NewLabel("Extension backups:"),
+				widget.NewButton("Refresh", g.refreshBackups),
+			),
+			g.backupsSummary,
+		),
+		nil,
+		nil,
+		nil,
+		g.backupsTable,
+	)
+}
+
+// backupCell returns the text of a Backups table cell; row 0 is the header
+func (g *MainGUI) backupCell(row, col int) string {
+	if row == 0 {
+		return backupColumns[col]
+	}
+
+	backup := g.backups[row-1]
+	switch col {
+	case 0:
+		return backup.CreationTime.Format("2006-01-02 15:04")
+	case 1:
+		return backup.ExtensionID
+	case 2:
+		return backup.BackupType
+	case 3:
+		return fmt.Sprintf("%d", backup.FileCount)
+	case 4:
+		return backup.CompressionType
+	case 5:
+		if backup.Verified {
+			return "Yes"
+		}
+		return "No"
+	default:
+		return backup.BackupPath
+	}
+}
+
+// refreshBackups reloads the Backups tab summary and table
+func (g *MainGUI) refreshBackups() {
+	if g.backupsSummary == nil {
+		return
+	}
+
+	ctx := context.Background()
+	stats, err := augmentcleaner.GetBackupStats(ctx, g.cleanerOptions())
+	if err != nil {
+		g.backupsSummary.SetText(fmt.Sprintf("Failed to read backups: %v", err))
+		return
+	}
+	g.backupsSummary.SetText(backupStatsSummary(stats))
+
+	backups, err := augmentcleaner.ListBackups(ctx, g.cleanerOptions())
+	if err != nil {
+		backups = nil
+	}
+	g.backups = backups
+	g.backupsTable.Refresh()
+}
+
+// backupStatsSummary formats backup statistics as the one-line summary row
+func backupStatsSummary(stats *augmentcleaner.BackupStats) string {
+	if stats.TotalBackups == 0 {
+		return "No backups yet."
+	}
+
+	return fmt.Sprintf("%d backups, %.2f MB total (%.2f MB average), %d verified / %d unverified, %s to %s, covering %s",
+		stats.TotalBackups,
+		float64(stats.TotalSizeBytes)/(1024*1024),
+		stats.AverageBackupSizeMB,
+		stats.VerifiedCount,
+		stats.UnverifiedCount,
+		stats.OldestBackup.Format("2006-01-02"),
+		stats.NewestBackup.Format("2006-01-02"),
+		strings.Join(stats.ExtensionsCovered, ", "),
+	)
+}
@@ -0,0 +1,95 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// historyTabLimit is the number of most recent operations shown in the History tab
+const historyTabLimit = 50
+
+// buildHistoryTab builds the History tab listing the most recent operations
+func (g *MainGUI) buildHistoryTab() fyne.CanvasObject {
+	g.historyText = widget.NewMultiLineEntry()
+	g.historyText.Wrapping = fyne.TextWrapWord
+	g.refreshHistory()
+
+	historyScroll := container.NewScroll(g.historyText)
+	historyScroll.SetMinSize(fyne.NewSize(800, 400))
+
+	return container.NewBorder(
+		container.NewHBox(
+			widget.NewLabel(fmt.Sprintf("Last %d operations:", historyTabLimit)),
+			widget.NewButton("Refresh", g.refreshHistory),
+		),
+		nil,
+		nil,
+		nil,
+		historyScroll,
+	)
+}
+
+// refreshHistory reloads the History tab and the last run shown under each
+// operation button from the history file
+func (g *MainGUI) refreshHistory() {
+	records, err := augmentcleaner.History(context.Background(), g.cleanerOptions(), 0)
+	g.refreshOperationStatuses(records)
+
+	if g.historyText == nil {
+		return
+	}
+	if err != nil {
+		g.historyText.SetText(fmt.Sprintf("Failed to read history: %v", err))
+		return
+	}
+	if len(records) == 0 {
+		g.historyText.SetText("No operations recorded yet.")
+		return
+	}
+	if len(records) > historyTabLimit {
+		records = records[:historyTabLimit]
+	}
+
+	var b strings.Builder
+	for _, record := range records {
+		writeHistoryRecord(&b, record)
+	}
+	g.historyText.SetText(b.String())
+}
+
+// writeHistoryRecord writes a history record as shown in the History tab
+func writeHistoryRecord(b *strings.Builder, record augmentcleaner.HistoryRecord) {
+	status := "OK"
+	if !record.Success {
+		status = "FAILED"
+	}
+	fmt.Fprintf(b, "%s  %-20s %s\n", record.Timestamp.Format("2006-01-02 15:04:05"), record.Operation, status)
+	if record.Summary != "" {
+		fmt.Fprintf(b, "    %s\n", record.Summary)
+	}
+	for _, backup := range record.BackupIDs {
+		fmt.Fprintf(b, "    Backup: %s\n", backup)
+	}
+	for _, msg := range record.Errors {
+		fmt.Fprintf(b, "    Error: %s\n", msg)
+	}
+}
+
+// lastRotationText describes how long ago telemetry IDs were last rotated
+func (g *MainGUI) lastRotationText() string {
+	record, err := augmentcleaner.LastSuccessfulRun(context.Background(), g.cleanerOptions(), "modify-telemetry")
+	if err != nil || record == nil {
+		return "No previous ID rotation recorded"
+	}
+
+	days := int(time.Since(record.Timestamp).Hours() / 24)
+	return fmt.Sprintf("IDs last rotated %d days ago (%s)", days, record.Timestamp.Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,184 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
+)
+
+// operationStatus shows under an operation button when the operation last ran,
+// with its full history record and a button running it again
+type operationStatus struct {
+	operation string // History name of the operation, e.g. "clean-database"
+	title     string
+	run       func()
+	record    *augmentcleaner.HistoryRecord
+
+	label   *widget.Label
+	details *widget.Button
+	rerun   *widget.Button
+}
+
+// newOperationStatus creates the last-run row of an operation; run performs the
+// operation without asking for confirmation
+func (g *MainGUI) newOperationStatus(operation, title string, run func()) *operationStatus {
+	status := &operationStatus{operation: operation, title: title, run: run}
+	status.label = widget.NewLabel("Never run")
+	status.label.Wrapping = fyne.TextTruncate
+	status.details = widget.NewButtonWithIcon("", theme.InfoIcon(), func() { g.showHistoryRecord(status) })
+	status.rerun = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() { g.onRunAgain(status) })
+	status.details.Disable()
+	status.rerun.Disable()
+	g.operationStatuses = append(g.operationStatuses, status)
+	return status
+}
+
+// withStatus places the last-run row of status under an operation button
+func withStatus(button *widget.Button, status *operationStatus) fyne.CanvasObject {
+	return container.NewVBox(
+		button,
+		container.NewBorder(nil, nil, nil, container.NewHBox(status.details, status.rerun), status.label),
+	)
+}
+
+// refreshOperationStatuses shows the newest record of each operation in records,
+// which are ordered newest first
+func (g *MainGUI) refreshOperationStatuses(records []augmentcleaner.HistoryRecord) {
+	for _, status := range g.operationStatuses {
+		status.record = nil
+		for i := range records {
+			if records[i].Operation == status.operation {
+				status.record = &records[i]
+				break
+			}
+		}
+		status.label.SetText(lastRunText(status.record))
+		if status.record == nil {
+			status.details.Disable()
+			status.rerun.Disable()
+			continue
+		}
+		status.details.Enable()
+		if !g.isRunning {
+			status.rerun.Enable()
+		}
+	}
+}
+
+// lastRunText describes a history record in one line: when it ran, whether it
+// succeeded and its summary, e.g. "✓ 2024-03-01 14:05: Deleted 2,144 records"
+func lastRunText(record *augmentcleaner.HistoryRecord) string {
+	if record == nil {
+		return "Never run"
+	}
+	mark := "✓"
+	if !record.Success {
+		mark = "✗"
+	}
+	text := fmt.Sprintf("%s %s", mark, record.Timestamp.Format("2006-01-02 15:04"))
+	if record.Summary != "" {
+		text += ": " + groupDigits(record.Summary)
+	}
+	return text
+}
+
+// groupDigits separates the thousands of the whole numbers in a summary with
+// commas, e.g. "Deleted 2144 records" becomes "Deleted 2,144 records". Digits
+// that are part of a decimal or a name such as backup-1700000000 are kept.
+func groupDigits(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		number := s[i:j]
+		partOfWord := (i > 0 && strings.IndexByte(".-_", s[i-1]) >= 0) || (j < len(s) && strings.IndexByte(".-_", s[j]) >= 0)
+		for k := range number {
+			if !partOfWord && k > 0 && (len(number)-k)%3 == 0 {
+				b.WriteByte(',')
+			}
+			b.WriteByte(number[k])
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// showHistoryRecord shows the full history record of an operation's last run
+func (g *MainGUI) showHistoryRecord(status *operationStatus) {
+	if status.record == nil {
+		return
+	}
+
+	var b strings.Builder
+	writeHistoryRecord(&b, *status.record)
+	if len(status.record.Options) > 0 {
+		b.WriteString("\nOptions:\n")
+		for _, key := range sortedKeys(status.record.Options) {
+			fmt.Fprintf(&b, "    %s: %v\n", key, status.record.Options[key])
+		}
+	}
+
+	text := widget.NewLabel(b.String())
+	text.Wrapping = fyne.TextWrapWord
+	scroll := container.NewScroll(text)
+	scroll.SetMinSize(fyne.NewSize(600, 250))
+	dialog.ShowCustom("Last "+status.title, "Close", scroll, g.window)
+}
+
+// onRunAgain runs an operation again with the backup setting of its last run.
+// History only records real runs, so dry run mode is turned off as well, which
+// is why this always asks first.
+func (g *MainGUI) onRunAgain(status *operationStatus) {
+	if g.isRunning || status.record == nil {
+		return
+	}
+
+	createBackups := g.configManager.GetConfig().CreateBackups
+	if recorded, ok := status.record.Options["create_backups"].(bool); ok {
+		createBackups = recorded
+	}
+	backups := "without backups"
+	if createBackups {
+		backups = "with backups"
+	}
+
+	message := fmt.Sprintf("Run %s again %s, as on %s?", status.title, backups, status.record.Timestamp.Format("2006-01-02 15:04"))
+	if g.dryRunCheck.Checked {
+		message += "\n\nDry run mode will be turned off."
+	}
+	dialog.ShowConfirm("Run Again", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		// The checks' handlers save the settings
+		g.dryRunCheck.SetChecked(false)
+		g.backupCheck.SetChecked(createBackups)
+		g.logger.Info("Running %s again with the options of %s", status.operation, status.record.Timestamp.Format("2006-01-02 15:04:05"))
+		go status.run()
+	}, g.window)
+}
+
+// sortedKeys returns the keys of a history record's options in order
+func sortedKeys(options map[string]interface{}) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
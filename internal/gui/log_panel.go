@@ -0,0 +1,184 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"augment-telemetry-cleaner/internal/logger"
+)
+
+// logPanelLimit is the number of most recent entries the Log tab keeps
+const logPanelLimit = 1000
+
+// LogEntry is a single line of the Log tab
+type LogEntry struct {
+	Time    time.Time
+	Level   logger.LogLevel
+	Message string
+	Color   color.Color // nil uses the theme's foreground color
+}
+
+// String formats the entry as it is shown, copied and saved
+func (e LogEntry) String() string {
+	return fmt.Sprintf("%s [%s] %s", e.Time.Format("15:04:05"), e.Level.String(), e.Message)
+}
+
+// LogPanel is a scrollable list of everything the logger reports, updated as
+// operations run
+type LogPanel struct {
+	window fyne.Window
+
+	mu      sync.Mutex
+	entries []LogEntry
+
+	incoming   chan LogEntry
+	list       *widget.List
+	autoScroll *widget.Check
+}
+
+// NewLogPanel creates a new log panel and starts delivering entries to it
+func NewLogPanel(window fyne.Window) *LogPanel {
+	p := &LogPanel{
+		window:   window,
+		incoming: make(chan LogEntry, 256),
+	}
+
+	p.list = widget.NewList(
+		p.length,
+		func() fyne.CanvasObject {
+			text := canvas.NewText("", theme.ForegroundColor())
+			text.TextStyle = fyne.TextStyle{Monospace: true}
+			return text
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			p.updateItem(id, item.(*canvas.Text))
+		},
+	)
+	p.autoScroll = widget.NewCheck("Auto-scroll", nil)
+	p.autoScroll.SetChecked(true)
+
+	go p.deliver()
+	return p
+}
+
+// Add queues a log message for display. It is safe to call from any goroutine.
+func (p *LogPanel) Add(level logger.LogLevel, message string) {
+	entry := LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: message,
+	}
+	switch level {
+	case logger.ERROR:
+		entry.Color = theme.ErrorColor()
+	case logger.WARN:
+		entry.Color = theme.WarningColor()
+	}
+	p.incoming <- entry
+}
+
+// deliver appends queued entries to the list, dropping the oldest past logPanelLimit
+func (p *LogPanel) deliver() {
+	for entry := range p.incoming {
+		p.mu.Lock()
+		p.entries = append(p.entries, entry)
+		if len(p.entries) > logPanelLimit {
+			p.entries = append(p.entries[:0], p.entries[len(p.entries)-logPanelLimit:]...)
+		}
+		p.mu.Unlock()
+
+		p.list.Refresh()
+		if p.autoScroll.Checked {
+			p.list.ScrollToBottom()
+		}
+	}
+}
+
+// length returns the number of entries for the list
+func (p *LogPanel) length() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// updateItem shows entry id in a list row
+func (p *LogPanel) updateItem(id widget.ListItemID, text *canvas.Text) {
+	p.mu.Lock()
+	if id >= len(p.entries) {
+		p.mu.Unlock()
+		return
+	}
+	entry := p.entries[id]
+	p.mu.Unlock()
+
+	text.Text = entry.String()
+	text.Color = entry.Color
+	if text.Color == nil {
+		text.Color = theme.ForegroundColor()
+	}
+	text.Refresh()
+}
+
+// Text returns all entries, one per line
+func (p *LogPanel) Text() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	for _, entry := range p.entries {
+		b.WriteString(entry.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// CanvasObject builds the Log tab: the buttons above the entry list
+func (p *LogPanel) CanvasObject() fyne.CanvasObject {
+	return container.NewBorder(
+		container.NewHBox(
+			widget.NewLabel(fmt.Sprintf("Last %d log entries:", logPanelLimit)),
+			p.autoScroll,
+			widget.NewButton("Copy to Clipboard", p.copyToClipboard),
+			widget.NewButton("Save to File", p.saveToFile),
+		),
+		nil,
+		nil,
+		nil,
+		p.list,
+	)
+}
+
+// copyToClipboard puts the whole log on the clipboard
+func (p *LogPanel) copyToClipboard() {
+	p.window.Clipboard().SetContent(p.Text())
+}
+
+// saveToFile asks for a file and writes the whole log to it
+func (p *LogPanel) saveToFile() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to choose log file: %w", err), p.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if _, err := writer.Write([]byte(p.Text())); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save log: %w", err), p.window)
+		}
+	}, p.window)
+	saveDialog.SetFileName(fmt.Sprintf("augment_cleaner_log_%s.txt", time.Now().Format("2006-01-02_15-04-05")))
+	saveDialog.Show()
+}
@@ -12,6 +12,8 @@ import (
 
 	"augment-telemetry-cleaner/internal/config"
 	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/retry"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
 )
 
 // MainGUI represents the main GUI application
@@ -26,6 +28,7 @@ type MainGUI struct {
 	statusLabel    *widget.Label
 	progressBar    *widget.ProgressBar
 	logText        *widget.Entry
+	logPanel       *LogPanel
 
 	// Operation buttons
 	modifyTelemetryBtn  *widget.Button
@@ -33,6 +36,16 @@ type MainGUI struct {
 	cleanWorkspaceBtn   *widget.Button
 	cleanBrowserBtn     *widget.Button
 	runAllBtn          *widget.Button
+	quickCleanBtn      *widget.Button
+
+	// Last run of each operation, shown under its button
+	modifyTelemetryStatus *operationStatus
+	cleanDatabaseStatus   *operationStatus
+	cleanWorkspaceStatus  *operationStatus
+	cleanBrowserStatus    *operationStatus
+	runAllStatus          *operationStatus
+	quickCleanStatus      *operationStatus
+	operationStatuses     []*operationStatus
 
 	// Mode selection
 	dryRunCheck        *widget.Check
@@ -41,6 +54,10 @@ type MainGUI struct {
 
 	// Results display
 	resultsText        *widget.Entry
+	historyText        *widget.Entry
+	backupsSummary     *widget.Label
+	backupsTable       *widget.Table
+	backups            []augmentcleaner.Backup
 
 	// Operation state
 	isRunning          bool
@@ -56,6 +73,7 @@ func NewMainGUI(window fyne.Window) *MainGUI {
 		dialog.ShowError(fmt.Errorf("failed to initialize configuration: %w", err), window)
 		return nil
 	}
+	retry.SetDefault(configManager.GetConfig().RetryPolicy())
 
 	// Initialize logger
 	logDir := "logs"
@@ -103,6 +121,14 @@ func (g *MainGUI) initializeComponents() {
 	g.cleanWorkspaceBtn = widget.NewButton("Clean Workspace", g.onCleanWorkspace)
 	g.cleanBrowserBtn = widget.NewButton("Clean Browser Data", g.onCleanBrowser)
 	g.runAllBtn = widget.NewButton("Run All Operations", g.onRunAll)
+	g.quickCleanBtn = widget.NewButton("Quick Clean", g.onQuickClean)
+
+	g.modifyTelemetryStatus = g.newOperationStatus("modify-telemetry", "Modify Telemetry IDs", g.runModifyTelemetry)
+	g.cleanDatabaseStatus = g.newOperationStatus("clean-database", "Clean Database", g.runCleanDatabase)
+	g.cleanWorkspaceStatus = g.newOperationStatus("clean-workspace", "Clean Workspace", g.runCleanWorkspace)
+	g.cleanBrowserStatus = g.newOperationStatus("clean-browser", "Clean Browser Data", g.runCleanBrowser)
+	g.runAllStatus = g.newOperationStatus("run-all", "Run All Operations", g.runAllOperations)
+	g.quickCleanStatus = g.newOperationStatus("quick-clean", "Quick Clean", g.runQuickClean)
 
 	// Make the main action button more prominent
 	g.runAllBtn.Importance = widget.HighImportance
@@ -123,6 +149,9 @@ func (g *MainGUI) initializeComponents() {
 	g.resultsText.Wrapping = fyne.TextWrapWord
 	g.resultsText.MultiLine = true
 
+	// Log tab, fed by the logger callback below
+	g.logPanel = NewLogPanel(g.window)
+
 	// Update logger with GUI callback
 	logDir := "logs"
 	var err error
@@ -147,16 +176,19 @@ func (g *MainGUI) BuildUI() fyne.CanvasObject {
 
 	// Operation buttons in a compact grid
 	buttonsGrid := container.NewGridWithColumns(2,
-		g.modifyTelemetryBtn,
-		g.cleanDatabaseBtn,
-		g.cleanWorkspaceBtn,
-		g.cleanBrowserBtn,
+		withStatus(g.modifyTelemetryBtn, g.modifyTelemetryStatus),
+		withStatus(g.cleanDatabaseBtn, g.cleanDatabaseStatus),
+		withStatus(g.cleanWorkspaceBtn, g.cleanWorkspaceStatus),
+		withStatus(g.cleanBrowserBtn, g.cleanBrowserStatus),
 	)
 
 	// Main action button
 	mainActionContainer := container.NewVBox(
 		buttonsGrid,
-		g.runAllBtn,
+		container.NewGridWithColumns(2,
+			withStatus(g.quickCleanBtn, g.quickCleanStatus),
+			withStatus(g.runAllBtn, g.runAllStatus),
+		),
 	)
 
 	// Log and results areas with optimized heights
@@ -184,12 +216,19 @@ func (g *MainGUI) BuildUI() fyne.CanvasObject {
 		widget.NewButton("Exit", g.onExit),
 	)
 
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Clean", mainContent),
+		container.NewTabItem("History", g.buildHistoryTab()),
+		container.NewTabItem("Backups", g.buildBackupsTab()),
+		container.NewTabItem("Log", g.logPanel.CanvasObject()),
+	)
+
 	return container.NewBorder(
 		nil,
 		footer,
 		nil,
 		nil,
-		mainContent,
+		tabs,
 	)
 }
 
@@ -202,7 +241,10 @@ func (g *MainGUI) onModifyTelemetry() {
 	}
 
 	config := g.configManager.GetConfig()
-	if config.RequireConfirmation && !g.showConfirmationDialog("Modify Telemetry IDs", "This will modify VS Code's telemetry IDs. Continue?") {
+	if !g.checkCloudSync("Modify Telemetry IDs", config.BackupDirectory) {
+		return
+	}
+	if config.RequireConfirmation && !g.showConfirmationDialog("Modify Telemetry IDs", fmt.Sprintf("This will modify VS Code's telemetry IDs.\n%s.\nContinue?", g.lastRotationText())) {
 		return
 	}
 
@@ -215,6 +257,9 @@ func (g *MainGUI) onCleanDatabase() {
 	}
 
 	config := g.configManager.GetConfig()
+	if !g.checkCloudSync("Clean Database", config.BackupDirectory) {
+		return
+	}
 	if config.RequireConfirmation && !g.showConfirmationDialog("Clean Database", "This will remove Augment-related data from VS Code's database. Continue?") {
 		return
 	}
@@ -228,6 +273,9 @@ func (g *MainGUI) onCleanWorkspace() {
 	}
 
 	config := g.configManager.GetConfig()
+	if !g.checkCloudSync("Clean Workspace", config.BackupDirectory) {
+		return
+	}
 	if config.RequireConfirmation && !g.showConfirmationDialog("Clean Workspace", "This will clean VS Code's workspace storage. Continue?") {
 		return
 	}
@@ -241,6 +289,9 @@ func (g *MainGUI) onCleanBrowser() {
 	}
 
 	config := g.configManager.GetConfig()
+	if !g.checkCloudSync("Clean Browser Data", config.BrowserBackupDir) {
+		return
+	}
 	if config.RequireConfirmation && !g.showConfirmationDialog("Clean Browser Data",
 		"This will remove Augment-related data from your browsers (cookies, storage, cache).\n\n"+
 		"⚠️ WARNING: Please close all browsers before proceeding.\n\n"+
@@ -257,6 +308,9 @@ func (g *MainGUI) onRunAll() {
 	}
 
 	config := g.configManager.GetConfig()
+	if !g.checkCloudSync("Run All Operations", config.BackupDirectory, config.BrowserBackupDir) {
+		return
+	}
 	if config.RequireConfirmation && !g.showConfirmationDialog("Run All Operations", "This will run all cleaning operations. Continue?") {
 		return
 	}
@@ -264,6 +318,25 @@ func (g *MainGUI) onRunAll() {
 	go g.runAllOperations()
 }
 
+func (g *MainGUI) onQuickClean() {
+	if g.isRunning {
+		return
+	}
+
+	config := g.configManager.GetConfig()
+	if !g.checkCloudSync("Quick Clean", config.BackupDirectory) {
+		return
+	}
+	if config.RequireConfirmation && !g.showConfirmationDialog("Quick Clean",
+		"This will clean the VS Code database and modify telemetry IDs, skipping workspace and browser cleaning.\n\n"+
+		"Backups are always created.\n\n"+
+		"Continue?") {
+		return
+	}
+
+	go g.runQuickClean()
+}
+
 
 
 func (g *MainGUI) onExit() {
@@ -298,6 +371,7 @@ func (g *MainGUI) onLogMessage(level logger.LogLevel, message string) {
 	timestamp := time.Now().Format("15:04:05")
 	logEntry := fmt.Sprintf("[%s] %s: %s", timestamp, level.String(), message)
 	g.appendLog(logEntry)
+	g.logPanel.Add(level, message)
 }
 
 // Helper methods
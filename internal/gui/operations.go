@@ -1,14 +1,18 @@
 package gui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2/dialog"
 
-	"augment-telemetry-cleaner/internal/browser"
-	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/instancelock"
 	"augment-telemetry-cleaner/internal/utils"
+	"augment-telemetry-cleaner/pkg/augmentcleaner"
 )
 
 // runModifyTelemetry executes the telemetry modification operation
@@ -21,14 +25,20 @@ func (g *MainGUI) runModifyTelemetry() {
 
 	if config.DryRunMode {
 		g.logger.Info("DRY RUN MODE: Would modify telemetry IDs")
-		g.setResults("DRY RUN: Telemetry IDs would be modified (no actual changes made)")
+		g.setResults(fmt.Sprintf("DRY RUN: Telemetry IDs would be modified (no actual changes made)\n%s", g.lastRotationText()))
 		return
 	}
 
-	result, err := cleaner.ModifyTelemetryIDs()
+	release, ok := g.acquireInstanceLock("Modify Telemetry IDs")
+	if !ok {
+		return
+	}
+	defer release()
+
+	result, err := augmentcleaner.ModifyTelemetryIDs(context.Background(), g.cleanerOptions())
 	if err != nil {
 		g.logger.LogOperationResult("Modify Telemetry IDs", false, err.Error())
-		g.showErrorDialog("Telemetry Modification Failed", err.Error())
+		g.showOperationError("Telemetry Modification Failed", err)
 		return
 	}
 
@@ -52,30 +62,39 @@ func (g *MainGUI) runCleanDatabase() {
 	g.logger.LogOperation("Clean Database")
 
 	if config.DryRunMode {
-		count, err := cleaner.GetAugmentDataCount()
+		estimate, err := augmentcleaner.EstimateDatabaseClean(context.Background(), g.cleanerOptions())
 		if err != nil {
 			g.logger.Error("Failed to count database records: %v", err)
 			g.showErrorDialog("Database Count Failed", err.Error())
 			return
 		}
-		g.logger.Info("DRY RUN MODE: Would delete %d database records", count)
-		g.setResults(fmt.Sprintf("DRY RUN: Would delete %d database records (no actual changes made)", count))
+		g.logger.Info("DRY RUN MODE: Would delete %d database records (%d bytes)", estimate.Records, estimate.Bytes)
+		g.setResults(fmt.Sprintf("DRY RUN: Would delete %d database records, freeing %s (no actual changes made)",
+			estimate.Records, utils.FormatBytes(estimate.Bytes)))
 		return
 	}
 
-	result, err := cleaner.CleanAugmentData()
+	release, ok := g.acquireInstanceLock("Clean Database")
+	if !ok {
+		return
+	}
+	defer release()
+
+	result, err := augmentcleaner.CleanDatabase(context.Background(), g.cleanerOptions())
 	if err != nil {
 		g.logger.LogOperationResult("Clean Database", false, err.Error())
-		g.showErrorDialog("Database Cleaning Failed", err.Error())
+		g.showOperationError("Database Cleaning Failed", err)
 		return
 	}
 
 	g.logger.LogOperationResult("Clean Database", true, fmt.Sprintf("Deleted %d records", result.DeletedRows))
-	g.logger.LogBackupCreated("database", result.DBBackupPath)
+	for _, backupPath := range result.DBBackupPaths {
+		g.logger.LogBackupCreated("database", backupPath)
+	}
 
 	// Display results
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	g.setResults(fmt.Sprintf("Database Cleaned Successfully:\n%s", string(resultJSON)))
+	g.setResults(fmt.Sprintf("Database Cleaned Successfully, freed %s:\n%s", utils.FormatBytes(result.BytesFreed), string(resultJSON)))
 }
 
 // runCleanWorkspace executes the workspace cleaning operation
@@ -87,26 +106,39 @@ func (g *MainGUI) runCleanWorkspace() {
 	g.logger.LogOperation("Clean Workspace")
 
 	if config.DryRunMode {
-		workspacePath, err := utils.GetWorkspaceStoragePath()
+		estimate, err := augmentcleaner.EstimateWorkspaceClean(context.Background(), g.cleanerOptions())
 		if err != nil {
-			g.logger.Error("Failed to get workspace path: %v", err)
-			g.showErrorDialog("Workspace Path Error", err.Error())
+			g.logger.Error("Failed to estimate workspace cleaning: %v", err)
+			g.showErrorDialog("Workspace Estimate Failed", err.Error())
 			return
 		}
-		g.logger.Info("DRY RUN MODE: Would clean workspace at %s", workspacePath)
-		g.setResults(fmt.Sprintf("DRY RUN: Would clean workspace storage at %s (no actual changes made)", workspacePath))
+		g.logger.Info("DRY RUN MODE: Would delete %d workspace storage files (%d bytes)", estimate.Files, estimate.Bytes)
+		message := fmt.Sprintf("DRY RUN: Would delete %d workspace storage files, freeing %s (no actual changes made)",
+			estimate.Files, utils.FormatBytes(estimate.Bytes))
+		if len(estimate.SkippedOpenWorkspaces) > 0 {
+			message += fmt.Sprintf("\n%d workspaces open in VS Code would be skipped", len(estimate.SkippedOpenWorkspaces))
+		}
+		g.setResults(message)
+		return
+	}
+
+	release, ok := g.acquireInstanceLock("Clean Workspace")
+	if !ok {
 		return
 	}
+	defer release()
 
-	result, err := cleaner.CleanWorkspaceStorage()
+	result, err := augmentcleaner.CleanWorkspace(context.Background(), g.cleanerOptions())
 	if err != nil {
 		g.logger.LogOperationResult("Clean Workspace", false, err.Error())
-		g.showErrorDialog("Workspace Cleaning Failed", err.Error())
+		g.showOperationError("Workspace Cleaning Failed", err)
 		return
 	}
 
 	g.logger.LogOperationResult("Clean Workspace", true, fmt.Sprintf("Deleted %d files", result.DeletedFilesCount))
-	g.logger.LogBackupCreated("workspace", result.BackupPath)
+	for _, backupPath := range result.BackupPaths {
+		g.logger.LogBackupCreated("workspace", backupPath)
+	}
 
 	// Log any failed operations
 	for _, failed := range result.FailedOperations {
@@ -115,7 +147,11 @@ func (g *MainGUI) runCleanWorkspace() {
 
 	// Display results
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	g.setResults(fmt.Sprintf("Workspace Cleaned Successfully:\n%s", string(resultJSON)))
+	g.setResults(fmt.Sprintf("Workspace Cleaned Successfully, freed %s:\n%s", utils.FormatBytes(result.BytesFreed), string(resultJSON)))
+
+	if len(result.PermissionDenied) > 0 {
+		g.showPermissionDeniedDialog(result.PermissionDenied)
+	}
 }
 
 // runCleanBrowser executes the browser data cleaning operation
@@ -127,14 +163,7 @@ func (g *MainGUI) runCleanBrowser() {
 	g.logger.LogOperation("Clean Browser Data")
 
 	if config.DryRunMode {
-		browserCleaner, err := browser.NewBrowserCleaner()
-		if err != nil {
-			g.logger.Error("Failed to create browser cleaner: %v", err)
-			g.showErrorDialog("Browser Cleaner Failed", err.Error())
-			return
-		}
-
-		counts, err := browserCleaner.GetBrowserDataCount()
+		counts, err := augmentcleaner.CountBrowserData(context.Background(), g.cleanerOptions())
 		if err != nil {
 			g.logger.Error("Failed to count browser data: %v", err)
 			g.showErrorDialog("Browser Count Failed", err.Error())
@@ -142,14 +171,17 @@ func (g *MainGUI) runCleanBrowser() {
 		}
 
 		totalCount := int64(0)
+		totalBytes := int64(0)
 		for _, count := range counts {
-			totalCount += count
+			totalCount += count.Total
+			totalBytes += count.Bytes
 		}
 
-		g.logger.Info("DRY RUN MODE: Would clean %d browser data items", totalCount)
+		g.logger.Info("DRY RUN MODE: Would clean %d browser data items (%d bytes)", totalCount, totalBytes)
 
 		countsJSON, _ := json.MarshalIndent(counts, "", "  ")
-		g.setResults(fmt.Sprintf("DRY RUN: Would clean browser data:\n%s\n\nTotal items: %d", string(countsJSON), totalCount))
+		g.setResults(fmt.Sprintf("DRY RUN: Would clean browser data:\n%s\n\nTotal items: %d\nSpace to free: %s",
+			string(countsJSON), totalCount, utils.FormatBytes(totalBytes)))
 		return
 	}
 
@@ -169,17 +201,16 @@ func (g *MainGUI) runCleanBrowser() {
 		}
 	}
 
-	browserCleaner, err := browser.NewBrowserCleaner()
-	if err != nil {
-		g.logger.LogOperationResult("Clean Browser Data", false, err.Error())
-		g.showErrorDialog("Browser Cleaner Failed", err.Error())
+	release, ok := g.acquireInstanceLock("Clean Browser Data")
+	if !ok {
 		return
 	}
+	defer release()
 
-	results, err := browserCleaner.CleanBrowserData(config.CreateBackups)
+	results, err := augmentcleaner.CleanBrowsers(context.Background(), g.cleanerOptions())
 	if err != nil {
 		g.logger.LogOperationResult("Clean Browser Data", false, err.Error())
-		g.showErrorDialog("Browser Cleaning Failed", err.Error())
+		g.showOperationError("Browser Cleaning Failed", err)
 		return
 	}
 
@@ -187,12 +218,14 @@ func (g *MainGUI) runCleanBrowser() {
 	totalCookies := int64(0)
 	totalStorage := int64(0)
 	totalCache := int64(0)
-	var allErrors []string
+	totalBytes := int64(0)
+	var allErrors, permissionDenied []string
 
 	for _, result := range results {
 		totalCookies += result.CookiesDeleted
 		totalStorage += result.StorageDeleted
 		totalCache += result.CacheDeleted
+		totalBytes += result.BytesFreed
 
 		if result.BackupPath != "" {
 			g.logger.LogBackupCreated("browser-"+result.Profile.Name, result.BackupPath)
@@ -201,6 +234,7 @@ func (g *MainGUI) runCleanBrowser() {
 		for _, err := range result.Errors {
 			allErrors = append(allErrors, fmt.Sprintf("%s: %s", result.Profile.Name, err))
 		}
+		permissionDenied = append(permissionDenied, result.PermissionDenied...)
 	}
 
 	// Log results
@@ -214,7 +248,11 @@ func (g *MainGUI) runCleanBrowser() {
 
 	// Display results
 	resultJSON, _ := json.MarshalIndent(results, "", "  ")
-	g.setResults(fmt.Sprintf("Browser Data Cleaned:\n%s", string(resultJSON)))
+	g.setResults(fmt.Sprintf("Browser Data Cleaned, freed %s:\n%s", utils.FormatBytes(totalBytes), string(resultJSON)))
+
+	if len(permissionDenied) > 0 {
+		g.showPermissionDeniedDialog(permissionDenied)
+	}
 }
 
 // runAllOperations executes all cleaning operations in sequence
@@ -222,111 +260,201 @@ func (g *MainGUI) runAllOperations() {
 	g.setOperationState(true, "Running all operations...")
 	defer g.setOperationState(false, "Ready")
 
+	config := g.configManager.GetConfig()
 	g.logger.LogOperation("Run All Operations")
 
-	// Step 1: Modify Telemetry IDs
-	g.setStatus("Step 1/4: Modifying telemetry IDs...")
-	g.setProgress(0.1)
-	g.runModifyTelemetryInternal()
-	g.setProgress(0.25)
-
-	// Step 2: Clean Database
-	g.setStatus("Step 2/4: Cleaning database...")
-	g.runCleanDatabaseInternal()
-	g.setProgress(0.5)
-
-	// Step 3: Clean Workspace
-	g.setStatus("Step 3/4: Cleaning workspace...")
-	g.runCleanWorkspaceInternal()
-	g.setProgress(0.75)
-
-	// Step 4: Clean Browser Data
-	g.setStatus("Step 4/4: Cleaning browser data...")
-	g.runCleanBrowserInternal()
-	g.setProgress(1.0)
-
-	g.logger.LogOperationResult("Run All Operations", true, "All operations completed")
-	g.setResults("All operations completed successfully! You can now restart VS Code and login with a new account.")
-}
-
-// Internal operation methods (without UI state management)
-func (g *MainGUI) runModifyTelemetryInternal() {
-	config := g.configManager.GetConfig()
 	if config.DryRunMode {
-		g.logger.Info("DRY RUN: Skipping telemetry modification")
+		estimate, err := augmentcleaner.EstimateRunAll(context.Background(), g.cleanerOptions())
+		if err != nil {
+			g.logger.Error("Failed to estimate operations: %v", err)
+			g.showErrorDialog("Estimate Failed", err.Error())
+			return
+		}
+		g.logger.Info("DRY RUN MODE: Would run all operations (%d bytes)", estimate.TotalBytes)
+		estimateJSON, _ := json.MarshalIndent(estimate, "", "  ")
+		g.setResults(fmt.Sprintf("DRY RUN: Would modify telemetry IDs and clean the database, workspace storage and browser data, freeing %s (no actual changes made)\n\n%s",
+			utils.FormatBytes(estimate.TotalBytes), string(estimateJSON)))
 		return
 	}
 
-	result, err := cleaner.ModifyTelemetryIDs()
-	if err != nil {
-		g.logger.Error("Telemetry modification failed: %v", err)
+	release, ok := g.acquireInstanceLock("Run All Operations")
+	if !ok {
 		return
 	}
-	g.logger.Info("Telemetry IDs modified successfully")
-	g.logger.LogBackupCreated("storage.json", result.StorageBackupPath)
-}
+	defer release()
+
+	// Advance the status and progress bar as each step reports its first progress
+	steps := []string{augmentcleaner.StepModifyTelemetry, augmentcleaner.StepCleanDatabase,
+		augmentcleaner.StepCleanWorkspace, augmentcleaner.StepCleanBrowser}
+	stepNames := map[string]string{
+		augmentcleaner.StepModifyTelemetry: "Modifying telemetry IDs",
+		augmentcleaner.StepCleanDatabase:   "Cleaning database",
+		augmentcleaner.StepCleanWorkspace:  "Cleaning workspace",
+		augmentcleaner.StepCleanBrowser:    "Cleaning browser data",
+	}
+	opts := g.cleanerOptions()
+	logProgress := opts.Progress
+	currentStep := ""
+	opts.Progress = func(p augmentcleaner.Progress) {
+		logProgress(p)
+		for i, step := range steps {
+			if p.Operation == step && step != currentStep {
+				currentStep = step
+				g.setStatus(fmt.Sprintf("Step %d/%d: %s...", i+1, len(steps), stepNames[step]))
+				g.setProgress(float64(i) / float64(len(steps)))
+			}
+		}
+	}
 
-func (g *MainGUI) runCleanDatabaseInternal() {
-	config := g.configManager.GetConfig()
-	if config.DryRunMode {
-		g.logger.Info("DRY RUN: Skipping database cleaning")
+	result, err := augmentcleaner.RunAll(context.Background(), opts)
+	g.setProgress(1.0)
+	if result == nil {
+		g.logger.LogOperationResult("Run All Operations", false, err.Error())
+		g.showOperationError("Run All Operations Failed", err)
 		return
 	}
 
-	result, err := cleaner.CleanAugmentData()
-	if err != nil {
-		g.logger.Error("Database cleaning failed: %v", err)
-		return
+	var permissionDenied []string
+	if result.Telemetry != nil {
+		g.logger.LogBackupCreated("storage.json", result.Telemetry.StorageBackupPath)
+	}
+	if result.Database != nil {
+		for _, backupPath := range result.Database.DBBackupPaths {
+			g.logger.LogBackupCreated("database", backupPath)
+		}
+	}
+	if result.Workspace != nil {
+		for _, backupPath := range result.Workspace.BackupPaths {
+			g.logger.LogBackupCreated("workspace", backupPath)
+		}
+		permissionDenied = append(permissionDenied, result.Workspace.PermissionDenied...)
+	}
+	for _, profile := range result.Browsers {
+		if profile.BackupPath != "" {
+			g.logger.LogBackupCreated("browser-"+profile.Profile.Name, profile.BackupPath)
+		}
+		permissionDenied = append(permissionDenied, profile.PermissionDenied...)
 	}
-	g.logger.Info("Database cleaned successfully, deleted %d records", result.DeletedRows)
-	g.logger.LogBackupCreated("database", result.DBBackupPath)
-}
 
-func (g *MainGUI) runCleanWorkspaceInternal() {
-	config := g.configManager.GetConfig()
-	if config.DryRunMode {
-		g.logger.Info("DRY RUN: Skipping workspace cleaning")
-		return
+	failed := result.Failed()
+	var failures []string
+	for _, step := range failed {
+		g.logger.Error("%s failed: %s", step.Step, step.Error)
+		failures = append(failures, fmt.Sprintf("• %s: %s", step.Step, step.Error))
+		if augmentcleaner.IsPermissionError(step.Err) {
+			permissionDenied = append(permissionDenied, augmentcleaner.PermissionDeniedPath(step.Err))
+		}
 	}
 
-	result, err := cleaner.CleanWorkspaceStorage()
-	if err != nil {
-		g.logger.Error("Workspace cleaning failed: %v", err)
-		return
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	if len(failed) > 0 {
+		summary := fmt.Sprintf("%d of %d steps failed", len(failed), len(result.Steps))
+		g.logger.LogOperationResult("Run All Operations", false, summary)
+		g.setResults(fmt.Sprintf("Run All Operations finished, %s, freed %s:\n%s\n\n%s",
+			summary, utils.FormatBytes(result.BytesFreed()), strings.Join(failures, "\n"), string(resultJSON)))
+	} else {
+		g.logger.LogOperationResult("Run All Operations", true, "All operations completed")
+		g.setResults(fmt.Sprintf("All operations completed successfully, freeing %s! You can now restart VS Code and login with a new account.\n\n%s",
+			utils.FormatBytes(result.BytesFreed()), string(resultJSON)))
+	}
+
+	if len(permissionDenied) > 0 {
+		g.showPermissionDeniedDialog(permissionDenied)
 	}
-	g.logger.Info("Workspace cleaned successfully, deleted %d files", result.DeletedFilesCount)
-	g.logger.LogBackupCreated("workspace", result.BackupPath)
 }
 
-func (g *MainGUI) runCleanBrowserInternal() {
+// runQuickClean cleans the database and modifies the telemetry IDs only
+func (g *MainGUI) runQuickClean() {
+	g.setOperationState(true, "Running quick clean...")
+	defer g.setOperationState(false, "Ready")
+
 	config := g.configManager.GetConfig()
+	g.logger.LogOperation("Quick Clean")
+
 	if config.DryRunMode {
-		g.logger.Info("DRY RUN: Skipping browser cleaning")
+		estimate, err := augmentcleaner.EstimateDatabaseClean(context.Background(), g.cleanerOptions())
+		if err != nil {
+			g.logger.Error("Failed to count database records: %v", err)
+			g.showErrorDialog("Database Count Failed", err.Error())
+			return
+		}
+		g.logger.Info("DRY RUN MODE: Would delete %d database records (%d bytes) and modify telemetry IDs", estimate.Records, estimate.Bytes)
+		g.setResults(fmt.Sprintf("DRY RUN: Would delete %d database records, freeing %s, and modify telemetry IDs (no actual changes made)",
+			estimate.Records, utils.FormatBytes(estimate.Bytes)))
 		return
 	}
 
-	browserCleaner, err := browser.NewBrowserCleaner()
-	if err != nil {
-		g.logger.Error("Browser cleaner creation failed: %v", err)
+	release, ok := g.acquireInstanceLock("Quick Clean")
+	if !ok {
 		return
 	}
-
-	results, err := browserCleaner.CleanBrowserData(config.CreateBackups)
+	defer release()
+
+	result, err := augmentcleaner.QuickClean(context.Background(), g.cleanerOptions())
+	if result != nil {
+		if result.Database != nil {
+			for _, backupPath := range result.Database.DBBackupPaths {
+				g.logger.LogBackupCreated("database", backupPath)
+			}
+		}
+		if result.Telemetry != nil {
+			g.logger.LogBackupCreated("storage.json", result.Telemetry.StorageBackupPath)
+		}
+	}
 	if err != nil {
-		g.logger.Error("Browser cleaning failed: %v", err)
+		g.logger.LogOperationResult("Quick Clean", false, err.Error())
+		g.showOperationError("Quick Clean Failed", err)
 		return
 	}
 
-	// Count total items cleaned
-	totalItems := int64(0)
-	for _, result := range results {
-		totalItems += result.CookiesDeleted + result.StorageDeleted + result.CacheDeleted
-		if result.BackupPath != "" {
-			g.logger.LogBackupCreated("browser-"+result.Profile.Name, result.BackupPath)
+	g.logger.LogOperationResult("Quick Clean", true, fmt.Sprintf("Deleted %d records and modified telemetry IDs in %s",
+		result.Database.DeletedRows, result.Duration.Round(time.Millisecond)))
+
+	// Display results
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	g.setResults(fmt.Sprintf("Quick Clean Completed:\n%s", string(resultJSON)))
+}
+
+// acquireInstanceLock takes the instance lock before an operation modifies data,
+// so that a scheduled CLI run cannot clean or back up the same files at the same
+// time. It reports a busy lock to the user and returns false if it is not acquired.
+func (g *MainGUI) acquireInstanceLock(operation string) (func(), bool) {
+	path, err := instancelock.DefaultPath()
+	if err == nil {
+		var lock *instancelock.Lock
+		if lock, err = instancelock.Acquire(path, operation); err == nil {
+			return lock.Release, true
 		}
 	}
 
-	g.logger.Info("Browser data cleaned successfully, processed %d items", totalItems)
+	g.logger.LogOperationResult(operation, false, err.Error())
+	var busy *instancelock.BusyError
+	if errors.As(err, &busy) {
+		g.showErrorDialog("Another Instance Running", fmt.Sprintf("%v.\nWait for it to finish and try again.", busy))
+	} else {
+		g.showErrorDialog("Instance Lock Failed", err.Error())
+	}
+	return nil, false
+}
+
+// cleanerOptions builds library options from the current configuration
+func (g *MainGUI) cleanerOptions() augmentcleaner.Options {
+	config := g.configManager.GetConfig()
+	historyPath, _ := augmentcleaner.DefaultHistoryPath() // Empty path disables the history
+	return augmentcleaner.Options{
+		CreateBackups:         config.CreateBackups,
+		BrowserBackupDir:      config.BrowserBackupDir,
+		DatabaseBatchSize:     config.CleanRateLimit.BatchSize,
+		DatabaseBatchDelay:    time.Duration(config.CleanRateLimit.BatchDelayMs) * time.Millisecond,
+		DatabaseLockBackoff:   time.Duration(config.CleanRateLimit.LockBackoffMs) * time.Millisecond,
+		StorageLimits:         config.StorageLimits,
+		CustomAugmentPatterns: config.CustomAugmentPatterns,
+		Progress: func(p augmentcleaner.Progress) {
+			g.logger.Debug("[%s] %s", p.Operation, p.Message)
+		},
+		Logger:      g.logger,
+		HistoryPath: historyPath,
+	}
 }
 
 // Helper methods for UI state management
@@ -340,6 +468,7 @@ func (g *MainGUI) setOperationState(running bool, status string) {
 	} else {
 		g.hideProgress()
 		g.enableButtons()
+		g.refreshHistory()
 	}
 }
 
@@ -349,6 +478,10 @@ func (g *MainGUI) disableButtons() {
 	g.cleanWorkspaceBtn.Disable()
 	g.cleanBrowserBtn.Disable()
 	g.runAllBtn.Disable()
+	g.quickCleanBtn.Disable()
+	for _, status := range g.operationStatuses {
+		status.rerun.Disable()
+	}
 }
 
 func (g *MainGUI) enableButtons() {
@@ -357,6 +490,43 @@ func (g *MainGUI) enableButtons() {
 	g.cleanWorkspaceBtn.Enable()
 	g.cleanBrowserBtn.Enable()
 	g.runAllBtn.Enable()
+	g.quickCleanBtn.Enable()
+	for _, status := range g.operationStatuses {
+		if status.record != nil {
+			status.rerun.Enable()
+		}
+	}
+}
+
+// checkCloudSync warns when the VS Code data or the backup directories of an
+// operation are inside a OneDrive, Dropbox, Google Drive or iCloud Drive folder
+// and returns whether the user wants to continue anyway
+func (g *MainGUI) checkCloudSync(title string, backupDirs ...string) bool {
+	config := g.configManager.GetConfig()
+	if !config.CreateBackups || config.DryRunMode {
+		backupDirs = nil
+	}
+
+	matches := augmentcleaner.FindCloudSyncPaths(g.cleanerOptions(), backupDirs)
+	if len(matches) == 0 {
+		return true
+	}
+
+	var message strings.Builder
+	for _, match := range matches {
+		if match.Backup {
+			g.logger.Warn("Backup directory %s is inside %s folder %s", match.Path, match.Provider, match.Root)
+			fmt.Fprintf(&message, "⚠️ Backup directory %s is inside the %s folder %s.\n"+
+				"Backups would be uploaded to cloud storage and can use a lot of space.\n\n", match.Path, match.Provider, match.Root)
+			continue
+		}
+		g.logger.Warn("VS Code data %s is inside %s folder %s", match.Path, match.Provider, match.Root)
+		fmt.Fprintf(&message, "⚠️ VS Code data %s is inside the %s folder %s.\n"+
+			"Pause %s syncing while cleaning, or the sync client may restore or conflict with the cleaned files.\n\n",
+			match.Path, match.Provider, match.Root, match.Provider)
+	}
+	message.WriteString("Continue anyway?")
+	return g.showConfirmationDialog(title+": Cloud Sync Folder", message.String())
 }
 
 // Dialog helpers
@@ -373,3 +543,38 @@ func (g *MainGUI) showConfirmationDialog(title, message string) bool {
 func (g *MainGUI) showErrorDialog(title, message string) {
 	dialog.ShowError(fmt.Errorf(message), g.window)
 }
+
+// showOperationError shows a failed operation, with remediation hints instead of the
+// raw error when it failed for lack of access rights
+func (g *MainGUI) showOperationError(title string, err error) {
+	if augmentcleaner.IsPermissionError(err) {
+		g.showPermissionDeniedDialog([]string{augmentcleaner.PermissionDeniedPath(err)})
+		return
+	}
+	g.showErrorDialog(title, err.Error())
+}
+
+// showPermissionDeniedDialog lists the paths that could not be cleaned for lack of
+// access rights and what the user can do about it
+func (g *MainGUI) showPermissionDeniedDialog(paths []string) {
+	const maxListed = 10
+
+	var message strings.Builder
+	message.WriteString("These items could not be cleaned because access was denied:\n\n")
+	for i, path := range paths {
+		if i == maxListed {
+			fmt.Fprintf(&message, "... and %d more (see the log)\n", len(paths)-maxListed)
+			break
+		}
+		message.WriteString(path + "\n")
+	}
+	message.WriteString("\nTo clean them:\n")
+	for _, hint := range augmentcleaner.PermissionRemediationHints() {
+		message.WriteString("• " + hint + "\n")
+	}
+
+	for _, path := range paths {
+		g.logger.Warn("Permission denied: %s", path)
+	}
+	dialog.ShowInformation("Permission Denied", message.String(), g.window)
+}
@@ -9,6 +9,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"augment-telemetry-cleaner/internal/config"
+	"augment-telemetry-cleaner/internal/retry"
 )
 
 // SettingsDialog represents the settings configuration dialog
@@ -24,6 +25,7 @@ type SettingsDialog struct {
 	
 	logLevelSelect    *widget.Select
 	backupDirEntry    *widget.Entry
+	browserBackupDirEntry *widget.Entry
 	maxBackupEntry    *widget.Entry
 	dbTimeoutEntry    *widget.Entry
 	retriesEntry      *widget.Entry
@@ -62,6 +64,11 @@ func (sd *SettingsDialog) createComponents() {
 	sd.backupDirEntry = widget.NewEntry()
 	sd.backupDirEntry.SetText(config.BackupDirectory)
 	
+	// Browser backup directory, empty keeps backups/browser-data
+	sd.browserBackupDirEntry = widget.NewEntry()
+	sd.browserBackupDirEntry.SetPlaceHolder("backups/browser-data")
+	sd.browserBackupDirEntry.SetText(config.BrowserBackupDir)
+	
 	// Numeric settings
 	sd.maxBackupEntry = widget.NewEntry()
 	sd.maxBackupEntry.SetText(fmt.Sprintf("%d", config.MaxBackupAge))
@@ -114,9 +121,16 @@ func (sd *SettingsDialog) createDialogContent() fyne.CanvasObject {
 		sd.backupDirEntry,
 	)
 	
+	browserBackupDirContainer := container.NewBorder(
+		nil, nil, nil, widget.NewButton("Browse", sd.onBrowseBrowserBackupDir),
+		sd.browserBackupDirEntry,
+	)
+	
 	backupCard := widget.NewCard("Backup Settings", "", container.NewVBox(
 		widget.NewLabel("Backup Directory:"),
 		backupDirContainer,
+		widget.NewLabel("Browser Backup Directory:"),
+		browserBackupDirContainer,
 		widget.NewLabel("Maximum Backup Age (days):"),
 		sd.maxBackupEntry,
 	))
@@ -164,6 +178,16 @@ func (sd *SettingsDialog) onBrowseBackupDir() {
 	folderDialog.Show()
 }
 
+func (sd *SettingsDialog) onBrowseBrowserBackupDir() {
+	folderDialog := dialog.NewFolderOpen(func(folder fyne.ListableURI, err error) {
+		if err == nil && folder != nil {
+			sd.browserBackupDirEntry.SetText(folder.Path())
+		}
+	}, sd.parent)
+
+	folderDialog.Show()
+}
+
 func (sd *SettingsDialog) onSave() {
 	// Validate inputs
 	if err := sd.validateInputs(); err != nil {
@@ -179,6 +203,7 @@ func (sd *SettingsDialog) onSave() {
 		config.ShowPreviewBeforeRun = sd.previewCheck.Checked
 		config.LogLevel = sd.logLevelSelect.Selected
 		config.BackupDirectory = sd.backupDirEntry.Text
+		config.BrowserBackupDir = sd.browserBackupDirEntry.Text
 		
 		// Parse numeric values
 		if maxAge, err := parseIntSafe(sd.maxBackupEntry.Text); err == nil {
@@ -196,6 +221,7 @@ func (sd *SettingsDialog) onSave() {
 		dialog.ShowError(fmt.Errorf("failed to save settings: %w", err), sd.parent)
 		return
 	}
+	retry.SetDefault(sd.configManager.GetConfig().RetryPolicy())
 	
 	dialog.ShowInformation("Settings Saved", "Settings have been saved successfully!", sd.parent)
 	sd.dialog.Hide()
@@ -221,6 +247,7 @@ func (sd *SettingsDialog) resetToDefaults() {
 	sd.previewCheck.SetChecked(defaultConfig.ShowPreviewBeforeRun)
 	sd.logLevelSelect.SetSelected(defaultConfig.LogLevel)
 	sd.backupDirEntry.SetText(defaultConfig.BackupDirectory)
+	sd.browserBackupDirEntry.SetText(defaultConfig.BrowserBackupDir)
 	sd.maxBackupEntry.SetText(fmt.Sprintf("%d", defaultConfig.MaxBackupAge))
 	sd.dbTimeoutEntry.SetText(fmt.Sprintf("%d", defaultConfig.DatabaseTimeout))
 	sd.retriesEntry.SetText(fmt.Sprintf("%d", defaultConfig.FileOperationRetries))
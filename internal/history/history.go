@@ -0,0 +1,159 @@
+// Package history records an append-only log of cleaning operations so users can
+// see when an operation last ran and what it changed.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/config"
+	"augment-telemetry-cleaner/internal/filelock"
+)
+
+// FileName is the name of the history file in the application config directory
+const FileName = "history.jsonl"
+
+// maxRecordSize bounds a single history line; larger lines are skipped when reading
+const maxRecordSize = 1024 * 1024
+
+// Record is a single operation in the history file
+type Record struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Operation string                 `json:"operation"`
+	Success   bool                   `json:"success"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	Summary   string                 `json:"summary"`
+	BackupIDs []string               `json:"backup_ids,omitempty"` // Paths of backups created by the operation
+	Errors    []string               `json:"errors,omitempty"`
+}
+
+// Store reads and appends records of a JSONL history file
+type Store struct {
+	path string
+}
+
+// NewStore creates a history store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the history file path in the application config directory
+func DefaultPath() (string, error) {
+	configDir, err := config.AppConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, FileName), nil
+}
+
+// Path returns the history file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append writes a record to the end of the history file. Records without a
+// timestamp are stamped with the current time.
+func (s *Store) Append(record Record) error {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	// Lock the file so records of concurrent runs are not interleaved
+	unlock, err := filelock.Lock(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer unlock()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history record: %w", err)
+	}
+
+	return nil
+}
+
+// Last returns up to n of the most recent records, newest first. n <= 0 returns
+// every record. A missing history file yields no records.
+func (s *Store) Last(n int) ([]Record, error) {
+	records, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+
+	return records, nil
+}
+
+// LastSuccess returns the most recent successful record of an operation, or nil if
+// the operation never succeeded
+func (s *Store) LastSuccess(operation string) (*Record, error) {
+	records, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Operation == operation && records[i].Success {
+			return &records[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readAll reads every record in file order. Lines that cannot be parsed, such as
+// a record cut short by a crash, are skipped.
+func (s *Store) readAll() ([]Record, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return records, nil
+}
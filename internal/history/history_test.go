@@ -0,0 +1,96 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreAppendAndLast(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+
+	records, err := store.Last(10)
+	if err != nil {
+		t.Fatalf("Last() on missing file failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected no records, got %v", records)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i, op := range []string{"modify-telemetry", "clean-database", "clean-workspace"} {
+		record := Record{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Operation: op,
+			Success:   true,
+			Summary:   "done",
+			BackupIDs: []string{"/backups/" + op},
+		}
+		if err := store.Append(record); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	records, err = store.Last(2)
+	if err != nil {
+		t.Fatalf("Last() failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Operation != "clean-workspace" || records[1].Operation != "clean-database" {
+		t.Errorf("Expected the two newest records, newest first, got %+v", records)
+	}
+
+	all, err := store.Last(0)
+	if err != nil {
+		t.Fatalf("Last(0) failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected all 3 records, got %d", len(all))
+	}
+}
+
+func TestStoreLastSuccess(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName))
+
+	rotated := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	store.Append(Record{Timestamp: rotated, Operation: "modify-telemetry", Success: true})
+	store.Append(Record{Operation: "modify-telemetry", Success: false, Errors: []string{"storage file not found"}})
+	store.Append(Record{Operation: "clean-database", Success: true})
+
+	record, err := store.LastSuccess("modify-telemetry")
+	if err != nil {
+		t.Fatalf("LastSuccess() failed: %v", err)
+	}
+	if record == nil || !record.Timestamp.Equal(rotated) {
+		t.Errorf("Expected the successful rotation at %v, got %+v", rotated, record)
+	}
+
+	record, err = store.LastSuccess("clean-browser")
+	if err != nil || record != nil {
+		t.Errorf("Expected no record for an operation that never ran, got %+v, %v", record, err)
+	}
+}
+
+func TestStoreSkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store := NewStore(path)
+	store.Append(Record{Operation: "clean-database", Success: true})
+
+	// Simulate a record cut short by a crash
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	file.WriteString(`{"timestamp":"2024-01-01T00:00:00Z","operat` + "\n")
+	file.Close()
+
+	store.Append(Record{Operation: "clean-workspace", Success: true})
+
+	records, err := store.Last(0)
+	if err != nil {
+		t.Fatalf("Last() failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("Expected the corrupt line to be skipped, got %+v", records)
+	}
+}
@@ -0,0 +1,153 @@
+// Package instancelock lets only one instance of the tool, GUI or CLI, modify
+// VS Code and browser data at a time. The lock is an advisory lock on a file in
+// the application config directory that also records the PID of its holder.
+package instancelock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"augment-telemetry-cleaner/internal/config"
+	"augment-telemetry-cleaner/internal/filelock"
+)
+
+// FileName is the name of the lock file in the application config directory
+const FileName = "instance.lock"
+
+// DefaultPollInterval is how often Wait retries a busy lock
+const DefaultPollInterval = 500 * time.Millisecond
+
+// Owner identifies the process holding the lock
+type Owner struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Operation string    `json:"operation,omitempty"`
+}
+
+// BusyError is returned when another instance holds the lock. Owner is nil if
+// the holder could not be identified, e.g. while it is still writing its PID.
+type BusyError struct {
+	Owner *Owner
+}
+
+// Error describes the instance holding the lock
+func (e *BusyError) Error() string {
+	if e.Owner == nil {
+		return "another instance is running"
+	}
+	msg := fmt.Sprintf("another instance (pid %d, started at %s) is running",
+		e.Owner.PID, e.Owner.StartedAt.Format("2006-01-02 15:04:05"))
+	if e.Owner.Operation != "" {
+		msg += " " + e.Owner.Operation
+	}
+	return msg
+}
+
+// Unwrap lets errors.Is match filelock.ErrLockBusy
+func (e *BusyError) Unwrap() error {
+	return filelock.ErrLockBusy
+}
+
+// Lock is a held instance lock
+type Lock struct {
+	path   string
+	unlock func()
+}
+
+// DefaultPath returns the lock file path in the application config directory
+func DefaultPath() (string, error) {
+	configDir, err := config.AppConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, FileName), nil
+}
+
+// Acquire takes the lock at path without blocking, recording this process and
+// operation as its owner. It returns a *BusyError if another instance holds it.
+func Acquire(path, operation string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// The lock file is never replaced, since a new file would not carry the lock
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	file.Close()
+
+	unlock, err := filelock.Lock(path)
+	if errors.Is(err, filelock.ErrLockBusy) {
+		return nil, &BusyError{Owner: readOwner(path)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock instance: %w", err)
+	}
+
+	owner := Owner{PID: os.Getpid(), StartedAt: time.Now(), Operation: operation}
+	data, err := json.Marshal(owner)
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("failed to write lock owner: %w", err)
+	}
+
+	return &Lock{path: path, unlock: unlock}, nil
+}
+
+// Wait takes the lock at path like Acquire, retrying every interval while
+// another instance holds it. onBusy, if not nil, is called with the first
+// BusyError so the caller can tell the user what it is waiting for.
+func Wait(ctx context.Context, path, operation string, interval time.Duration, onBusy func(*BusyError)) (*Lock, error) {
+	for {
+		lock, err := Acquire(path, operation)
+		var busy *BusyError
+		if !errors.As(err, &busy) {
+			return lock, err
+		}
+		if onBusy != nil {
+			onBusy(busy)
+			onBusy = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%v: %w", busy, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Path returns the lock file path
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release releases the lock. The lock file is left in place for the next owner.
+func (l *Lock) Release() {
+	if l.unlock != nil {
+		l.unlock()
+		l.unlock = nil
+	}
+}
+
+// readOwner returns the owner recorded in the lock file, or nil if it cannot be read
+func readOwner(path string) *Owner {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var owner Owner
+	if err := json.Unmarshal(data, &owner); err != nil || owner.PID == 0 {
+		return nil
+	}
+	return &owner
+}
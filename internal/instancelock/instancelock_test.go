@@ -0,0 +1,88 @@
+package instancelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/filelock"
+)
+
+func TestAcquireReportsOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", FileName)
+
+	lock, err := Acquire(path, "clean-database")
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+
+	_, err = Acquire(path, "clean-browser")
+	var busy *BusyError
+	if !errors.As(err, &busy) || !errors.Is(err, filelock.ErrLockBusy) {
+		t.Fatalf("Expected a BusyError while locked, got %v", err)
+	}
+	if busy.Owner == nil || busy.Owner.PID != os.Getpid() || busy.Owner.Operation != "clean-database" {
+		t.Errorf("Expected this process to own the lock, got %+v", busy.Owner)
+	}
+	if want := fmt.Sprintf("another instance (pid %d, started at ", os.Getpid()); !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Expected error to start with %q, got %q", want, err.Error())
+	}
+
+	lock.Release()
+	lock.Release()
+
+	lock, err = Acquire(path, "clean-browser")
+	if err != nil {
+		t.Fatalf("Expected the lock to be free after Release, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestBusyErrorWithoutOwner(t *testing.T) {
+	if got := (&BusyError{}).Error(); got != "another instance is running" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+func TestWaitBlocksUntilReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	lock, err := Acquire(path, "run-all")
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+
+	busyCalls := 0
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Release()
+	}()
+
+	waited, err := Wait(context.Background(), path, "quick-clean", 10*time.Millisecond, func(*BusyError) { busyCalls++ })
+	if err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	defer waited.Release()
+	if busyCalls != 1 {
+		t.Errorf("Expected onBusy to be called once, got %d", busyCalls)
+	}
+}
+
+func TestWaitStopsWhenContextDone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	lock, err := Acquire(path, "run-all")
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	defer lock.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := Wait(ctx, path, "run-all", 10*time.Millisecond, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline error, got %v", err)
+	}
+}
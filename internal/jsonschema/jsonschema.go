@@ -0,0 +1,380 @@
+// Package jsonschema generates JSON Schemas for the result structs the CLI
+// prints with --output json and stamps each document with its schema version.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// VersionField is the top-level field holding the schema version of a document
+const VersionField = "schema_version"
+
+// ResultField holds the result of a document whose result is not a JSON object
+const ResultField = "result"
+
+// Schema is a JSON Schema, limited to the keywords DocumentSchema produces and
+// the annotations and constraints of the config file schema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // A type name or a list of them
+	Format               string             `json:"format,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or a *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// MarshalDocument encodes result as a JSON document with a top-level
+// schema_version field. Object results get the field added in front of their
+// own; any other result is wrapped as {"schema_version": N, "result": ...}.
+func MarshalDocument(result interface{}, version int, indent bool) ([]byte, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bytes.Buffer
+	if isObject(data) {
+		fmt.Fprintf(&document, `{"%s":%d`, VersionField, version)
+		if body := bytes.TrimSpace(data[1:]); !bytes.Equal(body, []byte("}")) {
+			document.WriteByte(',')
+		}
+		document.Write(data[1:])
+	} else {
+		fmt.Fprintf(&document, `{"%s":%d,"%s":%s}`, VersionField, version, ResultField, data)
+	}
+
+	if !indent {
+		return document.Bytes(), nil
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, document.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// DocumentSchema returns the schema of the documents MarshalDocument writes for
+// results of the given types. Several types give a schema matching any of them.
+func DocumentSchema(title string, version int, types ...reflect.Type) *Schema {
+	g := &generator{defs: make(map[string]*Schema)}
+
+	var documents []*Schema
+	for _, t := range types {
+		documents = append(documents, g.document(t, version))
+	}
+
+	root := documents[0]
+	if len(documents) > 1 {
+		root = &Schema{AnyOf: documents}
+	}
+	root.Schema = Draft
+	root.Title = title
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+// generator builds schemas, collecting named struct types in defs so that
+// shared and recursive types are described once
+type generator struct {
+	defs map[string]*Schema
+}
+
+// document returns the schema of a document holding a result of type t
+func (g *generator) document(t reflect.Type, version int) *Schema {
+	versionSchema := &Schema{Type: "integer", Const: version}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t != timeType && !t.Implements(marshalerType) {
+		// A copy of the struct schema rather than a $ref, since the struct
+		// schema does not allow the extra version field
+		object := g.structSchema(t)
+		properties := map[string]*Schema{VersionField: versionSchema}
+		for name, property := range object.Properties {
+			properties[name] = property
+		}
+		object.Properties = properties
+		object.Required = append([]string{VersionField}, object.Required...)
+		return object
+	}
+
+	return &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{VersionField: versionSchema, ResultField: g.schema(t)},
+		Required:             []string{VersionField, ResultField},
+		AdditionalProperties: false,
+	}
+}
+
+// schema returns the schema of values of type t as encoding/json marshals them
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(marshalerType) {
+		return &Schema{} // Custom encoding, anything goes
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Ptr:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{"string", "null"}} // Base64
+		}
+		return &Schema{Type: []string{"array", "null"}, Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = &Schema{} // Placeholder for recursive references
+			g.defs[name] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	default: // Interfaces
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of struct type t. Fields without
+// omitempty are required and unknown properties are not allowed.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	object := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	g.addFields(object, t)
+	return object
+}
+
+// addFields adds the JSON properties of the fields of struct type t to object,
+// including those promoted from embedded structs
+func (g *generator) addFields(object *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(object, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		switch fieldType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128:
+			continue // encoding/json cannot marshal these
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		object.Properties[name] = g.schema(fieldType)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			object.Required = append(object.Required, name)
+		}
+	}
+}
+
+// nullable returns s extended to also allow null
+func nullable(s *Schema) *Schema {
+	switch types := s.Type.(type) {
+	case string:
+		copied := *s
+		copied.Type = []string{types, "null"}
+		return &copied
+	case []string:
+		return s // Slices and maps already allow null
+	}
+	if s.Ref == "" && s.AnyOf == nil {
+		return s // Anything goes
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+// defName returns the $defs key of a named type, qualified by its package
+func defName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// isObject reports whether data is a JSON object
+func isObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// Validate checks a JSON document against s. It only understands the keywords
+// DocumentSchema produces, which is enough to check documents against
+// generated schemas without a full JSON Schema implementation.
+func (s *Schema) Validate(document []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+	return s.validate(s, value, "$")
+}
+
+// validate checks value at location against s, resolving $refs in root
+func (s *Schema) validate(root *Schema, value interface{}, location string) error {
+	if s.Ref != "" {
+		def, ok := root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			return fmt.Errorf("%s: unknown $ref %s", location, s.Ref)
+		}
+		return def.validate(root, value, location)
+	}
+
+	if len(s.AnyOf) > 0 {
+		var errs []string
+		for _, option := range s.AnyOf {
+			err := option.validate(root, value, location)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("%s: matches none of anyOf: %s", location, strings.Join(errs, "; "))
+	}
+
+	if s.Type != nil && !matchesType(s.Type, value) {
+		return fmt.Errorf("%s: expected %v, got %s", location, s.Type, jsonTypeName(value))
+	}
+	if s.Const != nil {
+		want, _ := json.Marshal(s.Const)
+		got, _ := json.Marshal(value)
+		if !bytes.Equal(want, got) {
+			return fmt.Errorf("%s: expected %s, got %s", location, want, got)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", location, name)
+			}
+		}
+		for name, property := range v {
+			propertySchema, ok := s.Properties[name]
+			if !ok {
+				switch additional := s.AdditionalProperties.(type) {
+				case bool:
+					if !additional {
+						return fmt.Errorf("%s: unexpected property %q", location, name)
+					}
+					continue
+				case *Schema:
+					propertySchema = additional
+				default:
+					continue
+				}
+			}
+			if err := propertySchema.validate(root, property, location+"."+name); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(root, item, fmt.Sprintf("%s[%d]", location, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value has one of the JSON types in schemaType
+func matchesType(schemaType interface{}, value interface{}) bool {
+	var types []string
+	switch t := schemaType.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	}
+
+	actual := jsonTypeName(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName returns the JSON Schema type of a value decoded by encoding/json
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
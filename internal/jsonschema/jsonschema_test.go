@@ -0,0 +1,132 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEmbedded struct {
+	Source string `json:"source"`
+}
+
+type testNode struct {
+	Name     string     `json:"name"`
+	Children []testNode `json:"children,omitempty"`
+}
+
+type testResult struct {
+	testEmbedded
+	Count    int               `json:"count"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	When     time.Time         `json:"when"`
+	Parent   *testNode         `json:"parent"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Value    interface{}       `json:"value"`
+	Internal string            `json:"-"`
+	hidden   bool
+}
+
+func TestMarshalDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   string
+	}{
+		{"object", struct {
+			A int `json:"a"`
+		}{1}, `{"schema_version":3,"a":1}`},
+		{"empty object", struct{}{}, `{"schema_version":3}`},
+		{"array", []string{"x"}, `{"schema_version":3,"result":["x"]}`},
+		{"nil slice", []string(nil), `{"schema_version":3,"result":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalDocument(tt.result, 3, false)
+			if err != nil {
+				t.Fatalf("MarshalDocument() failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("MarshalDocument() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+
+	indented, err := MarshalDocument(map[string]int{"a": 1}, 1, true)
+	if err != nil {
+		t.Fatalf("MarshalDocument() failed: %v", err)
+	}
+	if want := "{\n  \"schema_version\": 1,\n  \"a\": 1\n}"; string(indented) != want {
+		t.Errorf("Expected indented document %q, got %q", want, indented)
+	}
+}
+
+func TestDocumentSchema(t *testing.T) {
+	schema := DocumentSchema("test", 2, reflect.TypeOf(&testResult{}))
+
+	if schema.Schema != Draft || schema.Title != "test" {
+		t.Errorf("Expected $schema and title on the root, got %q and %q", schema.Schema, schema.Title)
+	}
+	wantRequired := []string{"schema_version", "source", "count", "when", "parent", "value"}
+	if !reflect.DeepEqual(schema.Required, wantRequired) {
+		t.Errorf("Expected required %v, got %v", wantRequired, schema.Required)
+	}
+	for _, name := range []string{"Internal", "-", "hidden"} {
+		if _, ok := schema.Properties[name]; ok {
+			t.Errorf("Expected no property for the %s field", name)
+		}
+	}
+	if when := schema.Properties["when"]; when.Type != "string" || when.Format != "date-time" {
+		t.Errorf("Expected time.Time as a date-time string, got %+v", when)
+	}
+	if _, ok := schema.Defs["jsonschema.testNode"]; !ok {
+		t.Errorf("Expected the recursive testNode in $defs, got %v", schema.Defs)
+	}
+
+	valid := `{"schema_version":2,"source":"s","count":1,"when":"2024-01-01T00:00:00Z",
+		"parent":{"name":"a","children":[{"name":"b"}]},"labels":{"k":"v"},"value":[1]}`
+	if err := schema.Validate([]byte(valid)); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+	nullParent := `{"schema_version":2,"source":"s","count":1,"when":"2024-01-01T00:00:00Z","parent":null,"value":null}`
+	if err := schema.Validate([]byte(nullParent)); err != nil {
+		t.Errorf("Expected a null pointer to be valid, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"wrong version":    `{"schema_version":1,"source":"s","count":1,"when":"","parent":null,"value":null}`,
+		"missing required": `{"schema_version":2,"source":"s","when":"","parent":null,"value":null}`,
+		"unknown property": `{"schema_version":2,"source":"s","count":1,"when":"","parent":null,"value":null,"extra":1}`,
+		"wrong type":       `{"schema_version":2,"source":"s","count":1.5,"when":"","parent":null,"value":null}`,
+		"nested":           `{"schema_version":2,"source":"s","count":1,"when":"","parent":{"name":1},"value":null}`,
+	}
+	for name, document := range invalid {
+		if err := schema.Validate([]byte(document)); err == nil {
+			t.Errorf("Expected %s to be invalid", name)
+		}
+	}
+}
+
+func TestDocumentSchemaNonObjectResults(t *testing.T) {
+	schema := DocumentSchema("list", 1, reflect.TypeOf([]testNode{}), reflect.TypeOf(&testNode{}))
+	if len(schema.AnyOf) != 2 {
+		t.Fatalf("Expected anyOf with 2 documents, got %+v", schema)
+	}
+
+	for _, document := range []string{
+		`{"schema_version":1,"result":[{"name":"a"}]}`,
+		`{"schema_version":1,"result":null}`,
+		`{"schema_version":1,"name":"a"}`,
+	} {
+		if err := schema.Validate([]byte(document)); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", document, err)
+		}
+	}
+
+	err := schema.Validate([]byte(`{"schema_version":1,"result":"a"}`))
+	if err == nil || !strings.Contains(err.Error(), "anyOf") {
+		t.Errorf("Expected an anyOf mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAMLDocuments encodes result as YAML with the same fields, in the same
+// order, as MarshalDocument. A non-empty slice or array result is written as a
+// stream of documents separated by "---", one per element, each stamped with
+// the schema version like a document of its own.
+func MarshalYAMLDocuments(result interface{}, version int) ([]byte, error) {
+	items := []interface{}{result}
+	if v := reflect.ValueOf(result); (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) &&
+		v.Len() > 0 && v.Type().Elem().Kind() != reflect.Uint8 {
+		items = make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	for _, item := range items {
+		document, err := MarshalDocument(item, version, false)
+		if err != nil {
+			return nil, err
+		}
+		// JSON is YAML, so decoding the document into a node keeps its key order
+		var node yaml.Node
+		if err := yaml.Unmarshal(document, &node); err != nil {
+			return nil, fmt.Errorf("failed to convert document to YAML: %w", err)
+		}
+		blockStyle(&node)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// blockStyle drops the flow style and quoting node had as JSON, so it encodes
+// as block YAML; strings that would read as another type stay quoted
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,120 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeYAMLDocuments decodes every document of a YAML stream as JSON would, so
+// numbers compare as float64 like in a decoded JSON document
+func decodeYAMLDocuments(t *testing.T, data []byte) []interface{} {
+	t.Helper()
+	var documents []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents
+			}
+			t.Fatalf("Failed to decode YAML: %v\n%s", err, data)
+		}
+		encoded, err := json.Marshal(document)
+		if err != nil {
+			t.Fatalf("Failed to re-encode YAML document: %v", err)
+		}
+		var normalized interface{}
+		if err := json.Unmarshal(encoded, &normalized); err != nil {
+			t.Fatal(err)
+		}
+		documents = append(documents, normalized)
+	}
+}
+
+func decodeJSONDocument(t *testing.T, result interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := MarshalDocument(result, 3, false)
+	if err != nil {
+		t.Fatalf("MarshalDocument() failed: %v", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func TestMarshalYAMLDocumentsMatchesJSON(t *testing.T) {
+	result := testResult{
+		testEmbedded: testEmbedded{Source: "scan"},
+		Count:        2144,
+		Ratio:        0.5,
+		When:         time.Date(2024, 3, 1, 14, 5, 0, 0, time.UTC),
+		Parent:       &testNode{Name: "root", Children: []testNode{{Name: "leaf"}}},
+		Labels:       map[string]string{"b": "true", "a": "123", "multi": "line one\nline two"},
+		Value:        []interface{}{"- dash", nil, "key: value"},
+		Internal:     "not encoded",
+	}
+
+	data, err := MarshalYAMLDocuments(result, 3)
+	if err != nil {
+		t.Fatalf("MarshalYAMLDocuments() failed: %v", err)
+	}
+	documents := decodeYAMLDocuments(t, data)
+	if len(documents) != 1 {
+		t.Fatalf("Expected 1 document, got %d:\n%s", len(documents), data)
+	}
+	if want := decodeJSONDocument(t, result); !reflect.DeepEqual(documents[0], interface{}(want)) {
+		t.Errorf("YAML document differs from JSON:\nYAML: %#v\nJSON: %#v", documents[0], want)
+	}
+
+	// Fields keep the order of the JSON tags
+	text := string(data)
+	var last int
+	for _, field := range []string{"schema_version:", "source:", "count:", "ratio:", "when:", "parent:", "labels:", "value:"} {
+		i := strings.Index(text, field)
+		if i < last {
+			t.Errorf("Expected %s after the fields before it:\n%s", field, text)
+		}
+		last = i
+	}
+}
+
+func TestMarshalYAMLDocumentsLists(t *testing.T) {
+	nodes := []testNode{{Name: "a"}, {Name: "b", Children: []testNode{{Name: "c"}}}}
+	data, err := MarshalYAMLDocuments(nodes, 3)
+	if err != nil {
+		t.Fatalf("MarshalYAMLDocuments() failed: %v", err)
+	}
+	if strings.Count(string(data), "---\n") != len(nodes)-1 {
+		t.Errorf("Expected documents separated by ---:\n%s", data)
+	}
+
+	documents := decodeYAMLDocuments(t, data)
+	if len(documents) != len(nodes) {
+		t.Fatalf("Expected %d documents, got %d", len(nodes), len(documents))
+	}
+	for i, node := range nodes {
+		if want := decodeJSONDocument(t, node); !reflect.DeepEqual(documents[i], interface{}(want)) {
+			t.Errorf("Document %d = %#v, want %#v", i, documents[i], want)
+		}
+	}
+
+	// An empty list is one document, like its JSON document
+	data, err = MarshalYAMLDocuments([]testNode{}, 3)
+	if err != nil {
+		t.Fatalf("MarshalYAMLDocuments() failed: %v", err)
+	}
+	documents = decodeYAMLDocuments(t, data)
+	if want := decodeJSONDocument(t, []testNode{}); len(documents) != 1 || !reflect.DeepEqual(documents[0], interface{}(want)) {
+		t.Errorf("Empty list = %#v, want %#v", documents, want)
+	}
+}
@@ -0,0 +1,51 @@
+// Package likepattern checks user-defined SQL LIKE patterns of Augment data and
+// translates them into regular expressions, so file names, storage origins and
+// file content can be matched by the same patterns as database rows.
+package likepattern
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validate checks that pattern is usable as a custom Augment pattern. A pattern
+// made of nothing but the wildcards % and _ would match every row, cookie and
+// file, so it is rejected.
+func Validate(pattern string) error {
+	if strings.Trim(pattern, "%_") == "" {
+		return fmt.Errorf("pattern %q must contain characters other than %% and _", pattern)
+	}
+	return nil
+}
+
+// Compile returns a regular expression matching the same values as
+// "value LIKE pattern" in SQLite: % matches any run of characters, _ any single
+// character, and letters match case-insensitively.
+func Compile(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + translate(pattern) + "$")
+}
+
+// CompileSearch returns a regular expression matching wherever text contains a
+// value that matches pattern, e.g. the name of an Augment deployment inside a
+// cache file. The % at either end of pattern do not need to match anything.
+func CompileSearch(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(translate(strings.Trim(pattern, "%")))
+}
+
+// translate converts a LIKE pattern into the body of a regular expression
+func translate(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?is)")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package likepattern
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	for _, pattern := range []string{"", "%", "%%", "_", "%_%"} {
+		if err := Validate(pattern); err == nil {
+			t.Errorf("Validate(%q) should fail", pattern)
+		}
+	}
+	for _, pattern := range []string{"%acme%", "acme-ai", "%a_b%"} {
+		if err := Validate(pattern); err != nil {
+			t.Errorf("Validate(%q) failed: %v", pattern, err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"%acme%", "https://app.ACME.dev", true},
+		{"%acme%", "github.com", false},
+		{"acme%", "acme_session", true},
+		{"acme%", "my_acme_session", false},
+		{"%acme_ai%", "acme-ai", true},
+		{"%acme_ai%", "acmeai", false},
+		{"%a.c%", "abc", false}, // Regular expression characters are literal
+		{"%a.c%", "a.c", true},
+	}
+
+	for _, tt := range tests {
+		if got := Compile(tt.pattern).MatchString(tt.value); got != tt.want {
+			t.Errorf("%q LIKE %q = %v, want %v", tt.value, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCompileSearch(t *testing.T) {
+	search := CompileSearch("%acme_ai%")
+	if !search.MatchString("{\"host\":\"ACME-AI.example\"}") {
+		t.Error("Expected the pattern to be found inside the content")
+	}
+	if search.MatchString("acmeai") {
+		t.Error("Expected _ to require a character")
+	}
+}
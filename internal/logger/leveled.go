@@ -0,0 +1,55 @@
+package logger
+
+import "fmt"
+
+// Leveled is the logging interface the cleaning packages accept. *Logger
+// implements it; Discard and FuncLogger cover callers without a log file.
+type Leveled interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// Discard is a Leveled logger that drops every message
+var Discard Leveled = FuncLogger(nil)
+
+// FuncLogger adapts a function receiving formatted messages to Leveled.
+// Level filtering is left to the function; a nil FuncLogger drops everything.
+type FuncLogger func(level LogLevel, message string)
+
+// Debug logs a debug message
+func (f FuncLogger) Debug(format string, args ...interface{}) {
+	f.log(DEBUG, format, args...)
+}
+
+// Info logs an info message
+func (f FuncLogger) Info(format string, args ...interface{}) {
+	f.log(INFO, format, args...)
+}
+
+// Warn logs a warning message
+func (f FuncLogger) Warn(format string, args ...interface{}) {
+	f.log(WARN, format, args...)
+}
+
+// Error logs an error message
+func (f FuncLogger) Error(format string, args ...interface{}) {
+	f.log(ERROR, format, args...)
+}
+
+// log formats the message and passes it to the function
+func (f FuncLogger) log(level LogLevel, format string, args ...interface{}) {
+	if f == nil {
+		return
+	}
+	f(level, fmt.Sprintf(format, args...))
+}
+
+// OrDiscard returns l, or Discard when l is nil
+func OrDiscard(l Leveled) Leveled {
+	if l == nil {
+		return Discard
+	}
+	return l
+}
@@ -0,0 +1,36 @@
+package resources
+
+import (
+	_ "embed"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+//go:embed icon-dark.png
+var iconDarkPng []byte
+
+//go:embed icon.svg
+var iconSvg []byte
+
+// ResourceIconDarkPng is the icon framed in a light border so it stays visible on
+// dark backgrounds such as a dark-mode Windows taskbar
+var ResourceIconDarkPng = &fyne.StaticResource{
+	StaticName:    "icon-dark.png",
+	StaticContent: iconDarkPng,
+}
+
+// ResourceIconSvg is the scalable icon installed with Linux desktop entries
+var ResourceIconSvg = &fyne.StaticResource{
+	StaticName:    "icon.svg",
+	StaticContent: iconSvg,
+}
+
+// IconForVariant returns the application icon for a theme variant: the framed
+// icon for the dark theme and ResourceIconPng otherwise
+func IconForVariant(variant fyne.ThemeVariant) fyne.Resource {
+	if variant == theme.VariantDark {
+		return ResourceIconDarkPng
+	}
+	return ResourceIconPng
+}
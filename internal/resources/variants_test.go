@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestEmbeddedIconsDecode(t *testing.T) {
+	for _, resource := range []interface {
+		Name() string
+		Content() []byte
+	}{ResourceIconPng, ResourceIconDarkPng} {
+		img, err := png.Decode(bytes.NewReader(resource.Content()))
+		if err != nil {
+			t.Errorf("Failed to decode %s: %v", resource.Name(), err)
+			continue
+		}
+		if bounds := img.Bounds(); bounds.Dx() != bounds.Dy() || bounds.Dx() < 256 {
+			t.Errorf("Expected %s to be square and at least 256px, got %v", resource.Name(), bounds)
+		}
+	}
+
+	var svg struct {
+		XMLName xml.Name
+		ViewBox string `xml:"viewBox,attr"`
+	}
+	if err := xml.Unmarshal(ResourceIconSvg.Content(), &svg); err != nil {
+		t.Fatalf("Failed to decode %s: %v", ResourceIconSvg.Name(), err)
+	}
+	if svg.XMLName.Local != "svg" || svg.ViewBox == "" {
+		t.Errorf("Expected an <svg> root with a viewBox, got %+v", svg)
+	}
+}
+
+func TestIconForVariant(t *testing.T) {
+	if IconForVariant(theme.VariantDark) != ResourceIconDarkPng {
+		t.Error("Expected the framed icon for the dark theme")
+	}
+	if IconForVariant(theme.VariantLight) != ResourceIconPng {
+		t.Error("Expected the standard icon for the light theme")
+	}
+}
@@ -0,0 +1,168 @@
+// Package retry repeats operations that fail transiently, such as deleting a file
+// another process still holds open, with exponential backoff and jitter.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Default retry settings, used until SetDefault is called
+const (
+	DefaultAttempts  = 3
+	DefaultBaseDelay = 100 * time.Millisecond
+	DefaultMaxDelay  = 2 * time.Second
+	DefaultJitter    = 0.2
+)
+
+// Policy controls how often an operation is tried and how long to wait in
+// between. The wait before retry n is BaseDelay * 2^(n-1), capped at MaxDelay and
+// varied randomly by up to Jitter of itself so that concurrent callers spread out.
+type Policy struct {
+	Attempts  int           // Tries including the first; values below 1 mean a single try
+	BaseDelay time.Duration // Wait before the first retry
+	MaxDelay  time.Duration // Upper bound of a single wait; 0 means no bound
+	Jitter    float64       // Fraction of each wait that is randomized, from 0 to 1
+
+	clock  Clock          // nil uses the real clock
+	random func() float64 // Returns values in [0, 1); nil uses math/rand
+}
+
+// Clock waits between attempts; tests substitute one that does not sleep
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock waits in real time
+type realClock struct{}
+
+// RealClock is the clock a Policy waits on unless WithClock replaces it
+var RealClock Clock = realClock{}
+
+// After returns a channel that receives the time once d has passed
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultPolicy = DefaultPolicy()
+)
+
+// DefaultPolicy returns the built-in policy: 3 attempts, starting at 100ms
+func DefaultPolicy() Policy {
+	return Policy{
+		Attempts:  DefaultAttempts,
+		BaseDelay: DefaultBaseDelay,
+		MaxDelay:  DefaultMaxDelay,
+		Jitter:    DefaultJitter,
+	}
+}
+
+// SetDefault replaces the policy used by Do, e.g. with one built from the config file
+func SetDefault(policy Policy) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPolicy = policy
+}
+
+// Default returns the policy used by Do
+func Default() Policy {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPolicy
+}
+
+// Do runs fn with the default policy, see Policy.Do
+func Do(ctx context.Context, fn func() error) error {
+	return Default().Do(ctx, fn)
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it at once instead of retrying.
+// Do returns err itself, not the wrapper.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a Permanent error or the attempts are
+// used up, and returns the last error. Waiting stops early with the context's
+// error when ctx is done.
+func (p Policy) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= p.Attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.getClock().After(p.jittered(p.Delay(attempt))):
+		}
+	}
+}
+
+// Delay returns the wait before retry n (1-based), without jitter
+func (p Policy) Delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry; i++ {
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// jittered varies delay by up to ±Jitter of itself
+func (p Policy) jittered(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	random := p.random
+	if random == nil {
+		random = rand.Float64
+	}
+	jitter := min(p.Jitter, 1)
+	return time.Duration(float64(delay) * (1 - jitter + 2*jitter*random()))
+}
+
+// WithClock returns a copy of the policy that waits on clock instead of the real
+// clock, so that callers' tests do not sleep
+func (p Policy) WithClock(clock Clock) Policy {
+	p.clock = clock
+	return p
+}
+
+// getClock returns the configured clock or the real one
+func (p Policy) getClock() Clock {
+	if p.clock == nil {
+		return RealClock
+	}
+	return p.clock
+}
@@ -0,0 +1,141 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock records the waits it is asked for and returns at once
+type fakeClock struct {
+	waits []time.Duration
+	block bool // Never return, to test cancellation
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if !c.block {
+		ch <- time.Time{}
+	}
+	return ch
+}
+
+// failing returns an fn that fails the first n calls, and a pointer to the call count
+func failing(n int) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= n {
+			return errors.New("busy")
+		}
+		return nil
+	}, &calls
+}
+
+func TestDoBacksOffExponentially(t *testing.T) {
+	clock := &fakeClock{}
+	policy := Policy{Attempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond, clock: clock}
+
+	fn, calls := failing(4)
+	if err := policy.Do(context.Background(), fn); err != nil {
+		t.Fatalf("Expected the fifth attempt to succeed, got %v", err)
+	}
+	if *calls != 5 {
+		t.Errorf("Expected 5 calls, got %d", *calls)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	if !reflect.DeepEqual(clock.waits, want) {
+		t.Errorf("Expected waits %v, got %v", want, clock.waits)
+	}
+}
+
+func TestDoGivesUpAfterAttempts(t *testing.T) {
+	clock := &fakeClock{}
+	policy := Policy{Attempts: 3, BaseDelay: time.Second, clock: clock}
+
+	fn, calls := failing(10)
+	if err := policy.Do(context.Background(), fn); err == nil || err.Error() != "busy" {
+		t.Errorf("Expected the last error, got %v", err)
+	}
+	if *calls != 3 || len(clock.waits) != 2 {
+		t.Errorf("Expected 3 calls and 2 waits, got %d calls and waits %v", *calls, clock.waits)
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	clock := &fakeClock{}
+	policy := Policy{Attempts: 5, BaseDelay: time.Second, clock: clock}
+	notFound := errors.New("not found")
+
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		calls++
+		return Permanent(notFound)
+	})
+	if err != notFound {
+		t.Errorf("Expected the unwrapped permanent error, got %v", err)
+	}
+	if calls != 1 || len(clock.waits) != 0 {
+		t.Errorf("Expected a single call without waiting, got %d calls and waits %v", calls, clock.waits)
+	}
+	if Permanent(nil) != nil {
+		t.Error("Expected Permanent(nil) to be nil")
+	}
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	clock := &fakeClock{block: true}
+	policy := Policy{Attempts: 5, BaseDelay: time.Hour, clock: clock}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fn, calls := failing(10)
+	done := make(chan error, 1)
+	go func() { done <- policy.Do(ctx, fn) }()
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d calls", *calls)
+	}
+}
+
+func TestDoJitter(t *testing.T) {
+	for _, tc := range []struct {
+		random float64
+		want   time.Duration
+	}{
+		{0, 80 * time.Millisecond},
+		{0.5, 100 * time.Millisecond},
+		{0.99, 119600 * time.Microsecond},
+	} {
+		clock := &fakeClock{}
+		random := tc.random
+		policy := Policy{Attempts: 2, BaseDelay: 100 * time.Millisecond, Jitter: 0.2, clock: clock, random: func() float64 { return random }}
+
+		fn, _ := failing(1)
+		if err := policy.Do(context.Background(), fn); err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		if len(clock.waits) != 1 || clock.waits[0] != tc.want {
+			t.Errorf("random %v: expected a wait of %v, got %v", tc.random, tc.want, clock.waits)
+		}
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	defer SetDefault(DefaultPolicy())
+
+	SetDefault(Policy{Attempts: 1})
+	fn, calls := failing(1)
+	if err := Do(context.Background(), fn); err == nil {
+		t.Error("Expected a single attempt to fail")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, got %d", *calls)
+	}
+}
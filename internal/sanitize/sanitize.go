@@ -0,0 +1,256 @@
+// Package sanitize masks secrets and personal data before values are shown in
+// reports, logs or exported results.
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaskedValue replaces values that are sensitive as a whole
+const MaskedValue = "[SENSITIVE DATA MASKED]"
+
+// DefaultMaxLength is the length at which sanitized strings are truncated
+const DefaultMaxLength = 100
+
+// prefixLength is the number of characters of a detected secret that are kept
+const prefixLength = 4
+
+// sensitiveValueWords mark a string value as sensitive as a whole
+var sensitiveValueWords = []string{"password", "token", "secret", "key"}
+
+// sensitiveKeyWords mark the value stored under a key as a credential. They are
+// matched against whole segments of the key name, or the end of one, so
+// "authToken" and "oauth" are sensitive but "author" and "authority" are not.
+var sensitiveKeyWords = []string{"password", "passwd", "secret", "token", "apikey", "accesskey", "privatekey", "credential", "cookie", "auth", "authorization"}
+
+// secretPattern is a detectable secret format
+type secretPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// secretPatterns are applied in order; more specific formats come first
+var secretPatterns = []secretPattern{
+	{"jwt", regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)},
+	{"email", regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{"uuid", regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)},
+	{"hex", regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)},
+	{"base64", regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`)},
+}
+
+// String sanitizes s and truncates it to DefaultMaxLength characters
+func String(s string) string {
+	return StringN(s, DefaultMaxLength)
+}
+
+// StringN masks sensitive values and embedded secrets in s, then truncates the
+// result to maxLen characters. Masking always happens before truncation so a
+// secret is never partially revealed by the cut.
+func StringN(s string, maxLen int) string {
+	if containsAny(s, sensitiveValueWords) {
+		return MaskedValue
+	}
+
+	s = MaskSecrets(s)
+
+	if maxLen > 0 && utf8.RuneCountInString(s) > maxLen {
+		runes := []rune(s)
+		return string(runes[:maxLen]) + "... (truncated)"
+	}
+	return s
+}
+
+// MaskSecrets replaces JWTs, email addresses, UUIDs and long hex or base64 strings
+// in s with a short prefix followed by the detected kind, e.g. "eyJh***[jwt]"
+func MaskSecrets(s string) string {
+	for _, sp := range secretPatterns {
+		kind := sp.kind
+		s = sp.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if kind == "base64" && !looksLikeEncodedSecret(match) {
+				return match
+			}
+			return maskWithPrefix(match, kind)
+		})
+	}
+	return s
+}
+
+// Snippet returns the first maxLen characters of a secret followed by "...".
+// Short secrets reveal at most half of their characters, and the cut never
+// splits a multi-byte character.
+func Snippet(secret string, maxLen int) string {
+	if secret == "" {
+		return ""
+	}
+	runes := []rune(secret)
+	n := maxLen
+	if len(runes)/2 < n {
+		n = len(runes) / 2
+	}
+	return string(runes[:n]) + "..."
+}
+
+// Value sanitizes a decoded JSON value. Strings are sanitized with String, and maps
+// and slices are copied with every element sanitized; values stored under sensitive
+// keys are masked entirely.
+func Value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return String(v)
+	case map[string]interface{}:
+		sanitized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			sanitized[key] = KeyValue(key, item)
+		}
+		return sanitized
+	case []interface{}:
+		sanitized := make([]interface{}, len(v))
+		for i, item := range v {
+			sanitized[i] = Value(item)
+		}
+		return sanitized
+	default:
+		return value
+	}
+}
+
+// KeyValue sanitizes a value stored under key, masking it entirely if the key name
+// marks it as sensitive
+func KeyValue(key string, value interface{}) interface{} {
+	if value != nil && IsSensitiveKey(key) {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return Value(value)
+		default:
+			return MaskedValue
+		}
+	}
+	return Value(value)
+}
+
+// KeyStringN sanitizes a string stored under key, truncating it to maxLen characters
+func KeyStringN(key, value string, maxLen int) string {
+	if value != "" && IsSensitiveKey(key) {
+		return MaskedValue
+	}
+	return StringN(value, maxLen)
+}
+
+// IsSensitiveKey reports whether a key name suggests its value is a credential:
+// one of its segments, alone or joined to the one before it as in "api_key",
+// is or ends with one of sensitiveKeyWords, ignoring a plural "s"
+func IsSensitiveKey(key string) bool {
+	segments := keySegments(key)
+	for i, segment := range segments {
+		if isSensitiveSegment(segment) || (i > 0 && isSensitiveSegment(segments[i-1]+segment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSensitiveSegment reports whether a lowercase key segment is or ends with one
+// of sensitiveKeyWords
+func isSensitiveSegment(segment string) bool {
+	singular := strings.TrimSuffix(segment, "s")
+	for _, word := range sensitiveKeyWords {
+		if strings.HasSuffix(segment, word) || strings.HasSuffix(singular, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySegments splits a key name into lowercase segments at punctuation, between
+// letters and digits and at camelCase boundaries, e.g. "user.OAuth2Token" into
+// "user", "o", "auth", "2" and "token", and "HTTPAuthHeader" into "http",
+// "auth" and "header"
+func keySegments(key string) []string {
+	var segments []string
+	runes := []rune(key)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				segments = append(segments, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && isSegmentBoundary(runes, i) {
+			segments = append(segments, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, strings.ToLower(string(runes[start:])))
+	}
+	return segments
+}
+
+// isSegmentBoundary reports whether a new key segment starts at runes[i], which
+// follows a letter or digit
+func isSegmentBoundary(runes []rune, i int) bool {
+	prev, r := runes[i-1], runes[i]
+	switch {
+	case unicode.IsDigit(prev) != unicode.IsDigit(r):
+		return true
+	case unicode.IsUpper(r) && !unicode.IsUpper(prev):
+		return true // camelCase
+	case unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+		return true // The last capital of an acronym, as in "HTTPAuth"
+	}
+	return false
+}
+
+// containsAny reports whether s contains one of words, ignoring case
+func containsAny(s string, words []string) bool {
+	lower := strings.ToLower(s)
+	for _, word := range words {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskWithPrefix keeps the first few characters of a secret and names its kind
+func maskWithPrefix(secret, kind string) string {
+	prefix := []rune(secret)
+	if len(prefix) > prefixLength {
+		prefix = prefix[:prefixLength]
+	}
+	return string(prefix) + "***[" + kind + "]"
+}
+
+// looksLikeEncodedSecret reports whether a base64-looking match is an encoded secret
+// rather than a file path or long identifier: at least one "/"-separated segment
+// must be long and mix letters with digits
+func looksLikeEncodedSecret(s string) bool {
+	for _, segment := range strings.Split(s, "/") {
+		if len(segment) >= 24 && mixesLettersAndDigits(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// mixesLettersAndDigits reports whether s contains both letters and digits
+func mixesLettersAndDigits(s string) bool {
+	var hasLetter, hasDigit bool
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			hasLetter = true
+		}
+	}
+	return hasLetter && hasDigit
+}
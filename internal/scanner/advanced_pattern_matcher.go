@@ -12,6 +12,8 @@ type AdvancedPatternMatcher struct {
 	semanticPatterns   map[string]TelemetryRisk
 	combinationRules   []CombinationRule
 	exclusionPatterns  []*regexp.Regexp
+	endpointChecker    *TelemetryEndpointChecker
+	customPatterns     []customAugmentPattern // See AddCustomAugmentPatterns
 }
 
 // CombinationRule defines rules for combining multiple pattern matches
@@ -33,6 +35,7 @@ type PatternMatch struct {
 	Category    string        `json:"category"`
 	Line        int           `json:"line"`
 	Column      int           `json:"column"`
+	Offset      int64         `json:"offset,omitempty"` // Approximate byte offset, set by AnalyzeBundledExtension
 	Surrounding []string      `json:"surrounding"`
 }
 
@@ -41,6 +44,7 @@ func NewAdvancedPatternMatcher() *AdvancedPatternMatcher {
 	matcher := &AdvancedPatternMatcher{
 		contextPatterns:  make(map[string][]*regexp.Regexp),
 		semanticPatterns: make(map[string]TelemetryRisk),
+		endpointChecker:  NewTelemetryEndpointChecker(),
 	}
 	matcher.initializeContextPatterns()
 	matcher.initializeSemanticPatterns()
@@ -174,7 +178,7 @@ func (apm *AdvancedPatternMatcher) initializeCombinationRules() {
 func (apm *AdvancedPatternMatcher) initializeExclusionPatterns() {
 	exclusionPatterns := []string{
 		// Comments and documentation
-		`(?i)//.*(?:telemetry|analytics|tracking)`,
+		`(?i)(?:^|[^:])//.*(?:telemetry|analytics|tracking)`, // Not the // of a URL scheme
 		`(?i)/\*.*(?:telemetry|analytics|tracking).*\*/`,
 		`(?i)\*.*(?:telemetry|analytics|tracking)`,
 		
@@ -392,6 +396,8 @@ func (apm *AdvancedPatternMatcher) determineContextRisk(context, match string) T
 		return TelemetryRiskHigh
 	case "config_access":
 		return TelemetryRiskMedium
+	case customAugmentContext:
+		return TelemetryRiskHigh
 	default:
 		return TelemetryRiskLow
 	}
@@ -418,6 +424,48 @@ func (apm *AdvancedPatternMatcher) getSurroundingLines(lines []string, lineNum,
 	return surrounding
 }
 
+// Stored value formats that identify a machine, user or session, or date an event
+var (
+	machineIDValuePattern = regexp.MustCompile(`(?i)^machine-[0-9a-f]{8,}$`)
+	uuidValuePattern      = regexp.MustCompile(`(?i)^\{?[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\}?$`)
+	emailValuePattern     = regexp.MustCompile(`(?i)^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`)
+	domainValuePattern    = regexp.MustCompile(`(?i)^(?:https?://)?(?:[a-z0-9-]+\.)+[a-z]{2,}(?::\d+)?(?:/\S*)?$`)
+	timestampValuePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?$`)
+)
+
+// AnalyzeStorageValue assesses a stored value by its format rather than its key,
+// so that an identifier kept under a non-obvious key such as "clientContext.id"
+// is still recognized. It returns TelemetryRiskNone and an empty explanation for
+// values that are not strings or match no known format.
+func (apm *AdvancedPatternMatcher) AnalyzeStorageValue(key string, value interface{}) (TelemetryRisk, string) {
+	str, ok := value.(string)
+	if !ok {
+		return TelemetryRiskNone, ""
+	}
+	str = strings.TrimSpace(str)
+
+	switch {
+	case machineIDValuePattern.MatchString(str):
+		return TelemetryRiskCritical, fmt.Sprintf("%q holds a machine-<hex> value, the format of machine identifiers", key)
+	case uuidValuePattern.MatchString(str):
+		return TelemetryRiskHigh, fmt.Sprintf("%q holds a UUID, the usual format of device, session and user identifiers", key)
+	case emailValuePattern.MatchString(str):
+		return TelemetryRiskHigh, fmt.Sprintf("%q holds an email address, which identifies the user", key)
+	case domainValuePattern.MatchString(str):
+		endpoint := str
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint
+		}
+		if category, _, ok := apm.endpointChecker.Check(endpoint); ok {
+			return TelemetryRiskHigh, fmt.Sprintf("%q holds the address of a telemetry endpoint (%s)", key, category)
+		}
+	case timestampValuePattern.MatchString(str):
+		return TelemetryRiskLow, fmt.Sprintf("%q holds an ISO 8601 timestamp, which can record when events happened", key)
+	}
+
+	return TelemetryRiskNone, ""
+}
+
 // GetPatternStatistics returns statistics about pattern matching
 func (apm *AdvancedPatternMatcher) GetPatternStatistics() map[string]int {
 	stats := make(map[string]int)
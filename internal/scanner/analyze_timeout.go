@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// errAnalysisStopped is returned by an analysis that was abandoned by its caller
+var errAnalysisStopped = errors.New("storage analysis stopped")
+
+// analysisEvent carries one finished extension or workspace from the analysis goroutine
+type analysisEvent struct {
+	extension *ExtensionStorage
+	workspace *WorkspaceStorage
+}
+
+// analysisMonitor lets a running analysis publish progress and notice cancellation.
+// A nil monitor is valid and does nothing.
+type analysisMonitor struct {
+	ctx    context.Context
+	events chan<- analysisEvent
+}
+
+// stopped reports whether the analysis should stop
+func (m *analysisMonitor) stopped() bool {
+	return m != nil && m.ctx.Err() != nil
+}
+
+// extensionDone publishes a finished global extension storage
+func (m *analysisMonitor) extensionDone(storage ExtensionStorage) {
+	m.publish(analysisEvent{extension: &storage})
+}
+
+// workspaceDone publishes a finished workspace storage
+func (m *analysisMonitor) workspaceDone(storage WorkspaceStorage) {
+	m.publish(analysisEvent{workspace: &storage})
+}
+
+// publish sends an event unless the caller has stopped listening
+func (m *analysisMonitor) publish(event analysisEvent) {
+	if m == nil {
+		return
+	}
+	select {
+	case m.events <- event:
+	case <-m.ctx.Done():
+	}
+}
+
+// analysisOutcome is the final result of the analysis goroutine
+type analysisOutcome struct {
+	result *StorageAnalysisResult
+	err    error
+}
+
+// AnalyzeWithTimeout runs AnalyzeStorage for extensionID ("" for every extension) in
+// the background and waits at most timeout.
+// If the analysis does not finish in time, the extensions and workspaces processed so
+// far are returned with AnalysisIncomplete set; the bool result reports completeness.
+func (sa *StorageAnalyzer) AnalyzeWithTimeout(timeout time.Duration, extensionID string) (*StorageAnalysisResult, bool, error) {
+	elapsed := utils.StartTimer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan analysisEvent)
+	done := make(chan analysisOutcome, 1)
+	monitor := &analysisMonitor{ctx: ctx, events: events}
+
+	go func() {
+		result, err := sa.analyzeStorage(monitor, extensionID)
+		done <- analysisOutcome{result: result, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var extensions []ExtensionStorage
+	var workspaces []WorkspaceStorage
+
+	for {
+		select {
+		case event := <-events:
+			if event.extension != nil {
+				extensions = append(extensions, *event.extension)
+			}
+			if event.workspace != nil {
+				workspaces = append(workspaces, *event.workspace)
+			}
+
+		case outcome := <-done:
+			if outcome.err != nil {
+				return nil, false, outcome.err
+			}
+			return outcome.result, true, nil
+
+		case <-timer.C:
+			cancel()
+			result := sa.buildPartialResult(extensions, workspaces)
+			result.IncompleteReason = fmt.Sprintf("analysis timed out after %s", timeout)
+			result.ScanDuration = elapsed()
+			return result, false, nil
+		}
+	}
+}
+
+// buildPartialResult assembles a result from the storages analyzed before a timeout
+func (sa *StorageAnalyzer) buildPartialResult(extensions []ExtensionStorage, workspaces []WorkspaceStorage) *StorageAnalysisResult {
+	result := &StorageAnalysisResult{
+		CrossExtensionData:  make([]CrossExtensionData, 0),
+		SizeLimitViolations: make([]SizeLimitViolation, 0),
+		SecretStoreAnalysis: make([]SecretEntry, 0),
+		SkippedExtensions:   make([]string, 0),
+		AnalysisIncomplete:  true,
+		ScannedAt:           time.Now(),
+	}
+
+	global := &result.GlobalStorageAnalysis
+	global.ExtensionStorages = make([]ExtensionStorage, 0, len(extensions))
+	processed := make(map[string]bool)
+	for _, storage := range extensions {
+		global.ExtensionStorages = append(global.ExtensionStorages, storage)
+		global.TotalSize += storage.TotalSize
+		global.TelemetrySize += storage.TelemetrySize
+		if storage.Risk >= TelemetryRiskMedium {
+			global.TelemetryCount++
+		}
+		processed[storage.ExtensionID] = true
+
+		result.SizeLimitViolations = append(result.SizeLimitViolations, sa.CheckStorageSizeLimits(storage)...)
+	}
+	global.ExtensionCount = len(global.ExtensionStorages)
+
+	workspace := &result.WorkspaceStorageAnalysis
+	workspace.WorkspaceStorages = make([]WorkspaceStorage, 0, len(workspaces))
+	extensionSet := make(map[string]bool)
+	for _, storage := range workspaces {
+		workspace.WorkspaceStorages = append(workspace.WorkspaceStorages, storage)
+		workspace.TotalSize += storage.TotalSize
+		workspace.TelemetrySize += storage.TelemetrySize
+		for _, ext := range storage.ExtensionStorages {
+			extensionSet[ext.ExtensionID] = true
+		}
+	}
+	workspace.WorkspaceCount = len(workspace.WorkspaceStorages)
+	workspace.ExtensionCount = len(extensionSet)
+	result.RetentionViolations = sa.checkRetentionViolations(global.ExtensionStorages, workspace.WorkspaceStorages)
+
+	// Everything in global storage that was not reached is reported as skipped
+	if globalStoragePaths, err := sa.paths.GlobalStoragePaths(); err == nil {
+		for _, globalStoragePath := range globalStoragePaths {
+			entries, err := os.ReadDir(globalStoragePath)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if entry.IsDir() && !processed[entry.Name()] {
+					result.SkippedExtensions = append(result.SkippedExtensions, entry.Name())
+				}
+			}
+		}
+	}
+
+	result.CrossExtensionData = sa.correlationAnalyzer.AnalyzeCrossExtensionData(
+		global.ExtensionStorages, workspace.WorkspaceStorages)
+
+	sortStorageAnalysisResult(result)
+	result.StorageStatistics = sa.calculateStorageStatistics(result)
+
+	return result
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeWithTimeoutComplete(t *testing.T) {
+	writeStorageFixture(t)
+
+	result, complete, err := NewStorageAnalyzer().AnalyzeWithTimeout(time.Minute, "")
+	if err != nil {
+		t.Fatalf("AnalyzeWithTimeout() failed: %v", err)
+	}
+	if !complete || result.AnalysisIncomplete {
+		t.Fatal("Expected a complete analysis")
+	}
+	if result.GlobalStorageAnalysis.ExtensionCount != 4 {
+		t.Errorf("Expected 4 extensions, got %d", result.GlobalStorageAnalysis.ExtensionCount)
+	}
+}
+
+func TestBuildPartialResultReportsSkippedExtensions(t *testing.T) {
+	writeStorageFixture(t)
+	analyzer := NewStorageAnalyzer()
+
+	// Collect the first two extensions the way AnalyzeWithTimeout does
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan analysisEvent)
+	monitor := &analysisMonitor{ctx: ctx, events: events}
+
+	go analyzer.analyzeGlobalStorage(monitor, "")
+
+	var extensions []ExtensionStorage
+	for len(extensions) < 2 {
+		event := <-events
+		extensions = append(extensions, *event.extension)
+	}
+	cancel()
+
+	result := analyzer.buildPartialResult(extensions, nil)
+	if !result.AnalysisIncomplete {
+		t.Error("Expected partial result to be marked incomplete")
+	}
+	if result.GlobalStorageAnalysis.ExtensionCount != 2 {
+		t.Errorf("Expected 2 processed extensions, got %d", result.GlobalStorageAnalysis.ExtensionCount)
+	}
+	if len(result.SkippedExtensions) != 2 {
+		t.Errorf("Expected 2 skipped extensions, got %v", result.SkippedExtensions)
+	}
+	for _, skipped := range result.SkippedExtensions {
+		for _, processed := range extensions {
+			if skipped == processed.ExtensionID {
+				t.Errorf("Extension %s reported as both processed and skipped", skipped)
+			}
+		}
+	}
+}
+
+func TestAnalysisMonitorStopsOnCancel(t *testing.T) {
+	writeStorageFixture(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	monitor := &analysisMonitor{ctx: ctx, events: make(chan analysisEvent)}
+
+	analysis, err := NewStorageAnalyzer().analyzeGlobalStorage(monitor, "")
+	if err != nil {
+		t.Fatalf("analyzeGlobalStorage() failed: %v", err)
+	}
+	if analysis.ExtensionCount != 0 {
+		t.Errorf("Expected no extensions after cancellation, got %d", analysis.ExtensionCount)
+	}
+}
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// anonymizedPathPrefix marks a path replaced by its salted hash
+const anonymizedPathPrefix = "anon-"
+
+// rawValueFields are the report fields holding stored values, settings values or
+// snippets of files; anonymized reports drop them and keep only sizes and risks
+var rawValueFields = map[string]bool{
+	"value":             true,
+	"current_value":     true,
+	"recommended_value": true,
+	"setting_value":     true,
+	"old_value":         true,
+	"new_value":         true,
+	"shared_values":     true,
+	"token_snippet":     true,
+	"surrounding":       true,
+	"match":             true,
+	"examples":          true,
+	"account_name":      true,
+}
+
+// pathFields are the report fields holding a path or a list of paths, which are
+// replaced by salted hashes
+var pathFields = map[string]bool{
+	"path":            true,
+	"storage_path":    true,
+	"workspace_path":  true,
+	"file_path":       true,
+	"settings_path":   true,
+	"config_path":     true,
+	"database_path":   true,
+	"install_path":    true,
+	"manifest_path":   true,
+	"folder":          true,
+	"folders":         true,
+	"uri":             true,
+	"extension_paths": true,
+	"config_files":    true,
+	"vscode_files":    true,
+	"log_files":       true,
+	"augment_files":   true,
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// anonymizer rewrites decoded JSON reports with a salt that is random per report,
+// so equal paths get equal hashes within a report but cannot be matched across reports
+type anonymizer struct {
+	salt     []byte
+	homeDir  string
+	userName string
+}
+
+// newAnonymizer creates an anonymizer with a random salt
+func newAnonymizer(homeDir string) (*anonymizer, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate anonymization salt: %w", err)
+	}
+
+	a := &anonymizer{salt: salt, homeDir: filepath.Clean(homeDir)}
+	if homeDir != "" {
+		a.userName = filepath.Base(a.homeDir)
+	}
+	return a, nil
+}
+
+// AnonymizeScanResult returns a copy of result that can be shared, e.g. in an
+// issue: stored values are removed, workspace and storage paths are replaced by
+// hashes salted per report, and home directories, user names and email addresses
+// are masked everywhere else
+func AnonymizeScanResult(result *StorageAnalysisResult, homeDir string) (*StorageAnalysisResult, error) {
+	a, err := newAnonymizer(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scan result: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode scan result: %w", err)
+	}
+
+	data, err = json.Marshal(a.anonymize(decoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode anonymized scan result: %w", err)
+	}
+	anonymized := &StorageAnalysisResult{}
+	if err := json.Unmarshal(data, anonymized); err != nil {
+		return nil, fmt.Errorf("failed to decode anonymized scan result: %w", err)
+	}
+	anonymized.Anonymized = true
+	return anonymized, nil
+}
+
+// anonymize rewrites a decoded JSON value
+func (a *anonymizer) anonymize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		anonymized := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			switch {
+			case rawValueFields[key]:
+				continue
+			case pathFields[key]:
+				anonymized[a.scrubString(key)] = a.hashPaths(field)
+			default:
+				anonymized[a.scrubString(key)] = a.anonymize(field)
+			}
+		}
+		return anonymized
+	case []interface{}:
+		anonymized := make([]interface{}, len(v))
+		for i, element := range v {
+			anonymized[i] = a.anonymize(element)
+		}
+		return anonymized
+	case string:
+		return a.scrubString(v)
+	}
+	return value
+}
+
+// hashPaths replaces a path, or each path of a list, by its salted hash
+func (a *anonymizer) hashPaths(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return a.hashPath(v)
+	case []interface{}:
+		hashed := make([]interface{}, len(v))
+		for i, element := range v {
+			hashed[i] = a.hashPaths(element)
+		}
+		return hashed
+	}
+	return a.anonymize(value)
+}
+
+// hashPath returns the salted hash of path; empty paths stay empty
+func (a *anonymizer) hashPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(path))
+	return anonymizedPathPrefix + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// scrubString masks email addresses, replaces the home directory with ~ and masks
+// the user name
+func (a *anonymizer) scrubString(s string) string {
+	s = emailPattern.ReplaceAllString(s, "<email>")
+	if a.homeDir != "" && a.homeDir != "." {
+		s = strings.ReplaceAll(s, a.homeDir, "~")
+	}
+	// Short user names like "a" would mask unrelated text
+	if len(a.userName) >= 3 {
+		s = strings.ReplaceAll(s, a.userName, "<user>")
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeScanResult(t *testing.T) {
+	homeDir := filepath.Join(string(filepath.Separator), "home", "jdoe")
+	storagePath := filepath.Join(homeDir, ".config", "Code", "User", "globalStorage", "augment.vscode-augment")
+	workspacePath := filepath.Join(homeDir, "projects", "secret-client")
+
+	storage := ExtensionStorage{
+		ExtensionID: "augment.vscode-augment",
+		StoragePath: storagePath,
+		StorageItems: []StorageDataItem{{
+			Key:   "userEmail",
+			Value: "jdoe@example.com",
+			Size:  16,
+			Risk:  TelemetryRiskCritical,
+		}},
+		SkippedFiles: []SkippedFile{{Path: filepath.Join(storagePath, "state.json"), Reason: SkipReasonInvalidJSON}},
+	}
+	result := &StorageAnalysisResult{
+		GlobalStorageAnalysis: GlobalStorageAnalysis{ExtensionStorages: []ExtensionStorage{storage}},
+		WorkspaceStorageAnalysis: WorkspaceStorageAnalysis{WorkspaceStorages: []WorkspaceStorage{
+			{WorkspaceHash: "abc123", WorkspacePath: workspacePath, ExtensionStorages: []ExtensionStorage{storage}},
+		}},
+		IncompleteReason: "timed out reading " + filepath.Join(homeDir, ".vscode") + " for jdoe@example.com",
+	}
+
+	anonymized, err := AnonymizeScanResult(result, homeDir)
+	if err != nil {
+		t.Fatalf("AnonymizeScanResult failed: %v", err)
+	}
+	if !anonymized.Anonymized {
+		t.Error("Expected the report to be marked as anonymized")
+	}
+
+	data, err := json.Marshal(anonymized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"jdoe", "secret-client", "example.com"} {
+		if strings.Contains(string(data), leak) {
+			t.Errorf("Anonymized report contains %q: %s", leak, data)
+		}
+	}
+
+	global := anonymized.GlobalStorageAnalysis.ExtensionStorages[0]
+	workspace := anonymized.WorkspaceStorageAnalysis.WorkspaceStorages[0]
+	if !strings.HasPrefix(global.StoragePath, anonymizedPathPrefix) {
+		t.Errorf("Expected storage path to be hashed, got %s", global.StoragePath)
+	}
+	if global.StoragePath != workspace.ExtensionStorages[0].StoragePath {
+		t.Error("Expected equal paths to get equal hashes within a report")
+	}
+	if global.ExtensionID != storage.ExtensionID {
+		t.Errorf("Expected extension ID to be kept, got %s", global.ExtensionID)
+	}
+
+	if want := "timed out reading " + filepath.Join("~", ".vscode") + " for <email>"; anonymized.IncompleteReason != want {
+		t.Errorf("Expected %q, got %q", want, anonymized.IncompleteReason)
+	}
+
+	item := global.StorageItems[0]
+	if item.Value != nil || item.Size != 16 || item.Risk != TelemetryRiskCritical {
+		t.Errorf("Expected value removed and size and risk kept, got %+v", item)
+	}
+	if result.GlobalStorageAnalysis.ExtensionStorages[0].StorageItems[0].Value == nil {
+		t.Error("Expected the original result to be left unchanged")
+	}
+
+	again, err := AnonymizeScanResult(result, homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.GlobalStorageAnalysis.ExtensionStorages[0].StoragePath == global.StoragePath {
+		t.Error("Expected a different salt for each report")
+	}
+}
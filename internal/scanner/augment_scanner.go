@@ -109,6 +109,11 @@ func (s *AugmentScanner) ScanSystem() (*ScanResult, error) {
 		return nil, fmt.Errorf("failed to scan common directories: %w", err)
 	}
 
+	// Sort files so output is stable between runs
+	for _, files := range [][]FileInfo{result.VSCodeFiles, result.AugmentFiles, result.ConfigFiles, result.LogFiles} {
+		sortFileInfos(files)
+	}
+
 	// Calculate totals
 	result.TotalFiles = len(result.VSCodeFiles) + len(result.AugmentFiles) + 
 					   len(result.ConfigFiles) + len(result.LogFiles)
@@ -138,9 +143,12 @@ func (s *AugmentScanner) scanVSCodeDirectories(result *ScanResult) error {
 	}
 
 	// Scan database
-	if dbPath, err := utils.GetDBPath(); err == nil {
-		if info := s.analyzeFile(dbPath, "VS Code Database"); info != nil {
-			result.VSCodeFiles = append(result.VSCodeFiles, *info)
+	paths := utils.NewVSCodePaths(nil)
+	if dbPaths, err := paths.DBPaths(); err == nil {
+		for _, dbPath := range dbPaths {
+			if info := s.analyzeFile(dbPath, "VS Code Database"); info != nil {
+				result.VSCodeFiles = append(result.VSCodeFiles, *info)
+			}
 		}
 	}
 
@@ -152,8 +160,10 @@ func (s *AugmentScanner) scanVSCodeDirectories(result *ScanResult) error {
 	}
 
 	// Scan workspace storage
-	if workspacePath, err := utils.GetWorkspaceStoragePath(); err == nil {
-		s.scanDirectory(workspacePath, result, "VS Code Workspace")
+	if workspacePaths, err := paths.WorkspaceStoragePaths(); err == nil {
+		for _, workspacePath := range workspacePaths {
+			s.scanDirectory(workspacePath, result, "VS Code Workspace")
+		}
 	}
 
 	return nil
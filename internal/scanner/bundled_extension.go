@@ -0,0 +1,319 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxBundleTokenizeSize is the largest bundle AnalyzeBundledExtension tokenizes;
+// larger files are searched with the regular expressions alone, chunk by chunk
+const MaxBundleTokenizeSize = 5 * 1024 * 1024
+
+const (
+	// bundleChunkSize is the number of new bytes searched per chunk of a large bundle
+	bundleChunkSize = 1024 * 1024
+	// bundleChunkOverlap is how far chunks overlap, so matches up to this long
+	// that span two chunks are still found
+	bundleChunkOverlap = 4 * 1024
+	// maxBundleCallTokens limits how many tokens of a call's arguments are matched
+	maxBundleCallTokens = 64
+	// bundleContextRadius is how much code around a match becomes its context
+	bundleContextRadius = 100
+)
+
+// jsIdentifierPattern matches the property names that can be written as .name
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// AnalyzeBundledExtension analyzes a bundled, usually minified, extension file
+// such as dist/extension.js without relying on its line structure. The file is
+// tokenized, and the context patterns are matched against each function call
+// rebuilt from its tokens, the semantic patterns against member expressions and
+// string literals, and string literals are checked for telemetry endpoints.
+// Escapes are decoded and computed member names are read as properties, so
+// obfuscated code such as e["\x74\x72\x61\x63\x6b\x45\x76\x65\x6e\x74"](...) is
+// matched like e.trackEvent(...). Matches carry approximate byte offsets instead
+// of line numbers. Files over MaxBundleTokenizeSize are searched with the
+// context patterns and for endpoint URLs only, without decoding.
+func (apm *AdvancedPatternMatcher) AnalyzeBundledExtension(jsPath string) ([]PatternMatch, error) {
+	info, err := os.Stat(jsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access bundle: %w", err)
+	}
+
+	var matches []PatternMatch
+	if info.Size() > MaxBundleTokenizeSize {
+		matches, err = apm.scanBundleChunks(jsPath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		content, err := os.ReadFile(jsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		matches = apm.analyzeBundleTokens(tokenizeJS(content))
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Offset < matches[j].Offset })
+
+	matches = append(matches, apm.applyCombinationRules(matches, "", jsPath)...)
+	matches = apm.filterExclusions(matches)
+	return apm.calculateConfidence(matches), nil
+}
+
+// analyzeBundleTokens matches the calls, member expressions and string literals
+// of a tokenized bundle
+func (apm *AdvancedPatternMatcher) analyzeBundleTokens(tokens []jsToken) []PatternMatch {
+	var matches []PatternMatch
+
+	for i := 0; i < len(tokens); {
+		token := tokens[i]
+		switch token.kind {
+		case jsString:
+			matches = append(matches, apm.matchBundleString(token)...)
+			i++
+
+		case jsIdent:
+			chain, end := jsMemberChain(tokens, i)
+			matches = append(matches, apm.matchSemantic(chain, chain, int64(token.offset))...)
+
+			if end < len(tokens) && tokens[end].kind == jsPunct && tokens[end].value == "(" {
+				call := chain + joinJSTokens(jsCallArguments(tokens, end))
+				if i > 0 && tokens[i-1].kind == jsIdent && tokens[i-1].value == "new" {
+					call = "new " + call
+				} else if i > 0 && tokens[i-1].kind == jsPunct && tokens[i-1].value == "." {
+					call = "." + call // Called on the result of an expression, e.g. a().b()
+				}
+				matches = append(matches, apm.matchContextPatterns(call, int64(token.offset))...)
+			}
+			// The arguments are analyzed on their own, calls in them included
+			i = end
+
+		default:
+			i++
+		}
+	}
+
+	return matches
+}
+
+// jsMemberChain reads the member expression starting with the identifier at
+// tokens[i], e.g. a.b["c"].d, and returns it written with dots where possible
+// and the index of the token after it
+func jsMemberChain(tokens []jsToken, i int) (string, int) {
+	var b strings.Builder
+	b.WriteString(tokens[i].value)
+	i++
+
+	for i < len(tokens) {
+		switch {
+		case i+1 < len(tokens) && tokens[i].value == "." && tokens[i].kind == jsPunct && tokens[i+1].kind == jsIdent:
+			b.WriteString("." + tokens[i+1].value)
+			i += 2
+		case i+2 < len(tokens) && tokens[i].value == "[" && tokens[i].kind == jsPunct &&
+			tokens[i+1].kind == jsString && tokens[i+2].value == "]" && tokens[i+2].kind == jsPunct:
+			if name := tokens[i+1].value; jsIdentifierPattern.MatchString(name) {
+				b.WriteString("." + name)
+			} else {
+				b.WriteString("[" + strconv.Quote(name) + "]")
+			}
+			i += 3
+		default:
+			return b.String(), i
+		}
+	}
+	return b.String(), i
+}
+
+// jsCallArguments returns the tokens of the parenthesized arguments starting at
+// tokens[open], at most maxBundleCallTokens of them
+func jsCallArguments(tokens []jsToken, open int) []jsToken {
+	depth := 0
+	for i := open; i < len(tokens) && i-open < maxBundleCallTokens; i++ {
+		if tokens[i].kind != jsPunct {
+			continue
+		}
+		switch tokens[i].value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return tokens[open : i+1]
+			}
+		}
+	}
+	return tokens[open:min(open+maxBundleCallTokens, len(tokens))]
+}
+
+// joinJSTokens writes tokens back as code, with string literals double-quoted
+// in their decoded form
+func joinJSTokens(tokens []jsToken) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		if token.kind == jsString {
+			b.WriteString(strconv.Quote(token.value))
+			continue
+		}
+		// Keep adjacent words such as "new X" apart
+		if i > 0 && token.kind != jsPunct && tokens[i-1].kind != jsPunct && tokens[i-1].kind != jsString {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token.value)
+	}
+	return b.String()
+}
+
+// matchContextPatterns matches the context patterns against code at offset
+func (apm *AdvancedPatternMatcher) matchContextPatterns(code string, offset int64) []PatternMatch {
+	var matches []PatternMatch
+	for context, patterns := range apm.contextPatterns {
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllString(code, -1) {
+				matches = append(matches, PatternMatch{
+					Pattern:  pattern.String(),
+					Match:    match,
+					Context:  code,
+					Risk:     apm.determineContextRisk(context, match),
+					Category: context,
+					Offset:   offset,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// matchSemantic matches the semantic patterns against text, a member expression
+// or string value, reporting context as the code it came from
+func (apm *AdvancedPatternMatcher) matchSemantic(text, context string, offset int64) []PatternMatch {
+	var matches []PatternMatch
+	lowerText := strings.ToLower(text)
+	for pattern, risk := range apm.semanticPatterns {
+		if strings.Contains(lowerText, pattern) {
+			matches = append(matches, PatternMatch{
+				Pattern:  pattern,
+				Match:    pattern,
+				Context:  context,
+				Risk:     risk,
+				Category: "semantic",
+				Offset:   offset,
+			})
+		}
+	}
+	return matches
+}
+
+// matchBundleString matches the semantic patterns against a decoded string
+// literal and checks the URLs in it for telemetry endpoints
+func (apm *AdvancedPatternMatcher) matchBundleString(token jsToken) []PatternMatch {
+	literal := strconv.Quote(token.value)
+	if len(literal) > 2*bundleContextRadius {
+		literal = literal[:2*bundleContextRadius] + "..."
+	}
+
+	matches := apm.matchSemantic(token.value, literal, int64(token.offset))
+	for _, rawURL := range urlLiteralPattern.FindAllString(token.value, -1) {
+		if match, ok := apm.matchEndpoint(rawURL, literal, int64(token.offset)); ok {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// matchEndpoint returns a match for rawURL if it is a telemetry endpoint
+func (apm *AdvancedPatternMatcher) matchEndpoint(rawURL, context string, offset int64) (PatternMatch, bool) {
+	rawURL = strings.TrimRight(rawURL, ".,;:")
+	category, risk, ok := apm.endpointChecker.Check(rawURL)
+	if !ok {
+		return PatternMatch{}, false
+	}
+	return PatternMatch{
+		Pattern:  category,
+		Match:    rawURL,
+		Context:  context,
+		Risk:     risk,
+		Category: "endpoints",
+		Offset:   offset,
+	}, true
+}
+
+// scanBundleChunks searches a large bundle for the context patterns and
+// telemetry endpoint URLs, reading it in overlapping chunks so it is never held
+// in memory as a whole
+func (apm *AdvancedPatternMatcher) scanBundleChunks(jsPath string) ([]PatternMatch, error) {
+	file, err := os.Open(jsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	var matches []PatternMatch
+	buf := make([]byte, bundleChunkSize+bundleChunkOverlap)
+	var filled int
+	var base int64
+	for {
+		n, err := io.ReadFull(file, buf[filled:])
+		filled += n
+		atEOF := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !atEOF {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		// Matches starting in the overlap are found again at the start of the next chunk
+		limit := bundleChunkSize
+		if atEOF {
+			limit = filled
+		}
+		matches = append(matches, apm.matchBundleChunk(buf[:filled], base, limit)...)
+		if atEOF {
+			return matches, nil
+		}
+
+		filled = copy(buf, buf[bundleChunkSize:filled])
+		base += bundleChunkSize
+	}
+}
+
+// matchBundleChunk returns the matches starting before limit in a chunk of a
+// bundle that starts at offset base
+func (apm *AdvancedPatternMatcher) matchBundleChunk(chunk []byte, base int64, limit int) []PatternMatch {
+	var matches []PatternMatch
+	context := func(start, end int) string {
+		return strings.ToValidUTF8(string(chunk[max(start-bundleContextRadius, 0):min(end+bundleContextRadius, len(chunk))]), "")
+	}
+
+	for category, patterns := range apm.contextPatterns {
+		for _, pattern := range patterns {
+			for _, loc := range pattern.FindAllIndex(chunk, -1) {
+				if loc[0] >= limit {
+					break
+				}
+				match := string(chunk[loc[0]:loc[1]])
+				matches = append(matches, PatternMatch{
+					Pattern:  pattern.String(),
+					Match:    match,
+					Context:  context(loc[0], loc[1]),
+					Risk:     apm.determineContextRisk(category, match),
+					Category: category,
+					Offset:   base + int64(loc[0]),
+				})
+			}
+		}
+	}
+
+	for _, loc := range urlLiteralPattern.FindAllIndex(chunk, -1) {
+		if loc[0] >= limit {
+			break
+		}
+		if match, ok := apm.matchEndpoint(string(chunk[loc[0]:loc[1]]), context(loc[0], loc[1]), base+int64(loc[0])); ok {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeJSDecodesStrings(t *testing.T) {
+	src := `a/b/c;x=/"[a-z']/g;// "comment"
+/* 'block' */s="\x74\x65\x6ce\u{6D}etry";u='😀\
+';` + "t=`https://${host}/v1/${`track`}`;"
+
+	var strs []string
+	for _, token := range tokenizeJS([]byte(src)) {
+		if token.kind == jsString {
+			strs = append(strs, token.value)
+		}
+	}
+	want := []string{"telemetry", "😀", "https://", "/v1/", "track"}
+	if strings.Join(strs, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected strings %q, got %q", want, strs)
+	}
+}
+
+func TestAnalyzeBundledExtensionMinified(t *testing.T) {
+	// Minified to one line, with the method name and URL escaped
+	src := `var r=require("\x40vscode/extension-telemetry");!function(){const e=new r.default("ext","1.0","key");` +
+		`e["\x73\x65\x6e\x64\x54\x65\x6c\x65\x6d\x65\x74\x72\x79\x45\x76\x65\x6e\x74"]("activate",{id:vscode.env.machineId});` +
+		`fetch("\x68ttps://dc.services.visualstudio.com/v2/track",{method:"POST"})}();`
+	path := filepath.Join(t.TempDir(), "extension.js")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := NewAdvancedPatternMatcher().AnalyzeBundledExtension(path)
+	if err != nil {
+		t.Fatalf("AnalyzeBundledExtension failed: %v", err)
+	}
+
+	found := func(category, match string, offset int) bool {
+		for _, m := range matches {
+			if m.Category == category && strings.Contains(m.Match, match) && m.Offset == int64(offset) {
+				return true
+			}
+		}
+		return false
+	}
+	if !found("function_calls", ".sendTelemetryEvent(", strings.Index(src, `e["`)) {
+		t.Errorf("Expected the escaped sendTelemetryEvent call, got %+v", matches)
+	}
+	if !found("imports", "@vscode/extension-telemetry", strings.Index(src, "require")) {
+		t.Errorf("Expected the telemetry import, got %+v", matches)
+	}
+	if !found("endpoints", "https://dc.services.visualstudio.com/v2/track", strings.Index(src, `"\x68ttps`)) {
+		t.Errorf("Expected the escaped endpoint URL, got %+v", matches)
+	}
+	if !found("semantic", "vscode.env.machineid", strings.Index(src, "vscode.env")) {
+		t.Errorf("Expected the machine ID access, got %+v", matches)
+	}
+}
+
+func TestScanBundleChunks(t *testing.T) {
+	const endpoint = `"https://api.segment.io/v1/track"`
+	// One endpoint spans the first chunk boundary, the other is at the end
+	first := bundleChunkSize - 10
+	content := []byte(strings.Repeat("var a=1;", bundleChunkSize*3/2/8))
+	copy(content[first:], endpoint)
+	content = append(content, endpoint...)
+	path := filepath.Join(t.TempDir(), "extension.js")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files over MaxBundleTokenizeSize are scanned this way
+	matches, err := NewAdvancedPatternMatcher().scanBundleChunks(path)
+	if err != nil {
+		t.Fatalf("scanBundleChunks failed: %v", err)
+	}
+	var offsets []int64
+	for _, m := range matches {
+		if m.Category == "endpoints" {
+			offsets = append(offsets, m.Offset)
+		}
+	}
+	last := int64(len(content) - len(endpoint))
+	if len(offsets) != 2 || offsets[0] != int64(first+1) || offsets[1] != last+1 {
+		t.Errorf("Expected endpoints at %d and %d, found once each, got %v", first+1, last+1, offsets)
+	}
+}
+
+func TestAnalyzeBundledExtensionMissingFile(t *testing.T) {
+	if _, err := NewAdvancedPatternMatcher().AnalyzeBundledExtension(filepath.Join(t.TempDir(), "missing.js")); err == nil {
+		t.Error("Expected an error for a missing bundle")
+	}
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// ConcurrencyConfig controls how many goroutines storage analysis uses.
+// Cleaning always runs sequentially and is not affected.
+type ConcurrencyConfig struct {
+	// AnalysisWorkers is the number of extensions analyzed in parallel
+	AnalysisWorkers int
+}
+
+// DefaultConcurrencyConfig returns one analysis worker per usable CPU
+func DefaultConcurrencyConfig() ConcurrencyConfig {
+	return ConcurrencyConfig{AnalysisWorkers: runtime.GOMAXPROCS(0)}
+}
+
+// SetConcurrencyConfig sets the analysis parallelism; worker counts below 1 restore the default
+func (sa *StorageAnalyzer) SetConcurrencyConfig(config ConcurrencyConfig) {
+	if config.AnalysisWorkers < 1 {
+		config.AnalysisWorkers = DefaultConcurrencyConfig().AnalysisWorkers
+	}
+	sa.concurrency = config
+}
+
+// analyzeJob is a single extension storage directory waiting to be analyzed
+type analyzeJob struct {
+	index       int
+	extensionID string
+	path        string
+}
+
+// analyzeResult is an analyzed extension storage with the position of its directory
+type analyzeResult struct {
+	index   int
+	storage ExtensionStorage
+}
+
+// analyzeGlobalStorageConcurrent analyzes the extensions in globalStoragePath with
+// workers goroutines. The result lists extensions in the same order as os.ReadDir
+// regardless of which worker finishes first.
+func (sa *StorageAnalyzer) analyzeGlobalStorageConcurrent(globalStoragePath string, workers int) (*GlobalStorageAnalysis, error) {
+	return sa.analyzeGlobalStorageWorkers(globalStoragePath, workers, nil, "")
+}
+
+// analyzeGlobalStorageWorkers is analyzeGlobalStorageConcurrent with progress reporting
+// to monitor. With extensionID set, only that extension's directory is analyzed.
+func (sa *StorageAnalyzer) analyzeGlobalStorageWorkers(globalStoragePath string, workers int, monitor *analysisMonitor, extensionID string) (*GlobalStorageAnalysis, error) {
+	analysis := &GlobalStorageAnalysis{
+		ExtensionStorages: make([]ExtensionStorage, 0),
+	}
+
+	if _, err := os.Stat(globalStoragePath); os.IsNotExist(err) {
+		return analysis, nil // No global storage directory
+	}
+
+	entries, err := os.ReadDir(globalStoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global storage directory: %w", err)
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan analyzeJob, len(entries))
+	for i, entry := range entries {
+		if entry.IsDir() && matchesExtension(extensionID, entry.Name()) {
+			jobs <- analyzeJob{index: i, extensionID: entry.Name(), path: filepath.Join(globalStoragePath, entry.Name())}
+		}
+	}
+	close(jobs)
+
+	results := make(chan analyzeResult, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if monitor.stopped() {
+					continue // Drain the remaining jobs without analyzing them
+				}
+
+				storage, err := sa.analyzeOrTrustExtensionStorage(extensionID, job.extensionID, job.path, "global")
+				if err != nil {
+					continue // Skip extensions we can't analyze
+				}
+				if manifest, err := sa.AnalyzeExtensionManifest(job.extensionID); err == nil {
+					storage.ManifestInfo = manifest
+				}
+
+				monitor.extensionDone(*storage)
+				results <- analyzeResult{index: job.index, storage: *storage}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var collected []analyzeResult
+	for result := range results {
+		collected = append(collected, result)
+	}
+
+	// Extension IDs are directory names, so this matches os.ReadDir order
+	sort.Slice(collected, func(i, j int) bool {
+		if collected[i].storage.ExtensionID != collected[j].storage.ExtensionID {
+			return collected[i].storage.ExtensionID < collected[j].storage.ExtensionID
+		}
+		return collected[i].index < collected[j].index
+	})
+
+	risks := NewRiskAggregator()
+	for _, result := range collected {
+		storage := result.storage
+		analysis.ExtensionStorages = append(analysis.ExtensionStorages, storage)
+		analysis.TotalSize += storage.TotalSize
+		analysis.TelemetrySize += storage.TelemetrySize
+
+		if storage.Risk >= TelemetryRiskMedium {
+			analysis.TelemetryCount++
+		}
+		for _, item := range storage.StorageItems {
+			risks.Add(item.Risk)
+		}
+	}
+	analysis.RiskDistribution = risks.Count()
+
+	analysis.ExtensionCount = len(analysis.ExtensionStorages)
+	return analysis, nil
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeGlobalStorageConcurrentOrder(t *testing.T) {
+	globalStoragePath := t.TempDir()
+	for i := 0; i < 25; i++ {
+		dir := filepath.Join(globalStoragePath, fmt.Sprintf("publisher.ext%02d", (i*7)%25))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create extension dir: %v", err)
+		}
+		data := fmt.Sprintf(`{"machineId": "%s", "theme": "dark"}`, strings.Repeat("a", i))
+		if err := os.WriteFile(filepath.Join(dir, "state.json"), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write storage file: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(globalStoragePath, "storage.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	entries, err := os.ReadDir(globalStoragePath)
+	if err != nil {
+		t.Fatalf("Failed to read global storage: %v", err)
+	}
+	var expected []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			expected = append(expected, entry.Name())
+		}
+	}
+
+	analyzer := NewStorageAnalyzer()
+	sequential, err := analyzer.analyzeGlobalStorageConcurrent(globalStoragePath, 1)
+	if err != nil {
+		t.Fatalf("analyzeGlobalStorageConcurrent(1) failed: %v", err)
+	}
+
+	for _, workers := range []int{2, 8, 32} {
+		analysis, err := analyzer.analyzeGlobalStorageConcurrent(globalStoragePath, workers)
+		if err != nil {
+			t.Fatalf("analyzeGlobalStorageConcurrent(%d) failed: %v", workers, err)
+		}
+
+		if analysis.ExtensionCount != len(expected) {
+			t.Fatalf("workers=%d: expected %d extensions, got %d", workers, len(expected), analysis.ExtensionCount)
+		}
+		for i, storage := range analysis.ExtensionStorages {
+			if storage.ExtensionID != expected[i] {
+				t.Errorf("workers=%d: position %d: expected %s, got %s", workers, i, expected[i], storage.ExtensionID)
+			}
+			if storage.TotalSize != sequential.ExtensionStorages[i].TotalSize {
+				t.Errorf("workers=%d: %s size differs from sequential analysis", workers, storage.ExtensionID)
+			}
+		}
+		if analysis.TotalSize != sequential.TotalSize || analysis.TelemetryCount != sequential.TelemetryCount {
+			t.Errorf("workers=%d: totals differ from sequential analysis: %+v vs %+v", workers, analysis, sequential)
+		}
+	}
+}
+
+func TestSetConcurrencyConfigDefaults(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	if analyzer.concurrency.AnalysisWorkers < 1 {
+		t.Errorf("Expected a positive default worker count, got %d", analyzer.concurrency.AnalysisWorkers)
+	}
+
+	analyzer.SetConcurrencyConfig(ConcurrencyConfig{AnalysisWorkers: 3})
+	if analyzer.concurrency.AnalysisWorkers != 3 {
+		t.Errorf("Expected 3 workers, got %d", analyzer.concurrency.AnalysisWorkers)
+	}
+
+	analyzer.SetConcurrencyConfig(ConcurrencyConfig{})
+	if analyzer.concurrency != DefaultConcurrencyConfig() {
+		t.Errorf("Expected default config, got %+v", analyzer.concurrency)
+	}
+}
@@ -19,6 +19,7 @@ type ConfigAnalysisResult struct {
 	TelemetrySettings   []ConfigFinding `json:"telemetry_settings"`
 	TotalFindings       int             `json:"total_findings"`
 	HighRiskFindings    int             `json:"high_risk_findings"`
+	Coverage            CoverageStats   `json:"coverage"`
 }
 
 // ConfigFinding represents a telemetry-related finding in configuration files
@@ -31,22 +32,37 @@ type ConfigFinding struct {
 	Category        string        `json:"category"`
 	Description     string        `json:"description"`
 	Recommendation  string        `json:"recommendation"`
+	Reasons         []string      `json:"reasons,omitempty"` // Each matched pattern and how it contributed to Risk
 }
 
 // ConfigAnalyzer handles analysis of VS Code and extension configuration files
 type ConfigAnalyzer struct {
 	telemetryKeys    map[string]TelemetryRisk
 	extensionPatterns []*regexp.Regexp
+	paths            *utils.VSCodePaths
+	guessWorkspaces  bool
 }
 
 // NewConfigAnalyzer creates a new configuration analyzer
 func NewConfigAnalyzer() *ConfigAnalyzer {
-	analyzer := &ConfigAnalyzer{}
+	return NewConfigAnalyzerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewConfigAnalyzerWithResolver creates a configuration analyzer that derives the
+// settings and storage locations from resolver
+func NewConfigAnalyzerWithResolver(resolver utils.PathResolver) *ConfigAnalyzer {
+	analyzer := &ConfigAnalyzer{paths: utils.NewVSCodePaths(resolver)}
 	analyzer.initializeTelemetryKeys()
 	analyzer.initializeExtensionPatterns()
 	return analyzer
 }
 
+// SetGuessWorkspaceFolders makes workspace settings discovery fall back to searching
+// common project directories when VS Code has no recently opened folders with settings
+func (ca *ConfigAnalyzer) SetGuessWorkspaceFolders(guess bool) {
+	ca.guessWorkspaces = guess
+}
+
 // initializeTelemetryKeys sets up known telemetry-related configuration keys
 func (ca *ConfigAnalyzer) initializeTelemetryKeys() {
 	ca.telemetryKeys = map[string]TelemetryRisk{
@@ -137,6 +153,11 @@ func (ca *ConfigAnalyzer) AnalyzeConfigurations() (*ConfigAnalysisResult, error)
 		// Continue even if extension config analysis fails
 	}
 
+	// Sort findings so output is stable between runs
+	for _, findings := range [][]ConfigFinding{result.VSCodeSettings, result.ExtensionSettings, result.WorkspaceSettings, result.TelemetrySettings} {
+		sortConfigFindings(findings)
+	}
+
 	// Calculate totals
 	ca.calculateTotals(result)
 
@@ -145,21 +166,25 @@ func (ca *ConfigAnalyzer) AnalyzeConfigurations() (*ConfigAnalysisResult, error)
 
 // analyzeVSCodeSettings analyzes VS Code user settings.json
 func (ca *ConfigAnalyzer) analyzeVSCodeSettings(result *ConfigAnalysisResult) error {
-	settingsPath, err := ca.getVSCodeSettingsPath()
+	settingsPaths, err := ca.paths.UserSettingsPaths()
 	if err != nil {
 		return fmt.Errorf("failed to get settings path: %w", err)
 	}
 
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		return nil // Settings file doesn't exist, which is normal
-	}
+	for _, settingsPath := range settingsPaths {
+		if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+			continue // Settings file doesn't exist, which is normal
+		}
 
-	settings, err := ca.loadJSONConfig(settingsPath)
-	if err != nil {
-		return fmt.Errorf("failed to load settings: %w", err)
-	}
+		result.Coverage.FilesSeen++
+		settings, err := ca.loadJSONConfig(settingsPath)
+		if err != nil {
+			result.Coverage.recordFileError(err)
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
 
-	ca.analyzeConfigObject(settings, settingsPath, "VS Code Settings", result)
+		ca.analyzeConfigObject(settings, settingsPath, "VS Code Settings", result)
+	}
 	return nil
 }
 
@@ -173,8 +198,10 @@ func (ca *ConfigAnalyzer) analyzeWorkspaceSettings(result *ConfigAnalysisResult)
 			continue
 		}
 
+		result.Coverage.FilesSeen++
 		settings, err := ca.loadJSONConfig(workspacePath)
 		if err != nil {
+			result.Coverage.recordFileError(err)
 			continue // Skip files we can't parse
 		}
 
@@ -187,57 +214,42 @@ func (ca *ConfigAnalyzer) analyzeWorkspaceSettings(result *ConfigAnalysisResult)
 // analyzeExtensionConfigurations analyzes extension-specific configuration files
 func (ca *ConfigAnalyzer) analyzeExtensionConfigurations(result *ConfigAnalysisResult) error {
 	// Analyze global storage configurations
-	globalStoragePath, err := ca.getGlobalStoragePath()
+	globalStoragePaths, err := ca.paths.GlobalStoragePaths()
 	if err != nil {
 		return err
 	}
 
-	if _, err := os.Stat(globalStoragePath); err == nil {
-		ca.analyzeGlobalStorageConfigs(globalStoragePath, result)
+	for _, globalStoragePath := range globalStoragePaths {
+		if _, err := os.Stat(globalStoragePath); err == nil {
+			ca.analyzeGlobalStorageConfigs(globalStoragePath, result)
+		}
 	}
 
 	// Analyze workspace storage configurations
-	workspaceStoragePath, err := utils.GetWorkspaceStoragePath()
+	workspaceStoragePaths, err := ca.paths.WorkspaceStoragePaths()
 	if err != nil {
 		return err
 	}
 
-	if _, err := os.Stat(workspaceStoragePath); err == nil {
-		ca.analyzeWorkspaceStorageConfigs(workspaceStoragePath, result)
+	for _, workspaceStoragePath := range workspaceStoragePaths {
+		if _, err := os.Stat(workspaceStoragePath); err == nil {
+			ca.analyzeWorkspaceStorageConfigs(workspaceStoragePath, result)
+		}
 	}
 
 	return nil
 }
 
-// getVSCodeSettingsPath returns the path to VS Code user settings
-func (ca *ConfigAnalyzer) getVSCodeSettingsPath() (string, error) {
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	switch utils.GetOS() {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "settings.json"), nil
-
-	case "darwin":
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "settings.json"), nil
-
-	default: // Linux and other Unix-like systems
-		return filepath.Join(homeDir, ".config", "Code", "User", "settings.json"), nil
-	}
-}
-
-// getWorkspaceSettingsPaths returns possible workspace settings paths
+// getWorkspaceSettingsPaths returns the settings of recently opened workspaces,
+// or of folders in common project directories if guessing is enabled and none were found
 func (ca *ConfigAnalyzer) getWorkspaceSettingsPaths() []string {
-	var paths []string
+	paths := recentWorkspaceSettingsPaths(ca.paths)
+	if len(paths) > 0 || !ca.guessWorkspaces {
+		return paths
+	}
 
 	// Common workspace locations
-	homeDir, err := utils.GetHomeDir()
+	homeDir, err := ca.paths.HomeDir()
 	if err != nil {
 		return paths
 	}
@@ -290,29 +302,6 @@ func (ca *ConfigAnalyzer) findWorkspaceSettings(dir string, paths *[]string, max
 	}
 }
 
-// getGlobalStoragePath returns the global storage path
-func (ca *ConfigAnalyzer) getGlobalStoragePath() (string, error) {
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	switch utils.GetOS() {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "globalStorage"), nil
-
-	case "darwin":
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage"), nil
-
-	default: // Linux and other Unix-like systems
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage"), nil
-	}
-}
-
 // loadJSONConfig loads and parses a JSON configuration file
 func (ca *ConfigAnalyzer) loadJSONConfig(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
@@ -345,6 +334,7 @@ func (ca *ConfigAnalyzer) analyzeConfigRecursive(obj interface{}, filePath, cate
 			
 			// Check if this key is telemetry-related
 			if risk, found := ca.telemetryKeys[currentPath]; found {
+				_, _, reasons := explainRisk([]patternMatch{{pattern: currentPath, risk: risk}})
 				finding := ConfigFinding{
 					File:        filePath,
 					Path:        currentPath,
@@ -354,29 +344,34 @@ func (ca *ConfigAnalyzer) analyzeConfigRecursive(obj interface{}, filePath, cate
 					Category:    category,
 					Description: ca.getKeyDescription(currentPath, risk),
 					Recommendation: ca.getKeyRecommendation(currentPath, value),
+					Reasons:     reasons,
 				}
 				
 				ca.addFinding(finding, result)
 			}
 
-			// Check against extension patterns
+			// Check against extension patterns, recording one finding with every match
+			var matches []patternMatch
 			for _, pattern := range ca.extensionPatterns {
 				if pattern.MatchString(currentPath) {
-					risk := ca.determinePatternRisk(currentPath, value)
-					finding := ConfigFinding{
-						File:        filePath,
-						Path:        currentPath,
-						Key:         key,
-						Value:       value,
-						Risk:        risk,
-						Category:    category,
-						Description: ca.getPatternDescription(currentPath, risk),
-						Recommendation: ca.getPatternRecommendation(currentPath, value),
-					}
-					
-					ca.addFinding(finding, result)
-					break // Only match first pattern to avoid duplicates
+					matches = append(matches, patternMatch{pattern: pattern.String(), risk: TelemetryRiskLow})
+				}
+			}
+			if len(matches) > 0 {
+				risk, _, reasons := explainRisk(append(matches, ca.patternRiskMatches(currentPath)...))
+				finding := ConfigFinding{
+					File:        filePath,
+					Path:        currentPath,
+					Key:         key,
+					Value:       value,
+					Risk:        risk,
+					Category:    category,
+					Description: ca.getPatternDescription(currentPath, risk),
+					Recommendation: ca.getPatternRecommendation(currentPath, value),
+					Reasons:     reasons,
 				}
+
+				ca.addFinding(finding, result)
 			}
 
 			// Recurse into nested objects
@@ -467,21 +462,28 @@ func (ca *ConfigAnalyzer) getKeyRecommendation(key string, value interface{}) st
 	}
 }
 
-// determinePatternRisk determines the risk level for a pattern match
-func (ca *ConfigAnalyzer) determinePatternRisk(path string, value interface{}) TelemetryRisk {
+// patternRiskTerms raise the risk of extension settings whose path contains them
+// above the low risk of a plain extension pattern match
+var patternRiskTerms = map[string]TelemetryRisk{
+	"telemetry":  TelemetryRiskHigh,
+	"analytics":  TelemetryRiskHigh,
+	"tracking":   TelemetryRiskMedium,
+	"usage":      TelemetryRiskMedium,
+	"update":     TelemetryRiskMedium,
+	"experiment": TelemetryRiskMedium,
+}
+
+// patternRiskMatches returns the patternRiskTerms in an extension setting path
+func (ca *ConfigAnalyzer) patternRiskMatches(path string) []patternMatch {
 	lowerPath := strings.ToLower(path)
-	
-	if strings.Contains(lowerPath, "telemetry") || strings.Contains(lowerPath, "analytics") {
-		return TelemetryRiskHigh
-	}
-	if strings.Contains(lowerPath, "tracking") || strings.Contains(lowerPath, "usage") {
-		return TelemetryRiskMedium
-	}
-	if strings.Contains(lowerPath, "update") || strings.Contains(lowerPath, "experiment") {
-		return TelemetryRiskMedium
+
+	var matches []patternMatch
+	for term, risk := range patternRiskTerms {
+		if strings.Contains(lowerPath, term) {
+			matches = append(matches, patternMatch{pattern: term, risk: risk})
+		}
 	}
-	
-	return TelemetryRiskLow
+	return matches
 }
 
 // getPatternDescription returns a description for a pattern match
@@ -532,6 +534,7 @@ func (ca *ConfigAnalyzer) analyzeWorkspaceStorageConfigs(workspaceStoragePath st
 func (ca *ConfigAnalyzer) analyzeExtensionStorageDir(dirPath, category string, result *ConfigAnalysisResult) {
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			result.Coverage.recordWalkError(err)
 			return nil // Continue despite errors
 		}
 
@@ -543,14 +546,17 @@ func (ca *ConfigAnalyzer) analyzeExtensionStorageDir(dirPath, category string, r
 		if strings.ToLower(filepath.Ext(path)) != ".json" {
 			return nil
 		}
+		result.Coverage.FilesSeen++
 
 		// Skip very large files
 		if info.Size() > 1024*1024 { // 1MB limit
+			result.Coverage.SkippedTooLarge++
 			return nil
 		}
 
 		config, err := ca.loadJSONConfig(path)
 		if err != nil {
+			result.Coverage.recordFileError(err)
 			return nil // Skip files we can't parse
 		}
 
@@ -572,12 +578,12 @@ func (ca *ConfigAnalyzer) calculateTotals(result *ConfigAnalysisResult) {
 		result.TelemetrySettings,
 	}
 
+	risks := NewRiskAggregator()
 	for _, findings := range allFindings {
 		result.TotalFindings += len(findings)
 		for _, finding := range findings {
-			if finding.Risk >= TelemetryRiskHigh {
-				result.HighRiskFindings++
-			}
+			risks.Add(finding.Risk)
 		}
 	}
+	result.HighRiskFindings = risks.CountAtLeast(TelemetryRiskHigh)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package scanner
 import (
 	"crypto/md5"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -232,6 +233,7 @@ func (ca *CorrelationAnalyzer) AnalyzeCrossExtensionData(globalStorages []Extens
 	sharedDataCorrelations := ca.analyzeSharedDataTypes(allStorageItems)
 	crossExtensionData = append(crossExtensionData, sharedDataCorrelations...)
 	
+	sortCrossExtensionData(crossExtensionData)
 	return crossExtensionData
 }
 
@@ -485,7 +487,9 @@ func (ca *CorrelationAnalyzer) hashValue(value interface{}) string {
 
 // generateCorrelationHash generates a hash for a correlation
 func (ca *CorrelationAnalyzer) generateCorrelationHash(dataType string, extensionIDs []string) string {
-	combined := dataType + ":" + strings.Join(extensionIDs, ",")
+	sortedIDs := append([]string(nil), extensionIDs...)
+	sort.Strings(sortedIDs)
+	combined := dataType + ":" + strings.Join(sortedIDs, ",")
 	hash := md5.Sum([]byte(combined))
 	return fmt.Sprintf("%x", hash)
 }
@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// CoverageStats counts the files an analyzer found and why some of them could not
+// be analyzed, so unreadable files cannot make a report look clean
+type CoverageStats struct {
+	FilesSeen        int `json:"files_seen"`
+	SkippedTooLarge  int `json:"skipped_too_large"`
+	PermissionErrors int `json:"permission_errors"`
+	ReadErrors       int `json:"read_errors"`
+	ParseFailures    int `json:"parse_failures"`
+	WalkErrors       int `json:"walk_errors"` // Files and directories the walk could not stat or list
+}
+
+// FilesAnalyzed returns the number of files that were analyzed
+func (c CoverageStats) FilesAnalyzed() int {
+	return max(c.FilesSeen-c.failed(), 0)
+}
+
+// Unreadable returns the number of files that could not be opened or read
+func (c CoverageStats) Unreadable() int {
+	return c.PermissionErrors + c.ReadErrors
+}
+
+// Percent returns the share of files analyzed from 0 to 100; 100 if none were found
+func (c CoverageStats) Percent() float64 {
+	if c.FilesSeen == 0 {
+		return 100
+	}
+	return float64(c.FilesAnalyzed()) * 100 / float64(c.FilesSeen)
+}
+
+// Add adds the counters of other
+func (c *CoverageStats) Add(other CoverageStats) {
+	c.FilesSeen += other.FilesSeen
+	c.SkippedTooLarge += other.SkippedTooLarge
+	c.PermissionErrors += other.PermissionErrors
+	c.ReadErrors += other.ReadErrors
+	c.ParseFailures += other.ParseFailures
+	c.WalkErrors += other.WalkErrors
+}
+
+// String describes the coverage, e.g.
+// "analyzed 4,312 of 4,401 files; 61 unreadable, 28 too large"
+func (c CoverageStats) String() string {
+	summary := fmt.Sprintf("analyzed %s of %s files", formatThousands(c.FilesAnalyzed()), formatThousands(c.FilesSeen))
+
+	var problems []string
+	for _, problem := range []struct {
+		count int
+		label string
+	}{
+		{c.Unreadable(), "unreadable"},
+		{c.SkippedTooLarge, "too large"},
+		{c.ParseFailures, "invalid JSON"},
+		{c.WalkErrors, "walk errors"},
+	} {
+		if problem.count > 0 {
+			problems = append(problems, formatThousands(problem.count)+" "+problem.label)
+		}
+	}
+	if len(problems) == 0 {
+		return summary
+	}
+	return summary + "; " + strings.Join(problems, ", ")
+}
+
+// failed returns the number of files that were found but not analyzed
+func (c CoverageStats) failed() int {
+	return c.SkippedTooLarge + c.PermissionErrors + c.ReadErrors + c.ParseFailures + c.WalkErrors
+}
+
+// recordWalkError counts a path the directory walk could not stat or list
+func (c *CoverageStats) recordWalkError(err error) {
+	c.FilesSeen++
+	if errors.Is(err, fs.ErrPermission) {
+		c.PermissionErrors++
+	} else {
+		c.WalkErrors++
+	}
+}
+
+// recordFileError counts a file that could not be read or parsed
+func (c *CoverageStats) recordFileError(err error) {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		c.PermissionErrors++
+	case isParseError(err):
+		c.ParseFailures++
+	default:
+		c.ReadErrors++
+	}
+}
+
+// isParseError reports whether err comes from decoding malformed or truncated JSON
+func isParseError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// formatThousands formats n with comma thousands separators, e.g. 4,401
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
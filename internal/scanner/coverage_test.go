@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCoverageStatsString(t *testing.T) {
+	coverage := CoverageStats{FilesSeen: 4401, SkippedTooLarge: 28}
+	for i := 0; i < 61; i++ {
+		coverage.recordFileError(fmt.Errorf("failed to read file: %w", fs.ErrPermission))
+	}
+
+	if got, want := coverage.String(), "analyzed 4,312 of 4,401 files; 61 unreadable, 28 too large"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if percent := coverage.Percent(); percent < 97.9 || percent > 98 {
+		t.Errorf("Expected about 98%% coverage, got %.2f", percent)
+	}
+	if percent := (CoverageStats{}).Percent(); percent != 100 {
+		t.Errorf("Expected full coverage without files, got %.2f", percent)
+	}
+}
+
+func TestAnalyzeExtensionStorageCoverage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"telemetryData.json": `{"machineId": "abc"}`,
+		"analyticsData.json": `{"sessionId": `, // Truncated
+		"usageStats.json":    `not json`,
+		"readme.txt":         "no telemetry",
+		"nested/state.json":  `{}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		mkdirAll(t, filepath.Dir(path))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	storage, err := NewStorageAnalyzer().analyzeExtensionStorage("test.extension", dir, "global")
+	if err != nil {
+		t.Fatalf("analyzeExtensionStorage failed: %v", err)
+	}
+
+	coverage := storage.Coverage
+	if coverage.FilesSeen != 5 || coverage.ParseFailures != 2 || coverage.FilesAnalyzed() != 3 {
+		t.Errorf("Expected 3 of 5 files analyzed with 2 parse failures, got %+v", coverage)
+	}
+	if len(storage.SkippedFiles) != 2 {
+		t.Errorf("Expected both invalid files to be listed as skipped, got %+v", storage.SkippedFiles)
+	}
+
+	stats := NewStorageAnalyzer().calculateStorageStatistics(&StorageAnalysisResult{
+		GlobalStorageAnalysis: GlobalStorageAnalysis{ExtensionStorages: []ExtensionStorage{*storage, *storage}},
+	})
+	if stats.Coverage.FilesSeen != 10 || stats.Coverage.ParseFailures != 4 {
+		t.Errorf("Expected coverage summed over extensions, got %+v", stats.Coverage)
+	}
+}
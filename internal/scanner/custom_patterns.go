@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+
+	"augment-telemetry-cleaner/internal/likepattern"
+)
+
+// customAugmentContext is the context pattern category of custom Augment patterns
+const customAugmentContext = "custom_augment"
+
+// customAugmentPattern is a custom LIKE pattern with the regular expression
+// matching the same values
+type customAugmentPattern struct {
+	like  string
+	value *regexp.Regexp
+}
+
+// AddCustomAugmentPatterns compiles user-defined SQL LIKE patterns of Augment
+// data, e.g. from the custom_augment_patterns config setting, into context
+// patterns. Code and bundles containing a matching value are reported with high
+// risk in the custom_augment category. Patterns made only of wildcards are
+// skipped, as they would match everything.
+func (apm *AdvancedPatternMatcher) AddCustomAugmentPatterns(patterns ...string) {
+	for _, pattern := range patterns {
+		if likepattern.Validate(pattern) != nil {
+			continue
+		}
+		apm.contextPatterns[customAugmentContext] = append(apm.contextPatterns[customAugmentContext], likepattern.CompileSearch(pattern))
+		apm.customPatterns = append(apm.customPatterns, customAugmentPattern{like: pattern, value: likepattern.Compile(pattern)})
+	}
+}
+
+// matchCustomAugmentPattern returns the first custom pattern one of the values
+// matches as a whole, as a LIKE condition on it would
+func (apm *AdvancedPatternMatcher) matchCustomAugmentPattern(values ...string) (string, bool) {
+	for _, pattern := range apm.customPatterns {
+		for _, value := range values {
+			if pattern.value.MatchString(value) {
+				return pattern.like, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SetCustomAugmentPatterns adds user-defined SQL LIKE patterns of Augment data:
+// storage keys and values matching one are reported with high risk, and the
+// code pattern tests include them
+func (sa *StorageAnalyzer) SetCustomAugmentPatterns(patterns []string) {
+	sa.valueMatcher.AddCustomAugmentPatterns(patterns...)
+}
+
+// customKeyRiskMatch returns the match of a custom pattern in a JSON key, its
+// path or its string value
+func (sa *StorageAnalyzer) customKeyRiskMatch(key, fullPath, value string) (patternMatch, bool) {
+	pattern, ok := sa.valueMatcher.matchCustomAugmentPattern(key, fullPath, value)
+	if !ok {
+		return patternMatch{}, false
+	}
+	return patternMatch{
+		pattern:     "custom: " + pattern,
+		risk:        TelemetryRiskHigh,
+		explanation: fmt.Sprintf("matches the custom Augment pattern %q", pattern),
+	}, true
+}
@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddCustomAugmentPatterns(t *testing.T) {
+	apm := NewAdvancedPatternMatcher()
+	apm.AddCustomAugmentPatterns("%acme_ai%", "%%")
+
+	matches := apm.AnalyzeCode(`const host = "https://ACME-AI.example/v1";`, "")
+	var found bool
+	for _, m := range matches {
+		if m.Category == customAugmentContext {
+			found = true
+			if m.Risk != TelemetryRiskHigh || !strings.EqualFold(m.Match, "acme-ai") {
+				t.Errorf("Expected a high risk match of acme-ai, got %+v", m)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the custom pattern to match, got %+v", matches)
+	}
+	if n := len(apm.contextPatterns[customAugmentContext]); n != 1 {
+		t.Errorf("Expected the wildcard-only pattern to be skipped, got %d patterns", n)
+	}
+}
+
+func TestCustomAugmentPatternsKeyRisk(t *testing.T) {
+	sa := NewStorageAnalyzer()
+	if risk := sa.AssessKeyRisk("acme.workspace", "x"); risk >= TelemetryRiskHigh {
+		t.Fatalf("Expected the key to be low risk without custom patterns, got %v", risk)
+	}
+
+	sa.SetCustomAugmentPatterns([]string{"acme.%"})
+	if risk := sa.AssessKeyRisk("acme.workspace", "x"); risk != TelemetryRiskHigh {
+		t.Errorf("Expected a key matching the custom pattern to be high risk, got %v", risk)
+	}
+	if risk := sa.AssessKeyRisk("my.acme.workspace", "x"); risk >= TelemetryRiskHigh {
+		t.Errorf("Expected the pattern to match the whole key, got %v", risk)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"augment-telemetry-cleaner/internal/sanitize"
 	"augment-telemetry-cleaner/internal/utils"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -18,6 +19,7 @@ type DatabaseAnalysisResult struct {
 	ConfigEntries       []DatabaseEntry `json:"config_entries"`
 	TotalEntries        int             `json:"total_entries"`
 	HighRiskEntries     int             `json:"high_risk_entries"`
+	RiskDistribution    map[TelemetryRisk]int `json:"risk_distribution,omitempty"`
 	DatabasePath        string          `json:"database_path"`
 	ScanDuration        time.Duration   `json:"scan_duration"`
 }
@@ -33,12 +35,15 @@ type DatabaseEntry struct {
 	Description     string        `json:"description"`
 	Size            int64         `json:"size"`
 	LastModified    time.Time     `json:"last_modified,omitempty"`
+	Reasons         []string      `json:"reasons,omitempty"` // Each matched pattern and how it contributed to Risk
 }
 
 // DatabaseAnalyzer handles analysis of VS Code's SQLite database
 type DatabaseAnalyzer struct {
 	telemetryKeyPatterns map[string]TelemetryRisk
+	telemetryKeyMatcher  *patternMatcher // Compiled telemetryKeyPatterns
 	extensionPatterns    map[string]TelemetryRisk
+	extensionMatcher     *patternMatcher // Compiled extensionPatterns
 	tableAnalyzers       map[string]func(*sql.DB, *DatabaseAnalysisResult) error
 }
 
@@ -73,6 +78,12 @@ func (da *DatabaseAnalyzer) initializeTelemetryKeyPatterns() {
 		"installid":                    TelemetryRiskHigh,
 		"hostname":                     TelemetryRiskHigh,
 		
+		// Cursor's own telemetry, separate from VS Code's
+		"cursor.telemetry":             TelemetryRiskHigh,
+		"cursor.sessionId":             TelemetryRiskHigh,
+		"cursor.userId":                TelemetryRiskHigh,
+		"cursor_session_id":            TelemetryRiskHigh,
+		
 		// Extension-related
 		"extension.telemetry":          TelemetryRiskHigh,
 		"extension.analytics":          TelemetryRiskHigh,
@@ -97,6 +108,7 @@ func (da *DatabaseAnalyzer) initializeTelemetryKeyPatterns() {
 		"survey":                       TelemetryRiskMedium,
 		"feedback":                     TelemetryRiskLow,
 	}
+	da.telemetryKeyMatcher = newPatternMatcher(da.telemetryKeyPatterns)
 }
 
 // initializeExtensionPatterns sets up patterns for extension-specific database entries
@@ -124,6 +136,7 @@ func (da *DatabaseAnalyzer) initializeExtensionPatterns() {
 		"extension.install.source":     TelemetryRiskMedium,
 		"extension.uninstall.reason":   TelemetryRiskMedium,
 	}
+	da.extensionMatcher = newPatternMatcher(da.extensionPatterns)
 }
 
 // initializeTableAnalyzers sets up specialized analyzers for different database tables
@@ -181,6 +194,11 @@ func (da *DatabaseAnalyzer) AnalyzeDatabase() (*DatabaseAnalysisResult, error) {
 		}
 	}
 
+	// Sort entries so output is stable between runs
+	for _, entries := range [][]DatabaseEntry{result.ExtensionEntries, result.TelemetryEntries, result.UsageEntries, result.ConfigEntries} {
+		sortDatabaseEntries(entries)
+	}
+
 	// Calculate totals and statistics
 	da.calculateTotals(result)
 	result.ScanDuration = time.Since(startTime)
@@ -385,52 +403,49 @@ func (da *DatabaseAnalyzer) analyzeKeyValue(table, key, value string) *DatabaseE
 	lowerKey := strings.ToLower(key)
 	lowerValue := strings.ToLower(value)
 	
-	// Check against telemetry key patterns
-	risk := TelemetryRiskNone
-	category := "Unknown"
-	description := ""
-
-	// Check telemetry patterns
-	for pattern, patternRisk := range da.telemetryKeyPatterns {
-		if strings.Contains(lowerKey, strings.ToLower(pattern)) ||
-		   strings.Contains(lowerValue, strings.ToLower(pattern)) {
-			if patternRisk > risk {
-				risk = patternRisk
-				category = "Telemetry"
-				description = fmt.Sprintf("Contains telemetry pattern: %s", pattern)
-			}
-		}
+	// Collect every telemetry and extension pattern in the key or value
+	matches := da.telemetryKeyMatcher.appendMatches(nil, lowerKey, lowerValue)
+	telemetryCount := len(matches)
+	matches = da.extensionMatcher.appendMatches(matches, lowerKey, lowerValue)
+	extensionPatterns := make(map[string]bool)
+	for _, match := range matches[telemetryCount:] {
+		extensionPatterns[match.pattern] = true
 	}
 
-	// Check extension patterns
-	for pattern, patternRisk := range da.extensionPatterns {
-		if strings.Contains(lowerKey, strings.ToLower(pattern)) ||
-		   strings.Contains(lowerValue, strings.ToLower(pattern)) {
-			if patternRisk > risk {
-				risk = patternRisk
-				category = "Extension"
-				description = fmt.Sprintf("Contains extension pattern: %s", pattern)
-			}
-		}
-	}
+	risk, matches, reasons := explainRisk(matches)
 
 	// Skip entries with no telemetry risk
 	if risk == TelemetryRiskNone {
 		return nil
 	}
 
+	// Telemetry patterns win ties with extension patterns
+	category := "Extension"
+	description := ""
+	for _, match := range matches {
+		if match.risk == risk && !extensionPatterns[match.pattern] {
+			category = "Telemetry"
+			description = fmt.Sprintf("Contains telemetry pattern: %s", match.pattern)
+			break
+		}
+	}
+	if category == "Extension" {
+		description = fmt.Sprintf("Contains extension pattern: %s", matches[0].pattern)
+	}
+
 	// Extract extension ID if possible
 	extensionID := da.extractExtensionID(key, value)
 
 	return &DatabaseEntry{
 		Table:       table,
 		Key:         key,
-		Value:       da.sanitizeValue(value),
+		Value:       sanitize.KeyStringN(key, value, 200),
 		ExtensionID: extensionID,
 		Risk:        risk,
 		Category:    category,
 		Description: description,
 		Size:        int64(len(value)),
+		Reasons:     reasons,
 	}
 }
 
@@ -459,25 +474,6 @@ func (da *DatabaseAnalyzer) extractExtensionID(key, value string) string {
 	return ""
 }
 
-// sanitizeValue sanitizes a database value for safe display
-func (da *DatabaseAnalyzer) sanitizeValue(value string) string {
-	// Truncate very long values
-	if len(value) > 200 {
-		return value[:200] + "... (truncated)"
-	}
-
-	// Mask potentially sensitive data
-	lowerValue := strings.ToLower(value)
-	if strings.Contains(lowerValue, "password") ||
-	   strings.Contains(lowerValue, "token") ||
-	   strings.Contains(lowerValue, "secret") ||
-	   strings.Contains(lowerValue, "key") {
-		return "[SENSITIVE DATA MASKED]"
-	}
-
-	return value
-}
-
 // categorizeEntry categorizes a database entry into the appropriate result category
 func (da *DatabaseAnalyzer) categorizeEntry(entry DatabaseEntry, result *DatabaseAnalysisResult) {
 	switch entry.Category {
@@ -509,14 +505,15 @@ func (da *DatabaseAnalyzer) calculateTotals(result *DatabaseAnalysisResult) {
 		result.ConfigEntries,
 	}
 
+	risks := NewRiskAggregator()
 	for _, entries := range allEntries {
 		result.TotalEntries += len(entries)
 		for _, entry := range entries {
-			if entry.Risk >= TelemetryRiskHigh {
-				result.HighRiskEntries++
-			}
+			risks.Add(entry.Risk)
 		}
 	}
+	result.HighRiskEntries = risks.CountAtLeast(TelemetryRiskHigh)
+	result.RiskDistribution = risks.Count()
 }
 
 // GetDatabaseSchema returns information about the database schema
@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// maxSimilarExtensionIDs is the number of suggestions ExtensionNotFoundError lists
+const maxSimilarExtensionIDs = 5
+
+// minSimilarPrefix is the shortest common prefix that makes an extension ID similar
+const minSimilarPrefix = 3
+
+// ExtensionNotFoundError is returned when scanning for an extension ID that has
+// no storage and is not installed
+type ExtensionNotFoundError struct {
+	ExtensionID string
+	Similar     []string
+}
+
+func (e *ExtensionNotFoundError) Error() string {
+	if len(e.Similar) == 0 {
+		return fmt.Sprintf("extension %s not found", e.ExtensionID)
+	}
+	return fmt.Sprintf("extension %s not found; similar IDs: %s", e.ExtensionID, strings.Join(e.Similar, ", "))
+}
+
+// matchesExtension reports whether extensionID passes the filter. Extension IDs
+// are case-insensitive; an empty filter matches every extension.
+func matchesExtension(filter, extensionID string) bool {
+	return filter == "" || strings.EqualFold(filter, extensionID)
+}
+
+// installedExtensionID returns the extension ID of an extensions directory entry
+// such as "ms-python.python-2024.1.0", or "" if dirName has no version suffix
+func installedExtensionID(dirName string) string {
+	for i := strings.LastIndex(dirName, "-"); i > 0; i = strings.LastIndex(dirName[:i], "-") {
+		if version := dirName[i+1:]; version != "" && version[0] >= '0' && version[0] <= '9' {
+			return dirName[:i]
+		}
+	}
+	return ""
+}
+
+// detectExtensionIDs returns the lowercase IDs of every extension with global or
+// workspace storage or an installed bundle, sorted
+func detectExtensionIDs(paths *utils.VSCodePaths) []string {
+	idSet := make(map[string]bool)
+	addDirs := func(dir string, toID func(string) string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if id := toID(entry.Name()); entry.IsDir() && id != "" {
+				idSet[strings.ToLower(id)] = true
+			}
+		}
+	}
+	sameName := func(name string) string { return name }
+
+	if globalStoragePaths, err := paths.GlobalStoragePaths(); err == nil {
+		for _, globalStoragePath := range globalStoragePaths {
+			addDirs(globalStoragePath, sameName)
+		}
+	}
+	if workspaceStoragePaths, err := paths.WorkspaceStoragePaths(); err == nil {
+		for _, workspaceStoragePath := range workspaceStoragePaths {
+			entries, _ := os.ReadDir(workspaceStoragePath)
+			for _, entry := range entries {
+				if entry.IsDir() {
+					addDirs(filepath.Join(workspaceStoragePath, entry.Name()), sameName)
+				}
+			}
+		}
+	}
+	if extensionsPath, err := paths.ExtensionsPath(); err == nil {
+		addDirs(extensionsPath, installedExtensionID)
+	}
+
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkExtensionFilter returns an ExtensionNotFoundError if filter is set and no
+// detected extension has that ID
+func checkExtensionFilter(paths *utils.VSCodePaths, filter string) error {
+	if filter == "" {
+		return nil
+	}
+
+	ids := detectExtensionIDs(paths)
+	for _, id := range ids {
+		if matchesExtension(filter, id) {
+			return nil
+		}
+	}
+	return &ExtensionNotFoundError{ExtensionID: filter, Similar: similarExtensionIDs(filter, ids)}
+}
+
+// similarExtensionIDs returns the IDs sharing the longest common prefix with
+// extensionID, so "ms-python" suggests every ms-python extension and a typo like
+// "ms-python.pyton" suggests "ms-python.python"
+func similarExtensionIDs(extensionID string, ids []string) []string {
+	target := strings.ToLower(extensionID)
+
+	var similar []string
+	longest := minSimilarPrefix
+	for _, id := range ids {
+		prefix := commonPrefixLength(target, id)
+		if prefix > longest {
+			longest = prefix
+			similar = similar[:0]
+		}
+		if prefix == longest {
+			similar = append(similar, id)
+		}
+	}
+
+	if len(similar) > maxSimilarExtensionIDs {
+		similar = similar[:maxSimilarExtensionIDs]
+	}
+	return similar
+}
+
+// commonPrefixLength returns the number of leading bytes a and b share
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
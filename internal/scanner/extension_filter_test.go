@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestAnalyzeStorageExtensionFilter(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	userDir := filepath.Join(home, ".config", "Code", "User")
+
+	for _, dir := range []string{
+		filepath.Join(userDir, "globalStorage", "ms-python.python"),
+		filepath.Join(userDir, "globalStorage", "ms-python.vscode-pylance"),
+		filepath.Join(userDir, "workspaceStorage", "hash1", "ms-python.python"),
+		filepath.Join(userDir, "workspaceStorage", "hash1", "golang.go"),
+		filepath.Join(userDir, "workspaceStorage", "hash2", "golang.go"),
+	} {
+		mkdirAll(t, dir)
+		if err := os.WriteFile(filepath.Join(dir, "telemetry.json"), []byte(`{"machineId": "abc"}`), 0644); err != nil {
+			t.Fatalf("Failed to write storage: %v", err)
+		}
+	}
+
+	// Extension IDs are case-insensitive
+	result, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("MS-Python.Python")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	global := result.GlobalStorageAnalysis.ExtensionStorages
+	if len(global) != 1 || global[0].ExtensionID != "ms-python.python" {
+		t.Errorf("Expected only ms-python.python global storage, got %+v", global)
+	}
+	workspaces := result.WorkspaceStorageAnalysis.WorkspaceStorages
+	if len(workspaces) != 1 || workspaces[0].WorkspaceHash != "hash1" || len(workspaces[0].ExtensionStorages) != 1 {
+		t.Errorf("Expected only the ms-python.python storage of hash1, got %+v", workspaces)
+	}
+
+	settings, err := NewExtensionSettingsScannerWithResolver(resolver).ScanExtensionSettings("golang.go")
+	if err != nil {
+		t.Fatalf("ScanExtensionSettings failed: %v", err)
+	}
+	if len(settings.GlobalStorageItems) != 0 || len(settings.WorkspaceStorageItems) != 2 {
+		t.Errorf("Expected only the two golang.go workspace items, got %+v and %+v", settings.GlobalStorageItems, settings.WorkspaceStorageItems)
+	}
+	for _, item := range settings.WorkspaceStorageItems {
+		if item.ExtensionID != "golang.go" {
+			t.Errorf("Expected golang.go items only, got %s", item.ExtensionID)
+		}
+	}
+
+	// Installed extensions without storage are known and not an error
+	mkdirAll(t, filepath.Join(home, ".vscode", "extensions", "ms-vscode.cpptools-1.20.5-linux-x64"))
+	if _, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("ms-vscode.cpptools"); err != nil {
+		t.Errorf("Expected the installed extension to be found, got %v", err)
+	}
+
+	_, err = NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("ms-python")
+	var notFound *ExtensionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected an ExtensionNotFoundError, got %v", err)
+	}
+	if want := []string{"ms-python.python", "ms-python.vscode-pylance"}; !reflect.DeepEqual(notFound.Similar, want) {
+		t.Errorf("Expected similar IDs %v, got %v", want, notFound.Similar)
+	}
+	if err.Error() != "extension ms-python not found; similar IDs: ms-python.python, ms-python.vscode-pylance" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if _, err := NewExtensionSettingsScannerWithResolver(resolver).ScanExtensionSettings("ms-python"); !errors.As(err, &notFound) {
+		t.Errorf("Expected the settings scanner to reject unknown IDs, got %v", err)
+	}
+}
+
+func TestSimilarExtensionIDs(t *testing.T) {
+	ids := []string{"golang.go", "ms-python.python", "ms-python.vscode-pylance", "ms-vscode.cpptools"}
+
+	tests := []struct {
+		extensionID string
+		want        []string
+	}{
+		{"ms-python.pyton", []string{"ms-python.python"}},
+		{"ms-vscode", []string{"ms-vscode.cpptools"}},
+		{"ms-", []string{"ms-python.python", "ms-python.vscode-pylance", "ms-vscode.cpptools"}},
+		{"rust-lang.rust-analyzer", nil},
+	}
+	for _, test := range tests {
+		if got := similarExtensionIDs(test.extensionID, ids); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("similarExtensionIDs(%q) = %v, want %v", test.extensionID, got, test.want)
+		}
+	}
+
+	for dirName, want := range map[string]string{
+		"ms-python.python-2024.1.0":              "ms-python.python",
+		"ms-vscode.cpptools-1.20.5-linux-x64":    "ms-vscode.cpptools",
+		"ms-vscode.vscode-typescript-next-5.5.0": "ms-vscode.vscode-typescript-next",
+		".obsolete":                              "",
+	} {
+		if got := installedExtensionID(dirName); got != want {
+			t.Errorf("installedExtensionID(%q) = %q, want %q", dirName, got, want)
+		}
+	}
+}
@@ -96,11 +96,18 @@ type ExtensionScanResult struct {
 type ExtensionScanner struct {
 	telemetryPatterns []string
 	riskPatterns      map[TelemetryRisk][]string
+	resolver          utils.PathResolver
 }
 
 // NewExtensionScanner creates a new extension scanner
 func NewExtensionScanner() *ExtensionScanner {
-	scanner := &ExtensionScanner{}
+	return NewExtensionScannerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewExtensionScannerWithResolver creates an extension scanner that derives the
+// extension directories from resolver
+func NewExtensionScannerWithResolver(resolver utils.PathResolver) *ExtensionScanner {
+	scanner := &ExtensionScanner{resolver: resolver}
 	scanner.initializeTelemetryPatterns()
 	return scanner
 }
@@ -180,6 +187,9 @@ func (es *ExtensionScanner) ScanExtensions() (*ExtensionScanResult, error) {
 		result.Extensions = append(result.Extensions, extensions...)
 	}
 
+	// Sort extensions so output is stable between runs
+	sortExtensionInfos(result.Extensions)
+
 	// Calculate statistics
 	result.TotalExtensions = len(result.Extensions)
 	for _, ext := range result.Extensions {
@@ -200,47 +210,34 @@ func (es *ExtensionScanner) ScanExtensions() (*ExtensionScanResult, error) {
 func (es *ExtensionScanner) getExtensionDirectories() ([]string, error) {
 	var directories []string
 
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	// User extensions of every editor build (stable, Insiders, VSCodium, Cursor), or
+	// only of the one chosen with --editor
+	for _, editor := range utils.TargetEditors(es.resolver) {
+		extensionsPath, err := utils.NewEditorPaths(es.resolver, editor).ExtensionsPath()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		directories = append(directories, extensionsPath)
 	}
 
 	switch runtime.GOOS {
 	case "windows":
-		// User extensions
-		directories = append(directories, filepath.Join(homeDir, ".vscode", "extensions"))
-		
 		// System extensions (if accessible)
 		programFiles := os.Getenv("PROGRAMFILES")
 		if programFiles != "" {
 			directories = append(directories, 
 				filepath.Join(programFiles, "Microsoft VS Code", "resources", "app", "extensions"))
 		}
-		
-		// Insiders version
-		directories = append(directories, filepath.Join(homeDir, ".vscode-insiders", "extensions"))
 
 	case "darwin":
-		// User extensions
-		directories = append(directories, filepath.Join(homeDir, ".vscode", "extensions"))
-		
 		// System extensions
 		directories = append(directories, "/Applications/Visual Studio Code.app/Contents/Resources/app/extensions")
-		
-		// Insiders version
-		directories = append(directories, filepath.Join(homeDir, ".vscode-insiders", "extensions"))
 
 	default: // Linux and other Unix-like systems
-		// User extensions
-		directories = append(directories, filepath.Join(homeDir, ".vscode", "extensions"))
-		
 		// System extensions (common locations)
 		directories = append(directories, 
 			"/usr/share/code/resources/app/extensions",
 			"/opt/visual-studio-code/resources/app/extensions")
-		
-		// Insiders version
-		directories = append(directories, filepath.Join(homeDir, ".vscode-insiders", "extensions"))
 	}
 
 	return directories, nil
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"augment-telemetry-cleaner/internal/sanitize"
 	"augment-telemetry-cleaner/internal/utils"
 )
 
@@ -18,6 +19,8 @@ type ExtensionSettingsResult struct {
 	WorkspaceStorageItems []StorageItem    `json:"workspace_storage_items"`
 	TotalSettings       int                `json:"total_settings"`
 	TelemetrySettings   int                `json:"telemetry_settings"`
+	SkippedFiles        []SkippedFile      `json:"skipped_files,omitempty"`
+	Coverage            CoverageStats      `json:"coverage"`
 	ScanDuration        time.Duration      `json:"scan_duration"`
 }
 
@@ -50,16 +53,32 @@ type StorageItem struct {
 type ExtensionSettingsScanner struct {
 	telemetryKeyPatterns map[string]TelemetryRisk
 	storageKeyPatterns   map[string]TelemetryRisk
+	telemetryKeyMatcher  *patternMatcher // Compiled telemetryKeyPatterns
+	storageKeyMatcher    *patternMatcher // Compiled storageKeyPatterns
+	paths                *utils.VSCodePaths
+	guessWorkspaces      bool
 }
 
 // NewExtensionSettingsScanner creates a new extension settings scanner
 func NewExtensionSettingsScanner() *ExtensionSettingsScanner {
-	scanner := &ExtensionSettingsScanner{}
+	return NewExtensionSettingsScannerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewExtensionSettingsScannerWithResolver creates an extension settings scanner
+// that derives the settings and storage locations from resolver
+func NewExtensionSettingsScannerWithResolver(resolver utils.PathResolver) *ExtensionSettingsScanner {
+	scanner := &ExtensionSettingsScanner{paths: utils.NewVSCodePaths(resolver)}
 	scanner.initializeTelemetryKeyPatterns()
 	scanner.initializeStorageKeyPatterns()
 	return scanner
 }
 
+// SetGuessWorkspaceFolders makes workspace settings discovery fall back to searching
+// common project directories when VS Code has no recently opened folders with settings
+func (ess *ExtensionSettingsScanner) SetGuessWorkspaceFolders(guess bool) {
+	ess.guessWorkspaces = guess
+}
+
 // initializeTelemetryKeyPatterns sets up patterns for telemetry-related setting keys
 func (ess *ExtensionSettingsScanner) initializeTelemetryKeyPatterns() {
 	ess.telemetryKeyPatterns = map[string]TelemetryRisk{
@@ -93,6 +112,7 @@ func (ess *ExtensionSettingsScanner) initializeTelemetryKeyPatterns() {
 		"eslint.autoFixOnSave":         TelemetryRiskLow,
 		"prettier.requireConfig":       TelemetryRiskLow,
 	}
+	ess.telemetryKeyMatcher = newPatternMatcher(ess.telemetryKeyPatterns)
 }
 
 // initializeStorageKeyPatterns sets up patterns for telemetry-related storage keys
@@ -127,12 +147,46 @@ func (ess *ExtensionSettingsScanner) initializeStorageKeyPatterns() {
 		"surveys":                      TelemetryRiskMedium,
 		"feedback":                     TelemetryRiskLow,
 	}
-}
-
-// ScanExtensionSettings performs comprehensive scanning of extension settings and storage
-func (ess *ExtensionSettingsScanner) ScanExtensionSettings() (*ExtensionSettingsResult, error) {
+	ess.storageKeyMatcher = newPatternMatcher(ess.storageKeyPatterns)
+}
+
+// settingsFileMatcher grades storage files by the telemetry terms in their name or path
+var settingsFileMatcher = newPatternMatcher(map[string]TelemetryRisk{
+	"telemetry":   TelemetryRiskHigh,
+	"analytics":   TelemetryRiskHigh,
+	"tracking":    TelemetryRiskHigh,
+	"usage":       TelemetryRiskHigh,
+	"metrics":     TelemetryRiskHigh,
+	"crash":       TelemetryRiskMedium,
+	"error":       TelemetryRiskMedium,
+	"log":         TelemetryRiskMedium,
+	"diagnostic":  TelemetryRiskMedium,
+	"performance": TelemetryRiskMedium,
+	"cache":       TelemetryRiskLow,
+	"temp":        TelemetryRiskLow,
+	"config":      TelemetryRiskLow,
+	"settings":    TelemetryRiskLow,
+	"preferences": TelemetryRiskLow,
+})
+
+// settingsValueMatcher flags storage values that mention telemetry
+var settingsValueMatcher = newPatternMatcher(map[string]TelemetryRisk{
+	"telemetry": TelemetryRiskMedium,
+	"analytics": TelemetryRiskMedium,
+	"tracking":  TelemetryRiskMedium,
+})
+
+// ScanExtensionSettings performs comprehensive scanning of extension settings and
+// storage. A non-empty extensionID limits the scan to that extension's settings and
+// storage without walking the storage of other extensions; an extensionID that no
+// detected extension has returns an ExtensionNotFoundError listing similar IDs.
+func (ess *ExtensionSettingsScanner) ScanExtensionSettings(extensionID string) (*ExtensionSettingsResult, error) {
 	startTime := time.Now()
 	
+	if err := checkExtensionFilter(ess.paths, extensionID); err != nil {
+		return nil, err
+	}
+	
 	result := &ExtensionSettingsResult{
 		ExtensionSettings:     make([]ExtensionSetting, 0),
 		GlobalStorageItems:    make([]StorageItem, 0),
@@ -149,16 +203,26 @@ func (ess *ExtensionSettingsScanner) ScanExtensionSettings() (*ExtensionSettings
 		// Continue even if workspace settings scan fails
 	}
 
+	// Keep only the settings namespaced by the extension
+	if extensionID != "" {
+		result.ExtensionSettings = append(make([]ExtensionSetting, 0), settingsForExtension(extensionID, result.ExtensionSettings)...)
+	}
+
 	// Scan global storage
-	if err := ess.scanGlobalStorage(result); err != nil {
+	if err := ess.scanGlobalStorage(result, extensionID); err != nil {
 		// Continue even if global storage scan fails
 	}
 
 	// Scan workspace storage
-	if err := ess.scanWorkspaceStorage(result); err != nil {
+	if err := ess.scanWorkspaceStorage(result, extensionID); err != nil {
 		// Continue even if workspace storage scan fails
 	}
 
+	// Sort results so output is stable between runs
+	sortExtensionSettings(result.ExtensionSettings)
+	sortStorageItems(result.GlobalStorageItems)
+	sortStorageItems(result.WorkspaceStorageItems)
+
 	// Calculate totals
 	ess.calculateTotals(result)
 	result.ScanDuration = time.Since(startTime)
@@ -166,19 +230,40 @@ func (ess *ExtensionSettingsScanner) ScanExtensionSettings() (*ExtensionSettings
 	return result, nil
 }
 
+// scanSettings scans only the user and workspace settings for extension configurations
+func (ess *ExtensionSettingsScanner) scanSettings() []ExtensionSetting {
+	result := &ExtensionSettingsResult{ExtensionSettings: make([]ExtensionSetting, 0)}
+	ess.scanUserSettings(result)
+	ess.scanWorkspaceSettings(result)
+	sortExtensionSettings(result.ExtensionSettings)
+	return result.ExtensionSettings
+}
+
 // scanUserSettings scans VS Code user settings for extension configurations
 func (ess *ExtensionSettingsScanner) scanUserSettings(result *ExtensionSettingsResult) error {
-	settingsPath, err := ess.getVSCodeSettingsPath()
+	settingsPaths, err := ess.paths.UserSettingsPaths()
 	if err != nil {
 		return err
 	}
 
+	for _, settingsPath := range settingsPaths {
+		if err := ess.scanUserSettingsFile(settingsPath, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanUserSettingsFile scans a single user settings.json for extension configurations
+func (ess *ExtensionSettingsScanner) scanUserSettingsFile(settingsPath string, result *ExtensionSettingsResult) error {
 	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
 		return nil // Settings file doesn't exist
 	}
 
+	result.Coverage.FilesSeen++
 	settings, err := ess.loadJSONConfig(settingsPath)
 	if err != nil {
+		result.Coverage.recordFileError(err)
 		return err
 	}
 
@@ -201,8 +286,10 @@ func (ess *ExtensionSettingsScanner) scanWorkspaceSettings(result *ExtensionSett
 			continue
 		}
 
+		result.Coverage.FilesSeen++
 		settings, err := ess.loadJSONConfig(workspacePath)
 		if err != nil {
+			result.Coverage.recordFileError(err)
 			continue
 		}
 
@@ -218,13 +305,23 @@ func (ess *ExtensionSettingsScanner) scanWorkspaceSettings(result *ExtensionSett
 	return nil
 }
 
-// scanGlobalStorage scans extension global storage directories
-func (ess *ExtensionSettingsScanner) scanGlobalStorage(result *ExtensionSettingsResult) error {
-	globalStoragePath, err := ess.getGlobalStoragePath()
+// scanGlobalStorage scans extension global storage directories, only of extensionID if set
+func (ess *ExtensionSettingsScanner) scanGlobalStorage(result *ExtensionSettingsResult, extensionID string) error {
+	globalStoragePaths, err := ess.paths.GlobalStoragePaths()
 	if err != nil {
 		return err
 	}
 
+	for _, globalStoragePath := range globalStoragePaths {
+		if err := ess.scanGlobalStorageDir(globalStoragePath, result, extensionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanGlobalStorageDir scans the extension directories of a single globalStorage directory
+func (ess *ExtensionSettingsScanner) scanGlobalStorageDir(globalStoragePath string, result *ExtensionSettingsResult, extensionID string) error {
 	if _, err := os.Stat(globalStoragePath); os.IsNotExist(err) {
 		return nil // Global storage doesn't exist
 	}
@@ -235,26 +332,35 @@ func (ess *ExtensionSettingsScanner) scanGlobalStorage(result *ExtensionSettings
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || !matchesExtension(extensionID, entry.Name()) {
 			continue
 		}
 
-		extensionID := entry.Name()
-		extensionStoragePath := filepath.Join(globalStoragePath, extensionID)
+		extensionStoragePath := filepath.Join(globalStoragePath, entry.Name())
 		
-		ess.scanExtensionStorageDirectory(extensionID, extensionStoragePath, "global", result)
+		ess.scanExtensionStorageDirectory(entry.Name(), extensionStoragePath, "global", result)
 	}
 
 	return nil
 }
 
-// scanWorkspaceStorage scans extension workspace storage directories
-func (ess *ExtensionSettingsScanner) scanWorkspaceStorage(result *ExtensionSettingsResult) error {
-	workspaceStoragePath, err := utils.GetWorkspaceStoragePath()
+// scanWorkspaceStorage scans extension workspace storage directories, only of extensionID if set
+func (ess *ExtensionSettingsScanner) scanWorkspaceStorage(result *ExtensionSettingsResult, extensionID string) error {
+	workspaceStoragePaths, err := ess.paths.WorkspaceStoragePaths()
 	if err != nil {
 		return err
 	}
 
+	for _, workspaceStoragePath := range workspaceStoragePaths {
+		if err := ess.scanWorkspaceStorageDir(workspaceStoragePath, result, extensionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanWorkspaceStorageDir scans the workspace hash directories of a single workspaceStorage directory
+func (ess *ExtensionSettingsScanner) scanWorkspaceStorageDir(workspaceStoragePath string, result *ExtensionSettingsResult, extensionID string) error {
 	if _, err := os.Stat(workspaceStoragePath); os.IsNotExist(err) {
 		return nil // Workspace storage doesn't exist
 	}
@@ -279,14 +385,13 @@ func (ess *ExtensionSettingsScanner) scanWorkspaceStorage(result *ExtensionSetti
 		}
 
 		for _, extensionEntry := range extensionEntries {
-			if !extensionEntry.IsDir() {
+			if !extensionEntry.IsDir() || !matchesExtension(extensionID, extensionEntry.Name()) {
 				continue
 			}
 
-			extensionID := extensionEntry.Name()
-			extensionStoragePath := filepath.Join(workspaceHashPath, extensionID)
+			extensionStoragePath := filepath.Join(workspaceHashPath, extensionEntry.Name())
 			
-			ess.scanExtensionStorageDirectory(extensionID, extensionStoragePath, "workspace", result)
+			ess.scanExtensionStorageDirectory(extensionEntry.Name(), extensionStoragePath, "workspace", result)
 		}
 	}
 
@@ -297,15 +402,19 @@ func (ess *ExtensionSettingsScanner) scanWorkspaceStorage(result *ExtensionSetti
 func (ess *ExtensionSettingsScanner) scanExtensionStorageDirectory(extensionID, dirPath, storageType string, result *ExtensionSettingsResult) {
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			result.Coverage.recordWalkError(err)
 			return nil // Continue despite errors
 		}
 
 		if info.IsDir() {
 			return nil
 		}
+		result.Coverage.FilesSeen++
 
 		// Skip very large files
-		if info.Size() > 10*1024*1024 { // 10MB limit
+		if info.Size() > MaxJSONParseSize {
+			result.SkippedFiles = append(result.SkippedFiles, SkippedFile{Path: path, Size: info.Size(), Reason: SkipReasonParseLimit})
+			result.Coverage.SkippedTooLarge++
 			return nil
 		}
 
@@ -358,6 +467,8 @@ func (ess *ExtensionSettingsScanner) analyzeStorageFile(extensionID, filePath, s
 func (ess *ExtensionSettingsScanner) analyzeJSONStorageFile(extensionID, filePath, storageType string, info os.FileInfo, baseRisk TelemetryRisk, result *ExtensionSettingsResult) {
 	data, err := ess.loadJSONConfig(filePath)
 	if err != nil {
+		result.Coverage.recordFileError(err)
+
 		// If we can't parse as JSON, treat as regular file
 		storageItem := StorageItem{
 			ExtensionID:  extensionID,
@@ -409,7 +520,7 @@ func (ess *ExtensionSettingsScanner) analyzeJSONRecursive(obj interface{}, exten
 					ExtensionID:  extensionID,
 					StorageType:  storageType,
 					Key:          currentPath,
-					Value:        ess.sanitizeValue(value),
+					Value:        sanitize.KeyValue(key, value),
 					Size:         size,
 					Risk:         risk,
 					Description:  ess.getKeyDescription(key, risk),
@@ -438,36 +549,15 @@ func (ess *ExtensionSettingsScanner) analyzeJSONRecursive(obj interface{}, exten
 
 // Helper methods
 
-// getVSCodeSettingsPath returns the path to VS Code user settings
-func (ess *ExtensionSettingsScanner) getVSCodeSettingsPath() (string, error) {
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	switch utils.GetOS() {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "settings.json"), nil
-
-	case "darwin":
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "settings.json"), nil
-
-	default: // Linux and other Unix-like systems
-		return filepath.Join(homeDir, ".config", "Code", "User", "settings.json"), nil
+// getWorkspaceSettingsPaths returns the settings of recently opened workspaces,
+// or of common project directories if guessing is enabled and none were found
+func (ess *ExtensionSettingsScanner) getWorkspaceSettingsPaths() []string {
+	paths := recentWorkspaceSettingsPaths(ess.paths)
+	if len(paths) > 0 || !ess.guessWorkspaces {
+		return paths
 	}
-}
 
-// getWorkspaceSettingsPaths returns possible workspace settings paths
-func (ess *ExtensionSettingsScanner) getWorkspaceSettingsPaths() []string {
-	// This is a simplified implementation - in practice, you might want to
-	// scan more locations or use VS Code's workspace detection
-	var paths []string
-	
-	homeDir, err := utils.GetHomeDir()
+	homeDir, err := ess.paths.HomeDir()
 	if err != nil {
 		return paths
 	}
@@ -493,29 +583,6 @@ func (ess *ExtensionSettingsScanner) getWorkspaceSettingsPaths() []string {
 	return paths
 }
 
-// getGlobalStoragePath returns the global storage path
-func (ess *ExtensionSettingsScanner) getGlobalStoragePath() (string, error) {
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	switch utils.GetOS() {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "globalStorage"), nil
-
-	case "darwin":
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage"), nil
-
-	default: // Linux and other Unix-like systems
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage"), nil
-	}
-}
-
 // loadJSONConfig loads and parses a JSON configuration file
 func (ess *ExtensionSettingsScanner) loadJSONConfig(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
@@ -542,7 +609,7 @@ func (ess *ExtensionSettingsScanner) extractExtensionSettings(settings map[strin
 				setting := ExtensionSetting{
 					ExtensionID:  ess.extractExtensionID(key),
 					SettingKey:   key,
-					SettingValue: ess.sanitizeValue(value),
+					SettingValue: sanitize.KeyValue(key, value),
 					Source:       source,
 					Risk:         risk,
 					Category:     ess.getSettingCategory(key),
@@ -591,39 +658,20 @@ func (ess *ExtensionSettingsScanner) extractExtensionID(key string) string {
 
 // assessSettingRisk assesses the telemetry risk of a setting
 func (ess *ExtensionSettingsScanner) assessSettingRisk(key string, value interface{}) TelemetryRisk {
-	lowerKey := strings.ToLower(key)
-	
-	// Check against known telemetry patterns
-	for pattern, risk := range ess.telemetryKeyPatterns {
-		if strings.Contains(lowerKey, strings.ToLower(pattern)) {
-			return risk
-		}
-	}
-
-	return TelemetryRiskNone
+	// The highest risk of the known telemetry patterns in the key
+	return ess.telemetryKeyMatcher.highestRisk(strings.ToLower(key))
 }
 
 // assessKeyRisk assesses the telemetry risk of a storage key
 func (ess *ExtensionSettingsScanner) assessKeyRisk(key, fullPath string, value interface{}) TelemetryRisk {
-	lowerKey := strings.ToLower(key)
-	lowerPath := strings.ToLower(fullPath)
-	
-	// Check against storage key patterns
-	for pattern, risk := range ess.storageKeyPatterns {
-		if strings.Contains(lowerKey, strings.ToLower(pattern)) || 
-		   strings.Contains(lowerPath, strings.ToLower(pattern)) {
-			return risk
-		}
+	// Check against storage key patterns, the highest risk wins
+	if risk := ess.storageKeyMatcher.highestRisk(strings.ToLower(key), strings.ToLower(fullPath)); risk != TelemetryRiskNone {
+		return risk
 	}
 
 	// Check value content for additional patterns
 	if valueStr, ok := value.(string); ok {
-		lowerValue := strings.ToLower(valueStr)
-		if strings.Contains(lowerValue, "telemetry") || 
-		   strings.Contains(lowerValue, "analytics") ||
-		   strings.Contains(lowerValue, "tracking") {
-			return TelemetryRiskMedium
-		}
+		return settingsValueMatcher.highestRisk(strings.ToLower(valueStr))
 	}
 
 	return TelemetryRiskNone
@@ -631,55 +679,7 @@ func (ess *ExtensionSettingsScanner) assessKeyRisk(key, fullPath string, value i
 
 // assessFileRisk assesses the telemetry risk of a file based on its name and path
 func (ess *ExtensionSettingsScanner) assessFileRisk(fileName, filePath string) TelemetryRisk {
-	lowerName := strings.ToLower(fileName)
-	lowerPath := strings.ToLower(filePath)
-	
-	// High risk file patterns
-	highRiskPatterns := []string{"telemetry", "analytics", "tracking", "usage", "metrics"}
-	for _, pattern := range highRiskPatterns {
-		if strings.Contains(lowerName, pattern) || strings.Contains(lowerPath, pattern) {
-			return TelemetryRiskHigh
-		}
-	}
-
-	// Medium risk file patterns
-	mediumRiskPatterns := []string{"crash", "error", "log", "diagnostic", "performance"}
-	for _, pattern := range mediumRiskPatterns {
-		if strings.Contains(lowerName, pattern) || strings.Contains(lowerPath, pattern) {
-			return TelemetryRiskMedium
-		}
-	}
-
-	// Low risk file patterns
-	lowRiskPatterns := []string{"cache", "temp", "config", "settings", "preferences"}
-	for _, pattern := range lowRiskPatterns {
-		if strings.Contains(lowerName, pattern) || strings.Contains(lowerPath, pattern) {
-			return TelemetryRiskLow
-		}
-	}
-
-	return TelemetryRiskNone
-}
-
-// sanitizeValue sanitizes a value for safe display (removes sensitive data)
-func (ess *ExtensionSettingsScanner) sanitizeValue(value interface{}) interface{} {
-	if str, ok := value.(string); ok {
-		// Sanitize potential sensitive strings
-		if len(str) > 100 {
-			return str[:100] + "... (truncated)"
-		}
-		
-		// Check for potential sensitive patterns and mask them
-		lowerStr := strings.ToLower(str)
-		if strings.Contains(lowerStr, "key") || 
-		   strings.Contains(lowerStr, "token") || 
-		   strings.Contains(lowerStr, "secret") ||
-		   strings.Contains(lowerStr, "password") {
-			return "[SENSITIVE DATA MASKED]"
-		}
-	}
-	
-	return value
+	return settingsFileMatcher.highestRisk(strings.ToLower(fileName), strings.ToLower(filePath))
 }
 
 // estimateValueSize estimates the size of a JSON value in bytes
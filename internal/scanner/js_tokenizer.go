@@ -0,0 +1,312 @@
+package scanner
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsTokenKind is the kind of a JavaScript token
+type jsTokenKind int
+
+const (
+	jsIdent jsTokenKind = iota
+	jsString
+	jsNumber
+	jsRegExp
+	jsPunct
+)
+
+// jsToken is a token of JavaScript source. String tokens hold the decoded value
+// of the literal, so "\x74\x65\x6c" reads as "tel".
+type jsToken struct {
+	kind   jsTokenKind
+	value  string
+	offset int // Byte offset in the source
+}
+
+// regExpKeywords are the keywords after which a / starts a regular expression
+// literal rather than a division
+var regExpKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// tokenizeJS splits JavaScript source into identifiers, decoded string literals,
+// numbers, regular expression literals and punctuation, skipping comments. Line
+// breaks only separate tokens, so minified bundles tokenize like formatted code.
+// The text parts of template literals become string tokens, with a "${" token
+// before each embedded expression. Malformed source is tokenized as far as it
+// goes rather than rejected.
+func tokenizeJS(src []byte) []jsToken {
+	var tokens []jsToken
+	var templates []int // Open braces inside the ${} of each template literal being read
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			value, end := readJSString(src, i+1, c)
+			tokens = append(tokens, jsToken{kind: jsString, value: value, offset: i})
+			i = end
+
+		case c == '`' || (c == '}' && len(templates) > 0 && templates[len(templates)-1] == 0):
+			if c == '}' {
+				templates = templates[:len(templates)-1]
+			}
+			value, end, expression := readJSTemplate(src, i+1)
+			if value != "" {
+				tokens = append(tokens, jsToken{kind: jsString, value: value, offset: i})
+			}
+			if expression {
+				tokens = append(tokens, jsToken{kind: jsPunct, value: "${", offset: end - 2})
+				templates = append(templates, 0)
+			}
+			i = end
+
+		case c == '/' && regExpAllowed(tokens):
+			end := skipJSRegExp(src, i+1)
+			tokens = append(tokens, jsToken{kind: jsRegExp, value: string(src[i:end]), offset: i})
+			i = end
+
+		case isJSIdentByte(c) && (c < '0' || c > '9'):
+			end := i + 1
+			for end < len(src) && isJSIdentByte(src[end]) {
+				end++
+			}
+			tokens = append(tokens, jsToken{kind: jsIdent, value: string(src[i:end]), offset: i})
+			i = end
+
+		case (c >= '0' && c <= '9') || (c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9'):
+			end := i + 1
+			for end < len(src) && (isJSIdentByte(src[end]) || src[end] == '.' ||
+				((src[end] == '+' || src[end] == '-') && (src[end-1] == 'e' || src[end-1] == 'E'))) {
+				end++
+			}
+			tokens = append(tokens, jsToken{kind: jsNumber, value: string(src[i:end]), offset: i})
+			i = end
+
+		default:
+			if len(templates) > 0 {
+				if c == '{' {
+					templates[len(templates)-1]++
+				} else if c == '}' {
+					templates[len(templates)-1]--
+				}
+			}
+			tokens = append(tokens, jsToken{kind: jsPunct, value: string(c), offset: i})
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// isJSIdentByte reports whether c can be part of an identifier. Bytes of
+// non-ASCII characters are taken as identifier characters.
+func isJSIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// regExpAllowed reports whether a / after tokens starts a regular expression,
+// i.e. whether an operand rather than an operator is expected
+func regExpAllowed(tokens []jsToken) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case jsIdent:
+		return regExpKeywords[last.value]
+	case jsPunct:
+		return last.value != ")" && last.value != "]"
+	default:
+		return false
+	}
+}
+
+// skipJSRegExp returns the end of the regular expression literal whose body
+// starts at i, after its flags
+func skipJSRegExp(src []byte, i int) int {
+	inClass := false
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if !inClass {
+				i++
+				for i < len(src) && isJSIdentByte(src[i]) {
+					i++
+				}
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// readJSString decodes the string literal whose body starts at i and returns it
+// with the offset after the closing quote. An unescaped line break ends an
+// unterminated literal.
+func readJSString(src []byte, i int, quote byte) (string, int) {
+	var b strings.Builder
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == quote:
+			return b.String(), i + 1
+		case c == '\n':
+			return b.String(), i
+		case c == '\\':
+			i = decodeJSEscape(src, i+1, &b)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), i
+}
+
+// readJSTemplate decodes the text of a template literal from i up to its closing
+// backtick or the next "${", and returns the offset after it and whether an
+// embedded expression follows
+func readJSTemplate(src []byte, i int) (string, int, bool) {
+	var b strings.Builder
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '`':
+			return b.String(), i + 1, false
+		case c == '$' && i+1 < len(src) && src[i+1] == '{':
+			return b.String(), i + 2, true
+		case c == '\\':
+			i = decodeJSEscape(src, i+1, &b)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), i, false
+}
+
+// decodeJSEscape writes the character of the escape sequence whose body starts
+// at i (after the backslash) to b and returns the offset after the sequence.
+// Malformed \x and \u escapes are kept as the letter, as browsers do in sloppy
+// mode.
+func decodeJSEscape(src []byte, i int, b *strings.Builder) int {
+	if i >= len(src) {
+		return i
+	}
+	switch c := src[i]; c {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case '\r':
+		if i+1 < len(src) && src[i+1] == '\n' {
+			i++
+		}
+	case '\n':
+		// Line continuation
+	case 'x':
+		if r, ok := parseJSHex(src, i+1, 2); ok {
+			b.WriteRune(r)
+			return i + 3
+		}
+		b.WriteByte(c)
+	case 'u':
+		r, end, ok := parseJSUnicodeEscape(src, i+1)
+		if !ok {
+			b.WriteByte(c)
+			break
+		}
+		// A surrogate pair is written as two escapes
+		if utf16.IsSurrogate(r) && end+1 < len(src) && src[end] == '\\' && src[end+1] == 'u' {
+			if low, lowEnd, ok := parseJSUnicodeEscape(src, end+2); ok {
+				if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+					r, end = pair, lowEnd
+				}
+			}
+		}
+		b.WriteRune(r)
+		return end
+	default:
+		if c >= '0' && c <= '7' {
+			// \0 and legacy octal escapes of up to three digits
+			end := i + 1
+			for end < len(src) && end < i+3 && src[end] >= '0' && src[end] <= '7' {
+				end++
+			}
+			if value, err := strconv.ParseUint(string(src[i:end]), 8, 8); err == nil {
+				b.WriteRune(rune(value))
+				return end
+			}
+		}
+		r, size := utf8.DecodeRune(src[i:])
+		b.WriteRune(r)
+		return i + size
+	}
+	return i + 1
+}
+
+// parseJSUnicodeEscape parses the XXXX or {X...} of a \u escape starting at i
+func parseJSUnicodeEscape(src []byte, i int) (rune, int, bool) {
+	if i < len(src) && src[i] == '{' {
+		end := i + 1
+		for end < len(src) && src[end] != '}' && end-i <= 6 {
+			end++
+		}
+		if end >= len(src) || src[end] != '}' {
+			return 0, i, false
+		}
+		r, ok := parseJSHex(src, i+1, end-i-1)
+		return r, end + 1, ok && r <= utf8.MaxRune
+	}
+	r, ok := parseJSHex(src, i, 4)
+	return r, i + 4, ok
+}
+
+// parseJSHex parses n hexadecimal digits at i
+func parseJSHex(src []byte, i, n int) (rune, bool) {
+	if n == 0 || i+n > len(src) {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(string(src[i:i+n]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return rune(value), true
+}
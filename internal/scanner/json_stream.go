@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/sanitize"
+)
+
+// JSON file size limits used by the analyzers
+const (
+	// MaxJSONParseSize is the largest file that is fully unmarshaled into memory
+	MaxJSONParseSize = 10 * 1024 * 1024
+	// MaxJSONStreamSize is the largest file analyzed with a streaming token walk;
+	// larger files are skipped and reported
+	MaxJSONStreamSize = 1024 * 1024 * 1024
+)
+
+// Reasons recorded for files skipped during analysis
+const (
+	SkipReasonTooLarge    = "exceeds streaming size limit"
+	SkipReasonParseLimit  = "exceeds parse size limit"
+	SkipReasonInvalidJSON = "invalid JSON"
+	SkipReasonUnreadable  = "unreadable"
+)
+
+// skipReason returns the skip reason for a file that failed to open, read or parse
+func skipReason(err error) string {
+	if isParseError(err) {
+		return SkipReasonInvalidJSON
+	}
+	return SkipReasonUnreadable
+}
+
+// SkippedFile is a storage file that was not (fully) analyzed
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+}
+
+// analyzeLargeJSONStorageFile walks a JSON file token by token so keys can be
+// evaluated without holding the whole document in memory
+func (sa *StorageAnalyzer) analyzeLargeJSONStorageFile(filePath string, info os.FileInfo, storage *ExtensionStorage) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	tok, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	return sa.walkJSONValue(decoder, tok, filepath.Base(filePath), "", info, storage)
+}
+
+// walkJSONValue consumes the value starting with tok, recording telemetry keys the
+// same way analyzeJSONData does. Object and array values are summarized by size.
+func (sa *StorageAnalyzer) walkJSONValue(decoder *json.Decoder, tok json.Token, fileName, keyPath string, info os.FileInfo, storage *ExtensionStorage) error {
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil // Scalars have no nested keys
+	}
+
+	switch delim {
+	case '{':
+		for decoder.More() {
+			keyTok, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyTok.(string)
+
+			currentPath := key
+			if keyPath != "" {
+				currentPath = keyPath + "." + key
+			}
+
+			start := decoder.InputOffset()
+			valueTok, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			var value, displayValue interface{}
+			var size int64
+			if valueDelim, isContainer := valueTok.(json.Delim); isContainer {
+				// Recurse into nested objects
+				if err := sa.walkJSONValue(decoder, valueTok, fileName, currentPath, info, storage); err != nil {
+					return err
+				}
+				size = decoder.InputOffset() - start
+				kind := "Object"
+				if valueDelim == '[' {
+					kind = "Array"
+				}
+				displayValue = fmt.Sprintf("%s (%d bytes)", kind, size)
+			} else {
+				value = valueTok
+				displayValue = sanitize.KeyValue(key, value)
+				size = sa.estimateValueSize(value)
+			}
+
+			if size < sa.sizeThreshold {
+				continue
+			}
+
+			risk, _, reasons := explainRisk(sa.keyRiskMatches(key, currentPath, value))
+			if risk > TelemetryRiskNone {
+				item := StorageDataItem{
+					Key:             currentPath,
+					Value:           displayValue,
+					Size:            size,
+					Type:            "json_key",
+					Risk:            risk,
+					Category:        sa.categorizeKey(key),
+					Description:     sa.getKeyDescription(key, risk),
+					LastModified:    info.ModTime(),
+					AccessFrequency: sa.estimateAccessFrequency(info),
+					Reasons:         reasons,
+				}
+
+				storage.StorageItems = append(storage.StorageItems, item)
+
+				if risk >= TelemetryRiskMedium {
+					storage.TelemetrySize += item.Size
+				}
+			}
+		}
+
+	case '[':
+		for i := 0; decoder.More(); i++ {
+			elementTok, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			arrayPath := fmt.Sprintf("%s[%d]", keyPath, i)
+			if err := sa.walkJSONValue(decoder, elementTok, fileName, arrayPath, info, storage); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unexpected JSON delimiter %q", delim)
+	}
+
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestTelemetryInfo is what an installed extension's package.json discloses
+// about its telemetry
+type ManifestTelemetryInfo struct {
+	// DeclaredTelemetrySettings are the contributed settings whose key mentions telemetry, sorted
+	DeclaredTelemetrySettings []string `json:"declared_telemetry_settings"`
+	// HasExplicitOptOut is set when one of them is a boolean or has an off value
+	HasExplicitOptOut bool   `json:"has_explicit_opt_out"`
+	PrivacyPolicyURL  string `json:"privacy_policy_url,omitempty"` // From "homepage", else "bugs.url"
+	Publisher         string `json:"publisher"`
+}
+
+// manifestTelemetryMatcher flags contributed setting keys that mention telemetry
+var manifestTelemetryMatcher = newPatternMatcher(map[string]TelemetryRisk{
+	"telemetry": TelemetryRiskMedium,
+	"analytics": TelemetryRiskMedium,
+	"tracking":  TelemetryRiskMedium,
+})
+
+// manifestOptOutValues are enum values of a telemetry setting that turn it off
+var manifestOptOutValues = map[string]bool{"off": true, "none": true, "disabled": true, "false": true}
+
+// extensionManifestJSON mirrors the package.json fields AnalyzeExtensionManifest reads
+type extensionManifestJSON struct {
+	Publisher   string          `json:"publisher"`
+	Homepage    string          `json:"homepage"`
+	Bugs        json.RawMessage `json:"bugs"` // A URL string or an object with a url
+	Contributes struct {
+		// An object with properties, or an array of them for settings in several sections
+		Configuration json.RawMessage `json:"configuration"`
+	} `json:"contributes"`
+}
+
+// manifestConfiguration is one section of contributes.configuration
+type manifestConfiguration struct {
+	Properties map[string]manifestSetting `json:"properties"`
+}
+
+// manifestSetting is a contributed setting
+type manifestSetting struct {
+	Type interface{}   `json:"type"` // A type name or a list of them
+	Enum []interface{} `json:"enum"`
+}
+
+// AnalyzeExtensionManifest reads the package.json of the newest installed version
+// of extensionID and reports the telemetry settings it contributes
+func (sa *StorageAnalyzer) AnalyzeExtensionManifest(extensionID string) (*ManifestTelemetryInfo, error) {
+	extensionsPath, err := sa.paths.ExtensionsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extensions path: %w", err)
+	}
+
+	extensionDir, err := findInstalledExtension(extensionsPath, extensionID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestPath := filepath.Join(extensionDir, "package.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return parseManifestTelemetry(data)
+}
+
+// findInstalledExtension returns the directory of the newest installed version of
+// extensionID in extensionsPath
+func findInstalledExtension(extensionsPath, extensionID string) (string, error) {
+	entries, err := os.ReadDir(extensionsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read extensions directory: %w", err)
+	}
+
+	newest, newestVersion := "", ""
+	for _, entry := range entries {
+		id := installedExtensionID(entry.Name())
+		if !entry.IsDir() || id == "" || !strings.EqualFold(id, extensionID) {
+			continue
+		}
+		version := entry.Name()[len(id)+1:]
+		if newest == "" || compareVersions(version, newestVersion) > 0 {
+			newest, newestVersion = entry.Name(), version
+		}
+	}
+
+	if newest == "" {
+		return "", fmt.Errorf("extension %s is not installed: %w", extensionID, os.ErrNotExist)
+	}
+	return filepath.Join(extensionsPath, newest), nil
+}
+
+// parseManifestTelemetry extracts the telemetry disclosures of a package.json
+func parseManifestTelemetry(data []byte) (*ManifestTelemetryInfo, error) {
+	var manifest extensionManifestJSON
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	info := &ManifestTelemetryInfo{
+		DeclaredTelemetrySettings: make([]string, 0),
+		PrivacyPolicyURL:          manifest.Homepage,
+		Publisher:                 manifest.Publisher,
+	}
+	if info.PrivacyPolicyURL == "" {
+		info.PrivacyPolicyURL = manifestBugsURL(manifest.Bugs)
+	}
+
+	for _, section := range manifestConfigurations(manifest.Contributes.Configuration) {
+		for key, setting := range section.Properties {
+			if manifestTelemetryMatcher.highestRisk(strings.ToLower(key)) == TelemetryRiskNone {
+				continue
+			}
+			info.DeclaredTelemetrySettings = append(info.DeclaredTelemetrySettings, key)
+			if setting.isOptOut() {
+				info.HasExplicitOptOut = true
+			}
+		}
+	}
+	sort.Strings(info.DeclaredTelemetrySettings)
+
+	return info, nil
+}
+
+// manifestConfigurations decodes contributes.configuration, which is either a single
+// section or an array of sections. Malformed configuration has no sections.
+func manifestConfigurations(raw json.RawMessage) []manifestConfiguration {
+	var sections []manifestConfiguration
+	if err := json.Unmarshal(raw, &sections); err == nil {
+		return sections
+	}
+
+	var section manifestConfiguration
+	if err := json.Unmarshal(raw, &section); err == nil {
+		return []manifestConfiguration{section}
+	}
+	return nil
+}
+
+// manifestBugsURL returns the URL of the bugs field, which is a URL or an object
+// with a url
+func manifestBugsURL(raw json.RawMessage) string {
+	var bugsURL string
+	if err := json.Unmarshal(raw, &bugsURL); err == nil {
+		return bugsURL
+	}
+
+	var bugs struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &bugs); err == nil {
+		return bugs.URL
+	}
+	return ""
+}
+
+// isOptOut reports whether the user can turn the setting off: it is a boolean or
+// one of its enum values disables it
+func (s manifestSetting) isOptOut() bool {
+	switch t := s.Type.(type) {
+	case string:
+		if t == "boolean" {
+			return true
+		}
+	case []interface{}:
+		for _, name := range t {
+			if name == "boolean" {
+				return true
+			}
+		}
+	}
+
+	for _, value := range s.Enum {
+		if manifestOptOutValues[strings.ToLower(fmt.Sprint(value))] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// writeExtensionManifest installs an extension directory with the given package.json
+func writeExtensionManifest(t *testing.T, home, dirName, manifest string) {
+	t.Helper()
+	dir := filepath.Join(home, ".vscode", "extensions", dirName)
+	mkdirAll(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+}
+
+func TestAnalyzeExtensionManifest(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+
+	writeExtensionManifest(t, home, "acme.tool-1.9.0", `{"publisher": "acme", "name": "tool"}`)
+	writeExtensionManifest(t, home, "acme.tool-1.10.0", `{
+		"publisher": "acme",
+		"name": "tool",
+		"bugs": {"url": "https://example.com/acme/issues"},
+		"contributes": {"configuration": [
+			{"properties": {
+				"acme.telemetry.level": {"type": "string", "enum": ["all", "error", "off"]},
+				"acme.fontSize": {"type": "number"}
+			}},
+			{"properties": {"acme.usageAnalytics": {"type": "object"}}}
+		]}
+	}`)
+	writeExtensionManifest(t, home, "other.tracker-0.1.0", `{
+		"publisher": "other",
+		"name": "tracker",
+		"homepage": "https://example.com/privacy",
+		"bugs": "https://example.com/other/issues",
+		"contributes": {"configuration": {"properties": {"tracker.enableTracking": {"type": ["boolean", "null"]}}}}
+	}`)
+
+	analyzer := NewStorageAnalyzerWithResolver(resolver)
+
+	info, err := analyzer.AnalyzeExtensionManifest("Acme.Tool")
+	if err != nil {
+		t.Fatalf("AnalyzeExtensionManifest failed: %v", err)
+	}
+	want := &ManifestTelemetryInfo{
+		DeclaredTelemetrySettings: []string{"acme.telemetry.level", "acme.usageAnalytics"},
+		HasExplicitOptOut:         true,
+		PrivacyPolicyURL:          "https://example.com/acme/issues",
+		Publisher:                 "acme",
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Expected the newest version's disclosures %+v, got %+v", want, info)
+	}
+
+	info, err = analyzer.AnalyzeExtensionManifest("other.tracker")
+	if err != nil {
+		t.Fatalf("AnalyzeExtensionManifest failed: %v", err)
+	}
+	if !info.HasExplicitOptOut || info.PrivacyPolicyURL != "https://example.com/privacy" {
+		t.Errorf("Expected a boolean opt-out and the homepage URL, got %+v", info)
+	}
+
+	if _, err := analyzer.AnalyzeExtensionManifest("acme.missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist for an extension that is not installed, got %v", err)
+	}
+}
+
+func TestAnalyzeExtensionManifestNoOptOut(t *testing.T) {
+	info, err := parseManifestTelemetry([]byte(`{
+		"publisher": "acme",
+		"contributes": {"configuration": {"properties": {"acme.telemetryEndpoint": {"type": "string"}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("parseManifestTelemetry failed: %v", err)
+	}
+	if info.HasExplicitOptOut || len(info.DeclaredTelemetrySettings) != 1 {
+		t.Errorf("Expected a declared setting without opt-out, got %+v", info)
+	}
+}
+
+func TestAnalyzeStorageAttachesManifestInfo(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+
+	writeExtensionManifest(t, home, "acme.tool-1.0.0", `{
+		"publisher": "acme",
+		"name": "tool",
+		"contributes": {"configuration": {"properties": {"acme.enableTelemetry": {"type": "boolean"}}}}
+	}`)
+	for _, id := range []string{"acme.tool", "acme.uninstalled"} {
+		dir := filepath.Join(home, ".config", "Code", "User", "globalStorage", id)
+		mkdirAll(t, dir)
+		if err := os.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"machineId": "abc"}`), 0644); err != nil {
+			t.Fatalf("Failed to write storage: %v", err)
+		}
+	}
+
+	result, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	storages := result.GlobalStorageAnalysis.ExtensionStorages
+	if len(storages) != 2 {
+		t.Fatalf("Expected 2 extension storages, got %d", len(storages))
+	}
+	for _, storage := range storages {
+		switch storage.ExtensionID {
+		case "acme.tool":
+			if storage.ManifestInfo == nil || !storage.ManifestInfo.HasExplicitOptOut {
+				t.Errorf("Expected the manifest info of the installed extension, got %+v", storage.ManifestInfo)
+			}
+		default:
+			if storage.ManifestInfo != nil {
+				t.Errorf("Expected no manifest info for %s, got %+v", storage.ExtensionID, storage.ManifestInfo)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxBundleFileSize is the largest JavaScript file NetworkRequestAnalyzer reads
+const MaxBundleFileSize = 1024 * 1024
+
+// urlContextRadius is how far around a function call match URLs are searched for
+// when the call itself contains none. Bundles are often minified to a single line,
+// so the whole line would pick up unrelated URLs.
+const urlContextRadius = 200
+
+// urlLiteralPattern matches http(s) URLs inside string literals of JavaScript code
+var urlLiteralPattern = regexp.MustCompile("https?://[^\\s'\"`<>()\\\\]+")
+
+// NetworkEndpointFinding is a telemetry endpoint an extension bundle sends requests to
+type NetworkEndpointFinding struct {
+	File             string        `json:"file"` // Relative to the extension directory
+	LineNumber       int           `json:"line_number"`
+	URL              string        `json:"url"`
+	EndpointCategory string        `json:"endpoint_category"`
+	Risk             TelemetryRisk `json:"risk"`
+}
+
+// NetworkAnalysis summarizes the telemetry endpoints found in installed extensions
+type NetworkAnalysis struct {
+	ExtensionsAnalyzed int                      `json:"extensions_analyzed"`
+	EndpointsFound     int                      `json:"endpoints_found"`
+	Findings           []NetworkEndpointFinding `json:"findings"`
+}
+
+// NetworkRequestAnalyzer finds the telemetry endpoints extension bundles call by
+// running the function call patterns of AdvancedPatternMatcher over their
+// JavaScript and classifying the URLs at those calls
+type NetworkRequestAnalyzer struct {
+	matcher *AdvancedPatternMatcher
+	checker *TelemetryEndpointChecker
+}
+
+// NewNetworkRequestAnalyzer creates a new network request analyzer
+func NewNetworkRequestAnalyzer() *NetworkRequestAnalyzer {
+	return &NetworkRequestAnalyzer{
+		matcher: NewAdvancedPatternMatcher(),
+		checker: NewTelemetryEndpointChecker(),
+	}
+}
+
+// SetDeepScan enables analyzing the bundles of installed extensions for telemetry
+// endpoints during storage analysis
+func (sa *StorageAnalyzer) SetDeepScan(enabled bool) {
+	sa.deepScan = enabled
+}
+
+// AnalyzeExtensionBundle scans the *.js files of an installed extension, skipping
+// node_modules and files over MaxBundleFileSize, and returns the telemetry
+// endpoints called from them
+func (nra *NetworkRequestAnalyzer) AnalyzeExtensionBundle(extensionPath string) ([]NetworkEndpointFinding, error) {
+	if _, err := os.Stat(extensionPath); err != nil {
+		return nil, fmt.Errorf("failed to access extension directory: %w", err)
+	}
+
+	var findings []NetworkEndpointFinding
+	err := filepath.Walk(extensionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".js") || info.Size() > MaxBundleFileSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		relPath, err := filepath.Rel(extensionPath, path)
+		if err != nil {
+			relPath = path
+		}
+		findings = append(findings, nra.analyzeCode(string(content), filepath.ToSlash(relPath))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk extension directory: %w", err)
+	}
+
+	return findings, nil
+}
+
+// analyzeCode returns the telemetry endpoints called in a JavaScript file. URLs
+// are taken from the function call match itself, or from the code around it when
+// the call passes the URL indirectly (e.g. new TelemetryReporter(key)).
+func (nra *NetworkRequestAnalyzer) analyzeCode(content, file string) []NetworkEndpointFinding {
+	var findings []NetworkEndpointFinding
+	seen := make(map[string]bool)
+
+	for _, match := range nra.matcher.AnalyzeCode(content, file) {
+		if match.Category != "function_calls" {
+			continue
+		}
+
+		urls := urlLiteralPattern.FindAllString(match.Match, -1)
+		if len(urls) == 0 {
+			urls = urlLiteralPattern.FindAllString(surroundingCode(match), -1)
+		}
+
+		for _, rawURL := range urls {
+			rawURL = strings.TrimRight(rawURL, ".,;:")
+			category, risk, ok := nra.checker.Check(rawURL)
+			key := fmt.Sprintf("%d|%s", match.Line, rawURL)
+			if !ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			findings = append(findings, NetworkEndpointFinding{
+				File:             file,
+				LineNumber:       match.Line,
+				URL:              rawURL,
+				EndpointCategory: category,
+				Risk:             risk,
+			})
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].LineNumber != findings[j].LineNumber {
+			return findings[i].LineNumber < findings[j].LineNumber
+		}
+		return findings[i].URL < findings[j].URL
+	})
+	return findings
+}
+
+// surroundingCode returns the part of a match's line within urlContextRadius bytes
+// of the match
+func surroundingCode(match PatternMatch) string {
+	line := match.Context
+	start := match.Column
+	if start < 0 || start > len(line) {
+		return line
+	}
+	end := min(start+len(match.Match)+urlContextRadius, len(line))
+	start = max(start-urlContextRadius, 0)
+	return line[start:end]
+}
+
+// analyzeNetworkRequests analyzes the bundles of every installed extension, or only
+// of extensionID if set
+func (sa *StorageAnalyzer) analyzeNetworkRequests(extensionID string) (*NetworkAnalysis, error) {
+	extensionsPath, err := sa.paths.ExtensionsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extensions path: %w", err)
+	}
+
+	analysis := &NetworkAnalysis{Findings: make([]NetworkEndpointFinding, 0)}
+	entries, err := os.ReadDir(extensionsPath)
+	if os.IsNotExist(err) {
+		return analysis, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extensions directory: %w", err)
+	}
+
+	analyzer := NewNetworkRequestAnalyzer()
+	for _, entry := range entries {
+		if !entry.IsDir() || (extensionID != "" && !matchesExtension(extensionID, installedExtensionID(entry.Name()))) {
+			continue
+		}
+
+		extensionPath := filepath.Join(extensionsPath, entry.Name())
+		findings, err := analyzer.AnalyzeExtensionBundle(extensionPath)
+		if err != nil {
+			continue
+		}
+		analysis.ExtensionsAnalyzed++
+		for _, finding := range findings {
+			// Keep the extension directory so findings of different extensions are distinguishable
+			finding.File = entry.Name() + "/" + finding.File
+			analysis.Findings = append(analysis.Findings, finding)
+		}
+	}
+	analysis.EndpointsFound = len(analysis.Findings)
+
+	return analysis, nil
+}
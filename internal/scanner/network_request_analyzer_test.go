@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTelemetryEndpointCheckerCheck(t *testing.T) {
+	checker := NewTelemetryEndpointChecker()
+
+	tests := []struct {
+		url          string
+		wantOK       bool
+		wantCategory string
+		wantRisk     TelemetryRisk
+	}{
+		{"https://westus-0.in.applicationinsights.azure.com/v2/track", true, "Application Insights", TelemetryRiskCritical},
+		{"https://dc.services.visualstudio.com/v2/track", true, "Application Insights", TelemetryRiskCritical},
+		{"https://API.SEGMENT.IO/v1/batch", true, "Product Analytics", TelemetryRiskHigh},
+		{"https://o123.ingest.sentry.io/api/1/envelope", true, "Error Reporting", TelemetryRiskMedium},
+		{"https://api.example.com/v1/telemetry", true, "Generic Telemetry", TelemetryRiskMedium},
+		{"https://analytics.example.com/", true, "Generic Telemetry", TelemetryRiskMedium},
+		{"https://api.example.com/v1/completions", false, "", TelemetryRiskNone},
+		{"https://notsentry.io/api", false, "", TelemetryRiskNone},
+		{"ftp://example.com/telemetry", false, "", TelemetryRiskNone},
+		{"/relative/telemetry", false, "", TelemetryRiskNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			category, risk, ok := checker.Check(tt.url)
+			if ok != tt.wantOK || category != tt.wantCategory || risk != tt.wantRisk {
+				t.Errorf("Check(%q) = %q, %v, %v; want %q, %v, %v", tt.url, category, risk, ok, tt.wantCategory, tt.wantRisk, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTelemetryEndpointCheckerDomains(t *testing.T) {
+	domains := NewTelemetryEndpointChecker().Domains()
+	if !sort.StringsAreSorted(domains) {
+		t.Errorf("Expected sorted domains, got %v", domains)
+	}
+	seen := make(map[string]bool)
+	for _, domain := range domains {
+		if strings.HasPrefix(domain, ".") || seen[domain] {
+			t.Errorf("Expected unique domains without a leading dot, got %q", domain)
+		}
+		seen[domain] = true
+	}
+	for _, domain := range []string{"sentry.io", "dc.services.visualstudio.com", "events.data.microsoft.com"} {
+		if !seen[domain] {
+			t.Errorf("Expected %s in %v", domain, domains)
+		}
+	}
+}
+
+func TestAnalyzeExtensionBundle(t *testing.T) {
+	extDir := t.TempDir()
+	writeBundleFile(t, extDir, "out/extension.js", strings.Join([]string{
+		`const reporter = new TelemetryReporter("https://dc.services.visualstudio.com/v2/track");`,
+		`fetch("https://api.example.com/v1/completions");`,
+		`fetch("https://api.example.com/v1/telemetry", { method: "POST" });`,
+		`axios.post("https://api.mixpanel.com/analytics/track", payload);`,
+	}, "\n"))
+	// Dependencies, non-JS files and oversized bundles are skipped
+	writeBundleFile(t, extDir, "node_modules/dep/index.js", `fetch("https://api.segment.io/telemetry");`)
+	writeBundleFile(t, extDir, "README.md", `fetch("https://api.segment.io/telemetry");`)
+	writeBundleFile(t, extDir, "dist/huge.js", `fetch("https://api.segment.io/telemetry");`+strings.Repeat(" ", MaxBundleFileSize))
+
+	findings, err := NewNetworkRequestAnalyzer().AnalyzeExtensionBundle(extDir)
+	if err != nil {
+		t.Fatalf("AnalyzeExtensionBundle failed: %v", err)
+	}
+
+	want := []NetworkEndpointFinding{
+		{File: "out/extension.js", LineNumber: 1, URL: "https://dc.services.visualstudio.com/v2/track", EndpointCategory: "Application Insights", Risk: TelemetryRiskCritical},
+		{File: "out/extension.js", LineNumber: 3, URL: "https://api.example.com/v1/telemetry", EndpointCategory: "Generic Telemetry", Risk: TelemetryRiskMedium},
+		{File: "out/extension.js", LineNumber: 4, URL: "https://api.mixpanel.com/analytics/track", EndpointCategory: "Product Analytics", Risk: TelemetryRiskHigh},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(want), len(findings), findings)
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("Finding %d = %+v, want %+v", i, findings[i], want[i])
+		}
+	}
+}
+
+func TestAnalyzeExtensionBundleMissingDirectory(t *testing.T) {
+	if _, err := NewNetworkRequestAnalyzer().AnalyzeExtensionBundle(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing extension directory")
+	}
+}
+
+func writeBundleFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// workspaceURIKeys are the JSON keys that hold workspace URIs in VS Code's window state
+var workspaceURIKeys = map[string]bool{
+	"folder":        true,
+	"folderUri":     true,
+	"workspace":     true,
+	"configPath":    true,
+	"configURIPath": true,
+}
+
+// GetOpenWorkspacesIn returns the hashes of the workspaces VS Code has open in the
+// given workspaceStorage directory, e.g. of a Snap or Flatpak install. Open windows
+// are read from the windowsState entry in the storage.json of the same user data
+// directory; recently used workspaces that are not in a window are not open. Each
+// workspaceStorage/<hash> is resolved with ResolveWorkspaceLocation and matched
+// against the windows' locations. A missing storage.json yields no workspaces.
+func GetOpenWorkspacesIn(workspaceStoragePath string) ([]string, error) {
+	storagePath := filepath.Join(filepath.Dir(workspaceStoragePath), "globalStorage", "storage.json")
+	return openWorkspaceHashes(storagePath, workspaceStoragePath, runtime.GOOS)
+}
+
+// openWorkspaceHashes matches the locations of open windows against workspace storage directories
+func openWorkspaceHashes(storagePath, workspaceStoragePath, goos string) ([]string, error) {
+	open, err := openWindowLocations(storagePath, goos)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) == 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(workspaceStoragePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read workspace storage: %w", err)
+	}
+
+	var hashes []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		location, err := ResolveWorkspaceLocation(filepath.Join(workspaceStoragePath, entry.Name()))
+		if err != nil {
+			continue // Workspaces without a readable workspace.json cannot be matched
+		}
+		if open[normalizeWorkspaceLocation(location.Path, goos)] {
+			hashes = append(hashes, entry.Name())
+		}
+	}
+
+	return hashes, nil
+}
+
+// openWindowLocations returns the normalized locations of the windows recorded
+// under windowsState in storage.json
+func openWindowLocations(storagePath, goos string) (map[string]bool, error) {
+	data, err := os.ReadFile(storagePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read storage file: %w", err)
+	}
+
+	var storage map[string]interface{}
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to parse storage file: %w", err)
+	}
+
+	locations := make(map[string]bool)
+	collectWorkspaceURIs(storage["windowsState"], func(uri string) {
+		if path, _, err := workspaceURIToPath(uri); err == nil {
+			locations[normalizeWorkspaceLocation(path, goos)] = true
+		}
+	})
+	return locations, nil
+}
+
+// collectWorkspaceURIs walks a JSON value and calls add for each workspace URI
+// found under workspaceURIKeys
+func collectWorkspaceURIs(value interface{}, add func(uri string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if uri, ok := child.(string); ok {
+				if workspaceURIKeys[key] && strings.Contains(uri, "://") {
+					add(uri)
+				}
+				continue
+			}
+			collectWorkspaceURIs(child, add)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectWorkspaceURIs(child, add)
+		}
+	}
+}
+
+// normalizeWorkspaceLocation trims trailing separators so resolved locations compare
+// equal. Local paths are case-insensitive on Windows and macOS.
+func normalizeWorkspaceLocation(path, goos string) string {
+	if len(path) > 1 {
+		path = strings.TrimRight(path, `/\`)
+	}
+	if goos == "windows" || goos == "darwin" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
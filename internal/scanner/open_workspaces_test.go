@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// writeWorkspaceJSON creates workspaceStorage/<hash>/workspace.json with the given content
+func writeWorkspaceJSON(t *testing.T, workspaceStoragePath, hash, content string) {
+	t.Helper()
+	dir := filepath.Join(workspaceStoragePath, hash)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create workspace dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "workspace.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workspace.json: %v", err)
+	}
+}
+
+func TestOpenWorkspaceHashes(t *testing.T) {
+	tempDir := t.TempDir()
+	workspaceStoragePath := filepath.Join(tempDir, "workspaceStorage")
+
+	writeWorkspaceJSON(t, workspaceStoragePath, "open-folder", `{"folder":"file:///home/me/my%20project"}`)
+	writeWorkspaceJSON(t, workspaceStoragePath, "open-workspace", `{"workspace":"file:///home/me/app.code-workspace"}`)
+	writeWorkspaceJSON(t, workspaceStoragePath, "open-remote", `{"folder":"vscode-remote://ssh-remote%2Bbox/srv/app"}`)
+	writeWorkspaceJSON(t, workspaceStoragePath, "recent", `{"folder":"file:///home/me/recent"}`)
+	writeWorkspaceJSON(t, workspaceStoragePath, "closed", `{"folder":"file:///home/me/old"}`)
+
+	// Recently used workspaces are listed outside windowsState and are not open
+	storagePath := filepath.Join(tempDir, "storage.json")
+	storage := `{
+		"telemetry.machineId": "abc",
+		"lastKnownMenubarData": {"menus": {"File": {"items": [{"id": "openRecentFolder", "uri": {"folder": "file:///home/me/recent"}}]}}},
+		"windowsState": {
+			"lastActiveWindow": {"folder": "file:///home/me/my project/", "backupPath": "/tmp/backup"},
+			"openedWindows": [
+				{"workspaceIdentifier": {"id": "1234", "configURIPath": "file:///home/me/app.code-workspace"}},
+				{"folder": "vscode-remote://ssh-remote%2Bbox/srv/app"}
+			]
+		}
+	}`
+	if err := os.WriteFile(storagePath, []byte(storage), 0644); err != nil {
+		t.Fatalf("Failed to write storage.json: %v", err)
+	}
+
+	hashes, err := openWorkspaceHashes(storagePath, workspaceStoragePath, "linux")
+	if err != nil {
+		t.Fatalf("openWorkspaceHashes() failed: %v", err)
+	}
+
+	sort.Strings(hashes)
+	want := []string{"open-folder", "open-remote", "open-workspace"}
+	if len(hashes) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, hashes)
+	}
+	for i := range want {
+		if hashes[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, hashes)
+			break
+		}
+	}
+}
+
+func TestOpenWorkspaceHashesMissingState(t *testing.T) {
+	tempDir := t.TempDir()
+
+	hashes, err := openWorkspaceHashes(filepath.Join(tempDir, "storage.json"), filepath.Join(tempDir, "workspaceStorage"), "linux")
+	if err != nil {
+		t.Fatalf("Expected a missing storage.json to be ignored, got %v", err)
+	}
+	if len(hashes) != 0 {
+		t.Errorf("Expected no open workspaces, got %v", hashes)
+	}
+}
+
+func TestNormalizeWorkspaceLocation(t *testing.T) {
+	if normalizeWorkspaceLocation(`C:\Users\Me\Project\`, "windows") != normalizeWorkspaceLocation(`c:\users\me\project`, "windows") {
+		t.Error("Expected Windows paths to compare case-insensitively")
+	}
+	if normalizeWorkspaceLocation("/home/Me", "linux") == normalizeWorkspaceLocation("/home/me", "linux") {
+		t.Error("Expected Linux paths to stay case-sensitive")
+	}
+	if normalizeWorkspaceLocation("/", "linux") != "/" {
+		t.Error("Expected the root directory to be kept")
+	}
+}
@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestScannerPathsPerOS(t *testing.T) {
+	home := filepath.FromSlash("/home/user")
+
+	tests := []struct {
+		name          string
+		resolver      utils.FakePathResolver
+		globalStorage string
+		settings      string
+		cacheDir      string
+	}{
+		{
+			name:          "windows",
+			resolver:      utils.FakePathResolver{Home: home, OS: "windows"},
+			globalStorage: filepath.Join(home, "AppData", "Roaming", "Code", "User", "globalStorage"),
+			settings:      filepath.Join(home, "AppData", "Roaming", "Code", "User", "settings.json"),
+			cacheDir:      filepath.Join(home, "AppData", "Local", "vscode-extensions-cache"),
+		},
+		{
+			name:          "windows APPDATA",
+			resolver:      utils.FakePathResolver{Home: home, OS: "windows", Env: map[string]string{"APPDATA": filepath.FromSlash("/roaming")}},
+			globalStorage: filepath.Join(filepath.FromSlash("/roaming"), "Code", "User", "globalStorage"),
+			settings:      filepath.Join(filepath.FromSlash("/roaming"), "Code", "User", "settings.json"),
+			cacheDir:      filepath.Join(home, "AppData", "Local", "vscode-extensions-cache"),
+		},
+		{
+			name:          "macOS",
+			resolver:      utils.FakePathResolver{Home: home, OS: "darwin"},
+			globalStorage: filepath.Join(home, "Library", "Application Support", "Code", "User", "globalStorage"),
+			settings:      filepath.Join(home, "Library", "Application Support", "Code", "User", "settings.json"),
+			cacheDir:      filepath.Join(home, "Library", "Caches", "vscode-extensions"),
+		},
+		{
+			name:          "linux",
+			resolver:      utils.FakePathResolver{Home: home, OS: "linux", Env: map[string]string{"XDG_CACHE_HOME": filepath.FromSlash("/xdg")}},
+			globalStorage: filepath.Join(home, ".config", "Code", "User", "globalStorage"),
+			settings:      filepath.Join(home, ".config", "Code", "User", "settings.json"),
+			cacheDir:      filepath.Join(filepath.FromSlash("/xdg"), "vscode-extensions"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageAnalyzer := NewStorageAnalyzerWithResolver(tt.resolver)
+			configAnalyzer := NewConfigAnalyzerWithResolver(tt.resolver)
+			settingsScanner := NewExtensionSettingsScannerWithResolver(tt.resolver)
+
+			// All analyzers share one path derivation, so they cannot diverge again
+			for name, paths := range map[string]*utils.VSCodePaths{
+				"StorageAnalyzer":          storageAnalyzer.paths,
+				"ConfigAnalyzer":           configAnalyzer.paths,
+				"ExtensionSettingsScanner": settingsScanner.paths,
+			} {
+				if got, err := paths.GlobalStoragePath(); err != nil || got != tt.globalStorage {
+					t.Errorf("%s: expected global storage %s, got %s (%v)", name, tt.globalStorage, got, err)
+				}
+				if got, err := paths.UserSettingsPath(); err != nil || got != tt.settings {
+					t.Errorf("%s: expected settings %s, got %s (%v)", name, tt.settings, got, err)
+				}
+			}
+
+			if !containsString(storageAnalyzer.getCacheDirectories(), tt.cacheDir) {
+				t.Errorf("Expected cache directories to include %s, got %v", tt.cacheDir, storageAnalyzer.getCacheDirectories())
+			}
+		})
+	}
+}
+
+func TestSuggestOptimalSettingsReadsResolvedSettings(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "darwin"}
+
+	userDir := filepath.Join(home, "Library", "Application Support", "Code", "User")
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		t.Fatalf("Failed to create user directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "settings.json"), []byte(`{"telemetry.telemetryLevel": "off"}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	suggestion, err := NewConfigAnalyzerWithResolver(resolver).SuggestOptimalSettings()
+	if err != nil {
+		t.Fatalf("SuggestOptimalSettings failed: %v", err)
+	}
+	for _, change := range suggestion.RecommendedChanges {
+		if change.Key == "telemetry.telemetryLevel" {
+			t.Error("Expected the macOS settings file to be read, but telemetry was not seen as off")
+		}
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyzeStorageIncludesPackagedInstalls(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	regularUser := filepath.Join(home, ".config", "Code", "User")
+	snapUser := filepath.Join(home, "snap", "code", "current", ".config", "Code", "User")
+	flatpakUser := filepath.Join(home, ".var", "app", "com.visualstudio.code", "config", "Code", "User")
+	for _, path := range []string{
+		filepath.Join(regularUser, "globalStorage", "ms-python.python", "telemetry.json"),
+		filepath.Join(snapUser, "globalStorage", "augment.vscode-augment", "telemetry.json"),
+		filepath.Join(snapUser, "workspaceStorage", "abc123", "augment.vscode-augment", "state.json"),
+		filepath.Join(flatpakUser, "globalStorage", "github.copilot", "telemetry.json"),
+		filepath.Join(flatpakUser, "workspaceStorage", "def456", "github.copilot", "state.json"),
+	} {
+		mkdirAll(t, filepath.Dir(path))
+		if err := os.WriteFile(path, []byte(`{"machineId": "abc"}`), 0644); err != nil {
+			t.Fatalf("Failed to write storage: %v", err)
+		}
+	}
+
+	result, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+
+	extensions := make(map[string]bool)
+	for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+		extensions[storage.ExtensionID] = true
+	}
+	for _, id := range []string{"ms-python.python", "augment.vscode-augment", "github.copilot"} {
+		if !extensions[id] {
+			t.Errorf("Expected global storage of %s, got %v", id, extensions)
+		}
+	}
+	if result.GlobalStorageAnalysis.ExtensionCount != 3 {
+		t.Errorf("Expected 3 extensions, got %d", result.GlobalStorageAnalysis.ExtensionCount)
+	}
+	if result.WorkspaceStorageAnalysis.WorkspaceCount != 2 {
+		t.Errorf("Expected the Snap and Flatpak workspaces, got %d workspaces", result.WorkspaceStorageAnalysis.WorkspaceCount)
+	}
+}
+
+func TestAnalyzeConfigurationsIncludesFlatpakSettings(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	flatpakSettings := filepath.Join(home, ".var", "app", "com.visualstudio.code", "config", "Code", "User", "settings.json")
+	mkdirAll(t, filepath.Join(home, ".config", "Code", "User"))
+	mkdirAll(t, filepath.Dir(flatpakSettings))
+	if err := os.WriteFile(flatpakSettings, []byte(`{"telemetry.telemetryLevel": "all"}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	result, err := NewConfigAnalyzerWithResolver(resolver).AnalyzeConfigurations()
+	if err != nil {
+		t.Fatalf("AnalyzeConfigurations failed: %v", err)
+	}
+
+	found := false
+	for _, findings := range [][]ConfigFinding{result.VSCodeSettings, result.TelemetrySettings} {
+		for _, finding := range findings {
+			if finding.File == flatpakSettings {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected findings in %s, got %+v", flatpakSettings, result)
+	}
+}
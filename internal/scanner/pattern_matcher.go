@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// patternMatcher finds every telemetry pattern contained in a set of texts in a
+// single pass over each text. It is an Aho-Corasick automaton over the lowercase
+// patterns, compiled to a transition table, so matching is case-insensitive like
+// the strings.Contains loops it replaces. It is safe for concurrent use.
+type patternMatcher struct {
+	patterns []patternMatch // Sorted by pattern
+	empty    []int          // Indices of empty patterns, which every text contains
+
+	classes     [256]uint16 // Byte -> column of transitions; 0 for bytes no pattern has
+	classCount  int
+	transitions []int32 // State*classCount + class -> next state
+	outputs     [][]int // State -> indices into patterns that end there
+}
+
+// newPatternMatcher builds a matcher for patterns. Patterns that only differ in
+// case are all reported, as each one would have matched on its own.
+func newPatternMatcher(patterns map[string]TelemetryRisk) *patternMatcher {
+	m := &patternMatcher{}
+	for pattern, risk := range patterns {
+		m.patterns = append(m.patterns, patternMatch{pattern: pattern, risk: risk})
+	}
+	sort.Slice(m.patterns, func(i, j int) bool {
+		return m.patterns[i].pattern < m.patterns[j].pattern
+	})
+
+	lowerPatterns := make([]string, len(m.patterns))
+	m.classCount = 1
+	for i, match := range m.patterns {
+		lowerPatterns[i] = strings.ToLower(match.pattern)
+		for j := 0; j < len(lowerPatterns[i]); j++ {
+			if b := lowerPatterns[i][j]; m.classes[b] == 0 {
+				m.classes[b] = uint16(m.classCount)
+				m.classCount++
+			}
+		}
+	}
+
+	m.build(lowerPatterns)
+	return m
+}
+
+// build compiles the lowercase patterns into the transition table: a trie whose
+// missing edges are filled in from the longest proper suffix state, so matching
+// never has to follow failure links
+func (m *patternMatcher) build(lowerPatterns []string) {
+	// The trie; -1 marks a missing edge
+	newState := func() int32 {
+		for c := 0; c < m.classCount; c++ {
+			m.transitions = append(m.transitions, -1)
+		}
+		m.outputs = append(m.outputs, nil)
+		return int32(len(m.outputs) - 1)
+	}
+	newState()
+
+	for i, pattern := range lowerPatterns {
+		if pattern == "" {
+			m.empty = append(m.empty, i)
+			continue
+		}
+		state := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			edge := int(state)*m.classCount + int(m.classes[pattern[j]])
+			if m.transitions[edge] < 0 {
+				next := newState()
+				m.transitions[edge] = next
+			}
+			state = m.transitions[edge]
+		}
+		m.outputs[state] = append(m.outputs[state], i)
+	}
+
+	// Breadth-first, so each state's suffix state is complete before it is used
+	fail := make([]int32, len(m.outputs))
+	var queue []int32
+	for c := 0; c < m.classCount; c++ {
+		if next := m.transitions[c]; next > 0 {
+			queue = append(queue, next)
+		} else {
+			m.transitions[c] = 0
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.outputs[state] = append(m.outputs[state], m.outputs[fail[state]]...)
+
+		for c := 0; c < m.classCount; c++ {
+			edge := int(state)*m.classCount + c
+			suffixNext := m.transitions[int(fail[state])*m.classCount+c]
+			if next := m.transitions[edge]; next > 0 {
+				fail[next] = suffixNext
+				queue = append(queue, next)
+			} else {
+				m.transitions[edge] = suffixNext
+			}
+		}
+	}
+}
+
+// appendMatches appends every pattern that lowerTexts contain to dst, once per
+// pattern even if several texts contain it, in pattern order. The texts must
+// already be lowercase.
+func (m *patternMatcher) appendMatches(dst []patternMatch, lowerTexts ...string) []patternMatch {
+	var found []bool
+	mark := func(i int) {
+		if found == nil {
+			found = make([]bool, len(m.patterns))
+		}
+		found[i] = true
+	}
+	for _, i := range m.empty {
+		mark(i)
+	}
+
+	for _, text := range lowerTexts {
+		state := int32(0)
+		for j := 0; j < len(text); j++ {
+			state = m.transitions[int(state)*m.classCount+int(m.classes[text[j]])]
+			for _, i := range m.outputs[state] {
+				mark(i)
+			}
+		}
+	}
+
+	for i := range found {
+		if found[i] {
+			dst = append(dst, m.patterns[i])
+		}
+	}
+	return dst
+}
+
+// highestRisk returns the highest risk of the patterns lowerTexts contain, or
+// TelemetryRiskNone when there is no match
+func (m *patternMatcher) highestRisk(lowerTexts ...string) TelemetryRisk {
+	risk := TelemetryRiskNone
+	for _, i := range m.empty {
+		if m.patterns[i].risk > risk {
+			risk = m.patterns[i].risk
+		}
+	}
+
+	for _, text := range lowerTexts {
+		state := int32(0)
+		for j := 0; j < len(text); j++ {
+			state = m.transitions[int(state)*m.classCount+int(m.classes[text[j]])]
+			for _, i := range m.outputs[state] {
+				if m.patterns[i].risk > risk {
+					risk = m.patterns[i].risk
+				}
+			}
+		}
+	}
+	return risk
+}
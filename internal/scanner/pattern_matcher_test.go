@@ -0,0 +1,282 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// legacyKeyRiskMatches is the strings.Contains loop keyRiskMatches used before
+// patternMatcher, kept as the reference for the differential tests
+func legacyKeyRiskMatches(patterns map[string]TelemetryRisk, key, fullPath string, value interface{}) []patternMatch {
+	lowerKey := strings.ToLower(key)
+	lowerPath := strings.ToLower(fullPath)
+	lowerValue := ""
+	if valueStr, ok := value.(string); ok {
+		lowerValue = strings.ToLower(valueStr)
+	}
+
+	var matches []patternMatch
+	for pattern, risk := range patterns {
+		lowerPattern := strings.ToLower(pattern)
+		if strings.Contains(lowerKey, lowerPattern) ||
+			strings.Contains(lowerPath, lowerPattern) ||
+			(lowerValue != "" && strings.Contains(lowerValue, lowerPattern)) {
+			matches = append(matches, patternMatch{pattern: pattern, risk: risk})
+		}
+	}
+	return matches
+}
+
+// legacySettingsFileRisk is the ExtensionSettingsScanner.assessFileRisk loop
+// used before settingsFileMatcher
+func legacySettingsFileRisk(fileName, filePath string) TelemetryRisk {
+	lowerName := strings.ToLower(fileName)
+	lowerPath := strings.ToLower(filePath)
+	for _, level := range []struct {
+		patterns []string
+		risk     TelemetryRisk
+	}{
+		{[]string{"telemetry", "analytics", "tracking", "usage", "metrics"}, TelemetryRiskHigh},
+		{[]string{"crash", "error", "log", "diagnostic", "performance"}, TelemetryRiskMedium},
+		{[]string{"cache", "temp", "config", "settings", "preferences"}, TelemetryRiskLow},
+	} {
+		for _, pattern := range level.patterns {
+			if strings.Contains(lowerName, pattern) || strings.Contains(lowerPath, pattern) {
+				return level.risk
+			}
+		}
+	}
+	return TelemetryRiskNone
+}
+
+// matcherCorpus returns keys, paths and values resembling extension storage:
+// every pattern in several casings and surroundings, overlapping patterns and
+// text without any pattern
+func matcherCorpus(patterns map[string]TelemetryRisk) [][3]string {
+	corpus := [][3]string{
+		{"", "", ""},
+		{"workbench.view.explorer", "state.vscdb", "visible"},
+		{"sessionIdmachineId", "globalStorage/augment.vscode-augment", "deviceid-1234"},
+		{"TELEMETRY.MACHINEID", "/home/user/.config/Code/User", "analyticsData"},
+		{"recentFilesAndSearchHistory", "workspaceStorage/abc/state.json", ""},
+		{"usageStatsusageStats", "a/b/c", "tracking enabled"},
+		{"errorLogsCrashReportsDebugInfo", "logs/main.log", "null"},
+		{"ümlautSessionId", "C:\\Users\\ÄÖÜ\\AppData\\Roaming\\Code", "Geräte-ID machineId"},
+		{"m", "ma", "mac"},
+		{"machineI", "achineId", "machine Id"},
+	}
+	for pattern := range patterns {
+		corpus = append(corpus,
+			[3]string{pattern, "", ""},
+			[3]string{"prefix." + strings.ToUpper(pattern) + ".suffix", "", ""},
+			[3]string{"key", "globalStorage/" + strings.ToLower(pattern) + "/data.json", ""},
+			[3]string{"key", "", "value with " + pattern},
+			[3]string{pattern[:len(pattern)/2], pattern[len(pattern)/2:], ""},
+		)
+	}
+	return corpus
+}
+
+func TestPatternMatcherMatchesLegacyKeyRisk(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	// Patterns only differing in case, as pattern database updates can add
+	analyzer.MergeTelemetryPatterns(map[string]TelemetryRisk{"MACHINEID": TelemetryRiskLow, "id": TelemetryRiskLow})
+
+	for _, sample := range matcherCorpus(analyzer.telemetryPatterns) {
+		key, path, value := sample[0], sample[1], sample[2]
+
+		wantRisk, wantMatches, wantReasons := explainRisk(legacyKeyRiskMatches(analyzer.telemetryPatterns, key, path, value))
+		gotRisk, gotMatches, gotReasons := explainRisk(analyzer.keyRiskMatches(key, path, value))
+		if gotRisk != wantRisk || !reflect.DeepEqual(gotMatches, wantMatches) || !reflect.DeepEqual(gotReasons, wantReasons) {
+			t.Errorf("keyRiskMatches(%q, %q, %q) = %v %v, want %v %v", key, path, value, gotRisk, gotMatches, wantRisk, wantMatches)
+		}
+
+		wantRisk, wantMatches, _ = explainRisk(legacyKeyRiskMatches(analyzer.telemetryPatterns, key, path, nil))
+		gotRisk, gotMatches, _ = explainRisk(analyzer.fileRiskMatches(key, path))
+		if gotRisk != wantRisk || !reflect.DeepEqual(gotMatches, wantMatches) {
+			t.Errorf("fileRiskMatches(%q, %q) = %v %v, want %v %v", key, path, gotRisk, gotMatches, wantRisk, wantMatches)
+		}
+	}
+}
+
+func TestPatternMatcherMatchesLegacySettingsRisk(t *testing.T) {
+	scanner := NewExtensionSettingsScanner()
+
+	for _, sample := range matcherCorpus(scanner.storageKeyPatterns) {
+		key, path, value := sample[0], sample[1], sample[2]
+
+		if got, want := scanner.assessFileRisk(key, path), legacySettingsFileRisk(key, path); got != want {
+			t.Errorf("assessFileRisk(%q, %q) = %v, want %v", key, path, got, want)
+		}
+
+		// The old loop returned whichever matching pattern map iteration reached
+		// first; the matcher settles on the highest of them
+		want := TelemetryRiskNone
+		for _, match := range legacyKeyRiskMatches(scanner.storageKeyPatterns, key, path, nil) {
+			if match.risk > want {
+				want = match.risk
+			}
+		}
+		if want == TelemetryRiskNone && legacySettingsValueMentionsTelemetry(value) {
+			want = TelemetryRiskMedium
+		}
+		if got := scanner.assessKeyRisk(key, path, value); got != want {
+			t.Errorf("assessKeyRisk(%q, %q, %q) = %v, want %v", key, path, value, got, want)
+		}
+	}
+}
+
+// legacySettingsValueMentionsTelemetry is the value check of the old assessKeyRisk
+func legacySettingsValueMentionsTelemetry(value string) bool {
+	lowerValue := strings.ToLower(value)
+	return strings.Contains(lowerValue, "telemetry") ||
+		strings.Contains(lowerValue, "analytics") ||
+		strings.Contains(lowerValue, "tracking")
+}
+
+// legacyDatabaseKeyValue is the strings.Contains loop of
+// DatabaseAnalyzer.analyzeKeyValue used before its matchers, returning the
+// entry's risk, reasons and category
+func legacyDatabaseKeyValue(da *DatabaseAnalyzer, key, value string) (TelemetryRisk, []string, string) {
+	lowerKey := strings.ToLower(key)
+	lowerValue := strings.ToLower(value)
+
+	var matches []patternMatch
+	extensionPatterns := make(map[string]bool)
+	for pattern, patternRisk := range da.telemetryKeyPatterns {
+		if strings.Contains(lowerKey, strings.ToLower(pattern)) ||
+			strings.Contains(lowerValue, strings.ToLower(pattern)) {
+			matches = append(matches, patternMatch{pattern: pattern, risk: patternRisk})
+		}
+	}
+	for pattern, patternRisk := range da.extensionPatterns {
+		if strings.Contains(lowerKey, strings.ToLower(pattern)) ||
+			strings.Contains(lowerValue, strings.ToLower(pattern)) {
+			matches = append(matches, patternMatch{pattern: pattern, risk: patternRisk})
+			extensionPatterns[pattern] = true
+		}
+	}
+
+	risk, matches, reasons := explainRisk(matches)
+	if risk == TelemetryRiskNone {
+		return risk, nil, ""
+	}
+	category := "Extension"
+	for _, match := range matches {
+		if match.risk == risk && !extensionPatterns[match.pattern] {
+			category = "Telemetry"
+			break
+		}
+	}
+	return risk, reasons, category
+}
+
+// legacyCacheFileRisk is the StorageAnalyzer.assessCacheFileRisk loop used
+// before cacheMatcher
+func legacyCacheFileRisk(sa *StorageAnalyzer, fileName, filePath string) TelemetryRisk {
+	lowerName := strings.ToLower(fileName)
+	lowerPath := strings.ToLower(filePath)
+	maxRisk := TelemetryRiskNone
+	for pattern, risk := range sa.cachePatterns {
+		if strings.Contains(lowerName, strings.ToLower(pattern)) ||
+			strings.Contains(lowerPath, strings.ToLower(pattern)) {
+			if risk > maxRisk {
+				maxRisk = risk
+			}
+		}
+	}
+	return maxRisk
+}
+
+func TestPatternMatcherMatchesLegacyDatabaseRisk(t *testing.T) {
+	analyzer := NewDatabaseAnalyzer()
+	patterns := make(map[string]TelemetryRisk)
+	for _, set := range []map[string]TelemetryRisk{analyzer.telemetryKeyPatterns, analyzer.extensionPatterns} {
+		for pattern, risk := range set {
+			patterns[pattern] = risk
+		}
+	}
+
+	for _, sample := range matcherCorpus(patterns) {
+		key, value := sample[0], sample[1]+sample[2]
+
+		wantRisk, wantReasons, wantCategory := legacyDatabaseKeyValue(analyzer, key, value)
+		entry := analyzer.analyzeKeyValue("ItemTable", key, value)
+		if entry == nil {
+			if wantRisk != TelemetryRiskNone {
+				t.Errorf("analyzeKeyValue(%q, %q) = nil, want %v %s", key, value, wantRisk, wantCategory)
+			}
+			continue
+		}
+		if entry.Risk != wantRisk || entry.Category != wantCategory || !reflect.DeepEqual(entry.Reasons, wantReasons) {
+			t.Errorf("analyzeKeyValue(%q, %q) = %v %s %v, want %v %s %v",
+				key, value, entry.Risk, entry.Category, entry.Reasons, wantRisk, wantCategory, wantReasons)
+		}
+	}
+}
+
+func TestPatternMatcherMatchesLegacyCacheRisk(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+
+	for _, sample := range matcherCorpus(analyzer.cachePatterns) {
+		name, path := sample[0], sample[1]+sample[2]
+		if got, want := analyzer.assessCacheFileRisk(name, path), legacyCacheFileRisk(analyzer, name, path); got != want {
+			t.Errorf("assessCacheFileRisk(%q, %q) = %v, want %v", name, path, got, want)
+		}
+	}
+}
+
+func TestPatternMatcherEmptyPattern(t *testing.T) {
+	matcher := newPatternMatcher(map[string]TelemetryRisk{"": TelemetryRiskLow, "abc": TelemetryRiskHigh})
+
+	matches := matcher.appendMatches(nil, "xyz")
+	if len(matches) != 1 || matches[0].pattern != "" {
+		t.Errorf("Expected the empty pattern to match any text like strings.Contains, got %v", matches)
+	}
+	if risk := matcher.highestRisk("xxabcxx"); risk != TelemetryRiskHigh {
+		t.Errorf("Expected high risk, got %v", risk)
+	}
+}
+
+// benchmarkKeys are storage keys, paths and values as a scan sees them
+var benchmarkKeys = [][3]string{
+	{"workbench.panel.markers.hidden", "/home/user/.config/Code/User/globalStorage/state.vscdb", "[]"},
+	{"telemetry.machineId", "/home/user/.config/Code/User/globalStorage/storage.json", "4f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f"},
+	{"augment.sessionHistory[12].request", "/home/user/.config/Code/User/globalStorage/augment.vscode-augment/state.json", "Refactor the parser for better error messages"},
+	{"editor.fontSize", "/home/user/.config/Code/User/workspaceStorage/1a2b3c/state.json", "14"},
+}
+
+func BenchmarkKeyRiskMatches(b *testing.B) {
+	analyzer := NewStorageAnalyzer()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sample := benchmarkKeys[i%len(benchmarkKeys)]
+		analyzer.keyRiskMatches(sample[0], sample[1], sample[2])
+	}
+}
+
+func BenchmarkKeyRiskMatchesLegacy(b *testing.B) {
+	analyzer := NewStorageAnalyzer()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sample := benchmarkKeys[i%len(benchmarkKeys)]
+		legacyKeyRiskMatches(analyzer.telemetryPatterns, sample[0], sample[1], sample[2])
+	}
+}
+
+func BenchmarkSettingsFileRisk(b *testing.B) {
+	scanner := NewExtensionSettingsScanner()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sample := benchmarkKeys[i%len(benchmarkKeys)]
+		scanner.assessFileRisk(sample[0], sample[1])
+	}
+}
+
+func BenchmarkSettingsFileRiskLegacy(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sample := benchmarkKeys[i%len(benchmarkKeys)]
+		legacySettingsFileRisk(sample[0], sample[1])
+	}
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Pattern engines reported by TestPatterns
+const (
+	PatternEngineTelemetry = "telemetry-patterns" // Key and path patterns of the storage analyzer
+	PatternEngineValue     = "value-format"       // Value formats such as UUIDs and email addresses
+	PatternEngineCode      = "advanced-matcher"   // Context, semantic and combination rules for code
+	PatternEngineBrowser   = "browser"            // Augment patterns of browser cleaning
+)
+
+// Sources of the rules reported by TestPatterns
+const (
+	PatternSourceBuiltin = "builtin"
+	PatternSourceCustom  = "custom" // Merged without naming the file, see MergeTelemetryPatterns
+	PatternSourceConfig  = "config" // Custom Augment patterns, see SetCustomAugmentPatterns
+)
+
+// PatternRuleMatch is a pattern rule that fires for a tested value
+type PatternRuleMatch struct {
+	Engine string        `json:"engine"`
+	Rule   string        `json:"rule"`
+	Risk   TelemetryRisk `json:"risk"`
+	Source string        `json:"source"` // PatternSourceBuiltin or the pattern file the rule came from
+	Detail string        `json:"detail,omitempty"`
+}
+
+// TestPatterns runs value through the telemetry patterns, the value format checks
+// and the code rules of the advanced matcher, and returns every rule that fires,
+// highest risk first. value is tested as a storage key, path or value and as code,
+// so it can be a single key or the content of a whole file.
+func (sa *StorageAnalyzer) TestPatterns(value string) []PatternRuleMatch {
+	var matches []PatternRuleMatch
+
+	for _, match := range sa.telemetryMatcher.appendMatches(nil, strings.ToLower(value)) {
+		source := sa.patternSources[match.pattern]
+		if source == "" {
+			source = PatternSourceBuiltin
+		}
+		matches = append(matches, PatternRuleMatch{
+			Engine: PatternEngineTelemetry,
+			Rule:   match.pattern,
+			Risk:   match.risk,
+			Source: source,
+			Detail: ExplainPattern(match.pattern, match.risk).Explanation,
+		})
+	}
+
+	if risk, explanation := sa.valueMatcher.AnalyzeStorageValue("value", value); risk > TelemetryRiskNone {
+		matches = append(matches, PatternRuleMatch{
+			Engine: PatternEngineValue,
+			Rule:   "value format",
+			Risk:   risk,
+			Source: PatternSourceBuiltin,
+			Detail: explanation,
+		})
+	}
+
+	for _, match := range sa.valueMatcher.AnalyzeCode(value, "") {
+		source := PatternSourceBuiltin
+		if match.Category == customAugmentContext {
+			source = PatternSourceConfig
+		}
+		matches = append(matches, PatternRuleMatch{
+			Engine: PatternEngineCode,
+			Rule:   fmt.Sprintf("%s: %s", match.Category, match.Pattern),
+			Risk:   match.Risk,
+			Source: source,
+			Detail: fmt.Sprintf("line %d matched %q", match.Line, match.Match),
+		})
+	}
+
+	SortPatternRuleMatches(matches)
+	return matches
+}
+
+// SortPatternRuleMatches orders matches from highest to lowest risk, then by
+// engine and rule
+func SortPatternRuleMatches(matches []PatternRuleMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Risk != matches[j].Risk {
+			return matches[i].Risk > matches[j].Risk
+		}
+		if matches[i].Engine != matches[j].Engine {
+			return matches[i].Engine < matches[j].Engine
+		}
+		return matches[i].Rule < matches[j].Rule
+	})
+}
@@ -1,7 +1,9 @@
 package scanner
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewConfigAnalyzer(t *testing.T) {
@@ -110,6 +112,63 @@ function activate(context) {
 	}
 }
 
+func TestAdvancedPatternMatcherAnalyzeStorageValue(t *testing.T) {
+	matcher := NewAdvancedPatternMatcher()
+
+	tests := []struct {
+		value interface{}
+		risk  TelemetryRisk
+	}{
+		{"machine-3f2a9c1e7b6d4a05", TelemetryRiskCritical},
+		{"123e4567-e89b-12d3-a456-426614174000", TelemetryRiskHigh},
+		{"{123E4567-E89B-12D3-A456-426614174000}", TelemetryRiskHigh},
+		{"jane.doe@example.com", TelemetryRiskHigh},
+		{"dc.services.visualstudio.com", TelemetryRiskHigh},
+		{"https://api.segment.io/v1/track", TelemetryRiskHigh},
+		{"2024-03-01T12:00:00Z", TelemetryRiskLow},
+		{"2024-03-01T12:00:00.123+01:00", TelemetryRiskLow},
+		{"github.com", TelemetryRiskNone},
+		{"dark-theme", TelemetryRiskNone},
+		{"2024-03-01", TelemetryRiskNone},
+		{42.0, TelemetryRiskNone},
+		{nil, TelemetryRiskNone},
+	}
+
+	for _, tt := range tests {
+		risk, explanation := matcher.AnalyzeStorageValue("clientContext.id", tt.value)
+		if risk != tt.risk {
+			t.Errorf("AnalyzeStorageValue(%v) risk = %s, want %s", tt.value, risk, tt.risk)
+		}
+		if (explanation != "") != (tt.risk > TelemetryRiskNone) {
+			t.Errorf("AnalyzeStorageValue(%v) explanation = %q", tt.value, explanation)
+		}
+		if tt.risk > TelemetryRiskNone && !strings.Contains(explanation, `"clientContext.id"`) {
+			t.Errorf("Expected the explanation to name the key, got %q", explanation)
+		}
+	}
+}
+
+func TestStorageAnalyzerElevatesRiskForSuspiciousValues(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	storage := &ExtensionStorage{}
+	data := map[string]interface{}{
+		"clientContext": map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000"},
+		"theme":         "dark",
+	}
+	analyzer.analyzeJSONData(data, "state.json", "", &mockFileInfo{name: "state.json", modTime: time.Now()}, storage)
+
+	if len(storage.StorageItems) != 1 {
+		t.Fatalf("Expected only the UUID value to be reported, got %+v", storage.StorageItems)
+	}
+	item := storage.StorageItems[0]
+	if item.Key != "clientContext.id" || item.Risk != TelemetryRiskHigh {
+		t.Errorf("Expected clientContext.id at high risk, got %s at %s", item.Key, item.Risk)
+	}
+	if len(item.Reasons) != 1 || !strings.Contains(item.Reasons[0], "UUID") {
+		t.Errorf("Expected the value format as the reason, got %v", item.Reasons)
+	}
+}
+
 func TestPatternMatcherStatistics(t *testing.T) {
 	matcher := NewAdvancedPatternMatcher()
 	
@@ -185,4 +244,14 @@ func TestGetRiskColor(t *testing.T) {
 			t.Errorf("GetRiskColor(%v) = %s, want %s", test.risk, got, test.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestDatabaseAnalyzerCursorTelemetryKeys(t *testing.T) {
+	analyzer := NewDatabaseAnalyzer()
+
+	for _, key := range []string{"cursor.sessionId", "cursor.userId", "cursor_session_id", "cursor.telemetry.enabled"} {
+		entry := analyzer.analyzeKeyValue("ItemTable", key, "0f8e0e4a")
+		if entry == nil || entry.Risk < TelemetryRiskHigh || entry.Category != "Telemetry" {
+			t.Errorf("Expected %s to be high-risk telemetry, got %+v", key, entry)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestAnalyzeStorageReportsPhaseDurations(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	storageDir := filepath.Join(home, ".config", "Code", "User", "globalStorage", "ms-python.python")
+	mkdirAll(t, storageDir)
+	if err := os.WriteFile(filepath.Join(storageDir, "telemetry.json"), []byte(`{"machineId": "abc"}`), 0644); err != nil {
+		t.Fatalf("Failed to write storage: %v", err)
+	}
+
+	// Every reading of the clock is one second later than the last
+	current := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	utils.SetClock(func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	})
+	defer utils.SetClock(nil)
+
+	result, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("ms-python.python")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+
+	// A single-extension scan skips cache and temp files
+	want := PhaseDurations{GlobalStorage: time.Second, WorkspaceStorage: time.Second}
+	if result.PhaseDurations != want {
+		t.Errorf("Expected phase durations %+v, got %+v", want, result.PhaseDurations)
+	}
+	if result.ScanDuration != 5*time.Second {
+		t.Errorf("Expected a scan duration of 5s, got %s", result.ScanDuration)
+	}
+}
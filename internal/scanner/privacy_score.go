@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privacy score bands used for ratings and report badges
+const (
+	PrivacyScorePoorBelow = 40 // Scores below are rated poor
+	PrivacyScoreGoodAbove = 70 // Scores above are rated good
+)
+
+// privacyPenalties are the points deducted per storage item or setting of each risk
+var privacyPenalties = map[TelemetryRisk]int{
+	TelemetryRiskCritical: 20,
+	TelemetryRiskHigh:     10,
+	TelemetryRiskMedium:   5,
+	TelemetryRiskLow:      2,
+}
+
+// Bonus points for an extension that cannot phone home
+const (
+	telemetryOffBonus = 5 // telemetry.telemetryLevel is "off"
+	noEndpointsBonus  = 5 // A deep scan found no telemetry endpoints in the bundle
+)
+
+// PrivacyScore rates the privacy of one extension from 0 (worst) to 100
+type PrivacyScore struct {
+	Overall               int            `json:"overall"`
+	BreakdownByCategory   map[string]int `json:"breakdown_by_category"` // Points deducted per data category
+	PenaltyReasons        []string       `json:"penalty_reasons"`
+	RecommendationSummary string         `json:"recommendation_summary"`
+}
+
+// Rating returns "poor", "fair" or "good" for the band the overall score falls in
+func (ps PrivacyScore) Rating() string {
+	switch {
+	case ps.Overall < PrivacyScorePoorBelow:
+		return "poor"
+	case ps.Overall > PrivacyScoreGoodAbove:
+		return "good"
+	default:
+		return "fair"
+	}
+}
+
+// PrivacyScoreCalculator scores extensions by the telemetry they store and the
+// telemetry settings that apply to them
+type PrivacyScoreCalculator struct {
+	networkAnalysis *NetworkAnalysis
+}
+
+// NewPrivacyScoreCalculator creates a new privacy score calculator
+func NewPrivacyScoreCalculator() *PrivacyScoreCalculator {
+	return &PrivacyScoreCalculator{}
+}
+
+// SetNetworkAnalysis provides the deep scan results used for the no-endpoints bonus.
+// Without them the bonus is never awarded, since no endpoints were looked for.
+func (psc *PrivacyScoreCalculator) SetNetworkAnalysis(analysis *NetworkAnalysis) {
+	psc.networkAnalysis = analysis
+}
+
+// Score starts an extension at 100 and deducts 20, 10, 5 or 2 points for every
+// critical, high, medium or low risk storage item and setting. A telemetry.telemetryLevel
+// setting of "off" and a bundle without telemetry endpoints each add 5 points back.
+// The result is kept between 0 and 100.
+func (psc *PrivacyScoreCalculator) Score(storage ExtensionStorage, settings []ExtensionSetting) PrivacyScore {
+	score := PrivacyScore{
+		Overall:             100,
+		BreakdownByCategory: make(map[string]int),
+		PenaltyReasons:      make([]string, 0),
+	}
+
+	for _, item := range storage.StorageItems {
+		psc.penalize(&score, item.Risk, item.Category, fmt.Sprintf("stores %q", item.Key))
+	}
+
+	telemetryOff := false
+	for _, setting := range settings {
+		if setting.SettingKey == "telemetry.telemetryLevel" {
+			telemetryOff = fmt.Sprint(setting.SettingValue) == "off"
+			continue
+		}
+		psc.penalize(&score, setting.Risk, setting.Category, fmt.Sprintf("setting %s is %v", setting.SettingKey, setting.SettingValue))
+	}
+
+	if telemetryOff {
+		score.Overall += telemetryOffBonus
+	}
+	if psc.networkAnalysis != nil && !psc.hasEndpoints(storage.ExtensionID) {
+		score.Overall += noEndpointsBonus
+	}
+
+	score.Overall = max(0, min(score.Overall, 100))
+	score.RecommendationSummary = privacyRecommendation(score, telemetryOff)
+	return score
+}
+
+// penalize deducts the points for an item of the given risk and records why
+func (psc *PrivacyScoreCalculator) penalize(score *PrivacyScore, risk TelemetryRisk, category, reason string) {
+	penalty := privacyPenalties[risk]
+	if penalty == 0 {
+		return
+	}
+	if category == "" {
+		category = "uncategorized"
+	}
+
+	score.Overall -= penalty
+	score.BreakdownByCategory[category] += penalty
+	score.PenaltyReasons = append(score.PenaltyReasons, fmt.Sprintf("%s risk: %s (-%d)", risk, reason, penalty))
+}
+
+// hasEndpoints reports whether the deep scan found telemetry endpoints in the
+// bundle of extensionID. Findings start with the "<id>-<version>" directory.
+func (psc *PrivacyScoreCalculator) hasEndpoints(extensionID string) bool {
+	prefix := strings.ToLower(extensionID) + "-"
+	for _, finding := range psc.networkAnalysis.Findings {
+		if strings.HasPrefix(strings.ToLower(finding.File), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// privacyRecommendation summarizes what would raise a score
+func privacyRecommendation(score PrivacyScore, telemetryOff bool) string {
+	var summary string
+	switch score.Rating() {
+	case "poor":
+		summary = "Poor privacy: clean this extension's storage and disable its telemetry settings"
+	case "fair":
+		summary = "Fair privacy: review the flagged items and clean telemetry storage"
+	default:
+		summary = "Good privacy: little telemetry data stored"
+	}
+	if !telemetryOff {
+		summary += "; set telemetry.telemetryLevel to \"off\""
+	}
+	return summary
+}
+
+// settingsForExtension returns the settings that configure extensionID. Setting keys
+// are namespaced by the extension name ("eslint." for dbaeumer.vscode-eslint) or the
+// publisher ("redhat." for redhat.vscode-yaml), so both are matched.
+func settingsForExtension(extensionID string, settings []ExtensionSetting) []ExtensionSetting {
+	publisher, name, _ := strings.Cut(strings.ToLower(extensionID), ".")
+	namespaces := map[string]bool{publisher: true, name: true, strings.TrimPrefix(name, "vscode-"): true}
+
+	var matched []ExtensionSetting
+	for _, setting := range settings {
+		namespace, _, _ := strings.Cut(strings.ToLower(setting.SettingKey), ".")
+		if namespaces[namespace] {
+			matched = append(matched, setting)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrivacyScoreCalculatorScore(t *testing.T) {
+	storage := ExtensionStorage{
+		ExtensionID: "augment.vscode-augment",
+		StorageItems: []StorageDataItem{
+			{Key: "machineId", Risk: TelemetryRiskCritical, Category: "identifiers"},
+			{Key: "sessionId", Risk: TelemetryRiskHigh, Category: "identifiers"},
+			{Key: "crashReports", Risk: TelemetryRiskMedium, Category: "diagnostics"},
+			{Key: "lastUsed", Risk: TelemetryRiskLow},
+			{Key: "theme", Risk: TelemetryRiskNone, Category: "preferences"},
+		},
+	}
+	settings := []ExtensionSetting{
+		{SettingKey: "augment.telemetry.enabled", SettingValue: true, Risk: TelemetryRiskHigh, Category: "telemetry"},
+	}
+
+	score := NewPrivacyScoreCalculator().Score(storage, settings)
+
+	// 100 - 20 - 10 - 5 - 2 - 10
+	if score.Overall != 53 {
+		t.Errorf("Expected score 53, got %d", score.Overall)
+	}
+	if score.Rating() != "fair" {
+		t.Errorf("Expected a fair rating, got %s", score.Rating())
+	}
+	wantBreakdown := map[string]int{"identifiers": 30, "diagnostics": 5, "uncategorized": 2, "telemetry": 10}
+	for category, want := range wantBreakdown {
+		if score.BreakdownByCategory[category] != want {
+			t.Errorf("Expected %d points deducted for %s, got %d", want, category, score.BreakdownByCategory[category])
+		}
+	}
+	if len(score.PenaltyReasons) != 5 {
+		t.Errorf("Expected 5 penalty reasons, got %v", score.PenaltyReasons)
+	}
+	if !strings.Contains(score.RecommendationSummary, "telemetry.telemetryLevel") {
+		t.Errorf("Expected the summary to recommend turning telemetry off, got %q", score.RecommendationSummary)
+	}
+}
+
+func TestPrivacyScoreCalculatorBonuses(t *testing.T) {
+	storage := ExtensionStorage{
+		ExtensionID:  "ms-python.python",
+		StorageItems: []StorageDataItem{{Key: "userId", Risk: TelemetryRiskHigh}},
+	}
+	telemetryOff := []ExtensionSetting{{SettingKey: "telemetry.telemetryLevel", SettingValue: "off"}}
+
+	calculator := NewPrivacyScoreCalculator()
+	if score := calculator.Score(storage, telemetryOff); score.Overall != 95 {
+		t.Errorf("Expected the telemetry bonus without a deep scan, got %d", score.Overall)
+	}
+
+	calculator.SetNetworkAnalysis(&NetworkAnalysis{Findings: []NetworkEndpointFinding{
+		{File: "other.extension-1.0.0/out/extension.js", URL: "https://dc.services.visualstudio.com/v2/track"},
+	}})
+	if score := calculator.Score(storage, telemetryOff); score.Overall != 100 {
+		t.Errorf("Expected both bonuses, got %d", score.Overall)
+	}
+
+	calculator.SetNetworkAnalysis(&NetworkAnalysis{Findings: []NetworkEndpointFinding{
+		{File: "ms-python.python-2024.1.0/out/client.js", URL: "https://dc.services.visualstudio.com/v2/track"},
+	}})
+	if score := calculator.Score(storage, telemetryOff); score.Overall != 95 {
+		t.Errorf("Expected no endpoint bonus when the bundle calls telemetry endpoints, got %d", score.Overall)
+	}
+}
+
+func TestPrivacyScoreBounds(t *testing.T) {
+	items := make([]StorageDataItem, 10)
+	for i := range items {
+		items[i] = StorageDataItem{Key: "telemetryData", Risk: TelemetryRiskCritical}
+	}
+
+	score := NewPrivacyScoreCalculator().Score(ExtensionStorage{StorageItems: items}, nil)
+	if score.Overall != 0 || score.Rating() != "poor" {
+		t.Errorf("Expected the score to stop at 0 and rate poor, got %d (%s)", score.Overall, score.Rating())
+	}
+
+	tests := []struct {
+		overall int
+		want    string
+	}{
+		{39, "poor"},
+		{40, "fair"},
+		{70, "fair"},
+		{71, "good"},
+	}
+	for _, tt := range tests {
+		if got := (PrivacyScore{Overall: tt.overall}).Rating(); got != tt.want {
+			t.Errorf("Rating of %d: expected %s, got %s", tt.overall, tt.want, got)
+		}
+	}
+}
+
+func TestSettingsForExtension(t *testing.T) {
+	settings := []ExtensionSetting{
+		{SettingKey: "eslint.autoFixOnSave"},
+		{SettingKey: "redhat.telemetry.enabled"},
+		{SettingKey: "python.analysis.autoImportCompletions"},
+	}
+
+	tests := []struct {
+		extensionID string
+		want        string
+	}{
+		{"dbaeumer.vscode-eslint", "eslint.autoFixOnSave"},
+		{"redhat.vscode-yaml", "redhat.telemetry.enabled"},
+		{"ms-python.python", "python.analysis.autoImportCompletions"},
+	}
+	for _, tt := range tests {
+		matched := settingsForExtension(tt.extensionID, settings)
+		if len(matched) != 1 || matched[0].SettingKey != tt.want {
+			t.Errorf("%s: expected only %s, got %+v", tt.extensionID, tt.want, matched)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// recentlyOpenedPathsKey is the ItemTable key VS Code keeps its File > Open Recent list under
+const recentlyOpenedPathsKey = "history.recentlyOpenedPathsList"
+
+// recentlyOpenedEntry is one entry of the recently opened list. Folders carry a
+// folderUri, multi-root workspaces a workspace.configPath and files a fileUri.
+type recentlyOpenedEntry struct {
+	FolderURI string `json:"folderUri"`
+	Workspace *struct {
+		ConfigPath string `json:"configPath"`
+	} `json:"workspace"`
+}
+
+// recentWorkspaceSettingsPaths returns the .vscode/settings.json files of the
+// folders VS Code recently opened, most recent first. Folders come from the
+// recently opened list in the state database and from the workspace.json files in
+// workspaceStorage; remote workspaces and folders without settings are skipped.
+func recentWorkspaceSettingsPaths(paths *utils.VSCodePaths) []string {
+	var settingsPaths []string
+	for _, folder := range recentWorkspaceFolders(paths) {
+		settingsPath := filepath.Join(folder, ".vscode", "settings.json")
+		if _, err := os.Stat(settingsPath); err == nil {
+			settingsPaths = append(settingsPaths, settingsPath)
+		}
+	}
+	return settingsPaths
+}
+
+// recentWorkspaceFolders returns the local folders VS Code recently opened, without duplicates
+func recentWorkspaceFolders(paths *utils.VSCodePaths) []string {
+	var candidates []string
+
+	if dbPaths, err := paths.DBPaths(); err == nil {
+		for _, dbPath := range dbPaths {
+			if uris, err := readRecentlyOpenedURIs(dbPath); err == nil {
+				candidates = append(candidates, uris...)
+			}
+		}
+	}
+
+	if workspaceStoragePaths, err := paths.WorkspaceStoragePaths(); err == nil {
+		for _, workspaceStoragePath := range workspaceStoragePaths {
+			for _, hashPath := range listSubdirectories(workspaceStoragePath) {
+				location, err := ResolveWorkspaceLocation(hashPath)
+				if err != nil || location.Remote || !location.Exists {
+					continue
+				}
+				candidates = append(candidates, location.Path)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var folders []string
+	for _, candidate := range candidates {
+		for _, folder := range expandWorkspaceFolders(candidate) {
+			folder = filepath.Clean(folder)
+			if !seen[folder] {
+				seen[folder] = true
+				folders = append(folders, folder)
+			}
+		}
+	}
+	return folders
+}
+
+// readRecentlyOpenedURIs returns the local paths of the folders and workspace files
+// in the recently opened list of the state database at dbPath
+func readRecentlyOpenedURIs(dbPath string) ([]string, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var value string
+	err = db.QueryRow("SELECT value FROM ItemTable WHERE key = ?", recentlyOpenedPathsKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", recentlyOpenedPathsKey, err)
+	}
+
+	var recent struct {
+		Entries []recentlyOpenedEntry `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(value), &recent); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", recentlyOpenedPathsKey, err)
+	}
+
+	var paths []string
+	for _, entry := range recent.Entries {
+		rawURI := entry.FolderURI
+		if rawURI == "" && entry.Workspace != nil {
+			rawURI = entry.Workspace.ConfigPath
+		}
+		if rawURI == "" {
+			continue // Recently opened files have no workspace settings
+		}
+
+		if path, local, err := workspaceURIToPath(rawURI); err == nil && local {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// expandWorkspaceFolders returns the folders of a .code-workspace file, resolved
+// against the file's directory, or the path itself when it is a folder
+func expandWorkspaceFolders(path string) []string {
+	if !strings.EqualFold(filepath.Ext(path), ".code-workspace") {
+		return []string{path}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var workspace struct {
+		Folders []struct {
+			Path string `json:"path"`
+			URI  string `json:"uri"`
+		} `json:"folders"`
+	}
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		return nil // Workspace files with comments are not plain JSON
+	}
+
+	var folders []string
+	for _, folder := range workspace.Folders {
+		switch {
+		case folder.Path != "" && filepath.IsAbs(folder.Path):
+			folders = append(folders, folder.Path)
+		case folder.Path != "":
+			folders = append(folders, filepath.Join(filepath.Dir(path), folder.Path))
+		case folder.URI != "":
+			if folderPath, local, err := workspaceURIToPath(folder.URI); err == nil && local {
+				folders = append(folders, folderPath)
+			}
+		}
+	}
+	return folders
+}
@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestRecentWorkspaceSettingsPaths(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	userDir := filepath.Join(home, ".config", "Code", "User")
+
+	// Folders with settings from each source, one without settings and one
+	// guessable folder that must not be found without guessing
+	recentFolder := writeWorkspaceSettings(t, filepath.Join(home, "work", "recent"))
+	multiRootFolder := writeWorkspaceSettings(t, filepath.Join(home, "work", "multi", "api"))
+	storageFolder := writeWorkspaceSettings(t, filepath.Join(home, "elsewhere", "storage"))
+	noSettingsFolder := filepath.Join(home, "work", "plain")
+	mkdirAll(t, noSettingsFolder)
+	writeWorkspaceSettings(t, filepath.Join(home, "Projects", "guessed"))
+
+	workspaceFile := filepath.Join(home, "work", "multi", "multi.code-workspace")
+	if err := os.WriteFile(workspaceFile, []byte(`{"folders": [{"path": "api"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write workspace file: %v", err)
+	}
+
+	recent, err := json.Marshal(map[string]interface{}{
+		"entries": []map[string]interface{}{
+			{"folderUri": "file://" + filepath.ToSlash(recentFolder)},
+			{"workspace": map[string]string{"id": "abc", "configPath": "file://" + filepath.ToSlash(workspaceFile)}},
+			{"fileUri": "file://" + filepath.ToSlash(filepath.Join(home, "notes.txt"))},
+			{"folderUri": "vscode-remote://ssh-remote%2Bhost/srv/app"},
+			{"folderUri": "file://" + filepath.ToSlash(noSettingsFolder)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode recently opened list: %v", err)
+	}
+	createStateDB(t, filepath.Join(userDir, "globalStorage", "state.vscdb"), string(recent))
+
+	for hash, folder := range map[string]string{"hash1": storageFolder, "hash2": recentFolder} {
+		hashDir := filepath.Join(userDir, "workspaceStorage", hash)
+		mkdirAll(t, hashDir)
+		descriptor := `{"folder": "file://` + filepath.ToSlash(folder) + `"}`
+		if err := os.WriteFile(filepath.Join(hashDir, "workspace.json"), []byte(descriptor), 0644); err != nil {
+			t.Fatalf("Failed to write workspace.json: %v", err)
+		}
+	}
+
+	want := []string{
+		filepath.Join(recentFolder, ".vscode", "settings.json"),
+		filepath.Join(multiRootFolder, ".vscode", "settings.json"),
+		filepath.Join(storageFolder, ".vscode", "settings.json"),
+	}
+
+	analyzer := NewConfigAnalyzerWithResolver(resolver)
+	analyzer.SetGuessWorkspaceFolders(true) // Recent workspaces were found, so nothing is guessed
+	if got := analyzer.getWorkspaceSettingsPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigAnalyzer: expected %v, got %v", want, got)
+	}
+	if got := NewExtensionSettingsScannerWithResolver(resolver).getWorkspaceSettingsPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtensionSettingsScanner: expected %v, got %v", want, got)
+	}
+}
+
+func TestGuessWorkspaceFoldersFallback(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	guessed := writeWorkspaceSettings(t, filepath.Join(home, "Projects", "guessed"))
+
+	analyzer := NewConfigAnalyzerWithResolver(resolver)
+	if got := analyzer.getWorkspaceSettingsPaths(); len(got) != 0 {
+		t.Errorf("Expected no workspace settings without guessing, got %v", got)
+	}
+
+	analyzer.SetGuessWorkspaceFolders(true)
+	want := []string{filepath.Join(guessed, ".vscode", "settings.json")}
+	if got := analyzer.getWorkspaceSettingsPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected guessed settings %v, got %v", want, got)
+	}
+}
+
+// writeWorkspaceSettings creates folder with an empty .vscode/settings.json and returns folder
+func writeWorkspaceSettings(t *testing.T, folder string) string {
+	t.Helper()
+	mkdirAll(t, filepath.Join(folder, ".vscode"))
+	if err := os.WriteFile(filepath.Join(folder, ".vscode", "settings.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatalf("Failed to write workspace settings: %v", err)
+	}
+	return folder
+}
+
+// createStateDB creates a state database whose recently opened list is recent
+func createStateDB(t *testing.T, dbPath, recent string) {
+	t.Helper()
+	mkdirAll(t, filepath.Dir(dbPath))
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+		t.Fatalf("Failed to create ItemTable: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO ItemTable (key, value) VALUES (?, ?)", recentlyOpenedPathsKey, recent); err != nil {
+		t.Fatalf("Failed to insert recently opened list: %v", err)
+	}
+}
+
+func mkdirAll(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+}
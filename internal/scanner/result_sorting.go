@@ -0,0 +1,179 @@
+package scanner
+
+import "sort"
+
+// Result ordering
+//
+// All analyzers sort their result slices before returning so that JSON output is
+// byte-for-byte stable between runs over the same data:
+//
+//	extensions / extension storages   by extension ID, then path
+//	workspaces                        by workspace hash
+//	storage items and findings        by file, then key
+//	cache directories / files         by path
+//	temp files                        by path
+//	correlations                      by risk (highest first), then data type, then hash
+//	size limit violations             by extension ID
+//	secret store entries              by service, then account
+//	skipped extensions                by extension ID
+//	top telemetry items / extensions  by size (largest first), then extension ID
+//	database entries                  by table, then key
+
+// sortStorageAnalysisResult sorts every slice in a storage analysis result
+func sortStorageAnalysisResult(result *StorageAnalysisResult) {
+	sortExtensionStorages(result.GlobalStorageAnalysis.ExtensionStorages)
+
+	workspaces := result.WorkspaceStorageAnalysis.WorkspaceStorages
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		return workspaces[i].WorkspaceHash < workspaces[j].WorkspaceHash
+	})
+	for i := range workspaces {
+		sortExtensionStorages(workspaces[i].ExtensionStorages)
+	}
+
+	cacheDirs := result.CacheAnalysis.CacheDirectories
+	sort.SliceStable(cacheDirs, func(i, j int) bool {
+		return cacheDirs[i].Path < cacheDirs[j].Path
+	})
+	for i := range cacheDirs {
+		files := cacheDirs[i].CacheFiles
+		sort.SliceStable(files, func(a, b int) bool {
+			return files[a].Path < files[b].Path
+		})
+	}
+
+	tempFiles := result.TempFileAnalysis.TempFiles
+	sort.SliceStable(tempFiles, func(i, j int) bool {
+		return tempFiles[i].Path < tempFiles[j].Path
+	})
+
+	sortCrossExtensionData(result.CrossExtensionData)
+
+	violations := result.SizeLimitViolations
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].ExtensionID < violations[j].ExtensionID
+	})
+
+	retention := result.RetentionViolations
+	sort.SliceStable(retention, func(i, j int) bool {
+		if retention[i].ExtensionID != retention[j].ExtensionID {
+			return retention[i].ExtensionID < retention[j].ExtensionID
+		}
+		if retention[i].OverdueBy != retention[j].OverdueBy {
+			return retention[i].OverdueBy > retention[j].OverdueBy
+		}
+		return retention[i].ItemKey < retention[j].ItemKey
+	})
+
+	sortSecretEntries(result.SecretStoreAnalysis)
+	sort.Strings(result.SkippedExtensions)
+}
+
+// sortExtensionStorages sorts extension storages by ID and their items by key
+func sortExtensionStorages(storages []ExtensionStorage) {
+	sort.SliceStable(storages, func(i, j int) bool {
+		if storages[i].ExtensionID != storages[j].ExtensionID {
+			return storages[i].ExtensionID < storages[j].ExtensionID
+		}
+		return storages[i].StoragePath < storages[j].StoragePath
+	})
+
+	for i := range storages {
+		items := storages[i].StorageItems
+		sort.SliceStable(items, func(a, b int) bool {
+			if items[a].Key != items[b].Key {
+				return items[a].Key < items[b].Key
+			}
+			return items[a].Type < items[b].Type
+		})
+		sort.Strings(storages[i].DataCategories)
+	}
+}
+
+// sortCrossExtensionData sorts correlations by risk, then data type, then hash
+func sortCrossExtensionData(correlations []CrossExtensionData) {
+	for i := range correlations {
+		sort.Strings(correlations[i].ExtensionIDs)
+		sort.Strings(correlations[i].SharedKeys)
+	}
+
+	sort.SliceStable(correlations, func(i, j int) bool {
+		if correlations[i].Risk != correlations[j].Risk {
+			return correlations[i].Risk > correlations[j].Risk
+		}
+		if correlations[i].DataType != correlations[j].DataType {
+			return correlations[i].DataType < correlations[j].DataType
+		}
+		return correlations[i].CorrelationHash < correlations[j].CorrelationHash
+	})
+}
+
+// sortConfigFindings sorts configuration findings by file, then key path
+func sortConfigFindings(findings []ConfigFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Path < findings[j].Path
+	})
+}
+
+// sortDatabaseEntries sorts database entries by table, then key
+func sortDatabaseEntries(entries []DatabaseEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Table != entries[j].Table {
+			return entries[i].Table < entries[j].Table
+		}
+		return entries[i].Key < entries[j].Key
+	})
+}
+
+// sortExtensionSettings sorts extension settings by extension ID, then key
+func sortExtensionSettings(settings []ExtensionSetting) {
+	sort.SliceStable(settings, func(i, j int) bool {
+		if settings[i].ExtensionID != settings[j].ExtensionID {
+			return settings[i].ExtensionID < settings[j].ExtensionID
+		}
+		if settings[i].SettingKey != settings[j].SettingKey {
+			return settings[i].SettingKey < settings[j].SettingKey
+		}
+		return settings[i].Source < settings[j].Source
+	})
+}
+
+// sortStorageItems sorts storage items by file, then key
+func sortStorageItems(items []StorageItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].FilePath != items[j].FilePath {
+			return items[i].FilePath < items[j].FilePath
+		}
+		return items[i].Key < items[j].Key
+	})
+}
+
+// sortExtensionInfos sorts extensions by ID, then install path
+func sortExtensionInfos(extensions []ExtensionInfo) {
+	sort.SliceStable(extensions, func(i, j int) bool {
+		if extensions[i].ID != extensions[j].ID {
+			return extensions[i].ID < extensions[j].ID
+		}
+		return extensions[i].InstallPath < extensions[j].InstallPath
+	})
+}
+
+// sortFileInfos sorts discovered files by path
+func sortFileInfos(files []FileInfo) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+}
+
+// sortSecretEntries sorts secret entries by service, then account
+func sortSecretEntries(entries []SecretEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].ServiceName != entries[j].ServiceName {
+			return entries[i].ServiceName < entries[j].ServiceName
+		}
+		return entries[i].AccountName < entries[j].AccountName
+	})
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeStorageFixture creates a VS Code user data tree with several extensions
+// sharing telemetry keys under a temporary home directory
+func writeStorageFixture(t *testing.T) {
+	t.Helper()
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(homeDir, ".cache"))
+	t.Setenv("TMPDIR", t.TempDir())
+
+	userDir := filepath.Join(homeDir, ".config", "Code", "User")
+	state := map[string]interface{}{
+		"machineId":       "4f1c2a9e-shared-machine-id",
+		"sessionId":       "session-1234",
+		"telemetryUserId": "user-5678",
+		"analytics": map[string]interface{}{
+			"trackingId": "tracking-abc",
+			"deviceId":   "device-def",
+			"events":     []interface{}{"open", "close"},
+		},
+	}
+
+	extensions := []string{"zeta.telemetry", "augment.vscode-augment", "ms-python.python", "alpha.metrics"}
+	for _, ext := range extensions {
+		writeJSONFixture(t, filepath.Join(userDir, "globalStorage", ext, "telemetryData.json"), state)
+	}
+
+	for i, hash := range []string{"f00dbabe", "0badcafe", "deadbeef"} {
+		ext := extensions[i]
+		writeJSONFixture(t, filepath.Join(userDir, "workspaceStorage", hash, ext, "telemetryData.json"), state)
+	}
+}
+
+func writeJSONFixture(t *testing.T, path string, data interface{}) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create fixture directory: %v", err)
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to marshal fixture: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+}
+
+func analyzeStorageJSON(t *testing.T) []byte {
+	t.Helper()
+
+	result, err := NewStorageAnalyzer().AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage() failed: %v", err)
+	}
+	result.ScanDuration = 0
+	result.PhaseDurations = PhaseDurations{}
+	result.ScannedAt = time.Time{}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	return data
+}
+
+func TestAnalyzeStorageDeterministicOutput(t *testing.T) {
+	writeStorageFixture(t)
+
+	golden := analyzeStorageJSON(t)
+	for run := 1; run <= 5; run++ {
+		output := analyzeStorageJSON(t)
+		if !bytes.Equal(golden, output) {
+			t.Fatalf("Run %d produced different JSON than the first run", run)
+		}
+	}
+
+	var result StorageAnalysisResult
+	if err := json.Unmarshal(golden, &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+
+	var ids []string
+	for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+		ids = append(ids, storage.ExtensionID)
+
+		var keys []string
+		for _, item := range storage.StorageItems {
+			keys = append(keys, item.Key)
+		}
+		if !sort.StringsAreSorted(keys) {
+			t.Errorf("Storage items of %s are not sorted by key: %v", storage.ExtensionID, keys)
+		}
+	}
+	if !sort.StringsAreSorted(ids) || len(ids) != 4 {
+		t.Errorf("Expected 4 extension storages sorted by ID, got %v", ids)
+	}
+
+	var hashes []string
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		hashes = append(hashes, workspace.WorkspaceHash)
+	}
+	if !sort.StringsAreSorted(hashes) {
+		t.Errorf("Expected workspaces sorted by hash, got %v", hashes)
+	}
+
+	if len(result.CrossExtensionData) == 0 {
+		t.Fatal("Expected cross-extension correlations in fixture")
+	}
+	for i := 1; i < len(result.CrossExtensionData); i++ {
+		prev, cur := result.CrossExtensionData[i-1], result.CrossExtensionData[i]
+		if prev.Risk < cur.Risk {
+			t.Errorf("Correlations not sorted by risk: %s (%v) before %s (%v)",
+				prev.DataType, prev.Risk, cur.DataType, cur.Risk)
+		}
+	}
+}
+
+func TestSortCrossExtensionDataOrdering(t *testing.T) {
+	correlations := []CrossExtensionData{
+		{DataType: "b", Risk: TelemetryRiskLow, CorrelationHash: "1"},
+		{DataType: "a", Risk: TelemetryRiskLow, CorrelationHash: "2"},
+		{DataType: "z", Risk: TelemetryRiskCritical, CorrelationHash: "3", ExtensionIDs: []string{"y", "x"}},
+	}
+
+	sortCrossExtensionData(correlations)
+
+	got := fmt.Sprintf("%s,%s,%s", correlations[0].DataType, correlations[1].DataType, correlations[2].DataType)
+	if got != "z,a,b" {
+		t.Errorf("Expected order z,a,b, got %s", got)
+	}
+	if correlations[0].ExtensionIDs[0] != "x" {
+		t.Errorf("Expected extension IDs to be sorted, got %v", correlations[0].ExtensionIDs)
+	}
+}
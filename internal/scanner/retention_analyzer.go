@@ -196,7 +196,8 @@ func (ra *RetentionAnalyzer) findExplicitPolicy(extensionID, storagePath string)
 
 // parseRetentionPolicyFile parses a retention policy configuration file
 func (ra *RetentionAnalyzer) parseRetentionPolicyFile(filePath string) *RetentionPolicyInfo {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() > MaxJSONParseSize {
 		return nil
 	}
 
@@ -628,4 +629,69 @@ func (ra *RetentionAnalyzer) getItemRetentionRecommendation(item StorageDataItem
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// RetentionViolation is a storage item kept longer than its extension's
+// declared retention period
+type RetentionViolation struct {
+	ExtensionID  string        `json:"extension_id"`
+	ItemKey      string        `json:"item_key"`
+	ActualAge    time.Duration `json:"actual_age"`
+	PolicyPeriod time.Duration `json:"policy_period"`
+	OverdueBy    time.Duration `json:"overdue_by"`
+	Risk         TelemetryRisk `json:"risk"`
+}
+
+// ComputeRetentionViolations reports the items of extensionStorage that are in
+// use (AccessFrequency > 0) and older than policy.RetentionPeriod
+func (ra *RetentionAnalyzer) ComputeRetentionViolations(extensionStorage ExtensionStorage, policy RetentionPolicy) []RetentionViolation {
+	return computeRetentionViolations(extensionStorage, policy, time.Now())
+}
+
+// computeRetentionViolations reports the retention violations of extensionStorage at now
+func computeRetentionViolations(extensionStorage ExtensionStorage, policy RetentionPolicy, now time.Time) []RetentionViolation {
+	if policy.RetentionPeriod <= 0 {
+		return nil
+	}
+
+	var violations []RetentionViolation
+	for _, item := range extensionStorage.StorageItems {
+		if item.AccessFrequency <= 0 || item.LastModified.IsZero() {
+			continue
+		}
+
+		age := now.Sub(item.LastModified)
+		if age <= policy.RetentionPeriod {
+			continue
+		}
+		violations = append(violations, RetentionViolation{
+			ExtensionID:  extensionStorage.ExtensionID,
+			ItemKey:      item.Key,
+			ActualAge:    age,
+			PolicyPeriod: policy.RetentionPeriod,
+			OverdueBy:    age - policy.RetentionPeriod,
+			Risk:         item.Risk,
+		})
+	}
+	return violations
+}
+
+// checkRetentionViolations collects the retention violations of every global and
+// workspace extension storage with a declared or inferred retention policy
+func (sa *StorageAnalyzer) checkRetentionViolations(extensions []ExtensionStorage, workspaces []WorkspaceStorage) []RetentionViolation {
+	violations := make([]RetentionViolation, 0)
+	check := func(storage ExtensionStorage) {
+		if storage.RetentionPolicy.HasPolicy {
+			violations = append(violations, sa.retentionAnalyzer.ComputeRetentionViolations(storage, storage.RetentionPolicy)...)
+		}
+	}
+
+	for _, storage := range extensions {
+		check(storage)
+	}
+	for _, workspace := range workspaces {
+		for _, storage := range workspace.ExtensionStorages {
+			check(storage)
+		}
+	}
+	return violations
+}
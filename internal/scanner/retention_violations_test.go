@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeRetentionViolations(t *testing.T) {
+	const day = 24 * time.Hour
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	storage := ExtensionStorage{
+		ExtensionID: "augment.vscode-augment",
+		StorageItems: []StorageDataItem{
+			{Key: "sessionId", LastModified: now.Add(-10 * day), AccessFrequency: 1, Risk: TelemetryRiskHigh},
+			{Key: "recent", LastModified: now.Add(-2 * day), AccessFrequency: 5},
+			{Key: "unused", LastModified: now.Add(-40 * day), AccessFrequency: 0},
+			{Key: "unknownAge", AccessFrequency: 3},
+		},
+	}
+	policy := RetentionPolicy{HasPolicy: true, RetentionPeriod: 7 * day}
+
+	violations := computeRetentionViolations(storage, policy, now)
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %+v", violations)
+	}
+	v := violations[0]
+	if v.ExtensionID != "augment.vscode-augment" || v.ItemKey != "sessionId" || v.Risk != TelemetryRiskHigh {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+	if v.ActualAge != 10*day || v.PolicyPeriod != 7*day || v.OverdueBy != 3*day {
+		t.Errorf("Expected age 10d, period 7d and overdue 3d, got %v, %v and %v", v.ActualAge, v.PolicyPeriod, v.OverdueBy)
+	}
+
+	// Without a retention period nothing can be overdue
+	if v := computeRetentionViolations(storage, RetentionPolicy{HasPolicy: true}, now); len(v) != 0 {
+		t.Errorf("Expected no violations without a retention period, got %+v", v)
+	}
+}
+
+func TestCheckRetentionViolationsSkipsUndeclaredPolicies(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	item := StorageDataItem{Key: "machineId", LastModified: old, AccessFrequency: 1}
+	period := 30 * 24 * time.Hour
+
+	extensions := []ExtensionStorage{
+		{ExtensionID: "declared.extension", StorageItems: []StorageDataItem{item},
+			RetentionPolicy: RetentionPolicy{HasPolicy: true, RetentionPeriod: period}},
+		{ExtensionID: "default.extension", StorageItems: []StorageDataItem{item},
+			RetentionPolicy: RetentionPolicy{RetentionPeriod: period, PolicySource: "default"}},
+	}
+	workspaces := []WorkspaceStorage{{ExtensionStorages: []ExtensionStorage{
+		{ExtensionID: "workspace.extension", StorageItems: []StorageDataItem{item},
+			RetentionPolicy: RetentionPolicy{HasPolicy: true, RetentionPeriod: period}},
+	}}}
+
+	violations := analyzer.checkRetentionViolations(extensions, workspaces)
+	if len(violations) != 2 {
+		t.Fatalf("Expected violations for the two declared policies, got %+v", violations)
+	}
+	if violations[0].ExtensionID != "declared.extension" || violations[1].ExtensionID != "workspace.extension" {
+		t.Errorf("Unexpected violations: %+v", violations)
+	}
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// riskWeights are the weights WeightedScore gives each risk level
+var riskWeights = map[TelemetryRisk]int{
+	TelemetryRiskLow:      1,
+	TelemetryRiskMedium:   2,
+	TelemetryRiskHigh:     3,
+	TelemetryRiskCritical: 4,
+}
+
+// RiskAggregator computes the risk of a group of items, keeping how many items
+// have each risk level instead of only the highest one
+type RiskAggregator struct {
+	counts  map[TelemetryRisk]int
+	total   int
+	maxRisk TelemetryRisk
+}
+
+// NewRiskAggregator creates an empty risk aggregator
+func NewRiskAggregator() *RiskAggregator {
+	return &RiskAggregator{counts: make(map[TelemetryRisk]int)}
+}
+
+// Add records one item of the given risk
+func (ra *RiskAggregator) Add(risk TelemetryRisk) {
+	ra.counts[risk]++
+	ra.total++
+	if risk > ra.maxRisk {
+		ra.maxRisk = risk
+	}
+}
+
+// Count returns the number of items per risk level. Levels without items are left out.
+func (ra *RiskAggregator) Count() map[TelemetryRisk]int {
+	counts := make(map[TelemetryRisk]int, len(ra.counts))
+	for risk, count := range ra.counts {
+		counts[risk] = count
+	}
+	return counts
+}
+
+// CountAtLeast returns the number of items of the given risk or higher
+func (ra *RiskAggregator) CountAtLeast(risk TelemetryRisk) int {
+	total := 0
+	for level, count := range ra.counts {
+		if level >= risk {
+			total += count
+		}
+	}
+	return total
+}
+
+// MaxRisk returns the highest risk added, or TelemetryRiskNone for an empty group
+func (ra *RiskAggregator) MaxRisk() TelemetryRisk {
+	return ra.maxRisk
+}
+
+// WeightedScore weighs critical items 4, high 3, medium 2 and low 1 and normalizes
+// the sum to 0 (no risk) through 1 (every item critical). An empty group scores 0.
+func (ra *RiskAggregator) WeightedScore() float64 {
+	if ra.total == 0 {
+		return 0
+	}
+
+	sum := 0
+	for risk, count := range ra.counts {
+		sum += riskWeights[risk] * count
+	}
+	return float64(sum) / float64(ra.total*riskWeights[TelemetryRiskCritical])
+}
+
+// FormatRiskDistribution describes a risk distribution from low to critical,
+// e.g. "32 low, 15 medium, 5 high, 1 critical". Levels without items are left out.
+func FormatRiskDistribution(distribution map[TelemetryRisk]int) string {
+	var parts []string
+	for _, risk := range []TelemetryRisk{TelemetryRiskLow, TelemetryRiskMedium, TelemetryRiskHigh, TelemetryRiskCritical} {
+		if count := distribution[risk]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(risk.String())))
+		}
+	}
+	if len(parts) == 0 {
+		return "no risk"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRiskAggregator(t *testing.T) {
+	risks := NewRiskAggregator()
+	if risks.MaxRisk() != TelemetryRiskNone || risks.WeightedScore() != 0 {
+		t.Errorf("Expected an empty group to have no risk, got %s and %f", risks.MaxRisk(), risks.WeightedScore())
+	}
+
+	for _, risk := range []TelemetryRisk{
+		TelemetryRiskLow, TelemetryRiskLow, TelemetryRiskMedium,
+		TelemetryRiskCritical, TelemetryRiskNone, TelemetryRiskHigh,
+	} {
+		risks.Add(risk)
+	}
+
+	want := map[TelemetryRisk]int{
+		TelemetryRiskNone:     1,
+		TelemetryRiskLow:      2,
+		TelemetryRiskMedium:   1,
+		TelemetryRiskHigh:     1,
+		TelemetryRiskCritical: 1,
+	}
+	if got := risks.Count(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected counts %v, got %v", want, got)
+	}
+	if risks.MaxRisk() != TelemetryRiskCritical {
+		t.Errorf("Expected critical max risk, got %s", risks.MaxRisk())
+	}
+	if got := risks.CountAtLeast(TelemetryRiskHigh); got != 2 {
+		t.Errorf("Expected 2 high or critical items, got %d", got)
+	}
+	// (1+1+2+4+0+3) / (6*4)
+	if got, want := risks.WeightedScore(), 11.0/24.0; got != want {
+		t.Errorf("Expected weighted score %f, got %f", want, got)
+	}
+
+	// Count returns a copy
+	risks.Count()[TelemetryRiskLow] = 100
+	if risks.Count()[TelemetryRiskLow] != 2 {
+		t.Error("Expected Count to return a copy of the counts")
+	}
+}
+
+func TestRiskAggregatorAllCritical(t *testing.T) {
+	risks := NewRiskAggregator()
+	risks.Add(TelemetryRiskCritical)
+	risks.Add(TelemetryRiskCritical)
+	if risks.WeightedScore() != 1 {
+		t.Errorf("Expected an all-critical group to score 1, got %f", risks.WeightedScore())
+	}
+}
+
+func TestFormatRiskDistribution(t *testing.T) {
+	distribution := map[TelemetryRisk]int{
+		TelemetryRiskNone:     7,
+		TelemetryRiskLow:      32,
+		TelemetryRiskMedium:   15,
+		TelemetryRiskHigh:     5,
+		TelemetryRiskCritical: 1,
+	}
+	if got, want := FormatRiskDistribution(distribution), "32 low, 15 medium, 5 high, 1 critical"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := FormatRiskDistribution(map[TelemetryRisk]int{TelemetryRiskNone: 3}); got != "no risk" {
+		t.Errorf("Expected \"no risk\", got %q", got)
+	}
+}
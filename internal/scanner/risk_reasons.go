@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// References cited by pattern explanations
+const (
+	vscodeTelemetryDocs    = "https://code.visualstudio.com/docs/getstarted/telemetry"
+	extensionTelemetryDocs = "https://code.visualstudio.com/api/extension-guides/telemetry"
+	appInsightsDocs        = "https://learn.microsoft.com/azure/azure-monitor/app/app-insights-overview"
+)
+
+// PatternExplanation says why data matching a telemetry pattern is a risk
+type PatternExplanation struct {
+	Explanation string `json:"explanation"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+// patternExplanations is the registry of explanations shared by the storage, config
+// and database analyzers. Keys are lowercase terms; a pattern is explained by the
+// longest term it contains, so "searchHistory" uses "history".
+var patternExplanations = map[string]PatternExplanation{
+	"machineid":           {"Identifies this machine across sessions, installs and extensions", vscodeTelemetryDocs},
+	"deviceid":            {"Identifies this device across sessions and installs", vscodeTelemetryDocs},
+	"installid":           {"Identifies this installation across sessions", extensionTelemetryDocs},
+	"sessionid":           {"Links events of one editor session together", vscodeTelemetryDocs},
+	"userid":              {"Identifies the user across sessions and machines", extensionTelemetryDocs},
+	"hostname":            {"Reveals the name of this computer", extensionTelemetryDocs},
+	"telemetry":           {"Telemetry collected for sending to the extension author", extensionTelemetryDocs},
+	"analytics":           {"Analytics data describing how the editor is used", extensionTelemetryDocs},
+	"tracking":            {"Tracking data that follows activity over time", extensionTelemetryDocs},
+	"usage":               {"Usage statistics of features and commands", extensionTelemetryDocs},
+	"metrics":             {"Metrics collected about usage or performance", extensionTelemetryDocs},
+	"statistics":          {"Aggregated statistics about usage", extensionTelemetryDocs},
+	"performance":         {"Performance measurements, usually sent as telemetry", extensionTelemetryDocs},
+	"crash":               {"Crash reports that can include file paths and code", vscodeTelemetryDocs},
+	"error":               {"Error reports that can include file paths and code", vscodeTelemetryDocs},
+	"diagnostic":          {"Diagnostic data gathered for troubleshooting", vscodeTelemetryDocs},
+	"debug":               {"Debug information that may include local details", ""},
+	"history":             {"A history of searches, commands or navigation", ""},
+	"recentfiles":         {"Names and paths of recently opened files", ""},
+	"lastused":            {"Records when the extension was last used", ""},
+	"activation":          {"Records how often and when the extension is activated", extensionTelemetryDocs},
+	"experiment":          {"Experiment assignments used for A/B testing", vscodeTelemetryDocs},
+	"survey":              {"Survey prompts and responses", ""},
+	"feedback":            {"Feedback sent to the extension author", ""},
+	"feature.flag":        {"Feature flags that can be assigned per user", ""},
+	"betafeatures":        {"Beta features enabled for this user", ""},
+	"preferences":         {"User preferences that can be used for profiling", ""},
+	"apikeys":             {"API keys that grant access to remote services", ""},
+	"authtokens":          {"Authentication tokens that grant access to accounts", ""},
+	"serverendpoints":     {"Servers the extension sends data to", extensionTelemetryDocs},
+	"networklogs":         {"Logs of network requests made by the extension", ""},
+	"applicationinsights": {"Application Insights keys used to send telemetry to Azure", appInsightsDocs},
+	"update":              {"Update checks contact remote servers", vscodeTelemetryDocs},
+	"globalstorage":       {"Extension state kept across workspaces", ""},
+	"workspacestorage":    {"Extension state kept per workspace", ""},
+	"memento":             {"Extension state saved between sessions", ""},
+	"extension.install":   {"Where extensions were installed from", ""},
+	"extension.uninstall": {"Why extensions were uninstalled", ""},
+	"extension.config":    {"Extension configuration", ""},
+	"extension.settings":  {"Extension configuration", ""},
+}
+
+// explanationTerms are the keys of patternExplanations, longest first
+var explanationTerms = sortedExplanationTerms()
+
+// sortedExplanationTerms orders the registry terms so the most specific match wins
+func sortedExplanationTerms() []string {
+	terms := make([]string, 0, len(patternExplanations))
+	for term := range patternExplanations {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+	return terms
+}
+
+// ExplainPattern returns the registry explanation for a pattern, falling back to
+// the description of its risk level for patterns the registry does not cover
+func ExplainPattern(pattern string, risk TelemetryRisk) PatternExplanation {
+	lowerPattern := strings.ToLower(pattern)
+	for _, term := range explanationTerms {
+		if strings.Contains(lowerPattern, term) {
+			return patternExplanations[term]
+		}
+	}
+	return PatternExplanation{Explanation: GetRiskDescription(risk)}
+}
+
+// patternMatch is a pattern that matched a key, path or value and the risk it assigns
+type patternMatch struct {
+	pattern     string
+	risk        TelemetryRisk
+	explanation string // Overrides the registry explanation of pattern when set
+}
+
+// explainRisk returns the highest risk of matches and one reason per matched
+// pattern, saying whether it set the final risk. matches is deduplicated and sorted
+// in place from highest to lowest risk, so matches[0] is the pattern that won.
+func explainRisk(matches []patternMatch) (TelemetryRisk, []patternMatch, []string) {
+	if len(matches) == 0 {
+		return TelemetryRiskNone, matches, nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].risk != matches[j].risk {
+			return matches[i].risk > matches[j].risk
+		}
+		return matches[i].pattern < matches[j].pattern
+	})
+
+	seen := make(map[string]bool, len(matches))
+	unique := matches[:0]
+	for _, match := range matches {
+		if !seen[strings.ToLower(match.pattern)] {
+			seen[strings.ToLower(match.pattern)] = true
+			unique = append(unique, match)
+		}
+	}
+
+	finalRisk := unique[0].risk
+	reasons := make([]string, 0, len(unique))
+	for _, match := range unique {
+		contribution := "sets the final risk"
+		if match.risk < finalRisk {
+			contribution = fmt.Sprintf("below the final %s risk", finalRisk)
+		}
+
+		explanation := ExplainPattern(match.pattern, match.risk)
+		if match.explanation != "" {
+			explanation = PatternExplanation{Explanation: match.explanation}
+		}
+		reason := fmt.Sprintf("%q (%s, %s): %s", match.pattern, match.risk, contribution, explanation.Explanation)
+		if explanation.Reference != "" {
+			reason += " - see " + explanation.Reference
+		}
+		reasons = append(reasons, reason)
+	}
+
+	return finalRisk, unique, reasons
+}
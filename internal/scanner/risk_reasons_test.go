@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplainRiskRecordsEveryMatch(t *testing.T) {
+	risk, matches, reasons := explainRisk([]patternMatch{
+		{pattern: "usageStats", risk: TelemetryRiskHigh},
+		{pattern: "machineId", risk: TelemetryRiskCritical},
+		{pattern: "deviceId", risk: TelemetryRiskCritical},
+		{pattern: "machineid", risk: TelemetryRiskCritical}, // Same pattern, different case
+	})
+
+	if risk != TelemetryRiskCritical {
+		t.Errorf("Expected critical risk, got %s", risk)
+	}
+	if len(matches) != 3 || matches[0].pattern != "deviceId" {
+		t.Errorf("Expected 3 matches sorted by risk and pattern, got %+v", matches)
+	}
+	if len(reasons) != 3 {
+		t.Fatalf("Expected a reason per pattern, got %v", reasons)
+	}
+
+	// Both critical patterns set the final risk, the high one does not
+	for _, reason := range reasons[:2] {
+		if !strings.Contains(reason, "sets the final risk") {
+			t.Errorf("Expected %q to set the final risk", reason)
+		}
+	}
+	if !strings.Contains(reasons[2], `"usageStats" (High, below the final Critical risk)`) {
+		t.Errorf("Expected the high pattern to be below the final risk, got %q", reasons[2])
+	}
+	if !strings.Contains(reasons[1], vscodeTelemetryDocs) {
+		t.Errorf("Expected the machineId reason to cite its reference, got %q", reasons[1])
+	}
+
+	if risk, _, reasons := explainRisk(nil); risk != TelemetryRiskNone || reasons != nil {
+		t.Errorf("Expected no risk and no reasons without matches, got %s and %v", risk, reasons)
+	}
+}
+
+func TestExplainPattern(t *testing.T) {
+	// The most specific term wins: "searchHistory" is a history, not a search
+	if got := ExplainPattern("searchHistory", TelemetryRiskMedium); got != patternExplanations["history"] {
+		t.Errorf("Expected the history explanation, got %+v", got)
+	}
+	if got := ExplainPattern("applicationinsights.instrumentationkey", TelemetryRiskCritical); got.Reference != appInsightsDocs {
+		t.Errorf("Expected the Application Insights reference, got %+v", got)
+	}
+
+	// Patterns outside the registry fall back to their risk level
+	if got := ExplainPattern("somethingElse", TelemetryRiskHigh); got.Explanation != GetRiskDescription(TelemetryRiskHigh) {
+		t.Errorf("Expected the high risk description, got %+v", got)
+	}
+}
+
+func TestAnalyzersAttachReasons(t *testing.T) {
+	entry := NewDatabaseAnalyzer().analyzeKeyValue("ItemTable", "extension.telemetry.machineId", "{}")
+	if entry == nil {
+		t.Fatal("Expected a database entry")
+	}
+	if entry.Risk != TelemetryRiskCritical || entry.Category != "Telemetry" {
+		t.Errorf("Expected a critical telemetry entry, got %s %s", entry.Risk, entry.Category)
+	}
+	// machineid, extension.telemetry and telemetry all match
+	if len(entry.Reasons) != 3 || !strings.HasPrefix(entry.Reasons[0], `"machineid" (Critical, sets the final risk)`) {
+		t.Errorf("Expected three reasons led by machineid, got %v", entry.Reasons)
+	}
+
+	analyzer := NewStorageAnalyzer()
+	storage := &ExtensionStorage{}
+	analyzer.analyzeJSONData(map[string]interface{}{"sessionId": "userId-1"}, "state.json", "", &mockFileInfo{name: "state.json", modTime: time.Now()}, storage)
+	if len(storage.StorageItems) != 1 {
+		t.Fatalf("Expected one storage item, got %d", len(storage.StorageItems))
+	}
+	if item := storage.StorageItems[0]; item.Risk != TelemetryRiskHigh || len(item.Reasons) != 2 {
+		t.Errorf("Expected a high risk item matching sessionId and userId, got %s %v", item.Risk, item.Reasons)
+	}
+
+	result := &ConfigAnalysisResult{}
+	NewConfigAnalyzer().analyzeConfigObject(map[string]interface{}{"myext.telemetry.usage.enabled": true}, "settings.json", "VS Code Settings", result)
+	if len(result.TelemetrySettings) != 1 {
+		t.Fatalf("Expected one telemetry finding, got %+v", result)
+	}
+	finding := result.TelemetrySettings[0]
+	if finding.Risk != TelemetryRiskHigh || len(finding.Reasons) < 3 {
+		t.Errorf("Expected a high risk finding with every matched pattern, got %s %v", finding.Risk, finding.Reasons)
+	}
+}
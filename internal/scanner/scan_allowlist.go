@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// defaultScanAllowlist lists the extension IDs trusted by default, one per line
+//
+//go:embed trusted_extensions.txt
+var defaultScanAllowlist string
+
+// TrustedExtensionNote is the note of extension storage skipped by the scan allowlist
+const TrustedExtensionNote = "Extension in trusted allowlist"
+
+// DefaultScanAllowlist returns the extension IDs full scans skip unless the user
+// changed the allowlist
+func DefaultScanAllowlist() []string {
+	return parseScanAllowlist(defaultScanAllowlist)
+}
+
+// DefaultScanAllowlistPath returns the location of the user's scan allowlist
+func DefaultScanAllowlistPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user directories: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "augment-telemetry-cleaner", "allowlist.txt"), nil
+}
+
+// LoadScanAllowlist reads the scan allowlist at path; a missing file yields
+// DefaultScanAllowlist. Once the user changes the allowlist the file holds all of
+// it, so deleting the file restores the defaults.
+func LoadScanAllowlist(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return DefaultScanAllowlist(), nil
+		}
+		return nil, fmt.Errorf("failed to read scan allowlist: %w", err)
+	}
+	return parseScanAllowlist(string(data)), nil
+}
+
+// UpdateScanAllowlist adds and removes extension IDs from the scan allowlist at
+// path and returns the new allowlist
+func UpdateScanAllowlist(path string, add, remove []string) ([]string, error) {
+	current, err := LoadScanAllowlist(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(current)+len(add))
+	for _, id := range current {
+		ids[id] = true
+	}
+	for _, id := range add {
+		if id = strings.ToLower(strings.TrimSpace(id)); id != "" {
+			ids[id] = true
+		}
+	}
+	for _, id := range remove {
+		delete(ids, strings.ToLower(strings.TrimSpace(id)))
+	}
+
+	allowlist := make([]string, 0, len(ids))
+	for id := range ids {
+		allowlist = append(allowlist, id)
+	}
+	sort.Strings(allowlist)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scan allowlist directory: %w", err)
+	}
+	content := "# Extensions scans skip as trusted, one ID per line. Delete this file to\n" +
+		"# restore the default allowlist.\n" + strings.Join(allowlist, "\n") + "\n"
+	if err := utils.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write scan allowlist: %w", err)
+	}
+
+	return allowlist, nil
+}
+
+// parseScanAllowlist returns the lowercase extension IDs of an allowlist file,
+// ignoring blank lines and # comments
+func parseScanAllowlist(content string) []string {
+	var ids []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, strings.ToLower(line))
+	}
+	return ids
+}
+
+// SetScanAllowlist replaces the extensions whose storage full scans report as
+// trusted without analyzing it. A scan of a single extension analyzes it even
+// when it is on the allowlist.
+func (sa *StorageAnalyzer) SetScanAllowlist(extensionIDs []string) {
+	sa.scanAllowlist = make(map[string]bool, len(extensionIDs))
+	for _, id := range extensionIDs {
+		sa.scanAllowlist[strings.ToLower(id)] = true
+	}
+}
+
+// analyzeOrTrustExtensionStorage analyzes the storage of extensionID, unless a
+// full scan (an empty filter) finds it on the scan allowlist
+func (sa *StorageAnalyzer) analyzeOrTrustExtensionStorage(filter, extensionID, storagePath, storageType string) (*ExtensionStorage, error) {
+	if filter != "" || !sa.scanAllowlist[strings.ToLower(extensionID)] {
+		return sa.analyzeExtensionStorage(extensionID, storagePath, storageType)
+	}
+
+	dirInfo, err := os.Stat(storagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage directory info: %w", err)
+	}
+	storage := &ExtensionStorage{
+		ExtensionID:    extensionID,
+		StoragePath:    storagePath,
+		StorageItems:   make([]StorageDataItem, 0),
+		DataCategories: make([]string, 0),
+		LastAccessed:   dirInfo.ModTime(),
+		Risk:           TelemetryRiskNone,
+		Trusted:        true,
+		Note:           TrustedExtensionNote,
+	}
+
+	// Only the size is recorded, so the storage totals stay complete
+	filepath.Walk(storagePath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			storage.TotalSize += info.Size()
+		}
+		return nil
+	})
+
+	return storage, nil
+}
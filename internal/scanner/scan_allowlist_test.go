@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestAnalyzeStorageSkipsTrustedExtensions(t *testing.T) {
+	home := t.TempDir()
+	resolver := utils.FakePathResolver{Home: home, OS: "linux"}
+	globalDir := filepath.Join(home, ".config", "Code", "User", "globalStorage")
+	for _, id := range []string{"golang.go", "unknown.tracker"} {
+		mkdirAll(t, filepath.Join(globalDir, id))
+		if err := os.WriteFile(filepath.Join(globalDir, id, "telemetry.json"), []byte(`{"machineId": "abc"}`), 0644); err != nil {
+			t.Fatalf("Failed to write storage: %v", err)
+		}
+	}
+	storageByID := func(result *StorageAnalysisResult) map[string]ExtensionStorage {
+		storages := make(map[string]ExtensionStorage)
+		for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+			storages[storage.ExtensionID] = storage
+		}
+		return storages
+	}
+
+	result, err := NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	storages := storageByID(result)
+	trusted := storages["golang.go"]
+	if !trusted.Trusted || trusted.Risk != TelemetryRiskNone || trusted.Note != TrustedExtensionNote || trusted.RetentionPolicy.HasPolicy {
+		t.Errorf("Expected golang.go to be trusted without analysis, got %+v", trusted)
+	}
+	if trusted.TotalSize == 0 {
+		t.Error("Expected the size of trusted storage to be recorded")
+	}
+
+	// Only analyzed storage has a retention policy
+	if other := storages["unknown.tracker"]; other.Trusted || !other.RetentionPolicy.HasPolicy {
+		t.Errorf("Expected unknown.tracker to be analyzed, got %+v", other)
+	}
+
+	// Scanning the trusted extension on its own analyzes it
+	result, err = NewStorageAnalyzerWithResolver(resolver).AnalyzeStorage("golang.go")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	if storage := storageByID(result)["golang.go"]; storage.Trusted || !storage.RetentionPolicy.HasPolicy {
+		t.Errorf("Expected a single-extension scan to analyze golang.go, got %+v", storage)
+	}
+
+	analyzer := NewStorageAnalyzerWithResolver(resolver)
+	analyzer.SetScanAllowlist([]string{"Unknown.Tracker"})
+	result, err = analyzer.AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	storages = storageByID(result)
+	if storages["golang.go"].Trusted || !storages["unknown.tracker"].Trusted {
+		t.Errorf("Expected only unknown.tracker to be trusted, got %+v", storages)
+	}
+}
+
+func TestUpdateScanAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "allowlist.txt")
+
+	allowlist, err := LoadScanAllowlist(path)
+	if err != nil {
+		t.Fatalf("LoadScanAllowlist failed: %v", err)
+	}
+	if !reflect.DeepEqual(allowlist, DefaultScanAllowlist()) || len(allowlist) == 0 {
+		t.Errorf("Expected the default allowlist without a file, got %v", allowlist)
+	}
+
+	updated, err := UpdateScanAllowlist(path, []string{" My.Extension "}, []string{"golang.go", "not.listed"})
+	if err != nil {
+		t.Fatalf("UpdateScanAllowlist failed: %v", err)
+	}
+	reloaded, err := LoadScanAllowlist(path)
+	if err != nil {
+		t.Fatalf("LoadScanAllowlist failed: %v", err)
+	}
+	if !reflect.DeepEqual(updated, reloaded) {
+		t.Errorf("Expected the saved allowlist %v, got %v", updated, reloaded)
+	}
+
+	contains := func(ids []string, id string) bool {
+		for _, item := range ids {
+			if item == id {
+				return true
+			}
+		}
+		return false
+	}
+	if !contains(reloaded, "my.extension") || contains(reloaded, "golang.go") || !contains(reloaded, "ms-python.python") {
+		t.Errorf("Expected my.extension added and golang.go removed from the defaults, got %v", reloaded)
+	}
+}
@@ -0,0 +1,180 @@
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ScanDiffItem represents a single storage entry that differs between two scans
+type ScanDiffItem struct {
+	Location string        `json:"location"`
+	Key      string        `json:"key"`
+	Risk     TelemetryRisk `json:"risk"`
+	OldValue interface{}   `json:"old_value,omitempty"`
+	NewValue interface{}   `json:"new_value,omitempty"`
+	OldSize  int64         `json:"old_size"`
+	NewSize  int64         `json:"new_size"`
+}
+
+// ScanDiff represents the differences between two storage analysis results
+type ScanDiff struct {
+	Removed             []ScanDiffItem `json:"removed"`
+	Added               []ScanDiffItem `json:"added"`
+	Changed             []ScanDiffItem `json:"changed"`
+	BeforeItemCount     int            `json:"before_item_count"`
+	AfterItemCount      int            `json:"after_item_count"`
+	TelemetrySizeBefore int64          `json:"telemetry_size_before"`
+	TelemetrySizeAfter  int64          `json:"telemetry_size_after"`
+}
+
+// HasChanges reports whether the diff contains any removed, added or changed items
+func (d *ScanDiff) HasChanges() bool {
+	return len(d.Removed) > 0 || len(d.Added) > 0 || len(d.Changed) > 0
+}
+
+// DiffScanResults compares two storage analysis results and returns what changed.
+// Removed items are entries present only in before (typically cleaned), added items
+// are entries present only in after (typically re-created telemetry), and changed
+// items exist in both scans with a different value, size or risk.
+func DiffScanResults(before, after *StorageAnalysisResult) *ScanDiff {
+	diff := &ScanDiff{
+		Removed: make([]ScanDiffItem, 0),
+		Added:   make([]ScanDiffItem, 0),
+		Changed: make([]ScanDiffItem, 0),
+	}
+
+	beforeItems := collectScanItems(before)
+	afterItems := collectScanItems(after)
+
+	diff.BeforeItemCount = len(beforeItems)
+	diff.AfterItemCount = len(afterItems)
+	if before != nil {
+		diff.TelemetrySizeBefore = before.StorageStatistics.TelemetryStorageSize
+	}
+	if after != nil {
+		diff.TelemetrySizeAfter = after.StorageStatistics.TelemetryStorageSize
+	}
+
+	for id, old := range beforeItems {
+		current, exists := afterItems[id]
+		if !exists {
+			diff.Removed = append(diff.Removed, ScanDiffItem{
+				Location: old.location,
+				Key:      old.key,
+				Risk:     old.risk,
+				OldValue: old.value,
+				OldSize:  old.size,
+			})
+			continue
+		}
+
+		if old.size != current.size || old.risk != current.risk ||
+			!reflect.DeepEqual(old.value, current.value) {
+			diff.Changed = append(diff.Changed, ScanDiffItem{
+				Location: old.location,
+				Key:      old.key,
+				Risk:     current.risk,
+				OldValue: old.value,
+				NewValue: current.value,
+				OldSize:  old.size,
+				NewSize:  current.size,
+			})
+		}
+	}
+
+	for id, item := range afterItems {
+		if _, exists := beforeItems[id]; !exists {
+			diff.Added = append(diff.Added, ScanDiffItem{
+				Location: item.location,
+				Key:      item.key,
+				Risk:     item.risk,
+				NewValue: item.value,
+				NewSize:  item.size,
+			})
+		}
+	}
+
+	sortScanDiffItems(diff.Removed)
+	sortScanDiffItems(diff.Added)
+	sortScanDiffItems(diff.Changed)
+
+	return diff
+}
+
+// scanItem is a flattened storage entry used when comparing scans
+type scanItem struct {
+	location string
+	key      string
+	risk     TelemetryRisk
+	value    interface{}
+	size     int64
+}
+
+// collectScanItems flattens a storage analysis result into items keyed by location and key
+func collectScanItems(result *StorageAnalysisResult) map[string]scanItem {
+	items := make(map[string]scanItem)
+	if result == nil {
+		return items
+	}
+
+	addStorage := func(location string, storage ExtensionStorage) {
+		for _, item := range storage.StorageItems {
+			items[scanItemID(location, item.Key)] = scanItem{
+				location: location,
+				key:      item.Key,
+				risk:     item.Risk,
+				value:    item.Value,
+				size:     item.Size,
+			}
+		}
+	}
+
+	for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+		addStorage(fmt.Sprintf("globalStorage/%s", storage.ExtensionID), storage)
+	}
+
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		for _, storage := range workspace.ExtensionStorages {
+			addStorage(fmt.Sprintf("workspaceStorage/%s/%s", workspace.WorkspaceHash, storage.ExtensionID), storage)
+		}
+	}
+
+	for _, cacheDir := range result.CacheAnalysis.CacheDirectories {
+		location := fmt.Sprintf("cache/%s", cacheDir.ExtensionID)
+		for _, file := range cacheDir.CacheFiles {
+			items[scanItemID(location, file.Path)] = scanItem{
+				location: location,
+				key:      file.Path,
+				risk:     file.Risk,
+				size:     file.Size,
+			}
+		}
+	}
+
+	for _, file := range result.TempFileAnalysis.TempFiles {
+		items[scanItemID("temp", file.Path)] = scanItem{
+			location: "temp",
+			key:      file.Path,
+			risk:     file.Risk,
+			size:     file.Size,
+		}
+	}
+
+	return items
+}
+
+// scanItemID builds the identity used to match items across scans
+func scanItemID(location, key string) string {
+	return location + "\x00" + key
+}
+
+// sortScanDiffItems orders diff items by location and then key
+func sortScanDiffItems(items []ScanDiffItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Location != items[j].Location {
+			return items[i].Location < items[j].Location
+		}
+		return items[i].Key < items[j].Key
+	})
+}
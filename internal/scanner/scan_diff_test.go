@@ -0,0 +1,68 @@
+package scanner
+
+import "testing"
+
+func TestDiffScanResults(t *testing.T) {
+	before := &StorageAnalysisResult{
+		GlobalStorageAnalysis: GlobalStorageAnalysis{
+			ExtensionStorages: []ExtensionStorage{
+				{
+					ExtensionID: "augment.vscode-augment",
+					StorageItems: []StorageDataItem{
+						{Key: "machineId", Value: "abc", Size: 5, Risk: TelemetryRiskCritical},
+						{Key: "sessionId", Value: "s1", Size: 4, Risk: TelemetryRiskHigh},
+					},
+				},
+			},
+		},
+	}
+
+	after := &StorageAnalysisResult{
+		GlobalStorageAnalysis: GlobalStorageAnalysis{
+			ExtensionStorages: []ExtensionStorage{
+				{
+					ExtensionID: "augment.vscode-augment",
+					StorageItems: []StorageDataItem{
+						{Key: "sessionId", Value: "s2", Size: 4, Risk: TelemetryRiskHigh},
+						{Key: "userId", Value: "u1", Size: 4, Risk: TelemetryRiskHigh},
+					},
+				},
+			},
+		},
+	}
+
+	diff := DiffScanResults(before, after)
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "machineId" {
+		t.Errorf("Expected machineId to be removed, got %+v", diff.Removed)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "userId" {
+		t.Errorf("Expected userId to be added, got %+v", diff.Added)
+	}
+
+	if len(diff.Changed) != 1 || diff.Changed[0].Key != "sessionId" {
+		t.Fatalf("Expected sessionId to be changed, got %+v", diff.Changed)
+	}
+
+	if diff.Changed[0].OldValue != "s1" || diff.Changed[0].NewValue != "s2" {
+		t.Errorf("Expected value change s1 -> s2, got %v -> %v", diff.Changed[0].OldValue, diff.Changed[0].NewValue)
+	}
+
+	if !diff.HasChanges() {
+		t.Error("Expected HasChanges() to be true")
+	}
+}
+
+func TestDiffScanResultsIdentical(t *testing.T) {
+	result := &StorageAnalysisResult{
+		TempFileAnalysis: TempFileAnalysis{
+			TempFiles: []TempFile{{Path: "/tmp/vscode-telemetry.log", Size: 10, Risk: TelemetryRiskHigh}},
+		},
+	}
+
+	diff := DiffScanResults(result, result)
+	if diff.HasChanges() {
+		t.Errorf("Expected no changes for identical scans, got %+v", diff)
+	}
+}
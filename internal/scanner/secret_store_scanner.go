@@ -0,0 +1,265 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/sanitize"
+)
+
+// secretServicePrefix is the service name prefix VS Code uses for OS secret store entries
+const secretServicePrefix = "vscode"
+
+// SecretEntry describes an Augment-related entry in the OS secret store.
+// Only a short snippet of the secret is ever kept.
+type SecretEntry struct {
+	ServiceName  string        `json:"service_name"`
+	AccountName  string        `json:"account_name"`
+	TokenSnippet string        `json:"token_snippet,omitempty"`
+	Risk         TelemetryRisk `json:"risk"`
+	Target       string        `json:"target,omitempty"` // Windows Credential Manager target name
+}
+
+// SecretStoreScanner finds VS Code secrets belonging to Augment in libsecret (Linux),
+// the Keychain (macOS) or Windows Credential Manager
+type SecretStoreScanner struct {
+	goos       string
+	runCommand func(name string, args ...string) ([]byte, error)
+}
+
+// NewSecretStoreScanner creates a new secret store scanner for the current OS
+func NewSecretStoreScanner() *SecretStoreScanner {
+	return NewSecretStoreScannerWithRunner(runtime.GOOS, func(name string, args ...string) ([]byte, error) {
+		return exec.Command(name, args...).Output()
+	})
+}
+
+// NewSecretStoreScannerWithRunner creates a secret store scanner for goos that runs
+// the secret-tool, security or cmdkey commands through runCommand, e.g. a fake in tests
+func NewSecretStoreScannerWithRunner(goos string, runCommand func(name string, args ...string) ([]byte, error)) *SecretStoreScanner {
+	return &SecretStoreScanner{goos: goos, runCommand: runCommand}
+}
+
+// ScanForAugmentSecrets lists VS Code secret store entries whose service or account
+// mentions Augment
+func (s *SecretStoreScanner) ScanForAugmentSecrets() ([]SecretEntry, error) {
+	var entries []SecretEntry
+	var err error
+
+	switch s.goos {
+	case "linux":
+		entries, err = s.scanLibsecret()
+	case "darwin":
+		entries, err = s.scanKeychain()
+	case "windows":
+		entries, err = s.scanCredentialManager()
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", s.goos)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sortSecretEntries(entries)
+	return entries, nil
+}
+
+// DeleteSecret removes a single entry from the OS secret store
+func (s *SecretStoreScanner) DeleteSecret(entry SecretEntry) error {
+	var err error
+
+	switch s.goos {
+	case "linux":
+		_, err = s.runCommand("secret-tool", "clear", "service", entry.ServiceName, "account", entry.AccountName)
+	case "darwin":
+		_, err = s.runCommand("security", "delete-generic-password", "-s", entry.ServiceName, "-a", entry.AccountName)
+	case "windows":
+		_, err = s.runCommand("cmdkey", "/delete:"+entry.Target)
+	default:
+		return fmt.Errorf("unsupported operating system: %s", s.goos)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to delete secret %s/%s: %w", entry.ServiceName, entry.AccountName, err)
+	}
+	return nil
+}
+
+// scanLibsecret lists generic secrets via secret-tool
+func (s *SecretStoreScanner) scanLibsecret() ([]SecretEntry, error) {
+	output, err := s.runCommand("secret-tool", "search", "--all", "xdg:schema", "org.freedesktop.Secret.Generic")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search libsecret: %w", err)
+	}
+	return parseSecretToolOutput(output), nil
+}
+
+// scanKeychain lists generic passwords from the default keychain, then reads the
+// secret of each matching item to build its snippet
+func (s *SecretStoreScanner) scanKeychain() ([]SecretEntry, error) {
+	output, err := s.runCommand("security", "dump-keychain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keychain: %w", err)
+	}
+
+	entries := parseKeychainDump(output)
+	for i := range entries {
+		secret, err := s.runCommand("security", "find-generic-password",
+			"-s", entries[i].ServiceName, "-a", entries[i].AccountName, "-w")
+		if err == nil {
+			entries[i].TokenSnippet = secretSnippet(strings.TrimSpace(string(secret)))
+		}
+	}
+
+	return entries, nil
+}
+
+// scanCredentialManager lists generic credentials via cmdkey. cmdkey cannot read
+// credential blobs, so Windows entries carry no token snippet.
+func (s *SecretStoreScanner) scanCredentialManager() ([]SecretEntry, error) {
+	output, err := s.runCommand("cmdkey", "/list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	return parseCmdkeyList(output), nil
+}
+
+// parseSecretToolOutput parses `secret-tool search --all` output
+func parseSecretToolOutput(output []byte) []SecretEntry {
+	var entries []SecretEntry
+	var current *SecretEntry
+
+	flush := func() {
+		if current != nil && isAugmentSecret(current.ServiceName, current.AccountName) {
+			entries = append(entries, *current)
+		}
+		current = nil
+	}
+
+	lineScanner := bufio.NewScanner(bytes.NewReader(output))
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			current = &SecretEntry{Risk: TelemetryRiskHigh}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "attribute.service":
+			current.ServiceName = value
+		case "attribute.account":
+			current.AccountName = value
+		case "secret":
+			current.TokenSnippet = secretSnippet(value)
+		}
+	}
+	flush()
+
+	return entries
+}
+
+// parseKeychainDump parses generic password items from `security dump-keychain`
+func parseKeychainDump(output []byte) []SecretEntry {
+	var entries []SecretEntry
+	var current *SecretEntry
+	isGeneric := false
+
+	flush := func() {
+		if current != nil && isGeneric && isAugmentSecret(current.ServiceName, current.AccountName) {
+			entries = append(entries, *current)
+		}
+		current = nil
+		isGeneric = false
+	}
+
+	lineScanner := bufio.NewScanner(bytes.NewReader(output))
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		switch {
+		case strings.HasPrefix(line, "keychain:"):
+			flush()
+			current = &SecretEntry{Risk: TelemetryRiskHigh}
+		case current == nil:
+			continue
+		case line == `class: "genp"`:
+			isGeneric = true
+		case strings.HasPrefix(line, `"svce"<blob>=`):
+			current.ServiceName = keychainBlobValue(line)
+		case strings.HasPrefix(line, `"acct"<blob>=`):
+			current.AccountName = keychainBlobValue(line)
+		}
+	}
+	flush()
+
+	return entries
+}
+
+// parseCmdkeyList parses generic credentials from `cmdkey /list`. Targets created by
+// VS Code have the form "<service>/<account>".
+func parseCmdkeyList(output []byte) []SecretEntry {
+	var entries []SecretEntry
+
+	lineScanner := bufio.NewScanner(bytes.NewReader(output))
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		target, ok := strings.CutPrefix(line, "Target:")
+		if !ok {
+			continue
+		}
+		target = strings.TrimSpace(target)
+
+		name := target
+		if _, after, found := strings.Cut(target, "target="); found {
+			name = after
+		}
+
+		service, account, _ := strings.Cut(name, "/")
+		if isAugmentSecret(service, account) {
+			entries = append(entries, SecretEntry{
+				ServiceName: service,
+				AccountName: account,
+				Risk:        TelemetryRiskHigh,
+				Target:      name,
+			})
+		}
+	}
+
+	return entries
+}
+
+// keychainBlobValue extracts the quoted value of a dump-keychain attribute line
+func keychainBlobValue(line string) string {
+	_, value, _ := strings.Cut(line, "=")
+	value = strings.TrimSpace(value)
+	if value == "<NULL>" {
+		return ""
+	}
+	return strings.Trim(value, `"`)
+}
+
+// isAugmentSecret reports whether a secret belongs to VS Code and mentions Augment
+func isAugmentSecret(service, account string) bool {
+	lowerService := strings.ToLower(service)
+	if !strings.HasPrefix(lowerService, secretServicePrefix) {
+		return false
+	}
+	return strings.Contains(lowerService, "augment") || strings.Contains(strings.ToLower(account), "augment")
+}
+
+// secretSnippet returns the first 8 characters of a secret followed by "...".
+// Short secrets reveal at most half of their characters.
+func secretSnippet(secret string) string {
+	return sanitize.Snippet(secret, 8)
+}
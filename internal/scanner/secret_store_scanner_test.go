@@ -0,0 +1,191 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestParseSecretToolOutput(t *testing.T) {
+	output := `[/org/freedesktop/secrets/collection/login/12]
+label = vscodeaugment.vscode-augment
+secret = abcdefghijklmnopqrstuvwxyz
+created = 2024-01-01 10:00:00
+schema = org.freedesktop.Secret.Generic
+attribute.account = augment.sessions
+attribute.service = vscodeaugment.vscode-augment
+[/org/freedesktop/secrets/collection/login/13]
+label = vscodevscode.github-authentication
+secret = ghp_secretvalue
+attribute.account = github.auth
+attribute.service = vscodevscode.github-authentication
+[/org/freedesktop/secrets/collection/login/14]
+label = other
+secret = augment-but-not-vscode
+attribute.account = augment
+attribute.service = chrome
+`
+
+	entries := parseSecretToolOutput([]byte(output))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 Augment secret, got %d: %+v", len(entries), entries)
+	}
+
+	entry := entries[0]
+	if entry.ServiceName != "vscodeaugment.vscode-augment" || entry.AccountName != "augment.sessions" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.TokenSnippet != "abcdefgh..." {
+		t.Errorf("Expected truncated snippet, got %q", entry.TokenSnippet)
+	}
+	if entry.Risk != TelemetryRiskHigh {
+		t.Errorf("Expected high risk, got %v", entry.Risk)
+	}
+}
+
+func TestParseKeychainDump(t *testing.T) {
+	output := `keychain: "/Users/me/Library/Keychains/login.keychain-db"
+version: 512
+class: "genp"
+attributes:
+    "acct"<blob>="augment.sessions"
+    "svce"<blob>="vscodeaugment.vscode-augment"
+keychain: "/Users/me/Library/Keychains/login.keychain-db"
+version: 512
+class: "inet"
+attributes:
+    "acct"<blob>="augment"
+    "svce"<blob>="vscode-augment-web"
+`
+
+	entries := parseKeychainDump([]byte(output))
+	if len(entries) != 1 || entries[0].AccountName != "augment.sessions" {
+		t.Fatalf("Expected only the generic password entry, got %+v", entries)
+	}
+}
+
+func TestParseCmdkeyList(t *testing.T) {
+	output := `
+Currently stored credentials:
+
+    Target: LegacyGeneric:target=vscodeaugment.vscode-augment/augment.sessions
+    Type: Generic
+    User: augment.sessions
+    Local machine persistence
+
+    Target: LegacyGeneric:target=git:https://github.com
+    Type: Generic
+    User: me
+`
+
+	entries := parseCmdkeyList([]byte(output))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 Augment credential, got %+v", entries)
+	}
+	if entries[0].Target != "vscodeaugment.vscode-augment/augment.sessions" {
+		t.Errorf("Unexpected target: %s", entries[0].Target)
+	}
+	if entries[0].TokenSnippet != "" {
+		t.Errorf("Expected no snippet on Windows, got %q", entries[0].TokenSnippet)
+	}
+}
+
+func TestSecretStoreScannerDeleteSecret(t *testing.T) {
+	var commands []string
+	s := &SecretStoreScanner{
+		goos: "linux",
+		runCommand: func(name string, args ...string) ([]byte, error) {
+			commands = append(commands, name+" "+strings.Join(args, " "))
+			if args[0] == "search" {
+				return []byte("[/item/1]\nsecret = token-value-123\nattribute.account = augment\nattribute.service = vscodeaugment.vscode-augment\n"), nil
+			}
+			return nil, nil
+		},
+	}
+
+	entries, err := s.ScanForAugmentSecrets()
+	if err != nil {
+		t.Fatalf("ScanForAugmentSecrets() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	if err := s.DeleteSecret(entries[0]); err != nil {
+		t.Fatalf("DeleteSecret() failed: %v", err)
+	}
+
+	want := "secret-tool clear service vscodeaugment.vscode-augment account augment"
+	if commands[len(commands)-1] != want {
+		t.Errorf("Expected %q, got %q", want, commands[len(commands)-1])
+	}
+
+	for _, command := range commands {
+		if strings.Contains(command, "token-value-123") {
+			t.Error("Secret value must never be passed on the command line")
+		}
+	}
+
+	s.runCommand = func(name string, args ...string) ([]byte, error) {
+		return nil, fmt.Errorf("exit status 1")
+	}
+	if err := s.DeleteSecret(entries[0]); err == nil {
+		t.Error("Expected DeleteSecret() to report command failures")
+	}
+}
+
+func TestSecretSnippet(t *testing.T) {
+	if got := secretSnippet("short"); got != "sh..." {
+		t.Errorf("Expected short secrets to reveal at most half, got %q", got)
+	}
+	if got := secretSnippet(""); got != "" {
+		t.Errorf("Expected empty snippet for empty secret, got %q", got)
+	}
+}
+
+func TestAnalyzeStorageSecretStoreProbeIsOptIn(t *testing.T) {
+	resolver := utils.FakePathResolver{Home: t.TempDir(), OS: "linux"}
+
+	calls := 0
+	fake := NewSecretStoreScannerWithRunner("linux", func(name string, args ...string) ([]byte, error) {
+		calls++
+		return []byte("[/item/1]\nsecret = token-value-123\nattribute.account = augment\nattribute.service = vscodeaugment.vscode-augment\n"), nil
+	})
+
+	analyzer := NewStorageAnalyzerWithResolver(resolver)
+	analyzer.SetSecretStoreScanner(fake)
+	result, err := analyzer.AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	if calls != 0 || len(result.SecretStoreAnalysis) != 0 {
+		t.Errorf("Expected no secret store probe by default, got %d calls and %+v", calls, result.SecretStoreAnalysis)
+	}
+
+	analyzer.SetScanSecretStore(true)
+	result, err = analyzer.AnalyzeStorage("")
+	if err != nil {
+		t.Fatalf("AnalyzeStorage failed: %v", err)
+	}
+	if calls != 1 || len(result.SecretStoreAnalysis) != 1 {
+		t.Errorf("Expected one probe finding one entry, got %d calls and %+v", calls, result.SecretStoreAnalysis)
+	}
+}
+
+func TestSecretEntryJSONKeepsTarget(t *testing.T) {
+	entry := SecretEntry{ServiceName: "vscodeaugment.vscode-augment", AccountName: "augment.sessions", Target: "vscodeaugment.vscode-augment/augment.sessions"}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded SecretEntry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Target != entry.Target {
+		t.Errorf("Expected the target to survive a JSON round trip, got %q", decoded.Target)
+	}
+}
@@ -0,0 +1,166 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// SettingsSuggestion is the privacy score of the VS Code user settings and the
+// changes that would raise it to the maximum
+type SettingsSuggestion struct {
+	SettingsPath       string          `json:"settings_path"`
+	CurrentScore       int             `json:"current_score"`
+	PotentialScore     int             `json:"potential_score"`
+	RecommendedChanges []SettingChange `json:"recommended_changes"`
+}
+
+// SettingChange is a single recommended settings.json change
+type SettingChange struct {
+	Key              string      `json:"key"`
+	CurrentValue     interface{} `json:"current_value"` // nil when the setting is not set
+	RecommendedValue interface{} `json:"recommended_value"`
+	Reason           string      `json:"reason"`
+}
+
+// privacySetting is a setting that contributes to the privacy score when set to PrivateValue
+type privacySetting struct {
+	Key          string
+	PrivateValue interface{}
+	Weight       int
+	Reason       string
+}
+
+// privacySettings are the settings scored by SuggestOptimalSettings. Weights add up
+// to 100. Settings that would break the editor or stop security updates (update.mode,
+// extensions.autoUpdate) are deliberately left out.
+var privacySettings = []privacySetting{
+	{"telemetry.telemetryLevel", "off", 30, "Stops usage, error and crash telemetry being sent to Microsoft"},
+	{"telemetry.enableTelemetry", false, 10, "Legacy switch still honored by older VS Code versions and extensions"},
+	{"telemetry.enableCrashReporter", false, 10, "Legacy switch that stops crash dumps being uploaded"},
+	{"workbench.enableExperiments", false, 15, "Stops fetching and reporting A/B experiments"},
+	{"workbench.settings.enableNaturalLanguageSearch", false, 10, "Keeps settings search queries off Microsoft's online service"},
+	{"extensions.ignoreRecommendations", true, 5, "Stops recommendations derived from the files you open"},
+	{"typescript.surveys.enabled", false, 5, "Stops TypeScript survey prompts that report usage"},
+	{"npm.fetchOnlinePackageInfo", false, 5, "Stops package.json hovers querying the npm registry"},
+	{"redhat.telemetry.enabled", false, 5, "Disables telemetry of Red Hat extensions (YAML, Java, XML)"},
+	{"update.showReleaseNotes", false, 5, "Stops release notes being downloaded after each update"},
+}
+
+// SuggestOptimalSettings scores the VS Code user settings from 0 to 100 by how many
+// telemetry settings are opted out and recommends the changes for maximum privacy
+func (ca *ConfigAnalyzer) SuggestOptimalSettings() (*SettingsSuggestion, error) {
+	settingsPath, err := ca.paths.UserSettingsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings path: %w", err)
+	}
+
+	settings := make(map[string]interface{})
+	data, err := os.ReadFile(settingsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(stripJSONC(data), &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	}
+
+	suggestion := suggestSettings(settings)
+	suggestion.SettingsPath = settingsPath
+	return suggestion, nil
+}
+
+// suggestSettings scores parsed settings and lists the changes needed for a full score
+func suggestSettings(settings map[string]interface{}) *SettingsSuggestion {
+	suggestion := &SettingsSuggestion{RecommendedChanges: make([]SettingChange, 0)}
+
+	for _, setting := range privacySettings {
+		suggestion.PotentialScore += setting.Weight
+
+		current, found := settings[setting.Key]
+		if found && reflect.DeepEqual(current, setting.PrivateValue) {
+			suggestion.CurrentScore += setting.Weight
+			continue
+		}
+
+		suggestion.RecommendedChanges = append(suggestion.RecommendedChanges, SettingChange{
+			Key:              setting.Key,
+			CurrentValue:     current,
+			RecommendedValue: setting.PrivateValue,
+			Reason:           setting.Reason,
+		})
+	}
+
+	sort.SliceStable(suggestion.RecommendedChanges, func(i, j int) bool {
+		return suggestion.RecommendedChanges[i].Key < suggestion.RecommendedChanges[j].Key
+	})
+
+	return suggestion
+}
+
+// Patch returns the recommended changes as a settings.json object to merge into the user settings
+func (s *SettingsSuggestion) Patch() map[string]interface{} {
+	patch := make(map[string]interface{}, len(s.RecommendedChanges))
+	for _, change := range s.RecommendedChanges {
+		patch[change.Key] = change.RecommendedValue
+	}
+	return patch
+}
+
+// stripJSONC removes comments and trailing commas from VS Code's JSON-with-comments
+// settings format so it can be parsed with encoding/json
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ']' || c == '}':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
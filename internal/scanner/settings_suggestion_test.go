@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSuggestSettingsScoresOptedOutSettings(t *testing.T) {
+	settings := map[string]interface{}{
+		"telemetry.telemetryLevel":    "off",
+		"workbench.enableExperiments": true,
+		"editor.fontSize":             float64(14),
+	}
+
+	suggestion := suggestSettings(settings)
+
+	if suggestion.CurrentScore != 30 {
+		t.Errorf("Expected current score 30, got %d", suggestion.CurrentScore)
+	}
+	if suggestion.PotentialScore != 100 {
+		t.Errorf("Expected potential score 100, got %d", suggestion.PotentialScore)
+	}
+	if len(suggestion.RecommendedChanges) != len(privacySettings)-1 {
+		t.Fatalf("Expected %d recommended changes, got %d", len(privacySettings)-1, len(suggestion.RecommendedChanges))
+	}
+
+	var experiments *SettingChange
+	for i, change := range suggestion.RecommendedChanges {
+		if change.Key == "telemetry.telemetryLevel" {
+			t.Error("Did not expect a change for a setting that is already opted out")
+		}
+		if change.Key == "workbench.enableExperiments" {
+			experiments = &suggestion.RecommendedChanges[i]
+		}
+	}
+	if experiments == nil || experiments.CurrentValue != true || experiments.RecommendedValue != false {
+		t.Errorf("Unexpected change for workbench.enableExperiments: %+v", experiments)
+	}
+
+	patch := suggestion.Patch()
+	if patch["workbench.enableExperiments"] != false || patch["telemetry.enableTelemetry"] != false {
+		t.Errorf("Unexpected settings patch: %v", patch)
+	}
+}
+
+func TestSuggestSettingsFullyPrivate(t *testing.T) {
+	settings := make(map[string]interface{})
+	for _, setting := range privacySettings {
+		settings[setting.Key] = setting.PrivateValue
+	}
+
+	suggestion := suggestSettings(settings)
+	if suggestion.CurrentScore != 100 || len(suggestion.RecommendedChanges) != 0 {
+		t.Errorf("Expected a full score without changes, got %+v", suggestion)
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	input := `{
+	// Line comment
+	"telemetry.telemetryLevel": "off", /* block */
+	"url": "https://example.com/*not-a-comment*/",
+	"list": [1, 2,],
+}`
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(stripJSONC([]byte(input)), &settings); err != nil {
+		t.Fatalf("Failed to parse stripped settings: %v", err)
+	}
+
+	if settings["telemetry.telemetryLevel"] != "off" {
+		t.Errorf("Unexpected telemetry level: %v", settings["telemetry.telemetryLevel"])
+	}
+	if settings["url"] != "https://example.com/*not-a-comment*/" {
+		t.Errorf("Expected comment markers inside strings to be kept, got %v", settings["url"])
+	}
+	if list, ok := settings["list"].([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("Expected trailing comma to be removed, got %v", settings["list"])
+	}
+}
@@ -5,21 +5,48 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"augment-telemetry-cleaner/internal/sanitize"
 	"augment-telemetry-cleaner/internal/utils"
 )
 
 // StorageAnalysisResult represents the result of comprehensive storage analysis
 type StorageAnalysisResult struct {
+	Anonymized               bool                     `json:"anonymized,omitempty"`   // Set by AnonymizeScanResult
+	ToolVersion              string                   `json:"tool_version,omitempty"` // Version of the tool that wrote an anonymized report
 	GlobalStorageAnalysis    GlobalStorageAnalysis    `json:"global_storage_analysis"`
 	WorkspaceStorageAnalysis WorkspaceStorageAnalysis `json:"workspace_storage_analysis"`
 	CacheAnalysis           CacheAnalysis            `json:"cache_analysis"`
 	TempFileAnalysis        TempFileAnalysis         `json:"temp_file_analysis"`
 	CrossExtensionData      []CrossExtensionData     `json:"cross_extension_data"`
+	SizeLimitViolations     []SizeLimitViolation     `json:"size_limit_violations"`
+	RetentionViolations     []RetentionViolation     `json:"retention_violations,omitempty"` // Items older than a declared retention policy
+	SecretStoreAnalysis     []SecretEntry            `json:"secret_store_analysis"`
+	PrivacyOptimization     *SettingsSuggestion      `json:"privacy_optimization,omitempty"`
+	NetworkAnalysis         *NetworkAnalysis         `json:"network_analysis,omitempty"` // Only with SetDeepScan
+	PrivacyScores           map[string]PrivacyScore  `json:"privacy_scores,omitempty"`   // Keyed by extension ID
+	GrowthAnalysis          *GrowthAnalysis          `json:"growth_analysis,omitempty"` // Set by callers comparing against the previous scan
 	StorageStatistics       StorageStatistics        `json:"storage_statistics"`
+	ScannedAt               time.Time                `json:"scanned_at"`
 	ScanDuration            time.Duration            `json:"scan_duration"`
+	PhaseDurations          PhaseDurations           `json:"phase_durations"`
+	AnalysisIncomplete      bool                     `json:"analysis_incomplete,omitempty"`
+	IncompleteReason        string                   `json:"incomplete_reason,omitempty"`
+	SkippedExtensions       []string                 `json:"skipped_extensions,omitempty"`
+}
+
+// PhaseDurations is the time a scan spent in each phase; phases that did not
+// run or finish, such as cache and temp files of a single-extension scan or the
+// phases of a scan that timed out, are zero
+type PhaseDurations struct {
+	GlobalStorage    time.Duration `json:"global_storage"`
+	WorkspaceStorage time.Duration `json:"workspace_storage"`
+	Cache            time.Duration `json:"cache"`
+	TempFiles        time.Duration `json:"temp_files"`
 }
 
 // GlobalStorageAnalysis represents analysis of global storage
@@ -29,6 +56,7 @@ type GlobalStorageAnalysis struct {
 	TelemetrySize     int64              `json:"telemetry_size"`
 	ExtensionCount    int                `json:"extension_count"`
 	TelemetryCount    int                `json:"telemetry_count"`
+	RiskDistribution  map[TelemetryRisk]int `json:"risk_distribution,omitempty"` // Storage items per risk level
 }
 
 // WorkspaceStorageAnalysis represents analysis of workspace storage
@@ -50,13 +78,21 @@ type ExtensionStorage struct {
 	LastAccessed      time.Time           `json:"last_accessed"`
 	DataCategories    []string            `json:"data_categories"`
 	Risk              TelemetryRisk       `json:"risk"`
+	RiskDistribution  map[TelemetryRisk]int `json:"risk_distribution,omitempty"`
 	RetentionPolicy   RetentionPolicy     `json:"retention_policy"`
+	SkippedFiles      []SkippedFile       `json:"skipped_files,omitempty"`
+	Coverage          CoverageStats       `json:"coverage"`
+	ManifestInfo      *ManifestTelemetryInfo `json:"manifest_info,omitempty"` // Global storage of installed extensions only
+	Trusted           bool                `json:"trusted,omitempty"` // On the scan allowlist, so the storage was not analyzed
+	Note              string              `json:"note,omitempty"`
 }
 
 // WorkspaceStorage represents storage data for a workspace
 type WorkspaceStorage struct {
 	WorkspaceHash     string            `json:"workspace_hash"`
 	WorkspacePath     string            `json:"workspace_path,omitempty"`
+	Exists            bool              `json:"exists"`
+	ExistsUnknown     bool              `json:"exists_unknown,omitempty"` // The folder could not be checked, e.g. an offline share
 	ExtensionStorages []ExtensionStorage `json:"extension_storages"`
 	TotalSize         int64             `json:"total_size"`
 	TelemetrySize     int64             `json:"telemetry_size"`
@@ -74,6 +110,7 @@ type StorageDataItem struct {
 	Description     string        `json:"description"`
 	LastModified    time.Time     `json:"last_modified"`
 	AccessFrequency int           `json:"access_frequency"`
+	Reasons         []string      `json:"reasons,omitempty"` // Each matched pattern and how it contributed to Risk
 }
 
 // CacheAnalysis represents analysis of extension cache files
@@ -159,24 +196,86 @@ type StorageStatistics struct {
 	TempFileSize        int64   `json:"temp_file_size"`
 	OldestData          time.Time `json:"oldest_data"`
 	NewestData          time.Time `json:"newest_data"`
+	TopTelemetryItems   []TopTelemetryItem `json:"top_telemetry_items"`
+	TopExtensions       []TopExtension     `json:"top_extensions"`
+	SkippedFileCount    int                `json:"skipped_file_count"`
+	Coverage            CoverageStats      `json:"coverage"` // Files of all extension storages
+	SizeThreshold       int64              `json:"size_threshold,omitempty"` // Smallest JSON value listed, see SetSizeThreshold
 }
 
 // StorageAnalyzer handles comprehensive analysis of extension storage
 type StorageAnalyzer struct {
 	telemetryPatterns    map[string]TelemetryRisk
+	telemetryMatcher     *patternMatcher // Compiled telemetryPatterns
+	patternSources       map[string]string // Merged pattern -> pattern file it came from
+	valueMatcher         *AdvancedPatternMatcher // Assesses stored values by their format
 	cachePatterns        map[string]TelemetryRisk
+	cacheMatcher         *patternMatcher // Compiled cachePatterns
 	retentionAnalyzer    *RetentionAnalyzer
 	correlationAnalyzer  *CorrelationAnalyzer
+	storageLimits        map[string]int64
+	scanAllowlist        map[string]bool // Lowercase extension IDs full scans trust, see SetScanAllowlist
+	secretStoreScanner   *SecretStoreScanner
+	topOffenderCount     int
+	concurrency          ConcurrencyConfig
+	resolver             utils.PathResolver
+	paths                *utils.VSCodePaths
+
+	deepScan            bool // Analyze extension bundles for telemetry endpoints
+	guessWorkspaces     bool // Search project directories for workspace settings, see SetGuessWorkspaceFolders
+	sizeThreshold       int64 // Smallest JSON value reported as a storage item, see SetSizeThreshold
+	scanSecretStore     bool  // Look for Augment tokens in the OS secret store, see SetScanSecretStore
+	knownWorkspacesOnce sync.Once
+	knownWorkspaces     map[string]string // Workspace hash -> folder, see knownWorkspaceHashes
+}
+
+// SetGuessWorkspaceFolders makes privacy scoring fall back to searching common
+// project directories for workspace settings when VS Code lists no recent workspaces
+func (sa *StorageAnalyzer) SetGuessWorkspaceFolders(guess bool) {
+	sa.guessWorkspaces = guess
+}
+
+// SetSizeThreshold skips JSON keys whose values are smaller than minBytes, so a
+// quick scan only reports large data blobs; 0 reports every key
+func (sa *StorageAnalyzer) SetSizeThreshold(minBytes int64) {
+	sa.sizeThreshold = minBytes
+}
+
+// SetScanSecretStore makes full scans also list Augment tokens in the OS secret
+// store. It is off by default, as the probe runs secret-tool, security or cmdkey
+// and may prompt to unlock the keychain.
+func (sa *StorageAnalyzer) SetScanSecretStore(scan bool) {
+	sa.scanSecretStore = scan
+}
+
+// SetSecretStoreScanner replaces the scanner used for the secret store probe,
+// e.g. with one created by NewSecretStoreScannerWithRunner in tests
+func (sa *StorageAnalyzer) SetSecretStoreScanner(secretScanner *SecretStoreScanner) {
+	sa.secretStoreScanner = secretScanner
 }
 
 // NewStorageAnalyzer creates a new storage analyzer
 func NewStorageAnalyzer() *StorageAnalyzer {
+	return NewStorageAnalyzerWithResolver(utils.DefaultPathResolver())
+}
+
+// NewStorageAnalyzerWithResolver creates a storage analyzer that derives the
+// storage, cache and temp locations from resolver
+func NewStorageAnalyzerWithResolver(resolver utils.PathResolver) *StorageAnalyzer {
 	analyzer := &StorageAnalyzer{
 		retentionAnalyzer:   NewRetentionAnalyzer(),
 		correlationAnalyzer: NewCorrelationAnalyzer(),
+		storageLimits:       loadDefaultStorageLimits(),
+		secretStoreScanner:  NewSecretStoreScanner(),
+		valueMatcher:        NewAdvancedPatternMatcher(),
+		topOffenderCount:    DefaultTopOffenderCount,
+		concurrency:         DefaultConcurrencyConfig(),
+		resolver:            resolver,
+		paths:               utils.NewVSCodePaths(resolver),
 	}
 	analyzer.initializeTelemetryPatterns()
 	analyzer.initializeCachePatterns()
+	analyzer.SetScanAllowlist(DefaultScanAllowlist())
 	return analyzer
 }
 
@@ -226,6 +325,7 @@ func (sa *StorageAnalyzer) initializeTelemetryPatterns() {
 		"serverEndpoints":      TelemetryRiskMedium,
 		"networkLogs":          TelemetryRiskMedium,
 	}
+	sa.telemetryMatcher = newPatternMatcher(sa.telemetryPatterns)
 }
 
 // initializeCachePatterns sets up patterns for cache file analysis
@@ -256,46 +356,89 @@ func (sa *StorageAnalyzer) initializeCachePatterns() {
 		"tmp":                  TelemetryRiskLow,
 		"log":                  TelemetryRiskMedium,
 	}
+	sa.cacheMatcher = newPatternMatcher(sa.cachePatterns)
 }
 
-// AnalyzeStorage performs comprehensive storage analysis
-func (sa *StorageAnalyzer) AnalyzeStorage() (*StorageAnalysisResult, error) {
-	startTime := time.Now()
+// AnalyzeStorage performs comprehensive storage analysis. A non-empty extensionID
+// limits the analysis to that extension's global and workspace storage; other
+// extension directories are not walked, and the machine-wide cache, temp file and
+// secret store phases are skipped. An extensionID that no detected extension has
+// returns an ExtensionNotFoundError listing similar IDs.
+func (sa *StorageAnalyzer) AnalyzeStorage(extensionID string) (*StorageAnalysisResult, error) {
+	return sa.analyzeStorage(nil, extensionID)
+}
+
+// analyzeStorage performs the storage analysis, reporting progress to monitor if set
+func (sa *StorageAnalyzer) analyzeStorage(monitor *analysisMonitor, extensionID string) (*StorageAnalysisResult, error) {
+	scanElapsed := utils.StartTimer()
+	
+	if err := checkExtensionFilter(sa.paths, extensionID); err != nil {
+		return nil, err
+	}
 	
 	result := &StorageAnalysisResult{
-		CrossExtensionData: make([]CrossExtensionData, 0),
+		CrossExtensionData:  make([]CrossExtensionData, 0),
+		SizeLimitViolations: make([]SizeLimitViolation, 0),
+		SecretStoreAnalysis: make([]SecretEntry, 0),
+		ScannedAt:           time.Now(),
 	}
 
 	// Analyze global storage
-	globalAnalysis, err := sa.analyzeGlobalStorage()
+	phaseElapsed := utils.StartTimer()
+	globalAnalysis, err := sa.analyzeGlobalStorage(monitor, extensionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze global storage: %w", err)
 	}
 	result.GlobalStorageAnalysis = *globalAnalysis
+	result.PhaseDurations.GlobalStorage = phaseElapsed()
+
+	// Flag extensions whose storage is larger than expected
+	for _, extensionStorage := range globalAnalysis.ExtensionStorages {
+		violations := sa.CheckStorageSizeLimits(extensionStorage)
+		result.SizeLimitViolations = append(result.SizeLimitViolations, violations...)
+	}
 
 	// Analyze workspace storage
-	workspaceAnalysis, err := sa.analyzeWorkspaceStorage()
+	phaseElapsed = utils.StartTimer()
+	workspaceAnalysis, err := sa.analyzeWorkspaceStorage(monitor, extensionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze workspace storage: %w", err)
 	}
 	result.WorkspaceStorageAnalysis = *workspaceAnalysis
+	result.PhaseDurations.WorkspaceStorage = phaseElapsed()
 
-	// Analyze cache files
-	cacheAnalysis, err := sa.analyzeCacheFiles()
-	if err != nil {
-		// Continue even if cache analysis fails
-		result.CacheAnalysis = CacheAnalysis{}
-	} else {
-		result.CacheAnalysis = *cacheAnalysis
+	// Flag data kept longer than the retention policy its extension declares
+	result.RetentionViolations = sa.checkRetentionViolations(globalAnalysis.ExtensionStorages, workspaceAnalysis.WorkspaceStorages)
+
+	// The caller has stopped waiting; the remaining phases would be thrown away
+	if monitor.stopped() {
+		return nil, errAnalysisStopped
 	}
 
-	// Analyze temporary files
-	tempAnalysis, err := sa.analyzeTempFiles()
-	if err != nil {
-		// Continue even if temp file analysis fails
-		result.TempFileAnalysis = TempFileAnalysis{}
-	} else {
-		result.TempFileAnalysis = *tempAnalysis
+	// Cache and temp files are shared by all extensions, so a single
+	// extension's analysis leaves them out
+	if extensionID == "" {
+		// Analyze cache files
+		phaseElapsed = utils.StartTimer()
+		cacheAnalysis, err := sa.analyzeCacheFiles()
+		if err != nil {
+			// Continue even if cache analysis fails
+			result.CacheAnalysis = CacheAnalysis{}
+		} else {
+			result.CacheAnalysis = *cacheAnalysis
+		}
+		result.PhaseDurations.Cache = phaseElapsed()
+
+		// Analyze temporary files
+		phaseElapsed = utils.StartTimer()
+		tempAnalysis, err := sa.analyzeTempFiles()
+		if err != nil {
+			// Continue even if temp file analysis fails
+			result.TempFileAnalysis = TempFileAnalysis{}
+		} else {
+			result.TempFileAnalysis = *tempAnalysis
+		}
+		result.PhaseDurations.TempFiles = phaseElapsed()
 	}
 
 	// Perform cross-extension correlation analysis
@@ -305,62 +448,119 @@ func (sa *StorageAnalyzer) AnalyzeStorage() (*StorageAnalysisResult, error) {
 	)
 	result.CrossExtensionData = crossExtensionData
 
+	// Look for Augment tokens in the OS secret store
+	if sa.scanSecretStore && extensionID == "" {
+		if secrets, err := sa.secretStoreScanner.ScanForAugmentSecrets(); err == nil {
+			result.SecretStoreAnalysis = append(result.SecretStoreAnalysis, secrets...)
+		}
+	}
+
+	// Deep scan: find the telemetry endpoints extension bundles send requests to
+	if sa.deepScan {
+		if networkAnalysis, err := sa.analyzeNetworkRequests(extensionID); err == nil {
+			result.NetworkAnalysis = networkAnalysis
+		}
+	}
+
+	// Score the user settings; unreadable settings just leave the section out
+	if suggestion, err := NewConfigAnalyzerWithResolver(sa.resolver).SuggestOptimalSettings(); err == nil {
+		result.PrivacyOptimization = suggestion
+	}
+
+	// Sort results so output is stable between runs
+	sortStorageAnalysisResult(result)
+
+	// Score each extension by its telemetry storage and settings, after sorting
+	// so penalty reasons come out in a stable order
+	result.PrivacyScores = sa.scorePrivacy(result)
+
 	// Calculate overall statistics
 	result.StorageStatistics = sa.calculateStorageStatistics(result)
-	result.ScanDuration = time.Since(startTime)
+	result.ScanDuration = scanElapsed()
 
 	return result, nil
 }
 
-// analyzeGlobalStorage analyzes global storage for all extensions
-func (sa *StorageAnalyzer) analyzeGlobalStorage() (*GlobalStorageAnalysis, error) {
-	globalStoragePath, err := sa.getGlobalStoragePath()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get global storage path: %w", err)
+// scorePrivacy scores every extension with global storage. The user's
+// telemetry.telemetryLevel applies to all of them, so it is added to each
+// extension's own settings.
+func (sa *StorageAnalyzer) scorePrivacy(result *StorageAnalysisResult) map[string]PrivacyScore {
+	calculator := NewPrivacyScoreCalculator()
+	calculator.SetNetworkAnalysis(result.NetworkAnalysis)
+
+	var globalSettings []ExtensionSetting
+	if result.PrivacyOptimization != nil && !hasRecommendedChange(result.PrivacyOptimization, "telemetry.telemetryLevel") {
+		globalSettings = append(globalSettings, ExtensionSetting{
+			SettingKey:   "telemetry.telemetryLevel",
+			SettingValue: "off",
+			Source:       "user",
+			Category:     "telemetry",
+		})
 	}
 
-	analysis := &GlobalStorageAnalysis{
-		ExtensionStorages: make([]ExtensionStorage, 0),
+	settingsScanner := NewExtensionSettingsScannerWithResolver(sa.resolver)
+	settingsScanner.SetGuessWorkspaceFolders(sa.guessWorkspaces)
+	settings := settingsScanner.scanSettings()
+	scores := make(map[string]PrivacyScore, len(result.GlobalStorageAnalysis.ExtensionStorages))
+	for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+		extensionSettings := append(settingsForExtension(storage.ExtensionID, settings), globalSettings...)
+		scores[storage.ExtensionID] = calculator.Score(storage, extensionSettings)
 	}
+	return scores
+}
 
-	if _, err := os.Stat(globalStoragePath); os.IsNotExist(err) {
-		return analysis, nil // No global storage directory
+// hasRecommendedChange reports whether the suggestion still recommends changing key
+func hasRecommendedChange(suggestion *SettingsSuggestion, key string) bool {
+	for _, change := range suggestion.RecommendedChanges {
+		if change.Key == key {
+			return true
+		}
 	}
+	return false
+}
 
-	entries, err := os.ReadDir(globalStoragePath)
+// analyzeGlobalStorage analyzes global storage for all extensions, or only extensionID if set
+func (sa *StorageAnalyzer) analyzeGlobalStorage(monitor *analysisMonitor, extensionID string) (*GlobalStorageAnalysis, error) {
+	globalStoragePaths, err := sa.paths.GlobalStoragePaths()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read global storage directory: %w", err)
+		return nil, fmt.Errorf("failed to get global storage path: %w", err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		extensionID := entry.Name()
-		extensionStoragePath := filepath.Join(globalStoragePath, extensionID)
-		
-		extensionStorage, err := sa.analyzeExtensionStorage(extensionID, extensionStoragePath, "global")
+	var analysis *GlobalStorageAnalysis
+	for _, globalStoragePath := range globalStoragePaths {
+		pathAnalysis, err := sa.analyzeGlobalStorageWorkers(globalStoragePath, sa.concurrency.AnalysisWorkers, monitor, extensionID)
 		if err != nil {
-			continue // Skip extensions we can't analyze
+			return nil, err
 		}
-
-		analysis.ExtensionStorages = append(analysis.ExtensionStorages, *extensionStorage)
-		analysis.TotalSize += extensionStorage.TotalSize
-		analysis.TelemetrySize += extensionStorage.TelemetrySize
-		
-		if extensionStorage.Risk >= TelemetryRiskMedium {
-			analysis.TelemetryCount++
+		if analysis == nil {
+			analysis = pathAnalysis
+			continue
 		}
+		analysis.merge(pathAnalysis)
 	}
-
-	analysis.ExtensionCount = len(analysis.ExtensionStorages)
 	return analysis, nil
 }
 
-// analyzeWorkspaceStorage analyzes workspace storage for all workspaces
-func (sa *StorageAnalyzer) analyzeWorkspaceStorage() (*WorkspaceStorageAnalysis, error) {
-	workspaceStoragePath, err := utils.GetWorkspaceStoragePath()
+// merge adds the extension storages of other, e.g. those of a Snap or Flatpak
+// install, to the analysis
+func (a *GlobalStorageAnalysis) merge(other *GlobalStorageAnalysis) {
+	a.ExtensionStorages = append(a.ExtensionStorages, other.ExtensionStorages...)
+	a.TotalSize += other.TotalSize
+	a.TelemetrySize += other.TelemetrySize
+	a.TelemetryCount += other.TelemetryCount
+	a.ExtensionCount = len(a.ExtensionStorages)
+	for risk, count := range other.RiskDistribution {
+		if a.RiskDistribution == nil {
+			a.RiskDistribution = make(map[TelemetryRisk]int)
+		}
+		a.RiskDistribution[risk] += count
+	}
+}
+
+// analyzeWorkspaceStorage analyzes workspace storage for all workspaces. With
+// extensionID set, only workspaces with storage of that extension are included.
+func (sa *StorageAnalyzer) analyzeWorkspaceStorage(monitor *analysisMonitor, extensionID string) (*WorkspaceStorageAnalysis, error) {
+	workspaceStoragePaths, err := sa.paths.WorkspaceStoragePaths()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
 	}
@@ -369,31 +569,40 @@ func (sa *StorageAnalyzer) analyzeWorkspaceStorage() (*WorkspaceStorageAnalysis,
 		WorkspaceStorages: make([]WorkspaceStorage, 0),
 	}
 
-	if _, err := os.Stat(workspaceStoragePath); os.IsNotExist(err) {
-		return analysis, nil // No workspace storage directory
-	}
-
-	workspaceEntries, err := os.ReadDir(workspaceStoragePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read workspace storage directory: %w", err)
-	}
-
-	for _, workspaceEntry := range workspaceEntries {
-		if !workspaceEntry.IsDir() {
-			continue
+	for _, workspaceStoragePath := range workspaceStoragePaths {
+		if _, err := os.Stat(workspaceStoragePath); os.IsNotExist(err) {
+			continue // No workspace storage directory
 		}
 
-		workspaceHash := workspaceEntry.Name()
-		workspaceHashPath := filepath.Join(workspaceStoragePath, workspaceHash)
-		
-		workspaceStorage, err := sa.analyzeWorkspaceStorageDirectory(workspaceHash, workspaceHashPath)
+		workspaceEntries, err := os.ReadDir(workspaceStoragePath)
 		if err != nil {
-			continue // Skip workspaces we can't analyze
+			return nil, fmt.Errorf("failed to read workspace storage directory: %w", err)
 		}
 
-		analysis.WorkspaceStorages = append(analysis.WorkspaceStorages, *workspaceStorage)
-		analysis.TotalSize += workspaceStorage.TotalSize
-		analysis.TelemetrySize += workspaceStorage.TelemetrySize
+		for _, workspaceEntry := range workspaceEntries {
+			if !workspaceEntry.IsDir() {
+				continue
+			}
+			if monitor.stopped() {
+				break
+			}
+
+			workspaceHash := workspaceEntry.Name()
+			workspaceHashPath := filepath.Join(workspaceStoragePath, workspaceHash)
+
+			workspaceStorage, err := sa.analyzeWorkspaceStorageDirectory(workspaceHash, workspaceHashPath, extensionID)
+			if err != nil {
+				continue // Skip workspaces we can't analyze
+			}
+			if extensionID != "" && len(workspaceStorage.ExtensionStorages) == 0 {
+				continue // The extension has no storage in this workspace
+			}
+
+			analysis.WorkspaceStorages = append(analysis.WorkspaceStorages, *workspaceStorage)
+			analysis.TotalSize += workspaceStorage.TotalSize
+			analysis.TelemetrySize += workspaceStorage.TelemetrySize
+			monitor.workspaceDone(*workspaceStorage)
+		}
 	}
 
 	analysis.WorkspaceCount = len(analysis.WorkspaceStorages)
@@ -410,15 +619,19 @@ func (sa *StorageAnalyzer) analyzeWorkspaceStorage() (*WorkspaceStorageAnalysis,
 	return analysis, nil
 }
 
-// analyzeWorkspaceStorageDirectory analyzes a specific workspace storage directory
-func (sa *StorageAnalyzer) analyzeWorkspaceStorageDirectory(workspaceHash, workspaceHashPath string) (*WorkspaceStorage, error) {
+// analyzeWorkspaceStorageDirectory analyzes a specific workspace storage directory,
+// walking only the storage of extensionID if set
+func (sa *StorageAnalyzer) analyzeWorkspaceStorageDirectory(workspaceHash, workspaceHashPath, extensionID string) (*WorkspaceStorage, error) {
 	workspaceStorage := &WorkspaceStorage{
 		WorkspaceHash:     workspaceHash,
 		ExtensionStorages: make([]ExtensionStorage, 0),
 	}
 
-	// Try to determine workspace path from hash (this is complex and may not always work)
-	workspaceStorage.WorkspacePath = sa.resolveWorkspacePath(workspaceHash)
+	// Resolve the workspace folder from workspace.json
+	location := sa.resolveWorkspacePath(workspaceHash, workspaceHashPath)
+	workspaceStorage.WorkspacePath = location.Path
+	workspaceStorage.Exists = location.Exists
+	workspaceStorage.ExistsUnknown = location.Unknown
 
 	extensionEntries, err := os.ReadDir(workspaceHashPath)
 	if err != nil {
@@ -431,10 +644,13 @@ func (sa *StorageAnalyzer) analyzeWorkspaceStorageDirectory(workspaceHash, works
 			continue
 		}
 
-		extensionID := extensionEntry.Name()
-		extensionStoragePath := filepath.Join(workspaceHashPath, extensionID)
+		if !matchesExtension(extensionID, extensionEntry.Name()) {
+			continue
+		}
+
+		extensionStoragePath := filepath.Join(workspaceHashPath, extensionEntry.Name())
 		
-		extensionStorage, err := sa.analyzeExtensionStorage(extensionID, extensionStoragePath, "workspace")
+		extensionStorage, err := sa.analyzeOrTrustExtensionStorage(extensionID, extensionEntry.Name(), extensionStoragePath, "workspace")
 		if err != nil {
 			continue // Skip extensions we can't analyze
 		}
@@ -474,6 +690,7 @@ func (sa *StorageAnalyzer) analyzeExtensionStorage(extensionID, storagePath, sto
 	// Walk through all files in the storage directory
 	err = filepath.Walk(storagePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			storage.Coverage.recordWalkError(err)
 			return nil // Continue despite errors
 		}
 
@@ -482,6 +699,7 @@ func (sa *StorageAnalyzer) analyzeExtensionStorage(extensionID, storagePath, sto
 		}
 
 		// Analyze the file
+		storage.Coverage.FilesSeen++
 		sa.analyzeStorageFile(path, info, storage)
 		return nil
 	})
@@ -491,7 +709,9 @@ func (sa *StorageAnalyzer) analyzeExtensionStorage(extensionID, storagePath, sto
 	}
 
 	// Determine overall risk level
-	storage.Risk = sa.calculateStorageRisk(storage.StorageItems)
+	risks := sa.aggregateStorageRisk(storage.StorageItems)
+	storage.Risk = risks.MaxRisk()
+	storage.RiskDistribution = risks.Count()
 
 	// Extract unique data categories
 	categorySet := make(map[string]bool)
@@ -514,7 +734,7 @@ func (sa *StorageAnalyzer) analyzeStorageFile(filePath string, info os.FileInfo,
 	fileName := strings.ToLower(info.Name())
 	
 	// Determine file risk based on name and content
-	risk := sa.assessFileRisk(fileName, filePath)
+	risk, _, reasons := explainRisk(sa.fileRiskMatches(fileName, filePath))
 	
 	if risk == TelemetryRiskNone {
 		return // Skip files with no telemetry risk
@@ -535,6 +755,7 @@ func (sa *StorageAnalyzer) analyzeStorageFile(filePath string, info os.FileInfo,
 			Description:     sa.getFileDescription(fileName, risk),
 			LastModified:    info.ModTime(),
 			AccessFrequency: sa.estimateAccessFrequency(info),
+			Reasons:         reasons,
 		}
 		
 		storage.StorageItems = append(storage.StorageItems, item)
@@ -547,155 +768,152 @@ func (sa *StorageAnalyzer) analyzeStorageFile(filePath string, info os.FileInfo,
 
 // analyzeJSONStorageFile analyzes a JSON storage file in detail
 func (sa *StorageAnalyzer) analyzeJSONStorageFile(filePath string, info os.FileInfo, storage *ExtensionStorage) {
+	// Never load huge files into memory; stream them or record them as skipped
+	if info.Size() > MaxJSONStreamSize {
+		storage.SkippedFiles = append(storage.SkippedFiles, SkippedFile{Path: filePath, Size: info.Size(), Reason: SkipReasonTooLarge})
+		storage.Coverage.SkippedTooLarge++
+		return
+	}
+	if info.Size() > MaxJSONParseSize {
+		if err := sa.analyzeLargeJSONStorageFile(filePath, info, storage); err != nil {
+			storage.SkippedFiles = append(storage.SkippedFiles, SkippedFile{Path: filePath, Size: info.Size(), Reason: skipReason(err)})
+			storage.Coverage.recordFileError(err)
+		}
+		return
+	}
+
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return // Skip files we can't read
+		storage.SkippedFiles = append(storage.SkippedFiles, SkippedFile{Path: filePath, Size: info.Size(), Reason: SkipReasonUnreadable})
+		storage.Coverage.recordFileError(err)
+		return
 	}
 
 	var jsonData interface{}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
-		return // Skip files we can't parse
+		storage.SkippedFiles = append(storage.SkippedFiles, SkippedFile{Path: filePath, Size: info.Size(), Reason: SkipReasonInvalidJSON})
+		storage.Coverage.recordFileError(err)
+		return
 	}
 
 	// Analyze JSON structure recursively
 	sa.analyzeJSONData(jsonData, filepath.Base(filePath), "", info, storage)
 }
 
-// analyzeJSONData recursively analyzes JSON data structure
-func (sa *StorageAnalyzer) analyzeJSONData(data interface{}, fileName, keyPath string, info os.FileInfo, storage *ExtensionStorage) {
+// analyzeJSONData recursively analyzes JSON data structure and returns the size of
+// data encoded as JSON. Sizes are added up bottom-up, so every value is encoded
+// once however deeply it is nested.
+func (sa *StorageAnalyzer) analyzeJSONData(data interface{}, fileName, keyPath string, info os.FileInfo, storage *ExtensionStorage) int64 {
 	switch v := data.(type) {
 	case map[string]interface{}:
+		size := jsonContainerSize(len(v))
 		for key, value := range v {
 			currentPath := key
 			if keyPath != "" {
 				currentPath = keyPath + "." + key
 			}
-			
-			risk := sa.assessKeyRisk(key, currentPath, value)
+
+			// Nested keys are analyzed first to measure the value; the key's own item
+			// is inserted before theirs
+			start := len(storage.StorageItems)
+			valueSize := sa.analyzeJSONData(value, fileName, currentPath, info, storage)
+			size += sa.estimateValueSize(key) + 1 + valueSize
+
+			// Values below the size threshold are skipped without matching patterns
+			risk := TelemetryRiskNone
+			var reasons []string
+			if valueSize >= sa.sizeThreshold {
+				risk, _, reasons = explainRisk(sa.keyRiskMatches(key, currentPath, value))
+			}
 			if risk > TelemetryRiskNone {
 				item := StorageDataItem{
 					Key:             currentPath,
-					Value:           sa.sanitizeValue(value),
-					Size:            sa.estimateValueSize(value),
+					Value:           sanitize.KeyValue(key, value),
+					Size:            valueSize,
 					Type:            "json_key",
 					Risk:            risk,
 					Category:        sa.categorizeKey(key),
 					Description:     sa.getKeyDescription(key, risk),
 					LastModified:    info.ModTime(),
 					AccessFrequency: sa.estimateAccessFrequency(info),
+					Reasons:         reasons,
 				}
-				
-				storage.StorageItems = append(storage.StorageItems, item)
-				
+
+				storage.StorageItems = slices.Insert(storage.StorageItems, start, item)
+
 				if risk >= TelemetryRiskMedium {
 					storage.TelemetrySize += item.Size
 				}
 			}
-			
-			// Recurse into nested objects
-			sa.analyzeJSONData(value, fileName, currentPath, info, storage)
 		}
+		return size
 	case []interface{}:
 		// For arrays, analyze each element
+		size := jsonContainerSize(len(v))
 		for i, item := range v {
 			arrayPath := fmt.Sprintf("%s[%d]", keyPath, i)
-			sa.analyzeJSONData(item, fileName, arrayPath, info, storage)
+			size += sa.analyzeJSONData(item, fileName, arrayPath, info, storage)
 		}
+		return size
 	}
+	return sa.estimateValueSize(data)
 }
 
-// Helper methods for storage analysis
-
-// getGlobalStoragePath returns the global storage path
-func (sa *StorageAnalyzer) getGlobalStoragePath() (string, error) {
-	homeDir, err := utils.GetHomeDir()
-	if err != nil {
-		return "", err
+// jsonContainerSize returns the size of the brackets and commas of a JSON object
+// or array with n elements
+func jsonContainerSize(n int) int64 {
+	if n == 0 {
+		return 2
 	}
+	return int64(n + 1) // Two brackets and n-1 commas
+}
 
-	switch utils.GetOS() {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "globalStorage"), nil
-
-	case "darwin":
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage"), nil
+// Helper methods for storage analysis
 
-	default: // Linux and other Unix-like systems
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage"), nil
-	}
+// assessFileRisk assesses the telemetry risk of a file
+func (sa *StorageAnalyzer) assessFileRisk(fileName, filePath string) TelemetryRisk {
+	risk, _, _ := explainRisk(sa.fileRiskMatches(fileName, filePath))
+	return risk
 }
 
-// resolveWorkspacePath attempts to resolve workspace path from hash
-func (sa *StorageAnalyzer) resolveWorkspacePath(workspaceHash string) string {
-	// This is a simplified implementation
-	// In practice, VS Code uses a complex hashing algorithm
-	// and the reverse mapping is not straightforward
-	return fmt.Sprintf("Unknown workspace (hash: %s)", workspaceHash[:8])
+// fileRiskMatches returns every telemetry pattern in a file's name or path
+func (sa *StorageAnalyzer) fileRiskMatches(fileName, filePath string) []patternMatch {
+	return sa.telemetryMatcher.appendMatches(nil, strings.ToLower(fileName), strings.ToLower(filePath))
 }
 
-// assessFileRisk assesses the telemetry risk of a file
-func (sa *StorageAnalyzer) assessFileRisk(fileName, filePath string) TelemetryRisk {
-	lowerName := strings.ToLower(fileName)
-	lowerPath := strings.ToLower(filePath)
-	
-	// Check against telemetry patterns
-	maxRisk := TelemetryRiskNone
-	for pattern, risk := range sa.telemetryPatterns {
-		if strings.Contains(lowerName, strings.ToLower(pattern)) ||
-		   strings.Contains(lowerPath, strings.ToLower(pattern)) {
-			if risk > maxRisk {
-				maxRisk = risk
-			}
-		}
+// keyRiskMatches returns every telemetry pattern in a JSON key, its path or its
+// string value, and the risk of the value's format such as a UUID or email address
+func (sa *StorageAnalyzer) keyRiskMatches(key, fullPath string, value interface{}) []patternMatch {
+	lowerValue := ""
+	if valueStr, ok := value.(string); ok {
+		lowerValue = strings.ToLower(valueStr)
 	}
-	
-	return maxRisk
-}
+	matches := sa.telemetryMatcher.appendMatches(nil, strings.ToLower(key), strings.ToLower(fullPath), lowerValue)
 
-// assessKeyRisk assesses the telemetry risk of a JSON key
-func (sa *StorageAnalyzer) assessKeyRisk(key, fullPath string, value interface{}) TelemetryRisk {
-	lowerKey := strings.ToLower(key)
-	lowerPath := strings.ToLower(fullPath)
-	
-	// Check against telemetry patterns
-	maxRisk := TelemetryRiskNone
-	for pattern, risk := range sa.telemetryPatterns {
-		if strings.Contains(lowerKey, strings.ToLower(pattern)) ||
-		   strings.Contains(lowerPath, strings.ToLower(pattern)) {
-			if risk > maxRisk {
-				maxRisk = risk
-			}
-		}
+	// A value that is itself an identifier raises the risk of an innocuous key
+	if risk, explanation := sa.valueMatcher.AnalyzeStorageValue(fullPath, value); risk > TelemetryRiskNone {
+		matches = append(matches, patternMatch{pattern: "value format", risk: risk, explanation: explanation})
 	}
-	
-	// Check value content for additional patterns
-	if valueStr, ok := value.(string); ok {
-		lowerValue := strings.ToLower(valueStr)
-		for pattern, risk := range sa.telemetryPatterns {
-			if strings.Contains(lowerValue, strings.ToLower(pattern)) {
-				if risk > maxRisk {
-					maxRisk = risk
-				}
-			}
-		}
+	if match, ok := sa.customKeyRiskMatch(key, fullPath, lowerValue); ok {
+		matches = append(matches, match)
 	}
-	
-	return maxRisk
+	return matches
 }
 
-// calculateStorageRisk calculates overall risk for extension storage
-func (sa *StorageAnalyzer) calculateStorageRisk(items []StorageDataItem) TelemetryRisk {
-	maxRisk := TelemetryRiskNone
+// AssessKeyRisk returns the telemetry risk a storage scan gives a top-level JSON
+// key with value, from the key's name and the value's content and format
+func (sa *StorageAnalyzer) AssessKeyRisk(key string, value interface{}) TelemetryRisk {
+	risk, _, _ := explainRisk(sa.keyRiskMatches(key, key, value))
+	return risk
+}
+
+// aggregateStorageRisk aggregates the risk of the items in extension storage
+func (sa *StorageAnalyzer) aggregateStorageRisk(items []StorageDataItem) *RiskAggregator {
+	risks := NewRiskAggregator()
 	for _, item := range items {
-		if item.Risk > maxRisk {
-			maxRisk = item.Risk
-		}
+		risks.Add(item.Risk)
 	}
-	return maxRisk
+	return risks
 }
 
 // categorizeFile categorizes a file based on its name
@@ -751,26 +969,6 @@ func (sa *StorageAnalyzer) getKeyDescription(key string, risk TelemetryRisk) str
 	return fmt.Sprintf("Storage key with %s telemetry risk", risk.String())
 }
 
-// sanitizeValue sanitizes a value for safe display
-func (sa *StorageAnalyzer) sanitizeValue(value interface{}) interface{} {
-	if str, ok := value.(string); ok {
-		if len(str) > 100 {
-			return str[:100] + "... (truncated)"
-		}
-		
-		// Mask potentially sensitive data
-		lowerStr := strings.ToLower(str)
-		if strings.Contains(lowerStr, "key") ||
-		   strings.Contains(lowerStr, "token") ||
-		   strings.Contains(lowerStr, "secret") ||
-		   strings.Contains(lowerStr, "password") {
-			return "[SENSITIVE DATA MASKED]"
-		}
-	}
-	
-	return value
-}
-
 // estimateValueSize estimates the size of a JSON value
 func (sa *StorageAnalyzer) estimateValueSize(value interface{}) int64 {
 	data, err := json.Marshal(value)
@@ -798,7 +996,7 @@ func (sa *StorageAnalyzer) estimateAccessFrequency(info os.FileInfo) int {
 
 // calculateStorageStatistics calculates overall storage statistics
 func (sa *StorageAnalyzer) calculateStorageStatistics(result *StorageAnalysisResult) StorageStatistics {
-	stats := StorageStatistics{}
+	stats := StorageStatistics{SizeThreshold: sa.sizeThreshold}
 	
 	// Calculate totals from all storage types
 	stats.TotalStorageSize = result.GlobalStorageAnalysis.TotalSize +
@@ -832,6 +1030,22 @@ func (sa *StorageAnalyzer) calculateStorageStatistics(result *StorageAnalysisRes
 			stats.NewestData = ext.LastAccessed
 		}
 	}
+
+	// Report where the telemetry bytes are
+	stats.TopTelemetryItems = findTopTelemetryItems(result, sa.topOffenderCount)
+	stats.TopExtensions = findTopExtensions(result, sa.topOffenderCount)
+
+	// Make coverage gaps visible
+	for _, ext := range result.GlobalStorageAnalysis.ExtensionStorages {
+		stats.SkippedFileCount += len(ext.SkippedFiles)
+		stats.Coverage.Add(ext.Coverage)
+	}
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		for _, ext := range workspace.ExtensionStorages {
+			stats.SkippedFileCount += len(ext.SkippedFiles)
+			stats.Coverage.Add(ext.Coverage)
+		}
+	}
 	
 	return stats
 }
@@ -922,18 +1136,19 @@ func (sa *StorageAnalyzer) analyzeTempFiles() (*TempFileAnalysis, error) {
 func (sa *StorageAnalyzer) getCacheDirectories() []string {
 	var directories []string
 
-	homeDir, err := utils.GetHomeDir()
+	homeDir, err := sa.paths.HomeDir()
+	if err != nil {
+		return directories
+	}
+	cacheDir, err := sa.paths.CacheDir()
 	if err != nil {
 		return directories
 	}
 
-	switch utils.GetOS() {
+	switch sa.paths.OS() {
 	case "windows":
 		// Windows cache locations
-		localAppData := os.Getenv("LOCALAPPDATA")
-		if localAppData == "" {
-			localAppData = filepath.Join(homeDir, "AppData", "Local")
-		}
+		localAppData := cacheDir
 		
 		directories = append(directories,
 			filepath.Join(localAppData, "Microsoft", "vscode-cpptools"),
@@ -946,17 +1161,14 @@ func (sa *StorageAnalyzer) getCacheDirectories() []string {
 	case "darwin":
 		// macOS cache locations
 		directories = append(directories,
-			filepath.Join(homeDir, "Library", "Caches", "com.microsoft.VSCode"),
-			filepath.Join(homeDir, "Library", "Caches", "vscode-extensions"),
+			filepath.Join(cacheDir, "com.microsoft.VSCode"),
+			filepath.Join(cacheDir, "vscode-extensions"),
 			filepath.Join("/tmp", "vscode-extensions"),
 		)
 
 	default: // Linux
 		// Linux cache locations
-		xdgCache := os.Getenv("XDG_CACHE_HOME")
-		if xdgCache == "" {
-			xdgCache = filepath.Join(homeDir, ".cache")
-		}
+		xdgCache := cacheDir
 		
 		directories = append(directories,
 			filepath.Join(xdgCache, "vscode-extensions"),
@@ -976,9 +1188,9 @@ func (sa *StorageAnalyzer) getTempDirectories() []string {
 	directories = append(directories, os.TempDir())
 
 	// User-specific temp directories
-	homeDir, err := utils.GetHomeDir()
+	homeDir, err := sa.paths.HomeDir()
 	if err == nil {
-		switch utils.GetOS() {
+		switch sa.paths.OS() {
 		case "windows":
 			directories = append(directories,
 				filepath.Join(homeDir, "AppData", "Local", "Temp"),
@@ -1173,21 +1385,7 @@ func (sa *StorageAnalyzer) inferFileType(fileName string) string {
 
 // assessCacheFileRisk assesses the telemetry risk of a cache file
 func (sa *StorageAnalyzer) assessCacheFileRisk(fileName, filePath string) TelemetryRisk {
-	lowerName := strings.ToLower(fileName)
-	lowerPath := strings.ToLower(filePath)
-	
-	// Check against cache patterns
-	maxRisk := TelemetryRiskNone
-	for pattern, risk := range sa.cachePatterns {
-		if strings.Contains(lowerName, strings.ToLower(pattern)) ||
-		   strings.Contains(lowerPath, strings.ToLower(pattern)) {
-			if risk > maxRisk {
-				maxRisk = risk
-			}
-		}
-	}
-	
-	return maxRisk
+	return sa.cacheMatcher.highestRisk(strings.ToLower(fileName), strings.ToLower(filePath))
 }
 
 // assessTempFileRisk assesses the telemetry risk of a temporary file
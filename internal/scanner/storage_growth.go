@@ -0,0 +1,196 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// DefaultGrowthThresholdBytesPerDay is the daily telemetry storage growth above
+// which an extension is reported as fast-growing
+const DefaultGrowthThresholdBytesPerDay = 1 << 20
+
+// GrowthEntry is the telemetry storage growth of one extension between two scans
+type GrowthEntry struct {
+	ExtensionID          string        `json:"extension_id"`
+	PreviousSizeBytes    int64         `json:"previous_size_bytes"`
+	CurrentSizeBytes     int64         `json:"current_size_bytes"`
+	GrowthPercent        float64       `json:"growth_percent"` // Zero when the previous scan found no telemetry
+	GrowthPeriod         time.Duration `json:"growth_period"`
+	DailyGrowthRateBytes int64         `json:"daily_growth_rate_bytes"`
+}
+
+// GrowthAnalysis compares the telemetry storage of a scan with the previous one
+type GrowthAnalysis struct {
+	PreviousScanAt       time.Time `json:"previous_scan_at"`
+	ThresholdBytesPerDay int64     `json:"threshold_bytes_per_day"`
+	// FastGrowingExtensions grew faster than the threshold, fastest first
+	FastGrowingExtensions []GrowthEntry `json:"fast_growing_extensions"`
+}
+
+// StorageGrowthAnalyzer finds extensions whose telemetry storage grows abnormally
+// fast between scans
+type StorageGrowthAnalyzer struct {
+	thresholdBytesPerDay int64
+}
+
+// NewStorageGrowthAnalyzer creates a growth analyzer using
+// DefaultGrowthThresholdBytesPerDay
+func NewStorageGrowthAnalyzer() *StorageGrowthAnalyzer {
+	return &StorageGrowthAnalyzer{thresholdBytesPerDay: DefaultGrowthThresholdBytesPerDay}
+}
+
+// SetThreshold sets the daily growth above which an extension is reported; values
+// below 1 restore the default
+func (ga *StorageGrowthAnalyzer) SetThreshold(bytesPerDay int64) {
+	if bytesPerDay < 1 {
+		bytesPerDay = DefaultGrowthThresholdBytesPerDay
+	}
+	ga.thresholdBytesPerDay = bytesPerDay
+}
+
+// AnalyzeGrowth compares the telemetry size of each extension found by both
+// scans, summed over global and workspace storage, and reports the extensions
+// whose daily growth exceeds the threshold. It returns nil when there is no
+// previous scan or the scans carry no usable timestamps.
+func (ga *StorageGrowthAnalyzer) AnalyzeGrowth(previous, current *StorageAnalysisResult) *GrowthAnalysis {
+	if previous == nil || current == nil || previous.ScannedAt.IsZero() {
+		return nil
+	}
+	period := current.ScannedAt.Sub(previous.ScannedAt)
+	if period <= 0 {
+		return nil
+	}
+
+	analysis := &GrowthAnalysis{
+		PreviousScanAt:        previous.ScannedAt,
+		ThresholdBytesPerDay:  ga.thresholdBytesPerDay,
+		FastGrowingExtensions: make([]GrowthEntry, 0),
+	}
+
+	previousSizes := extensionTelemetrySizes(previous)
+	for extensionID, currentSize := range extensionTelemetrySizes(current) {
+		previousSize, found := previousSizes[extensionID]
+		if !found || currentSize <= previousSize {
+			continue
+		}
+
+		growth := currentSize - previousSize
+		daily := int64(float64(growth) * float64(24*time.Hour) / float64(period))
+		if daily <= ga.thresholdBytesPerDay {
+			continue
+		}
+
+		entry := GrowthEntry{
+			ExtensionID:          extensionID,
+			PreviousSizeBytes:    previousSize,
+			CurrentSizeBytes:     currentSize,
+			GrowthPeriod:         period,
+			DailyGrowthRateBytes: daily,
+		}
+		if previousSize > 0 {
+			entry.GrowthPercent = float64(growth) / float64(previousSize) * 100
+		}
+		analysis.FastGrowingExtensions = append(analysis.FastGrowingExtensions, entry)
+	}
+
+	sort.Slice(analysis.FastGrowingExtensions, func(i, j int) bool {
+		a, b := analysis.FastGrowingExtensions[i], analysis.FastGrowingExtensions[j]
+		if a.DailyGrowthRateBytes != b.DailyGrowthRateBytes {
+			return a.DailyGrowthRateBytes > b.DailyGrowthRateBytes
+		}
+		return a.ExtensionID < b.ExtensionID
+	})
+	return analysis
+}
+
+// extensionTelemetrySizes sums the telemetry size of each extension over global
+// and workspace storage
+func extensionTelemetrySizes(result *StorageAnalysisResult) map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, storage := range result.GlobalStorageAnalysis.ExtensionStorages {
+		sizes[storage.ExtensionID] += storage.TelemetrySize
+	}
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		for _, storage := range workspace.ExtensionStorages {
+			sizes[storage.ExtensionID] += storage.TelemetrySize
+		}
+	}
+	return sizes
+}
+
+// DefaultLastScanPath returns the location of the snapshot of the last full scan
+func DefaultLastScanPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user directories: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "augment-telemetry-cleaner", "last-scan.json"), nil
+}
+
+// LoadLastScan reads the scan snapshot at path; a missing file yields nil
+func LoadLastScan(path string) (*StorageAnalysisResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read last scan: %w", err)
+	}
+
+	var result StorageAnalysisResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last scan: %w", err)
+	}
+	return &result, nil
+}
+
+// SaveLastScan writes the sizes of a scan to path for the next AnalyzeGrowth.
+// Only the extension IDs and sizes are kept; storage items and their values are
+// not written.
+func SaveLastScan(path string, result *StorageAnalysisResult) error {
+	snapshot := &StorageAnalysisResult{ScannedAt: result.ScannedAt}
+	snapshot.GlobalStorageAnalysis.ExtensionStorages = storageSizes(result.GlobalStorageAnalysis.ExtensionStorages)
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		snapshot.WorkspaceStorageAnalysis.WorkspaceStorages = append(snapshot.WorkspaceStorageAnalysis.WorkspaceStorages, WorkspaceStorage{
+			WorkspaceHash:     workspace.WorkspaceHash,
+			ExtensionStorages: storageSizes(workspace.ExtensionStorages),
+		})
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last scan: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create last scan directory: %w", err)
+	}
+	if err := utils.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write last scan: %w", err)
+	}
+	return nil
+}
+
+// storageSizes returns the extension storages with only their ID and sizes
+func storageSizes(storages []ExtensionStorage) []ExtensionStorage {
+	sizes := make([]ExtensionStorage, 0, len(storages))
+	for _, storage := range storages {
+		sizes = append(sizes, ExtensionStorage{
+			ExtensionID:   storage.ExtensionID,
+			TotalSize:     storage.TotalSize,
+			TelemetrySize: storage.TelemetrySize,
+		})
+	}
+	return sizes
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// growthScanResult returns a scan taken at scannedAt with the telemetry sizes
+// of global storage; workspace adds the same sizes again in one workspace
+func growthScanResult(scannedAt time.Time, sizes map[string]int64, workspace bool) *StorageAnalysisResult {
+	result := &StorageAnalysisResult{ScannedAt: scannedAt}
+	var storages []ExtensionStorage
+	for id, size := range sizes {
+		storages = append(storages, ExtensionStorage{ExtensionID: id, TotalSize: size * 2, TelemetrySize: size})
+	}
+	result.GlobalStorageAnalysis.ExtensionStorages = storages
+	if workspace {
+		result.WorkspaceStorageAnalysis.WorkspaceStorages = []WorkspaceStorage{{WorkspaceHash: "abc", ExtensionStorages: storages}}
+	}
+	return result
+}
+
+func TestAnalyzeGrowth(t *testing.T) {
+	previousAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	previous := growthScanResult(previousAt, map[string]int64{
+		"fast.grower": 1 << 20,
+		"slow.grower": 1 << 20,
+		"shrinking":   10 << 20,
+		"faster":      0,
+	}, false)
+	current := growthScanResult(previousAt.Add(48*time.Hour), map[string]int64{
+		"fast.grower": 2 << 20, // Twice in global and workspace storage: 3 MB growth
+		"slow.grower": 1<<20 + 100<<10,
+		"shrinking":   1 << 20,
+		"faster":      4 << 20,
+		"new.one":     100 << 20, // Not in the previous scan
+	}, true)
+
+	analysis := NewStorageGrowthAnalyzer().AnalyzeGrowth(previous, current)
+	if analysis == nil {
+		t.Fatal("Expected a growth analysis")
+	}
+	if len(analysis.FastGrowingExtensions) != 2 {
+		t.Fatalf("Expected 2 fast-growing extensions, got %+v", analysis.FastGrowingExtensions)
+	}
+
+	faster, fast := analysis.FastGrowingExtensions[0], analysis.FastGrowingExtensions[1]
+	if faster.ExtensionID != "faster" || faster.DailyGrowthRateBytes != 4<<20 || faster.GrowthPercent != 0 {
+		t.Errorf("Expected faster to grow 4 MB a day from nothing, got %+v", faster)
+	}
+	if fast.ExtensionID != "fast.grower" || fast.CurrentSizeBytes != 4<<20 || fast.DailyGrowthRateBytes != 3<<19 {
+		t.Errorf("Expected fast.grower to grow 1.5 MB a day, got %+v", fast)
+	}
+	if fast.GrowthPercent != 300 || fast.GrowthPeriod != 48*time.Hour {
+		t.Errorf("Expected 300%% growth over 48 hours, got %.1f%% over %s", fast.GrowthPercent, fast.GrowthPeriod)
+	}
+
+	analyzer := NewStorageGrowthAnalyzer()
+	analyzer.SetThreshold(10 << 20)
+	if analysis := analyzer.AnalyzeGrowth(previous, current); len(analysis.FastGrowingExtensions) != 0 {
+		t.Errorf("Expected no extension above 10 MB a day, got %+v", analysis.FastGrowingExtensions)
+	}
+
+	if NewStorageGrowthAnalyzer().AnalyzeGrowth(nil, current) != nil {
+		t.Error("Expected no analysis without a previous scan")
+	}
+	if NewStorageGrowthAnalyzer().AnalyzeGrowth(current, previous) != nil {
+		t.Error("Expected no analysis when the previous scan is newer")
+	}
+}
+
+func TestLastScanRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "last-scan.json")
+	if previous, err := LoadLastScan(path); err != nil || previous != nil {
+		t.Fatalf("Expected no last scan without a file, got %+v (%v)", previous, err)
+	}
+
+	scannedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := growthScanResult(scannedAt, map[string]int64{"some.extension": 5 << 20}, true)
+	result.GlobalStorageAnalysis.ExtensionStorages[0].StorageItems = []StorageDataItem{{Key: "machineId", Value: "secret"}}
+	if err := SaveLastScan(path, result); err != nil {
+		t.Fatalf("SaveLastScan failed: %v", err)
+	}
+
+	loaded, err := LoadLastScan(path)
+	if err != nil {
+		t.Fatalf("LoadLastScan failed: %v", err)
+	}
+	if !loaded.ScannedAt.Equal(scannedAt) {
+		t.Errorf("Expected the scan time %s, got %s", scannedAt, loaded.ScannedAt)
+	}
+	if sizes := extensionTelemetrySizes(loaded); sizes["some.extension"] != 10<<20 {
+		t.Errorf("Expected 10 MB of telemetry over global and workspace storage, got %v", sizes)
+	}
+	if items := loaded.GlobalStorageAnalysis.ExtensionStorages[0].StorageItems; len(items) != 0 {
+		t.Errorf("Expected storage items not to be saved, got %+v", items)
+	}
+}
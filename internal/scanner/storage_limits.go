@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+// defaultStorageLimitsJSON maps extension IDs to storage size limits in MB.
+// The "default" entry applies to extensions without their own limit.
+//
+//go:embed storage_limits.json
+var defaultStorageLimitsJSON []byte
+
+// defaultStorageLimitKey is the limit table entry used for unknown extensions
+const defaultStorageLimitKey = "default"
+
+// Size limit violation severities
+const (
+	SizeLimitSeverityMedium   = "medium"
+	SizeLimitSeverityHigh     = "high"
+	SizeLimitSeverityCritical = "critical"
+)
+
+// SizeLimitViolation describes an extension whose storage exceeds its expected size
+type SizeLimitViolation struct {
+	ExtensionID     string `json:"extension_id"`
+	ActualSizeBytes int64  `json:"actual_size_bytes"`
+	LimitBytes      int64  `json:"limit_bytes"`
+	Severity        string `json:"severity"`
+}
+
+// loadDefaultStorageLimits parses the embedded limit table (values in MB)
+func loadDefaultStorageLimits() map[string]int64 {
+	limits := make(map[string]int64)
+	if err := json.Unmarshal(defaultStorageLimitsJSON, &limits); err != nil {
+		// The table is embedded at build time, so this only fails on a broken build
+		limits = map[string]int64{defaultStorageLimitKey: 100}
+	}
+	return limits
+}
+
+// SetStorageLimitOverrides overrides storage size limits (extension ID -> MB).
+// Use the "default" key to change the limit for unknown extensions.
+func (sa *StorageAnalyzer) SetStorageLimitOverrides(overrides map[string]int64) {
+	for extensionID, limitMB := range overrides {
+		if limitMB > 0 {
+			sa.storageLimits[strings.ToLower(extensionID)] = limitMB
+		}
+	}
+}
+
+// CheckStorageSizeLimits reports a violation when the extension's storage exceeds its limit
+func (sa *StorageAnalyzer) CheckStorageSizeLimits(extensionStorage ExtensionStorage) []SizeLimitViolation {
+	limitMB, ok := sa.storageLimits[strings.ToLower(extensionStorage.ExtensionID)]
+	if !ok {
+		limitMB = sa.storageLimits[defaultStorageLimitKey]
+	}
+
+	limitBytes := limitMB * 1024 * 1024
+	if limitBytes <= 0 || extensionStorage.TotalSize <= limitBytes {
+		return nil
+	}
+
+	return []SizeLimitViolation{{
+		ExtensionID:     extensionStorage.ExtensionID,
+		ActualSizeBytes: extensionStorage.TotalSize,
+		LimitBytes:      limitBytes,
+		Severity:        sizeLimitSeverity(extensionStorage.TotalSize, limitBytes),
+	}}
+}
+
+// sizeLimitSeverity grades a violation by how far the size exceeds the limit
+func sizeLimitSeverity(actual, limit int64) string {
+	switch {
+	case actual >= limit*4:
+		return SizeLimitSeverityCritical
+	case actual >= limit*2:
+		return SizeLimitSeverityHigh
+	default:
+		return SizeLimitSeverityMedium
+	}
+}
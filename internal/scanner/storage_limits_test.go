@@ -0,0 +1,42 @@
+package scanner
+
+import "testing"
+
+func TestCheckStorageSizeLimits(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	const mb = 1024 * 1024
+
+	// Known extension with a bundled limit (vscode.git: 50MB)
+	violations := analyzer.CheckStorageSizeLimits(ExtensionStorage{ExtensionID: "vscode.git", TotalSize: 120 * mb})
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation for vscode.git, got %d", len(violations))
+	}
+	if violations[0].LimitBytes != 50*mb || violations[0].Severity != SizeLimitSeverityHigh {
+		t.Errorf("Unexpected violation: %+v", violations[0])
+	}
+
+	// Unknown extension below the default limit
+	if v := analyzer.CheckStorageSizeLimits(ExtensionStorage{ExtensionID: "some.extension", TotalSize: 90 * mb}); len(v) != 0 {
+		t.Errorf("Expected no violation below the default limit, got %+v", v)
+	}
+
+	// Unknown extension far above the default limit
+	violations = analyzer.CheckStorageSizeLimits(ExtensionStorage{ExtensionID: "some.extension", TotalSize: 500 * mb})
+	if len(violations) != 1 || violations[0].Severity != SizeLimitSeverityCritical {
+		t.Errorf("Expected a critical violation, got %+v", violations)
+	}
+}
+
+func TestStorageLimitOverrides(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	analyzer.SetStorageLimitOverrides(map[string]int64{"ms-python.python": 10, "default": 1})
+	const mb = 1024 * 1024
+
+	if v := analyzer.CheckStorageSizeLimits(ExtensionStorage{ExtensionID: "ms-python.python", TotalSize: 15 * mb}); len(v) != 1 {
+		t.Errorf("Expected override to lower the ms-python.python limit, got %+v", v)
+	}
+
+	if v := analyzer.CheckStorageSizeLimits(ExtensionStorage{ExtensionID: "other.extension", TotalSize: 2 * mb}); len(v) != 1 {
+		t.Errorf("Expected override of the default limit, got %+v", v)
+	}
+}
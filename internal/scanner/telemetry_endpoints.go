@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TelemetryEndpoint describes a known telemetry or analytics service by host
+type TelemetryEndpoint struct {
+	Host     string        `json:"host"` // Exact host, or a suffix when it starts with "."
+	Category string        `json:"category"`
+	Risk     TelemetryRisk `json:"risk"`
+}
+
+// TelemetryEndpointChecker classifies URLs found in extension code as telemetry
+// endpoints by their host, or by a telemetry-like path on any other host
+type TelemetryEndpointChecker struct {
+	endpoints    []TelemetryEndpoint
+	pathKeywords []string
+}
+
+// NewTelemetryEndpointChecker creates a checker with the known telemetry services
+func NewTelemetryEndpointChecker() *TelemetryEndpointChecker {
+	return &TelemetryEndpointChecker{
+		endpoints: []TelemetryEndpoint{
+			// VS Code extension telemetry (@vscode/extension-telemetry)
+			{".applicationinsights.azure.com", "Application Insights", TelemetryRiskCritical},
+			{"dc.services.visualstudio.com", "Application Insights", TelemetryRiskCritical},
+			{"mobile.events.data.microsoft.com", "Microsoft Telemetry", TelemetryRiskCritical},
+			{".events.data.microsoft.com", "Microsoft Telemetry", TelemetryRiskCritical},
+
+			// Product analytics
+			{"api.segment.io", "Product Analytics", TelemetryRiskHigh},
+			{"api.mixpanel.com", "Product Analytics", TelemetryRiskHigh},
+			{".amplitude.com", "Product Analytics", TelemetryRiskHigh},
+			{".posthog.com", "Product Analytics", TelemetryRiskHigh},
+			{"www.google-analytics.com", "Product Analytics", TelemetryRiskHigh},
+			{"region1.google-analytics.com", "Product Analytics", TelemetryRiskHigh},
+			{".googletagmanager.com", "Product Analytics", TelemetryRiskHigh},
+			{".heap.io", "Product Analytics", TelemetryRiskHigh},
+			{"rs.fullstory.com", "Session Recording", TelemetryRiskHigh},
+
+			// Error and performance reporting
+			{".sentry.io", "Error Reporting", TelemetryRiskMedium},
+			{".bugsnag.com", "Error Reporting", TelemetryRiskMedium},
+			{".datadoghq.com", "Error Reporting", TelemetryRiskMedium},
+			{".rollbar.com", "Error Reporting", TelemetryRiskMedium},
+		},
+		pathKeywords: []string{"telemetry", "analytics", "/track", "/collect", "/events", "/metrics"},
+	}
+}
+
+// Check classifies rawURL, returning ok false when it is not a telemetry endpoint
+func (tec *TelemetryEndpointChecker) Check(rawURL string) (category string, risk TelemetryRisk, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", TelemetryRiskNone, false
+	}
+	host := strings.ToLower(u.Hostname())
+
+	for _, endpoint := range tec.endpoints {
+		if host == endpoint.Host || (strings.HasPrefix(endpoint.Host, ".") && strings.HasSuffix(host, endpoint.Host)) {
+			return endpoint.Category, endpoint.Risk, true
+		}
+	}
+
+	lowerPath := strings.ToLower(u.Path)
+	for _, keyword := range tec.pathKeywords {
+		if strings.Contains(lowerPath, keyword) || strings.Contains(host, strings.Trim(keyword, "/")) {
+			return "Generic Telemetry", TelemetryRiskMedium, true
+		}
+	}
+
+	return "", TelemetryRiskNone, false
+}
+
+// Domains returns the domains of the known telemetry services, sorted and without
+// duplicates. Suffix hosts such as ".sentry.io" are returned as the domain they
+// match subdomains of.
+func (tec *TelemetryEndpointChecker) Domains() []string {
+	seen := make(map[string]bool)
+	var domains []string
+	for _, endpoint := range tec.endpoints {
+		domain := strings.TrimPrefix(endpoint.Host, ".")
+		if !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
@@ -0,0 +1,235 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// DefaultPatternUpdateURL is the manifest checked by --check-pattern-updates
+const DefaultPatternUpdateURL = "https://raw.githubusercontent.com/v-eenay/augment-telemetry-cleaner/main/patterns/manifest.json"
+
+// maxPatternDownloadSize caps the size of downloaded manifests and pattern databases
+const maxPatternDownloadSize = 1024 * 1024
+
+// TelemetryPatternDatabase is a versioned set of telemetry key patterns that
+// supplements the built-in patterns
+type TelemetryPatternDatabase struct {
+	Version   string                   `json:"version"`
+	UpdatedAt time.Time                `json:"updated_at"`
+	Patterns  map[string]TelemetryRisk `json:"patterns"`
+}
+
+// PatternManifest describes the latest published pattern database
+type PatternManifest struct {
+	Version     string    `json:"version"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	PatternsURL string    `json:"patterns_url"` // May be relative to the manifest URL
+}
+
+// DefaultPatternDatabasePath returns the location of the local pattern database
+func DefaultPatternDatabasePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user directories: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "augment-telemetry-cleaner", "patterns-db.json"), nil
+}
+
+// LoadTelemetryPatternDatabase reads a pattern database; a missing file yields an
+// empty, unversioned database
+func LoadTelemetryPatternDatabase(path string) (*TelemetryPatternDatabase, error) {
+	db := &TelemetryPatternDatabase{Patterns: make(map[string]TelemetryRisk)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return db, nil
+		}
+		return nil, fmt.Errorf("failed to read pattern database: %w", err)
+	}
+
+	if err := json.Unmarshal(data, db); err != nil {
+		return nil, fmt.Errorf("failed to parse pattern database: %w", err)
+	}
+	if db.Patterns == nil {
+		db.Patterns = make(map[string]TelemetryRisk)
+	}
+
+	return db, nil
+}
+
+// Save writes the pattern database to path
+func (db *TelemetryPatternDatabase) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create pattern database directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pattern database: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pattern database: %w", err)
+	}
+
+	return nil
+}
+
+// CheckForUpdates fetches the manifest at updateURL and, if it announces a newer
+// version, downloads the published database and merges its patterns. It reports
+// whether the database changed; the caller is responsible for saving it.
+func (db *TelemetryPatternDatabase) CheckForUpdates(httpClient *http.Client, updateURL string) (bool, error) {
+	var manifest PatternManifest
+	if err := fetchJSON(httpClient, updateURL, &manifest); err != nil {
+		return false, fmt.Errorf("failed to fetch pattern manifest: %w", err)
+	}
+
+	if manifest.Version == "" || manifest.PatternsURL == "" {
+		return false, fmt.Errorf("pattern manifest is missing version or patterns_url")
+	}
+
+	if compareVersions(manifest.Version, db.Version) <= 0 {
+		return false, nil
+	}
+
+	patternsURL, err := resolveReference(updateURL, manifest.PatternsURL)
+	if err != nil {
+		return false, err
+	}
+
+	var latest TelemetryPatternDatabase
+	if err := fetchJSON(httpClient, patternsURL, &latest); err != nil {
+		return false, fmt.Errorf("failed to download pattern database: %w", err)
+	}
+
+	for pattern, risk := range latest.Patterns {
+		if risk < TelemetryRiskNone || risk > TelemetryRiskCritical {
+			return false, fmt.Errorf("invalid risk %d for pattern %q", risk, pattern)
+		}
+	}
+
+	if db.Patterns == nil {
+		db.Patterns = make(map[string]TelemetryRisk)
+	}
+	for pattern, risk := range latest.Patterns {
+		db.Patterns[pattern] = risk
+	}
+
+	db.Version = manifest.Version
+	db.UpdatedAt = manifest.UpdatedAt
+	if db.UpdatedAt.IsZero() {
+		db.UpdatedAt = time.Now().UTC()
+	}
+
+	return true, nil
+}
+
+// MergeTelemetryPatterns adds or overrides telemetry patterns used by the analyzer
+func (sa *StorageAnalyzer) MergeTelemetryPatterns(patterns map[string]TelemetryRisk) {
+	sa.MergeTelemetryPatternsFrom(PatternSourceCustom, patterns)
+}
+
+// MergeTelemetryPatternsFrom merges patterns like MergeTelemetryPatterns and
+// records source, usually the pattern database file, as where they came from
+func (sa *StorageAnalyzer) MergeTelemetryPatternsFrom(source string, patterns map[string]TelemetryRisk) {
+	if sa.patternSources == nil {
+		sa.patternSources = make(map[string]string)
+	}
+	for pattern, risk := range patterns {
+		sa.telemetryPatterns[pattern] = risk
+		sa.patternSources[pattern] = source
+	}
+	sa.telemetryMatcher = newPatternMatcher(sa.telemetryPatterns)
+}
+
+// fetchJSON GETs url and decodes the JSON response body into v
+func fetchJSON(httpClient *http.Client, rawURL string, v interface{}) error {
+	resp, err := httpClient.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPatternDownloadSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > maxPatternDownloadSize {
+		return fmt.Errorf("response exceeds %d bytes", maxPatternDownloadSize)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// resolveReference resolves ref relative to base
+func resolveReference(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid manifest URL: %w", err)
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid patterns URL: %w", err)
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// compareVersions compares dotted numeric versions ("1.10.0" > "1.9.2"), returning
+// -1, 0 or 1. An empty version is older than any other version.
+func compareVersions(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		if numA < numB {
+			return -1
+		}
+		if numA > numB {
+			return 1
+		}
+	}
+
+	return 0
+}
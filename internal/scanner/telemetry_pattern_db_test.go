@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestTelemetryPatternDatabaseCheckForUpdates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/patterns/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "1.2.0", "updated_at": "2025-01-01T00:00:00Z", "patterns_url": "patterns-db.json"}`))
+	})
+	mux.HandleFunc("/patterns/patterns-db.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "1.2.0", "patterns": {"augmentSessionTrace": 4, "machineId": 3}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	db := &TelemetryPatternDatabase{
+		Version:  "1.1.0",
+		Patterns: map[string]TelemetryRisk{"legacyPattern": TelemetryRiskLow},
+	}
+
+	updated, err := db.CheckForUpdates(server.Client(), server.URL+"/patterns/manifest.json")
+	if err != nil {
+		t.Fatalf("CheckForUpdates() failed: %v", err)
+	}
+	if !updated {
+		t.Fatal("Expected the database to be updated")
+	}
+	if db.Version != "1.2.0" {
+		t.Errorf("Expected version 1.2.0, got %s", db.Version)
+	}
+	if db.Patterns["augmentSessionTrace"] != TelemetryRiskCritical || db.Patterns["legacyPattern"] != TelemetryRiskLow {
+		t.Errorf("Expected merged patterns, got %v", db.Patterns)
+	}
+
+	// The same version must not be downloaded again
+	updated, err = db.CheckForUpdates(server.Client(), server.URL+"/patterns/manifest.json")
+	if err != nil {
+		t.Fatalf("Second CheckForUpdates() failed: %v", err)
+	}
+	if updated {
+		t.Error("Expected no update for the current version")
+	}
+
+	// Round trip through disk
+	path := filepath.Join(t.TempDir(), "patterns-db.json")
+	if err := db.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := LoadTelemetryPatternDatabase(path)
+	if err != nil {
+		t.Fatalf("LoadTelemetryPatternDatabase() failed: %v", err)
+	}
+	if loaded.Version != "1.2.0" || len(loaded.Patterns) != 3 {
+		t.Errorf("Unexpected loaded database: %+v", loaded)
+	}
+}
+
+func TestLoadTelemetryPatternDatabaseMissingFile(t *testing.T) {
+	db, err := LoadTelemetryPatternDatabase(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadTelemetryPatternDatabase() failed: %v", err)
+	}
+	if db.Version != "" || len(db.Patterns) != 0 {
+		t.Errorf("Expected empty database, got %+v", db)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.2", 1},
+		{"1.0", "1.0.0", 0},
+		{"", "0.0.1", -1},
+		{"v2.0.0", "1.9.9", 1},
+		{"1.2.3", "1.2.4", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import "sort"
+
+// DefaultTopOffenderCount is the number of top items and extensions reported by default
+const DefaultTopOffenderCount = 10
+
+// TopTelemetryItem is one of the largest telemetry-risk storage items
+type TopTelemetryItem struct {
+	ExtensionID   string        `json:"extension_id"`
+	WorkspaceHash string        `json:"workspace_hash,omitempty"`
+	Key           string        `json:"key"`
+	Size          int64         `json:"size"`
+	Risk          TelemetryRisk `json:"risk"`
+	Reasons       []string      `json:"reasons,omitempty"`
+}
+
+// TopExtension is one of the extensions storing the most telemetry data
+type TopExtension struct {
+	ExtensionID   string `json:"extension_id"`
+	TelemetrySize int64  `json:"telemetry_size"`
+	TotalSize     int64  `json:"total_size"`
+}
+
+// SetTopOffenderCount sets how many top items and extensions are reported; values
+// below 1 restore the default
+func (sa *StorageAnalyzer) SetTopOffenderCount(n int) {
+	if n < 1 {
+		n = DefaultTopOffenderCount
+	}
+	sa.topOffenderCount = n
+}
+
+// findTopTelemetryItems returns the n largest telemetry-risk items across global and
+// workspace storage. Ties are broken by extension ID, workspace hash and key.
+func findTopTelemetryItems(result *StorageAnalysisResult, n int) []TopTelemetryItem {
+	items := make([]TopTelemetryItem, 0)
+
+	collect := func(storages []ExtensionStorage, workspaceHash string) {
+		for _, storage := range storages {
+			for _, item := range storage.StorageItems {
+				if item.Risk <= TelemetryRiskNone {
+					continue
+				}
+				items = append(items, TopTelemetryItem{
+					ExtensionID:   storage.ExtensionID,
+					WorkspaceHash: workspaceHash,
+					Key:           item.Key,
+					Size:          item.Size,
+					Risk:          item.Risk,
+					Reasons:       item.Reasons,
+				})
+			}
+		}
+	}
+
+	collect(result.GlobalStorageAnalysis.ExtensionStorages, "")
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		collect(workspace.ExtensionStorages, workspace.WorkspaceHash)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Size != items[j].Size {
+			return items[i].Size > items[j].Size
+		}
+		if items[i].ExtensionID != items[j].ExtensionID {
+			return items[i].ExtensionID < items[j].ExtensionID
+		}
+		if items[i].WorkspaceHash != items[j].WorkspaceHash {
+			return items[i].WorkspaceHash < items[j].WorkspaceHash
+		}
+		return items[i].Key < items[j].Key
+	})
+
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// findTopExtensions returns the n extensions with the most telemetry data, summed over
+// global and workspace storage. Ties are broken by extension ID.
+func findTopExtensions(result *StorageAnalysisResult, n int) []TopExtension {
+	totals := make(map[string]*TopExtension)
+
+	collect := func(storages []ExtensionStorage) {
+		for _, storage := range storages {
+			ext, ok := totals[storage.ExtensionID]
+			if !ok {
+				ext = &TopExtension{ExtensionID: storage.ExtensionID}
+				totals[storage.ExtensionID] = ext
+			}
+			ext.TelemetrySize += storage.TelemetrySize
+			ext.TotalSize += storage.TotalSize
+		}
+	}
+
+	collect(result.GlobalStorageAnalysis.ExtensionStorages)
+	for _, workspace := range result.WorkspaceStorageAnalysis.WorkspaceStorages {
+		collect(workspace.ExtensionStorages)
+	}
+
+	extensions := make([]TopExtension, 0, len(totals))
+	for _, ext := range totals {
+		if ext.TelemetrySize > 0 {
+			extensions = append(extensions, *ext)
+		}
+	}
+
+	sort.Slice(extensions, func(i, j int) bool {
+		if extensions[i].TelemetrySize != extensions[j].TelemetrySize {
+			return extensions[i].TelemetrySize > extensions[j].TelemetrySize
+		}
+		return extensions[i].ExtensionID < extensions[j].ExtensionID
+	})
+
+	if len(extensions) > n {
+		extensions = extensions[:n]
+	}
+	return extensions
+}
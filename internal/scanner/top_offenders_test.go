@@ -0,0 +1,77 @@
+package scanner
+
+import "testing"
+
+func TestTopOffenders(t *testing.T) {
+	result := &StorageAnalysisResult{
+		GlobalStorageAnalysis: GlobalStorageAnalysis{
+			ExtensionStorages: []ExtensionStorage{
+				{
+					ExtensionID:   "b.ext",
+					TotalSize:     500,
+					TelemetrySize: 300,
+					StorageItems: []StorageDataItem{
+						{Key: "machineId", Size: 200, Risk: TelemetryRiskHigh},
+						{Key: "theme", Size: 900, Risk: TelemetryRiskNone},
+					},
+				},
+				{
+					ExtensionID:   "a.ext",
+					TotalSize:     300,
+					TelemetrySize: 300,
+					StorageItems: []StorageDataItem{
+						{Key: "sessionId", Size: 200, Risk: TelemetryRiskMedium},
+					},
+				},
+			},
+		},
+		WorkspaceStorageAnalysis: WorkspaceStorageAnalysis{
+			WorkspaceStorages: []WorkspaceStorage{
+				{
+					WorkspaceHash: "hash1",
+					ExtensionStorages: []ExtensionStorage{
+						{
+							ExtensionID:   "c.ext",
+							TotalSize:     100,
+							TelemetrySize: 100,
+							StorageItems: []StorageDataItem{
+								{Key: "events", Size: 50, Risk: TelemetryRiskLow},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	items := findTopTelemetryItems(result, 2)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	// Equal sizes are ordered by extension ID; non-telemetry items are ignored
+	if items[0].ExtensionID != "a.ext" || items[1].ExtensionID != "b.ext" {
+		t.Errorf("Unexpected item order: %+v", items)
+	}
+
+	extensions := findTopExtensions(result, 10)
+	if len(extensions) != 3 {
+		t.Fatalf("Expected 3 extensions, got %d", len(extensions))
+	}
+	if extensions[0].ExtensionID != "a.ext" || extensions[1].ExtensionID != "b.ext" || extensions[2].ExtensionID != "c.ext" {
+		t.Errorf("Unexpected extension order: %+v", extensions)
+	}
+}
+
+func TestSetTopOffenderCount(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+
+	analyzer.SetTopOffenderCount(3)
+	if analyzer.topOffenderCount != 3 {
+		t.Errorf("Expected count 3, got %d", analyzer.topOffenderCount)
+	}
+
+	analyzer.SetTopOffenderCount(0)
+	if analyzer.topOffenderCount != DefaultTopOffenderCount {
+		t.Errorf("Expected default count, got %d", analyzer.topOffenderCount)
+	}
+}
@@ -0,0 +1,209 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// workspaceDescriptor mirrors workspaceStorage/<hash>/workspace.json
+type workspaceDescriptor struct {
+	Folder        string `json:"folder"`
+	Workspace     string `json:"workspace"`
+	Configuration string `json:"configuration"` // Older VS Code versions
+}
+
+// WorkspaceLocation describes the folder or .code-workspace file a workspace
+// storage directory belongs to
+type WorkspaceLocation struct {
+	Path   string `json:"path"`
+	Remote bool   `json:"remote"`
+	Exists bool   `json:"exists"`
+	// Unknown is set when the folder could not be checked, e.g. for lack of access
+	// rights, an unmounted drive or an offline network share; Exists is then false
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// statWorkspace checks a workspace folder; tests replace it to simulate errors
+var statWorkspace = os.Stat
+
+// ResolveWorkspaceLocation reads workspace.json in a workspaceStorage/<hash> directory
+// and resolves its folder, workspace or remote URI. Remote workspaces are returned as
+// "<authority>:<path>" and never reported as existing. A local folder is missing
+// only when it does not exist; any other error checking it sets Unknown.
+func ResolveWorkspaceLocation(workspaceHashPath string) (*WorkspaceLocation, error) {
+	data, err := os.ReadFile(filepath.Join(workspaceHashPath, "workspace.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace.json: %w", err)
+	}
+
+	var descriptor workspaceDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace.json: %w", err)
+	}
+
+	rawURI := descriptor.Folder
+	if rawURI == "" {
+		rawURI = descriptor.Workspace
+	}
+	if rawURI == "" {
+		rawURI = descriptor.Configuration
+	}
+	if rawURI == "" {
+		return nil, fmt.Errorf("workspace.json contains no folder or workspace URI")
+	}
+
+	path, local, err := workspaceURIToPath(rawURI)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &WorkspaceLocation{Path: path, Remote: !local}
+	if local {
+		_, err := statWorkspace(path)
+		switch {
+		case err == nil:
+			location.Exists = true
+		case !errors.Is(err, fs.ErrNotExist):
+			location.Unknown = true
+		}
+	}
+
+	return location, nil
+}
+
+// resolveWorkspacePath returns the resolved workspace location. Hashes of common
+// project folders are matched first, so workspaces are found even when
+// workspace.json is missing; otherwise workspace.json is read, falling back to a
+// placeholder path when it is missing or unreadable.
+func (sa *StorageAnalyzer) resolveWorkspacePath(workspaceHash, workspaceHashPath string) WorkspaceLocation {
+	if path, ok := sa.knownWorkspaceHashes()[workspaceHash]; ok {
+		return WorkspaceLocation{Path: path, Exists: true}
+	}
+
+	location, err := ResolveWorkspaceLocation(workspaceHashPath)
+	if err != nil {
+		return WorkspaceLocation{Path: fmt.Sprintf("Unknown workspace (hash: %s)", shortWorkspaceHash(workspaceHash))}
+	}
+	return *location
+}
+
+// commonProjectDirs are the home subdirectories whose children are hashed by
+// knownWorkspaceHashes, in addition to the home directory and its own children
+var commonProjectDirs = []string{
+	"projects", "Projects", "src", "code", "Code", "dev", "repos", "git",
+	"workspace", "workspaces", "Documents", "Desktop",
+}
+
+// knownWorkspaceHashes computes the workspace hash of the home directory, its
+// subdirectories and the folders in commonProjectDirs once per analyzer
+func (sa *StorageAnalyzer) knownWorkspaceHashes() map[string]string {
+	sa.knownWorkspacesOnce.Do(func() {
+		sa.knownWorkspaces = make(map[string]string)
+
+		homeDir, err := utils.GetHomeDir()
+		if err != nil {
+			return
+		}
+
+		candidates := []string{homeDir}
+		candidates = append(candidates, listSubdirectories(homeDir)...)
+		for _, dir := range commonProjectDirs {
+			candidates = append(candidates, listSubdirectories(filepath.Join(homeDir, dir))...)
+		}
+
+		for _, candidate := range candidates {
+			if hash, err := utils.ComputeWorkspaceHash(candidate); err == nil {
+				if _, seen := sa.knownWorkspaces[hash]; !seen {
+					sa.knownWorkspaces[hash] = candidate
+				}
+			}
+		}
+	})
+	return sa.knownWorkspaces
+}
+
+// listSubdirectories returns the paths of the directories directly inside dir
+func listSubdirectories(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// workspaceURIToPath converts a workspace URI to a native path. For non-file URIs
+// (vscode-remote://, vscode-vfs://, ...) it returns a readable "<authority>:<path>" form
+// and reports local as false.
+func workspaceURIToPath(rawURI string) (path string, local bool, err error) {
+	scheme, rest, ok := strings.Cut(rawURI, "://")
+	if !ok {
+		return "", false, fmt.Errorf("invalid workspace URI: %s", rawURI)
+	}
+
+	if strings.EqualFold(scheme, "file") {
+		u, err := url.Parse(rawURI)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to parse workspace URI: %w", err)
+		}
+		return fileURIPathToNative(u.Host, u.Path, runtime.GOOS), true, nil
+	}
+
+	// Remote authorities escape '+' as %2B (e.g. ssh-remote%2Bmyhost), which
+	// net/url rejects in hosts, so the authority is split off by hand
+	authority, remotePath, _ := strings.Cut(rest, "/")
+	if unescaped, err := url.PathUnescape(authority); err == nil {
+		authority = unescaped
+	}
+	if unescaped, err := url.PathUnescape("/" + remotePath); err == nil {
+		remotePath = unescaped
+	} else {
+		remotePath = "/" + remotePath
+	}
+
+	if authority == "" {
+		return remotePath, false, nil
+	}
+	return authority + ":" + remotePath, false, nil
+}
+
+// fileURIPathToNative converts the host and decoded path of a file:// URI to a native path
+func fileURIPathToNative(host, uriPath, goos string) string {
+	if goos != "windows" {
+		return uriPath
+	}
+
+	// UNC paths: file://server/share/dir -> \\server\share\dir
+	if host != "" {
+		return `\\` + host + strings.ReplaceAll(uriPath, "/", `\`)
+	}
+
+	// Drive letters: /c:/Users/me -> c:\Users\me
+	if len(uriPath) >= 3 && uriPath[0] == '/' && uriPath[2] == ':' {
+		uriPath = uriPath[1:]
+	}
+	return strings.ReplaceAll(uriPath, "/", `\`)
+}
+
+// shortWorkspaceHash returns the first 8 characters of a workspace hash
+func shortWorkspaceHash(workspaceHash string) string {
+	if len(workspaceHash) > 8 {
+		return workspaceHash[:8]
+	}
+	return workspaceHash
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestResolveWorkspacePath(t *testing.T) {
+	analyzer := NewStorageAnalyzer()
+	projectDir := filepath.Join(t.TempDir(), "my project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	folderURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(projectDir)}).String()
+
+	tests := []struct {
+		name       string
+		descriptor string
+		wantPath   string
+		wantExists bool
+	}{
+		{"existing folder", `{"folder": "` + folderURI + `"}`, projectDir, true},
+		{"missing folder", `{"folder": "file:///does/not/exist"}`, "/does/not/exist", false},
+		{"workspace file", `{"workspace": "file:///home/me/app.code-workspace"}`, "/home/me/app.code-workspace", false},
+		{"remote folder", `{"folder": "vscode-remote://ssh-remote%2Bmyhost/home/me/project"}`, "ssh-remote+myhost:/home/me/project", false},
+		{"empty descriptor", `{}`, "Unknown workspace (hash: abcdef12)", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(hashDir, "workspace.json"), []byte(tt.descriptor), 0644); err != nil {
+				t.Fatalf("Failed to write workspace.json: %v", err)
+			}
+
+			location := analyzer.resolveWorkspacePath("abcdef1234567890", hashDir)
+			if filepath.ToSlash(location.Path) != filepath.ToSlash(tt.wantPath) && location.Path != tt.wantPath {
+				t.Errorf("Expected path %q, got %q", tt.wantPath, location.Path)
+			}
+			if location.Exists != tt.wantExists {
+				t.Errorf("Expected exists=%v, got %v", tt.wantExists, location.Exists)
+			}
+		})
+	}
+}
+
+func TestResolveWorkspacePathByHash(t *testing.T) {
+	home := t.TempDir()
+	utils.SetHomeDirOverride(home)
+	defer utils.SetHomeDirOverride("")
+
+	projectDir := filepath.Join(home, "projects", "app")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	hash, err := utils.ComputeWorkspaceHash(projectDir)
+	if err != nil {
+		t.Fatalf("ComputeWorkspaceHash failed: %v", err)
+	}
+
+	// No workspace.json: the folder is only found through its hash
+	hashDir := filepath.Join(t.TempDir(), hash)
+	if err := os.MkdirAll(hashDir, 0755); err != nil {
+		t.Fatalf("Failed to create hash dir: %v", err)
+	}
+
+	location := NewStorageAnalyzer().resolveWorkspacePath(hash, hashDir)
+	wantPath, _ := filepath.EvalSymlinks(projectDir)
+	if location.Path != projectDir && location.Path != wantPath {
+		t.Errorf("Expected path %q, got %q", projectDir, location.Path)
+	}
+	if !location.Exists {
+		t.Error("Expected hash-matched workspace to exist")
+	}
+}
+
+func TestResolveWorkspaceLocationUnknownOnStatError(t *testing.T) {
+	hashDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(hashDir, "workspace.json"), []byte(`{"folder": "file:///mnt/share/project"}`), 0644); err != nil {
+		t.Fatalf("Failed to write workspace.json: %v", err)
+	}
+
+	defer func(stat func(string) (os.FileInfo, error)) { statWorkspace = stat }(statWorkspace)
+	for _, tt := range []struct {
+		name        string
+		err         error
+		wantUnknown bool
+	}{
+		{"missing", fs.ErrNotExist, false},
+		{"access denied", fs.ErrPermission, true},
+		{"offline share", errors.New("host is down"), true},
+	} {
+		statWorkspace = func(string) (os.FileInfo, error) { return nil, &fs.PathError{Op: "stat", Path: "/mnt/share/project", Err: tt.err} }
+		location, err := ResolveWorkspaceLocation(hashDir)
+		if err != nil {
+			t.Fatalf("%s: ResolveWorkspaceLocation() failed: %v", tt.name, err)
+		}
+		if location.Exists || location.Unknown != tt.wantUnknown {
+			t.Errorf("%s: expected exists=false unknown=%v, got %+v", tt.name, tt.wantUnknown, location)
+		}
+	}
+}
+
+func TestFileURIPathToNativeWindows(t *testing.T) {
+	if got := fileURIPathToNative("", "/c:/Users/me/project", "windows"); got != `c:\Users\me\project` {
+		t.Errorf("Unexpected drive path: %s", got)
+	}
+	if got := fileURIPathToNative("server", "/share/project", "windows"); got != `\\server\share\project` {
+		t.Errorf("Unexpected UNC path: %s", got)
+	}
+}
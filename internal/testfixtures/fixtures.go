@@ -0,0 +1,139 @@
+// Package testfixtures creates small browser databases holding known Augment and
+// unrelated data, so tests of the cleaning code can check what is deleted and
+// what is kept without a real browser profile.
+package testfixtures
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Counts of the rows, keys and directories the fixtures create
+const (
+	// AugmentCookies are the cookies of Augment domains or with Augment names
+	AugmentCookies = 3
+	// OtherCookies are the cookies of other sites, which must be kept
+	OtherCookies = 2
+
+	// AugmentLocalStorageKeys are the Local Storage keys of Augment origins
+	AugmentLocalStorageKeys = 3
+	// OtherLocalStorageKeys are VERSION and the keys of other origins, including
+	// one whose name mentions Augment
+	OtherLocalStorageKeys = 3
+
+	// AugmentStorageOrigins are the Firefox storage directories of Augment origins
+	AugmentStorageOrigins = 2
+	// OtherStorageOrigins are the Firefox storage directories of other origins
+	OtherStorageOrigins = 1
+)
+
+// cookieRow is a cookie written by the cookie fixtures
+type cookieRow struct {
+	host, name, value string
+}
+
+// cookieRows are the AugmentCookies followed by the OtherCookies
+var cookieRows = []cookieRow{
+	{".augmentcode.com", "session", "abc123"},
+	{"app.augmentcode.com", "_ga", "GA1.2.3"},
+	{"login.example.com", "augment_session", "xyz"},
+	{"github.com", "user_session", "s3cr3t"},
+	{".google.com", "NID", "511=abc"},
+}
+
+// CreateChromiumCookiesDB creates a Chromium Cookies database at path with a
+// cookies table holding AugmentCookies and OtherCookies
+func CreateChromiumCookiesDB(path string) error {
+	return createCookiesDB(path,
+		`CREATE TABLE cookies (creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, top_frame_site_key TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB DEFAULT '', path TEXT NOT NULL DEFAULT '/',
+			expires_utc INTEGER NOT NULL DEFAULT 0, is_secure INTEGER NOT NULL DEFAULT 1, is_httponly INTEGER NOT NULL DEFAULT 1,
+			last_access_utc INTEGER NOT NULL DEFAULT 0, has_expires INTEGER NOT NULL DEFAULT 1, is_persistent INTEGER NOT NULL DEFAULT 1,
+			priority INTEGER NOT NULL DEFAULT 1, samesite INTEGER NOT NULL DEFAULT -1, source_scheme INTEGER NOT NULL DEFAULT 2,
+			UNIQUE (host_key, top_frame_site_key, name, path))`,
+		`INSERT INTO cookies (creation_utc, host_key, name, value) VALUES (13350000000000000, ?, ?, ?)`)
+}
+
+// CreateFirefoxCookiesDB creates a Firefox cookies.sqlite database at path with a
+// moz_cookies table holding AugmentCookies and OtherCookies
+func CreateFirefoxCookiesDB(path string) error {
+	return createCookiesDB(path,
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, value TEXT,
+			host TEXT, path TEXT DEFAULT '/', expiry INTEGER DEFAULT 0, lastAccessed INTEGER DEFAULT 0, creationTime INTEGER DEFAULT 0,
+			isSecure INTEGER DEFAULT 1, isHttpOnly INTEGER DEFAULT 1, inBrowserElement INTEGER DEFAULT 0, sameSite INTEGER DEFAULT 0,
+			rawSameSite INTEGER DEFAULT 0, schemeMap INTEGER DEFAULT 0,
+			CONSTRAINT moz_uniqueid UNIQUE (name, host, path, originAttributes))`,
+		`INSERT INTO moz_cookies (host, name, value) VALUES (?, ?, ?)`)
+}
+
+// createCookiesDB creates the SQLite database at path with schema and inserts
+// cookieRows with insert, which takes the host, name and value
+func createCookiesDB(path, schema, insert string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+	for _, row := range cookieRows {
+		if _, err := db.Exec(insert, row.host, row.name, row.value); err != nil {
+			return fmt.Errorf("failed to insert cookie %s: %w", row.name, err)
+		}
+	}
+	return nil
+}
+
+// CreateChromiumLevelDB creates a Chromium Local Storage LevelDB database in the
+// directory path holding AugmentLocalStorageKeys and OtherLocalStorageKeys. The
+// keys of an origin are "_<origin>\x00<key>" and "META:<origin>".
+func CreateChromiumLevelDB(path string) error {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create leveldb: %w", err)
+	}
+	defer db.Close()
+
+	entries := map[string]string{
+		// Augment
+		"META:https://app.augmentcode.com":          "meta",
+		"METAACCESS:https://app.augmentcode.com":    "access",
+		"_https://app.augmentcode.com\x00\x01token": "secret",
+		// Other
+		"VERSION":                 "1",
+		"META:https://github.com": "meta",
+		"_https://github.com\x00\x01augment_theme": "dark",
+	}
+	for key, value := range entries {
+		if err := db.Put([]byte(key), []byte(value), nil); err != nil {
+			return fmt.Errorf("failed to put %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// CreateFirefoxStorage creates a Firefox storage/default directory at path with
+// AugmentStorageOrigins and OtherStorageOrigins, each holding a local storage
+// database file
+func CreateFirefoxStorage(path string) error {
+	for _, origin := range []string{"https+++app.augmentcode.com", "https+++www.augmentcode.com", "https+++github.com"} {
+		file := filepath.Join(path, origin, "ls", "data.sqlite")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", origin, err)
+		}
+		if err := os.WriteFile(file, []byte("local storage of "+origin), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CheckAccess verifies that path exists and can be opened for reading, or for
+// reading and writing when write is set. Files are opened without truncating and
+// closed again; directories are listed, and for write access a probe file is
+// created and removed in them. File contents are never read or changed.
+func CheckAccess(path string, write bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		dir, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		_, err = dir.Readdirnames(1)
+		dir.Close()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		if write {
+			return probeWritableDir(path)
+		}
+		return nil
+	}
+
+	flag := os.O_RDONLY
+	if write {
+		flag = os.O_RDWR
+	}
+	file, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// CheckWritableDir verifies that dir, or the closest existing parent it would be
+// created in, is a directory new files can be created in
+func CheckWritableDir(dir string) error {
+	existing := filepath.Clean(dir)
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			return probeWritableDir(existing)
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("no existing parent directory of %s", dir)
+		}
+		existing = parent
+	}
+}
+
+// probeWritableDir creates and removes a temporary file in dir
+func probeWritableDir(dir string) error {
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// atomicWriteHelperEnv makes the test binary start an atomic write to the file
+// named by it and stall halfway, so the parent can kill it mid-write
+const atomicWriteHelperEnv = "AUGMENT_TEST_ATOMIC_WRITE"
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	if err := os.WriteFile(path, []byte(`{"old": true}`), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(`{"new": true}`), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != `{"new": true}` {
+		t.Errorf("Expected new content, got %q (%v)", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600 to be kept, got %v (%v)", info.Mode().Perm(), err)
+		}
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestWriteFileAtomicNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.json")
+
+	if err := WriteFileAtomic(path, []byte("{}"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "{}" {
+		t.Errorf("Expected new content, got %q (%v)", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600, got %v (%v)", info.Mode().Perm(), err)
+		}
+	}
+}
+
+func TestAtomicWriteHelper(t *testing.T) {
+	path := os.Getenv(atomicWriteHelperEnv)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+
+	writeFileAtomic(path, 0644, func(w io.Writer) error {
+		if _, err := w.Write([]byte(`{"telemetry.machineId": "trunc`)); err != nil {
+			return err
+		}
+		os.Stdout.WriteString("writing\n")
+		time.Sleep(time.Hour) // Stall until killed
+		return nil
+	})
+}
+
+func TestWriteFileAtomicKilledMidWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	original := `{"telemetry.machineId": "original"}`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestAtomicWriteHelper$")
+	helper.Env = append(os.Environ(), atomicWriteHelperEnv+"="+path)
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdout pipe: %v", err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatalf("Failed to start helper: %v", err)
+	}
+
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "writing\n" {
+		helper.Process.Kill()
+		helper.Wait()
+		t.Fatalf("Helper did not start writing: %q %v", line, err)
+	}
+	if err := helper.Process.Kill(); err != nil {
+		t.Fatalf("Failed to kill helper: %v", err)
+	}
+	helper.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != original {
+		t.Errorf("Expected original content after the write was killed, got %q (%v)", data, err)
+	}
+	// The partial content only ever reached the temporary file
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) != 1 {
+		t.Errorf("Expected the killed write to leave one temporary file, found %v", matches)
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp-*"))
+	if len(matches) > 0 {
+		t.Errorf("Expected temporary files to be removed, found %v", matches)
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	clockMu sync.RWMutex
+	clock   = time.Now
+)
+
+// SetClock replaces the clock StartTimer measures durations with, e.g. with a
+// fake clock in tests; nil restores time.Now
+func SetClock(now func() time.Time) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	clock = now
+}
+
+// now reads the clock set by SetClock
+func now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock()
+}
+
+// StartTimer starts timing a phase and returns a function that reports the time
+// elapsed since. Readings of time.Now carry the monotonic clock, so the durations
+// are not affected by changes to the system time.
+func StartTimer() func() time.Duration {
+	start := now()
+	return func() time.Duration {
+		return now().Sub(start)
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartTimerUsesClock(t *testing.T) {
+	current := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return current })
+	defer SetClock(nil)
+
+	elapsed := StartTimer()
+	current = current.Add(1500 * time.Millisecond)
+	if got := elapsed(); got != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s, got %s", got)
+	}
+	current = current.Add(time.Second)
+	if got := elapsed(); got != 2500*time.Millisecond {
+		t.Errorf("Expected 2.5s, got %s", got)
+	}
+
+	SetClock(nil)
+	if got := StartTimer()(); got < 0 || got > time.Second {
+		t.Errorf("Expected the real clock after SetClock(nil), got %s", got)
+	}
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CloudSyncFolder is a folder a cloud storage client keeps in sync
+type CloudSyncFolder struct {
+	Provider string // "OneDrive", "Dropbox", "Google Drive" or "iCloud Drive"
+	Root     string
+}
+
+// cloudStoragePrefixes maps the folder name prefixes of macOS File Provider
+// mounts in ~/Library/CloudStorage to their provider
+var cloudStoragePrefixes = map[string]string{
+	"OneDrive":    "OneDrive",
+	"Dropbox":     "Dropbox",
+	"GoogleDrive": "Google Drive",
+}
+
+// FindCloudSyncFolder returns the sync folder of a well-known cloud storage
+// provider that path is inside, following symlinks such as a VS Code User
+// directory linked into Dropbox. path does not need to exist yet.
+func FindCloudSyncFolder(path string) (CloudSyncFolder, bool) {
+	return findCloudSyncFolder(DefaultPathResolver(), path)
+}
+
+// findCloudSyncFolder returns the sync folder of resolver's environment that
+// path is inside
+func findCloudSyncFolder(resolver PathResolver, path string) (CloudSyncFolder, bool) {
+	if path == "" {
+		return CloudSyncFolder{}, false
+	}
+	resolved := resolveExistingPath(path)
+	// Windows and macOS file systems are case-insensitive by default
+	foldCase := resolver.GOOS() == "windows" || resolver.GOOS() == "darwin"
+
+	for _, folder := range cloudSyncFolders(resolver) {
+		if isWithin(resolved, resolveExistingPath(folder.Root), foldCase) {
+			return folder, true
+		}
+	}
+	return CloudSyncFolder{}, false
+}
+
+// cloudSyncFolders returns the existing sync folders of OneDrive, Dropbox, Google
+// Drive and iCloud Drive at their default locations or where their clients
+// report them
+func cloudSyncFolders(resolver PathResolver) []CloudSyncFolder {
+	homeDir, err := resolver.HomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var candidates []CloudSyncFolder
+	add := func(provider string, roots ...string) {
+		for _, root := range roots {
+			if root != "" {
+				candidates = append(candidates, CloudSyncFolder{Provider: provider, Root: root})
+			}
+		}
+	}
+
+	add("OneDrive", filepath.Join(homeDir, "OneDrive"))
+	add("Dropbox", filepath.Join(homeDir, "Dropbox"))
+	add("Google Drive", filepath.Join(homeDir, "Google Drive"))
+
+	switch resolver.GOOS() {
+	case "windows":
+		add("OneDrive", resolver.Getenv("OneDrive"), resolver.Getenv("OneDriveConsumer"), resolver.Getenv("OneDriveCommercial"))
+		// OneDrive for Business folders are named "OneDrive - <organization>"
+		if matches, err := filepath.Glob(filepath.Join(homeDir, "OneDrive - *")); err == nil {
+			add("OneDrive", matches...)
+		}
+		add("iCloud Drive", filepath.Join(homeDir, "iCloudDrive"))
+		for _, key := range []string{"APPDATA", "LOCALAPPDATA"} {
+			if dir := resolver.Getenv(key); dir != "" {
+				add("Dropbox", dropboxRoots(filepath.Join(dir, "Dropbox", "info.json"))...)
+			}
+		}
+	case "darwin":
+		if entries, err := os.ReadDir(filepath.Join(homeDir, "Library", "CloudStorage")); err == nil {
+			for _, entry := range entries {
+				for prefix, provider := range cloudStoragePrefixes {
+					if strings.HasPrefix(entry.Name(), prefix) {
+						add(provider, filepath.Join(homeDir, "Library", "CloudStorage", entry.Name()))
+					}
+				}
+			}
+		}
+		add("iCloud Drive", filepath.Join(homeDir, "Library", "Mobile Documents", "com~apple~CloudDocs"))
+		add("Dropbox", dropboxRoots(filepath.Join(homeDir, ".dropbox", "info.json"))...)
+	default: // Linux and other Unix-like systems
+		add("Dropbox", dropboxRoots(filepath.Join(homeDir, ".dropbox", "info.json"))...)
+	}
+
+	var folders []CloudSyncFolder
+	for _, folder := range candidates {
+		if dirExists(folder.Root) {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+// dropboxRoots returns the personal and business folder paths the Dropbox client
+// records in its info.json
+func dropboxRoots(infoPath string) []string {
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return nil
+	}
+
+	var accounts map[string]struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil
+	}
+
+	var roots []string
+	for _, account := range accounts {
+		if account.Path != "" {
+			roots = append(roots, account.Path)
+		}
+	}
+	return roots
+}
+
+// resolveExistingPath resolves the symlinks of the longest existing prefix of
+// path, so that a path below a linked directory resolves to its target
+func resolveExistingPath(path string) string {
+	path = filepath.Clean(path)
+	var missing []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...)
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// isWithin reports whether path is root or below it
+func isWithin(path, root string, foldCase bool) bool {
+	if foldCase {
+		path, root = strings.ToLower(path), strings.ToLower(root)
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
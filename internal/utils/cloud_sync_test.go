@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindCloudSyncFolder(t *testing.T) {
+	home := t.TempDir()
+	resolver := FakePathResolver{Home: home, OS: "linux"}
+
+	dataDir := filepath.Join(home, ".config", "Code")
+	mkdirAll(t, dataDir)
+	if folder, ok := findCloudSyncFolder(resolver, dataDir); ok {
+		t.Fatalf("Expected no sync folder without any provider, got %+v", folder)
+	}
+
+	// A User directory symlinked into Dropbox resolves to the sync folder
+	dropboxUser := filepath.Join(home, "Dropbox", "settings", "User")
+	mkdirAll(t, dropboxUser)
+	if err := os.Symlink(dropboxUser, filepath.Join(dataDir, "User")); err != nil {
+		t.Fatalf("Failed to link User directory: %v", err)
+	}
+	folder, ok := findCloudSyncFolder(resolver, filepath.Join(dataDir, "User", "globalStorage"))
+	if !ok || folder.Provider != "Dropbox" || folder.Root != filepath.Join(home, "Dropbox") {
+		t.Errorf("Expected the linked User directory inside Dropbox, got %+v, %v", folder, ok)
+	}
+	if _, ok := findCloudSyncFolder(resolver, filepath.Join(home, "Dropbox-old")); ok {
+		t.Error("Expected a sibling with the same prefix not to match")
+	}
+
+	// Dropbox folders elsewhere are read from the client's info.json
+	custom := filepath.Join(home, "sync", "Work Dropbox")
+	mkdirAll(t, custom)
+	mkdirAll(t, filepath.Join(home, ".dropbox"))
+	info := `{"business": {"path": "` + filepath.ToSlash(custom) + `", "is_team": true}}`
+	if err := os.WriteFile(filepath.Join(home, ".dropbox", "info.json"), []byte(info), 0644); err != nil {
+		t.Fatalf("Failed to write info.json: %v", err)
+	}
+	backupDir := filepath.Join(custom, "backups", "not-created-yet")
+	if folder, ok := findCloudSyncFolder(resolver, backupDir); !ok || folder.Provider != "Dropbox" {
+		t.Errorf("Expected a missing backup directory inside the info.json folder to match, got %+v, %v", folder, ok)
+	}
+}
+
+func TestCloudSyncFoldersPerPlatform(t *testing.T) {
+	home := t.TempDir()
+	oneDrive := filepath.Join(home, "OneDrive - Contoso")
+	iCloud := filepath.Join(home, "Library", "Mobile Documents", "com~apple~CloudDocs")
+	googleDrive := filepath.Join(home, "Library", "CloudStorage", "GoogleDrive-user@example.com")
+	for _, dir := range []string{oneDrive, iCloud, googleDrive} {
+		mkdirAll(t, dir)
+	}
+
+	tests := []struct {
+		name     string
+		resolver FakePathResolver
+		path     string
+		provider string
+	}{
+		{"windows OneDrive env", FakePathResolver{Home: home, OS: "windows", Env: map[string]string{"OneDriveCommercial": oneDrive}}, filepath.Join(oneDrive, "Documents", "Augment-Telemetry-Backups"), "OneDrive"},
+		{"windows OneDrive for Business", FakePathResolver{Home: home, OS: "windows"}, filepath.Join(oneDrive, "Documents"), "OneDrive"},
+		{"windows folder names ignore case", FakePathResolver{Home: home, OS: "windows"}, filepath.Join(home, "onedrive - contoso", "Documents"), "OneDrive"},
+		{"macOS iCloud Drive", FakePathResolver{Home: home, OS: "darwin"}, filepath.Join(iCloud, "Code"), "iCloud Drive"},
+		{"macOS File Provider", FakePathResolver{Home: home, OS: "darwin"}, filepath.Join(googleDrive, "My Drive", "backups"), "Google Drive"},
+		{"linux has no iCloud Drive", FakePathResolver{Home: home, OS: "linux"}, filepath.Join(iCloud, "Code"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder, ok := findCloudSyncFolder(tt.resolver, tt.path)
+			if tt.provider == "" {
+				if ok {
+					t.Errorf("Expected no sync folder, got %+v", folder)
+				}
+				return
+			}
+			if !ok || folder.Provider != tt.provider {
+				t.Errorf("Expected %s, got %+v, %v", tt.provider, folder, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InstalledExtension is an extension installed in the VS Code extensions directory,
+// read from its package.json
+type InstalledExtension struct {
+	ID                       string   `json:"id"` // publisher.name
+	Version                  string   `json:"version"`
+	DisplayName              string   `json:"display_name,omitempty"`
+	Publisher                string   `json:"publisher"`
+	ExtensionDependencies    []string `json:"extension_dependencies,omitempty"`
+	ContributesConfiguration bool     `json:"contributes_configuration"`
+	Path                     string   `json:"path"`
+
+	// Manifest is the complete parsed package.json
+	Manifest map[string]interface{} `json:"-"`
+}
+
+// extensionPackageJSON mirrors the fields of an extension's package.json we need
+type extensionPackageJSON struct {
+	Name                  string   `json:"name"`
+	Version               string   `json:"version"`
+	DisplayName           string   `json:"displayName"`
+	Publisher             string   `json:"publisher"`
+	ExtensionDependencies []string `json:"extensionDependencies"`
+	Contributes           struct {
+		Configuration json.RawMessage `json:"configuration"`
+	} `json:"contributes"`
+}
+
+// ListInstalledExtensions returns the extensions in the directory returned by
+// GetVSCodeExtensionsDir. A missing directory has no extensions; subdirectories
+// without a readable package.json are skipped.
+func ListInstalledExtensions() ([]InstalledExtension, error) {
+	return ListInstalledExtensionsWithResolver(DefaultPathResolver())
+}
+
+// ListInstalledExtensionsWithResolver returns the extensions in the extensions
+// directory of the environment described by resolver
+func ListInstalledExtensionsWithResolver(resolver PathResolver) ([]InstalledExtension, error) {
+	extensionsDir, err := NewVSCodePaths(resolver).ExtensionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extensions directory: %w", err)
+	}
+	return listInstalledExtensions(extensionsDir)
+}
+
+// listInstalledExtensions returns the extensions in extensionsDir
+func listInstalledExtensions(extensionsDir string) ([]InstalledExtension, error) {
+	entries, err := os.ReadDir(extensionsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extensions directory: %w", err)
+	}
+
+	var extensions []InstalledExtension
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		extension, err := readInstalledExtension(filepath.Join(extensionsDir, entry.Name()))
+		if err != nil {
+			continue // Partially installed or not an extension
+		}
+		extensions = append(extensions, *extension)
+	}
+	return extensions, nil
+}
+
+// readInstalledExtension reads the package.json of the extension installed in dir
+func readInstalledExtension(dir string) (*InstalledExtension, error) {
+	manifestPath := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg extensionPackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+	}
+	if pkg.Name == "" || pkg.Publisher == "" {
+		return nil, fmt.Errorf("no name or publisher in %s", manifestPath)
+	}
+
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+	}
+
+	configuration := string(pkg.Contributes.Configuration)
+	return &InstalledExtension{
+		ID:                       pkg.Publisher + "." + pkg.Name,
+		Version:                  pkg.Version,
+		DisplayName:              pkg.DisplayName,
+		Publisher:                pkg.Publisher,
+		ExtensionDependencies:    pkg.ExtensionDependencies,
+		ContributesConfiguration: configuration != "" && configuration != "null",
+		Path:                     dir,
+		Manifest:                 manifest,
+	}, nil
+}
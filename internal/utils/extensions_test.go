@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListInstalledExtensions(t *testing.T) {
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"augment.vscode-augment-0.400.0": `{"name":"vscode-augment","publisher":"augment","version":"0.400.0","displayName":"Augment","contributes":{"configuration":{"properties":{}}}}`,
+		"ms-python.python-2024.0.0":      `{"name":"python","publisher":"ms-python","version":"2024.0.0","extensionDependencies":["ms-python.vscode-pylance"]}`,
+		"broken-1.0.0":                   `{"name":`,
+		"no-publisher-1.0.0":             `{"name":"orphan","version":"1.0.0"}`,
+	}
+	for name, manifest := range manifests {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatalf("Failed to create extension directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "package.json"), []byte(manifest), 0644); err != nil {
+			t.Fatalf("Failed to write package.json: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extensions.json"), []byte(`[]`), 0644); err != nil {
+		t.Fatalf("Failed to write extensions.json: %v", err)
+	}
+
+	extensions, err := listInstalledExtensions(dir)
+	if err != nil {
+		t.Fatalf("listInstalledExtensions() failed: %v", err)
+	}
+	if len(extensions) != 2 {
+		t.Fatalf("Expected 2 extensions, got %d: %+v", len(extensions), extensions)
+	}
+
+	byID := make(map[string]InstalledExtension)
+	for _, extension := range extensions {
+		byID[extension.ID] = extension
+	}
+	augment, ok := byID["augment.vscode-augment"]
+	if !ok || augment.Version != "0.400.0" || augment.DisplayName != "Augment" || !augment.ContributesConfiguration {
+		t.Errorf("Unexpected augment.vscode-augment entry %+v", augment)
+	}
+	python, ok := byID["ms-python.python"]
+	if !ok || python.ContributesConfiguration || len(python.ExtensionDependencies) != 1 || python.ExtensionDependencies[0] != "ms-python.vscode-pylance" {
+		t.Errorf("Unexpected ms-python.python entry %+v", python)
+	}
+	if python.Path != filepath.Join(dir, "ms-python.python-2024.0.0") {
+		t.Errorf("Expected the install path, got %s", python.Path)
+	}
+
+	if extensions, err := listInstalledExtensions(filepath.Join(dir, "missing")); err != nil || len(extensions) != 0 {
+		t.Errorf("Expected no extensions and no error for a missing directory, got %v, %v", extensions, err)
+	}
+}
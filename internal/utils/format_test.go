@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                             "0 B",
+		1023:                          "1023 B",
+		1024:                          "1.0 KB",
+		1536:                          "1.5 KB",
+		5 * 1024 * 1024:               "5.0 MB",
+		1503238553:                    "1.4 GB",
+		3 * 1024 * 1024 * 1024 * 1024: "3.0 TB",
+	}
+	for bytes, want := range tests {
+		if got := FormatBytes(bytes); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
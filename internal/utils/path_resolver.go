@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+)
+
+// PathResolver supplies the environment that file locations are derived from: the
+// home directory, the operating system and environment variables. Injecting it lets
+// the path derivation of every platform be tested on any machine.
+type PathResolver interface {
+	HomeDir() (string, error)
+	GOOS() string
+	Getenv(key string) string
+}
+
+// systemPathResolver resolves paths for the current user and platform
+type systemPathResolver struct{}
+
+// DefaultPathResolver returns the resolver for the current user and platform. It
+// honors SetHomeDirOverride, under which environment variables read as empty so
+// every path falls back to its location under the override.
+func DefaultPathResolver() PathResolver {
+	return systemPathResolver{}
+}
+
+// HomeDir returns the user's home directory
+func (systemPathResolver) HomeDir() (string, error) {
+	return GetHomeDir()
+}
+
+// GOOS returns the current operating system
+func (systemPathResolver) GOOS() string {
+	return runtime.GOOS
+}
+
+// Getenv returns an environment variable, or "" while the home directory is overridden
+func (systemPathResolver) Getenv(key string) string {
+	if getHomeDirOverride() != "" {
+		return ""
+	}
+	return os.Getenv(key)
+}
+
+// editorPathResolver is a PathResolver limited to one editor build
+type editorPathResolver struct {
+	PathResolver
+	editor Editor
+}
+
+// NewEditorPathResolver returns resolver limited to editor: the paths derived
+// from it are those of editor, and scans over editor builds cover only editor.
+// A nil resolver uses DefaultPathResolver.
+func NewEditorPathResolver(resolver PathResolver, editor Editor) PathResolver {
+	if resolver == nil {
+		resolver = DefaultPathResolver()
+	}
+	return editorPathResolver{PathResolver: resolver, editor: editor}
+}
+
+// ResolverEditor returns the editor resolver was limited to with
+// NewEditorPathResolver, if any
+func ResolverEditor(resolver PathResolver) (Editor, bool) {
+	if r, ok := resolver.(editorPathResolver); ok {
+		return r.editor, true
+	}
+	return Editor{}, false
+}
+
+// homePathResolver resolves paths for the current platform under a fixed home directory
+type homePathResolver struct {
+	home string
+}
+
+// NewHomePathResolver returns a resolver for the current platform whose paths all
+// lie under home, e.g. a sandbox. Environment variables read as empty, so every
+// path falls back to its location under home.
+func NewHomePathResolver(home string) PathResolver {
+	return homePathResolver{home: home}
+}
+
+// HomeDir returns the fixed home directory
+func (r homePathResolver) HomeDir() (string, error) {
+	return r.home, nil
+}
+
+// GOOS returns the current operating system
+func (homePathResolver) GOOS() string {
+	return runtime.GOOS
+}
+
+// Getenv returns "" for every key
+func (homePathResolver) Getenv(key string) string {
+	return ""
+}
+
+// FakePathResolver is a PathResolver with fixed values, for tests that derive the
+// paths of another platform
+type FakePathResolver struct {
+	Home string
+	OS   string
+	Env  map[string]string
+}
+
+// HomeDir returns Home
+func (f FakePathResolver) HomeDir() (string, error) {
+	return f.Home, nil
+}
+
+// GOOS returns OS
+func (f FakePathResolver) GOOS() string {
+	return f.OS
+}
+
+// Getenv returns the value of key in Env
+func (f FakePathResolver) Getenv(key string) string {
+	return f.Env[key]
+}
@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVSCodePathsPerOS(t *testing.T) {
+	home := filepath.FromSlash("/home/user")
+	roaming := filepath.Join(home, "AppData", "Roaming")
+	appSupport := filepath.Join(home, "Library", "Application Support")
+
+	tests := []struct {
+		name     string
+		resolver FakePathResolver
+		path     func(*VSCodePaths) (string, error)
+		want     string
+	}{
+		{"windows app data", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).AppDataDir, roaming},
+		{"windows APPDATA", FakePathResolver{Home: home, OS: "windows", Env: map[string]string{"APPDATA": filepath.FromSlash("/roaming")}}, (*VSCodePaths).StoragePath, filepath.Join(filepath.FromSlash("/roaming"), "Code", "User", "globalStorage", "storage.json")},
+		{"windows storage", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).StoragePath, filepath.Join(roaming, "Code", "User", "globalStorage", "storage.json")},
+		{"windows database", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).DBPath, filepath.Join(roaming, "Code", "User", "globalStorage", "state.vscdb")},
+		{"windows machine ID", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).MachineIDPath, filepath.Join(roaming, "Code", "User", "machineid")},
+		{"windows workspace storage", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).WorkspaceStoragePath, filepath.Join(roaming, "Code", "User", "workspaceStorage")},
+		{"windows settings", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).UserSettingsPath, filepath.Join(roaming, "Code", "User", "settings.json")},
+		{"windows cache", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).CacheDir, filepath.Join(home, "AppData", "Local")},
+		{"windows LOCALAPPDATA", FakePathResolver{Home: home, OS: "windows", Env: map[string]string{"LOCALAPPDATA": filepath.FromSlash("/local")}}, (*VSCodePaths).CacheDir, filepath.FromSlash("/local")},
+		{"windows extensions", FakePathResolver{Home: home, OS: "windows"}, (*VSCodePaths).ExtensionsPath, filepath.Join(home, ".vscode", "extensions")},
+
+		{"macOS app data", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).AppDataDir, appSupport},
+		{"macOS storage", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).StoragePath, filepath.Join(appSupport, "Code", "User", "globalStorage", "storage.json")},
+		{"macOS database", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).DBPath, filepath.Join(appSupport, "Code", "User", "globalStorage", "state.vscdb")},
+		{"macOS machine ID", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).MachineIDPath, filepath.Join(appSupport, "Code", "machineid")},
+		{"macOS workspace storage", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).WorkspaceStoragePath, filepath.Join(appSupport, "Code", "User", "workspaceStorage")},
+		{"macOS settings", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).UserSettingsPath, filepath.Join(appSupport, "Code", "User", "settings.json")},
+		{"macOS cache", FakePathResolver{Home: home, OS: "darwin"}, (*VSCodePaths).CacheDir, filepath.Join(home, "Library", "Caches")},
+		{"macOS ignores APPDATA", FakePathResolver{Home: home, OS: "darwin", Env: map[string]string{"APPDATA": "/roaming"}}, (*VSCodePaths).GlobalStoragePath, filepath.Join(appSupport, "Code", "User", "globalStorage")},
+
+		{"linux app data", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).AppDataDir, filepath.Join(home, ".local", "share")},
+		{"linux storage", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).StoragePath, filepath.Join(home, ".config", "Code", "User", "globalStorage", "storage.json")},
+		{"linux database", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).DBPath, filepath.Join(home, ".config", "Code", "User", "globalStorage", "state.vscdb")},
+		{"linux machine ID", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).MachineIDPath, filepath.Join(home, ".config", "Code", "User", "machineid")},
+		{"linux workspace storage", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).WorkspaceStoragePath, filepath.Join(home, ".config", "Code", "User", "workspaceStorage")},
+		{"linux settings", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).UserSettingsPath, filepath.Join(home, ".config", "Code", "User", "settings.json")},
+		{"linux cache", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).CacheDir, filepath.Join(home, ".cache")},
+		{"linux XDG_CACHE_HOME", FakePathResolver{Home: home, OS: "linux", Env: map[string]string{"XDG_CACHE_HOME": filepath.FromSlash("/xdg")}}, (*VSCodePaths).CacheDir, filepath.FromSlash("/xdg")},
+		{"linux insiders extensions", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).InsidersExtensionsPath, filepath.Join(home, ".vscode-insiders", "extensions")},
+		{"linux extensions dir", FakePathResolver{Home: home, OS: "linux"}, (*VSCodePaths).ExtensionsDir, filepath.Join(home, ".vscode", "extensions")},
+		{"linux VSCODE_EXTENSIONS", FakePathResolver{Home: home, OS: "linux", Env: map[string]string{"VSCODE_EXTENSIONS": filepath.FromSlash("/ext"), "VSCODE_PORTABLE": filepath.FromSlash("/portable")}}, (*VSCodePaths).ExtensionsDir, filepath.FromSlash("/ext")},
+		{"windows portable extensions", FakePathResolver{Home: home, OS: "windows", Env: map[string]string{"VSCODE_PORTABLE": filepath.FromSlash("/portable")}}, (*VSCodePaths).ExtensionsDir, filepath.Join(filepath.FromSlash("/portable"), "extensions")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.path(NewVSCodePaths(tt.resolver))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVSCodePathsExtensionStorage(t *testing.T) {
+	paths := NewVSCodePaths(FakePathResolver{Home: filepath.FromSlash("/home/user"), OS: "linux"})
+	userDir := filepath.Join(filepath.FromSlash("/home/user"), ".config", "Code", "User")
+
+	if got, _ := paths.ExtensionGlobalStoragePath("augment.vscode-augment"); got != filepath.Join(userDir, "globalStorage", "augment.vscode-augment") {
+		t.Errorf("Unexpected extension global storage path %s", got)
+	}
+	if got, _ := paths.ExtensionWorkspaceStoragePath("abc123", "augment.vscode-augment"); got != filepath.Join(userDir, "workspaceStorage", "abc123", "augment.vscode-augment") {
+		t.Errorf("Unexpected extension workspace storage path %s", got)
+	}
+}
+
+func TestDefaultPathResolverHomeDirOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("APPDATA", filepath.Join(t.TempDir(), "real"))
+	SetHomeDirOverride(home)
+	defer SetHomeDirOverride("")
+
+	// Environment variables would point outside the overridden home
+	if value := DefaultPathResolver().Getenv("APPDATA"); value != "" {
+		t.Errorf("Expected environment to be ignored under the override, got %q", value)
+	}
+
+	appData, err := GetAppDataDir()
+	if err != nil {
+		t.Fatalf("GetAppDataDir failed: %v", err)
+	}
+	if !strings.HasPrefix(appData, home+string(filepath.Separator)) {
+		t.Errorf("Expected app data below %s, got %s", home, appData)
+	}
+}
+
+func TestEditorPathsPerVariant(t *testing.T) {
+	home := filepath.FromSlash("/home/user")
+
+	tests := []struct {
+		editor    Editor
+		os        string
+		settings  string
+		machineID string
+		extension string
+	}{
+		{EditorVSCode, "linux", filepath.Join(home, ".config", "Code", "User", "settings.json"), filepath.Join(home, ".config", "Code", "User", "machineid"), filepath.Join(home, ".vscode", "extensions")},
+		{EditorVSCodeInsiders, "linux", filepath.Join(home, ".config", "Code - Insiders", "User", "settings.json"), filepath.Join(home, ".config", "Code - Insiders", "User", "machineid"), filepath.Join(home, ".vscode-insiders", "extensions")},
+		{EditorVSCodium, "linux", filepath.Join(home, ".config", "VSCodium", "User", "settings.json"), filepath.Join(home, ".config", "VSCodium", "User", "machineid"), filepath.Join(home, ".vscode-oss", "extensions")},
+		{EditorVSCodeInsiders, "darwin", filepath.Join(home, "Library", "Application Support", "Code - Insiders", "User", "settings.json"), filepath.Join(home, "Library", "Application Support", "Code - Insiders", "machineid"), filepath.Join(home, ".vscode-insiders", "extensions")},
+		{EditorVSCodium, "darwin", filepath.Join(home, "Library", "Application Support", "VSCodium", "User", "settings.json"), filepath.Join(home, "Library", "Application Support", "VSCodium", "machineid"), filepath.Join(home, ".vscode-oss", "extensions")},
+		{EditorVSCodeInsiders, "windows", filepath.Join(home, "AppData", "Roaming", "Code - Insiders", "User", "settings.json"), filepath.Join(home, "AppData", "Roaming", "Code - Insiders", "User", "machineid"), filepath.Join(home, ".vscode-insiders", "extensions")},
+		{EditorVSCodium, "windows", filepath.Join(home, "AppData", "Roaming", "VSCodium", "User", "settings.json"), filepath.Join(home, "AppData", "Roaming", "VSCodium", "User", "machineid"), filepath.Join(home, ".vscode-oss", "extensions")},
+		{EditorCursor, "linux", filepath.Join(home, ".config", "Cursor", "User", "settings.json"), filepath.Join(home, ".config", "Cursor", "User", "machineid"), filepath.Join(home, ".cursor", "extensions")},
+		{EditorCursor, "darwin", filepath.Join(home, "Library", "Application Support", "Cursor", "User", "settings.json"), filepath.Join(home, "Library", "Application Support", "Cursor", "machineid"), filepath.Join(home, ".cursor", "extensions")},
+		{EditorCursor, "windows", filepath.Join(home, "AppData", "Roaming", "Cursor", "User", "settings.json"), filepath.Join(home, "AppData", "Roaming", "Cursor", "User", "machineid"), filepath.Join(home, ".cursor", "extensions")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.editor.Name+" "+tt.os, func(t *testing.T) {
+			paths := NewEditorPaths(FakePathResolver{Home: home, OS: tt.os}, tt.editor)
+
+			for name, check := range map[string]struct {
+				get  func() (string, error)
+				want string
+			}{
+				"settings":   {paths.UserSettingsPath, tt.settings},
+				"machine ID": {paths.MachineIDPath, tt.machineID},
+				"extensions": {paths.ExtensionsPath, tt.extension},
+			} {
+				if got, err := check.get(); err != nil || got != check.want {
+					t.Errorf("%s: expected %s, got %s (%v)", name, check.want, got, err)
+				}
+			}
+		})
+	}
+
+	// The Insiders shortcut agrees with the Insiders editor paths
+	insiders, _ := NewVSCodePaths(FakePathResolver{Home: home, OS: "linux"}).InsidersExtensionsPath()
+	if want := filepath.Join(home, ".vscode-insiders", "extensions"); insiders != want {
+		t.Errorf("Expected Insiders extensions at %s, got %s", want, insiders)
+	}
+}
+
+func TestSnapPaths(t *testing.T) {
+	home := t.TempDir()
+	resolver := FakePathResolver{Home: home, OS: "linux"}
+	paths := NewVSCodePaths(resolver)
+
+	regularStorage := filepath.Join(home, ".config", "Code", "User", "globalStorage")
+	snapStorage := filepath.Join(home, "snap", "code", "current", ".config", "Code", "User", "globalStorage")
+
+	if paths.IsSnapInstalled() {
+		t.Fatal("Expected no Snap install in an empty home")
+	}
+	if got, _ := paths.GlobalStoragePaths(); len(got) != 1 || got[0] != regularStorage {
+		t.Errorf("Expected only %s, got %v", regularStorage, got)
+	}
+
+	// Snap only: the regular helpers fall back to the Snap data directory
+	mkdirAll(t, snapStorage)
+	if !paths.IsSnapInstalled() {
+		t.Fatal("Expected ~/snap/code to be detected")
+	}
+	if got, _ := paths.GlobalStoragePath(); got != snapStorage {
+		t.Errorf("Expected the Snap global storage %s, got %s", snapStorage, got)
+	}
+	if got, _ := paths.GlobalStoragePaths(); len(got) != 1 || got[0] != snapStorage {
+		t.Errorf("Expected only %s, got %v", snapStorage, got)
+	}
+
+	// Both installs: the Snap location is returned in addition to the regular one
+	mkdirAll(t, regularStorage)
+	if got, _ := paths.GlobalStoragePaths(); len(got) != 2 || got[0] != regularStorage || got[1] != snapStorage {
+		t.Errorf("Expected %s and %s, got %v", regularStorage, snapStorage, got)
+	}
+	// Locations missing in the Snap install are not returned
+	if got, _ := paths.DBPaths(); len(got) != 1 {
+		t.Errorf("Expected only the regular database path, got %v", got)
+	}
+
+	want := filepath.Join(home, "snap", "code", "current", ".config", "Code")
+	if got := mustString(t, paths.SnapDataDir); got != want {
+		t.Errorf("Expected Snap data directory %s, got %s", want, got)
+	}
+
+	// Snap packages only exist on Linux
+	if NewVSCodePaths(FakePathResolver{Home: home, OS: "darwin"}).IsSnapInstalled() {
+		t.Error("Expected no Snap install on macOS")
+	}
+}
+
+func TestSnapInsidersAndVariantPaths(t *testing.T) {
+	home := t.TempDir()
+	resolver := FakePathResolver{Home: home, OS: "linux"}
+
+	mkdirAll(t, filepath.Join(home, ".config", "Code"))
+	mkdirAll(t, filepath.Join(home, "snap", "code", "current", ".config", "Code"))
+	mkdirAll(t, filepath.Join(home, "snap", "code-insiders", "current", ".config", "Code - Insiders", "User"))
+
+	insiders := NewEditorPaths(resolver, EditorVSCodeInsiders)
+	want := filepath.Join(home, "snap", "code-insiders", "current", ".config", "Code - Insiders", "User", "settings.json")
+	if got, _ := insiders.UserSettingsPath(); got != want {
+		t.Errorf("Expected Insiders Snap settings %s, got %s", want, got)
+	}
+
+	variants := GetAllVSCodeVariantPathsWithResolver(resolver)
+	wantVariants := map[string]string{
+		"Code":             filepath.Join(home, ".config", "Code"),
+		"SnapCode":         filepath.Join(home, "snap", "code", "current", ".config", "Code"),
+		"SnapCodeInsiders": filepath.Join(home, "snap", "code-insiders", "current", ".config", "Code - Insiders"),
+	}
+	if len(variants) != len(wantVariants) {
+		t.Errorf("Expected variants %v, got %v", wantVariants, variants)
+	}
+	for variant, dir := range wantVariants {
+		if variants[variant] != dir {
+			t.Errorf("Expected %s at %s, got %q", variant, dir, variants[variant])
+		}
+	}
+}
+
+func TestFlatpakPaths(t *testing.T) {
+	home := t.TempDir()
+	resolver := FakePathResolver{Home: home, OS: "linux"}
+	paths := NewVSCodePaths(resolver)
+
+	flatpakDir := filepath.Join(home, ".var", "app", "com.visualstudio.code", "config", "Code")
+	if paths.IsFlatpakInstalled() {
+		t.Fatal("Expected no Flatpak install in an empty home")
+	}
+	if got := mustString(t, paths.FlatpakDataDir); got != flatpakDir {
+		t.Errorf("Expected Flatpak data directory %s, got %s", flatpakDir, got)
+	}
+
+	// Flatpak only: the regular helpers fall back to the Flatpak data directory
+	flatpakStorage := filepath.Join(flatpakDir, "User", "globalStorage")
+	mkdirAll(t, flatpakStorage)
+	if !paths.IsFlatpakInstalled() {
+		t.Fatal("Expected ~/.var/app/com.visualstudio.code to be detected")
+	}
+	if got, _ := paths.GlobalStoragePath(); got != flatpakStorage {
+		t.Errorf("Expected the Flatpak global storage %s, got %s", flatpakStorage, got)
+	}
+
+	// Both installs: the Flatpak location is returned in addition to the regular one
+	regularStorage := filepath.Join(home, ".config", "Code", "User", "globalStorage")
+	mkdirAll(t, regularStorage)
+	if got, _ := paths.GlobalStoragePaths(); len(got) != 2 || got[0] != regularStorage || got[1] != flatpakStorage {
+		t.Errorf("Expected %s and %s, got %v", regularStorage, flatpakStorage, got)
+	}
+
+	mkdirAll(t, filepath.Join(home, ".var", "app", "com.visualstudio.code-insiders", "config", "Code - Insiders"))
+	variants := GetAllVSCodeVariantPathsWithResolver(resolver)
+	wantVariants := map[string]string{
+		"Code":                filepath.Join(home, ".config", "Code"),
+		"FlatpakCode":         flatpakDir,
+		"FlatpakCodeInsiders": filepath.Join(home, ".var", "app", "com.visualstudio.code-insiders", "config", "Code - Insiders"),
+	}
+	if len(variants) != len(wantVariants) {
+		t.Errorf("Expected variants %v, got %v", wantVariants, variants)
+	}
+	for variant, dir := range wantVariants {
+		if variants[variant] != dir {
+			t.Errorf("Expected %s at %s, got %q", variant, dir, variants[variant])
+		}
+	}
+
+	// Flatpak apps only exist on Linux
+	if NewVSCodePaths(FakePathResolver{Home: home, OS: "darwin"}).IsFlatpakInstalled() {
+		t.Error("Expected no Flatpak install on macOS")
+	}
+}
+
+func mkdirAll(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+}
+
+func mustString(t *testing.T, get func() (string, error)) string {
+	t.Helper()
+	value, err := get()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return value
+}
+
+func TestNewEditorPathResolver(t *testing.T) {
+	home := t.TempDir()
+	resolver := FakePathResolver{Home: home, OS: "linux"}
+	mkdirAll(t, filepath.Join(home, ".config", "Code"))
+	mkdirAll(t, filepath.Join(home, ".config", "Cursor"))
+
+	if _, err := ParseEditor("sublime"); err == nil {
+		t.Error("Expected an unknown editor to fail")
+	}
+	cursor, err := ParseEditor(" Cursor ")
+	if err != nil || cursor != EditorCursor {
+		t.Fatalf("Expected Cursor, got %+v (%v)", cursor, err)
+	}
+
+	if variants := GetAllVSCodeVariantPathsWithResolver(resolver); len(variants) != 2 {
+		t.Errorf("Expected VS Code and Cursor without an editor, got %v", variants)
+	}
+	if _, ok := ResolverEditor(resolver); ok {
+		t.Error("Expected no editor on a plain resolver")
+	}
+
+	cursorResolver := NewEditorPathResolver(resolver, cursor)
+	if editor, ok := ResolverEditor(cursorResolver); !ok || editor != EditorCursor {
+		t.Errorf("Expected Cursor on the editor resolver, got %+v", editor)
+	}
+	if got := mustString(t, cursorResolver.HomeDir); got != home {
+		t.Errorf("Expected the home directory %s of the wrapped resolver, got %s", home, got)
+	}
+
+	want := filepath.Join(home, ".config", "Cursor", "User", "globalStorage", "state.vscdb")
+	if got := mustString(t, NewVSCodePaths(cursorResolver).DBPath); got != want {
+		t.Errorf("Expected the Cursor database %s, got %s", want, got)
+	}
+	if editors := TargetEditors(cursorResolver); len(editors) != 1 || editors[0] != EditorCursor {
+		t.Errorf("Expected only Cursor to be targeted, got %+v", editors)
+	}
+	variants := GetAllVSCodeVariantPathsWithResolver(cursorResolver)
+	if len(variants) != 1 || variants["Cursor"] != filepath.Join(home, ".config", "Cursor") {
+		t.Errorf("Expected only the Cursor variant, got %v", variants)
+	}
+
+	// Resolvers without the editor are unaffected
+	if got := NewVSCodePaths(resolver).Editor(); got != EditorVSCode {
+		t.Errorf("Expected stable VS Code for the plain resolver, got %s", got.Name)
+	}
+}
@@ -1,13 +1,39 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
+	"strings"
+	"sync"
 )
 
+var (
+	homeDirOverrideMu sync.RWMutex
+	homeDirOverride   string
+)
+
+// SetHomeDirOverride makes every path returned by this package resolve under dir
+// instead of the user's home directory, ignoring %APPDATA%. An empty dir restores
+// the real locations. The self-test uses it to run the cleaners against a sandbox.
+func SetHomeDirOverride(dir string) {
+	homeDirOverrideMu.Lock()
+	defer homeDirOverrideMu.Unlock()
+	homeDirOverride = dir
+}
+
+// getHomeDirOverride returns the directory set by SetHomeDirOverride, if any
+func getHomeDirOverride() string {
+	homeDirOverrideMu.RLock()
+	defer homeDirOverrideMu.RUnlock()
+	return homeDirOverride
+}
+
 // GetHomeDir returns the user's home directory across different platforms
 func GetHomeDir() (string, error) {
+	if dir := getHomeDirOverride(); dir != "" {
+		return dir, nil
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -15,23 +41,128 @@ func GetHomeDir() (string, error) {
 	return homeDir, nil
 }
 
-// GetAppDataDir returns the application data directory across different platforms
+// Editor is a VS Code build. Builds share the directory layout and differ only in
+// the names of their user data and extensions directories.
+type Editor struct {
+	ID                string // Variant key used by GetAllVSCodeVariantPaths
+	ShortName         string // Name accepted by ParseEditor, e.g. "vscode"
+	Name              string // Display name
+	DataDirName       string // Directory below the app data/config directory, e.g. "Code"
+	ExtensionsDirName string // Directory below the home directory, e.g. ".vscode"
+	SnapName          string // Name of the Linux Snap package, e.g. "code"
+	FlatpakID         string // ID of the Linux Flatpak app, e.g. "com.visualstudio.code"
+}
+
+// Supported editor builds
+var (
+	EditorVSCode         = Editor{ID: "Code", ShortName: "vscode", Name: "VS Code", DataDirName: "Code", ExtensionsDirName: ".vscode", SnapName: "code", FlatpakID: "com.visualstudio.code"}
+	EditorVSCodeInsiders = Editor{ID: "CodeInsiders", ShortName: "vscode-insiders", Name: "VS Code Insiders", DataDirName: "Code - Insiders", ExtensionsDirName: ".vscode-insiders", SnapName: "code-insiders", FlatpakID: "com.visualstudio.code-insiders"}
+	EditorVSCodium       = Editor{ID: "VSCodium", ShortName: "vscodium", Name: "VSCodium", DataDirName: "VSCodium", ExtensionsDirName: ".vscode-oss", SnapName: "codium", FlatpakID: "com.vscodium.codium"}
+	// EditorCursor is the Cursor editor, a VS Code fork with its own telemetry. It
+	// ships as an AppImage on Linux, so it has no Snap package or Flatpak app.
+	EditorCursor = Editor{ID: "Cursor", ShortName: "cursor", Name: "Cursor", DataDirName: "Cursor", ExtensionsDirName: ".cursor"}
+)
+
+// Editors returns every supported editor build, stable VS Code first
+func Editors() []Editor {
+	return []Editor{EditorVSCode, EditorVSCodeInsiders, EditorVSCodium, EditorCursor}
+}
+
+// ParseEditor parses the short name of an editor build: vscode, vscode-insiders,
+// vscodium or cursor
+func ParseEditor(name string) (Editor, error) {
+	short := strings.ToLower(strings.TrimSpace(name))
+	for _, editor := range Editors() {
+		if editor.ShortName == short {
+			return editor, nil
+		}
+	}
+	return Editor{}, fmt.Errorf("unknown editor: %s", name)
+}
+
+// TargetEditors returns the editor chosen with NewEditorPathResolver or, without
+// one, every supported editor build
+func TargetEditors(resolver PathResolver) []Editor {
+	if editor, ok := ResolverEditor(resolver); ok {
+		return []Editor{editor}
+	}
+	return Editors()
+}
+
+// GetCursorPaths returns the paths of the Cursor editor for the current user and
+// platform
+// Windows: %APPDATA%/Cursor
+// macOS: ~/Library/Application Support/Cursor
+// Linux: ~/.config/Cursor
+func GetCursorPaths() *VSCodePaths {
+	return NewEditorPaths(DefaultPathResolver(), EditorCursor)
+}
+
+// VSCodePaths derives the locations of an editor's files from a PathResolver
+type VSCodePaths struct {
+	resolver PathResolver
+	editor   Editor
+	snap     bool // Derive the paths of the Snap package on Linux
+	flatpak  bool // Derive the paths of the Flatpak app on Linux
+}
+
+// NewVSCodePaths creates the paths of the environment described by resolver for
+// the editor chosen with NewEditorPathResolver, stable VS Code by default; nil
+// uses DefaultPathResolver
+func NewVSCodePaths(resolver PathResolver) *VSCodePaths {
+	if editor, ok := ResolverEditor(resolver); ok {
+		return NewEditorPaths(resolver, editor)
+	}
+	return NewEditorPaths(resolver, EditorVSCode)
+}
+
+// NewEditorPaths creates the paths of editor in the environment described by
+// resolver; nil uses DefaultPathResolver
+func NewEditorPaths(resolver PathResolver, editor Editor) *VSCodePaths {
+	if resolver == nil {
+		resolver = DefaultPathResolver()
+	}
+	return &VSCodePaths{resolver: resolver, editor: editor}
+}
+
+// Editor returns the editor build the paths are derived for
+func (p *VSCodePaths) Editor() Editor {
+	return p.editor
+}
+
+// defaultPaths returns the VS Code paths of the current user and platform
+func defaultPaths() *VSCodePaths {
+	return NewVSCodePaths(DefaultPathResolver())
+}
+
+// OS returns the operating system the paths are derived for
+func (p *VSCodePaths) OS() string {
+	return p.resolver.GOOS()
+}
+
+// HomeDir returns the user's home directory
+func (p *VSCodePaths) HomeDir() (string, error) {
+	return p.resolver.HomeDir()
+}
+
+// Getenv returns an environment variable of the resolved environment
+func (p *VSCodePaths) Getenv(key string) string {
+	return p.resolver.Getenv(key)
+}
+
+// AppDataDir returns the application data directory
 // Windows: %APPDATA% (typically C:\Users\<username>\AppData\Roaming)
 // macOS: ~/Library/Application Support
 // Linux: ~/.local/share
-func GetAppDataDir() (string, error) {
-	homeDir, err := GetHomeDir()
+func (p *VSCodePaths) AppDataDir() (string, error) {
+	homeDir, err := p.HomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch p.OS() {
 	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData != "" {
-			return appData, nil
-		}
-		return filepath.Join(homeDir, "AppData", "Roaming"), nil
+		return p.envDir("APPDATA", homeDir, "AppData", "Roaming"), nil
 	case "darwin":
 		return filepath.Join(homeDir, "Library", "Application Support"), nil
 	default: // Linux and other Unix-like systems
@@ -39,66 +170,373 @@ func GetAppDataDir() (string, error) {
 	}
 }
 
-// GetStoragePath returns the storage.json path across different platforms
-// Windows: %APPDATA%/Code/User/globalStorage/storage.json
-// macOS: ~/Library/Application Support/Code/User/globalStorage/storage.json
-// Linux: ~/.config/Code/User/globalStorage/storage.json
-func GetStoragePath() (string, error) {
-	switch runtime.GOOS {
+// CacheDir returns the per-user cache directory
+// Windows: %LOCALAPPDATA% (typically C:\Users\<username>\AppData\Local)
+// macOS: ~/Library/Caches
+// Linux: $XDG_CACHE_HOME or ~/.cache
+func (p *VSCodePaths) CacheDir() (string, error) {
+	homeDir, err := p.HomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch p.OS() {
+	case "windows":
+		return p.envDir("LOCALAPPDATA", homeDir, "AppData", "Local"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Caches"), nil
+	default: // Linux and other Unix-like systems
+		return p.envDir("XDG_CACHE_HOME", homeDir, ".cache"), nil
+	}
+}
+
+// DataDir returns the editor's data directory, for stable VS Code
+// Windows: %APPDATA%/Code
+// macOS: ~/Library/Application Support/Code
+// Linux: ~/.config/Code, or the data directory of the Snap package or Flatpak app
+// when only one of those has data
+func (p *VSCodePaths) DataDir() (string, error) {
+	homeDir, err := p.HomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch p.OS() {
 	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			homeDir, err := GetHomeDir()
-			if err != nil {
-				return "", err
+		return filepath.Join(p.envDir("APPDATA", homeDir, "AppData", "Roaming"), p.editor.DataDirName), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", p.editor.DataDirName), nil
+	default: // Linux and other Unix-like systems
+		if p.snap {
+			return p.SnapDataDir()
+		}
+		if p.flatpak {
+			return p.FlatpakDataDir()
+		}
+		dataDir := filepath.Join(homeDir, ".config", p.editor.DataDirName)
+		if !dirExists(dataDir) && p.IsSnapInstalled() {
+			if snapDir, err := p.SnapDataDir(); err == nil && dirExists(snapDir) {
+				return snapDir, nil
 			}
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
 		}
-		return filepath.Join(appData, "Code", "User", "globalStorage", "storage.json"), nil
-	case "darwin":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
+		if !dirExists(dataDir) && p.IsFlatpakInstalled() {
+			if flatpakDir, err := p.FlatpakDataDir(); err == nil && CheckAccess(flatpakDir, false) == nil {
+				return flatpakDir, nil
+			}
 		}
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage", "storage.json"), nil
-	default: // Linux and other Unix-like systems
-		homeDir, err := GetHomeDir()
+		return dataDir, nil
+	}
+}
+
+// Snap returns the paths of the editor's Snap package. Snaps only exist on Linux;
+// elsewhere the paths are the regular ones.
+func (p *VSCodePaths) Snap() *VSCodePaths {
+	return &VSCodePaths{resolver: p.resolver, editor: p.editor, snap: true}
+}
+
+// IsSnapInstalled reports whether the editor is installed as a Snap package,
+// detected by its ~/snap/<package> directory
+func (p *VSCodePaths) IsSnapInstalled() bool {
+	if p.OS() != "linux" || p.editor.SnapName == "" {
+		return false
+	}
+	snapDir, err := p.homePath("snap", p.editor.SnapName)
+	return err == nil && dirExists(snapDir)
+}
+
+// SnapDataDir returns the data directory of the editor's Snap package,
+// ~/snap/code/current/.config/Code for stable VS Code
+func (p *VSCodePaths) SnapDataDir() (string, error) {
+	return p.homePath("snap", p.editor.SnapName, "current", ".config", p.editor.DataDirName)
+}
+
+// Flatpak returns the paths of the editor's Flatpak app. Flatpaks only exist on
+// Linux; elsewhere the paths are the regular ones.
+func (p *VSCodePaths) Flatpak() *VSCodePaths {
+	return &VSCodePaths{resolver: p.resolver, editor: p.editor, flatpak: true}
+}
+
+// IsFlatpakInstalled reports whether the editor is installed as a Flatpak app,
+// detected by its ~/.var/app/<app ID> directory
+func (p *VSCodePaths) IsFlatpakInstalled() bool {
+	if p.OS() != "linux" || p.editor.FlatpakID == "" {
+		return false
+	}
+	appDir, err := p.homePath(".var", "app", p.editor.FlatpakID)
+	return err == nil && dirExists(appDir)
+}
+
+// FlatpakDataDir returns the data directory of the editor's Flatpak app,
+// ~/.var/app/com.visualstudio.code/config/Code for stable VS Code. The sandbox
+// only restricts the editor; this tool reads the directory from outside it.
+func (p *VSCodePaths) FlatpakDataDir() (string, error) {
+	return p.homePath(".var", "app", p.editor.FlatpakID, "config", p.editor.DataDirName)
+}
+
+// UserDir returns the editor's user data directory, the User directory inside DataDir
+func (p *VSCodePaths) UserDir() (string, error) {
+	dataDir, err := p.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "User"), nil
+}
+
+// GlobalStoragePath returns the globalStorage directory of the VS Code user data
+func (p *VSCodePaths) GlobalStoragePath() (string, error) {
+	return p.userPath("globalStorage")
+}
+
+// StoragePath returns the storage.json path
+func (p *VSCodePaths) StoragePath() (string, error) {
+	return p.userPath("globalStorage", "storage.json")
+}
+
+// DBPath returns the state.vscdb path
+func (p *VSCodePaths) DBPath() (string, error) {
+	return p.userPath("globalStorage", "state.vscdb")
+}
+
+// MachineIDPath returns the machine ID file path
+// Windows: %APPDATA%/Code/User/machineid
+// macOS: ~/Library/Application Support/Code/machineid
+// Linux: ~/.config/Code/User/machineid
+func (p *VSCodePaths) MachineIDPath() (string, error) {
+	if p.OS() == "darwin" {
+		dataDir, err := p.DataDir()
 		if err != nil {
 			return "", err
 		}
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage", "storage.json"), nil
+		return filepath.Join(dataDir, "machineid"), nil
 	}
+	return p.userPath("machineid")
 }
 
-// GetDBPath returns the state.vscdb path across different platforms
-// Windows: %APPDATA%/Code/User/globalStorage/state.vscdb
-// macOS: ~/Library/Application Support/Code/User/globalStorage/state.vscdb
-// Linux: ~/.config/Code/User/globalStorage/state.vscdb
-func GetDBPath() (string, error) {
-	switch runtime.GOOS {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			homeDir, err := GetHomeDir()
-			if err != nil {
-				return "", err
+// WorkspaceStoragePath returns the workspaceStorage path
+func (p *VSCodePaths) WorkspaceStoragePath() (string, error) {
+	return p.userPath("workspaceStorage")
+}
+
+// UserSettingsPath returns the path of the VS Code user settings.json
+func (p *VSCodePaths) UserSettingsPath() (string, error) {
+	return p.userPath("settings.json")
+}
+
+// ExtensionsPath returns the editor's extensions directory, ~/.vscode/extensions for
+// stable VS Code on every platform (%USERPROFILE%\.vscode\extensions on Windows)
+func (p *VSCodePaths) ExtensionsPath() (string, error) {
+	return p.homePath(p.editor.ExtensionsDirName, "extensions")
+}
+
+// ExtensionsDir returns the directory the editor actually loads extensions from:
+// $VSCODE_EXTENSIONS when set, <portable data>/extensions in portable mode
+// ($VSCODE_PORTABLE) and ExtensionsPath otherwise
+func (p *VSCodePaths) ExtensionsDir() (string, error) {
+	if dir := p.Getenv("VSCODE_EXTENSIONS"); dir != "" {
+		return dir, nil
+	}
+	if portable := p.Getenv("VSCODE_PORTABLE"); portable != "" {
+		return filepath.Join(portable, "extensions"), nil
+	}
+	return p.ExtensionsPath()
+}
+
+// InsidersExtensionsPath returns the VS Code Insiders extensions directory,
+// ~/.vscode-insiders/extensions on every platform
+func (p *VSCodePaths) InsidersExtensionsPath() (string, error) {
+	return NewEditorPaths(p.resolver, EditorVSCodeInsiders).ExtensionsPath()
+}
+
+// ExtensionGlobalStoragePath returns the global storage path for a specific extension
+func (p *VSCodePaths) ExtensionGlobalStoragePath(extensionId string) (string, error) {
+	return p.userPath("globalStorage", extensionId)
+}
+
+// ExtensionWorkspaceStoragePath returns the workspace storage path for a specific
+// extension. This requires a workspace hash which is typically generated by VS Code
+func (p *VSCodePaths) ExtensionWorkspaceStoragePath(workspaceHash, extensionId string) (string, error) {
+	return p.userPath("workspaceStorage", workspaceHash, extensionId)
+}
+
+// UserSettingsPaths returns the settings.json of the regular install and, when it
+// exists, of the Snap package and Flatpak app
+func (p *VSCodePaths) UserSettingsPaths() ([]string, error) {
+	return p.locations((*VSCodePaths).UserSettingsPath)
+}
+
+// GlobalStoragePaths returns the globalStorage directory of the regular install
+// and, when it exists, of the Snap package and Flatpak app
+func (p *VSCodePaths) GlobalStoragePaths() ([]string, error) {
+	return p.locations((*VSCodePaths).GlobalStoragePath)
+}
+
+// WorkspaceStoragePaths returns the workspaceStorage directory of the regular
+// install and, when it exists, of the Snap package and Flatpak app
+func (p *VSCodePaths) WorkspaceStoragePaths() ([]string, error) {
+	return p.locations((*VSCodePaths).WorkspaceStoragePath)
+}
+
+// DBPaths returns the state.vscdb of the regular install and, when it exists, of
+// the Snap package and Flatpak app
+func (p *VSCodePaths) DBPaths() ([]string, error) {
+	return p.locations((*VSCodePaths).DBPath)
+}
+
+// locations returns path of the regular install, followed by path of the Snap
+// package and of the Flatpak app when the editor is installed as such and that
+// path exists. Flatpak paths are only returned when they can be read.
+func (p *VSCodePaths) locations(path func(*VSCodePaths) (string, error)) ([]string, error) {
+	regular, err := path(p)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := []string{regular}
+	if p.snap || p.flatpak {
+		return locations, nil
+	}
+	if p.IsSnapInstalled() {
+		if snapPath, err := path(p.Snap()); err == nil && snapPath != regular && pathExists(snapPath) {
+			locations = append(locations, snapPath)
+		}
+	}
+	if p.IsFlatpakInstalled() {
+		if flatpakPath, err := path(p.Flatpak()); err == nil && flatpakPath != regular && CheckAccess(flatpakPath, false) == nil {
+			locations = append(locations, flatpakPath)
+		}
+	}
+	return locations, nil
+}
+
+// userPath joins elem to the VS Code user data directory
+func (p *VSCodePaths) userPath(elem ...string) (string, error) {
+	userDir, err := p.UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{userDir}, elem...)...), nil
+}
+
+// homePath joins elem to the home directory
+func (p *VSCodePaths) homePath(elem ...string) (string, error) {
+	homeDir, err := p.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{homeDir}, elem...)...), nil
+}
+
+// envDir returns the directory in the environment variable key, falling back to
+// fallback below homeDir when it is unset
+func (p *VSCodePaths) envDir(key, homeDir string, fallback ...string) string {
+	if dir := p.Getenv(key); dir != "" {
+		return dir
+	}
+	return filepath.Join(append([]string{homeDir}, fallback...)...)
+}
+
+// dirExists reports whether path is an existing directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// pathExists reports whether path exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// IsSnapVSCode reports whether VS Code is installed as a Snap package (~/snap/code exists)
+func IsSnapVSCode() bool {
+	return defaultPaths().IsSnapInstalled()
+}
+
+// GetSnapVSCodeDataDir returns the data directory of Snap-packaged VS Code,
+// ~/snap/code/current/.config/Code, or "" if the home directory is unknown
+func GetSnapVSCodeDataDir() string {
+	dataDir, err := defaultPaths().SnapDataDir()
+	if err != nil {
+		return ""
+	}
+	return dataDir
+}
+
+// IsFlatpakVSCode reports whether VS Code is installed as a Flatpak app
+// (~/.var/app/com.visualstudio.code exists)
+func IsFlatpakVSCode() bool {
+	return defaultPaths().IsFlatpakInstalled()
+}
+
+// GetFlatpakVSCodeDataDir returns the data directory of Flatpak-packaged VS Code,
+// ~/.var/app/com.visualstudio.code/config/Code, or "" if the home directory is unknown
+func GetFlatpakVSCodeDataDir() string {
+	dataDir, err := defaultPaths().FlatpakDataDir()
+	if err != nil {
+		return ""
+	}
+	return dataDir
+}
+
+// GetAllVSCodeVariantPaths returns the data directory of every installed editor
+// variant, keyed by variant: "Code", "CodeInsiders", "VSCodium", "Cursor" and, for Snap
+// packages and Flatpak apps on Linux, "SnapCode", "SnapCodeInsiders",
+// "SnapVSCodium", "FlatpakCode", "FlatpakCodeInsiders" and "FlatpakVSCodium"
+func GetAllVSCodeVariantPaths() map[string]string {
+	return GetAllVSCodeVariantPathsWithResolver(DefaultPathResolver())
+}
+
+// GetAllVSCodeVariantPathsWithResolver returns the existing data directories of
+// every editor variant in the environment described by resolver, or only of the
+// editor chosen with NewEditorPathResolver
+func GetAllVSCodeVariantPathsWithResolver(resolver PathResolver) map[string]string {
+	variants := make(map[string]string)
+	for _, editor := range TargetEditors(resolver) {
+		paths := NewEditorPaths(resolver, editor)
+
+		listed := make(map[string]bool)
+		if paths.IsSnapInstalled() {
+			if dataDir, err := paths.SnapDataDir(); err == nil && dirExists(dataDir) {
+				listed[dataDir] = true
+				variants["Snap"+editor.ID] = dataDir
 			}
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
 		}
-		return filepath.Join(appData, "Code", "User", "globalStorage", "state.vscdb"), nil
-	case "darwin":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
+		if paths.IsFlatpakInstalled() {
+			if dataDir, err := paths.FlatpakDataDir(); err == nil && CheckAccess(dataDir, false) == nil {
+				listed[dataDir] = true
+				variants["Flatpak"+editor.ID] = dataDir
+			}
 		}
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage", "state.vscdb"), nil
-	default: // Linux and other Unix-like systems
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
+		// DataDir falls back to the Snap or Flatpak directory, which is already listed
+		if dataDir, err := paths.DataDir(); err == nil && !listed[dataDir] && dirExists(dataDir) {
+			variants[editor.ID] = dataDir
 		}
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage", "state.vscdb"), nil
 	}
+	return variants
+}
+
+// GetAppDataDir returns the application data directory across different platforms
+// Windows: %APPDATA% (typically C:\Users\<username>\AppData\Roaming)
+// macOS: ~/Library/Application Support
+// Linux: ~/.local/share
+func GetAppDataDir() (string, error) {
+	return defaultPaths().AppDataDir()
+}
+
+// GetStoragePath returns the storage.json path across different platforms
+// Windows: %APPDATA%/Code/User/globalStorage/storage.json
+// macOS: ~/Library/Application Support/Code/User/globalStorage/storage.json
+// Linux: ~/.config/Code/User/globalStorage/storage.json
+func GetStoragePath() (string, error) {
+	return defaultPaths().StoragePath()
+}
+
+// GetDBPath returns the state.vscdb path across different platforms
+// Windows: %APPDATA%/Code/User/globalStorage/state.vscdb
+// macOS: ~/Library/Application Support/Code/User/globalStorage/state.vscdb
+// Linux: ~/.config/Code/User/globalStorage/state.vscdb
+func GetDBPath() (string, error) {
+	return defaultPaths().DBPath()
 }
 
 // GetMachineIDPath returns the machine ID file path across different platforms
@@ -106,30 +544,7 @@ func GetDBPath() (string, error) {
 // macOS: ~/Library/Application Support/Code/machineid
 // Linux: ~/.config/Code/User/machineid
 func GetMachineIDPath() (string, error) {
-	switch runtime.GOOS {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			homeDir, err := GetHomeDir()
-			if err != nil {
-				return "", err
-			}
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "machineid"), nil
-	case "darwin":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "machineid"), nil
-	default: // Linux and other Unix-like systems
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, ".config", "Code", "User", "machineid"), nil
-	}
+	return defaultPaths().MachineIDPath()
 }
 
 // GetWorkspaceStoragePath returns the workspaceStorage path across different platforms
@@ -137,55 +552,32 @@ func GetMachineIDPath() (string, error) {
 // macOS: ~/Library/Application Support/Code/User/workspaceStorage
 // Linux: ~/.config/Code/User/workspaceStorage
 func GetWorkspaceStoragePath() (string, error) {
-	switch runtime.GOOS {
-	case "windows":
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			homeDir, err := GetHomeDir()
-			if err != nil {
-				return "", err
-			}
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "workspaceStorage"), nil
-	case "darwin":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "workspaceStorage"), nil
-	default: // Linux and other Unix-like systems
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, ".config", "Code", "User", "workspaceStorage"), nil
-	}
+	return defaultPaths().WorkspaceStoragePath()
 }
+
 // GetExtensionsPath returns the VS Code extensions directory path across different platforms
 // Windows: %USERPROFILE%/.vscode/extensions
-// macOS: ~/.vscode/extensions  
+// macOS: ~/.vscode/extensions
 // Linux: ~/.vscode/extensions
 func GetExtensionsPath() (string, error) {
-	homeDir, err := GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-	
-	return filepath.Join(homeDir, ".vscode", "extensions"), nil
+	return defaultPaths().ExtensionsPath()
+}
+
+// GetVSCodeExtensionsDir returns the directory VS Code loads extensions from,
+// honoring $VSCODE_EXTENSIONS and portable mode ($VSCODE_PORTABLE)
+// Windows: %USERPROFILE%/.vscode/extensions
+// macOS: ~/.vscode/extensions
+// Linux: ~/.vscode/extensions
+func GetVSCodeExtensionsDir() (string, error) {
+	return defaultPaths().ExtensionsDir()
 }
 
 // GetInsidersExtensionsPath returns the VS Code Insiders extensions directory path
 // Windows: %USERPROFILE%/.vscode-insiders/extensions
 // macOS: ~/.vscode-insiders/extensions
-// Linux: ~/.vscode-insiders/extensions  
+// Linux: ~/.vscode-insiders/extensions
 func GetInsidersExtensionsPath() (string, error) {
-	homeDir, err := GetHomeDir()
-	if err != nil {
-		return "", err
-	}
-	
-	return filepath.Join(homeDir, ".vscode-insiders", "extensions"), nil
+	return defaultPaths().InsidersExtensionsPath()
 }
 
 // GetExtensionGlobalStoragePath returns the global storage path for a specific extension
@@ -193,47 +585,16 @@ func GetInsidersExtensionsPath() (string, error) {
 // macOS: ~/Library/Application Support/Code/User/globalStorage/{extensionId}
 // Linux: ~/.config/Code/User/globalStorage/{extensionId}
 func GetExtensionGlobalStoragePath(extensionId string) (string, error) {
-	switch runtime.GOOS {
-	case "windows":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		
-		appData := os.Getenv("APPDATA")
-		if appData == "" {
-			appData = filepath.Join(homeDir, "AppData", "Roaming")
-		}
-		return filepath.Join(appData, "Code", "User", "globalStorage", extensionId), nil
-
-	case "darwin":
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User", "globalStorage", extensionId), nil
-
-	default: // Linux and other Unix-like systems
-		homeDir, err := GetHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(homeDir, ".config", "Code", "User", "globalStorage", extensionId), nil
-	}
+	return defaultPaths().ExtensionGlobalStoragePath(extensionId)
 }
 
 // GetExtensionWorkspaceStoragePath returns the workspace storage path for a specific extension
 // This requires a workspace hash which is typically generated by VS Code
 func GetExtensionWorkspaceStoragePath(workspaceHash, extensionId string) (string, error) {
-	workspaceStoragePath, err := GetWorkspaceStoragePath()
-	if err != nil {
-		return "", err
-	}
-	
-	return filepath.Join(workspaceStoragePath, workspaceHash, extensionId), nil
+	return defaultPaths().ExtensionWorkspaceStoragePath(workspaceHash, extensionId)
 }
 
 // GetOS returns the current operating system
 func GetOS() string {
-	return runtime.GOOS
-}
\ No newline at end of file
+	return DefaultPathResolver().GOOS()
+}
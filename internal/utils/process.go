@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ProcessInfo describes a running process
+type ProcessInfo struct {
+	PID int `json:"pid"`
+	// Name is the process name reported by the OS. Linux truncates it to 15 characters.
+	Name string `json:"name"`
+	// Exe is the full executable path; empty when it cannot be read, e.g. for processes of other users
+	Exe string `json:"exe,omitempty"`
+	// PPID is the ID of the parent process; 0 when it is unknown
+	PPID int `json:"ppid,omitempty"`
+	// CmdLine is the process arguments; empty when they cannot be read, e.g. of
+	// other users' processes or on Windows before 8.1
+	CmdLine   []string  `json:"cmdline,omitempty"`
+	User      string    `json:"user,omitempty"`
+	StartTime time.Time `json:"start_time,omitempty"`
+}
+
+// ListProcesses returns the running processes using the native OS interface:
+// /proc on Linux, the kern.proc.all sysctl on macOS and a Toolhelp snapshot on Windows.
+// Processes that exit while being read are left out.
+func ListProcesses() ([]ProcessInfo, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return processes, nil
+}
+
+// FilterProcessesByName returns the processes whose name or executable file name
+// matches name, ignoring case. name may contain path.Match wildcards (*, ?, [...]).
+func FilterProcessesByName(procs []ProcessInfo, name string) []ProcessInfo {
+	pattern := strings.ToLower(name)
+
+	var matched []ProcessInfo
+	for _, proc := range procs {
+		if matchProcessName(pattern, proc.Name) || (proc.Exe != "" && matchProcessName(pattern, exeBase(proc.Exe))) {
+			matched = append(matched, proc)
+		}
+	}
+	return matched
+}
+
+// matchProcessName reports whether a lowercase pattern matches a process name.
+// Malformed patterns match nothing.
+func matchProcessName(pattern, name string) bool {
+	matched, err := path.Match(pattern, strings.ToLower(name))
+	return err == nil && matched
+}
+
+// exeBase returns the file name of an executable path with either separator,
+// so Windows paths are handled on every OS
+func exeBase(exe string) string {
+	return exe[strings.LastIndexAny(exe, `/\`)+1:]
+}
+
+// splitWindowsCommandLine splits a Windows command line into arguments the way
+// CommandLineToArgvW does: the program name ends at the next space or, when
+// quoted, at the closing quote; in the other arguments 2n backslashes before a
+// quote are n backslashes and a quote that toggles quoting, 2n+1 backslashes are
+// n backslashes and a literal quote, and "" inside quotes is a literal quote.
+func splitWindowsCommandLine(cmdLine string) []string {
+	var args []string
+
+	// The program name, where backslashes are never escapes
+	if rest := strings.TrimLeft(cmdLine, " \t"); strings.HasPrefix(rest, `"`) {
+		name, after, _ := strings.Cut(rest[1:], `"`)
+		args = append(args, name)
+		cmdLine = after
+	} else if rest != "" {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		args = append(args, rest[:end])
+		cmdLine = rest[end:]
+	}
+
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(cmdLine); i++ {
+		c := cmdLine[i]
+		switch {
+		case (c == ' ' || c == '\t') && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			backslashes := 1
+			for i+1 < len(cmdLine) && cmdLine[i+1] == '\\' {
+				backslashes++
+				i++
+			}
+			inArg = true
+			if i+1 < len(cmdLine) && cmdLine[i+1] == '"' {
+				arg.WriteString(strings.Repeat(`\`, backslashes/2))
+				if backslashes%2 == 1 {
+					arg.WriteByte('"')
+					i++
+				}
+			} else {
+				arg.WriteString(strings.Repeat(`\`, backslashes))
+			}
+		case c == '"':
+			inArg = true
+			if quoted && i+1 < len(cmdLine) && cmdLine[i+1] == '"' {
+				arg.WriteByte('"')
+				i++
+			} else {
+				quoted = !quoted
+			}
+		default:
+			inArg = true
+			arg.WriteByte(c)
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
@@ -0,0 +1,98 @@
+//go:build darwin
+
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listProcesses lists the running processes with the kern.proc.all sysctl
+// (CTL_KERN, KERN_PROC, KERN_PROC_ALL)
+func listProcesses() ([]ProcessInfo, error) {
+	procs, err := unix.SysctlKinfoProcSlice("kern.proc.all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kern.proc.all: %w", err)
+	}
+
+	users := make(map[uint32]string)
+	processes := make([]ProcessInfo, 0, len(procs))
+	for _, proc := range procs {
+		pid := int(proc.Proc.P_pid)
+		if pid == 0 {
+			continue // kernel_task has no arguments or executable
+		}
+
+		sec, nsec := proc.Proc.P_starttime.Unix()
+		process := ProcessInfo{
+			PID:       pid,
+			PPID:      int(proc.Eproc.Ppid),
+			Name:      unix.ByteSliceToString(proc.Proc.P_comm[:]),
+			User:      darwinUsername(proc.Eproc.Ucred.Uid, users),
+			StartTime: time.Unix(sec, nsec),
+		}
+
+		// Arguments are only readable for our own processes unless running as root
+		if args, err := unix.SysctlRaw("kern.procargs2", pid); err == nil {
+			process.Exe, process.CmdLine = parseProcArgs2(args)
+		}
+		if process.Exe != "" {
+			// p_comm is cut to 16 characters, e.g. "Google Chrome He" for the helper
+			process.Name = filepath.Base(process.Exe)
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// parseProcArgs2 parses the kern.procargs2 buffer: argc as a 32-bit integer, the
+// executable path, NUL padding and then argc NUL-terminated arguments
+func parseProcArgs2(data []byte) (string, []string) {
+	if len(data) < 4 {
+		return "", nil
+	}
+	argc := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", nil
+	}
+	exe := string(data[:end])
+	data = bytes.TrimLeft(data[end:], "\x00")
+
+	var args []string
+	for len(args) < argc && len(data) > 0 {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			end = len(data)
+		}
+		args = append(args, string(data[:end]))
+		data = data[min(end+1, len(data)):]
+	}
+
+	return exe, args
+}
+
+// darwinUsername resolves a user ID, caching results; unknown IDs are returned as numbers
+func darwinUsername(uid uint32, cache map[uint32]string) string {
+	if name, ok := cache[uid]; ok {
+		return name
+	}
+
+	name := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	cache[uid] = name
+	return name
+}
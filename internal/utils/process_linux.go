@@ -0,0 +1,153 @@
+//go:build linux
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// linuxClockTicks is USER_HZ, the unit of process start times in /proc/<pid>/stat.
+// It is 100 on every architecture Linux supports today.
+const linuxClockTicks = 100
+
+// listProcesses reads the running processes from /proc
+func listProcesses() ([]ProcessInfo, error) {
+	return listProcProcesses("/proc")
+}
+
+// listProcProcesses reads every process directory of a procfs mounted at procRoot
+func listProcProcesses(procRoot string) ([]ProcessInfo, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	bootTime, _ := readBootTime(filepath.Join(procRoot, "stat")) // Start times are left zero without it
+	users := make(map[string]string)
+
+	var processes []ProcessInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		process, err := readProcProcess(filepath.Join(procRoot, entry.Name()), pid, bootTime, users)
+		if err != nil {
+			continue // The process exited while we were reading it
+		}
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// readProcProcess reads a single /proc/<pid> directory. Only status is required;
+// the executable, arguments and start time are filled in when readable.
+func readProcProcess(dir string, pid int, bootTime time.Time, users map[string]string) (ProcessInfo, error) {
+	process := ProcessInfo{PID: pid}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return process, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Name":
+			process.Name = strings.TrimSpace(value)
+		case "PPid":
+			process.PPID, _ = strconv.Atoi(strings.TrimSpace(value))
+		case "Uid":
+			if fields := strings.Fields(value); len(fields) > 0 {
+				process.User = lookupUsername(fields[0], users)
+			}
+		}
+	}
+
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		process.Exe = strings.TrimSuffix(exe, " (deleted)")
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		cmdline = bytes.TrimRight(cmdline, "\x00")
+		if len(cmdline) > 0 {
+			process.CmdLine = strings.Split(string(cmdline), "\x00")
+		}
+	}
+
+	if !bootTime.IsZero() {
+		if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil {
+			if ticks, err := parseStatStartTime(string(stat)); err == nil {
+				process.StartTime = bootTime.Add(time.Duration(ticks) * time.Second / linuxClockTicks)
+			}
+		}
+	}
+
+	return process, nil
+}
+
+// parseStatStartTime returns the starttime field (22) of /proc/<pid>/stat in clock
+// ticks after boot. The command name in field 2 may contain spaces and parentheses,
+// so fields are counted from the last closing parenthesis.
+func parseStatStartTime(stat string) (uint64, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat line")
+	}
+
+	fields := strings.Fields(stat[end+1:])
+	const startTimeIndex = 22 - 3 // Fields after the command name start at field 3
+	if len(fields) <= startTimeIndex {
+		return 0, fmt.Errorf("stat line has %d fields", len(fields)+2)
+	}
+
+	return strconv.ParseUint(fields[startTimeIndex], 10, 64)
+}
+
+// readBootTime returns the boot time from the btime line of /proc/stat
+func readBootTime(statPath string) (time.Time, error) {
+	data, err := os.ReadFile(statPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, found := strings.CutPrefix(line, "btime "); found {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("malformed btime: %w", err)
+			}
+			return time.Unix(seconds, 0), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no btime in %s", statPath)
+}
+
+// lookupUsername resolves a numeric user ID, caching results; unknown IDs are returned as is
+func lookupUsername(uid string, cache map[string]string) string {
+	if name, ok := cache[uid]; ok {
+		return name
+	}
+
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	cache[uid] = name
+	return name
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListProcProcesses(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"stat":            "cpu  1 2 3\nbtime 1760000000\nprocesses 42\n",
+		"42/status":       "Name:\tchrome_crashpad\nUmask:\t0022\nPPid:\t7\nUid:\t0\t0\t0\t0\n",
+		"42/cmdline":      "/opt/google/chrome/chrome_crashpad_handler\x00--database=/tmp/x\x00",
+		"42/stat":         "42 (chrome (crash) pad) S 1 42 42 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 250 0 0",
+		"43/cmdline":      "", // No status: exited while listing
+		"self/status":     "Name:\tignored\n",
+		"notapid/cmdline": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	if err := os.Symlink("/opt/google/chrome/chrome_crashpad_handler", filepath.Join(root, "42", "exe")); err != nil {
+		t.Fatalf("Failed to create exe link: %v", err)
+	}
+
+	procs, err := listProcProcesses(root)
+	if err != nil {
+		t.Fatalf("listProcProcesses() failed: %v", err)
+	}
+	if len(procs) != 1 {
+		t.Fatalf("Expected 1 process, got %+v", procs)
+	}
+
+	proc := procs[0]
+	if proc.PID != 42 || proc.PPID != 7 || proc.Name != "chrome_crashpad" || proc.User != "root" {
+		t.Errorf("Unexpected process: %+v", proc)
+	}
+	if proc.Exe != "/opt/google/chrome/chrome_crashpad_handler" {
+		t.Errorf("Unexpected exe: %q", proc.Exe)
+	}
+	if len(proc.CmdLine) != 2 || proc.CmdLine[1] != "--database=/tmp/x" {
+		t.Errorf("Unexpected command line: %q", proc.CmdLine)
+	}
+	if want := time.Unix(1760000002, int64(500*time.Millisecond)); !proc.StartTime.Equal(want) {
+		t.Errorf("Expected start time %v, got %v", want, proc.StartTime)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// listProcesses is not implemented on this operating system
+func listProcesses() ([]ProcessInfo, error) {
+	return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+}
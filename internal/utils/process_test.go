@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFilterProcessesByName(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, Name: "chrome"},
+		{PID: 2, Name: "Chrome.exe", Exe: `C:\Program Files\Google\Chrome\Application\Chrome.exe`},
+		{PID: 3, Name: "chrome_crashpad", Exe: "/opt/google/chrome/chrome_crashpad_handler"},
+		{PID: 4, Name: "Code Helper", Exe: "/Applications/Visual Studio Code.app/Contents/MacOS/Code Helper"},
+		{PID: 5, Name: "firefox"},
+	}
+
+	tests := []struct {
+		name string
+		want []int
+	}{
+		{"CHROME", []int{1}},
+		{"chrome.exe", []int{2}},
+		{"chrome_crashpad_handler", []int{3}},
+		{"chrome*", []int{1, 2, 3}},
+		{"code ?elper", []int{4}},
+		{"[", nil}, // Malformed patterns match nothing
+	}
+
+	for _, tt := range tests {
+		matched := FilterProcessesByName(procs, tt.name)
+		if len(matched) != len(tt.want) {
+			t.Errorf("FilterProcessesByName(%q) matched %+v, want PIDs %v", tt.name, matched, tt.want)
+			continue
+		}
+		for i, proc := range matched {
+			if proc.PID != tt.want[i] {
+				t.Errorf("FilterProcessesByName(%q) matched PID %d, want %d", tt.name, proc.PID, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestListProcessesIncludesSelf(t *testing.T) {
+	procs, err := ListProcesses()
+	if err != nil {
+		t.Skipf("Process listing unavailable: %v", err)
+	}
+
+	for _, proc := range procs {
+		if proc.PID == os.Getpid() {
+			if proc.Name == "" {
+				t.Error("Expected the current process to have a name")
+			}
+			return
+		}
+	}
+	t.Errorf("Expected the current process (pid %d) among %d processes", os.Getpid(), len(procs))
+}
+
+func TestSplitWindowsCommandLine(t *testing.T) {
+	tests := []struct {
+		cmdLine string
+		want    []string
+	}{
+		{`"C:\Program Files\Microsoft VS Code\Code.exe" "C:\work\my project"`,
+			[]string{`C:\Program Files\Microsoft VS Code\Code.exe`, `C:\work\my project`}},
+		{`C:\Code\Code.exe --type=extensionHost  --user-data-dir "C:\Users\dev\AppData\Roaming\Code"`,
+			[]string{`C:\Code\Code.exe`, "--type=extensionHost", "--user-data-dir", `C:\Users\dev\AppData\Roaming\Code`}},
+		{`code.exe a\\\"b c\\"d e" ""`, []string{"code.exe", `a\"b`, `c\d e`, ""}},
+		{`code.exe "say ""hi""" C:\dir\`, []string{"code.exe", `say "hi"`, `C:\dir\`}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got := splitWindowsCommandLine(tt.cmdLine)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWindowsCommandLine(%q) = %q, expected %q", tt.cmdLine, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+//go:build windows
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// listProcesses lists the running processes from a Toolhelp snapshot
+func listProcesses() ([]ProcessInfo, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create process snapshot: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	if err := windows.Process32First(snapshot, &entry); err != nil {
+		return nil, fmt.Errorf("failed to read process snapshot: %w", err)
+	}
+
+	var processes []ProcessInfo
+	for {
+		process := ProcessInfo{
+			PID:  int(entry.ProcessID),
+			PPID: int(entry.ParentProcessID),
+			Name: windows.UTF16ToString(entry.ExeFile[:]),
+		}
+		readWindowsProcessDetails(&process)
+		processes = append(processes, process)
+
+		if err := windows.Process32Next(snapshot, &entry); err != nil {
+			if errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read process snapshot: %w", err)
+		}
+	}
+
+	return processes, nil
+}
+
+// readWindowsProcessDetails fills in the executable path, command line, start time
+// and owner of a process. Protected and system processes cannot be opened and keep
+// only their name.
+func readWindowsProcessDetails(process *ProcessInfo) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(process.PID))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err == nil {
+		process.Exe = windows.UTF16ToString(buf[:size])
+	}
+
+	if cmdLine, err := readWindowsCommandLine(handle); err == nil {
+		process.CmdLine = splitWindowsCommandLine(cmdLine)
+	}
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err == nil {
+		process.StartTime = time.Unix(0, creation.Nanoseconds())
+	}
+
+	var token windows.Token
+	if err := windows.OpenProcessToken(handle, windows.TOKEN_QUERY, &token); err == nil {
+		defer token.Close()
+		if tokenUser, err := token.GetTokenUser(); err == nil {
+			if account, domain, _, err := tokenUser.User.Sid.LookupAccount(""); err == nil {
+				process.User = domain + `\` + account
+			}
+		}
+	}
+}
+
+// readWindowsCommandLine returns the command line of a process opened with
+// PROCESS_QUERY_LIMITED_INFORMATION. It queries ProcessCommandLineInformation,
+// which Windows 8.1 and later provide without reading the process's memory.
+func readWindowsCommandLine(handle windows.Handle) (string, error) {
+	// The first call fails with the size of the UNICODE_STRING and its buffer
+	var size uint32
+	err := windows.NtQueryInformationProcess(handle, windows.ProcessCommandLineInformation, nil, 0, &size)
+	if size == 0 {
+		return "", fmt.Errorf("failed to query command line size: %w", err)
+	}
+
+	// uint64 elements keep the UNICODE_STRING's pointer aligned
+	buf := make([]uint64, (size+7)/8)
+	if err := windows.NtQueryInformationProcess(handle, windows.ProcessCommandLineInformation, unsafe.Pointer(&buf[0]), size, &size); err != nil {
+		return "", fmt.Errorf("failed to query command line: %w", err)
+	}
+	return (*windows.NTUnicodeString)(unsafe.Pointer(&buf[0])).String(), nil
+}
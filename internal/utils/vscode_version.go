@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// vscodePackageJSON mirrors the fields of VS Code's resources/app/package.json we need
+type vscodePackageJSON struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DetectVSCodeVersion returns the version of the first VS Code installation found,
+// read from <vscode-install>/resources/app/package.json
+func DetectVSCodeVersion() (string, error) {
+	candidates := vscodePackageJSONPaths(runtime.GOOS)
+	for _, path := range candidates {
+		version, err := readVSCodeVersion(path)
+		if err == nil {
+			return version, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("VS Code installation not found (checked %d locations)", len(candidates))
+}
+
+// readVSCodeVersion reads the version field of a VS Code package.json
+func readVSCodeVersion(packageJSONPath string) (string, error) {
+	data, err := os.ReadFile(packageJSONPath)
+	if err != nil {
+		return "", err
+	}
+
+	var pkg vscodePackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", packageJSONPath, err)
+	}
+	if pkg.Version == "" {
+		return "", fmt.Errorf("no version in %s", packageJSONPath)
+	}
+
+	return pkg.Version, nil
+}
+
+// vscodePackageJSONPaths returns the package.json locations of the usual VS Code installs
+// Windows: %LOCALAPPDATA%/Programs/Microsoft VS Code (user), %ProgramFiles%/Microsoft VS Code (system)
+// macOS: /Applications/Visual Studio Code.app/Contents/Resources
+// Linux: /usr/share/code, /usr/lib/code, /opt/visual-studio-code, snap
+func vscodePackageJSONPaths(goos string) []string {
+	var installDirs []string
+
+	switch goos {
+	case "windows":
+		if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+			installDirs = append(installDirs, filepath.Join(localAppData, "Programs", "Microsoft VS Code", "resources"))
+		}
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+			if programFiles := os.Getenv(env); programFiles != "" {
+				installDirs = append(installDirs, filepath.Join(programFiles, "Microsoft VS Code", "resources"))
+			}
+		}
+	case "darwin":
+		installDirs = append(installDirs, filepath.Join("/Applications", "Visual Studio Code.app", "Contents", "Resources"))
+		if homeDir, err := GetHomeDir(); err == nil {
+			installDirs = append(installDirs, filepath.Join(homeDir, "Applications", "Visual Studio Code.app", "Contents", "Resources"))
+		}
+	default: // Linux and other Unix-like systems
+		installDirs = append(installDirs,
+			filepath.Join("/usr", "share", "code", "resources"),
+			filepath.Join("/usr", "lib", "code", "resources"),
+			filepath.Join("/opt", "visual-studio-code", "resources"),
+			filepath.Join("/snap", "code", "current", "usr", "share", "code", "resources"),
+		)
+	}
+
+	paths := make([]string, 0, len(installDirs))
+	for _, dir := range installDirs {
+		paths = append(paths, filepath.Join(dir, "app", "package.json"))
+	}
+	return paths
+}
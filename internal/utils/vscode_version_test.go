@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadVSCodeVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(path, []byte(`{"name":"Code","version":"1.95.3"}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	version, err := readVSCodeVersion(path)
+	if err != nil {
+		t.Fatalf("readVSCodeVersion() failed: %v", err)
+	}
+	if version != "1.95.3" {
+		t.Errorf("Expected version 1.95.3, got %s", version)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"name":"Code"}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	if _, err := readVSCodeVersion(path); err == nil {
+		t.Error("Expected error for package.json without version")
+	}
+
+	if _, err := readVSCodeVersion(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error for missing package.json, got %v", err)
+	}
+}
+
+func TestVSCodePackageJSONPaths(t *testing.T) {
+	t.Setenv("LOCALAPPDATA", filepath.Join("C:", "Users", "alice", "AppData", "Local"))
+
+	for _, goos := range []string{"windows", "darwin", "linux"} {
+		paths := vscodePackageJSONPaths(goos)
+		if len(paths) == 0 {
+			t.Errorf("%s: expected candidate paths", goos)
+		}
+		for _, path := range paths {
+			if !strings.HasSuffix(path, filepath.Join("app", "package.json")) {
+				t.Errorf("%s: unexpected candidate %s", goos, path)
+			}
+		}
+	}
+}
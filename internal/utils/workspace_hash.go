@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ComputeWorkspaceHash returns the workspaceStorage directory name VS Code uses for
+// a local folder or .code-workspace file, so a path can be looked up without reading
+// every workspace.json.
+//
+// VS Code (src/vs/platform/workspaces/node/workspaces.ts) names folder storage after
+// the MD5 of the folder's fsPath followed by a salt that changes when the folder is
+// recreated: the inode on Linux and the creation time in milliseconds on macOS and
+// Windows. Multi-root workspaces use the MD5 of the .code-workspace path, lowercased
+// except on Linux. A leading "~" is expanded and symlinks are resolved first.
+func ComputeWorkspaceHash(workspacePath string) (string, error) {
+	path, err := normalizeWorkspacePath(workspacePath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat workspace: %w", err)
+	}
+
+	fsPath := workspaceFSPath(path, runtime.GOOS)
+	if !info.IsDir() {
+		if !strings.EqualFold(filepath.Ext(path), ".code-workspace") {
+			return "", fmt.Errorf("not a folder or .code-workspace file: %s", path)
+		}
+		return multiRootWorkspaceHash(fsPath, runtime.GOOS), nil
+	}
+
+	return folderWorkspaceHash(fsPath, workspaceSalt(info)), nil
+}
+
+// normalizeWorkspacePath expands "~", makes the path absolute and resolves symlinks
+func normalizeWorkspacePath(workspacePath string) (string, error) {
+	path := workspacePath
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		homeDir, err := GetHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path, nil
+}
+
+// workspaceFSPath returns the path as VS Code's URI.fsPath prints it: on Windows
+// with backslashes and a lowercase drive letter, while UNC paths (\\server\share)
+// keep their case
+func workspaceFSPath(path, goos string) string {
+	if goos != "windows" {
+		return path
+	}
+
+	path = strings.ReplaceAll(path, "/", `\`)
+	if len(path) >= 2 && path[1] == ':' {
+		path = strings.ToLower(path[:1]) + path[1:]
+	}
+	return path
+}
+
+// folderWorkspaceHash returns the storage name of a folder: md5(fsPath + salt)
+func folderWorkspaceHash(fsPath, salt string) string {
+	sum := md5.Sum([]byte(fsPath + salt))
+	return hex.EncodeToString(sum[:])
+}
+
+// multiRootWorkspaceHash returns the storage name of a .code-workspace file. Only
+// Linux has case-sensitive file systems by default, so other platforms lowercase
+// the path and "Work.code-workspace" and "work.code-workspace" share storage.
+func multiRootWorkspaceHash(fsPath, goos string) string {
+	if goos != "linux" {
+		fsPath = strings.ToLower(fsPath)
+	}
+	sum := md5.Sum([]byte(fsPath))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,21 @@
+//go:build darwin
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// workspaceSalt returns the folder creation time in milliseconds, which VS Code
+// salts folder hashes with on macOS
+func workspaceSalt(info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	birth := time.Unix(stat.Birthtimespec.Unix())
+	return strconv.FormatInt(birth.UnixMilli(), 10)
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// workspaceSalt returns the inode VS Code salts folder hashes with on Linux, where
+// the birth time is not reliably available
+func workspaceSalt(info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(uint64(stat.Ino), 10)
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package utils
+
+import "os"
+
+// workspaceSalt is unknown on this platform; VS Code then hashes the path alone
+func workspaceSalt(info os.FileInfo) string {
+	return ""
+}
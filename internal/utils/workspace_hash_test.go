@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWorkspaceFSPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		goos string
+		want string
+	}{
+		{"unix path unchanged", "/home/me/Project", "linux", "/home/me/Project"},
+		{"macOS keeps case", "/Users/Me/Project", "darwin", "/Users/Me/Project"},
+		{"windows drive letter lowercased", `C:\Users\Me\Project`, "windows", `c:\Users\Me\Project`},
+		{"windows forward slashes", "D:/src/app", "windows", `d:\src\app`},
+		{"windows UNC path", `\\FileServer\Share\Project`, "windows", `\\FileServer\Share\Project`},
+		{"windows UNC forward slashes", "//FileServer/Share/Project", "windows", `\\FileServer\Share\Project`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workspaceFSPath(tt.path, tt.goos); got != tt.want {
+				t.Errorf("workspaceFSPath(%q, %q) = %q, want %q", tt.path, tt.goos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolderWorkspaceHash(t *testing.T) {
+	sum := md5.Sum([]byte("/home/me/project" + "1234567"))
+	if got, want := folderWorkspaceHash("/home/me/project", "1234567"), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	// The salt keeps a recreated folder from inheriting the old storage
+	if folderWorkspaceHash("/home/me/project", "1") == folderWorkspaceHash("/home/me/project", "2") {
+		t.Error("Expected different salts to produce different hashes")
+	}
+
+	// UNC paths are hashed with their case preserved
+	if folderWorkspaceHash(`\\Server\Share\app`, "1") == folderWorkspaceHash(`\\server\share\app`, "1") {
+		t.Error("Expected UNC folder hashes to be case-sensitive")
+	}
+}
+
+func TestMultiRootWorkspaceHashCase(t *testing.T) {
+	upper := "/Users/Me/Work.code-workspace"
+	lower := "/users/me/work.code-workspace"
+
+	if multiRootWorkspaceHash(upper, "darwin") != multiRootWorkspaceHash(lower, "darwin") {
+		t.Error("Expected macOS workspace file hashes to ignore case")
+	}
+	if multiRootWorkspaceHash(`C:\Work.code-workspace`, "windows") != multiRootWorkspaceHash(`c:\work.code-workspace`, "windows") {
+		t.Error("Expected Windows workspace file hashes to ignore case")
+	}
+	if multiRootWorkspaceHash(upper, "linux") == multiRootWorkspaceHash(lower, "linux") {
+		t.Error("Expected Linux workspace file hashes to be case-sensitive")
+	}
+
+	sum := md5.Sum([]byte(lower))
+	if got := multiRootWorkspaceHash(upper, "darwin"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected workspace file hash: %s", got)
+	}
+}
+
+func TestComputeWorkspaceHash(t *testing.T) {
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "project")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+
+	hash, err := ComputeWorkspaceHash(folder)
+	if err != nil {
+		t.Fatalf("ComputeWorkspaceHash failed: %v", err)
+	}
+	if len(hash) != 32 {
+		t.Errorf("Expected a 32 character MD5 hash, got %q", hash)
+	}
+
+	// Symlinks resolve to the folder they point at
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(folder, link); err == nil {
+		linked, err := ComputeWorkspaceHash(link)
+		if err != nil {
+			t.Fatalf("ComputeWorkspaceHash via symlink failed: %v", err)
+		}
+		if linked != hash {
+			t.Errorf("Expected symlink hash %s, got %s", hash, linked)
+		}
+	}
+
+	workspaceFile := filepath.Join(dir, "Team.code-workspace")
+	if err := os.WriteFile(workspaceFile, []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write workspace file: %v", err)
+	}
+	resolved, err := filepath.EvalSymlinks(workspaceFile)
+	if err != nil {
+		t.Fatalf("Failed to resolve workspace file: %v", err)
+	}
+	fileHash, err := ComputeWorkspaceHash(workspaceFile)
+	if err != nil {
+		t.Fatalf("ComputeWorkspaceHash for workspace file failed: %v", err)
+	}
+	if want := multiRootWorkspaceHash(workspaceFSPath(resolved, runtime.GOOS), runtime.GOOS); fileHash != want {
+		t.Errorf("Expected workspace file hash %s, got %s", want, fileHash)
+	}
+
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ComputeWorkspaceHash(other); err == nil {
+		t.Error("Expected an error for a plain file")
+	}
+	if _, err := ComputeWorkspaceHash(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
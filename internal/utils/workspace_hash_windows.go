@@ -0,0 +1,19 @@
+//go:build windows
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// workspaceSalt returns the folder creation time in whole milliseconds, which VS
+// Code salts folder hashes with on Windows
+func workspaceSalt(info os.FileInfo) string {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatInt(attrs.CreationTime.Nanoseconds()/1e6, 10)
+}
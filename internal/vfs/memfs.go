@@ -0,0 +1,396 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"augment-telemetry-cleaner/internal/filelock"
+)
+
+var (
+	errNotDir   = errors.New("not a directory")
+	errIsDir    = errors.New("is a directory")
+	errNotEmpty = errors.New("directory not empty")
+	errBadMode  = errors.New("bad file descriptor")
+)
+
+// MemFS is a FileSystem held in memory, for tests. Relative and absolute paths
+// are separate trees whose roots always exist. It is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+	locks map[string]bool
+}
+
+// memNode is a file or directory of a MemFS
+type memNode struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// NewMemFS creates an empty in-memory file system
+func NewMemFS() *MemFS {
+	return &MemFS{
+		nodes: make(map[string]*memNode),
+		locks: make(map[string]bool),
+	}
+}
+
+// Open opens a file for reading
+func (m *MemFS) Open(name string) (File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// Create creates or truncates a file
+func (m *MemFS) Create(name string) (File, error) {
+	return m.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// OpenFile opens a file with the given os.O_* flags. The parent directory must exist.
+func (m *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	switch {
+	case ok && node.mode.IsDir() && flag&(os.O_WRONLY|os.O_RDWR) != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	case ok && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case !ok && isRoot(name):
+		node = &memNode{mode: os.ModeDir | 0755}
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok:
+		if err := m.checkParent("open", name); err != nil {
+			return nil, err
+		}
+		node = &memNode{mode: perm.Perm(), modTime: time.Now()}
+		m.nodes[name] = node
+	}
+
+	if flag&os.O_TRUNC != 0 && !node.mode.IsDir() {
+		node.data = nil
+		node.modTime = time.Now()
+	}
+
+	file := &memFile{fs: m, name: name, node: node, flag: flag}
+	if flag&os.O_APPEND != 0 {
+		file.offset = int64(len(node.data))
+	}
+	return file, nil
+}
+
+// ReadFile returns a copy of a file's content
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+// WriteFile replaces a file's content in one step
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if node, ok := m.nodes[name]; ok && node.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errIsDir}
+	}
+	if err := m.checkParent("write", name); err != nil {
+		return err
+	}
+	m.nodes[name] = &memNode{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// MkdirAll creates a directory and its parents
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	var missing []string
+	for dir := path; !isRoot(dir); dir = filepath.Dir(dir) {
+		if node, ok := m.nodes[dir]; ok {
+			if !node.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: errNotDir}
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		m.nodes[dir] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+// Remove removes a file or an empty directory
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() && len(m.children(name)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// Stat returns the file info of a path
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return newMemFileInfo(filepath.Clean(name), node), nil
+}
+
+// Walk visits root and everything below it in lexical order like filepath.Walk.
+// fn may modify the file system; entries are listed before it is called.
+func (m *MemFS) Walk(root string, fn filepath.WalkFunc) error {
+	root = filepath.Clean(root)
+	info, err := m.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = m.walk(root, info, fn)
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walk calls fn for path and, if it is a directory, for its children
+func (m *MemFS) walk(path string, info os.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+	if err := fn(path, info, nil); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	children := m.children(path)
+	m.mu.Unlock()
+
+	for _, child := range children {
+		childInfo, err := m.Stat(child)
+		if err != nil {
+			if err := fn(child, nil, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			continue
+		}
+		if err := m.walk(child, childInfo, fn); err != nil {
+			if !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			if !childInfo.IsDir() {
+				return nil // SkipDir on a file skips the rest of its directory
+			}
+		}
+	}
+	return nil
+}
+
+// Lock takes an exclusive lock on an existing path within this MemFS
+func (m *MemFS) Lock(path string) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, err := m.lookup("lock", path); err != nil {
+		return nil, err
+	}
+	if m.locks[path] {
+		return nil, filelock.ErrLockBusy
+	}
+	m.locks[path] = true
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.locks, path)
+	}, nil
+}
+
+// lookup returns the node of a path; roots always exist. The caller holds m.mu.
+func (m *MemFS) lookup(op, name string) (*memNode, error) {
+	name = filepath.Clean(name)
+	if node, ok := m.nodes[name]; ok {
+		return node, nil
+	}
+	if isRoot(name) {
+		return &memNode{mode: os.ModeDir | 0755}, nil
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// checkParent fails unless the parent directory of name exists. The caller holds m.mu.
+func (m *MemFS) checkParent(op, name string) error {
+	parent, err := m.lookup(op, filepath.Dir(name))
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: errNotDir}
+	}
+	return nil
+}
+
+// children returns the sorted paths directly inside dir. The caller holds m.mu.
+func (m *MemFS) children(dir string) []string {
+	var children []string
+	for path := range m.nodes {
+		if path != dir && filepath.Dir(path) == dir {
+			children = append(children, path)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// isRoot reports whether path is "." or a volume root, which always exist
+func isRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+// memFile is an open MemFS file; reads and writes go straight to its node
+type memFile struct {
+	fs     *MemFS
+	name   string
+	node   *memNode
+	flag   int
+	offset int64
+	closed bool
+}
+
+// Read reads from the current offset
+func (f *memFile) Read(p []byte) (int, error) {
+	if err := f.check("read", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+// ReadAt reads from an absolute offset
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.check("read", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.node.mode.IsDir() {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errIsDir}
+	}
+	if off >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Write writes at the current offset, growing the file as needed
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: errBadMode}
+	}
+	if err := f.check("write", 0); err != nil {
+		return 0, err
+	}
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if end := f.offset + int64(len(p)); end > int64(len(f.node.data)) {
+		f.node.data = append(f.node.data, make([]byte, end-int64(len(f.node.data)))...)
+	}
+	copy(f.node.data[f.offset:], p)
+	f.offset += int64(len(p))
+	f.node.modTime = time.Now()
+	return len(p), nil
+}
+
+// Close closes the file; further reads and writes fail
+func (f *memFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// Stat returns the file info of the open file
+func (f *memFile) Stat() (os.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	return newMemFileInfo(f.name, f.node), nil
+}
+
+// check fails if the file is closed or was opened with the forbidden access flag
+func (f *memFile) check(op string, forbidden int) error {
+	if f.closed {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+	if forbidden != 0 && f.flag&forbidden != 0 {
+		return &fs.PathError{Op: op, Path: f.name, Err: errBadMode}
+	}
+	return nil
+}
+
+// memFileInfo is a snapshot of a node's metadata
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// newMemFileInfo snapshots a node. The caller holds the MemFS lock.
+func newMemFileInfo(path string, node *memNode) *memFileInfo {
+	return &memFileInfo{
+		name:    filepath.Base(path),
+		size:    int64(len(node.data)),
+		mode:    node.mode,
+		modTime: node.modTime,
+	}
+}
+
+func (fi *memFileInfo) Name() string       { return fi.name }
+func (fi *memFileInfo) Size() int64        { return fi.size }
+func (fi *memFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *memFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *memFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *memFileInfo) Sys() interface{}   { return nil }
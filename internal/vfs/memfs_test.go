@@ -0,0 +1,125 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/filelock"
+)
+
+func TestMemFSFiles(t *testing.T) {
+	memFS := NewMemFS()
+	path := filepath.FromSlash("/data/dir/file.txt")
+
+	if _, err := memFS.Create(path); !os.IsNotExist(err) {
+		t.Errorf("Expected creating a file in a missing directory to fail with not exist, got %v", err)
+	}
+	if err := memFS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+
+	file, err := memFS.Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := io.WriteString(file, "hello world"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file.Close()
+
+	file, err = memFS.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	buf := make([]byte, 5)
+	if n, err := file.ReadAt(buf, 6); n != 5 || string(buf) != "world" {
+		t.Errorf("Expected ReadAt to return world, got %q (%v)", buf[:n], err)
+	}
+	if _, err := file.Write([]byte("x")); err == nil {
+		t.Error("Expected writing a file opened for reading to fail")
+	}
+	if info, err := file.Stat(); err != nil || info.Size() != 11 || info.Name() != "file.txt" || info.IsDir() {
+		t.Errorf("Unexpected file info %v (%v)", info, err)
+	}
+	file.Close()
+
+	if err := memFS.MkdirAll(path, 0755); err == nil {
+		t.Error("Expected MkdirAll over a file to fail")
+	}
+	if err := memFS.Remove(filepath.Dir(path)); err == nil {
+		t.Error("Expected removing a non-empty directory to fail")
+	}
+	if err := memFS.Remove(path); err != nil {
+		t.Errorf("Remove failed: %v", err)
+	}
+	if _, err := memFS.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the removed file to be gone, got %v", err)
+	}
+}
+
+func TestMemFSWalk(t *testing.T) {
+	memFS := NewMemFS()
+	root := filepath.FromSlash("/root")
+	for _, name := range []string{"b.txt", "a/2.txt", "a/1.txt", "skip/x.txt", "c.txt"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		memFS.MkdirAll(filepath.Dir(path), 0755)
+		memFS.WriteFile(path, []byte(name), 0644)
+	}
+
+	var visited []string
+	err := memFS.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		visited = append(visited, filepath.ToSlash(rel))
+		if info.IsDir() && info.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	want := []string{".", "a", "a/1.txt", "a/2.txt", "b.txt", "c.txt", "skip"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("Expected %v, got %v", want, visited)
+	}
+
+	var missingErr error
+	memFS.Walk(filepath.FromSlash("/missing"), func(path string, info os.FileInfo, err error) error {
+		missingErr = err
+		return nil
+	})
+	if !os.IsNotExist(missingErr) {
+		t.Errorf("Expected Walk to report a missing root, got %v", missingErr)
+	}
+}
+
+func TestMemFSLock(t *testing.T) {
+	memFS := NewMemFS()
+	if err := memFS.MkdirAll("backups", 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+
+	unlock, err := memFS.Lock("backups")
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if _, err := memFS.Lock("backups"); !errors.Is(err, filelock.ErrLockBusy) {
+		t.Errorf("Expected ErrLockBusy for a held lock, got %v", err)
+	}
+	unlock()
+
+	unlock, err = memFS.Lock("backups")
+	if err != nil {
+		t.Errorf("Expected the lock to be free after unlock, got %v", err)
+	} else {
+		unlock()
+	}
+}
@@ -0,0 +1,100 @@
+// Package vfs abstracts the file operations of the backup code so that it can run
+// against the real disk (OsFS) or, in tests, against an in-memory tree (MemFS).
+package vfs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/filelock"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// FileSystem is the subset of the os and filepath packages used by the backup code
+type FileSystem interface {
+	Open(name string) (File, error)
+	Create(name string) (File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	ReadFile(name string) ([]byte, error)
+	// WriteFile replaces name atomically, so readers never see a partial file
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	MkdirAll(path string, perm os.FileMode) error
+	Remove(name string) error
+	Stat(name string) (os.FileInfo, error)
+	// Walk visits the tree in lexical order with the semantics of filepath.Walk
+	Walk(root string, fn filepath.WalkFunc) error
+	// Lock takes an exclusive, non-blocking lock on an existing path and fails
+	// with filelock.ErrLockBusy while it is held elsewhere
+	Lock(path string) (unlock func(), err error)
+}
+
+// File is an open file of a FileSystem. ReadAt lets archive/zip read it directly.
+type File interface {
+	io.Reader
+	io.Writer
+	io.ReaderAt
+	io.Closer
+	Stat() (os.FileInfo, error)
+}
+
+// OsFS is the FileSystem of the operating system
+type OsFS struct{}
+
+// Open opens a file for reading
+func (OsFS) Open(name string) (File, error) {
+	return fileOrNil(os.Open(name))
+}
+
+// Create creates or truncates a file
+func (OsFS) Create(name string) (File, error) {
+	return fileOrNil(os.Create(name))
+}
+
+// OpenFile opens a file with the given os.O_* flags
+func (OsFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	return fileOrNil(os.OpenFile(name, flag, perm))
+}
+
+// ReadFile reads a whole file
+func (OsFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile writes a file through a temporary file and a rename
+func (OsFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return utils.WriteFileAtomic(name, data, perm)
+}
+
+// MkdirAll creates a directory and its parents
+func (OsFS) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Remove removes a file or an empty directory
+func (OsFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// Stat returns the file info of a path
+func (OsFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Walk walks a directory tree with filepath.Walk
+func (OsFS) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+// Lock takes an advisory lock with filelock.Lock
+func (OsFS) Lock(path string) (func(), error) {
+	return filelock.Lock(path)
+}
+
+// fileOrNil avoids returning a nil *os.File as a non-nil File
+func fileOrNil(file *os.File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
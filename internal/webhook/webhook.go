@@ -0,0 +1,119 @@
+// Package webhook posts a JSON summary of each run to a URL, e.g. so fleet
+// management learns when the cleaner ran on a developer machine.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SecretEnv is the environment variable holding the shared secret payloads are signed with
+const SecretEnv = "AUGMENT_WEBHOOK_SECRET"
+
+// SignatureHeader carries "sha256=<hex HMAC-SHA256 of the body>" when a secret is set
+const SignatureHeader = "X-Augment-Signature"
+
+// DefaultTimeout bounds each delivery attempt
+const DefaultTimeout = 10 * time.Second
+
+// retryDelay is the pause before the single retry of a failed delivery
+const retryDelay = 2 * time.Second
+
+// Summary is the payload posted after a run
+type Summary struct {
+	Operation  string           `json:"operation"`
+	HostID     string           `json:"host_id"` // Hash of the host name, see HostID
+	Success    bool             `json:"success"`
+	ExitCode   int              `json:"exit_code"`
+	DryRun     bool             `json:"dry_run"`
+	Counts     map[string]int64 `json:"counts,omitempty"`
+	Errors     []string         `json:"errors,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	DurationMs int64            `json:"duration_ms"`
+}
+
+// Sender posts summaries to a webhook URL
+type Sender struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+// NewSender creates a sender for url. Payloads are signed when secret is non-empty.
+func NewSender(url string, secret []byte) *Sender {
+	return &Sender{
+		url:        url,
+		secret:     secret,
+		client:     &http.Client{Timeout: DefaultTimeout},
+		retryDelay: retryDelay,
+	}
+}
+
+// Send posts summary, retrying once if the first attempt fails
+func (s *Sender) Send(summary Summary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook summary: %w", err)
+	}
+
+	if err = s.post(body); err == nil {
+		return nil
+	}
+	time.Sleep(s.retryDelay)
+	if err = s.post(body); err != nil {
+		return fmt.Errorf("failed to deliver webhook after retry: %w", err)
+	}
+	return nil
+}
+
+// post makes a single delivery attempt; any status other than 2xx is an error
+func (s *Sender) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of body: "sha256=" followed by the hex
+// HMAC-SHA256 of body keyed with secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HostID returns a stable identifier of this machine that does not reveal its
+// host name: the first 16 hex digits of a SHA-256 of the name
+func HostID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	sum := sha256.Sum256([]byte("augment-telemetry-cleaner:" + hostname))
+	return hex.EncodeToString(sum[:])[:16]
+}
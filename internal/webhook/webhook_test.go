@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendSignsPayload(t *testing.T) {
+	secret := []byte("shared-secret")
+	var got Summary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if signature := r.Header.Get(SignatureHeader); signature != Sign(secret, body) {
+			t.Errorf("Unexpected signature %q", signature)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	summary := Summary{Operation: "clean-database", HostID: HostID(), Success: true, Counts: map[string]int64{"database_records_deleted": 3}}
+	if err := NewSender(server.URL, secret).Send(summary); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got.Operation != "clean-database" || got.Counts["database_records_deleted"] != 3 || got.HostID != summary.HostID {
+		t.Errorf("Unexpected payload %+v", got)
+	}
+}
+
+func TestSendRetriesOnce(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Error("Expected no signature without a secret")
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	sender := NewSender(server.URL, nil)
+	sender.retryDelay = 0
+	if err := sender.Send(Summary{Operation: "scan"}); err != nil {
+		t.Fatalf("Expected the retry to succeed: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	sender = NewSender(failing.URL, nil)
+	sender.retryDelay = 0
+	if err := sender.Send(Summary{Operation: "scan"}); err == nil {
+		t.Error("Expected an error when both attempts fail")
+	}
+	if attempts != 2 {
+		t.Errorf("Expected exactly one retry, got %d attempts", attempts)
+	}
+}
@@ -9,10 +9,12 @@ import (
 
 func main() {
 	myApp := app.NewWithID("com.vinaykoirala.augmenttelemetrycleaner")
-	myApp.SetIcon(resources.ResourceIconPng)
+	// The standard icon is nearly invisible on dark taskbars and title bars
+	icon := resources.IconForVariant(myApp.Settings().ThemeVariant())
+	myApp.SetIcon(icon)
 
 	mainWindow := myApp.NewWindow("Augment Telemetry Cleaner v2.0.0")
-	mainWindow.SetIcon(resources.ResourceIconPng)
+	mainWindow.SetIcon(icon)
 	mainWindow.Resize(fyne.NewSize(800, 700))
 	mainWindow.CenterOnScreen()
 
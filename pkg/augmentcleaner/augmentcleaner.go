@@ -0,0 +1,837 @@
+// Package augmentcleaner is the public API of the Augment Telemetry Cleaner.
+//
+// It exposes the cleaning and scanning operations used by the CLI and GUI so
+// other Go tools can embed them. Functions never print to stdout; progress is
+// reported through the optional Options.Progress callback.
+package augmentcleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/browser"
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/logger"
+	"augment-telemetry-cleaner/internal/scanner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// Result types re-exported from the internal packages
+type (
+	// Report is the result of a storage scan
+	Report = scanner.StorageAnalysisResult
+	// TelemetryModifyResult is the result of rotating telemetry IDs
+	TelemetryModifyResult = cleaner.TelemetryModifyResult
+	// DatabaseCleanResult is the result of cleaning the VS Code database
+	DatabaseCleanResult = cleaner.DatabaseCleanResult
+	// DatabaseCleanEstimate is what CleanDatabase would delete, see EstimateDatabaseClean
+	DatabaseCleanEstimate = cleaner.DatabaseCleanEstimate
+	// WorkspaceCleanResult is the result of cleaning workspace storage
+	WorkspaceCleanResult = cleaner.WorkspaceCleanResult
+	// WorkspaceCleanEstimate is what CleanWorkspace would delete, see EstimateWorkspaceClean
+	WorkspaceCleanEstimate = cleaner.WorkspaceCleanEstimate
+	// OrphanedWorkspace is a workspace storage directory whose folder no longer exists
+	OrphanedWorkspace = cleaner.OrphanedWorkspace
+	// OrphanCleanResult is the result of pruning orphaned workspace storage
+	OrphanCleanResult = cleaner.OrphanCleanResult
+	// ExtensionNotFoundError is returned by Scan for an ExtensionID no extension has
+	ExtensionNotFoundError = scanner.ExtensionNotFoundError
+	// SecretEntry is an Augment entry in the OS secret store
+	SecretEntry = scanner.SecretEntry
+	// SecretStoreCleanResult is the result of removing Augment secrets from the OS secret store
+	SecretStoreCleanResult = cleaner.SecretStoreCleanResult
+	// BrowserCleanResult is the result of cleaning a single browser profile
+	BrowserCleanResult = browser.BrowserCleanResult
+	// BrowserProfile is a detected browser profile
+	BrowserProfile = browser.BrowserProfile
+	// BrowserProfileCount is the Augment data found in a single browser profile
+	BrowserProfileCount = browser.ProfileDataCount
+	// BrowserProcess is a running process matched to a browser
+	BrowserProcess = browser.BrowserProcess
+	// SettingsSuggestion is the privacy score of the VS Code settings with recommended changes
+	SettingsSuggestion = scanner.SettingsSuggestion
+	// PrivacyScore rates the privacy of one extension from 0 to 100
+	PrivacyScore = scanner.PrivacyScore
+	// GrowthAnalysis lists the extensions whose telemetry storage grew abnormally
+	// fast since the previous scan
+	GrowthAnalysis = scanner.GrowthAnalysis
+	// GrowthEntry is the telemetry storage growth of one extension
+	GrowthEntry = scanner.GrowthEntry
+	// Backup is the metadata of an extension backup
+	Backup = cleaner.BackupMetadata
+	// RestoreVSCodeSettingsResult is the result of restoring settings.json from a backup
+	RestoreVSCodeSettingsResult = cleaner.RestoreVSCodeSettingsResult
+	// IDEProcess is a running VS Code main process or extension host
+	IDEProcess = cleaner.IDEProcess
+	// MultipleIDEWindowsError is returned when several VS Code windows are open
+	MultipleIDEWindowsError = cleaner.MultipleIDEWindowsError
+	// SettingChange is a top-level settings.json key whose value a restore or clean changed
+	SettingChange = cleaner.SettingChange
+	// CleanVSCodeSettingsResult is the result of cleaning Augment settings from settings.json
+	CleanVSCodeSettingsResult = cleaner.CleanVSCodeSettingsResult
+	// BackupStats summarizes the disk space and coverage of all extension backups
+	BackupStats = cleaner.BackupStats
+	// SettingsSyncState is whether VS Code Settings Sync is on, see DetectSettingsSync
+	SettingsSyncState = cleaner.SettingsSyncState
+	// BackupPage is one page of extension backups, see ListBackupsPage
+	BackupPage = cleaner.BackupPage
+	// ExtensionStorage is the global or workspace storage of one extension found by a scan
+	ExtensionStorage = scanner.ExtensionStorage
+	// TelemetryRisk is the telemetry risk level of extension storage
+	TelemetryRisk = scanner.TelemetryRisk
+	// RemovalPolicy controls which extension storage items CleanExtensions removes
+	RemovalPolicy = cleaner.RemovalPolicy
+	// ExtensionCleanResult is the result of cleaning the storage of one extension
+	ExtensionCleanResult = cleaner.ExtensionCleanResult
+	// BulkCleanResult is the result of cleaning several extensions, see CleanExtensions
+	BulkCleanResult = cleaner.BulkCleanResult
+	// ExtensionStorageCleanResult is the result of CleanAugmentExtension
+	ExtensionStorageCleanResult = cleaner.ExtensionStorageCleanResult
+	// PatternRuleMatch is a pattern rule that fires for a value, see TestPattern
+	PatternRuleMatch = scanner.PatternRuleMatch
+	// Logger receives leveled log messages; logger.FuncLogger adapts a plain function
+	Logger = logger.Leveled
+	// PathResolver supplies the home directory, OS and environment VS Code and
+	// browser paths are derived from, see Options.PathResolver
+	PathResolver = utils.PathResolver
+)
+
+// Progress describes a progress update emitted during an operation
+type Progress struct {
+	Operation string
+	Message   string
+}
+
+// ProgressFunc receives progress updates
+type ProgressFunc func(Progress)
+
+// Options controls the behaviour of the cleaning operations
+type Options struct {
+	// CreateBackups enables backups before destructive browser operations
+	CreateBackups bool
+	// AuditKey enables a signed telemetry audit file when non-empty
+	AuditKey []byte
+	// IncludePlaintext records raw IDs in the telemetry audit file
+	IncludePlaintext bool
+	// UpdateSyncMetadata also writes the new telemetry IDs to the VS Code Settings
+	// Sync metadata, so the next sync does not bring the old IDs back
+	UpdateSyncMetadata bool
+	// DatabaseBatchSize is the number of rows deleted per transaction; 0 uses the default
+	DatabaseBatchSize int
+	// DatabaseBatchDelay is the pause between delete batches; 0 does not pause
+	// and a negative value uses the default
+	DatabaseBatchDelay time.Duration
+	// DatabaseLockBackoff is the pause after "database is locked"; 0 uses the default
+	DatabaseLockBackoff time.Duration
+	// StorageLimits overrides per-extension storage size limits in MB
+	StorageLimits map[string]int64
+	// CheckPatternUpdates downloads newer telemetry patterns before scanning
+	CheckPatternUpdates bool
+	// PatternUpdateURL is the pattern manifest URL; empty uses the default
+	PatternUpdateURL string
+	// ScanTimeout limits how long Scan runs; on timeout partial results are returned
+	// with AnalysisIncomplete set. 0 means no limit.
+	ScanTimeout time.Duration
+	// DatabasePath is the state.vscdb database CleanDatabase and CountDatabaseRecords
+	// work on, e.g. of a portable VS Code; empty uses the auto-detected database
+	DatabasePath string
+	// PathResolver locates the VS Code files and browser profiles the operations
+	// work on, e.g. in a sandbox home directory; nil uses the current user's. A
+	// resolver from utils.NewEditorPathResolver limits them to one editor build.
+	PathResolver PathResolver
+	// DeepScan makes Scan also analyze the JavaScript bundles of installed extensions
+	// for the telemetry endpoints they send requests to; slower than a storage scan
+	DeepScan bool
+	// ScanSecretStore makes Scan also list Augment tokens in the OS secret store
+	// (libsecret, Keychain or Windows Credential Manager)
+	ScanSecretStore bool
+	// ExtensionID limits Scan to the storage of one extension, e.g. ms-python.python;
+	// empty scans every extension. Unknown IDs fail with an ExtensionNotFoundError.
+	ExtensionID string
+	// MinItemSizeBytes makes Scan skip JSON storage keys whose values are smaller, for
+	// a quick scan for large data blobs; 0 reports every key
+	MinItemSizeBytes int64
+	// GuessWorkspaceFolders makes Scan search common project directories for
+	// workspace settings when VS Code lists no recently opened folders
+	GuessWorkspaceFolders bool
+	// TopOffenders is the number of largest items and extensions in scan statistics; 0 uses the default
+	TopOffenders int
+	// BrowserProcessNames adds process names per browser (chrome, chrome-beta, chrome-dev,
+	// chrome-canary, edge, arc, webview2, firefox, safari) that are closed before cleaning, e.g.
+	// renamed builds
+	BrowserProcessNames map[string][]string
+	// BrowserExtensionIDs are Chromium extension IDs of Augment browser extensions whose
+	// storage and settings are removed, besides the built-in list
+	BrowserExtensionIDs []string
+	// CustomAugmentPatterns are SQL LIKE patterns of Augment data besides the built-in
+	// ones, e.g. of a differently branded deployment. They select database keys and
+	// browser cookies, and storage origins, file names and content matching them are
+	// cleaned as well.
+	CustomAugmentPatterns []string
+	// AggressiveBrowserCleaning also removes the data of unknown browser extensions
+	// whose manifest references Augment domains; otherwise they are only reported
+	AggressiveBrowserCleaning bool
+	// PurgeAllSessionStorage deletes every Chromium Session Storage file instead of
+	// the keys of Augment origins, losing the session storage of every site
+	PurgeAllSessionStorage bool
+	// RebootDeleteLocked registers browser files locked by other processes for deletion
+	// at the next reboot (Windows only, requires administrator rights)
+	RebootDeleteLocked bool
+	// DefaultBrowserProfilesOnly cleans only the default profile of each browser
+	// (for Firefox, the default of each installation) instead of every profile
+	DefaultBrowserProfilesOnly bool
+	// IncludeBrowserHistory also removes visited Augment URLs from the Chromium History
+	// and Top Sites databases and Firefox places.sqlite; off by default as it modifies
+	// the browsing history
+	IncludeBrowserHistory bool
+	// BrowserBackupDir is the directory browser profile backups are created in, as
+	// <dir>/<browser>/<timestamp>/<profile>; empty uses backups/browser-data
+	BrowserBackupDir string
+	// Browser limits browser operations to one browser by short name, e.g. chrome,
+	// chrome-canary or arc; empty works on every browser
+	Browser string
+	// BrowserProfiles pins the profiles browser operations work on, e.g. a snapshot from
+	// DetectBrowserProfiles shared by a dry-run count and the following clean; nil
+	// detects them on each call
+	BrowserProfiles []BrowserProfile
+	// Force cleans storage of workspaces that are currently open in VS Code
+	Force bool
+	// AllowMultipleIDEWindows runs ModifyTelemetryIDs, CleanDatabase and CleanWorkspace
+	// while several VS Code windows are open, which otherwise fails their pre-flight check
+	AllowMultipleIDEWindows bool
+	// Progress is called with progress updates; may be nil
+	Progress ProgressFunc
+	// Logger receives DEBUG traces of every file deleted, SQL statement run and
+	// path skipped during cleaning, with values sanitized; may be nil
+	Logger Logger
+	// HistoryPath is the JSONL file every cleaning operation is appended to; empty
+	// disables the history. DefaultHistoryPath returns the standard location.
+	HistoryPath string
+}
+
+// DefaultOptions returns the default options
+func DefaultOptions() Options {
+	return Options{
+		CreateBackups: true,
+	}
+}
+
+// pathResolver returns PathResolver, or the resolver of the current user when it is nil
+func (o Options) pathResolver() utils.PathResolver {
+	if o.PathResolver == nil {
+		return utils.DefaultPathResolver()
+	}
+	return o.PathResolver
+}
+
+// databasePath returns DatabasePath, or the state.vscdb database of the user
+// PathResolver describes when it is empty
+func (o Options) databasePath() (string, error) {
+	if o.DatabasePath != "" {
+		return o.DatabasePath, nil
+	}
+	dbPath, err := utils.NewVSCodePaths(o.pathResolver()).DBPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get database path: %w", err)
+	}
+	return dbPath, nil
+}
+
+// databasePaths returns DatabasePath, or the state.vscdb databases of the regular
+// install and the Snap and Flatpak packages of the user PathResolver describes
+func (o Options) databasePaths() ([]string, error) {
+	if o.DatabasePath != "" {
+		return []string{o.DatabasePath}, nil
+	}
+	dbPaths, err := utils.NewVSCodePaths(o.pathResolver()).DBPaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database path: %w", err)
+	}
+	return dbPaths, nil
+}
+
+// report sends a progress update if a callback is configured
+func (o Options) report(operation, format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(Progress{Operation: operation, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Scan analyzes extension storage without modifying anything
+func Scan(ctx context.Context, opts Options) (*Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("scan", "Analyzing extension storage")
+	analyzer := scanner.NewStorageAnalyzerWithResolver(opts.pathResolver())
+	analyzer.SetStorageLimitOverrides(opts.StorageLimits)
+	analyzer.SetTopOffenderCount(opts.TopOffenders)
+	analyzer.SetDeepScan(opts.DeepScan)
+	analyzer.SetScanSecretStore(opts.ScanSecretStore)
+	analyzer.SetGuessWorkspaceFolders(opts.GuessWorkspaceFolders)
+	analyzer.SetSizeThreshold(opts.MinItemSizeBytes)
+	analyzer.SetCustomAugmentPatterns(opts.CustomAugmentPatterns)
+
+	allowlist, err := loadScanAllowlist()
+	if err != nil {
+		return nil, err
+	}
+	analyzer.SetScanAllowlist(allowlist)
+
+	if opts.CheckPatternUpdates {
+		db, err := updatePatternDatabase(opts)
+		if err != nil {
+			return nil, err
+		}
+		analyzer.MergeTelemetryPatterns(db.Patterns)
+	}
+
+	var result *Report
+	if opts.ScanTimeout > 0 {
+		var complete bool
+		result, complete, err = analyzer.AnalyzeWithTimeout(opts.ScanTimeout, opts.ExtensionID)
+		if err == nil && !complete {
+			opts.report("scan", "Scan incomplete: %s", result.IncompleteReason)
+		}
+	} else {
+		result, err = analyzer.AnalyzeStorage(opts.ExtensionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage scan failed: %w", err)
+	}
+	opts.report("scan", "Analyzed %d extensions", result.StorageStatistics.ExtensionCount)
+
+	if err := trackStorageGrowth(opts, result); err != nil {
+		opts.report("scan", "Storage growth not tracked: %v", err)
+	}
+
+	return result, nil
+}
+
+// trackStorageGrowth compares a scan with the last full scan to fill in its
+// GrowthAnalysis. A complete full scan then becomes the last scan; scans of a
+// single extension or cut short by a timeout do not replace it.
+func trackStorageGrowth(opts Options, result *Report) error {
+	path, err := scanner.DefaultLastScanPath()
+	if err != nil {
+		return err
+	}
+	previous, err := scanner.LoadLastScan(path)
+	if err != nil {
+		return err
+	}
+
+	result.GrowthAnalysis = scanner.NewStorageGrowthAnalyzer().AnalyzeGrowth(previous, result)
+	if growth := result.GrowthAnalysis; growth != nil && len(growth.FastGrowingExtensions) > 0 {
+		opts.report("scan", "%d extensions store over %s more telemetry per day than at the last scan",
+			len(growth.FastGrowingExtensions), utils.FormatBytes(growth.ThresholdBytesPerDay))
+	}
+
+	if opts.ExtensionID != "" || result.AnalysisIncomplete {
+		return nil
+	}
+	return scanner.SaveLastScan(path, result)
+}
+
+// SuggestSettings scores the VS Code user settings for privacy and recommends changes
+func SuggestSettings(ctx context.Context, opts Options) (*SettingsSuggestion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("suggest-settings", "Analyzing VS Code settings")
+	suggestion, err := scanner.NewConfigAnalyzerWithResolver(opts.pathResolver()).SuggestOptimalSettings()
+	if err != nil {
+		return nil, fmt.Errorf("settings analysis failed: %w", err)
+	}
+	opts.report("suggest-settings", "Privacy score %d/%d with %d recommended changes",
+		suggestion.CurrentScore, suggestion.PotentialScore, len(suggestion.RecommendedChanges))
+
+	return suggestion, nil
+}
+
+// updatePatternDatabase checks for newer telemetry patterns and returns the local
+// pattern database. A failed update check falls back to the stored database.
+func updatePatternDatabase(opts Options) (*scanner.TelemetryPatternDatabase, error) {
+	dbPath, err := scanner.DefaultPatternDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := scanner.LoadTelemetryPatternDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	updateURL := opts.PatternUpdateURL
+	if updateURL == "" {
+		updateURL = scanner.DefaultPatternUpdateURL
+	}
+
+	opts.report("scan", "Checking for telemetry pattern updates")
+	updated, err := db.CheckForUpdates(&http.Client{Timeout: 15 * time.Second}, updateURL)
+	if err != nil {
+		opts.report("scan", "Pattern update check failed: %v", err)
+		return db, nil
+	}
+
+	if updated {
+		if err := db.Save(dbPath); err != nil {
+			return nil, err
+		}
+		opts.report("scan", "Telemetry patterns updated to version %s", db.Version)
+	} else {
+		opts.report("scan", "Telemetry patterns are up to date")
+	}
+
+	return db, nil
+}
+
+// ModifyTelemetryIDs rotates the VS Code machine and device IDs
+func ModifyTelemetryIDs(ctx context.Context, opts Options) (*TelemetryModifyResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkIDEProcesses(opts, "modify-telemetry"); err != nil {
+		return nil, err
+	}
+
+	if cleaner.DetectSettingsSync(opts.PathResolver).Detected() && !opts.UpdateSyncMetadata {
+		opts.report("modify-telemetry", "Settings Sync is enabled and may restore the old IDs; set UpdateSyncMetadata to update its metadata too")
+	}
+
+	opts.report("modify-telemetry", "Modifying telemetry IDs")
+	result, err := cleaner.ModifyTelemetryIDsWithOptions(cleaner.TelemetryModifyOptions{
+		AuditKey:         opts.AuditKey,
+		IncludePlaintext: opts.IncludePlaintext,
+		Logger:           opts.Logger,
+		Resolver:         opts.PathResolver,
+		UpdateSyncFiles:  opts.UpdateSyncMetadata,
+	})
+	if err != nil {
+		var backups []string
+		if result != nil {
+			backups = result.SyncBackupPaths
+		}
+		opts.recordHistory("modify-telemetry", "", backups, nil, err)
+		return result, fmt.Errorf("telemetry modification failed: %w", err)
+	}
+	opts.report("modify-telemetry", "Telemetry IDs modified")
+	summary := fmt.Sprintf("Rotated machine and device IDs (%s format)", result.IDFormat)
+	if len(result.SyncFilesUpdated) > 0 {
+		summary += fmt.Sprintf(", updated %d settings sync files", len(result.SyncFilesUpdated))
+	}
+	opts.recordHistory("modify-telemetry", summary,
+		append([]string{result.StorageBackupPath, result.MachineIDBackupPath, result.AuditFilePath}, result.SyncBackupPaths...), nil, nil)
+
+	return result, nil
+}
+
+// DetectSettingsSync reports whether VS Code Settings Sync is on or has synced this
+// installation, in which case it may restore telemetry IDs ModifyTelemetryIDs
+// rotated unless Options.UpdateSyncMetadata is set
+func DetectSettingsSync(ctx context.Context, opts Options) (*SettingsSyncState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	state := cleaner.DetectSettingsSync(opts.PathResolver)
+	return &state, nil
+}
+
+// checkIDEProcesses is the pre-flight check of the VS Code cleaning operations:
+// it fails with a *MultipleIDEWindowsError listing the open windows when several
+// VS Code windows could write to the files being cleaned, unless
+// opts.AllowMultipleIDEWindows is set. A failed process listing does not block.
+func checkIDEProcesses(opts Options, operation string) error {
+	processes, err := cleaner.NewSafetyValidator().CheckForRunningIDEProcesses()
+	var multiple *MultipleIDEWindowsError
+	switch {
+	case errors.As(err, &multiple) && !opts.AllowMultipleIDEWindows:
+		opts.recordHistory(operation, "", nil, nil, err)
+		return err
+	case errors.As(err, &multiple):
+		opts.report(operation, "Continuing with %d VS Code processes running", len(processes))
+	case err != nil:
+		opts.report(operation, "Could not check for running VS Code windows: %v", err)
+	case len(processes) > 0:
+		opts.report(operation, "VS Code is running (pid %d); changes may be overwritten until it is closed", processes[0].PID)
+	}
+	return nil
+}
+
+// CountDatabaseRecords returns the number of Augment records in the VS Code database
+func CountDatabaseRecords(ctx context.Context, opts Options) (int64, error) {
+	estimate, err := EstimateDatabaseClean(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	return estimate.Records, nil
+}
+
+// EstimateDatabaseClean returns the Augment records CleanDatabase would delete and
+// their size, without modifying the database
+func EstimateDatabaseClean(ctx context.Context, opts Options) (*DatabaseCleanEstimate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dbPaths, err := opts.databasePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := cleaner.EstimateAugmentDataFromPaths(dbPaths, opts.CustomAugmentPatterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count database records: %w", err)
+	}
+
+	return estimate, nil
+}
+
+// CleanDatabase deletes Augment records from the VS Code database
+func CleanDatabase(ctx context.Context, opts Options) (*DatabaseCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkIDEProcesses(opts, "clean-database"); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-database", "Cleaning VS Code database")
+	limiter := cleaner.NewRateLimitedCleaner()
+	limiter.BatchSize = opts.DatabaseBatchSize
+	limiter.BatchDelay = opts.DatabaseBatchDelay
+	limiter.LockBackoff = opts.DatabaseLockBackoff
+	limiter.Logger = opts.Logger
+	limiter.ExtraKeyPatterns = opts.CustomAugmentPatterns
+
+	dbPaths, err := opts.databasePaths()
+	if err != nil {
+		opts.recordHistory("clean-database", "", nil, nil, err)
+		return nil, fmt.Errorf("database cleaning failed: %w", err)
+	}
+	if opts.DatabasePath != "" {
+		opts.report("clean-database", "Using database %s", opts.DatabasePath)
+	}
+
+	result, err := cleaner.CleanAugmentDataFromPathsWithLimiter(dbPaths, true, limiter)
+	if err != nil {
+		opts.recordHistory("clean-database", "", result.DBBackupPaths, nil, err)
+		return result, fmt.Errorf("database cleaning failed: %w", err)
+	}
+	opts.report("clean-database", "Deleted %d records (%s) in %d batches (%d lock retries)",
+		result.DeletedRows, utils.FormatBytes(result.BytesFreed), result.BatchCount, result.LockRetries)
+	opts.recordHistory("clean-database", fmt.Sprintf("Deleted %d records", result.DeletedRows),
+		result.DBBackupPaths, nil, nil)
+
+	return result, nil
+}
+
+// CleanWorkspace backs up and clears VS Code workspace storage
+func CleanWorkspace(ctx context.Context, opts Options) (*WorkspaceCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkIDEProcesses(opts, "clean-workspace"); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-workspace", "Cleaning workspace storage")
+	result, err := cleaner.CleanWorkspaceStorageWithOptions(cleaner.WorkspaceCleanOptions{
+		Force:    opts.Force,
+		Logger:   opts.Logger,
+		Resolver: opts.PathResolver,
+	})
+	if err != nil {
+		var backups []string
+		if result != nil {
+			backups = result.BackupPaths
+		}
+		opts.recordHistory("clean-workspace", "", backups, nil, err)
+		return result, fmt.Errorf("workspace cleaning failed: %w", err)
+	}
+	for _, hash := range result.SkippedOpenWorkspaces {
+		opts.report("clean-workspace", "Skipped workspace %s because it is open in VS Code", hash)
+	}
+	opts.report("clean-workspace", "Deleted %d files (%s)", result.DeletedFilesCount, utils.FormatBytes(result.BytesFreed))
+	opts.recordHistory("clean-workspace",
+		fmt.Sprintf("Deleted %d files, kept %d open workspaces", result.DeletedFilesCount, len(result.SkippedOpenWorkspaces)),
+		result.BackupPaths, failedOperationErrors(result.FailedOperations), nil)
+
+	return result, nil
+}
+
+// EstimateWorkspaceClean returns the files CleanWorkspace would delete with the
+// same options and their size, without modifying anything
+func EstimateWorkspaceClean(ctx context.Context, opts Options) (*WorkspaceCleanEstimate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	estimate, err := cleaner.EstimateWorkspaceStorage(cleaner.WorkspaceCleanOptions{
+		Force:    opts.Force,
+		Logger:   opts.Logger,
+		Resolver: opts.PathResolver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate workspace cleaning: %w", err)
+	}
+
+	return estimate, nil
+}
+
+// FindOrphanedWorkspaces lists workspace storage directories whose folder no longer exists
+func FindOrphanedWorkspaces(ctx context.Context, opts Options) ([]OrphanedWorkspace, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	workspacePaths, err := utils.NewVSCodePaths(opts.pathResolver()).WorkspaceStoragePaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
+	}
+
+	opts.report("clean-workspace", "Looking for orphaned workspaces")
+	var orphans []OrphanedWorkspace
+	for _, workspacePath := range workspacePaths {
+		found, err := cleaner.FindOrphanedWorkspaces(workspacePath)
+		if err != nil {
+			return nil, fmt.Errorf("orphaned workspace lookup failed: %w", err)
+		}
+		orphans = append(orphans, found...)
+	}
+
+	return orphans, nil
+}
+
+// PruneOrphanedWorkspaces removes only the workspace storage of deleted folders
+func PruneOrphanedWorkspaces(ctx context.Context, opts Options) (*OrphanCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-workspace", "Pruning orphaned workspaces")
+	result, err := cleaner.PruneOrphanedWorkspaceStorageWithResolver(opts.pathResolver(), opts.CreateBackups)
+	if err != nil {
+		opts.recordHistory("prune-orphaned-workspaces", "", nil, nil, err)
+		return result, fmt.Errorf("orphaned workspace pruning failed: %w", err)
+	}
+	opts.report("clean-workspace", "Pruned %d workspaces", len(result.PrunedWorkspaces))
+	opts.recordHistory("prune-orphaned-workspaces",
+		fmt.Sprintf("Pruned %d workspaces, reclaimed %d bytes", len(result.PrunedWorkspaces), result.ReclaimedBytes),
+		nil, failedOperationErrors(result.FailedOperations), nil)
+
+	return result, nil
+}
+
+// ScanSecretStore lists Augment entries in the OS secret store without removing them
+func ScanSecretStore(ctx context.Context, opts Options) ([]SecretEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-secret-store", "Searching the OS secret store")
+	entries, err := scanner.NewSecretStoreScanner().ScanForAugmentSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("secret store scan failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CleanSecretStore removes Augment entries from the OS secret store
+func CleanSecretStore(ctx context.Context, opts Options) (*SecretStoreCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-secret-store", "Removing Augment secrets")
+	result, err := cleaner.CleanAugmentSecrets()
+	if err != nil {
+		opts.recordHistory("clean-secret-store", "", nil, nil, err)
+		return nil, fmt.Errorf("secret store cleaning failed: %w", err)
+	}
+	opts.report("clean-secret-store", "Removed %d secrets", len(result.RemovedEntries))
+	opts.recordHistory("clean-secret-store", fmt.Sprintf("Removed %d secrets", len(result.RemovedEntries)),
+		nil, failedOperationErrors(result.FailedOperations), nil)
+
+	return result, nil
+}
+
+// DetectBrowserProfiles returns every detected browser profile. Pass the result
+// in Options.BrowserProfiles so counting and cleaning see the same profiles.
+func DetectBrowserProfiles(ctx context.Context, opts Options) ([]BrowserProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := browserCleaner.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect browsers: %w", err)
+	}
+	opts.report("clean-browser", "Detected %d browser profiles", len(profiles))
+
+	return profiles, nil
+}
+
+// CountBrowserData returns the Augment items of every browser profile that would be cleaned
+func CountBrowserData(ctx context.Context, opts Options) ([]BrowserProfileCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := browserCleaner.GetBrowserDataCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count browser data: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CleanBrowsers removes Augment data from all detected browser profiles
+func CleanBrowsers(ctx context.Context, opts Options) ([]BrowserCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-browser", "Cleaning browser data")
+	results, err := browserCleaner.CleanBrowserData(opts.CreateBackups)
+	if err != nil {
+		opts.recordHistory("clean-browser", "", nil, nil, err)
+		return nil, fmt.Errorf("browser cleaning failed: %w", err)
+	}
+
+	var cookies, storage, cache int64
+	var backups, errs []string
+	for _, result := range results {
+		opts.report("clean-browser", "%s: %d cookies, %d storage items, %d cache items",
+			result.Profile.Name, result.CookiesDeleted, result.StorageDeleted, result.CacheDeleted)
+		if result.PermissionsDeleted > 0 {
+			opts.report("clean-browser", "%s: %d site permissions removed",
+				result.Profile.Name, result.PermissionsDeleted)
+		}
+		if result.HistoryDeleted > 0 {
+			opts.report("clean-browser", "%s: %d history entries removed",
+				result.Profile.Name, result.HistoryDeleted)
+		}
+		if result.PreferencesDeleted > 0 {
+			opts.report("clean-browser", "%s: %d site preferences removed",
+				result.Profile.Name, result.PreferencesDeleted)
+		}
+		if result.ExtensionDataDeleted > 0 {
+			opts.report("clean-browser", "%s: %d extension data items removed",
+				result.Profile.Name, result.ExtensionDataDeleted)
+		}
+		if len(result.SuspiciousExtensions) > 0 {
+			opts.report("clean-browser", "%s: %d extensions reference Augment domains: %s",
+				result.Profile.Name, len(result.SuspiciousExtensions), strings.Join(result.SuspiciousExtensions, ", "))
+		}
+		if len(result.PendingRebootDeletions) > 0 {
+			opts.report("clean-browser", "%s: %d locked files will be deleted at reboot",
+				result.Profile.Name, len(result.PendingRebootDeletions))
+		}
+		if len(result.PermissionDenied) > 0 {
+			opts.report("clean-browser", "%s: %d files skipped, permission denied",
+				result.Profile.Name, len(result.PermissionDenied))
+		}
+
+		cookies += result.CookiesDeleted
+		storage += result.StorageDeleted
+		cache += result.CacheDeleted
+		backups = append(backups, result.BackupPath)
+		for _, msg := range result.Errors {
+			errs = append(errs, fmt.Sprintf("%s: %s", result.Profile.Name, msg))
+		}
+	}
+	opts.recordHistory("clean-browser",
+		fmt.Sprintf("Cleaned %d profiles: %d cookies, %d storage items, %d cache items", len(results), cookies, storage, cache),
+		backups, errs, nil)
+
+	return results, nil
+}
+
+// ListBrowserProcesses returns every running process that browser cleaning would close
+func ListBrowserProcesses(ctx context.Context, opts Options) ([]BrowserProcess, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	processes, err := browserCleaner.ListBrowserProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list browser processes: %w", err)
+	}
+	opts.report("list-processes", "Matched %d browser processes", len(processes))
+
+	return processes, nil
+}
+
+// newBrowserCleaner creates a browser cleaner with the configured extra process names
+func newBrowserCleaner(opts Options) (*browser.BrowserCleaner, error) {
+	browserCleaner, err := browser.NewBrowserCleanerWithResolver(opts.pathResolver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser cleaner: %w", err)
+	}
+
+	if len(opts.BrowserProcessNames) > 0 {
+		names := make(map[browser.BrowserType][]string)
+		for name, processNames := range opts.BrowserProcessNames {
+			browserType, err := browser.ParseBrowserType(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid browser process names: %w", err)
+			}
+			names[browserType] = append(names[browserType], processNames...)
+		}
+		browserCleaner.SetExtraProcessNames(names)
+	}
+	if opts.Browser != "" {
+		browserType, err := browser.ParseBrowserType(opts.Browser)
+		if err != nil {
+			return nil, fmt.Errorf("invalid browser: %w", err)
+		}
+		browserCleaner.SetBrowsers(browserType)
+	}
+	browserCleaner.SetScheduleDeleteOnReboot(opts.RebootDeleteLocked)
+	browserCleaner.SetDefaultProfilesOnly(opts.DefaultBrowserProfilesOnly)
+	browserCleaner.SetIncludeHistory(opts.IncludeBrowserHistory)
+	browserCleaner.SetAugmentExtensionIDs(opts.BrowserExtensionIDs...)
+	browserCleaner.SetCustomAugmentPatterns(opts.CustomAugmentPatterns...)
+	browserCleaner.SetAggressive(opts.AggressiveBrowserCleaning)
+	browserCleaner.SetPurgeAllSessionStorage(opts.PurgeAllSessionStorage)
+	if opts.BrowserBackupDir != "" {
+		browserCleaner.SetBackupDir(opts.BrowserBackupDir)
+	}
+	if opts.BrowserProfiles != nil {
+		browserCleaner.SetProfiles(opts.BrowserProfiles)
+	}
+	browserCleaner.SetLogger(opts.Logger)
+
+	return browserCleaner, nil
+}
@@ -0,0 +1,161 @@
+package augmentcleaner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestOperationsRespectCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	opts := DefaultOptions()
+	opts.Progress = func(Progress) { called = true }
+
+	if _, err := Scan(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan: expected context.Canceled, got %v", err)
+	}
+	if _, err := CleanDatabase(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("CleanDatabase: expected context.Canceled, got %v", err)
+	}
+	if _, err := CleanBrowsers(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("CleanBrowsers: expected context.Canceled, got %v", err)
+	}
+	if _, err := ListBrowserProcesses(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListBrowserProcesses: expected context.Canceled, got %v", err)
+	}
+	if _, err := ModifyTelemetryIDs(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("ModifyTelemetryIDs: expected context.Canceled, got %v", err)
+	}
+	if _, err := QuickClean(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("QuickClean: expected context.Canceled, got %v", err)
+	}
+	if _, err := RunAll(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunAll: expected context.Canceled, got %v", err)
+	}
+	if _, err := GetBackupStats(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetBackupStats: expected context.Canceled, got %v", err)
+	}
+	if _, err := ListBackupsPage(ctx, opts, 0, 5, "date"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListBackupsPage: expected context.Canceled, got %v", err)
+	}
+	if _, err := DetectSettingsSync(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("DetectSettingsSync: expected context.Canceled, got %v", err)
+	}
+	if _, err := EstimateDatabaseClean(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("EstimateDatabaseClean: expected context.Canceled, got %v", err)
+	}
+	if _, err := EstimateWorkspaceClean(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("EstimateWorkspaceClean: expected context.Canceled, got %v", err)
+	}
+	if _, err := EstimateRunAll(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("EstimateRunAll: expected context.Canceled, got %v", err)
+	}
+	if _, err := UpdateScanAllowlist(ctx, opts, []string{"golang.go"}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("UpdateScanAllowlist: expected context.Canceled, got %v", err)
+	}
+	if _, err := RestoreVSCodeSettings(ctx, opts, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("RestoreVSCodeSettings: expected context.Canceled, got %v", err)
+	}
+	if _, err := CleanVSCodeSettings(ctx, opts, SettingsCleanRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CleanVSCodeSettings: expected context.Canceled, got %v", err)
+	}
+	if _, err := CleanAugmentExtension(ctx, opts, AugmentStorageCleanRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CleanAugmentExtension: expected context.Canceled, got %v", err)
+	}
+	if _, err := TestPattern(ctx, opts, PatternTestRequest{Value: "augment"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("TestPattern: expected context.Canceled, got %v", err)
+	}
+
+	if called {
+		t.Error("Expected no progress callbacks for cancelled operations")
+	}
+}
+
+// createAugmentDatabase creates a state.vscdb at dbPath with one Augment row and
+// one unrelated row
+func createAugmentDatabase(t *testing.T, dbPath string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(dbPath), err)
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT, value BLOB)"); err != nil {
+		t.Fatalf("Failed to create ItemTable: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO ItemTable VALUES ('augment.vscode-augment.state', 'v'), ('workbench.state', 'v')"); err != nil {
+		t.Fatalf("Failed to insert rows: %v", err)
+	}
+}
+
+func TestCleanDatabaseIncludesFlatpakInstall(t *testing.T) {
+	home := t.TempDir()
+	opts := DefaultOptions()
+	opts.PathResolver = utils.FakePathResolver{Home: home, OS: "linux"}
+	opts.AllowMultipleIDEWindows = true
+
+	dbPaths := []string{
+		filepath.Join(home, ".config", "Code", "User", "globalStorage", "state.vscdb"),
+		filepath.Join(home, ".var", "app", "com.visualstudio.code", "config", "Code", "User", "globalStorage", "state.vscdb"),
+	}
+	for _, dbPath := range dbPaths {
+		createAugmentDatabase(t, dbPath)
+	}
+
+	estimate, err := EstimateDatabaseClean(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("EstimateDatabaseClean failed: %v", err)
+	}
+	if estimate.Records != 2 {
+		t.Errorf("Expected a record in each database, got %d", estimate.Records)
+	}
+
+	result, err := CleanDatabase(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CleanDatabase failed: %v", err)
+	}
+	if result.DeletedRows != 2 || len(result.DBBackupPaths) != 2 {
+		t.Errorf("Expected both databases to be backed up and cleaned, got %+v", result)
+	}
+}
+
+func TestCleanDatabaseUsesEditorOfPathResolver(t *testing.T) {
+	home := t.TempDir()
+	vscodeDB := filepath.Join(home, ".config", "Code", "User", "globalStorage", "state.vscdb")
+	cursorDB := filepath.Join(home, ".config", "Cursor", "User", "globalStorage", "state.vscdb")
+	createAugmentDatabase(t, vscodeDB)
+	createAugmentDatabase(t, cursorDB)
+
+	opts := DefaultOptions()
+	opts.PathResolver = utils.NewEditorPathResolver(utils.FakePathResolver{Home: home, OS: "linux"}, utils.EditorCursor)
+	opts.AllowMultipleIDEWindows = true
+
+	result, err := CleanDatabase(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CleanDatabase failed: %v", err)
+	}
+	if result.DeletedRows != 1 {
+		t.Errorf("Expected only the Cursor database to be cleaned, got %+v", result)
+	}
+
+	// Stable VS Code was not selected, so its Augment row survives
+	opts.PathResolver = utils.FakePathResolver{Home: home, OS: "linux"}
+	estimate, err := EstimateDatabaseClean(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("EstimateDatabaseClean failed: %v", err)
+	}
+	if estimate.Records != 1 {
+		t.Errorf("Expected the VS Code Augment row to survive, got %d records", estimate.Records)
+	}
+}
@@ -0,0 +1,94 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+)
+
+// ListBackups returns the extension backups, newest first
+func ListBackups(ctx context.Context, opts Options) ([]Backup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	backups, err := cleaner.NewBackupManager().ListBackups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreationTime.After(backups[j].CreationTime)
+	})
+	return backups, nil
+}
+
+// ListBackupsPage returns up to limit extension backups, skipping the first
+// offset, sorted by sortBy: "date" (newest first, the default) or "size"
+// (largest first). A limit of zero or less returns all of them. Only the
+// metadata of the backups on the page is loaded.
+func ListBackupsPage(ctx context.Context, opts Options, offset, limit int, sortBy string) (*BackupPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	page, err := cleaner.NewBackupManager().ListBackupsPage(offset, limit, cleaner.BackupSort(sortBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return page, nil
+}
+
+// GetBackupStats summarizes how much disk space the extension backups use and
+// which extensions they cover
+func GetBackupStats(ctx context.Context, opts Options) (*BackupStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("backup-stats", "Summarizing backups")
+	stats, err := cleaner.NewBackupManager().GetBackupStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize backups: %w", err)
+	}
+
+	return stats, nil
+}
+
+// FindSettingsBackup returns the VS Code settings backup RestoreVSCodeSettings
+// would restore: the one with backupID, or the most recent when it is empty
+func FindSettingsBackup(ctx context.Context, opts Options, backupID string) (*Backup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return cleaner.NewBackupManager().FindSettingsBackup(backupID)
+}
+
+// RestoreVSCodeSettings reverts the VS Code settings.json to a settings backup,
+// the one with backupID or the most recent when it is empty. With
+// opts.CreateBackups the settings being replaced are backed up first.
+func RestoreVSCodeSettings(ctx context.Context, opts Options, backupID string) (*RestoreVSCodeSettingsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("restore-vscode-settings", "Restoring VS Code settings")
+	result, err := cleaner.NewBackupManager().RestoreVSCodeSettings(backupID, opts.CreateBackups)
+	if err != nil {
+		opts.recordHistory("restore-vscode-settings", "", nil, nil, err)
+		return nil, fmt.Errorf("settings restore failed: %w", err)
+	}
+	opts.report("restore-vscode-settings", "Restored %d settings from backup %s", len(result.SettingsRestored), result.BackupUsed.BackupID)
+	opts.recordHistory("restore-vscode-settings",
+		fmt.Sprintf("Restored %d settings from backup %s", len(result.SettingsRestored), result.BackupUsed.BackupID),
+		[]string{result.PreRestoreBackup}, nil, nil)
+
+	return result, nil
+}
+
+// ExtensionBackupDir returns the directory extension backups are created in
+func ExtensionBackupDir() string {
+	return cleaner.NewBackupManager().GetBackupDirectory()
+}
@@ -0,0 +1,61 @@
+package augmentcleaner
+
+import (
+	"path/filepath"
+	"sort"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// CloudSyncMatch is a directory the cleaner modifies or writes backups to that is
+// inside a folder kept in sync by a cloud storage client
+type CloudSyncMatch struct {
+	Path     string
+	Backup   bool   // Path is a backup destination rather than VS Code data
+	Provider string // "OneDrive", "Dropbox", "Google Drive" or "iCloud Drive"
+	Root     string // Root of the sync folder
+}
+
+// FindCloudSyncPaths returns the VS Code user data directories of every installed
+// editor variant, the database in opts.DatabasePath and the backupDirs that are
+// inside a OneDrive, Dropbox, Google Drive or iCloud Drive folder. Symlinks are
+// followed, so a User directory linked into a sync folder is found.
+func FindCloudSyncPaths(opts Options, backupDirs []string) []CloudSyncMatch {
+	variants := utils.GetAllVSCodeVariantPathsWithResolver(opts.pathResolver())
+	dataPaths := make([]string, 0, len(variants)+1)
+	for _, dataDir := range variants {
+		dataPaths = append(dataPaths, filepath.Join(dataDir, "User"))
+	}
+	sort.Strings(dataPaths)
+	if opts.DatabasePath != "" {
+		dataPaths = append(dataPaths, opts.DatabasePath)
+	}
+
+	var matches []CloudSyncMatch
+	seen := make(map[string]bool)
+	check := func(path string, backup bool) {
+		if path == "" {
+			return
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+
+		if folder, ok := utils.FindCloudSyncFolder(path); ok {
+			opts.report("cloud-sync", "%s is inside %s folder %s", path, folder.Provider, folder.Root)
+			matches = append(matches, CloudSyncMatch{Path: path, Backup: backup, Provider: folder.Provider, Root: folder.Root})
+		}
+	}
+
+	for _, path := range dataPaths {
+		check(path, false)
+	}
+	for _, dir := range backupDirs {
+		check(dir, true)
+	}
+	return matches
+}
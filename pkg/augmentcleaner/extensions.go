@@ -0,0 +1,170 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+)
+
+// extensionCleanWorkers is the number of extensions CleanExtensions cleans at once
+const extensionCleanWorkers = 4
+
+// DefaultRemovalPolicy returns the removal policy CleanExtensions uses by default:
+// medium risk and above, preserving data modified in the last 24 hours
+func DefaultRemovalPolicy() RemovalPolicy {
+	return cleaner.GetDefaultRemovalPolicy()
+}
+
+// QuickRemovalPolicy returns the removal policy of a quick clean: critical risk
+// only, without preserving recently modified data, always backed up
+func QuickRemovalPolicy() RemovalPolicy {
+	return cleaner.GetQuickRemovalPolicy()
+}
+
+// CleanExtensions scans extension storage and cleans the global and workspace
+// storage of the given extension IDs. Without IDs it cleans every extension whose
+// storage risk is at least policy.MinRiskLevel. IDs without any storage fail
+// before anything is cleaned; an extension that fails to clean does not stop the
+// others and is listed in BulkCleanResult.FailedExtensions.
+func CleanExtensions(ctx context.Context, opts Options, extensionIDs []string, policy RemovalPolicy) (*BulkCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.ExtensionID = ""
+	report, err := Scan(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	storages, err := selectExtensionStorages(report, extensionIDs, policy.MinRiskLevel)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.report("clean-extensions", "Cleaning %d extension storages", len(storages))
+	result, err := cleaner.CleanExtensionListWithResolver(storages, policy, extensionCleanWorkers, opts.pathResolver())
+	if err != nil {
+		if !policy.DryRun {
+			opts.recordHistory("clean-extensions", "", nil, nil, err)
+		}
+		return nil, fmt.Errorf("extension cleaning failed: %w", err)
+	}
+
+	summary := fmt.Sprintf("Removed %d items (%d bytes) from %d extensions",
+		result.TotalItemsRemoved, result.TotalSizeRemoved, len(result.PerExtension))
+	opts.report("clean-extensions", "%s", summary)
+	if !policy.DryRun {
+		var backups, errs []string
+		for id, extension := range result.PerExtension {
+			backups = append(backups, extension.BackupPaths...)
+			for _, message := range extension.Errors {
+				errs = append(errs, fmt.Sprintf("%s: %s", id, message))
+			}
+		}
+		opts.recordHistory("clean-extensions", summary, backups, errs, nil)
+	}
+
+	return result, nil
+}
+
+// selectExtensionStorages returns the global and workspace storages of
+// extensionIDs, or of every extension at or above minRisk when none are given
+func selectExtensionStorages(report *Report, extensionIDs []string, minRisk TelemetryRisk) ([]ExtensionStorage, error) {
+	all := append([]ExtensionStorage{}, report.GlobalStorageAnalysis.ExtensionStorages...)
+	for _, workspace := range report.WorkspaceStorageAnalysis.WorkspaceStorages {
+		all = append(all, workspace.ExtensionStorages...)
+	}
+
+	var selected []ExtensionStorage
+	if len(extensionIDs) == 0 {
+		for _, storage := range all {
+			if storage.Risk >= minRisk {
+				selected = append(selected, storage)
+			}
+		}
+		return selected, nil
+	}
+
+	var missing []string
+	for _, id := range extensionIDs {
+		found := false
+		for _, storage := range all {
+			if strings.EqualFold(storage.ExtensionID, id) {
+				selected = append(selected, storage)
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no storage found for extension(s): %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// AugmentStorageCleanRequest selects what CleanAugmentExtension removes
+type AugmentStorageCleanRequest struct {
+	// ExtensionID is the extension whose global storage is cleaned; empty cleans the
+	// Augment extension
+	ExtensionID string
+	// PreserveKeys are top-level storage keys that are always kept; nil keeps
+	// userPreferences and licenseKey
+	PreserveKeys []string
+	// DryRun lists the keys and files that would be removed without changing them
+	DryRun bool
+}
+
+// CleanAugmentExtension removes telemetry keys from the JSON files in the global
+// storage of the Augment extension, deleting files that hold nothing else, without
+// scanning the storage of other extensions first. With opts.CreateBackups the
+// storage is backed up as an extension backup first.
+func CleanAugmentExtension(ctx context.Context, opts Options, req AugmentStorageCleanRequest) (*ExtensionStorageCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		if err := checkIDEProcesses(opts, "clean-augment-extension"); err != nil {
+			return nil, err
+		}
+	}
+
+	extensionID := req.ExtensionID
+	if extensionID == "" {
+		extensionID = cleaner.AugmentExtensionID
+	}
+	opts.report("clean-augment-extension", "Cleaning global storage of %s", extensionID)
+	result, err := cleaner.NewBackupManager().CleanExtensionGlobalStorage(cleaner.ExtensionStorageCleanOptions{
+		ExtensionID:  extensionID,
+		PreserveKeys: req.PreserveKeys,
+		Resolver:     opts.PathResolver,
+		Backup:       opts.CreateBackups,
+		DryRun:       req.DryRun,
+	})
+	if err != nil {
+		if !req.DryRun {
+			opts.recordHistory("clean-augment-extension", "", nil, nil, err)
+		}
+		return nil, fmt.Errorf("extension storage cleaning failed: %w", err)
+	}
+
+	summary := fmt.Sprintf("Removed %d telemetry keys and deleted %d files", len(result.KeysRemoved), len(result.FilesDeleted))
+	opts.report("clean-augment-extension", "%s", summary)
+	if !req.DryRun {
+		var backups []string
+		if result.BackupPath != "" {
+			backups = append(backups, result.BackupPath)
+		}
+		opts.recordHistory("clean-augment-extension", summary, backups, result.Errors, nil)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/history"
+)
+
+// HistoryRecord is a single operation in the persistent operation history
+type HistoryRecord = history.Record
+
+// DefaultHistoryPath returns the history file in the application config directory
+func DefaultHistoryPath() (string, error) {
+	return history.DefaultPath()
+}
+
+// History returns up to last of the most recent history records, newest first.
+// last <= 0 returns every record.
+func History(ctx context.Context, opts Options, last int) ([]HistoryRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.HistoryPath == "" {
+		return nil, fmt.Errorf("no history file configured")
+	}
+
+	records, err := history.NewStore(opts.HistoryPath).Last(last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return records, nil
+}
+
+// LastSuccessfulRun returns the most recent successful history record of an
+// operation (e.g. "modify-telemetry"), or nil if it never succeeded
+func LastSuccessfulRun(ctx context.Context, opts Options, operation string) (*HistoryRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.HistoryPath == "" {
+		return nil, nil
+	}
+
+	record, err := history.NewStore(opts.HistoryPath).LastSuccess(operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return record, nil
+}
+
+// recordHistory appends an operation to the history file if one is configured.
+// A failed write is reported as progress and never fails the operation itself.
+func (o Options) recordHistory(operation, summary string, backups, errs []string, opErr error) {
+	if o.HistoryPath == "" {
+		return
+	}
+
+	record := history.Record{
+		Operation: operation,
+		Success:   opErr == nil,
+		Options:   o.historyOptions(),
+		Summary:   summary,
+		Errors:    errs,
+	}
+	for _, backup := range backups {
+		if backup != "" {
+			record.BackupIDs = append(record.BackupIDs, backup)
+		}
+	}
+	if opErr != nil {
+		record.Summary = "Failed"
+		record.Errors = append(record.Errors, opErr.Error())
+	}
+
+	if err := history.NewStore(o.HistoryPath).Append(record); err != nil {
+		o.report("history", "Failed to record %s in history: %v", operation, err)
+	}
+}
+
+// historyOptions returns the options worth recording with each history entry
+func (o Options) historyOptions() map[string]interface{} {
+	options := map[string]interface{}{
+		"create_backups": o.CreateBackups,
+	}
+	if len(o.AuditKey) > 0 {
+		options["audit"] = true
+	}
+	if o.Force {
+		options["force"] = true
+	}
+	if o.RebootDeleteLocked {
+		options["reboot_delete_locked"] = true
+	}
+	if o.UpdateSyncMetadata {
+		options["update_sync_metadata"] = true
+	}
+	if o.DefaultBrowserProfilesOnly {
+		options["default_browser_profiles_only"] = true
+	}
+	if o.IncludeBrowserHistory {
+		options["include_browser_history"] = true
+	}
+	if o.PurgeAllSessionStorage {
+		options["purge_all_session_storage"] = true
+	}
+	if o.DatabasePath != "" {
+		options["database_path"] = o.DatabasePath
+	}
+	if o.DatabaseBatchSize > 0 {
+		options["database_batch_size"] = o.DatabaseBatchSize
+	}
+	if len(o.CustomAugmentPatterns) > 0 {
+		options["custom_augment_patterns"] = o.CustomAugmentPatterns
+	}
+	return options
+}
+
+// failedOperationErrors formats failed operations as history error messages
+func failedOperationErrors(failed []cleaner.FailedOperation) []string {
+	var errs []string
+	for _, op := range failed {
+		errs = append(errs, fmt.Sprintf("%s: %s", op.Path, op.Error))
+	}
+	return errs
+}
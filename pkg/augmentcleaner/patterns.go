@@ -0,0 +1,133 @@
+package augmentcleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"augment-telemetry-cleaner/internal/browser"
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+// maxPatternTestFileBytes is how much of a file TestPattern reads
+const maxPatternTestFileBytes = 1 << 20
+
+// PatternTestRequest is the value or file TestPattern runs through the patterns
+type PatternTestRequest struct {
+	Value string
+	// File is tested by its path and by up to the first MiB of its content
+	File string
+}
+
+// PatternTestResult lists the pattern rules that fire for a tested value or file
+type PatternTestResult struct {
+	Value       string             `json:"value,omitempty"`
+	File        string             `json:"file,omitempty"`
+	PatternFile string             `json:"pattern_file,omitempty"` // Local pattern database merged into the builtin patterns
+	Matches     []PatternRuleMatch `json:"matches"`
+}
+
+// TestPattern runs a value or the path and content of a file through the
+// telemetry patterns of the storage analyzer, including the local pattern
+// database, the advanced pattern matcher and the Augment patterns of browser
+// cleaning, and returns every rule that fires with its risk and source. Nothing
+// is modified.
+func TestPattern(ctx context.Context, opts Options, req PatternTestRequest) (*PatternTestResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Value == "" && req.File == "" {
+		return nil, errors.New("a value or a file to test is required")
+	}
+
+	result := &PatternTestResult{Value: req.Value, File: req.File, Matches: []PatternRuleMatch{}}
+
+	analyzer := scanner.NewStorageAnalyzerWithResolver(opts.pathResolver())
+	analyzer.SetCustomAugmentPatterns(opts.CustomAugmentPatterns)
+	dbPath, err := scanner.DefaultPatternDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := scanner.LoadTelemetryPatternDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(db.Patterns) > 0 {
+		analyzer.MergeTelemetryPatternsFrom(dbPath, db.Patterns)
+		result.PatternFile = dbPath
+	}
+
+	if req.Value != "" {
+		opts.report("test-pattern", "Testing %q", req.Value)
+		result.Matches = append(result.Matches, testPatternValue(analyzer, opts.CustomAugmentPatterns, req.Value, "")...)
+	}
+
+	if req.File != "" {
+		opts.report("test-pattern", "Testing %s", req.File)
+		content, err := readPatternTestFile(req.File)
+		if err != nil {
+			return nil, err
+		}
+		matches := testPatternValue(analyzer, opts.CustomAugmentPatterns, string(content), "")
+
+		// The path can fire rules the content does not, such as a storage folder name
+		seen := make(map[string]bool)
+		for _, match := range matches {
+			seen[match.Engine+"\x00"+match.Rule] = true
+		}
+		for _, match := range testPatternValue(analyzer, opts.CustomAugmentPatterns, req.File, "file path: ") {
+			if !seen[match.Engine+"\x00"+match.Rule] {
+				matches = append(matches, match)
+			}
+		}
+		result.Matches = append(result.Matches, matches...)
+	}
+
+	scanner.SortPatternRuleMatches(result.Matches)
+	opts.report("test-pattern", "%d pattern rules matched", len(result.Matches))
+
+	return result, nil
+}
+
+// testPatternValue returns the analyzer and browser rules that fire for value,
+// the custom Augment patterns included, with detailPrefix in front of each detail
+func testPatternValue(analyzer *scanner.StorageAnalyzer, customPatterns []string, value, detailPrefix string) []PatternRuleMatch {
+	matches := analyzer.TestPatterns(value)
+	for _, rule := range browser.MatchAugmentPatterns(value, customPatterns...) {
+		source := scanner.PatternSourceBuiltin
+		if strings.HasPrefix(rule, "custom: ") {
+			source = scanner.PatternSourceConfig
+		}
+		matches = append(matches, PatternRuleMatch{
+			Engine: scanner.PatternEngineBrowser,
+			Rule:   rule,
+			Risk:   scanner.TelemetryRiskHigh,
+			Source: source,
+			Detail: "removed by clean-browser",
+		})
+	}
+	if detailPrefix != "" {
+		for i := range matches {
+			matches[i].Detail = detailPrefix + matches[i].Detail
+		}
+	}
+	return matches
+}
+
+// readPatternTestFile reads up to maxPatternTestFileBytes of path
+func readPatternTestFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxPatternTestFileBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,95 @@
+package augmentcleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+func TestTestPatternReportsRuleSources(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("HOME", configDir)
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	dbPath, err := scanner.DefaultPatternDatabasePath()
+	if err != nil {
+		t.Fatalf("DefaultPatternDatabasePath failed: %v", err)
+	}
+	db := &scanner.TelemetryPatternDatabase{Patterns: map[string]scanner.TelemetryRisk{"acme.tracker": scanner.TelemetryRiskMedium}}
+	if err := db.Save(dbPath); err != nil {
+		t.Fatalf("Failed to save pattern database: %v", err)
+	}
+
+	result, err := TestPattern(context.Background(), DefaultOptions(), PatternTestRequest{Value: "augment.acme.tracker"})
+	if err != nil {
+		t.Fatalf("TestPattern failed: %v", err)
+	}
+	if result.PatternFile != dbPath {
+		t.Errorf("Expected pattern file %s, got %q", dbPath, result.PatternFile)
+	}
+
+	sources := make(map[string]string)
+	for _, match := range result.Matches {
+		sources[match.Engine+" "+match.Rule] = match.Source
+	}
+	if source := sources[scanner.PatternEngineTelemetry+" acme.tracker"]; source != dbPath {
+		t.Errorf("Expected the custom pattern from %s, got %q in %+v", dbPath, source, result.Matches)
+	}
+	if source := sources[scanner.PatternEngineBrowser+" file content: augment"]; source != scanner.PatternSourceBuiltin {
+		t.Errorf("Expected the builtin browser pattern, got %q in %+v", source, result.Matches)
+	}
+	for i := 1; i < len(result.Matches); i++ {
+		if result.Matches[i].Risk > result.Matches[i-1].Risk {
+			t.Errorf("Expected matches sorted by risk, got %+v", result.Matches)
+			break
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "augment-state.json")
+	if err := os.WriteFile(file, []byte(`{"note": "nothing here"}`), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	result, err = TestPattern(context.Background(), DefaultOptions(), PatternTestRequest{File: file})
+	if err != nil {
+		t.Fatalf("TestPattern failed: %v", err)
+	}
+	if len(result.Matches) == 0 || !strings.HasPrefix(result.Matches[0].Detail, "file path: ") {
+		t.Errorf("Expected the file path to match, got %+v", result.Matches)
+	}
+
+	if _, err := TestPattern(context.Background(), DefaultOptions(), PatternTestRequest{}); err == nil {
+		t.Error("Expected an error without a value or file")
+	}
+}
+
+func TestTestPatternCustomAugmentPatterns(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("HOME", configDir)
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	opts := DefaultOptions()
+	opts.CustomAugmentPatterns = []string{"%acme-ai%"}
+	result, err := TestPattern(context.Background(), opts, PatternTestRequest{Value: "https://app.acme-ai.dev"})
+	if err != nil {
+		t.Fatalf("TestPattern failed: %v", err)
+	}
+
+	sources := make(map[string]string)
+	for _, match := range result.Matches {
+		sources[match.Engine+" "+match.Rule] = match.Source
+	}
+	if source := sources[scanner.PatternEngineBrowser+" custom: %acme-ai%"]; source != scanner.PatternSourceConfig {
+		t.Errorf("Expected the custom browser pattern from the config, got %q in %+v", source, result.Matches)
+	}
+	var code bool
+	for _, match := range result.Matches {
+		code = code || (match.Engine == scanner.PatternEngineCode && match.Source == scanner.PatternSourceConfig)
+	}
+	if !code {
+		t.Errorf("Expected the advanced matcher to report the custom pattern, got %+v", result.Matches)
+	}
+}
@@ -0,0 +1,38 @@
+package augmentcleaner
+
+import (
+	"runtime"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+)
+
+// IsPermissionError reports whether err was caused by missing access rights, a
+// read-only file system or a read-only database. Retrying does not help; the user
+// has to act on PermissionRemediationHints first.
+func IsPermissionError(err error) bool {
+	return cleaner.IsPermissionError(err)
+}
+
+// PermissionDeniedPath returns the path a permission error occurred on, or the
+// error message when err carries no path
+func PermissionDeniedPath(err error) string {
+	return cleaner.PermissionDeniedPath(err)
+}
+
+// PermissionRemediationHints returns what the user can do about permission errors
+// on the current platform
+func PermissionRemediationHints() []string {
+	hints := []string{
+		"Run the tool as the user who owns the VS Code and browser profiles, not as another account",
+		"Close VS Code, the browsers and apps that sync these folders (Settings Sync, OneDrive, Dropbox, iCloud Drive), then retry",
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		hints = append(hints, "Grant your terminal (or this app) Full Disk Access in System Settings > Privacy & Security > Full Disk Access; Safari data needs it")
+	case "windows":
+		hints = append(hints, "Clear the read-only attribute of the listed files, or ask your administrator for write access to them")
+	default:
+		hints = append(hints, "Make sure the listed files are writable by you (ls -l), and that their file system is not mounted read-only")
+	}
+	return hints
+}
@@ -0,0 +1,65 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// QuickCleanTarget is how long a quick clean should take on a typical machine
+const QuickCleanTarget = 30 * time.Second
+
+// QuickCleanResult is the result of QuickClean. A step that failed has no result
+// and its error is listed in Errors.
+type QuickCleanResult struct {
+	Database  *DatabaseCleanResult   `json:"database,omitempty"`
+	Telemetry *TelemetryModifyResult `json:"telemetry,omitempty"`
+	Duration  time.Duration          `json:"duration"`
+	Errors    []string               `json:"errors,omitempty"`
+}
+
+// QuickClean runs only the two fastest operations, CleanDatabase and then
+// ModifyTelemetryIDs, for quick iteration; workspace storage and browsers are
+// skipped. Backups are always created. A failing step does not stop the other;
+// the returned error lists every failure alongside the partial result. Besides
+// the record of each step, the run as a whole is recorded in the history.
+func QuickClean(ctx context.Context, opts Options) (*QuickCleanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.CreateBackups = true
+	elapsed := utils.StartTimer()
+	result := &QuickCleanResult{}
+
+	opts.report("quick-clean", "Running quick clean (database and telemetry IDs)")
+	database, err := CleanDatabase(ctx, opts)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.Database = database
+
+	telemetry, err := ModifyTelemetryIDs(ctx, opts)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.Telemetry = telemetry
+	}
+
+	result.Duration = elapsed()
+	opts.report("quick-clean", "Quick clean finished in %s", result.Duration.Round(time.Millisecond))
+	if result.Duration > QuickCleanTarget {
+		opts.report("quick-clean", "Quick clean took longer than %s; the VS Code database may be unusually large", QuickCleanTarget)
+	}
+
+	if len(result.Errors) > 0 {
+		err := fmt.Errorf("quick clean failed: %s", strings.Join(result.Errors, "; "))
+		opts.recordHistory("quick-clean", "", nil, nil, err)
+		return result, err
+	}
+	opts.recordHistory("quick-clean", fmt.Sprintf("Deleted %d records and rotated telemetry IDs", result.Database.DeletedRows), nil, nil, nil)
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package augmentcleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestQuickCleanSkipsWorkspaceAndBrowsers(t *testing.T) {
+	home := t.TempDir()
+	utils.SetHomeDirOverride(home)
+	defer utils.SetHomeDirOverride("")
+
+	sandbox, err := newSelfTestSandbox(home, utils.DefaultPathResolver())
+	if err != nil {
+		t.Fatalf("Failed to build sandbox: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.CreateBackups = false // Quick clean backs up regardless
+	opts.HistoryPath = filepath.Join(home, "history.jsonl")
+	result, err := QuickClean(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("QuickClean failed: %v", err)
+	}
+
+	if result.Database == nil || result.Database.DeletedRows != int64(len(selfTestAugmentKeys)) {
+		t.Errorf("Expected %d database rows deleted, got %+v", len(selfTestAugmentKeys), result.Database)
+	}
+	if result.Database != nil && result.Database.DBBackupPath == "" {
+		t.Error("Expected the database to be backed up")
+	}
+	if result.Telemetry == nil || result.Telemetry.NewMachineID == selfTestMachineID {
+		t.Errorf("Expected the telemetry IDs to be rotated, got %+v", result.Telemetry)
+	}
+	if result.Telemetry != nil && result.Telemetry.StorageBackupPath == "" {
+		t.Error("Expected storage.json to be backed up")
+	}
+	if last, err := History(context.Background(), opts, 1); err != nil || len(last) != 1 || last[0].Operation != "quick-clean" || !last[0].Success {
+		t.Errorf("Expected a successful quick-clean history record, got %+v (%v)", last, err)
+	}
+
+	untouched := []string{
+		filepath.Join(sandbox.workspacePath, "0123456789abcdef", "Augment.vscode-augment", "state"),
+		filepath.Join(sandbox.chromeProfile, "Local Storage", "leveldb", "000003.log"),
+	}
+	for _, path := range untouched {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be left alone, got %v", path, err)
+		}
+	}
+}
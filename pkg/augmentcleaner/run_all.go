@@ -0,0 +1,184 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// Names of the RunAll steps, matching the operations they run
+const (
+	StepModifyTelemetry = "modify-telemetry"
+	StepCleanDatabase   = "clean-database"
+	StepCleanWorkspace  = "clean-workspace"
+	StepCleanBrowser    = "clean-browser"
+)
+
+// StepResult is the outcome of one RunAll step
+type StepResult struct {
+	Step     string        `json:"step"`
+	Skipped  bool          `json:"skipped,omitempty"` // Not run because the context was done
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Err      error         `json:"-"` // The error behind Error, for errors.Is and errors.As
+}
+
+// AllOperationsResult is the result of RunAll. Steps lists every step in order;
+// a step that failed or was skipped has no result of its own.
+type AllOperationsResult struct {
+	Telemetry *TelemetryModifyResult `json:"telemetry,omitempty"`
+	Database  *DatabaseCleanResult   `json:"database,omitempty"`
+	Workspace *WorkspaceCleanResult  `json:"workspace,omitempty"`
+	Browsers  []BrowserCleanResult   `json:"browsers,omitempty"`
+	Steps     []StepResult           `json:"steps"`
+	Duration  time.Duration          `json:"duration"`
+}
+
+// Failed returns the steps that failed, in order
+func (r *AllOperationsResult) Failed() []StepResult {
+	var failed []StepResult
+	for _, step := range r.Steps {
+		if step.Err != nil && !step.Skipped {
+			failed = append(failed, step)
+		}
+	}
+	return failed
+}
+
+// BytesFreed returns the bytes freed by the database, workspace and browser steps
+func (r *AllOperationsResult) BytesFreed() int64 {
+	var total int64
+	if r.Database != nil {
+		total += r.Database.BytesFreed
+	}
+	if r.Workspace != nil {
+		total += r.Workspace.BytesFreed
+	}
+	for _, browser := range r.Browsers {
+		total += browser.BytesFreed
+	}
+	return total
+}
+
+// RunAllEstimate is what RunAll would delete, see EstimateRunAll. A step whose
+// estimate failed has none and its error is listed in Errors.
+type RunAllEstimate struct {
+	Database   *DatabaseCleanEstimate  `json:"database,omitempty"`
+	Workspace  *WorkspaceCleanEstimate `json:"workspace,omitempty"`
+	Browsers   []BrowserProfileCount   `json:"browsers,omitempty"`
+	TotalBytes int64                   `json:"total_bytes"`
+	Errors     []string                `json:"errors,omitempty"`
+}
+
+// EstimateRunAll returns what the database, workspace and browser steps of RunAll
+// would delete and the total size, without modifying anything. A failing estimate
+// does not stop the others.
+func EstimateRunAll(ctx context.Context, opts Options) (*RunAllEstimate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	estimate := &RunAllEstimate{}
+	if database, err := EstimateDatabaseClean(ctx, opts); err != nil {
+		estimate.Errors = append(estimate.Errors, fmt.Sprintf("%s: %v", StepCleanDatabase, err))
+	} else {
+		estimate.Database = database
+		estimate.TotalBytes += database.Bytes
+	}
+	if workspace, err := EstimateWorkspaceClean(ctx, opts); err != nil {
+		estimate.Errors = append(estimate.Errors, fmt.Sprintf("%s: %v", StepCleanWorkspace, err))
+	} else {
+		estimate.Workspace = workspace
+		estimate.TotalBytes += workspace.Bytes
+	}
+	if browsers, err := CountBrowserData(ctx, opts); err != nil {
+		estimate.Errors = append(estimate.Errors, fmt.Sprintf("%s: %v", StepCleanBrowser, err))
+	} else {
+		estimate.Browsers = browsers
+		for _, browser := range browsers {
+			estimate.TotalBytes += browser.Bytes
+		}
+	}
+
+	return estimate, nil
+}
+
+// RunAll runs every cleaning operation in order: ModifyTelemetryIDs, CleanDatabase,
+// CleanWorkspace and CleanBrowsers. A failing step does not stop the others; once
+// ctx is done the remaining steps are marked skipped. The result is always
+// returned, with the returned error listing every failed or skipped step. Besides
+// the record of each step, the run as a whole is recorded in the history.
+func RunAll(ctx context.Context, opts Options) (*AllOperationsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	elapsed := utils.StartTimer()
+	result := &AllOperationsResult{}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{StepModifyTelemetry, func() error {
+			telemetry, err := ModifyTelemetryIDs(ctx, opts)
+			if err == nil {
+				result.Telemetry = telemetry
+			}
+			return err
+		}},
+		{StepCleanDatabase, func() error {
+			database, err := CleanDatabase(ctx, opts)
+			result.Database = database
+			return err
+		}},
+		{StepCleanWorkspace, func() error {
+			workspace, err := CleanWorkspace(ctx, opts)
+			result.Workspace = workspace
+			return err
+		}},
+		{StepCleanBrowser, func() error {
+			browsers, err := CleanBrowsers(ctx, opts)
+			result.Browsers = browsers
+			return err
+		}},
+	}
+
+	var errs []string
+	for i, step := range steps {
+		stepResult := StepResult{Step: step.name}
+		if err := ctx.Err(); err != nil {
+			stepResult.Skipped = true
+			stepResult.Err = err
+			stepResult.Error = err.Error()
+			errs = append(errs, fmt.Sprintf("%s: skipped: %v", step.name, err))
+			result.Steps = append(result.Steps, stepResult)
+			continue
+		}
+
+		opts.report("run-all", "Step %d/%d: %s", i+1, len(steps), step.name)
+		stepElapsed := utils.StartTimer()
+		if err := step.run(); err != nil {
+			stepResult.Err = err
+			stepResult.Error = err.Error()
+			errs = append(errs, fmt.Sprintf("%s: %v", step.name, err))
+		}
+		stepResult.Duration = stepElapsed()
+		result.Steps = append(result.Steps, stepResult)
+	}
+
+	result.Duration = elapsed()
+	opts.report("run-all", "Finished %d steps in %s, %d failed or skipped",
+		len(steps), result.Duration.Round(time.Millisecond), len(errs))
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("run-all failed: %s", strings.Join(errs, "; "))
+		opts.recordHistory("run-all", "", nil, nil, err)
+		return result, err
+	}
+	opts.recordHistory("run-all", fmt.Sprintf("Ran %d steps, freed %s", len(steps), utils.FormatBytes(result.BytesFreed())), nil, nil, nil)
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package augmentcleaner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestRunAllReportsFailuresPerStep(t *testing.T) {
+	home := t.TempDir()
+	utils.SetHomeDirOverride(home)
+	defer utils.SetHomeDirOverride("")
+
+	if _, err := newSelfTestSandbox(home, utils.DefaultPathResolver()); err != nil {
+		t.Fatalf("Failed to build sandbox: %v", err)
+	}
+
+	// An unknown browser fails the browser step before any browser is touched
+	opts := DefaultOptions()
+	opts.Browser = "netscape"
+	opts.HistoryPath = filepath.Join(home, "history.jsonl")
+	result, err := RunAll(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), StepCleanBrowser) {
+		t.Errorf("Expected the browser step failure to be returned, got %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected a result despite the failed step")
+	}
+
+	wantSteps := []string{StepModifyTelemetry, StepCleanDatabase, StepCleanWorkspace, StepCleanBrowser}
+	if len(result.Steps) != len(wantSteps) {
+		t.Fatalf("Expected steps %v, got %+v", wantSteps, result.Steps)
+	}
+	for i, step := range result.Steps {
+		if step.Step != wantSteps[i] || step.Skipped {
+			t.Errorf("Expected step %d to be %s and run, got %+v", i, wantSteps[i], step)
+		}
+		if wantErr := step.Step == StepCleanBrowser; (step.Err != nil) != wantErr || (step.Error != "") != wantErr {
+			t.Errorf("Step %s: expected an error: %v, got %q", step.Step, wantErr, step.Error)
+		}
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Step != StepCleanBrowser {
+		t.Errorf("Expected only the browser step to fail, got %+v", failed)
+	}
+
+	if result.Telemetry == nil || result.Telemetry.NewMachineID == selfTestMachineID {
+		t.Errorf("Expected the telemetry IDs to be rotated, got %+v", result.Telemetry)
+	}
+	if result.Database == nil || result.Database.DeletedRows != int64(len(selfTestAugmentKeys)) {
+		t.Errorf("Expected %d database rows deleted, got %+v", len(selfTestAugmentKeys), result.Database)
+	}
+	if result.Workspace == nil || result.Workspace.DeletedFilesCount == 0 {
+		t.Errorf("Expected workspace files to be deleted, got %+v", result.Workspace)
+	}
+	if result.Browsers != nil {
+		t.Errorf("Expected no browser results, got %+v", result.Browsers)
+	}
+
+	if last, err := History(context.Background(), opts, 1); err != nil || len(last) != 1 || last[0].Operation != "run-all" || last[0].Success {
+		t.Errorf("Expected a failed run-all history record after the steps, got %+v (%v)", last, err)
+	}
+}
+
+func TestRunAllSkipsStepsAfterCancellation(t *testing.T) {
+	home := t.TempDir()
+	utils.SetHomeDirOverride(home)
+	defer utils.SetHomeDirOverride("")
+	if _, err := newSelfTestSandbox(home, utils.DefaultPathResolver()); err != nil {
+		t.Fatalf("Failed to build sandbox: %v", err)
+	}
+
+	// Cancel as soon as the first step reports progress
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := DefaultOptions()
+	opts.Progress = func(p Progress) {
+		if p.Operation == StepModifyTelemetry {
+			cancel()
+		}
+	}
+
+	result, err := RunAll(ctx, opts)
+	if err == nil || result == nil {
+		t.Fatalf("Expected a partial result and an error, got %+v and %v", result, err)
+	}
+	for _, step := range result.Steps[1:] {
+		if !step.Skipped || !errors.Is(step.Err, context.Canceled) {
+			t.Errorf("Expected %s to be skipped after cancellation, got %+v", step.Step, step)
+		}
+	}
+	if len(result.Failed()) != 0 {
+		t.Errorf("Expected skipped steps not to count as failed, got %+v", result.Failed())
+	}
+}
@@ -0,0 +1,46 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+
+	"augment-telemetry-cleaner/internal/scanner"
+)
+
+// ScanAllowlist returns the extensions Scan reports as trusted without analyzing
+// their storage: the user's allowlist, or the built-in one until it is changed
+func ScanAllowlist(ctx context.Context, opts Options) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return loadScanAllowlist()
+}
+
+// UpdateScanAllowlist adds and removes extension IDs from the user's scan
+// allowlist and returns the new allowlist
+func UpdateScanAllowlist(ctx context.Context, opts Options, add, remove []string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path, err := scanner.DefaultScanAllowlistPath()
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := scanner.UpdateScanAllowlist(path, add, remove)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scan allowlist: %w", err)
+	}
+	opts.report("allowlist", "Scan allowlist has %d extensions", len(allowlist))
+
+	return allowlist, nil
+}
+
+// loadScanAllowlist reads the user's scan allowlist
+func loadScanAllowlist() ([]string, error) {
+	path, err := scanner.DefaultScanAllowlistPath()
+	if err != nil {
+		return nil, err
+	}
+	return scanner.LoadScanAllowlist(path)
+}
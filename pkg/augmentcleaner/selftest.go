@@ -0,0 +1,423 @@
+package augmentcleaner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"augment-telemetry-cleaner/internal/browser"
+	"augment-telemetry-cleaner/internal/utils"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SelfTestStatus is the outcome of a self-test module
+type SelfTestStatus string
+
+const (
+	SelfTestPass SelfTestStatus = "PASS"
+	SelfTestFail SelfTestStatus = "FAIL"
+	SelfTestSkip SelfTestStatus = "SKIP"
+)
+
+// SelfTestResult is the outcome of running one cleaner against the self-test sandbox
+type SelfTestResult struct {
+	Module string         `json:"module"`
+	Status SelfTestStatus `json:"status"`
+	Detail string         `json:"detail"`
+}
+
+// Fixture values seeded into the sandbox
+const (
+	selfTestMachineID = "0000000000000000000000000000000000000000000000000000000000000000"
+	selfTestDeviceID  = "00000000-0000-4000-8000-000000000000"
+	selfTestKeptKey   = "workbench.panel.selfTest"
+	selfTestKeptHost  = ".example.com"
+)
+
+// selfTestAugmentKeys are the ItemTable keys the database cleaner must delete
+var selfTestAugmentKeys = []string{"Augment.vscode-augment", "augment.sessions.selfTest"}
+
+// selfTestSandbox is a temporary home directory laid out like a VS Code and Chrome install
+type selfTestSandbox struct {
+	home          string
+	storagePath   string
+	machineIDPath string
+	dbPath        string
+	workspacePath string
+	chromeProfile string
+}
+
+// SelfTest builds a throwaway sandbox mimicking the VS Code and browser data
+// layout, runs every cleaner against it and checks the expected deletions and
+// backups. The real user data is never touched: the cleaners get a PathResolver
+// rooted in the sandbox, so other operations may run at the same time. The OS
+// secret store cannot be sandboxed and is reported as skipped.
+func SelfTest(ctx context.Context, opts Options) ([]SelfTestResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	home, err := os.MkdirTemp("", "augment-cleaner-selftest-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+	defer os.RemoveAll(home)
+
+	resolver := utils.NewHomePathResolver(home)
+
+	opts.report("self-test", "Building sandbox in %s", home)
+	sandbox, err := newSelfTestSandbox(home, resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never record sandbox runs in the real history or write audit files
+	opts.HistoryPath = ""
+	opts.AuditKey = nil
+	opts.CreateBackups = true
+	opts.Force = false
+	opts.AllowMultipleIDEWindows = true // VS Code windows cannot touch the sandbox
+	opts.BrowserProfiles = nil
+	opts.PathResolver = resolver
+	opts.DatabasePath = ""
+
+	modules := []struct {
+		name string
+		run  func(context.Context, Options, *selfTestSandbox) (string, error)
+	}{
+		{"modify-telemetry", selfTestTelemetry},
+		{"clean-database", selfTestDatabase},
+		{"clean-workspace", selfTestWorkspace},
+		{"clean-browser", selfTestBrowser},
+	}
+
+	var results []SelfTestResult
+	for _, module := range modules {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		opts.report("self-test", "Testing %s", module.name)
+		result := SelfTestResult{Module: module.name, Status: SelfTestPass}
+		detail, err := module.run(ctx, opts, sandbox)
+		if err != nil {
+			result.Status = SelfTestFail
+			detail = err.Error()
+		}
+		result.Detail = detail
+		results = append(results, result)
+	}
+
+	results = append(results, SelfTestResult{
+		Module: "clean-secret-store",
+		Status: SelfTestSkip,
+		Detail: "The OS secret store cannot be sandboxed",
+	})
+
+	return results, nil
+}
+
+// newSelfTestSandbox seeds the fixtures under home at the paths resolver
+// derives, so they match what the cleaners resolve
+func newSelfTestSandbox(home string, resolver utils.PathResolver) (*selfTestSandbox, error) {
+	sandbox := &selfTestSandbox{home: home}
+	paths := utils.NewVSCodePaths(resolver)
+	var err error
+
+	if sandbox.storagePath, err = paths.StoragePath(); err != nil {
+		return nil, fmt.Errorf("failed to get storage path: %w", err)
+	}
+	if sandbox.machineIDPath, err = paths.MachineIDPath(); err != nil {
+		return nil, fmt.Errorf("failed to get machine ID path: %w", err)
+	}
+	if sandbox.dbPath, err = paths.DBPath(); err != nil {
+		return nil, fmt.Errorf("failed to get database path: %w", err)
+	}
+	if sandbox.workspacePath, err = paths.WorkspaceStoragePath(); err != nil {
+		return nil, fmt.Errorf("failed to get workspace storage path: %w", err)
+	}
+	sandbox.chromeProfile = filepath.Join(chromeUserDataDir(home, runtime.GOOS), "Default")
+
+	storage, err := json.MarshalIndent(map[string]interface{}{
+		"telemetry.machineId":   selfTestMachineID,
+		"telemetry.devDeviceId": selfTestDeviceID,
+	}, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal storage fixture: %w", err)
+	}
+
+	files := map[string]string{
+		sandbox.storagePath:   string(storage),
+		sandbox.machineIDPath: selfTestDeviceID,
+		filepath.Join(sandbox.workspacePath, "0123456789abcdef", "workspace.json"):                  `{"folder": "file:///tmp/selftest"}`,
+		filepath.Join(sandbox.workspacePath, "0123456789abcdef", "Augment.vscode-augment", "state"): "augment state",
+		filepath.Join(sandbox.chromeProfile, "Local Storage", "leveldb", "000003.log"):              "_https://app.augmentcode.com\x00augment_session",
+		filepath.Join(sandbox.chromeProfile, "Local Storage", "leveldb", "CURRENT"):                 "MANIFEST-000001\n",
+	}
+	for path, content := range files {
+		if err := writeSelfTestFile(path, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := seedSelfTestDatabase(sandbox.dbPath); err != nil {
+		return nil, err
+	}
+	if err := seedSelfTestCookies(filepath.Join(sandbox.chromeProfile, "Cookies")); err != nil {
+		return nil, err
+	}
+
+	return sandbox, nil
+}
+
+// chromeUserDataDir returns where Chrome keeps its profiles under home on goos
+func chromeUserDataDir(home, goos string) string {
+	switch goos {
+	case "windows":
+		return filepath.Join(home, "AppData", "Local", "Google", "Chrome", "User Data")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Google", "Chrome")
+	default:
+		return filepath.Join(home, ".config", "google-chrome")
+	}
+}
+
+// writeSelfTestFile writes a fixture file, creating its parent directories
+func writeSelfTestFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// seedSelfTestDatabase creates a state.vscdb with Augment rows and one unrelated row
+func seedSelfTestDatabase(dbPath string) error {
+	statements := []string{`CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)`}
+	for _, key := range append(selfTestAugmentKeys, selfTestKeptKey) {
+		statements = append(statements, fmt.Sprintf(`INSERT INTO ItemTable (key, value) VALUES ('%s', '{}')`, key))
+	}
+	return execSelfTestSQL(dbPath, statements)
+}
+
+// seedSelfTestCookies creates a Chromium cookies database with one Augment and one unrelated cookie
+func seedSelfTestCookies(dbPath string) error {
+	return execSelfTestSQL(dbPath, []string{
+		`CREATE TABLE cookies (host_key TEXT NOT NULL, name TEXT NOT NULL, value TEXT NOT NULL)`,
+		`INSERT INTO cookies (host_key, name, value) VALUES ('.augmentcode.com', 'session', 'selftest')`,
+		fmt.Sprintf(`INSERT INTO cookies (host_key, name, value) VALUES ('%s', 'session', 'selftest')`, selfTestKeptHost),
+	})
+}
+
+// execSelfTestSQL runs statements against the SQLite database at dbPath
+func execSelfTestSQL(dbPath string, statements []string) error {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open fixture database: %w", err)
+	}
+	defer db.Close()
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to seed fixture database %s: %w", dbPath, err)
+		}
+	}
+	return nil
+}
+
+// countSelfTestRows returns the result of a COUNT(*) query against the database at dbPath
+func countSelfTestRows(dbPath, query string, args ...interface{}) (int64, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", dbPath, err)
+	}
+	defer db.Close()
+
+	var count int64
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", dbPath, err)
+	}
+	return count, nil
+}
+
+// selfTestTelemetry checks that both IDs were rotated and the originals backed up
+func selfTestTelemetry(ctx context.Context, opts Options, sandbox *selfTestSandbox) (string, error) {
+	result, err := ModifyTelemetryIDs(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if result.NewMachineID == "" || result.NewMachineID == selfTestMachineID {
+		return "", fmt.Errorf("machine ID was not rotated")
+	}
+	if result.NewDeviceID == "" || result.NewDeviceID == selfTestDeviceID {
+		return "", fmt.Errorf("device ID was not rotated")
+	}
+
+	data, err := os.ReadFile(sandbox.storagePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read storage.json: %w", err)
+	}
+	var storage map[string]interface{}
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return "", fmt.Errorf("storage.json is no longer valid JSON: %w", err)
+	}
+	if storage["telemetry.machineId"] != result.NewMachineID {
+		return "", fmt.Errorf("storage.json does not contain the new machine ID")
+	}
+
+	machineID, err := os.ReadFile(sandbox.machineIDPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read machine ID file: %w", err)
+	}
+	if string(machineID) != result.NewDeviceID {
+		return "", fmt.Errorf("machine ID file does not contain the new device ID")
+	}
+
+	backup, err := os.ReadFile(result.StorageBackupPath)
+	if err != nil {
+		return "", fmt.Errorf("storage.json backup missing: %w", err)
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(backup, &original); err != nil || original["telemetry.machineId"] != selfTestMachineID {
+		return "", fmt.Errorf("storage.json backup does not contain the original IDs")
+	}
+	if _, err := os.Stat(result.MachineIDBackupPath); err != nil {
+		return "", fmt.Errorf("machine ID backup missing: %w", err)
+	}
+
+	return "Rotated machine and device IDs and backed up the originals", nil
+}
+
+// selfTestDatabase checks that only the Augment rows were deleted and the database backed up
+func selfTestDatabase(ctx context.Context, opts Options, sandbox *selfTestSandbox) (string, error) {
+	result, err := CleanDatabase(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if result.DeletedRows != int64(len(selfTestAugmentKeys)) {
+		return "", fmt.Errorf("deleted %d rows, expected %d", result.DeletedRows, len(selfTestAugmentKeys))
+	}
+
+	remaining, err := countSelfTestRows(sandbox.dbPath, `SELECT COUNT(*) FROM ItemTable WHERE key LIKE '%augment%'`)
+	if err != nil {
+		return "", err
+	}
+	if remaining != 0 {
+		return "", fmt.Errorf("%d Augment rows left in the database", remaining)
+	}
+
+	kept, err := countSelfTestRows(sandbox.dbPath, `SELECT COUNT(*) FROM ItemTable WHERE key = ?`, selfTestKeptKey)
+	if err != nil {
+		return "", err
+	}
+	if kept != 1 {
+		return "", fmt.Errorf("unrelated row %s was deleted", selfTestKeptKey)
+	}
+
+	backedUp, err := countSelfTestRows(result.DBBackupPath, `SELECT COUNT(*) FROM ItemTable WHERE key LIKE '%augment%'`)
+	if err != nil {
+		return "", fmt.Errorf("database backup unreadable: %w", err)
+	}
+	if backedUp != int64(len(selfTestAugmentKeys)) {
+		return "", fmt.Errorf("database backup holds %d Augment rows, expected %d", backedUp, len(selfTestAugmentKeys))
+	}
+
+	return fmt.Sprintf("Deleted %d Augment rows, kept unrelated rows and backed up the database", result.DeletedRows), nil
+}
+
+// selfTestWorkspace checks that workspace storage was emptied and zipped first
+func selfTestWorkspace(ctx context.Context, opts Options, sandbox *selfTestSandbox) (string, error) {
+	result, err := CleanWorkspace(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.FailedOperations) > 0 {
+		return "", fmt.Errorf("%d deletions failed: %s", len(result.FailedOperations), result.FailedOperations[0].Error)
+	}
+
+	entries, err := os.ReadDir(sandbox.workspacePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read workspace storage: %w", err)
+	}
+	if len(entries) != 0 {
+		return "", fmt.Errorf("%d entries left in workspace storage", len(entries))
+	}
+
+	info, err := os.Stat(result.BackupPath)
+	if err != nil {
+		return "", fmt.Errorf("workspace backup missing: %w", err)
+	}
+	if info.Size() == 0 {
+		return "", fmt.Errorf("workspace backup is empty")
+	}
+
+	return fmt.Sprintf("Deleted %d files and zipped a backup", result.DeletedFilesCount), nil
+}
+
+// selfTestBrowser checks that the Augment cookie and local storage were removed
+// from the sandbox Chrome profile and the profile backed up. Running browsers are
+// ignored since the sandbox profile is not in use by them.
+func selfTestBrowser(ctx context.Context, opts Options, sandbox *selfTestSandbox) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return "", err
+	}
+	browserCleaner.SetProcessLister(func() ([]browser.BrowserProcess, error) { return nil, nil })
+	browserCleaner.SetBackupDir(filepath.Join(sandbox.home, "backups", "browser-data"))
+
+	results, err := browserCleaner.CleanBrowserData(true)
+	if err != nil {
+		return "", err
+	}
+	if len(results) != 1 {
+		return "", fmt.Errorf("found %d browser profiles, expected the sandbox Chrome profile", len(results))
+	}
+
+	result := results[0]
+	if len(result.Errors) > 0 {
+		return "", fmt.Errorf("%s", result.Errors[0])
+	}
+	if result.CookiesDeleted != 1 {
+		return "", fmt.Errorf("deleted %d cookies, expected 1", result.CookiesDeleted)
+	}
+	if result.StorageDeleted < 1 {
+		return "", fmt.Errorf("no local storage files were deleted")
+	}
+
+	cookiesDB := filepath.Join(sandbox.chromeProfile, "Cookies")
+	kept, err := countSelfTestRows(cookiesDB, `SELECT COUNT(*) FROM cookies WHERE host_key = ?`, selfTestKeptHost)
+	if err != nil {
+		return "", err
+	}
+	if kept != 1 {
+		return "", fmt.Errorf("unrelated cookie for %s was deleted", selfTestKeptHost)
+	}
+	if _, err := os.Stat(filepath.Join(sandbox.chromeProfile, "Local Storage", "leveldb", "000003.log")); !os.IsNotExist(err) {
+		return "", fmt.Errorf("local storage file with Augment data was not deleted")
+	}
+
+	if _, err := os.Stat(filepath.Join(result.BackupPath, "Cookies")); err != nil {
+		return "", fmt.Errorf("profile backup missing cookies database: %w", err)
+	}
+
+	return fmt.Sprintf("Deleted %d cookies and %d storage items and backed up the profile",
+		result.CookiesDeleted, result.StorageDeleted), nil
+}
@@ -0,0 +1,53 @@
+package augmentcleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestSelfTestPassesWithoutOverridingPaths(t *testing.T) {
+	realStoragePath, err := utils.GetStoragePath()
+	if err != nil {
+		t.Fatalf("GetStoragePath failed: %v", err)
+	}
+
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	opts := DefaultOptions()
+	opts.HistoryPath = historyPath
+
+	// The sandbox is passed to the cleaners, so the process-wide paths never change
+	opts.Progress = func(p Progress) {
+		if path, _ := utils.GetStoragePath(); path != realStoragePath {
+			t.Errorf("Expected the storage path to stay %s during %q, got %s", realStoragePath, p.Message, path)
+		}
+	}
+
+	results, err := SelfTest(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("SelfTest failed: %v", err)
+	}
+
+	modules := make(map[string]SelfTestStatus)
+	for _, result := range results {
+		modules[result.Module] = result.Status
+		if result.Status == SelfTestFail {
+			t.Errorf("%s failed: %s", result.Module, result.Detail)
+		}
+	}
+	for _, module := range []string{"modify-telemetry", "clean-database", "clean-workspace", "clean-browser"} {
+		if modules[module] != SelfTestPass {
+			t.Errorf("Expected %s to pass, got %q", module, modules[module])
+		}
+	}
+	if modules["clean-secret-store"] != SelfTestSkip {
+		t.Errorf("Expected the secret store to be skipped, got %q", modules["clean-secret-store"])
+	}
+
+	if _, err := os.Stat(historyPath); !os.IsNotExist(err) {
+		t.Error("Expected sandbox runs to stay out of the history")
+	}
+}
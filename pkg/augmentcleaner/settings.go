@@ -0,0 +1,83 @@
+package augmentcleaner
+
+import (
+	"context"
+	"fmt"
+
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// SettingsCleanRequest selects the Augment settings CleanVSCodeSettings changes
+type SettingsCleanRequest struct {
+	// Keys are settings.json keys or path.Match patterns such as augment.chat.*;
+	// empty selects every Augment setting
+	Keys []string
+	// ResetToDefaults sets the selected keys to the defaults the installed Augment
+	// extension declares instead of removing them; keys without a default are removed
+	ResetToDefaults bool
+	// DryRun computes the change and its diff without writing settings.json
+	DryRun bool
+}
+
+// CleanVSCodeSettings removes the selected Augment settings from the VS Code user
+// settings.json, keeping its comments and formatting. Settings the safety
+// validator protects are kept and listed in the result. With opts.CreateBackups
+// the file is backed up first, so RestoreVSCodeSettings can undo the change.
+func CleanVSCodeSettings(ctx context.Context, opts Options, req SettingsCleanRequest) (*CleanVSCodeSettingsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	settingsPath, err := utils.NewVSCodePaths(opts.pathResolver()).UserSettingsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings path: %w", err)
+	}
+
+	cleanOpts := cleaner.CleanVSCodeSettingsOptions{
+		Keys:   req.Keys,
+		Backup: opts.CreateBackups,
+		DryRun: req.DryRun,
+	}
+	if req.ResetToDefaults {
+		if cleanOpts.Defaults, err = augmentSettingDefaults(opts.pathResolver()); err != nil {
+			return nil, err
+		}
+	}
+
+	opts.report("clean-settings", "Cleaning Augment settings in %s", settingsPath)
+	result, err := cleaner.NewBackupManager().CleanVSCodeSettings(settingsPath, cleanOpts)
+	if err != nil {
+		if !req.DryRun {
+			opts.recordHistory("clean-settings", "", nil, nil, err)
+		}
+		return nil, fmt.Errorf("settings cleaning failed: %w", err)
+	}
+
+	summary := fmt.Sprintf("Removed %d and reset %d Augment settings", len(result.SettingsRemoved), len(result.SettingsReset))
+	opts.report("clean-settings", "%s", summary)
+	if !req.DryRun {
+		opts.recordHistory("clean-settings", summary, []string{result.BackupPath}, nil, nil)
+	}
+
+	return result, nil
+}
+
+// augmentSettingDefaults returns the setting defaults declared by the Augment
+// extensions installed in the environment described by resolver
+func augmentSettingDefaults(resolver utils.PathResolver) (map[string]interface{}, error) {
+	extensions, err := utils.ListInstalledExtensionsWithResolver(resolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed extensions: %w", err)
+	}
+
+	defaults := make(map[string]interface{})
+	for _, extension := range extensions {
+		for key, value := range cleaner.ManifestSettingDefaults(extension.Manifest) {
+			if cleaner.IsAugmentSetting(key) {
+				defaults[key] = value
+			}
+		}
+	}
+	return defaults, nil
+}
@@ -0,0 +1,199 @@
+package augmentcleaner
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"augment-telemetry-cleaner/internal/browser"
+	"augment-telemetry-cleaner/internal/cleaner"
+	"augment-telemetry-cleaner/internal/config"
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+// ValidationFailure is a check that failed during Validate or ConfigManager.Validate
+type ValidationFailure = config.ValidationFailure
+
+// minBackupFreeSpace is the free space required in backup directories on top of
+// the size of the files that would be backed up
+const minBackupFreeSpace = 100 * 1024 * 1024
+
+// ValidationRequest selects what Validate checks
+type ValidationRequest struct {
+	// ReadPaths checks the VS Code paths scanning reads can be read
+	ReadPaths bool
+	// WritePaths checks the VS Code paths cleaning modifies can be read and written
+	WritePaths bool
+	// Browsers checks the profiles of every detected browser, or of Browser when set
+	Browsers bool
+	// Browser is the short name of the browser to check: chrome, chrome-beta, chrome-dev,
+	// chrome-canary, edge, arc, webview2, firefox or safari
+	Browser string
+	// BackupDirs must be writable and have enough free space for the backups
+	BackupDirs []string
+}
+
+// validationPath is a VS Code path checked by Validate
+type validationPath struct {
+	check string
+	path  func() (string, error)
+	// created paths may be missing; their directory must then be writable
+	created bool
+}
+
+// Validate checks that the paths an operation would use exist and are accessible,
+// that the VS Code database can be opened and that backup directories are writable
+// with enough free space. Files are opened and closed but never read or modified.
+// An empty result means every check passed.
+func Validate(ctx context.Context, opts Options, req ValidationRequest) ([]ValidationFailure, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var failures []ValidationFailure
+	fail := func(check, path string, err error) {
+		failures = append(failures, ValidationFailure{Check: check, Path: path, Problem: err.Error()})
+	}
+
+	paths := utils.NewVSCodePaths(opts.pathResolver())
+
+	var checked []validationPath
+	if req.ReadPaths {
+		checked = append(checked,
+			validationPath{check: "storage file", path: paths.StoragePath},
+			validationPath{check: "database", path: opts.databasePath},
+			validationPath{check: "workspace storage", path: paths.WorkspaceStoragePath},
+			validationPath{check: "extensions", path: paths.ExtensionsPath},
+		)
+	}
+	if req.WritePaths {
+		checked = append(checked,
+			validationPath{check: "storage file", path: paths.StoragePath},
+			validationPath{check: "machine ID file", path: paths.MachineIDPath, created: true},
+			validationPath{check: "database", path: opts.databasePath},
+			validationPath{check: "workspace storage", path: paths.WorkspaceStoragePath},
+		)
+	}
+
+	var backupSize uint64
+	validated := make(map[string]bool)
+	for _, vp := range checked {
+		path, err := vp.path()
+		if err != nil {
+			fail(vp.check, "", err)
+			continue
+		}
+
+		// A path needed by both scanning and cleaning is checked once, for writing
+		write := req.WritePaths && vp.check != "extensions"
+		if validated[path] {
+			continue
+		}
+		validated[path] = true
+		opts.report("validate", "Checking %s %s", vp.check, path)
+
+		info, statErr := os.Stat(path)
+		if os.IsNotExist(statErr) && vp.created {
+			if err := utils.CheckWritableDir(filepath.Dir(path)); err != nil {
+				fail(vp.check, path, err)
+			}
+			continue
+		}
+		if err := utils.CheckAccess(path, write); err != nil {
+			fail(vp.check, path, err)
+			continue
+		}
+		if !info.IsDir() {
+			backupSize += uint64(info.Size())
+		}
+
+		if vp.check == "database" {
+			if err := checkDatabaseOpenable(path); err != nil {
+				fail("database", path, err)
+			}
+		}
+	}
+
+	if req.Browsers {
+		failures = append(failures, validateBrowserProfiles(opts, req.Browser)...)
+	}
+
+	safetyValidator := cleaner.NewSafetyValidator()
+	for _, dir := range req.BackupDirs {
+		if dir == "" {
+			continue
+		}
+		opts.report("validate", "Checking backup directory %s", dir)
+		if err := utils.CheckWritableDir(dir); err != nil {
+			fail("backup directory", dir, err)
+			continue
+		}
+		if err := safetyValidator.CheckDiskSpace(dir, backupSize+minBackupFreeSpace); err != nil {
+			fail("backup disk space", dir, err)
+		}
+	}
+
+	return failures, nil
+}
+
+// checkDatabaseOpenable opens the SQLite database read-only and queries its schema
+func checkDatabaseOpenable(dbPath string) error {
+	db, err := sql.Open("sqlite3", "file:"+filepath.ToSlash(dbPath)+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master").Scan(&tables); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	return nil
+}
+
+// validateBrowserProfiles checks that the detected profiles of browserName, or of
+// every browser when empty, exist and are readable and writable
+func validateBrowserProfiles(opts Options, browserName string) []ValidationFailure {
+	var failures []ValidationFailure
+
+	var browserType browser.BrowserType
+	if browserName != "" {
+		parsed, err := browser.ParseBrowserType(browserName)
+		if err != nil {
+			return []ValidationFailure{{Check: "browser", Problem: err.Error()}}
+		}
+		browserType = parsed
+	}
+
+	browserCleaner, err := newBrowserCleaner(opts)
+	if err != nil {
+		return []ValidationFailure{{Check: "browser", Problem: err.Error()}}
+	}
+	profiles, err := browserCleaner.Profiles()
+	if err != nil {
+		return []ValidationFailure{{Check: "browser", Problem: fmt.Sprintf("failed to detect browsers: %v", err)}}
+	}
+
+	found := 0
+	for _, profile := range profiles {
+		if browserName != "" && profile.Type != browserType {
+			continue
+		}
+		found++
+		opts.report("validate", "Checking %s profile %s", profile.Type, profile.ProfilePath)
+		if err := utils.CheckAccess(profile.ProfilePath, true); err != nil {
+			failures = append(failures, ValidationFailure{
+				Check:   fmt.Sprintf("%s profile %s", profile.Type, profile.Name),
+				Path:    profile.ProfilePath,
+				Problem: err.Error(),
+			})
+		}
+	}
+
+	if browserName != "" && found == 0 {
+		failures = append(failures, ValidationFailure{Check: "browser", Problem: fmt.Sprintf("no %s profiles found", browserType)})
+	}
+	return failures
+}
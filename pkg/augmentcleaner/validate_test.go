@@ -0,0 +1,91 @@
+package augmentcleaner
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"augment-telemetry-cleaner/internal/utils"
+)
+
+func TestValidate(t *testing.T) {
+	home := t.TempDir()
+	utils.SetHomeDirOverride(home)
+	defer utils.SetHomeDirOverride("")
+
+	storagePath, _ := utils.GetStoragePath()
+	dbPath, _ := utils.GetDBPath()
+	workspacePath, _ := utils.GetWorkspaceStoragePath()
+	extensionsPath, _ := utils.GetExtensionsPath()
+	backupDir := filepath.Join(home, "backups", "not-created-yet")
+
+	req := ValidationRequest{ReadPaths: true, WritePaths: true, BackupDirs: []string{backupDir}}
+	failures, err := Validate(context.Background(), DefaultOptions(), req)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(failures) != 4 {
+		t.Errorf("Expected the storage file, database, workspace storage and extensions to be missing, got %+v", failures)
+	}
+
+	for _, dir := range []string{filepath.Dir(storagePath), workspacePath, extensionsPath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(storagePath, []byte(`{}`), 0644); err != nil {
+		t.Fatalf("Failed to write storage file: %v", err)
+	}
+
+	// A file that is not a SQLite database exists but cannot be opened
+	if err := os.WriteFile(dbPath, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("Failed to write database: %v", err)
+	}
+	failures, err = Validate(context.Background(), DefaultOptions(), req)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(failures) != 1 || failures[0].Check != "database" || failures[0].Path != dbPath {
+		t.Errorf("Expected only the database to fail, got %+v", failures)
+	}
+
+	os.Remove(dbPath)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT, value BLOB)"); err != nil {
+		t.Fatalf("Failed to create ItemTable: %v", err)
+	}
+	db.Close()
+
+	failures, err = Validate(context.Background(), DefaultOptions(), req)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("Expected validation to pass, got %+v", failures)
+	}
+
+	// Validation never creates the machine ID file or the backup directory
+	if machineIDPath, _ := utils.GetMachineIDPath(); fileExists(machineIDPath) {
+		t.Error("Expected the machine ID file not to be created")
+	}
+	if fileExists(backupDir) {
+		t.Error("Expected the backup directory not to be created")
+	}
+
+	// A backup directory below a file can never be created
+	req.BackupDirs = []string{filepath.Join(storagePath, "backups")}
+	failures, _ = Validate(context.Background(), DefaultOptions(), req)
+	if len(failures) != 1 || failures[0].Check != "backup directory" {
+		t.Errorf("Expected the backup directory to fail, got %+v", failures)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}